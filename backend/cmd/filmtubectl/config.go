@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+func runConfigExport(args []string) error {
+	fs := flag.NewFlagSet("config export", flag.ExitOnError)
+	format := fs.String("format", "yaml", "bundle format: yaml or json")
+	out := fs.String("out", "", "output file (default stdout)")
+	apiURL := fs.String("api", getEnv("FILMTUBE_API_URL", "http://localhost:8080"), "API base URL")
+	token := fs.String("token", os.Getenv("FILMTUBE_TOKEN"), "API token")
+	fs.Parse(args)
+
+	if *format != "yaml" && *format != "json" {
+		return errors.New("--format must be yaml or json")
+	}
+
+	client := newAPIClient(*apiURL, *token)
+	data, err := client.doRaw(context.Background(), http.MethodGet, "/api/admin/config/export?format="+*format, "", nil)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o644)
+}
+
+func runConfigImport(args []string) error {
+	fs := flag.NewFlagSet("config import", flag.ExitOnError)
+	file := fs.String("file", "", "bundle file to import (required)")
+	dryRun := fs.Bool("dry-run", false, "only show the changes that would be applied")
+	apiURL := fs.String("api", getEnv("FILMTUBE_API_URL", "http://localhost:8080"), "API base URL")
+	token := fs.String("token", os.Getenv("FILMTUBE_TOKEN"), "API token")
+	fs.Parse(args)
+
+	if *file == "" {
+		return errors.New("--file is required")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	contentType := "application/json"
+	if ext := filepath.Ext(*file); ext == ".yaml" || ext == ".yml" {
+		contentType = "application/yaml"
+	}
+
+	path := "/api/admin/config/import"
+	if *dryRun {
+		path += "?dry_run=true"
+	}
+
+	client := newAPIClient(*apiURL, *token)
+	resp, err := client.doRaw(context.Background(), http.MethodPost, path, contentType, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		DryRun  bool `json:"dry_run"`
+		Changes []struct {
+			Section string          `json:"section"`
+			Key     string          `json:"key"`
+			Action  string          `json:"action"`
+			Old     json.RawMessage `json:"old"`
+			New     json.RawMessage `json:"new"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return err
+	}
+
+	if len(result.Changes) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+
+	for _, change := range result.Changes {
+		fmt.Printf("%-7s %s/%s\n", change.Action, change.Section, change.Key)
+		if len(change.Old) > 0 {
+			fmt.Printf("  - %s\n", change.Old)
+		}
+		if len(change.New) > 0 {
+			fmt.Printf("  + %s\n", change.New)
+		}
+	}
+
+	if result.DryRun {
+		fmt.Printf("%d change(s) would be applied (dry run)\n", len(result.Changes))
+	} else {
+		fmt.Printf("%d change(s) applied\n", len(result.Changes))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// backfillEvent is the part of a film event the dry run prints
+type backfillEvent struct {
+	Type       string `json:"type"`
+	FilmID     string `json:"film_id"`
+	OccurredAt string `json:"occurred_at"`
+}
+
+type backfillResponse struct {
+	Films       int             `json:"films"`
+	Emitted     int             `json:"emitted"`
+	Events      []backfillEvent `json:"events"`
+	NextAfterID *string         `json:"next_after_id"`
+}
+
+func runEventsBackfill(args []string) error {
+	fs := flag.NewFlagSet("events backfill", flag.ExitOnError)
+	films := fs.String("film", "", "comma-separated film IDs to replay (default: every film)")
+	types := fs.String("types", "", "comma-separated event types: film.created, film.ready, film.published (default: all)")
+	rate := fs.Int("rate", 50, "events per second")
+	batch := fs.Int("batch", 100, "films per request when replaying every film")
+	after := fs.String("after", "", "resume after this film ID")
+	dryRun := fs.Bool("dry-run", false, "list the events without emitting them")
+	apiURL := fs.String("api", getEnv("FILMTUBE_API_URL", "http://localhost:8080"), "API base URL")
+	token := fs.String("token", os.Getenv("FILMTUBE_TOKEN"), "API token")
+	fs.Parse(args)
+
+	if *token == "" {
+		return errors.New("--token or FILMTUBE_TOKEN is required")
+	}
+
+	body := map[string]interface{}{
+		"rate":    *rate,
+		"dry_run": *dryRun,
+	}
+	if list := splitFlag(*types); len(list) > 0 {
+		body["types"] = list
+	}
+	filmIDs := splitFlag(*films)
+	if len(filmIDs) > 0 {
+		body["film_ids"] = filmIDs
+	} else {
+		body["limit"] = *batch
+		if *after != "" {
+			body["after_id"] = *after
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := newAPIClient(*apiURL, *token)
+
+	totalFilms, totalEvents := 0, 0
+	for {
+		var resp backfillResponse
+		if err := client.do(ctx, http.MethodPost, "/api/admin/events/backfill", body, &resp); err != nil {
+			if last, ok := body["after_id"]; ok {
+				return fmt.Errorf("%w (resume with --after %s)", err, last)
+			}
+			return err
+		}
+
+		totalFilms += resp.Films
+		if *dryRun {
+			for _, e := range resp.Events {
+				fmt.Printf("%-15s %s  %s\n", e.Type, e.FilmID, e.OccurredAt)
+			}
+			totalEvents += len(resp.Events)
+		} else {
+			totalEvents += resp.Emitted
+			fmt.Printf("Emitted %d events for %d films\n", resp.Emitted, resp.Films)
+		}
+
+		if resp.NextAfterID == nil {
+			break
+		}
+		body["after_id"] = *resp.NextAfterID
+	}
+
+	if *dryRun {
+		fmt.Printf("Dry run: %d events for %d films would be emitted\n", totalEvents, totalFilms)
+	} else {
+		fmt.Printf("Done: emitted %d events for %d films\n", totalEvents, totalFilms)
+	}
+	return nil
+}
+
+// splitFlag splits a comma-separated flag value, dropping empty entries
+func splitFlag(value string) []string {
+	out := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
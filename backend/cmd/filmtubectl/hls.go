@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// repairTask is the part of a worker task the repair report reads
+type repairTask struct {
+	ID     string            `json:"id"`
+	FilmID string            `json:"film_id"`
+	Status string            `json:"status"`
+	Error  string            `json:"error"`
+	Result map[string]string `json:"result"`
+}
+
+func runHLSRepair(args []string) error {
+	fs := flag.NewFlagSet("hls repair", flag.ExitOnError)
+	films := fs.String("film", "", "comma-separated film IDs to repair (default: every ready film)")
+	apiURL := fs.String("api", getEnv("FILMTUBE_API_URL", "http://localhost:8080"), "API base URL")
+	token := fs.String("token", os.Getenv("FILMTUBE_TOKEN"), "API token")
+	noWait := fs.Bool("no-wait", false, "exit after queueing instead of waiting for the report")
+	fs.Parse(args)
+
+	if *token == "" {
+		return errors.New("--token or FILMTUBE_TOKEN is required")
+	}
+
+	filmIDs := []string{}
+	for _, id := range strings.Split(*films, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			filmIDs = append(filmIDs, id)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := newAPIClient(*apiURL, *token)
+
+	var queued struct {
+		Tasks []repairTask `json:"tasks"`
+	}
+	err := client.do(ctx, http.MethodPost, "/api/admin/hls-repair", map[string]interface{}{
+		"film_ids": filmIDs,
+	}, &queued)
+	if err != nil {
+		return fmt.Errorf("failed to queue repair: %w", err)
+	}
+	fmt.Printf("Queued HLS repair for %d films\n", len(queued.Tasks))
+
+	if *noWait {
+		for _, task := range queued.Tasks {
+			fmt.Printf("  film %s: task %s\n", task.FilmID, task.ID)
+		}
+		return nil
+	}
+
+	tasks, err := waitForTasks(ctx, client, queued.Tasks)
+	if err != nil {
+		return err
+	}
+
+	repaired, failed := 0, 0
+	for _, task := range tasks {
+		r := task.Result
+		switch {
+		case task.Status == "FAILED":
+			failed++
+			fmt.Printf("FAILED    %s: %s\n", task.FilmID, task.Error)
+		case r["repaired"] == "true":
+			repaired++
+			fmt.Printf("REPAIRED  %s: %s of %s segments missing, %s re-uploaded, %s re-encoded\n",
+				task.FilmID, r["missing"], r["segments"], r["reuploaded"], r["reencoded"])
+		}
+	}
+	fmt.Printf("%d films checked, %d repaired, %d failed\n", len(tasks), repaired, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d films could not be repaired", failed)
+	}
+	return nil
+}
+
+// waitForTasks polls worker tasks until each has completed or failed
+func waitForTasks(ctx context.Context, client *apiClient, tasks []repairTask) ([]repairTask, error) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	done := make([]bool, len(tasks))
+	remaining := len(tasks)
+	for remaining > 0 {
+		for i := range tasks {
+			if done[i] {
+				continue
+			}
+			if err := client.do(ctx, http.MethodGet, "/api/tasks/"+tasks[i].ID, nil, &tasks[i]); err != nil {
+				return nil, err
+			}
+			if tasks[i].Status == "COMPLETED" || tasks[i].Status == "FAILED" {
+				done[i] = true
+				remaining--
+			}
+		}
+		if remaining == 0 {
+			break
+		}
+		fmt.Printf("\rWaiting for %d of %d films...", remaining, len(tasks))
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	fmt.Println()
+
+	return tasks, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `filmtubectl - FilmTube admin CLI
+
+Usage:
+  filmtubectl <command> <subcommand> [flags]
+
+Commands:
+  transcode submit   Upload a video file and follow it through transcoding
+  config export      Export settings and transcode profiles as a bundle
+  config import      Preview or apply a configuration bundle
+  hls repair         Find and repair missing HLS segments, then report
+  events backfill    Re-emit film lifecycle events for new consumers
+  search check       Diff the search index against Postgres per shard
+  search repair      Reindex only the films the search index diverges on
+
+Environment:
+  FILMTUBE_API_URL   API base URL (default http://localhost:8080)
+  FILMTUBE_TOKEN     JWT used to authenticate against the API
+`
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] + " " + os.Args[2] {
+	case "transcode submit":
+		err = runTranscodeSubmit(os.Args[3:])
+	case "config export":
+		err = runConfigExport(os.Args[3:])
+	case "config import":
+		err = runConfigImport(os.Args[3:])
+	case "hls repair":
+		err = runHLSRepair(os.Args[3:])
+	case "events backfill":
+		err = runEventsBackfill(os.Args[3:])
+	case "search check":
+		err = runSearchCheck(os.Args[3:])
+	case "search repair":
+		err = runSearchRepair(os.Args[3:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+)
+
+// searchShard is the part of a shard check the CLI reports
+type searchShard struct {
+	Shard         int      `json:"shard"`
+	DBCount       int      `json:"db_count"`
+	IndexCount    int      `json:"index_count"`
+	DBChecksum    string   `json:"db_checksum"`
+	IndexChecksum string   `json:"index_checksum"`
+	Missing       []string `json:"missing"`
+	Stale         []string `json:"stale"`
+	Orphaned      []string `json:"orphaned"`
+}
+
+// searchCheck is the part of a consistency report the CLI reports
+type searchCheck struct {
+	Index      string        `json:"index"`
+	Watermark  string        `json:"watermark"`
+	Consistent bool          `json:"consistent"`
+	Divergent  int           `json:"divergent"`
+	Unsharded  int           `json:"unsharded"`
+	Shards     []searchShard `json:"shards"`
+}
+
+func runSearchCheck(args []string) error {
+	fs := flag.NewFlagSet("search check", flag.ExitOnError)
+	apiURL := fs.String("api", getEnv("FILMTUBE_API_URL", "http://localhost:8080"), "API base URL")
+	token := fs.String("token", os.Getenv("FILMTUBE_TOKEN"), "API token")
+	fs.Parse(args)
+
+	if *token == "" {
+		return errors.New("--token or FILMTUBE_TOKEN is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var report searchCheck
+	client := newAPIClient(*apiURL, *token)
+	if err := client.do(ctx, http.MethodPost, "/api/admin/search/check", nil, &report); err != nil {
+		return fmt.Errorf("failed to check search index: %w", err)
+	}
+	printSearchCheck(report)
+
+	if !report.Consistent {
+		return fmt.Errorf("index diverges from Postgres; run filmtubectl search repair")
+	}
+	return nil
+}
+
+func runSearchRepair(args []string) error {
+	fs := flag.NewFlagSet("search repair", flag.ExitOnError)
+	apiURL := fs.String("api", getEnv("FILMTUBE_API_URL", "http://localhost:8080"), "API base URL")
+	token := fs.String("token", os.Getenv("FILMTUBE_TOKEN"), "API token")
+	fs.Parse(args)
+
+	if *token == "" {
+		return errors.New("--token or FILMTUBE_TOKEN is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var out struct {
+		Check  searchCheck `json:"check"`
+		Repair struct {
+			Reindexed int      `json:"reindexed"`
+			Deleted   int      `json:"deleted"`
+			Failed    int      `json:"failed"`
+			Errors    []string `json:"errors"`
+		} `json:"repair"`
+	}
+	client := newAPIClient(*apiURL, *token)
+	if err := client.do(ctx, http.MethodPost, "/api/admin/search/repair", nil, &out); err != nil {
+		return fmt.Errorf("failed to repair search index: %w", err)
+	}
+	printSearchCheck(out.Check)
+
+	for _, msg := range out.Repair.Errors {
+		fmt.Printf("FAILED    %s\n", msg)
+	}
+	fmt.Printf("%d films reindexed, %d documents deleted, %d failed\n", out.Repair.Reindexed, out.Repair.Deleted, out.Repair.Failed)
+
+	if out.Repair.Failed > 0 {
+		return fmt.Errorf("%d films could not be repaired", out.Repair.Failed)
+	}
+	return nil
+}
+
+// printSearchCheck prints the divergent shards of a report and a summary
+func printSearchCheck(report searchCheck) {
+	for _, shard := range report.Shards {
+		if shard.DBCount == shard.IndexCount && shard.DBChecksum == shard.IndexChecksum {
+			continue
+		}
+		fmt.Printf("SHARD %x   %d in Postgres, %d indexed: %d missing, %d stale, %d orphaned\n",
+			shard.Shard, shard.DBCount, shard.IndexCount, len(shard.Missing), len(shard.Stale), len(shard.Orphaned))
+	}
+	if report.Unsharded > 0 {
+		fmt.Printf("%d documents were indexed without a shard\n", report.Unsharded)
+	}
+
+	watermark := report.Watermark
+	if watermark == "" {
+		watermark = "not set"
+	}
+	status := "consistent"
+	if !report.Consistent {
+		status = fmt.Sprintf("%d films divergent", report.Divergent)
+	}
+	fmt.Printf("Index %s (watermark %s): %s\n", report.Index, watermark, status)
+}
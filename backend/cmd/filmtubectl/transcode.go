@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// presignBatch is how many part URLs are asked for at a time, the most
+// the API hands out per request
+const presignBatch = 100
+
+// streamReconnectDelay is how long to wait before reopening a dropped
+// server-sent event stream
+const streamReconnectDelay = 2 * time.Second
+
+// submitState is persisted next to the source file so an interrupted
+// submission can be resumed by re-running the same command. The parts of
+// the multipart upload are recorded as they finish, so a resumed upload
+// skips them.
+type submitState struct {
+	FilmID     string         `json:"film_id"`
+	UploadID   string         `json:"upload_id,omitempty"`
+	PartSize   int64          `json:"part_size,omitempty"`
+	TotalParts int            `json:"total_parts,omitempty"`
+	Parts      []uploadedPart `json:"parts,omitempty"`
+	Uploaded   bool           `json:"uploaded"`
+	SHA256     string         `json:"sha256,omitempty"`
+	Confirmed  bool           `json:"confirmed"`
+}
+
+// uploadedPart is a part of the multipart upload that reached storage
+type uploadedPart struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+}
+
+func stateFilePath(file string) string {
+	return file + ".filmtube.json"
+}
+
+func loadSubmitState(file string) (*submitState, error) {
+	data, err := os.ReadFile(stateFilePath(file))
+	if errors.Is(err, os.ErrNotExist) {
+		return &submitState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state submitState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("corrupt state file %s: %w", stateFilePath(file), err)
+	}
+	return &state, nil
+}
+
+func (s *submitState) save(file string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(stateFilePath(file), data, 0o644)
+}
+
+func runTranscodeSubmit(args []string) error {
+	fs := flag.NewFlagSet("transcode submit", flag.ExitOnError)
+	file := fs.String("file", "", "path to the source video (required)")
+	title := fs.String("title", "", "film title (defaults to the file name)")
+	description := fs.String("description", "", "film description")
+	filmType := fs.String("type", "FEATURE_FILM", "film type: SHORT_FILM or FEATURE_FILM")
+	apiURL := fs.String("api", getEnv("FILMTUBE_API_URL", "http://localhost:8080"), "API base URL")
+	token := fs.String("token", os.Getenv("FILMTUBE_TOKEN"), "API token")
+	noWait := fs.Bool("no-wait", false, "exit after confirming the upload instead of following transcoding")
+	fs.Parse(args)
+
+	if *file == "" {
+		return errors.New("--file is required")
+	}
+	if *token == "" {
+		return errors.New("--token or FILMTUBE_TOKEN is required")
+	}
+	if *title == "" {
+		base := filepath.Base(*file)
+		*title = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := newAPIClient(*apiURL, *token)
+
+	state, err := loadSubmitState(*file)
+	if err != nil {
+		return err
+	}
+
+	// Step 1: create the film (skipped when resuming)
+	if state.FilmID == "" {
+		var film struct {
+			ID string `json:"id"`
+		}
+		err := client.do(ctx, http.MethodPost, "/api/films", map[string]string{
+			"title":       *title,
+			"description": *description,
+			"type":        *filmType,
+		}, &film)
+		if err != nil {
+			return fmt.Errorf("failed to create film: %w", err)
+		}
+		state.FilmID = film.ID
+		if err := state.save(*file); err != nil {
+			return err
+		}
+		fmt.Printf("Created film %s\n", state.FilmID)
+	} else {
+		fmt.Printf("Resuming submission for film %s\n", state.FilmID)
+	}
+
+	// Step 2: upload the source in parts
+	if !state.Uploaded {
+		if err := uploadSource(ctx, client, state, *file); err != nil {
+			return err
+		}
+		state.Uploaded = true
+		state.Parts = nil
+		if err := state.save(*file); err != nil {
+			return err
+		}
+	}
+
+	// Step 3: confirm the upload, which enqueues transcoding
+	if !state.Confirmed {
+		var body interface{}
+		if state.SHA256 != "" {
+			body = map[string]string{"sha256": state.SHA256}
+		}
+		if err := client.do(ctx, http.MethodPost, "/api/films/"+state.FilmID+"/confirm-upload", body, nil); err != nil {
+			return fmt.Errorf("failed to confirm upload: %w", err)
+		}
+		state.Confirmed = true
+		if err := state.save(*file); err != nil {
+			return err
+		}
+		fmt.Println("Upload confirmed, transcoding queued")
+	}
+
+	if *noWait {
+		return nil
+	}
+
+	// Step 4: follow transcoding until the film is READY or FAILED
+	if err := waitForTranscode(ctx, client, state.FilmID); err != nil {
+		return err
+	}
+
+	os.Remove(stateFilePath(*file))
+	return nil
+}
+
+// uploadSource uploads the file as a multipart upload, resuming the one in
+// the state when the file is unchanged. Progress is followed from the
+// API's upload progress stream, which each recorded part updates.
+func uploadSource(ctx context.Context, client *apiClient, state *submitState, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(file)))
+	if !strings.HasPrefix(contentType, "video/") {
+		contentType = "video/mp4"
+	}
+
+	resumed := state.UploadID != "" && state.SHA256 == digest
+	if resumed {
+		fmt.Printf("Resuming upload: %d of %d parts already uploaded\n", len(state.Parts), state.TotalParts)
+	} else if err := startUpload(ctx, client, state, file, info.Size(), contentType, digest); err != nil {
+		return err
+	}
+
+	stopProgress := followUploadProgress(ctx, client, state.FilmID)
+	defer stopProgress()
+
+	err = uploadParts(ctx, client, state, file, f)
+	var apiErr *apiError
+	if resumed && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		fmt.Println("\nThe interrupted upload has expired, starting over")
+		if err := startUpload(ctx, client, state, file, info.Size(), contentType, digest); err != nil {
+			return err
+		}
+		err = uploadParts(ctx, client, state, file, f)
+	}
+	return err
+}
+
+// startUpload starts a multipart upload of the file and records it in the
+// state. Any unfinished upload of the film is discarded.
+func startUpload(ctx context.Context, client *apiClient, state *submitState, file string, size int64, contentType, digest string) error {
+	var upload struct {
+		UploadID   string `json:"upload_id"`
+		PartSize   int64  `json:"part_size"`
+		TotalParts int    `json:"total_parts"`
+	}
+	err := client.do(ctx, http.MethodPost, "/api/films/"+state.FilmID+"/upload-url", map[string]interface{}{
+		"file_size":    size,
+		"content_type": contentType,
+		"sha256":       digest,
+		"multipart":    true,
+	}, &upload)
+	if err != nil {
+		return fmt.Errorf("failed to start upload: %w", err)
+	}
+
+	state.UploadID = upload.UploadID
+	state.PartSize = upload.PartSize
+	state.TotalParts = upload.TotalParts
+	state.Parts = nil
+	state.SHA256 = digest
+	return state.save(file)
+}
+
+// uploadParts uploads the parts the state doesn't list yet, recording each
+// one as it finishes, then assembles the upload
+func uploadParts(ctx context.Context, client *apiClient, state *submitState, file string, f *os.File) error {
+	done := map[int]bool{}
+	for _, part := range state.Parts {
+		done[part.PartNumber] = true
+	}
+	pending := []int{}
+	for n := 1; n <= state.TotalParts; n++ {
+		if !done[n] {
+			pending = append(pending, n)
+		}
+	}
+
+	for len(pending) > 0 {
+		batch := pending
+		if len(batch) > presignBatch {
+			batch = batch[:presignBatch]
+		}
+		pending = pending[len(batch):]
+
+		var presigned struct {
+			Parts []struct {
+				PartNumber int    `json:"part_number"`
+				UploadURL  string `json:"upload_url"`
+				Size       int64  `json:"size"`
+			} `json:"parts"`
+		}
+		err := client.do(ctx, http.MethodPost, "/api/films/"+state.FilmID+"/multipart-upload/parts", map[string]interface{}{
+			"part_numbers": batch,
+		}, &presigned)
+		if err != nil {
+			return fmt.Errorf("failed to get upload part URLs: %w", err)
+		}
+
+		for _, part := range presigned.Parts {
+			offset := int64(part.PartNumber-1) * state.PartSize
+			etag, err := putPart(ctx, part.UploadURL, io.NewSectionReader(f, offset, part.Size), part.Size)
+			if err != nil {
+				return fmt.Errorf("failed to upload part %d: %w", part.PartNumber, err)
+			}
+			state.Parts = append(state.Parts, uploadedPart{PartNumber: part.PartNumber, ETag: etag})
+			if err := state.save(file); err != nil {
+				return err
+			}
+
+			// Recording the part notifies upload progress subscribers
+			err = client.do(ctx, http.MethodPost, "/api/films/"+state.FilmID+"/upload-parts", map[string]interface{}{
+				"part_number": part.PartNumber,
+				"size_bytes":  part.Size,
+				"etag":        etag,
+			}, nil)
+			if err != nil {
+				return fmt.Errorf("failed to record upload part: %w", err)
+			}
+		}
+	}
+
+	err := client.do(ctx, http.MethodPost, "/api/films/"+state.FilmID+"/multipart-upload/complete", map[string]interface{}{
+		"parts": state.Parts,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to complete upload: %w", err)
+	}
+	return nil
+}
+
+// putPart uploads one part to its pre-signed URL and returns the ETag
+// storage gave it
+func putPart(ctx context.Context, url string, body io.Reader, size int64) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", errors.New(resp.Status)
+	}
+
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", errors.New("storage returned no ETag")
+	}
+	return etag, nil
+}
+
+// followUploadProgress prints the film's upload progress from the API's
+// progress stream until the returned function is called
+func followUploadProgress(ctx context.Context, client *apiClient, filmID string) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for {
+			err := client.stream(ctx, "/api/films/"+filmID+"/upload-progress/stream", func(event string, data []byte) bool {
+				if event != "progress" {
+					return true
+				}
+				var progress struct {
+					TotalBytes     int64   `json:"total_bytes"`
+					UploadedBytes  int64   `json:"uploaded_bytes"`
+					TotalParts     int     `json:"total_parts"`
+					CompletedParts int     `json:"completed_parts"`
+					Percent        float64 `json:"percent"`
+				}
+				if err := json.Unmarshal(data, &progress); err == nil {
+					fmt.Printf("\rUploading: %5.1f%% (%d/%d parts, %d/%d bytes)", progress.Percent,
+						progress.CompletedParts, progress.TotalParts, progress.UploadedBytes, progress.TotalBytes)
+				}
+				return true
+			})
+			var apiErr *apiError
+			if errors.As(err, &apiErr) {
+				fmt.Printf("Upload progress unavailable: %v\n", err)
+				return
+			}
+
+			// The stream dropped, e.g. the API instance is shutting down
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(streamReconnectDelay):
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+		fmt.Println()
+	}
+}
+
+// waitForTranscode follows the film's status stream until transcoding
+// finishes, reconnecting when the stream drops
+func waitForTranscode(ctx context.Context, client *apiClient, filmID string) error {
+	lastStatus := ""
+	showingProgress := false
+	var result error
+	finished := false
+
+	for {
+		err := client.stream(ctx, "/api/films/"+filmID+"/transcode-status/stream", func(event string, data []byte) bool {
+			if event != "status" {
+				return true
+			}
+			var update struct {
+				Status   string `json:"status"`
+				Progress *int   `json:"progress"`
+			}
+			if err := json.Unmarshal(data, &update); err != nil {
+				return true
+			}
+
+			if update.Status != lastStatus {
+				if showingProgress {
+					fmt.Println()
+					showingProgress = false
+				}
+				fmt.Printf("Film status: %s\n", update.Status)
+				lastStatus = update.Status
+			}
+			if update.Progress != nil {
+				fmt.Printf("\rTranscoding: %3d%%", *update.Progress)
+				showingProgress = true
+			}
+
+			switch update.Status {
+			case "READY":
+				finished = true
+			case "FAILED":
+				finished = true
+				result = errors.New("transcoding failed")
+			}
+			return !finished
+		})
+		if finished {
+			return result
+		}
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			return err
+		}
+
+		// The stream dropped, e.g. the API instance is shutting down
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(streamReconnectDelay):
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/arjunaayasa/filmtube/internal/backup"
+	"github.com/arjunaayasa/filmtube/internal/config"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+)
+
+const usage = `restore - restore FilmTube platform data from a backup
+
+Usage:
+  restore list                                   List complete backups, newest first
+  restore verify <backup-id>                     Check a backup against the target database and bucket
+  restore run <backup-id> [--skip-playlists]     Verify, then restore tables and missing HLS playlists
+
+The target database and buckets come from the server's environment
+(DATABASE_URL, R2_*, BACKUP_BUCKET, BACKUP_ENCRYPTION_KEY). Restore into a
+freshly migrated database: tables that already hold rows are refused.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "list":
+		err = runList(os.Args[2:])
+	case "verify":
+		err = runVerify(os.Args[2:])
+	case "run":
+		err = runRestore(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("restore list", flag.ExitOnError)
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	backups, closeDB, err := newService()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	manifests, err := backups.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		fmt.Println("No backups found")
+		return nil
+	}
+	for _, m := range manifests {
+		var rows int64
+		for _, t := range m.Tables {
+			rows += t.Count
+		}
+		fmt.Printf("%s   %s   %d tables, %d rows, %d playlists, %d objects\n",
+			m.ID, m.CreatedAt.UTC().Format("2006-01-02 15:04:05"), len(m.Tables), rows, m.Playlists.Count, m.Inventory.Count)
+	}
+	return nil
+}
+
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("restore verify", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("usage: restore verify <backup-id>")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	backups, closeDB, err := newService()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	_, report, err := loadAndVerify(ctx, backups, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	printReport(report)
+	if err := report.Restorable(); err != nil {
+		return err
+	}
+	fmt.Println("Backup is restorable")
+	return nil
+}
+
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore run", flag.ExitOnError)
+	skipPlaylists := fs.Bool("skip-playlists", false, "restore only tables, leaving missing HLS playlists alone")
+	id := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if id == "" && fs.NArg() == 1 {
+		id = fs.Arg(0)
+	}
+	if id == "" {
+		return errors.New("usage: restore run <backup-id> [--skip-playlists]")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	backups, closeDB, err := newService()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	b, report, err := loadAndVerify(ctx, backups, id)
+	if err != nil {
+		return err
+	}
+	printReport(report)
+	if err := backups.Restore(ctx, b, report, *skipPlaylists); err != nil {
+		return err
+	}
+
+	restored := 0
+	if !*skipPlaylists {
+		restored = len(report.MissingPlaylists)
+	}
+	fmt.Printf("Restored %d tables and %d playlists from %s\n", len(report.Tables), restored, id)
+	if report.MissingObjects > 0 {
+		fmt.Printf("%d objects are still missing from the bucket and must be re-transcoded or re-uploaded\n", report.MissingObjects)
+	}
+	return nil
+}
+
+// newService connects to the target database and buckets from the server's
+// environment
+func newService() (*backup.Service, func(), error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+	if cfg.BackupBucket == "" {
+		return nil, nil, errors.New("BACKUP_BUCKET is not set")
+	}
+	key, err := backup.ParseKey(cfg.BackupEncryptionKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	database, err := db.Connect(cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to database: %w", err)
+	}
+	source, err := r2.New(cfg.R2Endpoint, cfg.R2AccessKeyID, cfg.R2SecretAccessKey, cfg.R2Bucket, cfg.R2Region, cfg.R2PublicURL)
+	if err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("initialize R2 client: %w", err)
+	}
+	source.SetPrivateBucket(cfg.R2PrivateBucket)
+	bucket, err := r2.New(cfg.R2Endpoint, cfg.R2AccessKeyID, cfg.R2SecretAccessKey, cfg.BackupBucket, cfg.R2Region, "")
+	if err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("initialize backup bucket client: %w", err)
+	}
+
+	backups := backup.New(db.NewQueries(database), source, bucket, nil, key, cfg.BackupHour, cfg.BackupRetentionDays)
+	return backups, func() { database.Close() }, nil
+}
+
+func loadAndVerify(ctx context.Context, backups *backup.Service, id string) (*backup.Backup, *backup.Report, error) {
+	fmt.Printf("Loading backup %s...\n", id)
+	b, err := backups.Load(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	report, err := backups.Verify(ctx, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, report, nil
+}
+
+func printReport(report *backup.Report) {
+	for _, t := range report.Tables {
+		line := fmt.Sprintf("TABLE %-32s %d rows", t.Table, t.Rows)
+		if t.Existing > 0 {
+			line += fmt.Sprintf(" (target already has %d)", t.Existing)
+		}
+		fmt.Println(line)
+	}
+	for _, v := range report.Violations {
+		fmt.Printf("FK    %s.%s -> %s.%s: %d unresolved (e.g. %s)\n",
+			v.Table, v.Column, v.RefTable, v.RefColumn, v.Missing, strings.Join(v.Examples, ", "))
+	}
+	if len(report.MissingPlaylists) > 0 {
+		fmt.Printf("%d HLS playlists are missing from the bucket and will be restored from the backup\n", len(report.MissingPlaylists))
+	}
+	if report.MissingObjects > 0 {
+		fmt.Printf("%d objects are missing from the bucket (e.g. %s)\n",
+			report.MissingObjects, strings.Join(report.MissingObjectExamples, ", "))
+	}
+}
@@ -10,13 +10,44 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/arjunaayasa/filmtube/internal/accounts"
+	"github.com/arjunaayasa/filmtube/internal/admission"
+	"github.com/arjunaayasa/filmtube/internal/alerts"
+	"github.com/arjunaayasa/filmtube/internal/analytics"
 	"github.com/arjunaayasa/filmtube/internal/api"
+	"github.com/arjunaayasa/filmtube/internal/apikeys"
+	"github.com/arjunaayasa/filmtube/internal/approval"
 	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/backup"
+	"github.com/arjunaayasa/filmtube/internal/calendar"
+	"github.com/arjunaayasa/filmtube/internal/beacon"
+	"github.com/arjunaayasa/filmtube/internal/chaos"
+	"github.com/arjunaayasa/filmtube/internal/clientip"
+	"github.com/arjunaayasa/filmtube/internal/comments"
 	"github.com/arjunaayasa/filmtube/internal/config"
 	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/entitlements"
+	"github.com/arjunaayasa/filmtube/internal/geo"
+	"github.com/arjunaayasa/filmtube/internal/mail"
 	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/moderation"
+	"github.com/arjunaayasa/filmtube/internal/playbacklog"
+	"github.com/arjunaayasa/filmtube/internal/playerconfig"
+	"github.com/arjunaayasa/filmtube/internal/playlists"
+	"github.com/arjunaayasa/filmtube/internal/positions"
+	"github.com/arjunaayasa/filmtube/internal/press"
+	"github.com/arjunaayasa/filmtube/internal/pruning"
 	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/reaper"
+	"github.com/arjunaayasa/filmtube/internal/recommend"
 	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/retention"
+	"github.com/arjunaayasa/filmtube/internal/search"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/arjunaayasa/filmtube/internal/shutdown"
+	"github.com/arjunaayasa/filmtube/internal/stats"
+	"github.com/arjunaayasa/filmtube/internal/timeline"
+	"github.com/arjunaayasa/filmtube/internal/webhooks"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/cors"
 )
@@ -63,6 +94,14 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to initialize R2 client: %v", err)
 	}
+	r2Client.SetPrivateBucket(cfg.R2PrivateBucket)
+	sourceBuckets, err := r2.ParseSourceBuckets(cfg.SourceBuckets)
+	if err != nil {
+		log.Fatalf("Invalid SOURCE_BUCKETS: %v", err)
+	}
+	if err := r2Client.SetSourceRegions(cfg.SourceRegion, sourceBuckets); err != nil {
+		log.Fatalf("Invalid SOURCE_REGION: %v", err)
+	}
 	log.Println("R2 client initialized successfully")
 
 	// Initialize JWT manager
@@ -71,13 +110,259 @@ func main() {
 	// Initialize queries
 	queries := db.NewQueries(database)
 
+	// Background services run until shutdown
+	appCtx, appCancel := context.WithCancel(context.Background())
+	defer appCancel()
+
+	// Graceful shutdown: drain traffic, then run flush hooks
+	drain := shutdown.New()
+
+	// Fault injection for resilience testing (CHAOS_ENABLED, staging only)
+	chaosInjector := chaos.New(redisClient, cfg.ChaosEnabled)
+	if cfg.ChaosEnabled {
+		redisClient.AddHook(chaosInjector.RedisHook())
+		r2Client.InjectFaults(chaosInjector.R2Fault)
+		go chaosInjector.RunSync(appCtx, 5*time.Second)
+	}
+
+	// Initialize settings service and keep caches in sync across instances
+	settingsService := settings.New(queries, redisClient)
+	go settingsService.Listen(appCtx)
+
+	// Correct any drift in the maintained film counters
+	go stats.RunCountReconciler(appCtx, queries, time.Hour)
+
+	// Roll up how long uploads take to become ready films
+	go stats.RunUploadLatencyRollup(appCtx, queries, 15*time.Minute)
+
+	// Roll up platform-wide daily stats for admin analytics each night
+	platformRollup := stats.NewPlatformRollup(queries, redisClient)
+	go platformRollup.RunLoop(appCtx, time.Hour)
+
+	// Rebuild the "most watched" day/week/month counters
+	topFilms := stats.NewTopFilmsAggregator(queries, redisClient)
+	go topFilms.RunLoop(appCtx, cfg.TopFilmsInterval)
+
+	// Apply analytics retention: rollups, anonymization, deletion, compaction
+	analyticsLifecycle := analytics.NewLifecycle(queries, settingsService)
+	go analyticsLifecycle.RunLoop(appCtx, time.Hour)
+
+	// Back up platform data nightly to a separate, encrypted bucket
+	var backupBucket *r2.Client
+	var backupKey []byte
+	if cfg.BackupBucket != "" {
+		backupKey, err = backup.ParseKey(cfg.BackupEncryptionKey)
+		if err != nil {
+			log.Fatalf("Invalid BACKUP_ENCRYPTION_KEY: %v", err)
+		}
+		backupBucket, err = r2.New(cfg.R2Endpoint, cfg.R2AccessKeyID, cfg.R2SecretAccessKey, cfg.BackupBucket, cfg.R2Region, "")
+		if err != nil {
+			log.Fatalf("Failed to initialize backup bucket client: %v", err)
+		}
+	}
+	backups := backup.New(queries, r2Client, backupBucket, redisClient, backupKey, cfg.BackupHour, cfg.BackupRetentionDays)
+	go backups.RunLoop(appCtx, 10*time.Minute)
+
+	// Export the event stream to an external analytics store when enabled
+	analyticsSink, err := analytics.NewSink(analytics.SinkConfig{
+		Sink:                    cfg.AnalyticsSink,
+		ClickHouseURL:           cfg.ClickHouseURL,
+		ClickHouseDatabase:      cfg.ClickHouseDatabase,
+		ClickHouseUsername:      cfg.ClickHouseUsername,
+		ClickHousePassword:      cfg.ClickHousePassword,
+		BigQueryProject:         cfg.BigQueryProject,
+		BigQueryDataset:         cfg.BigQueryDataset,
+		BigQueryCredentialsFile: cfg.BigQueryCredentialsFile,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize analytics sink: %v", err)
+	}
+	if analyticsSink != nil {
+		exporter := analytics.NewExporter(queries, redisClient, analyticsSink, cfg.AnalyticsHashSalt, cfg.AnalyticsSinkBatchSize)
+		go exporter.RunLoop(appCtx, cfg.AnalyticsSinkInterval)
+		drain.OnDrain("analytics sink export", exporter.Flush)
+		log.Printf("Analytics sink: %s", analyticsSink.Source())
+	}
+
+	// Live viewing counters for the creator realtime dashboard
+	analyticsRealtime := analytics.NewRealtime(queries, redisClient)
+
+	// Precompute recommendation candidates for active viewers
+	recommender, err := recommend.NewRecommender(recommend.Config{
+		Recommender: cfg.Recommender,
+		URL:         cfg.RecommenderURL,
+		Timeout:     cfg.RecommenderTimeout,
+	}, queries)
+	if err != nil {
+		log.Fatalf("Failed to initialize recommender: %v", err)
+	}
+	recommendEngine := recommend.New(queries, redisClient, settingsService, recommender)
+	log.Printf("Recommender: %s", recommender.Name())
+	go recommendEngine.RunBatch(appCtx, cfg.RecommendationsInterval)
+
+	// Initialize search backend and indexer
+	searchBackend, err := search.New(search.Config{
+		Backend:            cfg.SearchBackend,
+		OpenSearchURL:      cfg.OpenSearchURL,
+		OpenSearchIndex:    cfg.OpenSearchIndex,
+		OpenSearchUsername: cfg.OpenSearchUsername,
+		OpenSearchPassword: cfg.OpenSearchPassword,
+	}, queries)
+	if err != nil {
+		log.Fatalf("Failed to initialize search backend: %v", err)
+	}
+	indexer := search.NewIndexer(searchBackend, queries)
+	log.Printf("Search backend: %s", cfg.SearchBackend)
+
+	// Serve player configuration from rules reloaded on every instance
+	playerConfig := playerconfig.New(queries)
+	go playerConfig.RunSync(appCtx, 30*time.Second)
+
+	// Viewers' plans cap the renditions they are served
+	entitlementService := entitlements.New(queries, settingsService)
+
+	// Keep a separate search index in sync and check it against Postgres
+	searchSyncer := search.NewSyncer(searchBackend, queries, redisClient)
+	go searchSyncer.RunLoop(appCtx, 30*time.Second)
+
+	// Initialize country detection for region-restricted titles
+	var geoDB *geo.Database
+	if cfg.GeoIPCSVPath != "" {
+		geoDB, err = geo.LoadCSV(cfg.GeoIPCSVPath)
+		if err != nil {
+			log.Fatalf("Failed to load GeoIP database: %v", err)
+		}
+		log.Printf("GeoIP database loaded (%d ranges)", geoDB.Len())
+	}
+
+	// Find the real client IP behind trusted proxies and the CDN
+	ipResolver, err := clientip.New(cfg.TrustedProxies, cfg.ClientIPHeaders)
+	if err != nil {
+		log.Fatalf("Invalid client IP configuration: %v", err)
+	}
+	geoResolver := geo.NewResolver(cfg.GeoCountryHeader, ipResolver, geoDB)
+
+	// Press screeners are deleted once access ends
+	pressService := press.New(queries, r2Client, redisClient)
+	go pressService.RunExpiryLoop(appCtx, 5*time.Minute)
+
+	// Publish films whose scheduled release on the content calendar has come
+	approvalWorkflow := approval.NewWorkflow(queries)
+	releasePublisher := calendar.NewPublisher(queries, approvalWorkflow, indexer, redisClient)
+	go releasePublisher.RunLoop(appCtx, time.Minute)
+
+	// Free storage by pruning renditions of old, rarely watched films
+	renditionPruner := pruning.New(queries, redisClient, settingsService)
+	go renditionPruner.RunLoop(appCtx, 24*time.Hour)
+
+	// Expire films whose upload was abandoned and free what it stored
+	uploadReaper := reaper.New(queries, redisClient, r2Client, settingsService)
+	go uploadReaper.RunLoop(appCtx, time.Hour)
+
+	// Delete or archive the sources of ready films per the retention policy
+	sourceRetention := retention.New(queries, redisClient, r2Client, settingsService)
+	go sourceRetention.RunLoop(appCtx, time.Hour)
+
+	// Alert users when newly published films match their saved searches
+	mailer := mail.New(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	savedSearchMatcher := alerts.NewMatcher(queries, redisClient, mailer, mail.NewTemplates(queries), cfg.AppURL)
+	go savedSearchMatcher.RunLoop(appCtx, cfg.SavedSearchInterval)
+
+	// Playback sessions sign the analytics beacons they send
+	beaconSigner := beacon.NewSigner(cfg.BeaconSecret)
+
+	// Playback requests are logged briefly for support investigations
+	playbackLogger := playbacklog.New(queries, settingsService)
+	go playbackLogger.RunRetentionLoop(appCtx, time.Hour)
+
+	// Player heartbeats land in Redis and are flushed to Postgres
+	positionStore := positions.New(queries, redisClient)
+	go positionStore.RunFlushLoop(appCtx, 30*time.Second)
+	drain.OnDrain("playback position flush", func(ctx context.Context) error {
+		_, err := positionStore.Flush(ctx)
+		return err
+	})
+
+	// API key usage is counted in Redis and flushed to Postgres
+	apiKeys := apikeys.New(queries, redisClient, settingsService)
+	go apiKeys.RunFlushLoop(appCtx, time.Minute)
+	drain.OnDrain("API key usage flush", func(ctx context.Context) error {
+		_, err := apiKeys.Flush(ctx)
+		return err
+	})
+
+	// Film events are sent on to users' webhook endpoints
+	webhookDispatcher := webhooks.New(queries, redisClient, settingsService)
+	go webhookDispatcher.RunLoop(appCtx, 5*time.Second)
+
+	// Deleted accounts' kept films go to the ghost account
+	accountDeleter := accounts.NewDeleter(queries, r2Client, searchBackend, indexer)
+
+	// Smart playlists are evaluated on read and cached
+	playlistService := playlists.NewService(queries, redisClient)
+
+	// Users @mentioned in comments are notified once the comment is visible
+	commentMentions := comments.NewMentions(queries)
+
+	// Film timelines are generated after transcode and cached
+	timelineBuilder := timeline.New(queries, r2Client, redisClient)
+
+	// Reject or defer confirmed uploads while transcoding is saturated
+	admissionControl := admission.New(queries, redisClient, indexer, settingsService)
+	go admissionControl.RunLoop(appCtx, 15*time.Second)
+
 	// Initialize handlers
-	authHandler := api.NewAuthHandler(queries, jwtManager)
-	filmHandler := api.NewFilmHandler(queries, r2Client, redisClient, int(cfg.UploadURLExpiration.Minutes()))
+	authHandler := api.NewAuthHandler(queries, jwtManager, settingsService)
+	filmHandler := api.NewFilmHandler(queries, r2Client, redisClient, searchBackend, indexer, approvalWorkflow, pressService, settingsService, beaconSigner, commentMentions, playbackLogger, positionStore, entitlementService, admissionControl, cfg.APIURL, int(cfg.UploadURLExpiration.Minutes()))
+	bootstrapHandler := api.NewBootstrapHandler(queries, cfg.BootstrapToken)
+	settingsHandler := api.NewSettingsHandler(settingsService)
+	statsHandler := api.NewStatsHandler(queries)
+	metricsHandler := api.NewMetricsHandler(queries, cfg.MetricsToken)
+	analyticsHandler := api.NewAnalyticsHandler(queries, settingsService, analyticsLifecycle, analyticsSink, analyticsRealtime, beaconSigner)
+	recommendationHandler := api.NewRecommendationHandler(queries, recommendEngine, settingsService, positionStore)
+	organizationHandler := api.NewOrganizationHandler(queries)
+	notificationHandler := api.NewNotificationHandler(queries)
+	savedSearchHandler := api.NewSavedSearchHandler(queries)
+	chaosHandler := api.NewChaosHandler(chaosInjector)
+	pruningHandler := api.NewPruningHandler(queries, renditionPruner)
+	sourceRetentionHandler := api.NewSourceRetentionHandler(queries, sourceRetention)
+	searchSyncHandler := api.NewSearchSyncHandler(searchSyncer)
+	playerConfigHandler := api.NewPlayerConfigHandler(queries, playerConfig)
+	entitlementHandler := api.NewEntitlementHandler(queries, entitlementService)
+	backupHandler := api.NewBackupHandler(queries, backups)
+	catalogImportHandler := api.NewCatalogImportHandler(queries, redisClient, r2Client, settingsService)
+	qualityHandler := api.NewQualityHandler(queries, redisClient, settingsService)
+	workerHandler := api.NewWorkerHandler(redisClient)
+	apiKeyHandler := api.NewAPIKeyHandler(queries, redisClient, apiKeys)
+	accountHandler := api.NewAccountHandler(queries, accountDeleter)
+	webhookHandler := api.NewWebhookHandler(queries, webhookDispatcher)
+	playbackLogHandler := api.NewPlaybackLogHandler(queries)
+	adminListingHandler := api.NewAdminListingHandler(queries, redisClient, r2Client)
+	reportHandler := api.NewReportHandler(queries)
+	apiRateLimit := api.APIRateLimit(redisClient, settingsService)
+	reportRateLimit := api.ReportRateLimit(redisClient, settingsService)
+	commentRateLimit := api.CommentRateLimit(redisClient, settingsService)
+	emailTemplateHandler := api.NewEmailTemplateHandler(queries)
+	moderationHandler := api.NewModerationHandler(queries, moderation.NewQueue(queries, indexer, settingsService))
+	timelineHandler := api.NewTimelineHandler(queries, timelineBuilder)
+	eventHandler := api.NewEventHandler(queries, redisClient)
+	shareHandler := api.NewShareHandler(queries, cfg.AppURL)
+	oembedHandler := api.NewOEmbedHandler(queries, cfg.AppURL)
+	feedHandler := api.NewFeedHandler(queries, redisClient, playlistService, cfg.AppURL)
+	reactionHandler := api.NewReactionHandler(queries)
+	watchlistHandler := api.NewWatchlistHandler(queries)
+	reviewHandler := api.NewReviewHandler(queries, settingsService)
+	commentHandler := api.NewCommentHandler(queries, settingsService, commentMentions)
+	playlistHandler := api.NewPlaylistHandler(queries, playlistService)
+	purchaseHandler := api.NewPurchaseHandler(queries, r2Client, redisClient, settingsService, cfg.PaymentsWebhookSecret)
 
 	// Setup Gin
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
+	// Client IPs come from ClientIPMiddleware; gin must not trust headers itself
+	if err := router.SetTrustedProxies(nil); err != nil {
+		log.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
 	router.Use(gin.Recovery())
 	router.Use(gin.Logger())
 
@@ -85,7 +370,7 @@ func main() {
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:3000", "http://localhost:3001"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Origin", "Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Origin", "Content-Type", "Authorization", "X-Bootstrap-Token"},
 		AllowCredentials: true,
 		MaxAge:          86400,
 	})
@@ -93,18 +378,45 @@ func main() {
 		corsHandler.HandlerFunc(c.Writer, c.Request)
 		c.Next()
 	})
+	router.Use(api.DrainMiddleware(drain))
+	router.Use(api.ClientIPMiddleware(ipResolver))
+	router.Use(api.CountryMiddleware(geoResolver))
 
 	// Health check
+	// Fails once shutdown begins so load balancers stop routing here
 	router.GET("/health", func(c *gin.Context) {
+		if drain.Draining() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":  "draining",
+				"service": "filmtube-api",
+			})
+			return
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"status": "healthy",
 			"service": "filmtube-api",
 		})
 	})
 
+	// Prometheus metrics, for scrapers holding METRICS_TOKEN
+	router.GET("/metrics", metricsHandler.GetMetrics)
+
+	// Sitemap and RSS feeds, served outside /api for crawlers and readers
+	router.GET("/sitemap.xml", feedHandler.GetSitemap)
+	router.GET("/feeds/films.rss", feedHandler.GetFilmsFeed)
+	router.GET("/feeds/creators/:id/films.rss", feedHandler.GetCreatorFeed)
+	router.GET("/feeds/playlists/:id/films.rss", feedHandler.GetPlaylistFeed)
+
+	// oEmbed for film, embed and share link URLs
+	router.GET("/oembed", oembedHandler.GetOEmbed)
+
 	// Public routes
 	public := router.Group("/api")
+	public.Use(apiRateLimit)
 	{
+		// First-run setup (guarded by BOOTSTRAP_TOKEN)
+		public.POST("/bootstrap", bootstrapHandler.Bootstrap)
+
 		// Auth routes
 		auth := public.Group("/auth")
 		{
@@ -112,30 +424,329 @@ func main() {
 			auth.POST("/login", authHandler.Login)
 		}
 
-		// Public film routes (browse)
+		// Public film routes (browse); listings and film details accept a
+		// token so ?exclude_watched=true can hide films the viewer has
+		// finished, responses carry the viewer's own reaction and private
+		// films show to their creator
+		optionalAuth := api.OptionalAuthMiddleware(jwtManager)
 		films := public.Group("/films")
 		{
-			films.GET("", filmHandler.ListFilms)
-			films.GET("/:id", filmHandler.GetFilm)
-			films.GET("/:id/playback", filmHandler.GetPlaybackURL)
+			films.GET("", optionalAuth, filmHandler.ListFilms)
+			films.GET("/search", filmHandler.SearchFilms)
+			films.GET("/discover", optionalAuth, filmHandler.DiscoverFilms)
+			films.GET("/top", optionalAuth, filmHandler.GetTopFilms)
+			films.GET("/:id", optionalAuth, filmHandler.GetFilm)
+			films.GET("/:id/playback", optionalAuth, filmHandler.GetPlaybackURL)
+			films.GET("/:id/manifest.m3u8", filmHandler.GetPlaybackManifest)
+			films.GET("/:id/related", recommendationHandler.GetRelatedFilms)
+			films.GET("/:id/subtitles", filmHandler.ListSubtitles)
+			films.GET("/:id/reviews", reviewHandler.ListFilmReviews)
+			films.GET("/:id/comments", optionalAuth, commentHandler.ListComments)
+			films.GET("/:id/comments/:commentId/replies", optionalAuth, commentHandler.ListReplies)
+			films.POST("/:id/share", shareHandler.ShareFilm)
+		}
+
+		// Short, time-coded share links
+		public.GET("/share/:code", shareHandler.GetShareLink)
+
+		// Playlists (private ones only for their owner)
+		public.GET("/playlists/:id", optionalAuth, playlistHandler.GetPlaylist)
+		public.GET("/playlists/:id/next", optionalAuth, playlistHandler.GetNextFilm)
+
+		// Home page hero
+		public.GET("/featured", filmHandler.GetFeatured)
+
+		// Payment provider callbacks (guarded by PAYMENTS_WEBHOOK_SECRET)
+		public.POST("/payments/purchases", purchaseHandler.RecordPurchase)
+		public.POST("/payments/refunds", purchaseHandler.RecordRefund)
+
+		// Download-to-own license verification
+		public.GET("/licenses/:key", purchaseHandler.GetLicense)
+
+		// Player configuration for mobile, TV and web clients
+		public.GET("/player/config", optionalAuth, playerConfigHandler.GetPlayerConfig)
+
+		// Analytics beacons (user attached when authenticated)
+		tracking := public.Group("/analytics")
+		tracking.Use(api.OptionalAuthMiddleware(jwtManager))
+		{
+			tracking.POST("/events", analyticsHandler.TrackEvents)
 		}
 	}
 
 	// Protected routes (require authentication)
 	protected := router.Group("/api")
-	protected.Use(api.AuthMiddleware(jwtManager))
+	protected.Use(api.APIKeyMiddleware(apiKeys), api.AuthMiddleware(jwtManager, api.NewBanCache(queries)), apiRateLimit)
 	{
 		// User routes
 		protected.GET("/auth/me", authHandler.GetMe)
+		protected.GET("/entitlements", entitlementHandler.ListMyEntitlements)
+		protected.PUT("/auth/me/handle", authHandler.SetHandle)
+		protected.PUT("/auth/me/birth-date", authHandler.SetBirthDate)
+		protected.DELETE("/auth/me", accountHandler.DeleteMe)
+		protected.GET("/tasks/:id", filmHandler.GetTask)
+
+		// API keys
+		protected.GET("/keys", apiKeyHandler.ListAPIKeys)
+		protected.POST("/keys", apiKeyHandler.CreateAPIKey)
+		protected.DELETE("/keys/:id", apiKeyHandler.RevokeAPIKey)
+		protected.GET("/keys/:id/usage", apiKeyHandler.GetAPIKeyUsage)
+
+		// Viewing signals and recommendations
+		protected.POST("/films/:id/watch", recommendationHandler.RecordWatch)
+		protected.POST("/films/:id/like", reactionHandler.LikeFilm)
+		protected.POST("/films/:id/dislike", reactionHandler.DislikeFilm)
+		protected.POST("/films/:id/watchlist", watchlistHandler.AddToWatchlist)
+		protected.DELETE("/films/:id/watchlist", watchlistHandler.RemoveFromWatchlist)
+		protected.GET("/films/:id/review", reviewHandler.GetMyReview)
+		protected.PUT("/films/:id/review", reviewHandler.SaveReview)
+		protected.DELETE("/films/:id/review", reviewHandler.DeleteReview)
+		protected.POST("/reviews/:id/flag", reviewHandler.FlagReview)
+		protected.POST("/films/:id/comments", commentRateLimit, commentHandler.CreateComment)
+		protected.PATCH("/films/:id/comments/:commentId", commentHandler.EditComment)
+		protected.DELETE("/films/:id/comments/:commentId", commentHandler.DeleteComment)
+		protected.POST("/films/:id/comments/:commentId/report", reportRateLimit, commentHandler.ReportComment)
+		protected.POST("/films/:id/report", reportRateLimit, reportHandler.ReportFilm)
+		protected.POST("/creators/:id/follow", recommendationHandler.FollowCreator)
+		protected.DELETE("/creators/:id/follow", recommendationHandler.UnfollowCreator)
+		protected.GET("/recommendations", recommendationHandler.GetRecommendations)
+		protected.PUT("/films/:id/position", recommendationHandler.UpdatePlaybackPosition)
+
+		// Personal rails
+		my := protected.Group("/my")
+		{
+			my.GET("/continue-watching", recommendationHandler.GetContinueWatching)
+			my.GET("/new-from-follows", recommendationHandler.GetNewFromFollows)
+			my.GET("/notifications", notificationHandler.ListNotifications)
+			my.POST("/notifications/read", notificationHandler.MarkAllNotificationsRead)
+			my.POST("/notifications/:id/read", notificationHandler.MarkNotificationRead)
+			my.GET("/purchases", purchaseHandler.ListMyPurchases)
+			my.POST("/purchases/:id/download", purchaseHandler.DownloadPurchase)
+			my.POST("/purchases/:id/retry", purchaseHandler.RetryPurchase)
+			my.GET("/saved-searches", savedSearchHandler.ListSavedSearches)
+			my.POST("/saved-searches", savedSearchHandler.CreateSavedSearch)
+			my.PUT("/saved-searches/:id", savedSearchHandler.UpdateSavedSearch)
+			my.DELETE("/saved-searches/:id", savedSearchHandler.DeleteSavedSearch)
+			my.GET("/playlists", playlistHandler.ListMyPlaylists)
+			my.GET("/watchlist", watchlistHandler.ListWatchlist)
+			my.GET("/films/:id/analytics", analyticsHandler.GetFilmAnalyticsSeries)
+			my.GET("/email-templates", api.RequireCreator(), emailTemplateHandler.ListMyTemplates)
+			my.PUT("/email-templates/:key", api.RequireCreator(), emailTemplateHandler.SaveMyTemplate)
+			my.DELETE("/email-templates/:key", api.RequireCreator(), emailTemplateHandler.DeleteMyTemplate)
+		}
+
+		// Email templates and previews
+		protected.GET("/email-templates", emailTemplateHandler.ListSystemTemplates)
+		protected.POST("/email-templates/preview", emailTemplateHandler.PreviewTemplate)
+
+		// Playlists
+		protected.POST("/playlists", playlistHandler.CreatePlaylist)
+		protected.PUT("/playlists/:id", playlistHandler.UpdatePlaylist)
+		protected.DELETE("/playlists/:id", playlistHandler.DeletePlaylist)
+		protected.POST("/playlists/:id/items", playlistHandler.AddPlaylistItem)
+		protected.PUT("/playlists/:id/items", playlistHandler.ReorderPlaylist)
+		protected.DELETE("/playlists/:id/items/:filmId", playlistHandler.RemovePlaylistItem)
+
+		// Organizations and publishing approval chains
+		protected.GET("/organizations", organizationHandler.ListMyOrganizations)
+		protected.POST("/organizations", api.RequireCreator(), organizationHandler.CreateOrganization)
+		protected.GET("/organizations/:id", organizationHandler.GetOrganization)
+		protected.PUT("/organizations/:id/approval-policy", organizationHandler.UpdateApprovalPolicy)
+		protected.PUT("/organizations/:id/privacy", organizationHandler.UpdatePrivacyMode)
+		protected.PUT("/organizations/:id/members/:userId", organizationHandler.SetMember)
+		protected.DELETE("/organizations/:id/members/:userId", organizationHandler.RemoveMember)
+		protected.GET("/organizations/:id/approvals", organizationHandler.ListPendingApprovals)
+		protected.GET("/organizations/:id/playback-plans", entitlementHandler.GetOrganizationPlans)
+		protected.PUT("/organizations/:id/playback-plans", entitlementHandler.SetOrganizationPlans)
+		protected.DELETE("/organizations/:id/playback-plans", entitlementHandler.DeleteOrganizationPlans)
+		protected.GET("/organizations/:id/entitlements", entitlementHandler.ListOrganizationEntitlements)
+		protected.PUT("/organizations/:id/entitlements/:userId", entitlementHandler.SetOrganizationEntitlement)
+		protected.DELETE("/organizations/:id/entitlements/:userId", entitlementHandler.DeleteOrganizationEntitlement)
+		protected.GET("/organizations/:id/email-templates", emailTemplateHandler.ListOrganizationTemplates)
+		protected.PUT("/organizations/:id/email-templates/:key", emailTemplateHandler.SaveOrganizationTemplate)
+		protected.DELETE("/organizations/:id/email-templates/:key", emailTemplateHandler.DeleteOrganizationTemplate)
+		protected.GET("/films/:id/approvals", filmHandler.GetFilmApprovals)
+		protected.POST("/films/:id/review", filmHandler.ReviewFilm)
+		protected.POST("/films/:id/approve", filmHandler.ApproveFilm)
+		protected.POST("/films/:id/reject", filmHandler.RejectFilm)
 
 		// Film management routes (require creator role)
 		films := protected.Group("/films")
 		films.Use(api.RequireCreator())
+		// New uploads are refused once shutdown begins
+		acceptingUploads := api.RejectWhileDraining(drain)
 		{
 			films.POST("", filmHandler.CreateFilm)
-			films.POST("/:id/upload-url", filmHandler.GetUploadURL)
-			films.POST("/:id/confirm-upload", filmHandler.ConfirmUpload)
+			films.POST("/:id/upload-preflight", filmHandler.UploadPreflight)
+			films.POST("/:id/upload-url", acceptingUploads, filmHandler.GetUploadURL)
+			films.POST("/:id/import-url", acceptingUploads, filmHandler.ImportURL)
+			films.POST("/:id/multipart-upload/parts", acceptingUploads, filmHandler.PresignUploadParts)
+			films.POST("/:id/multipart-upload/complete", filmHandler.CompleteMultipartUpload)
+			films.DELETE("/:id/multipart-upload", filmHandler.AbortMultipartUpload)
+			films.POST("/:id/upload-parts", filmHandler.ConfirmUploadPart)
+			films.GET("/:id/upload-progress", filmHandler.GetUploadProgress)
+			films.GET("/:id/upload-progress/stream", filmHandler.StreamUploadProgress)
+			films.GET("/:id/transcode-status/stream", filmHandler.StreamTranscodeStatus)
+			films.POST("/:id/confirm-upload", acceptingUploads, filmHandler.ConfirmUpload)
 			films.POST("/:id/publish", filmHandler.PublishFilm)
+			films.PUT("/:id/regions", filmHandler.UpdateFilmRegions)
+			films.PUT("/:id/embed", filmHandler.UpdateEmbedSettings)
+			films.PUT("/:id/organization", filmHandler.SetFilmOrganization)
+			films.POST("/:id/submit", filmHandler.SubmitFilmForApproval)
+			films.POST("/:id/subtitles", filmHandler.ImportSubtitles)
+			films.POST("/:id/burn-in", filmHandler.RequestBurnIn)
+			films.GET("/:id/timeline", timelineHandler.GetTimeline)
+			films.GET("/:id/markers", timelineHandler.ListMarkers)
+			films.PUT("/:id/markers", timelineHandler.SetMarkers)
+			films.POST("/:id/audio/upload-url", acceptingUploads, filmHandler.GetAudioUploadURL)
+			films.POST("/:id/audio/replace", filmHandler.ReplaceAudio)
+			films.GET("/:id/lut", filmHandler.GetFilmLUT)
+			films.POST("/:id/lut", filmHandler.UploadFilmLUT)
+			films.DELETE("/:id/lut", filmHandler.DeleteFilmLUT)
+			films.POST("/:id/lut/preview", filmHandler.RequestLUTPreview)
+			films.PUT("/:id/press/embargo", filmHandler.SetPressEmbargo)
+			films.GET("/:id/press", filmHandler.ListPressAccess)
+			films.POST("/:id/press", filmHandler.GrantPressAccess)
+			films.DELETE("/:id/press/:userId", filmHandler.RevokePressAccess)
+			films.PUT("/:id/download-price", filmHandler.SetDownloadPrice)
+			films.POST("/:id/comments/import", filmHandler.ImportComments)
+			films.POST("/:id/comments/export", filmHandler.ExportComments)
+			films.GET("/:id/comments/export/:taskId", filmHandler.GetCommentExport)
+			films.PUT("/:id/policy", filmHandler.UpdateFilmPolicy)
+			films.PUT("/:id/owner-deletion", filmHandler.SetFilmOwnerDeletion)
+			films.PUT("/:id/comment-settings", filmHandler.UpdateCommentSettings)
+			films.GET("/:id/comments/held", filmHandler.ListHeldComments)
+			films.PUT("/:id/comments/:commentId/status", filmHandler.SetCommentStatus)
+			films.PUT("/:id/comments/:commentId/pin", filmHandler.PinComment)
+			films.DELETE("/:id/comments/:commentId/pin", filmHandler.UnpinComment)
+		}
+
+		// Bulk imports of existing catalogs (require creator role)
+		imports := protected.Group("/imports")
+		imports.Use(api.RequireCreator())
+		{
+			imports.POST("", acceptingUploads, catalogImportHandler.CreateCatalogImport)
+			imports.GET("", catalogImportHandler.ListCatalogImports)
+			imports.GET("/:id", catalogImportHandler.GetCatalogImport)
+			imports.POST("/:id/retry", acceptingUploads, catalogImportHandler.RetryCatalogImport)
+		}
+
+		// Embargoed press screeners (require press role)
+		pressRoutes := protected.Group("/press")
+		pressRoutes.Use(api.RequirePress())
+		{
+			pressRoutes.GET("/films", filmHandler.ListPressFilms)
+			pressRoutes.GET("/films/:id/playback", filmHandler.GetPressPlayback)
+		}
+
+		// Webhook endpoints for film events, with delivery history
+		hooks := protected.Group("/webhooks")
+		hooks.Use(api.RequireCreator())
+		{
+			hooks.GET("", webhookHandler.ListWebhooks)
+			hooks.POST("", webhookHandler.CreateWebhook)
+			hooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			hooks.GET("/:id/health", webhookHandler.GetWebhookHealth)
+			hooks.POST("/:id/enable", webhookHandler.EnableWebhook)
+			hooks.POST("/:id/verify", webhookHandler.VerifyWebhookSignature)
+			hooks.GET("/:id/deliveries", webhookHandler.ListWebhookDeliveries)
+			hooks.POST("/:id/replay", webhookHandler.ReplayWebhookDeliveries)
+			hooks.GET("/:id/deliveries/:deliveryId", webhookHandler.GetWebhookDelivery)
+			hooks.POST("/:id/deliveries/:deliveryId/replay", webhookHandler.ReplayWebhookDelivery)
+		}
+
+		// Creator-wide defaults
+		creator := protected.Group("/creator")
+		creator.Use(api.RequireCreator())
+		{
+			creator.POST("/lut", filmHandler.UploadCreatorLUT)
+			creator.DELETE("/lut", filmHandler.DeleteCreatorLUT)
+			creator.GET("/analytics/realtime", analyticsHandler.StreamRealtime)
+			creator.GET("/analytics/films/:id", analyticsHandler.GetFilmAnalytics)
+			creator.GET("/analytics/films/:id/funnel", analyticsHandler.GetFilmFunnel)
+			creator.GET("/analytics/films/:id/revenue", analyticsHandler.GetFilmRevenue)
+			creator.GET("/analytics/egress", analyticsHandler.GetCreatorEgress)
+		}
+
+		// Admin routes (require admin role)
+		admin := protected.Group("/admin")
+		admin.Use(api.RequireAdmin())
+		{
+			admin.GET("/settings", settingsHandler.ListSettings)
+			admin.GET("/settings/:key", settingsHandler.GetSetting)
+			admin.PUT("/settings/:key", settingsHandler.UpdateSetting)
+			admin.DELETE("/settings/:key", settingsHandler.ResetSetting)
+			admin.GET("/config/export", settingsHandler.ExportConfig)
+			admin.POST("/config/import", settingsHandler.ImportConfig)
+			admin.POST("/lut", filmHandler.UploadPlatformLUT)
+			admin.DELETE("/lut", filmHandler.DeletePlatformLUT)
+			admin.POST("/hls-repair", filmHandler.RepairHLS)
+			admin.GET("/rendition-pruning", pruningHandler.GetPruning)
+			admin.POST("/rendition-pruning/run", pruningHandler.RunPruning)
+			admin.POST("/films/:id/renditions/restore", pruningHandler.RestoreRenditions)
+			admin.POST("/source-retention/run", sourceRetentionHandler.RunSourceRetention)
+			admin.PUT("/films/:id/source-retention", sourceRetentionHandler.SetSourceRetention)
+			admin.GET("/search/sync", searchSyncHandler.GetSearchSync)
+			admin.POST("/search/check", searchSyncHandler.CheckSearchIndex)
+			admin.POST("/search/repair", searchSyncHandler.RepairSearchIndex)
+			admin.GET("/player-config/rules", playerConfigHandler.ListPlayerConfigRules)
+			admin.POST("/player-config/rules", playerConfigHandler.CreatePlayerConfigRule)
+			admin.PUT("/player-config/rules/:id", playerConfigHandler.UpdatePlayerConfigRule)
+			admin.DELETE("/player-config/rules/:id", playerConfigHandler.DeletePlayerConfigRule)
+			admin.POST("/films/:id/retranscode", filmHandler.Retranscode)
+			admin.GET("/films/:id/retranscode/compare", filmHandler.CompareRetranscode)
+			admin.POST("/films/:id/retranscode/swap", filmHandler.SwapRetranscode)
+			admin.GET("/quality", qualityHandler.GetQuality)
+			admin.GET("/quality/alerts", qualityHandler.ListQualityAlerts)
+			admin.POST("/films/:id/quality-check", qualityHandler.RunQualityCheck)
+			admin.GET("/workers", workerHandler.GetWorkerRegions)
+			admin.GET("/stats/films/counts", statsHandler.GetFilmCounts)
+			admin.GET("/stats/upload-latency", statsHandler.GetUploadLatency)
+			admin.GET("/analytics", analyticsHandler.GetPlatformAnalytics)
+			admin.GET("/qoe", analyticsHandler.GetQoE)
+			admin.GET("/egress", analyticsHandler.GetPlatformEgress)
+			admin.POST("/egress/cdn", analyticsHandler.IngestCDNEgress)
+			admin.GET("/keys/top", apiKeyHandler.ListTopAPIKeyConsumers)
+			admin.PATCH("/keys/:id/limits", apiKeyHandler.SetAPIKeyLimits)
+			admin.GET("/playback-logs", playbackLogHandler.ListPlaybackLogs)
+			admin.POST("/events/backfill", eventHandler.BackfillEvents)
+			admin.GET("/data-lifecycle", analyticsHandler.GetDataLifecycle)
+			admin.POST("/data-lifecycle/run", analyticsHandler.RunDataLifecycle)
+			admin.GET("/stats/backups", backupHandler.GetBackupStatus)
+			admin.POST("/backups/run", backupHandler.RunBackup)
+			admin.GET("/featured", filmHandler.ListFeatured)
+			admin.POST("/featured", filmHandler.CreateFeatured)
+			admin.PUT("/featured/:id", filmHandler.UpdateFeatured)
+			admin.DELETE("/featured/:id", filmHandler.DeleteFeatured)
+			admin.POST("/featured/:id/artwork-url", filmHandler.GetFeaturedArtworkURL)
+			admin.PUT("/users/:id/role", filmHandler.UpdateUserRole)
+			admin.PUT("/users/:id/verified", filmHandler.SetUserVerified)
+			admin.GET("/users/:id/entitlements", entitlementHandler.ListUserEntitlements)
+			admin.PUT("/users/:id/entitlement", entitlementHandler.SetPlatformEntitlement)
+			admin.DELETE("/users/:id/entitlement", entitlementHandler.DeletePlatformEntitlement)
+			admin.DELETE("/users/:id", accountHandler.DeleteUser)
+			admin.GET("/chaos", chaosHandler.GetChaos)
+			admin.PUT("/chaos", chaosHandler.SetChaos)
+			admin.DELETE("/chaos", chaosHandler.ClearChaos)
+			admin.GET("/reviews/flagged", reviewHandler.ListFlaggedReviews)
+			admin.PUT("/reviews/:id/moderation", reviewHandler.ModerateReview)
+			admin.GET("/comments/reported", commentHandler.ListReportedComments)
+			admin.PUT("/comments/:id/moderation", commentHandler.ModerateComment)
+			admin.GET("/reports", adminListingHandler.ListReports)
+			admin.GET("/films/:id/reports", reportHandler.ListFilmReports)
+			admin.POST("/films/:id/reports/resolve", reportHandler.ResolveFilmReports)
+			admin.GET("/moderation", moderationHandler.ListQueue)
+			admin.POST("/moderation/actions", moderationHandler.TakeAction)
+			admin.GET("/films/:id/classifications", moderationHandler.ListFilmClassifications)
+			admin.GET("/moderation/log", adminListingHandler.ListModerationActions)
+			admin.GET("/account-deletions", adminListingHandler.ListAccountDeletions)
+			admin.GET("/quarantine", adminListingHandler.ListQuarantine)
+			admin.POST("/exports", adminListingHandler.CreateExport)
+			admin.GET("/exports/:taskId", adminListingHandler.GetExport)
+			admin.GET("/calendar", filmHandler.GetCalendar)
+			admin.POST("/calendar/events", filmHandler.CreateCalendarEvent)
+			admin.PATCH("/calendar/events/:id", filmHandler.RescheduleCalendarEvent)
+			admin.DELETE("/calendar/events/:id", filmHandler.DeleteCalendarEvent)
 		}
 	}
 
@@ -160,10 +771,21 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
-	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	// Fail health checks, refuse new uploads and end streams, then give
+	// load balancers time to notice before the listener closes
+	drain.Drain()
+	time.Sleep(cfg.ShutdownDrainDelay)
+
+	ctx, cancel = context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
+
+	// In-flight requests are done; stop background work and flush buffers
+	appCancel()
+	hooksCtx, hooksCancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer hooksCancel()
+	drain.RunHooks(hooksCtx)
 	log.Println("Server stopped")
 }
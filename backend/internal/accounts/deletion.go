@@ -0,0 +1,152 @@
+// Package accounts deletes user accounts. Films that must outlive their
+// owner, because they are published and licensed or belong to an
+// organization, are reassigned to the ghost account with the original
+// creator's name kept as their attribution; the rest are deleted with the
+// account.
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/search"
+	"github.com/google/uuid"
+)
+
+// cleanupTimeout bounds removing a deleted account's films from storage
+// and search
+const cleanupTimeout = 10 * time.Minute
+
+var (
+	// ErrGhostAccount is returned for attempts to delete the ghost account
+	ErrGhostAccount = errors.New("the ghost account cannot be deleted")
+	// ErrInvalidOverride is returned for overrides naming films the user
+	// does not own or policies other than keep and delete
+	ErrInvalidOverride = errors.New("invalid film override")
+)
+
+// Deleter runs account deletions
+type Deleter struct {
+	queries *db.Queries
+	storage *r2.Client
+	search  search.Search
+	indexer *search.Indexer
+}
+
+// NewDeleter creates an account deleter
+func NewDeleter(queries *db.Queries, storage *r2.Client, searchBackend search.Search, indexer *search.Indexer) *Deleter {
+	return &Deleter{
+		queries: queries,
+		storage: storage,
+		search:  searchBackend,
+		indexer: indexer,
+	}
+}
+
+// Plan decides what deleting a user's account does with each of their
+// films. overrides maps film IDs to keep or delete and wins over the
+// film's own policy.
+func (d *Deleter) Plan(ctx context.Context, userID uuid.UUID, overrides map[uuid.UUID]models.OwnerDeletionPolicy) ([]models.FilmDisposition, error) {
+	films, err := d.queries.ListFilmsByOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[uuid.UUID]bool, len(films))
+	for _, f := range films {
+		owned[f.ID] = true
+	}
+	for filmID, policy := range overrides {
+		if !owned[filmID] {
+			return nil, fmt.Errorf("%w: film %s is not the user's", ErrInvalidOverride, filmID)
+		}
+		if policy != models.OwnerDeletionKeep && policy != models.OwnerDeletionDelete {
+			return nil, fmt.Errorf("%w: film %s must be keep or delete", ErrInvalidOverride, filmID)
+		}
+	}
+
+	plan := make([]models.FilmDisposition, 0, len(films))
+	for _, f := range films {
+		disposition := models.FilmDisposition{FilmID: f.ID, Title: f.Title, Policy: f.OnOwnerDeletion}
+		if policy, ok := overrides[f.ID]; ok {
+			disposition.Policy = policy
+			disposition.Override = true
+		}
+		switch disposition.Policy {
+		case models.OwnerDeletionKeep:
+			disposition.Keep = true
+		case models.OwnerDeletionDelete:
+			disposition.Keep = false
+		default:
+			disposition.Keep = f.PublishedAt != nil || f.TenantID != nil
+		}
+		plan = append(plan, disposition)
+	}
+	return plan, nil
+}
+
+// Delete deletes a user's account as planned by Plan with the same
+// overrides. deletedByID is the admin deleting it, nil when users delete
+// their own. Deleted films are removed from storage and search in the
+// background.
+func (d *Deleter) Delete(ctx context.Context, userID uuid.UUID, overrides map[uuid.UUID]models.OwnerDeletionPolicy, deletedByID *uuid.UUID) (*models.AccountDeletion, []models.FilmDisposition, error) {
+	if userID == models.GhostAccountID {
+		return nil, nil, ErrGhostAccount
+	}
+	user, err := d.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan, err := d.Plan(ctx, userID, overrides)
+	if err != nil {
+		return nil, nil, err
+	}
+	keep := []uuid.UUID{}
+	for _, f := range plan {
+		if f.Keep {
+			keep = append(keep, f.FilmID)
+		}
+	}
+
+	record, deleted, err := d.queries.DeleteUserAccount(ctx, user, keep, deletedByID)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("[Accounts] Deleted user %s: kept %d films, deleted %d", userID, record.FilmsKept, record.FilmsDeleted)
+
+	// Kept films show the ghost account as their creator now
+	for _, filmID := range keep {
+		d.indexer.SyncFilmAsync(filmID)
+	}
+	go d.cleanup(deleted)
+
+	return record, plan, nil
+}
+
+// cleanup removes deleted films' objects and search documents. Failures
+// are logged; the database rows are already gone.
+func (d *Deleter) cleanup(films []db.DeletedFilm) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	for _, f := range films {
+		if err := d.storage.DeleteFilm(ctx, f.ID); err != nil {
+			log.Printf("[Accounts] Failed to delete storage of film %s: %v", f.ID, err)
+		}
+		if f.SourceRegion != "" {
+			if err := d.storage.ForRegion(f.SourceRegion).DeleteFilm(ctx, f.ID); err != nil {
+				log.Printf("[Accounts] Failed to delete source of film %s in %s: %v", f.ID, f.SourceRegion, err)
+			}
+		}
+		if err := d.search.Delete(ctx, f.ID); err != nil {
+			log.Printf("[Accounts] Failed to remove film %s from search: %v", f.ID, err)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package adminlists
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/google/uuid"
+)
+
+// Export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+const (
+	// MaxExportRows bounds how many rows one export writes
+	MaxExportRows = 100000
+
+	exportBatchSize = 500
+)
+
+// ContentType returns the MIME type of an export format, "" for an
+// unknown format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatJSON:
+		return "application/json"
+	}
+	return ""
+}
+
+// Export writes the rows matching the filter to w, newest first, as CSV
+// with a header row or as a JSON array of the rows the listing endpoint
+// returns. It stops after MaxExportRows, reporting truncated.
+func (l *Listing[T]) Export(ctx context.Context, q *db.Queries, filter db.AdminListFilter, format string, w io.Writer) (rows int, truncated bool, err error) {
+	var csvWriter *csv.Writer
+	switch format {
+	case FormatCSV:
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write(l.header); err != nil {
+			return 0, false, err
+		}
+	case FormatJSON:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return 0, false, err
+		}
+	default:
+		return 0, false, fmt.Errorf("unknown export format %q", format)
+	}
+
+	var after *db.AdminCursor
+	for !truncated {
+		batch, err := l.list(q, ctx, filter, after, 0, exportBatchSize)
+		if err != nil {
+			return rows, false, err
+		}
+		for _, row := range batch {
+			if rows == MaxExportRows {
+				truncated = true
+				break
+			}
+			if csvWriter != nil {
+				err = csvWriter.Write(l.record(row))
+			} else {
+				err = writeJSONRow(w, row, rows == 0)
+			}
+			if err != nil {
+				return rows, false, err
+			}
+			rows++
+		}
+		if len(batch) < exportBatchSize {
+			break
+		}
+		next := l.cursor(batch[len(batch)-1])
+		after = &next
+	}
+
+	if csvWriter != nil {
+		csvWriter.Flush()
+		return rows, truncated, csvWriter.Error()
+	}
+	_, err = io.WriteString(w, "\n]\n")
+	return rows, truncated, err
+}
+
+// writeJSONRow writes one element of a JSON array export
+func writeJSONRow(w io.Writer, row interface{}, first bool) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if first {
+		sep = "\n"
+	}
+	if _, err := io.WriteString(w, sep); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// FilterParams encodes a filter as worker task params
+func FilterParams(filter db.AdminListFilter) map[string]string {
+	params := map[string]string{}
+	if filter.ActorID != nil {
+		params["actor_id"] = filter.ActorID.String()
+	}
+	if filter.From != nil {
+		params["from"] = filter.From.Format(time.RFC3339)
+	}
+	if filter.To != nil {
+		params["to"] = filter.To.Format(time.RFC3339)
+	}
+	if filter.Action != "" {
+		params["action"] = filter.Action
+	}
+	if filter.Target != "" {
+		params["target"] = filter.Target
+	}
+	return params
+}
+
+// ParseFilterParams decodes a filter encoded by FilterParams
+func ParseFilterParams(params map[string]string) (db.AdminListFilter, error) {
+	filter := db.AdminListFilter{Action: params["action"], Target: params["target"]}
+	if v := params["actor_id"]; v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid actor_id: %w", err)
+		}
+		filter.ActorID = &id
+	}
+	for key, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		v := params[key]
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		*dst = &t
+	}
+	return filter, nil
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
+
+func ftoa(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,246 @@
+package adminlists
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// Listing is an admin listing: what it can be filtered by, how to page
+// through it and how its rows look in a CSV export
+type Listing[T any] struct {
+	name string
+	// actor is whether actor_id filters the listing
+	actor bool
+	// actions are the accepted action filters, none when unsupported
+	actions []string
+	// targets are the accepted target filters, none when unsupported
+	targets []string
+	header  []string
+	record  func(T) []string
+	cursor  func(T) db.AdminCursor
+	list    func(q *db.Queries, ctx context.Context, filter db.AdminListFilter, after *db.AdminCursor, offset, limit int) ([]T, error)
+	count   func(q *db.Queries, ctx context.Context, filter db.AdminListFilter) (int, error)
+}
+
+// Exporter is a listing of any row type, as export tasks see it
+type Exporter interface {
+	Name() string
+	Validate(filter db.AdminListFilter) error
+	Export(ctx context.Context, q *db.Queries, filter db.AdminListFilter, format string, w io.Writer) (rows int, truncated bool, err error)
+}
+
+var (
+	ReportedComments = &Listing[models.ReportedComment]{
+		name:   "reported_comments",
+		actor:  true,
+		header: []string{"comment_id", "film_id", "author_id", "author_name", "status", "report_count", "last_reported_at", "body"},
+		record: func(c models.ReportedComment) []string {
+			return []string{c.ID.String(), c.FilmID.String(), c.UserID.String(), c.AuthorName, string(c.Status), itoa(c.ReportCount), timestamp(c.LastReportedAt), c.Body}
+		},
+		cursor: func(c models.ReportedComment) db.AdminCursor {
+			return db.AdminCursor{At: c.LastReportedAt, ID: c.ID}
+		},
+		list:  (*db.Queries).ListReportedComments,
+		count: (*db.Queries).CountReportedComments,
+	}
+
+	FlaggedReviews = &Listing[models.FlaggedReview]{
+		name:    "flagged_reviews",
+		actor:   true,
+		actions: []string{models.FlaggedReviewHidden, models.FlaggedReviewVisible},
+		header:  []string{"review_id", "film_id", "author_id", "author_name", "rating", "hidden", "flag_count", "last_flagged_at", "body"},
+		record: func(r models.FlaggedReview) []string {
+			return []string{r.ID.String(), r.FilmID.String(), r.UserID.String(), r.AuthorName, itoa(r.Rating), fmt.Sprint(r.Hidden), itoa(r.FlagCount), timestamp(r.LastFlaggedAt), r.Body}
+		},
+		cursor: func(r models.FlaggedReview) db.AdminCursor {
+			return db.AdminCursor{At: r.LastFlaggedAt, ID: r.ID}
+		},
+		list:  (*db.Queries).ListFlaggedReviews,
+		count: (*db.Queries).CountFlaggedReviews,
+	}
+
+	QualityAlerts = &Listing[models.QualityAlert]{
+		name:    "quality_alerts",
+		actions: []string{models.QualityBelowFloor, models.QualityRegression},
+		header:  []string{"alert_id", "film_id", "film_title", "variant", "quality", "reason", "vmaf", "threshold", "created_at"},
+		record: func(a models.QualityAlert) []string {
+			return []string{a.ID.String(), a.FilmID.String(), a.FilmTitle, a.Variant, a.Quality, a.Reason, ftoa(a.VMAF), ftoa(a.Threshold), timestamp(a.CreatedAt)}
+		},
+		cursor: func(a models.QualityAlert) db.AdminCursor {
+			return db.AdminCursor{At: a.CreatedAt, ID: a.ID}
+		},
+		list:  (*db.Queries).ListQualityAlerts,
+		count: (*db.Queries).CountQualityAlerts,
+	}
+
+	AccountDeletions = &Listing[models.AccountDeletion]{
+		name:    "account_deletions",
+		actor:   true,
+		actions: []string{models.AccountDeletionSelf, models.AccountDeletionAdmin},
+		header:  []string{"deletion_id", "user_id", "email", "deleted_by_id", "films_kept", "films_deleted", "created_at"},
+		record: func(d models.AccountDeletion) []string {
+			deletedBy := ""
+			if d.DeletedByID != nil {
+				deletedBy = d.DeletedByID.String()
+			}
+			return []string{d.ID.String(), d.UserID.String(), d.Email, deletedBy, itoa(d.FilmsKept), itoa(d.FilmsDeleted), timestamp(d.CreatedAt)}
+		},
+		cursor: func(d models.AccountDeletion) db.AdminCursor {
+			return db.AdminCursor{At: d.CreatedAt, ID: d.ID}
+		},
+		list:  (*db.Queries).ListAccountDeletions,
+		count: (*db.Queries).CountAccountDeletions,
+	}
+
+	Reports = &Listing[models.ReportedTarget]{
+		name:    "reports",
+		actor:   true,
+		actions: reportCategories(),
+		targets: []string{models.ReportTargetFilm, models.ReportTargetComment},
+		header:  []string{"target_type", "target_id", "film_id", "film_title", "reports", "categories", "first_reported_at", "last_reported_at", "excerpt"},
+		record: func(t models.ReportedTarget) []string {
+			return []string{t.TargetType, t.TargetID.String(), t.FilmID.String(), t.FilmTitle, itoa(t.Reports), string(t.Categories), timestamp(t.FirstReportedAt), timestamp(t.LastReportedAt), t.Excerpt}
+		},
+		cursor: func(t models.ReportedTarget) db.AdminCursor {
+			return db.AdminCursor{At: t.LastReportedAt, ID: t.TargetID}
+		},
+		list:  (*db.Queries).ListReportedTargets,
+		count: (*db.Queries).CountReportedTargets,
+	}
+
+	ModerationActions = &Listing[models.ModerationAction]{
+		name:  "moderation",
+		actor: true,
+		actions: []string{
+			models.ModerationCommentHidden, models.ModerationCommentVisible,
+			models.ModerationReviewHidden, models.ModerationReviewVisible,
+			models.ModerationDismiss, models.ModerationAgeRestrict,
+			models.ModerationUnpublish, models.ModerationBanCreator,
+		},
+		header: []string{"target", "id", "action", "film_id", "author_id", "moderator_id", "moderated_at"},
+		record: func(m models.ModerationAction) []string {
+			moderator := ""
+			if m.ModeratorID != nil {
+				moderator = m.ModeratorID.String()
+			}
+			return []string{m.Target, m.ID.String(), m.Action, m.FilmID.String(), m.AuthorID.String(), moderator, timestamp(m.ModeratedAt)}
+		},
+		cursor: func(m models.ModerationAction) db.AdminCursor {
+			return db.AdminCursor{At: m.ModeratedAt, ID: m.ID}
+		},
+		list:  (*db.Queries).ListModerationActions,
+		count: (*db.Queries).CountModerationActions,
+	}
+
+	Quarantine = &Listing[models.QuarantinedFile]{
+		name:    "quarantine",
+		actor:   true,
+		actions: []string{models.ScannerClamAV, models.ScannerVirusTotal},
+		header:  []string{"scan_id", "film_id", "film_title", "creator_id", "kind", "object_key", "quarantine_key", "scanner", "signature", "size_bytes", "sha256", "scanned_at"},
+		record: func(f models.QuarantinedFile) []string {
+			quarantineKey, scannedAt := "", ""
+			if f.QuarantineKey != nil {
+				quarantineKey = *f.QuarantineKey
+			}
+			if f.ScannedAt != nil {
+				scannedAt = timestamp(*f.ScannedAt)
+			}
+			return []string{f.ID.String(), f.FilmID.String(), f.FilmTitle, f.CreatorID.String(), string(f.Kind), f.ObjectKey, quarantineKey, f.Scanner, f.Signature, fmt.Sprint(f.SizeBytes), f.SHA256, scannedAt}
+		},
+		cursor: func(f models.QuarantinedFile) db.AdminCursor {
+			return db.AdminCursor{At: *f.ScannedAt, ID: f.ID}
+		},
+		list:  (*db.Queries).ListQuarantinedFiles,
+		count: (*db.Queries).CountQuarantinedFiles,
+	}
+)
+
+// exporters maps listing names to their listings
+var exporters = map[string]Exporter{
+	ReportedComments.name:  ReportedComments,
+	FlaggedReviews.name:    FlaggedReviews,
+	QualityAlerts.name:     QualityAlerts,
+	AccountDeletions.name:  AccountDeletions,
+	ModerationActions.name: ModerationActions,
+	Reports.name:           Reports,
+	Quarantine.name:        Quarantine,
+}
+
+// Lookup returns the listing with the given name
+func Lookup(name string) (Exporter, bool) {
+	e, ok := exporters[name]
+	return e, ok
+}
+
+// Names returns the names of all listings, sorted
+func Names() []string {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name returns the listing's name, as export requests give it
+func (l *Listing[T]) Name() string {
+	return l.name
+}
+
+// Validate checks that the listing supports the filter
+func (l *Listing[T]) Validate(filter db.AdminListFilter) error {
+	if filter.ActorID != nil && !l.actor {
+		return fmt.Errorf("%s cannot be filtered by actor", l.name)
+	}
+	if filter.Action != "" {
+		if len(l.actions) == 0 {
+			return fmt.Errorf("%s cannot be filtered by action", l.name)
+		}
+		if !slices.Contains(l.actions, filter.Action) {
+			return fmt.Errorf("action must be one of %s", strings.Join(l.actions, ", "))
+		}
+	}
+	if filter.Target != "" && !slices.Contains(l.targets, filter.Target) {
+		if len(l.targets) == 0 {
+			return fmt.Errorf("%s cannot be filtered by target", l.name)
+		}
+		return fmt.Errorf("target must be one of %s", strings.Join(l.targets, ", "))
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// List returns a page of the listing: keyset pages pass after (nil for
+// the first) and offset 0
+func (l *Listing[T]) List(ctx context.Context, q *db.Queries, filter db.AdminListFilter, after *db.AdminCursor, offset, limit int) ([]T, error) {
+	return l.list(q, ctx, filter, after, offset, limit)
+}
+
+// Count returns how many rows match the filter
+func (l *Listing[T]) Count(ctx context.Context, q *db.Queries, filter db.AdminListFilter) (int, error) {
+	return l.count(q, ctx, filter)
+}
+
+// Cursor returns the keyset position of a row
+func (l *Listing[T]) Cursor(row T) db.AdminCursor {
+	return l.cursor(row)
+}
+
+// reportCategories returns the report categories as action filters
+func reportCategories() []string {
+	categories := make([]string, len(models.ReportCategories))
+	for i, category := range models.ReportCategories {
+		categories[i] = string(category)
+	}
+	return categories
+}
@@ -0,0 +1,191 @@
+// Package admission keeps a flood of uploads from burying the workers:
+// while the transcode queues are at the limits of the transcode admission
+// policy, confirmed uploads are rejected or deferred, and deferred films
+// are queued as room frees up.
+package admission
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/search"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/google/uuid"
+)
+
+const releaseLock = "transcode-admission"
+
+// Controller decides whether a confirmed upload is queued for transcoding
+// now and queues the deferred ones later
+type Controller struct {
+	queries  *db.Queries
+	redis    *redis.Client
+	indexer  *search.Indexer
+	settings *settings.Service
+	token    string
+}
+
+// New creates an admission controller
+func New(queries *db.Queries, redisClient *redis.Client, indexer *search.Indexer, settingsService *settings.Service) *Controller {
+	return &Controller{
+		queries:  queries,
+		redis:    redisClient,
+		indexer:  indexer,
+		settings: settingsService,
+		token:    uuid.New().String(),
+	}
+}
+
+// Policy returns the configured admission policy
+func (a *Controller) Policy(ctx context.Context) models.AdmissionPolicy {
+	var policy models.AdmissionPolicy
+	if err := a.settings.Decode(ctx, settings.KeyTranscodeAdmission, &policy); err != nil {
+		log.Printf("[Admission] Failed to load admission policy: %v", err)
+	}
+	return policy
+}
+
+// Load measures the transcode work waiting, in flight and deferred
+func (a *Controller) Load(ctx context.Context) (models.AdmissionLoad, error) {
+	var load models.AdmissionLoad
+	var err error
+	if load.Queued, err = a.redis.TranscodeQueueDepth(ctx); err != nil {
+		return load, err
+	}
+	if load.InFlight, err = a.queries.CountTranscodingFilms(ctx); err != nil {
+		return load, err
+	}
+	if load.Deferred, err = a.redis.DeferredTranscodeJobs(ctx); err != nil {
+		return load, err
+	}
+	return load, nil
+}
+
+// Admit reports whether another film can be queued for transcoding now.
+// Deferred films go first, so a new one waits while any are deferred. When
+// the load can't be measured the film is admitted.
+func (a *Controller) Admit(ctx context.Context, policy models.AdmissionPolicy) (bool, models.AdmissionLoad) {
+	if policy.MaxQueued == 0 && policy.MaxInFlight == 0 {
+		return true, models.AdmissionLoad{}
+	}
+	load, err := a.Load(ctx)
+	if err != nil {
+		log.Printf("[Admission] Failed to measure transcode load: %v", err)
+		return true, load
+	}
+	return load.Deferred == 0 && !policy.Saturated(load), load
+}
+
+// Submit queues a confirmed film for transcoding when the policy leaves
+// room and defers it otherwise, whatever the mode, since its upload was
+// already accepted. It returns the film's position when deferred, or 0.
+func (a *Controller) Submit(ctx context.Context, filmID uuid.UUID) (int64, error) {
+	if admit, _ := a.Admit(ctx, a.Policy(ctx)); admit {
+		return 0, a.queue(ctx, filmID)
+	}
+	position, err := a.Defer(ctx, filmID)
+	if err != nil {
+		return 0, err
+	}
+	if err := a.queries.SetFilmStatus(ctx, filmID, models.StatusUploaded); err != nil {
+		log.Printf("[Admission] Failed to update status of film %s: %v", filmID, err)
+	}
+	a.redis.SetFilmStatus(ctx, filmID, models.StatusUploaded)
+	a.indexer.SyncFilmAsync(filmID)
+	return position, nil
+}
+
+// Defer puts a film in the deferred queue and returns its position
+func (a *Controller) Defer(ctx context.Context, filmID uuid.UUID) (int64, error) {
+	return a.redis.DeferTranscodeJob(ctx, filmID)
+}
+
+// Release queues deferred films, oldest first, while the policy leaves
+// room, and returns how many it queued
+func (a *Controller) Release(ctx context.Context) (int, error) {
+	policy := a.Policy(ctx)
+	released := 0
+	for {
+		load, err := a.Load(ctx)
+		if err != nil {
+			return released, err
+		}
+		if load.Deferred == 0 || policy.Saturated(load) {
+			return released, nil
+		}
+
+		filmID, err := a.redis.NextDeferredTranscodeJob(ctx)
+		if err != nil || filmID == uuid.Nil {
+			return released, err
+		}
+		if err := a.queue(ctx, filmID); err != nil {
+			// Back of the line, so one bad film doesn't block the rest
+			if _, deferErr := a.redis.DeferTranscodeJob(ctx, filmID); deferErr != nil {
+				log.Printf("[Admission] Failed to defer film %s again: %v", filmID, deferErr)
+			}
+			return released, err
+		}
+		released++
+	}
+}
+
+// queue hands a deferred film to the workers, as confirming its upload
+// would have
+func (a *Controller) queue(ctx context.Context, filmID uuid.UUID) error {
+	if err := a.redis.EnqueueTranscodeJob(ctx, filmID); err != nil {
+		return err
+	}
+	if err := a.queries.RecordUploadMilestone(ctx, filmID, models.UploadQueued); err != nil {
+		log.Printf("[Admission] Failed to record upload milestone of film %s: %v", filmID, err)
+	}
+	if err := a.queries.StartFilmTranscoding(ctx, filmID); err != nil {
+		log.Printf("[Admission] Failed to update status of film %s: %v", filmID, err)
+	}
+	a.redis.SetFilmStatus(ctx, filmID, models.StatusTranscoding)
+	a.indexer.SyncFilmAsync(filmID)
+	return nil
+}
+
+// RunLoop releases deferred films on every interval. A Redis lock keeps a
+// single instance releasing at a time. It blocks until ctx is cancelled.
+func (a *Controller) RunLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.release(ctx, interval)
+		}
+	}
+}
+
+func (a *Controller) release(ctx context.Context, interval time.Duration) {
+	ok, err := a.redis.AcquireLock(ctx, releaseLock, a.token, interval)
+	if err != nil {
+		log.Printf("[Admission] Failed to acquire admission lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := a.redis.ReleaseLock(context.Background(), releaseLock, a.token); err != nil {
+			log.Printf("[Admission] Failed to release admission lock: %v", err)
+		}
+	}()
+
+	released, err := a.Release(ctx)
+	if err != nil {
+		log.Printf("[Admission] Failed to queue deferred films: %v", err)
+	}
+	if released > 0 {
+		log.Printf("[Admission] Queued %d deferred films for transcoding", released)
+	}
+}
@@ -0,0 +1,168 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/mail"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	matchLock = "saved-search-matcher"
+
+	// matchLag keeps a pass clear of publishes whose transactions may not
+	// have committed yet
+	matchLag = time.Minute
+)
+
+// Matcher alerts users when newly published films match their saved
+// searches, in-app and by email when they opted in
+type Matcher struct {
+	queries   *db.Queries
+	redis     *redis.Client
+	mailer    *mail.Sender // nil when email is disabled
+	templates *mail.Templates
+	appURL    string
+	token     string
+}
+
+// NewMatcher creates a saved search matcher; mailer may be nil
+func NewMatcher(queries *db.Queries, redisClient *redis.Client, mailer *mail.Sender, templates *mail.Templates, appURL string) *Matcher {
+	return &Matcher{
+		queries:   queries,
+		redis:     redisClient,
+		mailer:    mailer,
+		templates: templates,
+		appURL:    strings.TrimRight(appURL, "/"),
+		token:     uuid.New().String(),
+	}
+}
+
+// MatchOnce alerts on every film published since the previous pass and
+// returns how many saved searches had new results
+func (m *Matcher) MatchOnce(ctx context.Context) (int, error) {
+	until := time.Now().Add(-matchLag)
+
+	matches, err := m.queries.ListSavedSearchMatches(ctx, until)
+	if err != nil {
+		return 0, err
+	}
+
+	notifications := make([]models.Notification, len(matches))
+	for i, match := range matches {
+		notifications[i] = models.Notification{
+			UserID:  match.UserID,
+			Kind:    models.NotifySavedSearchMatch,
+			Message: summary(match),
+		}
+		if match.MatchCount == 1 {
+			filmID := match.FirstFilmID
+			notifications[i].FilmID = &filmID
+		}
+	}
+	if err := m.queries.CommitSavedSearchAlerts(ctx, until, notifications); err != nil {
+		return 0, err
+	}
+
+	// Email is best effort; the in-app alert is already stored
+	if m.mailer != nil {
+		for _, match := range matches {
+			if !match.EmailAlerts {
+				continue
+			}
+			if err := m.email(ctx, match); err != nil {
+				log.Printf("[Alerts] Failed to email saved search %s: %v", match.SavedSearchID, err)
+			}
+		}
+	}
+
+	return len(matches), nil
+}
+
+// summary describes a match in one line, e.g.
+// `2 new films match "Noir shorts": Night Train, The Last Call`
+func summary(match models.SavedSearchMatch) string {
+	noun := "films match"
+	if match.MatchCount == 1 {
+		noun = "film matches"
+	}
+	titles := strings.Join(match.Titles, ", ")
+	if more := match.MatchCount - len(match.Titles); more > 0 {
+		titles += fmt.Sprintf(" and %d more", more)
+	}
+	return fmt.Sprintf("%d new %s %q: %s", match.MatchCount, noun, match.Name, titles)
+}
+
+// email sends a match's alert. An alert about one film is branded by the
+// film's organization or creator.
+func (m *Matcher) email(ctx context.Context, match models.SavedSearchMatch) error {
+	vars := map[string]interface{}{
+		"name":      match.Name,
+		"summary":   summary(match),
+		"count":     match.MatchCount,
+		"titles":    []string(match.Titles),
+		"more":      match.MatchCount - len(match.Titles),
+		"watch_url": "",
+		"app_url":   m.appURL,
+	}
+	var brand *mail.Brand
+	if match.MatchCount == 1 {
+		vars["watch_url"] = fmt.Sprintf("%s/films/%s", m.appURL, match.FirstFilmID)
+		if film, err := m.queries.GetFilmByID(ctx, match.FirstFilmID); err == nil {
+			brand = &mail.Brand{TenantID: film.TenantID, CreatorID: film.CreatedByID}
+		}
+	}
+
+	msg, err := m.templates.Render(ctx, mail.TemplateSavedSearchMatch, brand, vars)
+	if err != nil {
+		return err
+	}
+	return m.mailer.SendMessage(match.Email, msg)
+}
+
+// RunLoop matches saved searches on every interval. A Redis lock keeps a
+// single instance matching at a time. It blocks until ctx is cancelled.
+func (m *Matcher) RunLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.match(ctx, interval)
+		}
+	}
+}
+
+func (m *Matcher) match(ctx context.Context, interval time.Duration) {
+	ok, err := m.redis.AcquireLock(ctx, matchLock, m.token, interval)
+	if err != nil {
+		log.Printf("[Alerts] Failed to acquire matcher lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := m.redis.ReleaseLock(context.Background(), matchLock, m.token); err != nil {
+			log.Printf("[Alerts] Failed to release matcher lock: %v", err)
+		}
+	}()
+
+	n, err := m.MatchOnce(ctx)
+	if err != nil {
+		log.Printf("[Alerts] Failed to match saved searches: %v", err)
+	}
+	if n > 0 {
+		log.Printf("[Alerts] Alerted %d saved searches", n)
+	}
+}
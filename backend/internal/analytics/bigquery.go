@@ -0,0 +1,452 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	bigQueryAPI   = "https://bigquery.googleapis.com/bigquery/v2"
+	bigQueryScope = "https://www.googleapis.com/auth/bigquery"
+	bigQueryTable = "events"
+)
+
+// BigQuery exports events to BigQuery via the REST API, authenticating with
+// a service account key. Streaming inserts use the event id as insertId so
+// retried batches are de-duplicated.
+type BigQuery struct {
+	project  string
+	dataset  string
+	email    string
+	key      *rsa.PrivateKey
+	tokenURI string
+	http     *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewBigQuery creates a BigQuery sink from a service account credentials file
+func NewBigQuery(project, dataset, credentialsFile string) (*BigQuery, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bigquery credentials: %w", err)
+	}
+
+	var creds struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("invalid bigquery credentials: %w", err)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(creds.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid bigquery private key: %w", err)
+	}
+
+	if project == "" {
+		project = creds.ProjectID
+	}
+	if dataset == "" {
+		dataset = "filmtube"
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	return &BigQuery{
+		project:  project,
+		dataset:  dataset,
+		email:    creds.ClientEmail,
+		key:      key,
+		tokenURI: creds.TokenURI,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Source names the sink
+func (bq *BigQuery) Source() string {
+	return SinkBigQuery
+}
+
+type bigQueryField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// EnsureSchema creates the dataset and events table and adds missing fields
+func (bq *BigQuery) EnsureSchema(ctx context.Context) error {
+	datasetPath := fmt.Sprintf("/projects/%s/datasets/%s", bq.project, bq.dataset)
+	if err := bq.do(ctx, http.MethodGet, datasetPath, nil, nil); isNotFound(err) {
+		body := map[string]interface{}{
+			"datasetReference": map[string]string{"projectId": bq.project, "datasetId": bq.dataset},
+		}
+		if err := bq.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/datasets", bq.project), body, nil); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	fields := make([]bigQueryField, len(exportColumns))
+	for i, col := range exportColumns {
+		fields[i] = bigQueryField{Name: col.Name, Type: col.BigQuery}
+	}
+
+	tablePath := fmt.Sprintf("%s/tables/%s", datasetPath, bigQueryTable)
+	var table struct {
+		Schema struct {
+			Fields []bigQueryField `json:"fields"`
+		} `json:"schema"`
+	}
+	err := bq.do(ctx, http.MethodGet, tablePath, nil, &table)
+	if isNotFound(err) {
+		body := map[string]interface{}{
+			"tableReference":   map[string]string{"projectId": bq.project, "datasetId": bq.dataset, "tableId": bigQueryTable},
+			"schema":           map[string]interface{}{"fields": fields},
+			"timePartitioning": map[string]string{"type": "DAY", "field": "occurred_at"},
+			"clustering":       map[string][]string{"fields": {"film_id", "event_type"}},
+		}
+		return bq.do(ctx, http.MethodPost, datasetPath+"/tables", body, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool, len(table.Schema.Fields))
+	for _, f := range table.Schema.Fields {
+		existing[f.Name] = true
+	}
+	merged := table.Schema.Fields
+	for _, f := range fields {
+		if !existing[f.Name] {
+			merged = append(merged, f)
+		}
+	}
+	if len(merged) == len(table.Schema.Fields) {
+		return nil
+	}
+	body := map[string]interface{}{"schema": map[string]interface{}{"fields": merged}}
+	return bq.do(ctx, http.MethodPatch, tablePath, body, nil)
+}
+
+// Write streams a batch into the events table
+func (bq *BigQuery) Write(ctx context.Context, events []ExportEvent) error {
+	rows := make([]map[string]interface{}, len(events))
+	for i, e := range events {
+		rows[i] = map[string]interface{}{
+			"insertId": strconv.FormatInt(e.ID, 10),
+			"json": map[string]interface{}{
+				"id":          e.ID,
+				"event_type":  e.EventType,
+				"film_id":     e.FilmID,
+				"viewer_hash": e.ViewerHash,
+				"country":     e.Country,
+				"device":      e.Device,
+				"os":          e.OS,
+				"surface":     e.Surface,
+				"properties":  e.Properties,
+				"occurred_at": e.OccurredAt.Format(time.RFC3339Nano),
+			},
+		}
+	}
+
+	var resp struct {
+		InsertErrors []struct {
+			Index  int `json:"index"`
+			Errors []struct {
+				Reason  string `json:"reason"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"insertErrors"`
+	}
+	path := fmt.Sprintf("/projects/%s/datasets/%s/tables/%s/insertAll", bq.project, bq.dataset, bigQueryTable)
+	if err := bq.do(ctx, http.MethodPost, path, map[string]interface{}{"rows": rows}, &resp); err != nil {
+		return err
+	}
+	if len(resp.InsertErrors) > 0 {
+		first := resp.InsertErrors[0]
+		msg := "unknown error"
+		if len(first.Errors) > 0 {
+			msg = first.Errors[0].Message
+		}
+		return fmt.Errorf("bigquery rejected %d rows (row %d: %s)", len(resp.InsertErrors), first.Index, msg)
+	}
+	return nil
+}
+
+// FilmDailyCounts aggregates events for a film per day and type
+func (bq *BigQuery) FilmDailyCounts(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.DailyEventCount, error) {
+	rows, err := bq.queryFilmRange(ctx, `
+		SELECT FORMAT_DATE('%%F', DATE(occurred_at)) AS day,
+		       event_type,
+		       COUNT(DISTINCT id) AS event_count,
+		       COUNT(DISTINCT NULLIF(viewer_hash, '')) AS unique_viewers
+		FROM %s
+		WHERE film_id = @film AND occurred_at >= @from AND occurred_at < @to
+		GROUP BY day, event_type
+		ORDER BY day, event_type`, filmID, from, to, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]models.DailyEventCount, 0, len(rows))
+	for _, row := range rows {
+		day, err := time.Parse("2006-01-02", row[0])
+		if err != nil {
+			return nil, err
+		}
+		count, _ := strconv.ParseInt(row[2], 10, 64)
+		viewers, _ := strconv.ParseInt(row[3], 10, 64)
+		counts = append(counts, models.DailyEventCount{
+			Day:           day,
+			EventType:     models.EventType(row[1]),
+			Count:         count,
+			UniqueViewers: viewers,
+		})
+	}
+	return counts, nil
+}
+
+// FilmSurfaceCounts aggregates events for a film per surface and type
+func (bq *BigQuery) FilmSurfaceCounts(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.SurfaceEventCount, error) {
+	rows, err := bq.queryFilmRange(ctx, `
+		SELECT IFNULL(surface, '') AS surface,
+		       event_type,
+		       COUNT(DISTINCT id) AS event_count
+		FROM %s
+		WHERE film_id = @film AND occurred_at >= @from AND occurred_at < @to
+		GROUP BY surface, event_type
+		ORDER BY surface, event_type`, filmID, from, to, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]models.SurfaceEventCount, 0, len(rows))
+	for _, row := range rows {
+		count, _ := strconv.ParseInt(row[2], 10, 64)
+		counts = append(counts, models.SurfaceEventCount{
+			Surface:   row[0],
+			EventType: models.EventType(row[1]),
+			Count:     count,
+		})
+	}
+	return counts, nil
+}
+
+// FilmBreakdownCounts aggregates events for a film per value of a
+// breakdown dimension and type
+func (bq *BigQuery) FilmBreakdownCounts(ctx context.Context, filmID uuid.UUID, dimension string, from, to time.Time) ([]models.BreakdownEventCount, error) {
+	if !validDimension(dimension) {
+		return nil, fmt.Errorf("unknown breakdown dimension %q", dimension)
+	}
+	rows, err := bq.queryFilmRange(ctx, `
+		SELECT IFNULL(`+dimension+`, '') AS value,
+		       event_type,
+		       COUNT(DISTINCT id) AS event_count
+		FROM %s
+		WHERE film_id = @film AND occurred_at >= @from AND occurred_at < @to
+		GROUP BY value, event_type
+		ORDER BY value, event_type`, filmID, from, to, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]models.BreakdownEventCount, 0, len(rows))
+	for _, row := range rows {
+		count, _ := strconv.ParseInt(row[2], 10, 64)
+		counts = append(counts, models.BreakdownEventCount{
+			Value:     row[0],
+			EventType: models.EventType(row[1]),
+			Count:     count,
+		})
+	}
+	return counts, nil
+}
+
+// queryFilmRange runs a report over the events table, which the query
+// names as %s, with @film, @from and @to bound. Rows are returned as
+// strings and must have the given number of columns.
+func (bq *BigQuery) queryFilmRange(ctx context.Context, query string, filmID uuid.UUID, from, to time.Time, columns int) ([][]string, error) {
+	table := "`" + bq.project + "." + bq.dataset + "." + bigQueryTable + "`"
+
+	param := func(name, typ, value string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":           name,
+			"parameterType":  map[string]string{"type": typ},
+			"parameterValue": map[string]string{"value": value},
+		}
+	}
+	body := map[string]interface{}{
+		"query":         fmt.Sprintf(query, table),
+		"useLegacySql":  false,
+		"parameterMode": "NAMED",
+		"timeoutMs":     20000,
+		"queryParameters": []map[string]interface{}{
+			param("film", "STRING", filmID.String()),
+			param("from", "TIMESTAMP", from.UTC().Format(time.RFC3339)),
+			param("to", "TIMESTAMP", to.UTC().Format(time.RFC3339)),
+		},
+	}
+
+	var resp struct {
+		JobComplete bool `json:"jobComplete"`
+		Rows        []struct {
+			F []struct {
+				V *string `json:"v"`
+			} `json:"f"`
+		} `json:"rows"`
+	}
+	if err := bq.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/queries", bq.project), body, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.JobComplete {
+		return nil, fmt.Errorf("bigquery query did not complete in time")
+	}
+
+	rows := make([][]string, len(resp.Rows))
+	for i, row := range resp.Rows {
+		if len(row.F) != columns {
+			return nil, fmt.Errorf("unexpected bigquery row shape")
+		}
+		rows[i] = make([]string, columns)
+		for j, cell := range row.F {
+			if cell.V != nil {
+				rows[i][j] = *cell.V
+			}
+		}
+	}
+	return rows, nil
+}
+
+// accessToken returns a cached OAuth token, exchanging a signed JWT
+// assertion for a new one when it is about to expire
+func (bq *BigQuery) accessToken(ctx context.Context) (string, error) {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+
+	if bq.token != "" && time.Until(bq.expires) > time.Minute {
+		return bq.token, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   bq.email,
+		"scope": bigQueryScope,
+		"aud":   bq.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(bq.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, bq.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := bq.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("bigquery token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("bigquery token request returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+
+	bq.token = token.AccessToken
+	bq.expires = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return bq.token, nil
+}
+
+// bigQueryError is a non-2xx API response
+type bigQueryError struct {
+	Status int
+	Body   string
+}
+
+func (e *bigQueryError) Error() string {
+	return fmt.Sprintf("bigquery returned %d: %s", e.Status, e.Body)
+}
+
+func isNotFound(err error) bool {
+	bqErr, ok := err.(*bigQueryError)
+	return ok && bqErr.Status == http.StatusNotFound
+}
+
+// do sends an authenticated JSON request and decodes the response into out
+func (bq *BigQuery) do(ctx context.Context, method, path string, body, out interface{}) error {
+	token, err := bq.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, bigQueryAPI+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := bq.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("bigquery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return &bigQueryError{Status: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
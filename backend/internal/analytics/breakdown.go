@@ -0,0 +1,51 @@
+package analytics
+
+import (
+	"sort"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// UnknownBreakdownValue labels events whose country, device or OS is not
+// known
+const UnknownBreakdownValue = "unknown"
+
+// Breakdown folds per-value counts into one row per value of the
+// dimension, most viewed first
+func Breakdown(counts []models.BreakdownEventCount) []models.BreakdownRow {
+	byValue := make(map[string]*models.BreakdownRow)
+	var views int64
+
+	for _, c := range counts {
+		value := c.Value
+		if value == "" {
+			value = UnknownBreakdownValue
+		}
+		row, ok := byValue[value]
+		if !ok {
+			row = &models.BreakdownRow{Value: value, Counts: make(map[models.EventType]int64)}
+			byValue[value] = row
+		}
+		row.Counts[c.EventType] += c.Count
+		if c.EventType == models.EventView {
+			row.Views += c.Count
+			views += c.Count
+		}
+	}
+
+	rows := make([]models.BreakdownRow, 0, len(byValue))
+	for _, row := range byValue {
+		if views > 0 {
+			row.ShareOfViews = float64(row.Views) / float64(views)
+		}
+		rows = append(rows, *row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Views != rows[j].Views {
+			return rows[i].Views > rows[j].Views
+		}
+		return rows[i].Value < rows[j].Value
+	})
+	return rows
+}
@@ -0,0 +1,273 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+const clickHouseTable = "events"
+
+// ClickHouse exports events to ClickHouse over its HTTP interface. The table
+// is a ReplacingMergeTree keyed on the event id so re-sent batches collapse.
+type ClickHouse struct {
+	baseURL  string
+	database string
+	username string
+	password string
+	http     *http.Client
+}
+
+// NewClickHouse creates a ClickHouse sink
+func NewClickHouse(baseURL, database, username, password string) *ClickHouse {
+	if database == "" {
+		database = "filmtube"
+	}
+	return &ClickHouse{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		database: database,
+		username: username,
+		password: password,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Source names the sink
+func (ch *ClickHouse) Source() string {
+	return SinkClickHouse
+}
+
+// EnsureSchema creates the database and events table and adds missing columns
+func (ch *ClickHouse) EnsureSchema(ctx context.Context) error {
+	if _, err := ch.exec(ctx, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", ch.database), nil, nil); err != nil {
+		return err
+	}
+
+	columns := make([]string, len(exportColumns))
+	for i, col := range exportColumns {
+		columns[i] = fmt.Sprintf("%s %s", col.Name, col.ClickHouse)
+	}
+	create := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (%s)
+		ENGINE = ReplacingMergeTree
+		PARTITION BY toYYYYMM(occurred_at)
+		ORDER BY (film_id, occurred_at, id)`,
+		ch.database, clickHouseTable, strings.Join(columns, ", "))
+	if _, err := ch.exec(ctx, create, nil, nil); err != nil {
+		return err
+	}
+
+	for _, col := range exportColumns {
+		alter := fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN IF NOT EXISTS %s %s",
+			ch.database, clickHouseTable, col.Name, col.ClickHouse)
+		if _, err := ch.exec(ctx, alter, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write inserts a batch as JSONEachRow
+func (ch *ClickHouse) Write(ctx context.Context, events []ExportEvent) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, e := range events {
+		row := map[string]interface{}{
+			"id":          e.ID,
+			"event_type":  e.EventType,
+			"film_id":     e.FilmID,
+			"viewer_hash": e.ViewerHash,
+			"country":     e.Country,
+			"device":      e.Device,
+			"os":          e.OS,
+			"surface":     e.Surface,
+			"properties":  e.Properties,
+			"occurred_at": e.OccurredAt.Format("2006-01-02 15:04:05.000"),
+		}
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s.%s FORMAT JSONEachRow", ch.database, clickHouseTable)
+	_, err := ch.exec(ctx, query, &body, nil)
+	return err
+}
+
+// FilmDailyCounts aggregates events for a film per day and type
+func (ch *ClickHouse) FilmDailyCounts(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.DailyEventCount, error) {
+	query := fmt.Sprintf(`
+		SELECT toString(toDate(occurred_at)) AS day,
+		       event_type,
+		       count() AS event_count,
+		       uniqExactIf(viewer_hash, viewer_hash != '') AS unique_viewers
+		FROM %s.%s FINAL
+		WHERE film_id = {film:String}
+		  AND occurred_at >= {from:DateTime64(3, 'UTC')}
+		  AND occurred_at < {to:DateTime64(3, 'UTC')}
+		GROUP BY day, event_type
+		ORDER BY day, event_type
+		FORMAT JSONEachRow`, ch.database, clickHouseTable)
+
+	data, err := ch.exec(ctx, query, nil, filmRangeParams(filmID, from, to))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := []models.DailyEventCount{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var row struct {
+			Day           string `json:"day"`
+			EventType     string `json:"event_type"`
+			EventCount    int64  `json:"event_count"`
+			UniqueViewers int64  `json:"unique_viewers"`
+		}
+		if err := dec.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode clickhouse row: %w", err)
+		}
+		day, err := time.Parse("2006-01-02", row.Day)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, models.DailyEventCount{
+			Day:           day,
+			EventType:     models.EventType(row.EventType),
+			Count:         row.EventCount,
+			UniqueViewers: row.UniqueViewers,
+		})
+	}
+	return counts, nil
+}
+
+// FilmSurfaceCounts aggregates events for a film per surface and type
+func (ch *ClickHouse) FilmSurfaceCounts(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.SurfaceEventCount, error) {
+	query := fmt.Sprintf(`
+		SELECT surface, event_type, count() AS event_count
+		FROM %s.%s FINAL
+		WHERE film_id = {film:String}
+		  AND occurred_at >= {from:DateTime64(3, 'UTC')}
+		  AND occurred_at < {to:DateTime64(3, 'UTC')}
+		GROUP BY surface, event_type
+		ORDER BY surface, event_type
+		FORMAT JSONEachRow`, ch.database, clickHouseTable)
+
+	data, err := ch.exec(ctx, query, nil, filmRangeParams(filmID, from, to))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := []models.SurfaceEventCount{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var row struct {
+			Surface    string `json:"surface"`
+			EventType  string `json:"event_type"`
+			EventCount int64  `json:"event_count"`
+		}
+		if err := dec.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode clickhouse row: %w", err)
+		}
+		counts = append(counts, models.SurfaceEventCount{
+			Surface:   row.Surface,
+			EventType: models.EventType(row.EventType),
+			Count:     row.EventCount,
+		})
+	}
+	return counts, nil
+}
+
+// FilmBreakdownCounts aggregates events for a film per value of a
+// breakdown dimension and type
+func (ch *ClickHouse) FilmBreakdownCounts(ctx context.Context, filmID uuid.UUID, dimension string, from, to time.Time) ([]models.BreakdownEventCount, error) {
+	if !validDimension(dimension) {
+		return nil, fmt.Errorf("unknown breakdown dimension %q", dimension)
+	}
+	query := fmt.Sprintf(`
+		SELECT %s AS value, event_type, count() AS event_count
+		FROM %s.%s FINAL
+		WHERE film_id = {film:String}
+		  AND occurred_at >= {from:DateTime64(3, 'UTC')}
+		  AND occurred_at < {to:DateTime64(3, 'UTC')}
+		GROUP BY value, event_type
+		ORDER BY value, event_type
+		FORMAT JSONEachRow`, dimension, ch.database, clickHouseTable)
+
+	data, err := ch.exec(ctx, query, nil, filmRangeParams(filmID, from, to))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := []models.BreakdownEventCount{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var row struct {
+			Value      string `json:"value"`
+			EventType  string `json:"event_type"`
+			EventCount int64  `json:"event_count"`
+		}
+		if err := dec.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode clickhouse row: %w", err)
+		}
+		counts = append(counts, models.BreakdownEventCount{
+			Value:     row.Value,
+			EventType: models.EventType(row.EventType),
+			Count:     row.EventCount,
+		})
+	}
+	return counts, nil
+}
+
+// filmRangeParams binds the {film}, {from} and {to} query parameters
+func filmRangeParams(filmID uuid.UUID, from, to time.Time) url.Values {
+	params := url.Values{}
+	params.Set("param_film", filmID.String())
+	params.Set("param_from", from.UTC().Format("2006-01-02 15:04:05.000"))
+	params.Set("param_to", to.UTC().Format("2006-01-02 15:04:05.000"))
+	params.Set("output_format_json_quote_64bit_integers", "0")
+	return params
+}
+
+// exec runs a statement. With a body, the statement goes in the query
+// string and the body carries the data; otherwise the statement is the body.
+func (ch *ClickHouse) exec(ctx context.Context, query string, data io.Reader, params url.Values) ([]byte, error) {
+	if params == nil {
+		params = url.Values{}
+	}
+	var body io.Reader
+	if data != nil {
+		params.Set("query", query)
+		body = data
+	} else {
+		body = strings.NewReader(query)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.baseURL+"/?"+params.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	if ch.username != "" {
+		req.Header.Set("X-ClickHouse-User", ch.username)
+		req.Header.Set("X-ClickHouse-Key", ch.password)
+	}
+
+	resp, err := ch.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("clickhouse request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("clickhouse returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
@@ -0,0 +1,85 @@
+package analytics
+
+import "strings"
+
+// Device classes
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceTV      = "tv"
+	DeviceBot     = "bot"
+	DeviceOther   = "other"
+)
+
+// Operating system families
+const (
+	OSiOS      = "ios"
+	OSAndroid  = "android"
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSChromeOS = "chromeos"
+	OSLinux    = "linux"
+	OSTV       = "tv" // smart TV platforms such as Tizen, webOS and Roku
+	OSOther    = "other"
+)
+
+var (
+	botMarkers = []string{"bot", "crawler", "spider", "slurp", "headless", "curl/", "wget/", "python-requests"}
+	tvMarkers  = []string{"smart-tv", "smarttv", "tizen", "webos", "web0s", "roku", "appletv", "apple tv", "crkey", "aftb", "aftm", "aftt", "hbbtv", "googletv", "android tv", "bravia"}
+)
+
+// ClassifyUserAgent sorts a user agent into a device class and operating
+// system family. Both are "" for an empty user agent. The classes are
+// coarse on purpose: they are kept when events are anonymized.
+func ClassifyUserAgent(userAgent string) (device, os string) {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "", ""
+	}
+
+	switch {
+	case containsAny(ua, "iphone", "ipad", "ipod"):
+		os = OSiOS
+	case strings.Contains(ua, "android"):
+		os = OSAndroid
+	case containsAny(ua, "tizen", "webos", "web0s", "roku"):
+		os = OSTV
+	case strings.Contains(ua, "windows"):
+		os = OSWindows
+	case strings.Contains(ua, "cros"):
+		os = OSChromeOS
+	case containsAny(ua, "macintosh", "mac os x"):
+		os = OSMacOS
+	case containsAny(ua, "linux", "x11"):
+		os = OSLinux
+	default:
+		os = OSOther
+	}
+
+	switch {
+	case containsAny(ua, botMarkers...):
+		device = DeviceBot
+	case containsAny(ua, tvMarkers...):
+		device = DeviceTV
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(os == OSAndroid && !strings.Contains(ua, "mobile")):
+		device = DeviceTablet
+	case containsAny(ua, "mobi", "iphone", "ipod") || os == OSAndroid:
+		device = DeviceMobile
+	case os == OSWindows || os == OSMacOS || os == OSChromeOS || os == OSLinux:
+		device = DeviceDesktop
+	default:
+		device = DeviceOther
+	}
+	return device, os
+}
+
+func containsAny(s string, substrs ...string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
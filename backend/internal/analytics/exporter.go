@@ -0,0 +1,149 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+// Exporter streams raw analytics events to a sink in id order, tracking a
+// watermark in Postgres so every event is sent at least once
+type Exporter struct {
+	queries   *db.Queries
+	redis     *redis.Client
+	sink      Sink
+	salt      string
+	batchSize int
+	token     string
+}
+
+// NewExporter creates an exporter for a sink
+func NewExporter(queries *db.Queries, redisClient *redis.Client, sink Sink, salt string, batchSize int) *Exporter {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	return &Exporter{
+		queries:   queries,
+		redis:     redisClient,
+		sink:      sink,
+		salt:      salt,
+		batchSize: batchSize,
+		token:     uuid.New().String(),
+	}
+}
+
+// Export sends every event after the watermark, batch by batch, and
+// returns the number exported. On failure the watermark stays at the last
+// successful batch and the error is recorded on the sink state.
+func (e *Exporter) Export(ctx context.Context) (int, error) {
+	state, err := e.queries.GetAnalyticsSinkState(ctx, e.sink.Source())
+	if err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	lastID := state.LastEventID
+	for {
+		events, err := e.queries.ListAnalyticsEventsAfter(ctx, lastID, e.batchSize)
+		if err != nil {
+			return exported, err
+		}
+		if len(events) == 0 {
+			return exported, nil
+		}
+
+		// Unverified playback events stay in Postgres only
+		batch := make([]ExportEvent, 0, len(events))
+		for _, ev := range events {
+			if ev.Unverified {
+				continue
+			}
+			batch = append(batch, toExportEvent(ev, e.salt))
+		}
+
+		if len(batch) > 0 {
+			if err := e.sink.Write(ctx, batch); err != nil {
+				if recErr := e.queries.RecordAnalyticsSinkError(ctx, e.sink.Source(), err.Error()); recErr != nil {
+					log.Printf("[Analytics] Failed to record sink error: %v", recErr)
+				}
+				return exported, fmt.Errorf("write batch after event %d: %w", lastID, err)
+			}
+		}
+
+		lastID = events[len(events)-1].ID
+		if err := e.queries.AdvanceAnalyticsSinkState(ctx, e.sink.Source(), lastID, len(batch)); err != nil {
+			return exported, err
+		}
+		exported += len(batch)
+
+		if len(events) < e.batchSize {
+			return exported, nil
+		}
+	}
+}
+
+// RunLoop ensures the sink schema and then exports on every interval.
+// A Redis lock keeps a single instance exporting at a time.
+func (e *Exporter) RunLoop(ctx context.Context, interval time.Duration) {
+	for {
+		err := e.sink.EnsureSchema(ctx)
+		if err == nil {
+			break
+		}
+		log.Printf("[Analytics] Failed to prepare %s schema: %v", e.sink.Source(), err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.runOnce(ctx, interval)
+		}
+	}
+}
+
+// Flush exports pending events once, such as before shutdown, unless
+// another instance is exporting
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.runOnce(ctx, time.Minute)
+	return nil
+}
+
+func (e *Exporter) runOnce(ctx context.Context, interval time.Duration) {
+	lockName := "analytics-sink:" + e.sink.Source()
+	ok, err := e.redis.AcquireLock(ctx, lockName, e.token, interval*5)
+	if err != nil {
+		log.Printf("[Analytics] Failed to acquire sink lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := e.redis.ReleaseLock(context.Background(), lockName, e.token); err != nil {
+			log.Printf("[Analytics] Failed to release sink lock: %v", err)
+		}
+	}()
+
+	n, err := e.Export(ctx)
+	if err != nil {
+		log.Printf("[Analytics] Export to %s failed: %v", e.sink.Source(), err)
+	}
+	if n > 0 {
+		log.Printf("[Analytics] Exported %d events to %s", n, e.sink.Source())
+	}
+}
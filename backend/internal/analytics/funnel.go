@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"sort"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// UnattributedSurface labels events sent without a surface
+const UnattributedSurface = "unattributed"
+
+// Funnel folds per-surface counts into impression -> play -> completion
+// funnels, one per surface (most plays first) plus an overall total.
+// Plays and completions are attributed to the surface the client reported
+// for them, so share_of_plays shows which surfaces drive views.
+func Funnel(counts []models.SurfaceEventCount) (models.FunnelStep, []models.FunnelStep) {
+	bySurface := make(map[string]*models.FunnelStep)
+	total := models.FunnelStep{Surface: "all"}
+
+	for _, c := range counts {
+		surface := c.Surface
+		if surface == "" {
+			surface = UnattributedSurface
+		}
+		step, ok := bySurface[surface]
+		if !ok {
+			step = &models.FunnelStep{Surface: surface}
+			bySurface[surface] = step
+		}
+
+		switch c.EventType {
+		case models.EventImpression:
+			step.Impressions += c.Count
+			total.Impressions += c.Count
+		case models.EventPlay:
+			step.Plays += c.Count
+			total.Plays += c.Count
+		case models.EventCompletion:
+			step.Completions += c.Count
+			total.Completions += c.Count
+		}
+	}
+
+	surfaces := make([]models.FunnelStep, 0, len(bySurface))
+	for _, step := range bySurface {
+		if step.Impressions == 0 && step.Plays == 0 && step.Completions == 0 {
+			continue
+		}
+		fillRates(step, total.Plays)
+		surfaces = append(surfaces, *step)
+	}
+	fillRates(&total, total.Plays)
+
+	sort.Slice(surfaces, func(i, j int) bool {
+		if surfaces[i].Plays != surfaces[j].Plays {
+			return surfaces[i].Plays > surfaces[j].Plays
+		}
+		return surfaces[i].Surface < surfaces[j].Surface
+	})
+	return total, surfaces
+}
+
+func fillRates(step *models.FunnelStep, totalPlays int64) {
+	step.ClickThrough = ratio(step.Plays, step.Impressions)
+	step.CompletionRate = ratio(step.Completions, step.Plays)
+	step.ShareOfPlays = ratio(step.Plays, totalPlays)
+}
+
+func ratio(n, d int64) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,142 @@
+// Package analytics handles analytics event storage and its data lifecycle.
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+)
+
+// Lifecycle applies the analytics retention policies: it rolls raw events
+// up into daily counters, anonymizes and deletes raw events past their
+// windows, and compacts old daily rollups into monthly ones
+type Lifecycle struct {
+	queries  *db.Queries
+	settings *settings.Service
+	mu       sync.Mutex
+}
+
+// NewLifecycle creates a lifecycle runner
+func NewLifecycle(queries *db.Queries, settingsService *settings.Service) *Lifecycle {
+	return &Lifecycle{
+		queries:  queries,
+		settings: settingsService,
+	}
+}
+
+// Policies returns the configured retention policies keyed by event type
+func (l *Lifecycle) Policies(ctx context.Context) map[string]models.RetentionPolicy {
+	var policies map[string]models.RetentionPolicy
+	if err := l.settings.Decode(ctx, settings.KeyAnalyticsRetention, &policies); err != nil {
+		log.Printf("[Analytics] Failed to load retention policies: %v", err)
+	}
+	return policies
+}
+
+// PolicyFor returns the retention policy that applies to an event type
+func PolicyFor(policies map[string]models.RetentionPolicy, eventType models.EventType) models.RetentionPolicy {
+	if p, ok := policies[string(eventType)]; ok {
+		return p
+	}
+	return policies[models.DefaultRetentionKey]
+}
+
+// Run performs one lifecycle pass and records it. Concurrent calls on the
+// same instance are serialized.
+func (l *Lifecycle) Run(ctx context.Context) (*models.DataLifecycleRun, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	run, err := l.queries.CreateDataLifecycleRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.run(ctx, run); err != nil {
+		msg := err.Error()
+		run.Error = &msg
+	}
+
+	if err := l.queries.FinishDataLifecycleRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (l *Lifecycle) run(ctx context.Context, run *models.DataLifecycleRun) error {
+	now := time.Now()
+
+	// Roll up first so deleted events are already counted
+	rolledUp, err := l.queries.RollupAnalyticsEvents(ctx)
+	if err != nil {
+		return fmt.Errorf("rollup: %w", err)
+	}
+	run.RolledUp = rolledUp
+
+	policies := l.Policies(ctx)
+	eventTypes, err := l.queries.ListAnalyticsEventTypes(ctx)
+	if err != nil {
+		return fmt.Errorf("list event types: %w", err)
+	}
+
+	for _, eventType := range eventTypes {
+		policy := PolicyFor(policies, eventType)
+
+		if policy.AnonymizeAfterDays > 0 {
+			n, err := l.queries.AnonymizeAnalyticsEvents(ctx, eventType, daysAgo(now, policy.AnonymizeAfterDays))
+			if err != nil {
+				return fmt.Errorf("anonymize %s: %w", eventType, err)
+			}
+			run.Anonymized += n
+		}
+
+		if policy.DeleteAfterDays > 0 {
+			n, err := l.queries.DeleteAnalyticsEvents(ctx, eventType, daysAgo(now, policy.DeleteAfterDays))
+			if err != nil {
+				return fmt.Errorf("delete %s: %w", eventType, err)
+			}
+			run.Deleted += n
+		}
+	}
+
+	compactDays := int(l.settings.Int(ctx, settings.KeyRollupCompactDays))
+	compacted, err := l.queries.CompactAnalyticsRollups(ctx, daysAgo(now, compactDays))
+	if err != nil {
+		return fmt.Errorf("compact rollups: %w", err)
+	}
+	run.Compacted = compacted
+
+	return nil
+}
+
+// RunLoop runs the lifecycle periodically. It blocks until ctx is cancelled.
+func (l *Lifecycle) RunLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run, err := l.Run(ctx)
+			if err != nil {
+				log.Printf("[Analytics] Lifecycle run failed: %v", err)
+				continue
+			}
+			if run.Error != nil {
+				log.Printf("[Analytics] Lifecycle run %s failed: %s", run.ID, *run.Error)
+			}
+		}
+	}
+}
+
+func daysAgo(now time.Time, days int) time.Time {
+	return now.AddDate(0, 0, -days)
+}
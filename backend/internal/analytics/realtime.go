@@ -0,0 +1,141 @@
+package analytics
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	// ActiveViewerWindow is how recently a viewer must have sent a play or
+	// heartbeat to count as watching now
+	ActiveViewerWindow = 2 * time.Minute
+
+	// realtimeMinutes is the length of the plays-per-minute series
+	realtimeMinutes = 30
+
+	// realtimeTopFilms caps the top active films list
+	realtimeTopFilms = 10
+
+	// filmMetaTTL bounds how long a renamed film keeps its old title
+	filmMetaTTL = time.Hour
+)
+
+// Realtime maintains short-lived Redis counters of live viewing activity
+// per creator, so dashboards can be served without touching Postgres
+type Realtime struct {
+	queries *db.Queries
+	redis   *redis.Client
+}
+
+// NewRealtime creates realtime counters
+func NewRealtime(queries *db.Queries, redisClient *redis.Client) *Realtime {
+	return &Realtime{
+		queries: queries,
+		redis:   redisClient,
+	}
+}
+
+// Record updates the counters for freshly ingested events. Only plays and
+// heartbeats from the last few minutes count as live activity.
+func (r *Realtime) Record(ctx context.Context, events []models.AnalyticsEvent) {
+	cutoff := time.Now().Add(-ActiveViewerWindow)
+	owners := make(map[uuid.UUID]uuid.UUID)
+
+	for _, e := range events {
+		if e.FilmID == nil || e.Unverified || e.OccurredAt.Before(cutoff) {
+			continue
+		}
+		if e.EventType != models.EventPlay && e.EventType != models.EventHeartbeat {
+			continue
+		}
+
+		creatorID, ok := owners[*e.FilmID]
+		if !ok {
+			var err error
+			creatorID, err = r.filmOwner(ctx, *e.FilmID)
+			if err != nil {
+				continue
+			}
+			owners[*e.FilmID] = creatorID
+		}
+
+		var viewer string
+		switch {
+		case e.UserID != nil:
+			viewer = "u:" + e.UserID.String()
+		case e.SessionID != nil:
+			viewer = "s:" + *e.SessionID
+		}
+
+		if err := r.redis.RecordRealtimeActivity(ctx, creatorID, *e.FilmID, viewer, e.EventType == models.EventPlay, e.OccurredAt); err != nil {
+			log.Printf("[Analytics] Failed to record realtime activity: %v", err)
+			return
+		}
+	}
+}
+
+// filmOwner resolves a film's creator, caching it with the title in Redis
+func (r *Realtime) filmOwner(ctx context.Context, filmID uuid.UUID) (uuid.UUID, error) {
+	if creatorID, _, err := r.redis.GetFilmMeta(ctx, filmID); err == nil {
+		return creatorID, nil
+	}
+
+	film, err := r.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := r.redis.SetFilmMeta(ctx, filmID, film.CreatedByID, film.Title, filmMetaTTL); err != nil {
+		log.Printf("[Analytics] Failed to cache film meta for %s: %v", filmID, err)
+	}
+	return film.CreatedByID, nil
+}
+
+// Snapshot reads a creator's live activity from Redis
+func (r *Realtime) Snapshot(ctx context.Context, creatorID uuid.UUID) (*models.RealtimeSnapshot, error) {
+	now := time.Now().UTC()
+
+	viewers, distinct, err := r.redis.GetRealtimeViewers(ctx, creatorID, now.Add(-ActiveViewerWindow))
+	if err != nil {
+		return nil, err
+	}
+	plays, err := r.redis.GetRealtimePlays(ctx, creatorID, now, realtimeMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.RealtimeSnapshot{
+		At:             now,
+		CurrentViewers: distinct,
+		PlaysPerMinute: plays,
+		TopFilms:       make([]models.ActiveFilm, 0, len(viewers)),
+	}
+	for filmID, count := range viewers {
+		snapshot.TopFilms = append(snapshot.TopFilms, models.ActiveFilm{FilmID: filmID, Viewers: count})
+	}
+
+	sort.Slice(snapshot.TopFilms, func(i, j int) bool {
+		if snapshot.TopFilms[i].Viewers != snapshot.TopFilms[j].Viewers {
+			return snapshot.TopFilms[i].Viewers > snapshot.TopFilms[j].Viewers
+		}
+		return snapshot.TopFilms[i].FilmID.String() < snapshot.TopFilms[j].FilmID.String()
+	})
+	if len(snapshot.TopFilms) > realtimeTopFilms {
+		snapshot.TopFilms = snapshot.TopFilms[:realtimeTopFilms]
+	}
+
+	// Titles come from the meta cached when the activity was recorded
+	for i := range snapshot.TopFilms {
+		if _, title, err := r.redis.GetFilmMeta(ctx, snapshot.TopFilms[i].FilmID); err == nil {
+			snapshot.TopFilms[i].Title = title
+		}
+	}
+
+	return snapshot, nil
+}
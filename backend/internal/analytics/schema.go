@@ -0,0 +1,167 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+type propertyKind int
+
+const (
+	kindNumber propertyKind = iota
+	kindString
+	kindBool
+)
+
+// property is the rule for one event property
+type property struct {
+	kind     propertyKind
+	required bool
+	maxLen   int      // strings
+	oneOf    []string // strings; empty allows any value
+}
+
+// Player event properties. Positions are seconds into the film.
+var (
+	positionProperty  = property{kind: kindNumber, required: true}
+	renditionProperty = property{kind: kindString, maxLen: 16}
+
+	propertySchemas = map[models.EventType]map[string]property{
+		models.EventPause: {
+			"position": positionProperty,
+		},
+		models.EventSeek: {
+			"from": positionProperty,
+			"to":   positionProperty,
+		},
+		models.EventQualitySwitch: {
+			"position":  {kind: kindNumber},
+			"from":      renditionProperty,
+			"to":        {kind: kindString, required: true, maxLen: 16},
+			"reason":    {kind: kindString, oneOf: []string{"auto", "manual"}},
+			"bandwidth": {kind: kindNumber}, // estimated bits per second
+		},
+		models.EventError: {
+			"position": {kind: kindNumber},
+			"code":     {kind: kindString, required: true, maxLen: 64},
+			"message":  {kind: kindString, maxLen: 500},
+			"fatal":    {kind: kindBool},
+		},
+		models.EventStartup: {
+			"startup_ms": {kind: kindNumber, required: true}, // request to first frame
+			"rendition":  renditionProperty,
+		},
+		models.EventRebuffer: {
+			"position":    {kind: kindNumber},
+			"duration_ms": {kind: kindNumber, required: true}, // time stalled
+			"rendition":   renditionProperty,
+		},
+	}
+
+	// Event types that accept any properties but whose known ones must be
+	// well formed, because rollups read them
+	openPropertySchemas = map[models.EventType]map[string]property{
+		models.EventHeartbeat: {
+			"seconds":   {kind: kindNumber}, // played since the previous heartbeat
+			"position":  {kind: kindNumber},
+			"rendition": renditionProperty,
+			"bitrate":   {kind: kindNumber}, // bits per second of the rendition playing
+		},
+	}
+)
+
+// ValidateProperties checks an event's properties against the schema of
+// its type. Player events must send exactly the properties their schema
+// lists, with the right types; heartbeats may send any properties but
+// the ones they list must have the right types; other event types accept
+// any JSON object.
+func ValidateProperties(eventType models.EventType, raw json.RawMessage) error {
+	var props map[string]json.RawMessage
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &props); err != nil || props == nil {
+			return fmt.Errorf("properties must be a JSON object")
+		}
+	}
+
+	if open, ok := openPropertySchemas[eventType]; ok {
+		for name, value := range props {
+			if rule, ok := open[name]; ok {
+				if err := rule.check(value); err != nil {
+					return fmt.Errorf("property %q %w", name, err)
+				}
+			}
+		}
+		return nil
+	}
+
+	schema, ok := propertySchemas[eventType]
+	if !ok {
+		return nil
+	}
+
+	for name, value := range props {
+		rule, ok := schema[name]
+		if !ok {
+			return fmt.Errorf("unknown property %q for %s events", name, eventType)
+		}
+		if err := rule.check(value); err != nil {
+			return fmt.Errorf("property %q %w", name, err)
+		}
+	}
+
+	// Report missing properties in a stable order
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := props[name]; schema[name].required && !ok {
+			return fmt.Errorf("property %q is required for %s events", name, eventType)
+		}
+	}
+	return nil
+}
+
+func (p property) check(value json.RawMessage) error {
+	if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+		return fmt.Errorf("must not be null")
+	}
+
+	switch p.kind {
+	case kindNumber:
+		var n float64
+		if err := json.Unmarshal(value, &n); err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		if n < 0 {
+			return fmt.Errorf("must not be negative")
+		}
+	case kindBool:
+		var b bool
+		if err := json.Unmarshal(value, &b); err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+	case kindString:
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return fmt.Errorf("must be a string")
+		}
+		if p.maxLen > 0 && len(s) > p.maxLen {
+			return fmt.Errorf("must be at most %d characters", p.maxLen)
+		}
+		if len(p.oneOf) > 0 {
+			for _, allowed := range p.oneOf {
+				if s == allowed {
+					return nil
+				}
+			}
+			return fmt.Errorf("must be one of %v", p.oneOf)
+		}
+	}
+	return nil
+}
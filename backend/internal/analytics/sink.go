@@ -0,0 +1,184 @@
+package analytics
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// Sink names
+const (
+	SinkNone       = "none"
+	SinkClickHouse = "clickhouse"
+	SinkBigQuery   = "bigquery"
+)
+
+// Querier answers the reporting queries behind the creator analytics API
+type Querier interface {
+	// Source names where the data comes from (postgres or the sink)
+	Source() string
+	// FilmDailyCounts returns per-day event counts for a film in [from, to)
+	FilmDailyCounts(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.DailyEventCount, error)
+	// FilmSurfaceCounts returns event counts for a film per UI surface in [from, to)
+	FilmSurfaceCounts(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.SurfaceEventCount, error)
+	// FilmBreakdownCounts returns event counts for a film per value of a
+	// breakdown dimension (country, device or os) in [from, to)
+	FilmBreakdownCounts(ctx context.Context, filmID uuid.UUID, dimension string, from, to time.Time) ([]models.BreakdownEventCount, error)
+}
+
+// Sink is an external analytics store that receives the event stream
+type Sink interface {
+	Querier
+	// EnsureSchema creates the events table and adds any missing columns
+	EnsureSchema(ctx context.Context) error
+	// Write stores a batch of events; rows carry the event id so retried
+	// batches can be de-duplicated
+	Write(ctx context.Context, events []ExportEvent) error
+}
+
+// ExportEvent is the row shape sent to sinks. Personal data is never
+// exported: viewers are identified by a salted hash only.
+type ExportEvent struct {
+	ID         int64     `json:"id"`
+	EventType  string    `json:"event_type"`
+	FilmID     string    `json:"film_id"`
+	ViewerHash string    `json:"viewer_hash"`
+	Country    string    `json:"country"`
+	Device     string    `json:"device"`
+	OS         string    `json:"os"`
+	Surface    string    `json:"surface"`
+	Properties string    `json:"properties"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// exportColumns is the sink table schema, in order. New columns are only
+// ever appended so EnsureSchema can add them in place.
+var exportColumns = []struct {
+	Name       string
+	ClickHouse string
+	BigQuery   string
+}{
+	{"id", "Int64", "INT64"},
+	{"event_type", "LowCardinality(String)", "STRING"},
+	{"film_id", "String", "STRING"},
+	{"viewer_hash", "String", "STRING"},
+	{"country", "LowCardinality(String)", "STRING"},
+	{"properties", "String", "STRING"},
+	{"occurred_at", "DateTime64(3, 'UTC')", "TIMESTAMP"},
+	{"surface", "LowCardinality(String)", "STRING"},
+	{"device", "LowCardinality(String)", "STRING"},
+	{"os", "LowCardinality(String)", "STRING"},
+}
+
+// SinkConfig selects and configures the analytics sink
+type SinkConfig struct {
+	Sink string
+
+	ClickHouseURL      string
+	ClickHouseDatabase string
+	ClickHouseUsername string
+	ClickHousePassword string
+
+	BigQueryProject         string
+	BigQueryDataset         string
+	BigQueryCredentialsFile string
+}
+
+// NewSink creates the configured sink, or nil when none is enabled
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.Sink {
+	case "", SinkNone:
+		return nil, nil
+	case SinkClickHouse:
+		return NewClickHouse(cfg.ClickHouseURL, cfg.ClickHouseDatabase, cfg.ClickHouseUsername, cfg.ClickHousePassword), nil
+	case SinkBigQuery:
+		bq, err := NewBigQuery(cfg.BigQueryProject, cfg.BigQueryDataset, cfg.BigQueryCredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		return bq, nil
+	default:
+		return nil, fmt.Errorf("unknown analytics sink %q", cfg.Sink)
+	}
+}
+
+// NewQuerier returns the sink when one is enabled, else Postgres rollups
+func NewQuerier(sink Sink, queries *db.Queries) Querier {
+	if sink != nil {
+		return sink
+	}
+	return &postgresQuerier{queries: queries}
+}
+
+// toExportEvent converts a raw event into its exported form
+func toExportEvent(e models.AnalyticsEvent, salt string) ExportEvent {
+	out := ExportEvent{
+		ID:         e.ID,
+		EventType:  string(e.EventType),
+		Device:     e.Device,
+		OS:         e.OS,
+		Surface:    e.Surface,
+		Properties: string(e.Properties),
+		OccurredAt: e.OccurredAt.UTC(),
+	}
+	if e.FilmID != nil {
+		out.FilmID = e.FilmID.String()
+	}
+	if e.Country != nil {
+		out.Country = *e.Country
+	}
+	if out.Properties == "" {
+		out.Properties = "{}"
+	}
+
+	var viewer string
+	switch {
+	case e.UserID != nil:
+		viewer = "u:" + e.UserID.String()
+	case e.SessionID != nil:
+		viewer = "s:" + *e.SessionID
+	}
+	if viewer != "" {
+		sum := sha256.Sum256([]byte(salt + viewer))
+		out.ViewerHash = hex.EncodeToString(sum[:])
+	}
+	return out
+}
+
+// validDimension reports whether dimension is a known breakdown
+// dimension; sinks name it as a column
+func validDimension(dimension string) bool {
+	for _, d := range models.BreakdownDimensions {
+		if d == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+// postgresQuerier reports from the Postgres rollups
+type postgresQuerier struct {
+	queries *db.Queries
+}
+
+func (p *postgresQuerier) Source() string {
+	return "postgres"
+}
+
+func (p *postgresQuerier) FilmDailyCounts(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.DailyEventCount, error) {
+	return p.queries.GetFilmDailyEventCounts(ctx, filmID, from, to)
+}
+
+func (p *postgresQuerier) FilmSurfaceCounts(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.SurfaceEventCount, error) {
+	return p.queries.GetFilmSurfaceEventCounts(ctx, filmID, from, to)
+}
+
+func (p *postgresQuerier) FilmBreakdownCounts(ctx context.Context, filmID uuid.UUID, dimension string, from, to time.Time) ([]models.BreakdownEventCount, error) {
+	return p.queries.GetBreakdownEventCounts(ctx, &filmID, dimension, from, to)
+}
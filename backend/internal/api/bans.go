@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/google/uuid"
+)
+
+const (
+	// banCacheTTL bounds how long a token keeps working after its user is
+	// banned
+	banCacheTTL = 30 * time.Second
+	// banCacheSize caps the cached users; the cache is emptied when full
+	banCacheSize = 10000
+)
+
+// BanCache answers whether a user is banned from a short-lived copy of
+// users.banned_at, so AuthMiddleware can refuse a banned user's unexpired
+// tokens without reading the database on every request
+type BanCache struct {
+	queries *db.Queries
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]banCacheEntry
+}
+
+type banCacheEntry struct {
+	banned    bool
+	expiresAt time.Time
+}
+
+// NewBanCache creates a ban cache
+func NewBanCache(queries *db.Queries) *BanCache {
+	return &BanCache{
+		queries: queries,
+		entries: map[uuid.UUID]banCacheEntry{},
+	}
+}
+
+// Banned reports whether a user is banned. When the database is
+// unavailable the user is let through; their request will likely fail on
+// its own.
+func (b *BanCache) Banned(ctx context.Context, userID uuid.UUID) bool {
+	now := time.Now()
+	b.mu.Lock()
+	entry, ok := b.entries[userID]
+	b.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.banned
+	}
+
+	banned, err := b.queries.IsUserBanned(ctx, userID)
+	if err != nil {
+		log.Printf("[Auth] Warning: failed to check ban for user %s: %v", userID, err)
+		return false
+	}
+
+	b.mu.Lock()
+	if len(b.entries) >= banCacheSize {
+		b.entries = map[uuid.UUID]banCacheEntry{}
+	}
+	b.entries[userID] = banCacheEntry{banned: banned, expiresAt: now.Add(banCacheTTL)}
+	b.mu.Unlock()
+	return banned
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/accounts"
+	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AccountHandler deletes user accounts
+type AccountHandler struct {
+	queries *db.Queries
+	deleter *accounts.Deleter
+}
+
+func NewAccountHandler(queries *db.Queries, deleter *accounts.Deleter) *AccountHandler {
+	return &AccountHandler{queries: queries, deleter: deleter}
+}
+
+// DeleteAccountRequest deletes an account. Films maps film IDs to keep or
+// delete, overriding each film's own policy. With dry_run the planned
+// outcome for each film is returned and nothing is deleted.
+type DeleteAccountRequest struct {
+	Password string                                   `json:"password"`
+	Films    map[uuid.UUID]models.OwnerDeletionPolicy `json:"films"`
+	DryRun   bool                                     `json:"dry_run"`
+}
+
+// OwnerDeletionRequest sets what happens to a film when its owner's
+// account is deleted
+type OwnerDeletionRequest struct {
+	Policy models.OwnerDeletionPolicy `json:"policy" binding:"required"`
+}
+
+// DeleteMe deletes the current user's account after checking their
+// password. Films they keep go to the ghost account.
+func (h *AccountHandler) DeleteMe(c *gin.Context) {
+	if !requireSession(c) {
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	user, err := h.queries.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err := auth.CheckPassword(user.PasswordHash, req.Password); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "incorrect password"})
+		return
+	}
+
+	h.deleteAccount(c, userID, &req, nil)
+}
+
+// DeleteUser deletes a user's account (admin)
+func (h *AccountHandler) DeleteUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req DeleteAccountRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	adminID, _ := GetUserID(c)
+	h.deleteAccount(c, userID, &req, &adminID)
+}
+
+// deleteAccount plans or runs an account deletion and writes the response
+func (h *AccountHandler) deleteAccount(c *gin.Context, userID uuid.UUID, req *DeleteAccountRequest, deletedByID *uuid.UUID) {
+	ctx := c.Request.Context()
+
+	if req.DryRun {
+		if userID == models.GhostAccountID {
+			writeAccountDeletionError(c, accounts.ErrGhostAccount)
+			return
+		}
+		if _, err := h.queries.GetUserByID(ctx, userID); err != nil {
+			writeAccountDeletionError(c, err)
+			return
+		}
+		plan, err := h.deleter.Plan(ctx, userID, req.Films)
+		if err != nil {
+			writeAccountDeletionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"dry_run": true, "films": plan})
+		return
+	}
+
+	record, plan, err := h.deleter.Delete(ctx, userID, req.Films, deletedByID)
+	if err != nil {
+		writeAccountDeletionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deletion": record, "films": plan})
+}
+
+func writeAccountDeletionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, accounts.ErrGhostAccount):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, accounts.ErrInvalidOverride):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete account"})
+	}
+}
+
+// SetFilmOwnerDeletion sets whether one of the creator's films is kept
+// (auto, keep or delete) if their account is deleted
+func (h *FilmHandler) SetFilmOwnerDeletion(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req OwnerDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.Policy.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "policy must be auto, keep or delete"})
+		return
+	}
+
+	if err := h.queries.SetFilmOwnerDeletion(c.Request.Context(), film.ID, req.Policy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update film"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"film_id": film.ID, "on_owner_deletion": req.Policy})
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/adminlists"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const adminExportExpiration = 1 * time.Hour
+
+// AdminListingHandler serves the admin audit listings without a handler
+// of their own and exports any admin listing
+type AdminListingHandler struct {
+	queries  *db.Queries
+	redis    *redis.Client
+	r2Client *r2.Client
+}
+
+func NewAdminListingHandler(queries *db.Queries, redisClient *redis.Client, r2Client *r2.Client) *AdminListingHandler {
+	return &AdminListingHandler{queries: queries, redis: redisClient, r2Client: r2Client}
+}
+
+// AdminExportRequest queues an export of an admin listing with the same
+// filters its endpoint takes
+type AdminExportRequest struct {
+	Listing string     `json:"listing" binding:"required"`
+	Format  string     `json:"format"`
+	ActorID *uuid.UUID `json:"actor_id"`
+	From    string     `json:"from"`
+	To      string     `json:"to"`
+	Action  string     `json:"action"`
+	Target  string     `json:"target"`
+}
+
+// ListAccountDeletions returns completed account deletions
+func (h *AdminListingHandler) ListAccountDeletions(c *gin.Context) {
+	serveAdminListing(c, h.queries, adminlists.AccountDeletions)
+}
+
+// ListReports returns the moderation queue: films and comments with open
+// reports, counted per category
+func (h *AdminListingHandler) ListReports(c *gin.Context) {
+	serveAdminListing(c, h.queries, adminlists.Reports)
+}
+
+// ListModerationActions returns the moderation log: the latest decision
+// on each comment and review and the moderation queue actions
+func (h *AdminListingHandler) ListModerationActions(c *gin.Context) {
+	serveAdminListing(c, h.queries, adminlists.ModerationActions)
+}
+
+// ListQuarantine returns the quarantine report: uploads a malware scan
+// found infected, with the scanner's signature
+func (h *AdminListingHandler) ListQuarantine(c *gin.Context) {
+	serveAdminListing(c, h.queries, adminlists.Quarantine)
+}
+
+// CreateExport queues an export of an admin listing as CSV or JSON
+func (h *AdminListingHandler) CreateExport(c *gin.Context) {
+	var req AdminExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	listing, ok := adminlists.Lookup(req.Listing)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "listing must be one of " + strings.Join(adminlists.Names(), ", ")})
+		return
+	}
+	if req.Format == "" {
+		req.Format = adminlists.FormatCSV
+	}
+	if adminlists.ContentType(req.Format) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or json"})
+		return
+	}
+
+	filter := db.AdminListFilter{ActorID: req.ActorID, Action: req.Action, Target: req.Target}
+	var err error
+	if filter.From, filter.To, err = parseAdminListRange(req.From, req.To); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := listing.Validate(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+
+	params := adminlists.FilterParams(filter)
+	params["listing"] = listing.Name()
+	params["format"] = req.Format
+	task := &models.WorkerTask{
+		ID:          uuid.New(),
+		Type:        models.TaskAdminExport,
+		Params:      params,
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.redis.EnqueueTask(ctx, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue task"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Export queued",
+		"task":    task,
+	})
+}
+
+// GetExport returns a download URL for a finished admin listing export
+func (h *AdminListingHandler) GetExport(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	task, err := h.redis.GetTask(ctx, taskID)
+	if err != nil || task.Type != models.TaskAdminExport || task.RequestedBy != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "export not found"})
+		return
+	}
+
+	switch task.Status {
+	case models.TaskCompleted:
+	case models.TaskFailed:
+		c.JSON(http.StatusConflict, gin.H{"error": "export failed: " + task.Error})
+		return
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": "export is still being prepared"})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", task.Params["listing"], task.CreatedAt.UTC().Format("20060102-150405"), task.Params["format"])
+	url, err := h.r2Client.GeneratePresignedDownloadURL(ctx, task.Result["key"], filename, adminExportExpiration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate download URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"download_url": url,
+		"rows":         task.Result["rows"],
+		"truncated":    task.Result["truncated"] == "true",
+		"expires_at":   time.Now().Add(adminExportExpiration),
+	})
+}
+
+// serveAdminListing writes a page of an admin listing filtered by
+// ?actor_id=, ?from=, ?to=, ?action= and ?target=. Pages are keyset
+// cursors unless the client asks for a page number (legacy mode).
+func serveAdminListing[T any](c *gin.Context, queries *db.Queries, listing *adminlists.Listing[T]) {
+	filter, ok := parseAdminListFilter(c, listing)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	failed := "failed to list " + strings.ReplaceAll(listing.Name(), "_", " ")
+
+	var page pagination.Page[T]
+	if c.Query("page") != "" {
+		params := pagination.ParseOffset(c)
+		rows, err := listing.List(ctx, queries, filter, nil, params.Offset, params.Limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": failed})
+			return
+		}
+		total, err := listing.Count(ctx, queries, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": failed})
+			return
+		}
+		page = pagination.NewOffsetPage(rows, params, total, false)
+	} else {
+		limit := pagination.ParseLimit(c)
+		var after *db.AdminCursor
+		if cursor := c.Query("cursor"); cursor != "" {
+			after = &db.AdminCursor{}
+			if err := pagination.DecodeCursor(cursor, after); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+				return
+			}
+		}
+		rows, err := listing.List(ctx, queries, filter, after, 0, limit+1)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": failed})
+			return
+		}
+		total, err := listing.Count(ctx, queries, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": failed})
+			return
+		}
+		page = pagination.NewCursorPage(rows, limit, total, false, func(last T) string {
+			return pagination.EncodeCursor(listing.Cursor(last))
+		})
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parseAdminListFilter reads an admin listing's filters and checks the
+// listing supports them. Writes a 400 and returns false when they are
+// invalid.
+func parseAdminListFilter(c *gin.Context, listing adminlists.Exporter) (db.AdminListFilter, bool) {
+	filter := db.AdminListFilter{Action: c.Query("action"), Target: c.Query("target")}
+	if v := c.Query("actor_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor_id"})
+			return filter, false
+		}
+		filter.ActorID = &id
+	}
+
+	var err error
+	if filter.From, filter.To, err = parseAdminListRange(c.Query("from"), c.Query("to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if err := listing.Validate(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	return filter, true
+}
+
+// parseAdminListRange parses optional from and to dates or RFC 3339
+// times. A to date includes that whole day.
+func parseAdminListRange(fromValue, toValue string) (from, to *time.Time, err error) {
+	if fromValue != "" {
+		t, err := parseDateParam(fromValue)
+		if err != nil {
+			return nil, nil, errors.New("invalid from")
+		}
+		from = &t
+	}
+	if toValue != "" {
+		t, err := parseDateParam(toValue)
+		if err != nil {
+			return nil, nil, errors.New("invalid to")
+		}
+		if len(toValue) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+	return from, to, nil
+}
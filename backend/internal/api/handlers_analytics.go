@@ -0,0 +1,665 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/analytics"
+	"github.com/arjunaayasa/filmtube/internal/beacon"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	maxEventsPerBatch = 100
+	// Clients may buffer events offline; older ones are rejected
+	maxEventAge = 24 * time.Hour
+
+	// Creator analytics date range
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 366
+)
+
+// surfacePattern accepts short lowercase surface names such as
+// "home:trending" or "search"; empty means unattributed
+var surfacePattern = regexp.MustCompile(`^[a-z0-9:_-]{0,64}$`)
+
+// AnalyticsHandler handles event ingestion and the analytics data lifecycle
+type AnalyticsHandler struct {
+	queries   *db.Queries
+	settings  *settings.Service
+	lifecycle *analytics.Lifecycle
+	sink      analytics.Sink
+	querier   analytics.Querier
+	realtime  *analytics.Realtime
+	beacons   *beacon.Signer
+}
+
+// NewAnalyticsHandler creates an analytics handler. sink is nil when no
+// external sink is enabled; reports then come from the Postgres rollups.
+func NewAnalyticsHandler(queries *db.Queries, settingsService *settings.Service, lifecycle *analytics.Lifecycle, sink analytics.Sink, realtime *analytics.Realtime, beacons *beacon.Signer) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		queries:   queries,
+		settings:  settingsService,
+		lifecycle: lifecycle,
+		sink:      sink,
+		querier:   analytics.NewQuerier(sink, queries),
+		realtime:  realtime,
+		beacons:   beacons,
+	}
+}
+
+// TrackEventRequest is one client event
+type TrackEventRequest struct {
+	Type       models.EventType `json:"type" binding:"required"`
+	FilmID     *uuid.UUID       `json:"film_id"`
+	SessionID  string           `json:"session_id"`
+	Surface    string           `json:"surface"` // UI surface the event happened on, e.g. "home:trending"
+	Properties json.RawMessage  `json:"properties"`
+	OccurredAt *time.Time       `json:"occurred_at"`
+	// BeaconToken is the playback session's token from the playback
+	// endpoint; required for play, view, heartbeat and completion events
+	BeaconToken string `json:"beacon_token"`
+}
+
+// TrackEventsRequest is a batch of client events
+type TrackEventsRequest struct {
+	Events []TrackEventRequest `json:"events" binding:"required,min=1,dive"`
+}
+
+// TrackEvents ingests a batch of analytics events. Authentication is
+// optional; the user is attached when a valid token is sent. A batch with
+// an invalid event, including player event properties that do not match
+// their schema, is rejected whole. Playback events without a valid beacon
+// token are flagged or dropped according to the analytics.beacon_mode
+// setting.
+func (h *AnalyticsHandler) TrackEvents(c *gin.Context) {
+	var req TrackEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Events) > maxEventsPerBatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d events per batch", maxEventsPerBatch)})
+		return
+	}
+
+	now := time.Now()
+	ip := GetClientIP(c)
+	userAgent := c.Request.UserAgent()
+	country := GetCountry(c)
+	device, deviceOS := analytics.ClassifyUserAgent(userAgent)
+
+	var userID *uuid.UUID
+	if id, ok := GetUserID(c); ok {
+		userID = &id
+	}
+
+	dropUnverified := h.settings.String(c.Request.Context(), settings.KeyBeaconMode) == models.BeaconModeDrop
+
+	events := make([]models.AnalyticsEvent, 0, len(req.Events))
+	dropped, flagged := 0, 0
+	for i, e := range req.Events {
+		if !validEventType(e.Type) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("events[%d]: unknown event type %q", i, e.Type)})
+			return
+		}
+		if !surfacePattern.MatchString(e.Surface) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("events[%d]: invalid surface", i)})
+			return
+		}
+		if err := analytics.ValidateProperties(e.Type, e.Properties); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("events[%d]: %v", i, err)})
+			return
+		}
+
+		occurredAt := now
+		if e.OccurredAt != nil && e.OccurredAt.Before(now) {
+			if now.Sub(*e.OccurredAt) > maxEventAge {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("events[%d]: occurred_at is too old", i)})
+				return
+			}
+			occurredAt = *e.OccurredAt
+		}
+
+		properties := e.Properties
+		if len(properties) == 0 {
+			properties = json.RawMessage(`{}`)
+		}
+
+		event := models.AnalyticsEvent{
+			EventType:  e.Type,
+			FilmID:     e.FilmID,
+			UserID:     userID,
+			IPAddress:  &ip,
+			UserAgent:  &userAgent,
+			Device:     device,
+			OS:         deviceOS,
+			Surface:    e.Surface,
+			Properties: properties,
+			OccurredAt: occurredAt,
+		}
+		if e.SessionID != "" {
+			sessionID := e.SessionID
+			event.SessionID = &sessionID
+		}
+		if country != "" {
+			event.Country = &country
+		}
+		if isPlaybackEvent(e.Type) && !h.verifyBeacon(&event, e.BeaconToken, now) {
+			if dropUnverified {
+				dropped++
+				continue
+			}
+			event.Unverified = true
+			flagged++
+		}
+		events = append(events, event)
+	}
+
+	if err := h.queries.InsertAnalyticsEvents(c.Request.Context(), events); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record events"})
+		return
+	}
+	h.realtime.Record(c.Request.Context(), events)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"accepted":   len(events),
+		"unverified": flagged,
+		"dropped":    dropped,
+	})
+}
+
+func isPlaybackEvent(t models.EventType) bool {
+	for _, playback := range models.PlaybackEventTypes {
+		if t == playback {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyBeacon checks that a playback event carries a valid beacon token
+// issued for its film and viewer, and binds the event to the token's
+// session
+func (h *AnalyticsHandler) verifyBeacon(event *models.AnalyticsEvent, token string, now time.Time) bool {
+	if token == "" {
+		return false
+	}
+	claims, err := h.beacons.Verify(token, now)
+	if err != nil {
+		return false
+	}
+	if event.FilmID == nil || *event.FilmID != claims.FilmID {
+		return false
+	}
+	if (event.UserID == nil) != (claims.UserID == nil) ||
+		(event.UserID != nil && *event.UserID != *claims.UserID) {
+		return false
+	}
+	if event.SessionID != nil && *event.SessionID != claims.SessionID {
+		return false
+	}
+
+	sessionID := claims.SessionID
+	event.SessionID = &sessionID
+	return true
+}
+
+func validEventType(t models.EventType) bool {
+	for _, known := range models.EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDataLifecycle reports retention policies, stored data and recent
+// lifecycle runs
+func (h *AnalyticsHandler) GetDataLifecycle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	events, err := h.queries.GetEventRetentionStats(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve event stats"})
+		return
+	}
+
+	rollups, err := h.queries.GetRollupStats(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve rollup stats"})
+		return
+	}
+
+	runs, err := h.queries.ListDataLifecycleRuns(ctx, 20)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve lifecycle runs"})
+		return
+	}
+
+	// Show the effective policy next to each stored event type
+	policies := h.lifecycle.Policies(ctx)
+	effective := make(map[models.EventType]models.RetentionPolicy, len(events))
+	for _, e := range events {
+		effective[e.EventType] = analytics.PolicyFor(policies, e.EventType)
+	}
+
+	response := gin.H{
+		"policies":                  policies,
+		"effective_policies":        effective,
+		"rollup_compact_after_days": h.settings.Int(ctx, settings.KeyRollupCompactDays),
+		"events":                    events,
+		"rollups":                   rollups,
+		"runs":                      runs,
+	}
+
+	if h.sink != nil {
+		state, err := h.queries.GetAnalyticsSinkState(ctx, h.sink.Source())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve sink state"})
+			return
+		}
+		maxID, err := h.queries.GetMaxAnalyticsEventID(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve sink state"})
+			return
+		}
+		lag := maxID - state.LastEventID
+		if lag < 0 {
+			lag = 0
+		}
+		response["sink"] = gin.H{
+			"state":      state,
+			"lag_events": lag,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RunDataLifecycle runs a lifecycle pass immediately
+func (h *AnalyticsHandler) RunDataLifecycle(c *gin.Context) {
+	run, err := h.lifecycle.Run(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to run data lifecycle"})
+		return
+	}
+
+	status := http.StatusOK
+	if run.Error != nil {
+		status = http.StatusInternalServerError
+	}
+	c.JSON(status, run)
+}
+
+// GetFilmAnalytics returns daily event counts for one of the creator's
+// films. from and to are inclusive dates (YYYY-MM-DD); the last 30 days
+// are returned by default. With breakdown=country, device or os, counts
+// over the range per value of that dimension are included.
+func (h *AnalyticsHandler) GetFilmAnalytics(c *gin.Context) {
+	film, ok := h.requireAnalyticsFilm(c)
+	if !ok {
+		return
+	}
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	dimension, ok := parseBreakdown(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	days, err := h.querier.FilmDailyCounts(ctx, film.ID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve analytics"})
+		return
+	}
+
+	totals := make(map[models.EventType]int64)
+	for _, d := range days {
+		totals[d.EventType] += d.Count
+	}
+
+	response := gin.H{
+		"film_id": film.ID,
+		"from":    from.Format("2006-01-02"),
+		"to":      to.Format("2006-01-02"),
+		"source":  h.querier.Source(),
+		"days":    days,
+		"totals":  totals,
+	}
+	if dimension != "" {
+		counts, err := h.querier.FilmBreakdownCounts(ctx, film.ID, dimension, from, to.AddDate(0, 0, 1))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve breakdown"})
+			return
+		}
+		response["breakdown"] = gin.H{"dimension": dimension, "values": analytics.Breakdown(counts)}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Analytics series intervals
+const (
+	seriesDay  = "day"
+	seriesWeek = "week"
+)
+
+// GetFilmAnalyticsSeries returns a time series of views, unique viewers,
+// watch time and completion rate for one of the creator's films, bucketed
+// by day or, with interval=week, by week starting on Monday. from and to
+// are inclusive dates (YYYY-MM-DD), the last 30 days by default; the
+// first and last weeks may be partial. Served from the Postgres rollups.
+func (h *AnalyticsHandler) GetFilmAnalyticsSeries(c *gin.Context) {
+	film, ok := h.requireAnalyticsFilm(c)
+	if !ok {
+		return
+	}
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+	interval := c.DefaultQuery("interval", seriesDay)
+	if interval != seriesDay && interval != seriesWeek {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be day or week"})
+		return
+	}
+
+	counts, err := h.queries.GetFilmEventSeries(c.Request.Context(), film.ID, from, to.AddDate(0, 0, 1), interval)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve analytics"})
+		return
+	}
+
+	// Every bucket of the range is returned, empty ones included
+	buckets := []*models.FilmAnalyticsBucket{}
+	byStart := map[string]*models.FilmAnalyticsBucket{}
+	step := 1
+	start := from
+	if interval == seriesWeek {
+		step = 7
+		start = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+	}
+	for day := start; !day.After(to); day = day.AddDate(0, 0, step) {
+		b := &models.FilmAnalyticsBucket{Start: day}
+		buckets = append(buckets, b)
+		byStart[day.Format("2006-01-02")] = b
+	}
+
+	total := &models.FilmAnalyticsBucket{Start: from}
+	for _, count := range counts {
+		b, ok := byStart[count.Bucket.UTC().Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		for _, into := range []*models.FilmAnalyticsBucket{b, total} {
+			switch count.EventType {
+			case models.EventView:
+				into.Views += count.Count
+				into.UniqueViewers += count.UniqueViewers
+			case models.EventPlay:
+				into.Plays += count.Count
+			case models.EventCompletion:
+				into.Completions += count.Count
+			}
+			into.WatchSeconds += count.WatchSeconds
+		}
+	}
+	for _, b := range append(buckets, total) {
+		if b.Plays > 0 {
+			rate := float64(b.Completions) / float64(b.Plays)
+			b.CompletionRate = &rate
+		}
+	}
+
+	c.Header("Cache-Control", "private, max-age=60")
+	c.JSON(http.StatusOK, gin.H{
+		"film_id":  film.ID,
+		"interval": interval,
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"buckets":  buckets,
+		"totals":   total,
+	})
+}
+
+// realtimeInterval is how often the realtime stream pushes a snapshot
+const realtimeInterval = 5 * time.Second
+
+// StreamRealtime streams live activity across the creator's films as
+// server-sent "snapshot" events: current viewers, plays per minute and the
+// most watched films right now. Reads only Redis counters.
+func (h *AnalyticsHandler) StreamRealtime(c *gin.Context) {
+	ctx := c.Request.Context()
+	creatorID, _ := GetUserID(c)
+
+	snapshot, err := h.realtime.Snapshot(ctx, creatorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read realtime analytics"})
+		return
+	}
+	c.SSEvent("snapshot", snapshot)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(realtimeInterval)
+	defer ticker.Stop()
+
+	draining := drainSignal(c)
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-draining:
+			sendShutdownEvent(c)
+			return false
+		case <-ticker.C:
+			snapshot, err := h.realtime.Snapshot(ctx, creatorID)
+			if err != nil {
+				// Skip a beat rather than dropping the connection
+				return true
+			}
+			c.SSEvent("snapshot", snapshot)
+			return true
+		}
+	})
+}
+
+// GetFilmFunnel returns the impression -> play -> completion funnel for one
+// of the creator's films, overall and per UI surface
+func (h *AnalyticsHandler) GetFilmFunnel(c *gin.Context) {
+	film, ok := h.requireAnalyticsFilm(c)
+	if !ok {
+		return
+	}
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	counts, err := h.querier.FilmSurfaceCounts(c.Request.Context(), film.ID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve funnel"})
+		return
+	}
+
+	total, surfaces := analytics.Funnel(counts)
+
+	c.JSON(http.StatusOK, gin.H{
+		"film_id":  film.ID,
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"source":   h.querier.Source(),
+		"total":    total,
+		"surfaces": surfaces,
+	})
+}
+
+// GetFilmRevenue returns revenue per day and currency, refunds and
+// trailer-to-purchase conversion for one of the creator's films, as JSON
+// or, with format=csv, as a CSV download of the daily rows
+func (h *AnalyticsHandler) GetFilmRevenue(c *gin.Context) {
+	film, ok := h.requireAnalyticsFilm(c)
+	if !ok {
+		return
+	}
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	end := to.AddDate(0, 0, 1)
+
+	days, err := h.queries.GetFilmRevenueByDay(ctx, film.ID, from, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve revenue"})
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeRevenueCSV(c, film.ID, from, to, days)
+		return
+	}
+
+	conversion, err := h.queries.GetFilmRevenueConversion(ctx, film.ID, from, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve conversion"})
+		return
+	}
+
+	// Amounts in different currencies are never added together
+	totals := make(map[string]*models.RevenueDay)
+	for _, d := range days {
+		t, ok := totals[d.Currency]
+		if !ok {
+			t = &models.RevenueDay{Currency: d.Currency}
+			totals[d.Currency] = t
+		}
+		t.GrossCents += d.GrossCents
+		t.RefundCents += d.RefundCents
+		t.NetCents += d.NetCents
+		t.Rentals += d.Rentals
+		t.Purchases += d.Purchases
+		t.Subscriptions += d.Subscriptions
+		t.Refunds += d.Refunds
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"film_id":    film.ID,
+		"from":       from.Format("2006-01-02"),
+		"to":         to.Format("2006-01-02"),
+		"days":       days,
+		"totals":     totals,
+		"conversion": conversion,
+	})
+}
+
+func writeRevenueCSV(c *gin.Context, filmID uuid.UUID, from, to time.Time, days []models.RevenueDay) {
+	filename := fmt.Sprintf("revenue-%s-%s-%s.csv", filmID, from.Format("20060102"), to.Format("20060102"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"day", "currency", "gross_cents", "refund_cents", "net_cents", "rentals", "purchases", "subscriptions", "refunds"})
+	for _, d := range days {
+		w.Write([]string{
+			d.Day.Format("2006-01-02"),
+			d.Currency,
+			strconv.FormatInt(d.GrossCents, 10),
+			strconv.FormatInt(d.RefundCents, 10),
+			strconv.FormatInt(d.NetCents, 10),
+			strconv.Itoa(d.Rentals),
+			strconv.Itoa(d.Purchases),
+			strconv.Itoa(d.Subscriptions),
+			strconv.Itoa(d.Refunds),
+		})
+	}
+	w.Flush()
+}
+
+// requireAnalyticsFilm resolves the :id param to a film owned by the
+// current user
+func (h *AnalyticsHandler) requireAnalyticsFilm(c *gin.Context) (*models.Film, bool) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return nil, false
+	}
+
+	film, err := h.queries.GetFilmByID(c.Request.Context(), filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return nil, false
+	}
+
+	userID, _ := GetUserID(c)
+	if film.CreatedByID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+		return nil, false
+	}
+
+	return film, true
+}
+
+// parseAnalyticsRange reads the inclusive from/to dates (YYYY-MM-DD) of an
+// analytics query, defaulting to the last 30 days
+func parseAnalyticsRange(c *gin.Context) (from, to time.Time, ok bool) {
+	var err error
+
+	to = time.Now().UTC().Truncate(24 * time.Hour)
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date"})
+			return from, to, false
+		}
+	}
+	from = to.AddDate(0, 0, -(defaultAnalyticsDays - 1))
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date"})
+			return from, to, false
+		}
+	}
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return from, to, false
+	}
+	if to.Sub(from) >= maxAnalyticsDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("range must be at most %d days", maxAnalyticsDays)})
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// parseBreakdown reads the optional breakdown query parameter, a dimension
+// to break counts down by; "" when not requested. Writes a 400 and returns
+// false when it is unknown.
+func parseBreakdown(c *gin.Context) (string, bool) {
+	dimension := c.Query("breakdown")
+	if dimension == "" {
+		return "", true
+	}
+	for _, d := range models.BreakdownDimensions {
+		if d == dimension {
+			return dimension, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "breakdown must be one of " + strings.Join(models.BreakdownDimensions, ", ")})
+	return "", false
+}
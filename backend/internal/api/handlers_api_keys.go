@@ -0,0 +1,287 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/apikeys"
+	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxAPIKeys bounds how many active keys one user may have
+	maxAPIKeys = 10
+
+	// topAPIKeyConsumers is how many keys the admin ranking returns
+	topAPIKeyConsumers = 25
+)
+
+// APIKeyHandler manages API keys and reports their usage
+type APIKeyHandler struct {
+	queries *db.Queries
+	redis   *redis.Client
+	keys    *apikeys.Service
+}
+
+func NewAPIKeyHandler(queries *db.Queries, redisClient *redis.Client, keys *apikeys.Service) *APIKeyHandler {
+	return &APIKeyHandler{queries: queries, redis: redisClient, keys: keys}
+}
+
+// CreateAPIKeyRequest names a new API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// APIKeyLimitsRequest sets a key's own limits; null falls back to the
+// platform settings
+type APIKeyLimitsRequest struct {
+	RateLimitPerMinute *int `json:"rate_limit_per_minute" binding:"omitempty,min=1"`
+	DailyQuota         *int `json:"daily_quota" binding:"omitempty,min=0"`
+}
+
+// requireSession refuses key management to requests authenticated with an
+// API key, so a leaked key can't mint or revoke keys
+func requireSession(c *gin.Context) bool {
+	if _, ok := GetAPIKeyID(c); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys can't manage API keys; sign in instead"})
+		return false
+	}
+	return true
+}
+
+// ListAPIKeys returns the current user's API keys, including revoked ones
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, _ := GetUserID(c)
+
+	keys, err := h.queries.ListAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// CreateAPIKey issues an API key for the current user. The key is only
+// returned here; clients send it in the X-API-Key header.
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	if !requireSession(c) {
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	user, err := h.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API key"})
+		return
+	}
+	if user.BannedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "account is banned"})
+		return
+	}
+
+	existing, err := h.queries.ListAPIKeys(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API key"})
+		return
+	}
+	active := 0
+	for _, k := range existing {
+		if k.RevokedAt == nil {
+			active++
+		}
+	}
+	if active >= maxAPIKeys {
+		c.JSON(http.StatusConflict, gin.H{"error": "API key limit reached; revoke one first"})
+		return
+	}
+
+	key, raw, err := h.keys.Create(ctx, userID, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"api_key": key, "key": raw})
+}
+
+// RevokeAPIKey revokes one of the current user's API keys. Its usage
+// history is kept.
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	if !requireSession(c) {
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid API key ID"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	revoked, err := h.queries.RevokeAPIKey(c.Request.Context(), keyID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke API key"})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
+
+// GetAPIKeyUsage reports an API key's requests, errors, rate-limited
+// requests and latency per hour or day (interval=hour|day; default day)
+// over a range of days (from, to; default the last 30), with its limits
+// and what is left of them. Usage lags by up to a minute.
+func (h *APIKeyHandler) GetAPIKeyUsage(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid API key ID"})
+		return
+	}
+	interval := c.DefaultQuery("interval", "day")
+	if interval != "hour" && interval != "day" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be hour or day"})
+		return
+	}
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	key, err := h.queries.GetAPIKey(ctx, keyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get API key"})
+		return
+	}
+	userID, _ := GetUserID(c)
+	role, _ := GetUserRole(c)
+	if key.UserID != userID && !auth.IsAdmin(role) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	usage, err := h.queries.GetAPIKeyUsage(ctx, keyID, from, to.AddDate(0, 0, 1), interval)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load API key usage"})
+		return
+	}
+
+	var totals models.APIKeyUsage
+	for i := range usage {
+		u := &usage[i]
+		u.AvgLatencyMs = models.AverageLatency(u.LatencyMs, u.Requests, u.RateLimited)
+		totals.Requests += u.Requests
+		totals.ClientErrors += u.ClientErrors
+		totals.ServerErrors += u.ServerErrors
+		totals.RateLimited += u.RateLimited
+		totals.LatencyMs += u.LatencyMs
+	}
+	totals.AvgLatencyMs = models.AverageLatency(totals.LatencyMs, totals.Requests, totals.RateLimited)
+
+	perMinute, perDay := h.keys.Limits(ctx, key)
+	minute, day, err := h.redis.GetAPIKeyCounts(ctx, keyID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load API key limits"})
+		return
+	}
+	limits := gin.H{
+		"requests_per_minute": perMinute,
+		"minute_remaining":    max(perMinute-minute, 0),
+		"requests_per_day":    perDay,
+		"requests_today":      day,
+	}
+	if perDay > 0 {
+		limits["day_remaining"] = max(perDay-day, 0)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"api_key":  key,
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"interval": interval,
+		"totals": gin.H{
+			"requests":       totals.Requests,
+			"client_errors":  totals.ClientErrors,
+			"server_errors":  totals.ServerErrors,
+			"rate_limited":   totals.RateLimited,
+			"avg_latency_ms": totals.AvgLatencyMs,
+		},
+		"limits": limits,
+		"usage":  usage,
+	})
+}
+
+// ListTopAPIKeyConsumers ranks API keys by requests over a window of days
+// (window=7d, 30d or 90d; default 7d), including today
+func (h *APIKeyHandler) ListTopAPIKeyConsumers(c *gin.Context) {
+	window := c.DefaultQuery("window", "7d")
+	days, ok := models.PlatformWindows[window]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be one of 7d, 30d, 90d"})
+		return
+	}
+	to := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -days)
+
+	consumers, err := h.queries.ListTopAPIKeyConsumers(c.Request.Context(), from, to, topAPIKeyConsumers)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rank API keys"})
+		return
+	}
+	for i := range consumers {
+		k := &consumers[i]
+		k.AvgLatencyMs = models.AverageLatency(k.LatencyMs, k.Requests, k.RateLimited)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"window": window, "consumers": consumers})
+}
+
+// SetAPIKeyLimits overrides an API key's rate limit and daily quota
+func (h *APIKeyHandler) SetAPIKeyLimits(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid API key ID"})
+		return
+	}
+
+	var req APIKeyLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := h.queries.SetAPIKeyLimits(c.Request.Context(), keyID, req.RateLimitPerMinute, req.DailyQuota)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set API key limits"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_key": key})
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/approval"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ApprovalActionRequest carries an optional note for an approval action
+type ApprovalActionRequest struct {
+	Comment *string `json:"comment" binding:"omitempty,max=2000"`
+}
+
+// SetFilmOrganizationRequest moves a film into or out of an organization
+type SetFilmOrganizationRequest struct {
+	TenantID *uuid.UUID `json:"tenant_id"`
+}
+
+// SetFilmOrganization assigns an unpublished film to one of the creator's
+// organizations (or removes it with null), resetting its approval state
+func (h *FilmHandler) SetFilmOrganization(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+	if film.PublishedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "published films cannot change organization"})
+		return
+	}
+
+	var req SetFilmOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if req.TenantID != nil {
+		userID, _ := GetUserID(c)
+		if _, err := h.queries.GetTenantMemberRole(ctx, *req.TenantID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not a member of this organization"})
+			return
+		}
+	}
+
+	if err := h.queries.SetFilmTenant(ctx, film.ID, req.TenantID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update film organization"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"film_id":        film.ID,
+		"tenant_id":      req.TenantID,
+		"approval_state": models.ApprovalNone,
+	})
+}
+
+// SubmitFilmForApproval sends a transcoded film to its organization's
+// approvers
+func (h *FilmHandler) SubmitFilmForApproval(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+	req, ok := bindApprovalAction(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	result, err := h.approvals.Submit(c.Request.Context(), film, userID, req.Comment)
+	respondApproval(c, result, err)
+}
+
+// ReviewFilm marks a submitted film as under review by the current approver
+func (h *FilmHandler) ReviewFilm(c *gin.Context) {
+	film, ok := h.requireFilm(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	result, err := h.approvals.Review(c.Request.Context(), film, userID)
+	respondApproval(c, result, err)
+}
+
+// ApproveFilm records the current approver's sign-off
+func (h *FilmHandler) ApproveFilm(c *gin.Context) {
+	film, ok := h.requireFilm(c)
+	if !ok {
+		return
+	}
+	req, ok := bindApprovalAction(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	result, err := h.approvals.Approve(c.Request.Context(), film, userID, req.Comment)
+	respondApproval(c, result, err)
+}
+
+// RejectFilm sends a film back to its submitter
+func (h *FilmHandler) RejectFilm(c *gin.Context) {
+	film, ok := h.requireFilm(c)
+	if !ok {
+		return
+	}
+	req, ok := bindApprovalAction(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	result, err := h.approvals.Reject(c.Request.Context(), film, userID, req.Comment)
+	respondApproval(c, result, err)
+}
+
+// GetFilmApprovals returns a film's approval state and history to its
+// creator and members of its organization
+func (h *FilmHandler) GetFilmApprovals(c *gin.Context) {
+	film, ok := h.requireFilm(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	if film.CreatedByID != userID && !isAdmin(c) {
+		if film.TenantID == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+			return
+		}
+		if _, err := h.queries.GetTenantMemberRole(ctx, *film.TenantID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+			return
+		}
+	}
+
+	events, err := h.queries.ListFilmApprovalEvents(ctx, film.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load approval history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"film_id":        film.ID,
+		"tenant_id":      film.TenantID,
+		"approval_state": film.ApprovalState,
+		"events":         events,
+	})
+}
+
+// requireFilm loads the film named by :id, writing the error response
+// itself when it is missing
+func (h *FilmHandler) requireFilm(c *gin.Context) (*models.Film, bool) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return nil, false
+	}
+
+	film, err := h.queries.GetFilmByID(c.Request.Context(), filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return nil, false
+	}
+	return film, true
+}
+
+// bindApprovalAction reads the optional request body of an approval action
+func bindApprovalAction(c *gin.Context) (ApprovalActionRequest, bool) {
+	var req ApprovalActionRequest
+	if c.Request.ContentLength == 0 {
+		return req, true
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	return req, true
+}
+
+// respondApproval maps approval workflow errors to HTTP statuses
+func respondApproval(c *gin.Context, result *approval.Result, err error) {
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, result)
+	case errors.Is(err, approval.ErrNotMember), errors.Is(err, approval.ErrNotApprover),
+		errors.Is(err, approval.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, approval.ErrNoOrganization), errors.Is(err, approval.ErrNotReady):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, approval.ErrInvalidState), errors.Is(err, approval.ErrAlreadyApproved):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record approval action"})
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const audioUploadExpiration = 30 * time.Minute
+
+// GetAudioUploadURL generates a pre-signed URL for uploading a replacement
+// audio track for a film that has already been transcoded
+func (h *FilmHandler) GetAudioUploadURL(c *gin.Context) {
+	film, ok := h.requireReadyOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	uploadURL, err := h.r2Client.GeneratePresignedUploadURLForAudio(c.Request.Context(), film.ID, audioUploadExpiration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate upload URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"upload_url": uploadURL,
+		"expiration": audioUploadExpiration.String(),
+	})
+}
+
+// ReplaceAudio queues a worker task that swaps the film's audio for the
+// uploaded replacement track, remuxing the existing renditions without
+// re-encoding video
+func (h *FilmHandler) ReplaceAudio(c *gin.Context) {
+	film, ok := h.requireReadyOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+
+	task := &models.WorkerTask{
+		ID:          uuid.New(),
+		Type:        models.TaskReplaceAudio,
+		FilmID:      film.ID,
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.redis.EnqueueTask(ctx, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue task"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Audio replacement queued",
+		"task":    task,
+	})
+}
+
+// requireReadyOwnedFilm is requireOwnedFilm for films that have finished
+// transcoding
+func (h *FilmHandler) requireReadyOwnedFilm(c *gin.Context) (*models.Film, bool) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return nil, false
+	}
+
+	if film.Status != models.StatusReady {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "film must be in READY status"})
+		return nil, false
+	}
+
+	return film, true
+}
+
+// requireOwnedFilm loads the film from the :id param and checks that the
+// current user owns it. It writes the error response itself and returns
+// false when the request should stop.
+func (h *FilmHandler) requireOwnedFilm(c *gin.Context) (*models.Film, bool) {
+	idParam := c.Param("id")
+	filmID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return nil, false
+	}
+
+	film, err := h.queries.GetFilmByID(c.Request.Context(), filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return nil, false
+	}
+
+	userID, _ := GetUserID(c)
+	if film.CreatedByID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+		return nil, false
+	}
+
+	return film, true
+}
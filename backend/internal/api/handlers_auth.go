@@ -2,10 +2,13 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/comments"
 	"github.com/arjunaayasa/filmtube/internal/db"
 	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/settings"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -14,12 +17,14 @@ import (
 type AuthHandler struct {
 	queries    *db.Queries
 	jwtManager *auth.JWTManager
+	settings   *settings.Service
 }
 
-func NewAuthHandler(queries *db.Queries, jwtManager *auth.JWTManager) *AuthHandler {
+func NewAuthHandler(queries *db.Queries, jwtManager *auth.JWTManager, settingsService *settings.Service) *AuthHandler {
 	return &AuthHandler{
 		queries:    queries,
 		jwtManager: jwtManager,
+		settings:   settingsService,
 	}
 }
 
@@ -28,9 +33,20 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
 	Name     string `json:"name" binding:"required"`
+	Handle   string `json:"handle,omitempty"`
 	Role     string `json:"role,omitempty"`
 }
 
+// HandleRequest sets or, when empty, clears the current user's @handle
+type HandleRequest struct {
+	Handle string `json:"handle"`
+}
+
+// BirthDateRequest sets the current user's birth date (YYYY-MM-DD)
+type BirthDateRequest struct {
+	BirthDate string `json:"birth_date" binding:"required,datetime=2006-01-02"`
+}
+
 // LoginRequest represents login input
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
@@ -43,8 +59,14 @@ type AuthResponse struct {
 	User  *models.User `json:"user"`
 }
 
-// Register handles user registration
+// Register handles user registration while the
+// features.registration_enabled setting allows it
 func (h *AuthHandler) Register(c *gin.Context) {
+	if !h.settings.Bool(c.Request.Context(), settings.KeyRegistrationEnabled) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "registration is disabled"})
+		return
+	}
+
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -59,6 +81,14 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	var handle *string
+	if req.Handle != "" {
+		if !h.handleAvailable(c, req.Handle, uuid.Nil) {
+			return
+		}
+		handle = &req.Handle
+	}
+
 	// Hash password
 	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
@@ -79,6 +109,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
 		Name:         req.Name,
+		Handle:       handle,
 		Role:         role,
 	}
 
@@ -115,7 +146,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// Get user by email
 	user, err := h.queries.GetUserByEmail(ctx, req.Email)
-	if err != nil {
+	if err != nil || user.GhostOwnerID != nil || user.ID == models.GhostAccountID {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": auth.ErrInvalidCredentials.Error()})
 		return
 	}
@@ -125,6 +156,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": auth.ErrInvalidCredentials.Error()})
 		return
 	}
+	if user.BannedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "account is banned"})
+		return
+	}
 
 	// Generate token
 	token, err := h.jwtManager.GenerateToken(user)
@@ -159,3 +194,81 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	user.PasswordHash = ""
 	c.JSON(http.StatusOK, user)
 }
+
+// SetHandle sets or clears the current user's @handle, which others use to
+// mention them in comments
+func (h *AuthHandler) SetHandle(c *gin.Context) {
+	var req HandleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	var handle *string
+	if req.Handle != "" {
+		if !h.handleAvailable(c, req.Handle, userID) {
+			return
+		}
+		handle = &req.Handle
+	}
+
+	ctx := c.Request.Context()
+	if err := h.queries.SetUserHandle(ctx, userID, handle); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set handle"})
+		return
+	}
+
+	user, err := h.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	user.PasswordHash = ""
+	c.JSON(http.StatusOK, user)
+}
+
+// SetBirthDate sets the current user's birth date, which age-gated films
+// check before playback
+func (h *AuthHandler) SetBirthDate(c *gin.Context) {
+	var req BirthDateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	birthDate, _ := time.Parse("2006-01-02", req.BirthDate)
+	if birthDate.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "birth_date must be in the past"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	ctx := c.Request.Context()
+	if err := h.queries.SetUserBirthDate(ctx, userID, birthDate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set birth date"})
+		return
+	}
+
+	user, err := h.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	user.PasswordHash = ""
+	c.JSON(http.StatusOK, user)
+}
+
+// handleAvailable checks that handle is well formed and not taken by
+// anyone but owner (uuid.Nil for a new user)
+func (h *AuthHandler) handleAvailable(c *gin.Context, handle string, owner uuid.UUID) bool {
+	if !comments.ValidHandle(handle) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "handle must be 3-30 letters, digits or underscores"})
+		return false
+	}
+	existing, err := h.queries.GetUserByHandle(c.Request.Context(), handle)
+	if err == nil && existing.ID != owner {
+		c.JSON(http.StatusConflict, gin.H{"error": "handle already taken"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/backup"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// backupStaleAfter is how old the last successful backup may get before
+// status reports backups as stale: a day plus slack for a slow run
+const backupStaleAfter = 26 * time.Hour
+
+// BackupHandler reports on and runs platform data backups
+type BackupHandler struct {
+	queries *db.Queries
+	backups *backup.Service
+}
+
+func NewBackupHandler(queries *db.Queries, backups *backup.Service) *BackupHandler {
+	return &BackupHandler{queries: queries, backups: backups}
+}
+
+// GetBackupStatus returns whether backups are on, the last successful one,
+// whether it is stale, and the recent runs
+func (h *BackupHandler) GetBackupStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	runs, err := h.queries.ListBackupRuns(ctx, 20)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list backup runs"})
+		return
+	}
+	last, err := h.queries.GetLastSuccessfulBackupRun(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load the last backup"})
+		return
+	}
+
+	stale := h.backups.Enabled() && (last == nil || time.Since(last.StartedAt) > backupStaleAfter)
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":      h.backups.Enabled(),
+		"hour_utc":     h.backups.Hour(),
+		"last_success": last,
+		"stale":        stale,
+		"runs":         runs,
+	})
+}
+
+// RunBackup takes a backup now
+func (h *BackupHandler) RunBackup(c *gin.Context) {
+	run, err := h.backups.Run(c.Request.Context())
+	if errors.Is(err, backup.ErrDisabled) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to run backup"})
+		return
+	}
+
+	status := http.StatusOK
+	if run.Error != nil {
+		status = http.StatusInternalServerError
+	}
+	c.JSON(status, run)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BootstrapHandler handles first-run platform setup
+type BootstrapHandler struct {
+	queries *db.Queries
+	token   string
+}
+
+func NewBootstrapHandler(queries *db.Queries, token string) *BootstrapHandler {
+	return &BootstrapHandler{
+		queries: queries,
+		token:   token,
+	}
+}
+
+// BootstrapRequest represents first-run setup input
+type BootstrapRequest struct {
+	AdminEmail    string `json:"admin_email" binding:"required,email"`
+	AdminPassword string `json:"admin_password" binding:"required,min=8"`
+	AdminName     string `json:"admin_name" binding:"required"`
+	TenantName    string `json:"tenant_name" binding:"required,max=255"`
+	TenantSlug    string `json:"tenant_slug" binding:"omitempty,max=100"`
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Bootstrap creates the initial admin, tenant and default transcode profiles.
+// It is idempotent: once setup has completed, further calls return the
+// existing state with 200 instead of creating anything. An admin email that
+// is already registered is refused with 409 rather than promoted.
+func (h *BootstrapHandler) Bootstrap(c *gin.Context) {
+	// The endpoint is disabled unless a bootstrap token is configured
+	if h.token == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bootstrap is disabled"})
+		return
+	}
+
+	provided := c.GetHeader("X-Bootstrap-Token")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(h.token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid bootstrap token"})
+		return
+	}
+
+	var req BootstrapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slug := req.TenantSlug
+	if slug == "" {
+		slug = strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(req.TenantName), "-"), "-")
+	}
+
+	hashedPassword, err := auth.HashPassword(req.AdminPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process password"})
+		return
+	}
+
+	admin := &models.User{
+		ID:           uuid.New(),
+		Email:        req.AdminEmail,
+		PasswordHash: hashedPassword,
+		Name:         req.AdminName,
+		Role:         models.RoleAdmin,
+	}
+	tenant := &models.Tenant{
+		ID:   uuid.New(),
+		Name: req.TenantName,
+		Slug: slug,
+	}
+
+	state, created, err := h.queries.Bootstrap(c.Request.Context(), admin, tenant, models.DefaultTranscodeProfiles)
+	if errors.Is(err, db.ErrBootstrapEmailTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to bootstrap platform"})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	c.JSON(status, gin.H{
+		"created":         created,
+		"admin_id":        state.AdminID,
+		"tenant_id":       state.TenantID,
+		"bootstrapped_at": state.BootstrappedAt,
+	})
+}
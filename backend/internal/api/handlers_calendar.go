@@ -0,0 +1,344 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// calendarDefaultSpan is the range served when ?to= is omitted
+	calendarDefaultSpan = 31 * 24 * time.Hour
+	// calendarMaxSpan bounds the range of one calendar request
+	calendarMaxSpan = 366 * 24 * time.Hour
+)
+
+// ReleaseEventRequest schedules a publish, premiere or festival window
+type ReleaseEventRequest struct {
+	FilmID   uuid.UUID           `json:"film_id" binding:"required"`
+	Kind     models.CalendarKind `json:"kind" binding:"required,oneof=PUBLISH PREMIERE FESTIVAL"`
+	Name     *string             `json:"name"` // required for festivals
+	StartsAt time.Time           `json:"starts_at" binding:"required"`
+	EndsAt   *time.Time          `json:"ends_at"`
+	Force    bool                `json:"force"` // schedule despite premiere collisions
+}
+
+// RescheduleRequest moves a release event. When ends_at is omitted the
+// event keeps its length, as when dragged on a calendar.
+type RescheduleRequest struct {
+	StartsAt    time.Time  `json:"starts_at" binding:"required"`
+	EndsAt      *time.Time `json:"ends_at"`
+	ClearEndsAt bool       `json:"clear_ends_at"`
+	Force       bool       `json:"force"`
+}
+
+// GetCalendar returns every scheduled publish, premiere, festival window,
+// featured slot and press embargo lift in a date range, with the premieres
+// whose slots collide
+func (h *FilmHandler) GetCalendar(c *gin.Context) {
+	filter, err := parseCalendarFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	entries, err := h.queries.ListCalendar(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load calendar"})
+		return
+	}
+
+	slot := h.premiereSlot(ctx)
+	c.JSON(http.StatusOK, gin.H{
+		"from":                  filter.From,
+		"to":                    filter.To,
+		"premiere_slot_minutes": int(slot.Minutes()),
+		"entries":               entries,
+		"conflicts":             models.PremiereConflicts(entries, slot),
+	})
+}
+
+// CreateCalendarEvent schedules a publish, premiere or festival window. A
+// premiere colliding with another is rejected with the conflicts unless
+// force is set.
+func (h *FilmHandler) CreateCalendarEvent(c *gin.Context) {
+	var req ReleaseEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	film, err := h.queries.GetFilmByID(ctx, req.FilmID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "film not found"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	event := &models.ReleaseEvent{
+		FilmID:      film.ID,
+		Kind:        req.Kind,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		CreatedByID: &userID,
+	}
+	if req.Name != nil {
+		event.Name = nullIfEmpty(strings.TrimSpace(*req.Name))
+	}
+
+	switch event.Kind {
+	case models.CalendarPublish:
+		if film.PublishedAt != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "film is already published"})
+			return
+		}
+		if event.EndsAt != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a scheduled publish has no ends_at"})
+			return
+		}
+		if !event.StartsAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "starts_at must be in the future"})
+			return
+		}
+	case models.CalendarFestival:
+		if event.Name == nil || event.EndsAt == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a festival window needs a name and ends_at"})
+			return
+		}
+	}
+	if !h.checkSchedule(c, event, req.Force) {
+		return
+	}
+
+	err = h.queries.CreateReleaseEvent(ctx, event)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "film already has a scheduled publish"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to schedule event"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+// RescheduleCalendarEvent moves a release event; a scheduled publish that
+// failed is retried at its new time
+func (h *FilmHandler) RescheduleCalendarEvent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+
+	var req RescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	event, err := h.queries.GetReleaseEvent(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+	if event.PublishedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "film was already published by this event"})
+		return
+	}
+
+	switch {
+	case req.ClearEndsAt:
+		event.EndsAt = nil
+	case req.EndsAt != nil:
+		event.EndsAt = req.EndsAt
+	case event.EndsAt != nil:
+		end := event.EndsAt.Add(req.StartsAt.Sub(event.StartsAt))
+		event.EndsAt = &end
+	}
+	event.StartsAt = req.StartsAt
+
+	if event.Kind == models.CalendarPublish && !event.StartsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "starts_at must be in the future"})
+		return
+	}
+	if event.Kind == models.CalendarFestival && event.EndsAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a festival window needs ends_at"})
+		return
+	}
+	if !h.checkSchedule(c, event, req.Force) {
+		return
+	}
+
+	err = h.queries.RescheduleReleaseEvent(ctx, event)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "film was already published by this event"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reschedule event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// DeleteCalendarEvent removes a release event; deleting a pending publish
+// cancels it
+func (h *FilmHandler) DeleteCalendarEvent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+
+	err = h.queries.DeleteReleaseEvent(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Event removed"})
+}
+
+// checkSchedule validates an event's window and, for premieres, rejects
+// collisions with other premieres unless forced. It writes the error
+// response itself.
+func (h *FilmHandler) checkSchedule(c *gin.Context, event *models.ReleaseEvent, force bool) bool {
+	if event.EndsAt != nil && !event.EndsAt.After(event.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return false
+	}
+	if event.Kind != models.CalendarPremiere || force {
+		return true
+	}
+
+	ctx := c.Request.Context()
+	slot := h.premiereSlot(ctx)
+	start, end := models.PremiereSlot(event.StartsAt, event.EndsAt, slot)
+	others, err := h.queries.ListCalendar(ctx, db.CalendarFilter{
+		From:  start.Add(-slot),
+		To:    end,
+		Kinds: []models.CalendarKind{models.CalendarPremiere},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check calendar"})
+		return false
+	}
+
+	self := uuid.New()
+	if event.ID != uuid.Nil {
+		self = event.ID
+	}
+	candidates := []models.CalendarEntry{{ID: self, Kind: event.Kind, StartsAt: event.StartsAt, EndsAt: event.EndsAt}}
+	for _, other := range others {
+		if other.ID != event.ID {
+			candidates = append(candidates, other)
+		}
+	}
+
+	conflicts := []models.CalendarConflict{}
+	for _, conflict := range models.PremiereConflicts(candidates, slot) {
+		if conflict.EntryID == self || conflict.ConflictsWith == self {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "premiere collides with another premiere; set force to schedule anyway",
+			"conflicts": conflicts,
+		})
+		return false
+	}
+	return true
+}
+
+// premiereSlot returns how long a premiere without an end occupies
+func (h *FilmHandler) premiereSlot(ctx context.Context) time.Duration {
+	return time.Duration(h.settings.Int(ctx, settings.KeyPremiereSlot)) * time.Minute
+}
+
+// parseCalendarFilter reads the calendar range and filters, defaulting to
+// the month starting today (UTC)
+func parseCalendarFilter(c *gin.Context) (db.CalendarFilter, error) {
+	var filter db.CalendarFilter
+
+	filter.From = time.Now().UTC().Truncate(24 * time.Hour)
+	if v := c.Query("from"); v != "" {
+		t, err := parseDateParam(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: use YYYY-MM-DD or RFC3339")
+		}
+		filter.From = t
+	}
+	filter.To = filter.From.Add(calendarDefaultSpan)
+	if v := c.Query("to"); v != "" {
+		t, err := parseDateParam(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: use YYYY-MM-DD or RFC3339")
+		}
+		filter.To = t
+	}
+	if !filter.To.After(filter.From) {
+		return filter, fmt.Errorf("to must be after from")
+	}
+	if filter.To.Sub(filter.From) > calendarMaxSpan {
+		return filter, fmt.Errorf("range must not exceed 366 days")
+	}
+
+	if v := c.Query("kind"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			kind := models.CalendarKind(strings.ToUpper(strings.TrimSpace(name)))
+			if !validCalendarKind(kind) {
+				return filter, fmt.Errorf("kind must be one of PUBLISH, PREMIERE, FESTIVAL, FEATURED, EMBARGO")
+			}
+			filter.Kinds = append(filter.Kinds, kind)
+		}
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **uuid.UUID
+	}{
+		{"film_id", &filter.FilmID},
+		{"creator_id", &filter.CreatorID},
+	} {
+		if v := c.Query(p.name); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = &id
+		}
+	}
+
+	return filter, nil
+}
+
+// validCalendarKind reports whether kind is a calendar entry kind
+func validCalendarKind(kind models.CalendarKind) bool {
+	for _, k := range models.CalendarKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
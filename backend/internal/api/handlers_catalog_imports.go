@@ -0,0 +1,193 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/arjunaayasa/filmtube/internal/urlimport"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CatalogImportHandler imports creators' existing catalogs from YouTube,
+// Vimeo or plain file URLs
+type CatalogImportHandler struct {
+	queries  *db.Queries
+	redis    *redis.Client
+	r2Client *r2.Client
+	settings *settings.Service
+}
+
+func NewCatalogImportHandler(queries *db.Queries, redisClient *redis.Client, r2Client *r2.Client, settingsService *settings.Service) *CatalogImportHandler {
+	return &CatalogImportHandler{queries: queries, redis: redisClient, r2Client: r2Client, settings: settingsService}
+}
+
+// CreateCatalogImportRequest lists the source URLs to import. Every film
+// created gets Type and, when set, belongs to the organization TenantID.
+type CreateCatalogImportRequest struct {
+	URLs     []string   `json:"urls" binding:"required,min=1,max=500,dive,required,max=2048"`
+	Type     string     `json:"type" binding:"required,oneof=SHORT_FILM FEATURE_FILM"`
+	TenantID *uuid.UUID `json:"tenant_id"`
+}
+
+// CreateCatalogImport validates the URLs and queues a worker task per URL
+// that creates a DRAFT film from the video's metadata, stores the video as
+// its source and queues its transcode
+func (h *CatalogImportHandler) CreateCatalogImport(c *gin.Context) {
+	var req CreateCatalogImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	if req.TenantID != nil {
+		if _, err := h.queries.GetTenantMemberRole(ctx, *req.TenantID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not a member of this organization"})
+			return
+		}
+	}
+
+	var schemes []string
+	if err := h.settings.Decode(ctx, settings.KeyImportURLSchemes, &schemes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load import settings"})
+		return
+	}
+	items := make([]models.CatalogImportItem, 0, len(req.URLs))
+	seen := map[string]bool{}
+	for i, raw := range req.URLs {
+		u, err := urlimport.ValidateURL(strings.TrimSpace(raw), schemes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("urls[%d]: %v", i, err), "schemes": schemes})
+			return
+		}
+		if seen[u.String()] {
+			continue
+		}
+		seen[u.String()] = true
+		items = append(items, models.CatalogImportItem{
+			ID:        uuid.New(),
+			Position:  len(items),
+			SourceURL: u.String(),
+			Provider:  models.DetectImportProvider(u),
+		})
+	}
+
+	imp := &models.CatalogImport{
+		ID:          uuid.New(),
+		CreatedByID: userID,
+		TenantID:    req.TenantID,
+		FilmType:    models.FilmType(req.Type),
+	}
+	if err := h.queries.CreateCatalogImport(ctx, imp, items); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create import"})
+		return
+	}
+	h.enqueueItems(c, imp, items)
+
+	c.JSON(http.StatusAccepted, gin.H{"import": imp, "items": items})
+}
+
+// ListCatalogImports returns the creator's imports with their progress
+func (h *CatalogImportHandler) ListCatalogImports(c *gin.Context) {
+	userID, _ := GetUserID(c)
+	imports, err := h.queries.ListCatalogImports(c.Request.Context(), userID, 50)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list imports"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"imports": imports})
+}
+
+// GetCatalogImport returns an import with every item's status
+func (h *CatalogImportHandler) GetCatalogImport(c *gin.Context) {
+	imp, ok := h.requireImport(c)
+	if !ok {
+		return
+	}
+	items, err := h.queries.ListCatalogImportItems(c.Request.Context(), imp.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list import items"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"import": imp, "items": items})
+}
+
+// RetryCatalogImport queues the import's failed items again. Items that
+// already created a film keep it.
+func (h *CatalogImportHandler) RetryCatalogImport(c *gin.Context) {
+	imp, ok := h.requireImport(c)
+	if !ok {
+		return
+	}
+	items, err := h.queries.ResetFailedCatalogImportItems(c.Request.Context(), imp.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset failed items"})
+		return
+	}
+	h.enqueueItems(c, imp, items)
+	c.JSON(http.StatusAccepted, gin.H{"retried": len(items)})
+}
+
+// enqueueItems queues a worker task per item in this deployment's source
+// region. An item that can't be queued is failed so it can be retried.
+func (h *CatalogImportHandler) enqueueItems(c *gin.Context, imp *models.CatalogImport, items []models.CatalogImportItem) {
+	ctx := c.Request.Context()
+	region := h.r2Client.SourceRegion()
+	for i := range items {
+		task := &models.WorkerTask{
+			ID:          uuid.New(),
+			Type:        models.TaskImportCatalogItem,
+			Region:      region,
+			Params:      map[string]string{"item_id": items[i].ID.String()},
+			RequestedBy: imp.CreatedByID,
+			CreatedAt:   time.Now(),
+		}
+		if err := h.redis.EnqueueTask(ctx, task); err != nil {
+			log.Printf("Failed to queue import item %s: %v", items[i].ID, err)
+			message := "failed to queue"
+			items[i].Status = models.ImportItemFailed
+			items[i].Error = &message
+			if err := h.queries.UpdateCatalogImportItem(ctx, &items[i]); err != nil {
+				log.Printf("Failed to record import item %s: %v", items[i].ID, err)
+			}
+		}
+	}
+}
+
+// requireImport loads the import from the :id param for its creator or an
+// admin. It writes the error response itself and returns false when the
+// request should stop.
+func (h *CatalogImportHandler) requireImport(c *gin.Context) (*models.CatalogImport, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid import ID"})
+		return nil, false
+	}
+	imp, err := h.queries.GetCatalogImport(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "import not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get import"})
+		return nil, false
+	}
+	userID, _ := GetUserID(c)
+	if imp.CreatedByID != userID && !isAdmin(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "import not found"})
+		return nil, false
+	}
+	return imp, true
+}
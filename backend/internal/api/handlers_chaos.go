@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/chaos"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ChaosHandler controls fault injection for resilience testing. Every
+// endpoint returns 404 unless CHAOS_ENABLED is set.
+type ChaosHandler struct {
+	injector *chaos.Injector
+}
+
+func NewChaosHandler(injector *chaos.Injector) *ChaosHandler {
+	return &ChaosHandler{injector: injector}
+}
+
+// ChaosRequest sets fault rates (0-1) for a limited time
+type ChaosRequest struct {
+	R2TimeoutRate     float64 `json:"r2_timeout_rate" binding:"min=0,max=1"`
+	RedisDropRate     float64 `json:"redis_drop_rate" binding:"min=0,max=1"`
+	FFmpegFailureRate float64 `json:"ffmpeg_failure_rate" binding:"min=0,max=1"`
+	DurationMinutes   int     `json:"duration_minutes" binding:"omitempty,min=1,max=1440"` // default 60
+}
+
+// GetChaos returns the fault rates in effect
+func (h *ChaosHandler) GetChaos(c *gin.Context) {
+	if !h.requireEnabled(c) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "faults": h.injector.Faults()})
+}
+
+// SetChaos starts injecting faults on every API and worker instance until
+// the duration runs out
+func (h *ChaosHandler) SetChaos(c *gin.Context) {
+	if !h.requireEnabled(c) {
+		return
+	}
+
+	var req ChaosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.DurationMinutes == 0 {
+		req.DurationMinutes = 60
+	}
+
+	userID, _ := GetUserID(c)
+	now := time.Now()
+	faults := &models.ChaosFaults{
+		R2TimeoutRate:     req.R2TimeoutRate,
+		RedisDropRate:     req.RedisDropRate,
+		FFmpegFailureRate: req.FFmpegFailureRate,
+		ExpiresAt:         now.Add(time.Duration(req.DurationMinutes) * time.Minute),
+		UpdatedByID:       &userID,
+		UpdatedAt:         now,
+	}
+	if err := h.injector.Set(c.Request.Context(), faults); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set fault rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "faults": faults})
+}
+
+// ClearChaos stops all fault injection
+func (h *ChaosHandler) ClearChaos(c *gin.Context) {
+	if !h.requireEnabled(c) {
+		return
+	}
+	if err := h.injector.Clear(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear fault rates"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Fault injection stopped"})
+}
+
+// requireEnabled hides the endpoints when fault injection is disabled
+func (h *ChaosHandler) requireEnabled(c *gin.Context) bool {
+	if !h.injector.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "fault injection is disabled"})
+		return false
+	}
+	return true
+}
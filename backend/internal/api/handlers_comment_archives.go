@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/comments"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	maxCommentArchiveBytes  = 20 << 20 // 20MB
+	commentExportExpiration = 1 * time.Hour
+)
+
+// ImportComments queues an import of a comment archive uploaded as the
+// multipart "file", e.g. history from another platform. Authors are mapped
+// to ghost users and original timestamps are kept. With dry_run=true the
+// worker only validates. The task's result carries the validation report.
+func (h *FilmHandler) ImportComments(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if fileHeader.Size > maxCommentArchiveBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("file exceeds %d bytes", maxCommentArchiveBytes)})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.PostForm("dry_run"))
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxCommentArchiveBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+
+	// Reject unreadable archives now; per-comment problems go in the report
+	var archive models.CommentArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid comment archive: " + err.Error()})
+		return
+	}
+	if len(archive.Comments) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "comment archive is empty"})
+		return
+	}
+	if len(archive.Comments) > comments.MaxArchiveComments {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("at most %d comments per import", comments.MaxArchiveComments)})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+
+	task := &models.WorkerTask{
+		ID:          uuid.New(),
+		Type:        models.TaskImportComments,
+		FilmID:      film.ID,
+		Params:      map[string]string{"dry_run": strconv.FormatBool(dryRun)},
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	key := r2.GetCommentImportKey(film.ID, task.ID)
+	if err := h.r2Client.UploadFile(ctx, key, bytes.NewReader(data), "application/json"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store comment archive"})
+		return
+	}
+	if err := h.redis.EnqueueTask(ctx, task); err != nil {
+		h.r2Client.DeleteFile(ctx, key)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue task"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Comment import queued",
+		"task":    task,
+	})
+}
+
+// ExportComments queues an export of a film's comment threads as an
+// archive that ImportComments accepts
+func (h *FilmHandler) ExportComments(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+
+	task := &models.WorkerTask{
+		ID:          uuid.New(),
+		Type:        models.TaskExportComments,
+		FilmID:      film.ID,
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.redis.EnqueueTask(ctx, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue task"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Comment export queued",
+		"task":    task,
+	})
+}
+
+// GetCommentExport returns a download URL for a finished comment export
+func (h *FilmHandler) GetCommentExport(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	task, err := h.redis.GetTask(ctx, taskID)
+	if err != nil || task.Type != models.TaskExportComments || task.FilmID != film.ID || task.RequestedBy != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "export not found"})
+		return
+	}
+
+	switch task.Status {
+	case models.TaskCompleted:
+	case models.TaskFailed:
+		c.JSON(http.StatusConflict, gin.H{"error": "export failed: " + task.Error})
+		return
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": "export is still being prepared"})
+		return
+	}
+
+	filename := fmt.Sprintf("comments-%s.json", film.ID)
+	url, err := h.r2Client.GeneratePresignedDownloadURL(ctx, task.Result["key"], filename, commentExportExpiration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate download URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"download_url": url,
+		"comments":     task.Result["comments"],
+		"expires_at":   time.Now().Add(commentExportExpiration),
+	})
+}
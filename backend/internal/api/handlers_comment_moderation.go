@@ -0,0 +1,158 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CommentSettingsRequest sets whether a film takes comments
+type CommentSettingsRequest struct {
+	Mode models.CommentMode `json:"mode" binding:"required,oneof=ENABLED HELD DISABLED"`
+}
+
+// CommentStatusRequest approves, restores or hides a comment
+type CommentStatusRequest struct {
+	Status models.CommentStatus `json:"status" binding:"required,oneof=VISIBLE HIDDEN"`
+}
+
+// UpdateCommentSettings sets a film's comment mode, the comments part of
+// its policy. Switching away from HELD leaves held comments waiting for
+// review.
+func (h *FilmHandler) UpdateCommentSettings(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req CommentSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.updatePolicy(c, film, func(p *models.FilmPolicy) { p.Comments = req.Mode }) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"film_id": film.ID, "comment_mode": req.Mode})
+}
+
+// ListHeldComments lists comments on the creator's film awaiting approval,
+// oldest first
+func (h *FilmHandler) ListHeldComments(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	params := pagination.ParseOffset(c)
+	comments, err := h.queries.ListHeldComments(ctx, film.ID, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list comments"})
+		return
+	}
+	total, err := h.queries.CountHeldComments(ctx, film.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(comments, params, total, false))
+}
+
+// SetCommentStatus lets a creator approve a held comment, hide a comment on
+// their film or restore one they hid. Approving notifies mentioned users.
+func (h *FilmHandler) SetCommentStatus(c *gin.Context) {
+	comment, ok := h.requireOwnedFilmComment(c)
+	if !ok {
+		return
+	}
+
+	var req CommentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	updated, err := h.queries.ModerateComment(ctx, comment.ID, userID, req.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to moderate comment"})
+		return
+	}
+	h.mentions.Notify(ctx, updated)
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// PinComment pins a visible top-level comment above the creator's film's
+// other comments, replacing the pinned one
+func (h *FilmHandler) PinComment(c *gin.Context) {
+	comment, ok := h.requireOwnedFilmComment(c)
+	if !ok {
+		return
+	}
+	if comment.ParentID != nil || comment.Status != models.CommentVisible {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "only visible top-level comments can be pinned"})
+		return
+	}
+
+	if err := h.queries.PinComment(c.Request.Context(), comment.FilmID, comment.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to pin comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment pinned"})
+}
+
+// UnpinComment unpins a comment on the creator's film
+func (h *FilmHandler) UnpinComment(c *gin.Context) {
+	comment, ok := h.requireOwnedFilmComment(c)
+	if !ok {
+		return
+	}
+	if comment.PinnedAt == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment is not pinned"})
+		return
+	}
+
+	if err := h.queries.UnpinComment(c.Request.Context(), comment.FilmID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unpin comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment unpinned"})
+}
+
+// requireOwnedFilmComment loads the :commentId comment on the current
+// user's :id film
+func (h *FilmHandler) requireOwnedFilmComment(c *gin.Context) (*models.Comment, bool) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return nil, false
+	}
+
+	commentID, err := uuid.Parse(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return nil, false
+	}
+	comment, err := h.queries.GetComment(c.Request.Context(), commentID)
+	if err != nil || comment.FilmID != film.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return nil, false
+	}
+	return comment, true
+}
@@ -0,0 +1,417 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/adminlists"
+	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/comments"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CommentHandler handles comment threads on films
+type CommentHandler struct {
+	queries  *db.Queries
+	settings *settings.Service
+	mentions *comments.Mentions
+}
+
+func NewCommentHandler(queries *db.Queries, settingsService *settings.Service, mentions *comments.Mentions) *CommentHandler {
+	return &CommentHandler{queries: queries, settings: settingsService, mentions: mentions}
+}
+
+// CommentRequest posts a comment, or a reply when ParentID is set
+type CommentRequest struct {
+	Body     string     `json:"body" binding:"required,max=5000"`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+// EditCommentRequest replaces a comment's text
+type EditCommentRequest struct {
+	Body string `json:"body" binding:"required,max=5000"`
+}
+
+// ReportCommentRequest reports a comment to moderators
+type ReportCommentRequest struct {
+	Category models.ReportCategory `json:"category"` // default other
+	Reason   string                `json:"reason" binding:"max=500"`
+}
+
+// ListComments lists a film's top-level comments, newest first or with the
+// most replies first (?sort=top)
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	sort := c.DefaultQuery("sort", db.DefaultCommentSort)
+	if _, ok := db.CommentSortOrders[sort]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be newest or top"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	eval := policy.For(film)
+	if !eval.CanSee(policyViewer(c, h.queries, film)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	if eval.Comments() == models.CommentsDisabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "comments are disabled for this film"})
+		return
+	}
+
+	viewerID, _ := GetUserID(c)
+	params := pagination.ParseOffset(c)
+	comments, err := h.queries.ListComments(ctx, filmID, viewerID, sort, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list comments"})
+		return
+	}
+	total, err := h.queries.CountComments(ctx, filmID, viewerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count comments"})
+		return
+	}
+	for i := range comments {
+		maskShadowHeld(&comments[i])
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(comments, params, total, false))
+}
+
+// ListReplies lists the replies to a top-level comment, oldest first
+func (h *CommentHandler) ListReplies(c *gin.Context) {
+	comment, ok := h.filmComment(c)
+	if !ok {
+		return
+	}
+	if comment.Status != models.CommentVisible {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+
+	viewerID, _ := GetUserID(c)
+	params := pagination.ParseOffset(c)
+	replies, err := h.queries.ListReplies(c.Request.Context(), comment.ID, viewerID, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list replies"})
+		return
+	}
+	for i := range replies {
+		maskShadowHeld(&replies[i])
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(replies, params, comment.ReplyCount, false))
+}
+
+// CreateComment comments on a ready film. Replies are one level deep, so a
+// reply to a reply joins its parent's thread. On films holding comments for
+// review, comments by anyone but the creator are held. Comments the film's
+// spam filter flags are shadow-held: the author is told they were posted.
+// Users @mentioned by handle are notified once the comment is visible.
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "comment body is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	eval := policy.For(film)
+	if !eval.CanSee(policyViewer(c, h.queries, film)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	if eval.Comments() == models.CommentsDisabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "comments are disabled for this film"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	comment := &models.Comment{FilmID: filmID, UserID: userID, Body: body, Status: models.CommentVisible}
+	if eval.Comments() == models.CommentsHeld && film.CreatedByID != userID {
+		comment.Status = models.CommentHeld
+	}
+	if film.CreatedByID != userID {
+		if reason := h.checkSpam(ctx, eval, body); reason != "" {
+			comment.SpamReason = &reason
+			if comment.Status == models.CommentVisible {
+				comment.Status = models.CommentShadowHeld
+			}
+		}
+	}
+	if req.ParentID != nil {
+		parent, err := h.queries.GetComment(ctx, *req.ParentID)
+		if err != nil || parent.FilmID != filmID || parent.Status != models.CommentVisible {
+			c.JSON(http.StatusNotFound, gin.H{"error": "parent comment not found"})
+			return
+		}
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		} else {
+			comment.ParentID = &parent.ID
+		}
+	}
+
+	err = h.queries.CreateComment(ctx, comment)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create comment"})
+		return
+	}
+
+	if created, err := h.queries.GetComment(ctx, comment.ID); err == nil {
+		comment = created
+	}
+	h.mentions.Update(ctx, comment)
+	if comment.Status == models.CommentHeld {
+		comment.SpamReason = nil
+		c.JSON(http.StatusAccepted, comment)
+		return
+	}
+	maskShadowHeld(comment)
+	c.JSON(http.StatusCreated, comment)
+}
+
+// EditComment lets a comment's author change its text within the edit
+// window. A visible comment edited into spam is shadow-held. Newly
+// mentioned users are notified.
+func (h *CommentHandler) EditComment(c *gin.Context) {
+	comment, ok := h.filmComment(c)
+	if !ok {
+		return
+	}
+
+	var req EditCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "comment body is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	if comment.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+		return
+	}
+	window := time.Duration(h.settings.Int(ctx, settings.KeyCommentEditWindow)) * time.Minute
+	if time.Since(comment.CreatedAt) > window {
+		c.JSON(http.StatusForbidden, gin.H{"error": "comment can no longer be edited"})
+		return
+	}
+
+	if err := h.queries.UpdateCommentBody(ctx, comment.ID, body); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update comment"})
+		return
+	}
+	updated, err := h.queries.GetComment(ctx, comment.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get comment"})
+		return
+	}
+	if film, err := h.queries.GetFilmByID(ctx, comment.FilmID); err == nil && film.CreatedByID != userID && updated.Status == models.CommentVisible {
+		if reason := h.checkSpam(ctx, policy.For(film), body); reason != "" {
+			if held, err := h.queries.ShadowHoldComment(ctx, updated.ID, reason); err == nil {
+				held.AuthorName, held.AuthorAvatarURL = updated.AuthorName, updated.AuthorAvatarURL
+				updated = held
+			}
+		}
+	}
+	h.mentions.Update(ctx, updated)
+
+	maskShadowHeld(updated)
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteComment removes a comment with its replies. The author, the film's
+// creator and admins can delete comments.
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	comment, ok := h.filmComment(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	role, _ := GetUserRole(c)
+	if comment.UserID != userID && !auth.IsAdmin(role) {
+		film, err := h.queries.GetFilmByID(ctx, comment.FilmID)
+		if err != nil || film.CreatedByID != userID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+			return
+		}
+	}
+
+	err := h.queries.DeleteComment(ctx, comment.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
+
+// ReportComment reports someone else's visible comment for moderation
+func (h *CommentHandler) ReportComment(c *gin.Context) {
+	comment, ok := h.filmComment(c)
+	if !ok {
+		return
+	}
+	if comment.Status != models.CommentVisible {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+
+	var req ReportCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Category == "" {
+		req.Category = models.ReportOther
+	}
+	if !req.Category.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report category"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	if comment.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot report your own comment"})
+		return
+	}
+
+	if err := h.queries.ReportComment(c.Request.Context(), comment.ID, userID, req.Category, strings.TrimSpace(req.Reason)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to report comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment reported for moderation"})
+}
+
+// ListReportedComments lists comments with open reports, most recently
+// reported first
+func (h *CommentHandler) ListReportedComments(c *gin.Context) {
+	serveAdminListing(c, h.queries, adminlists.ReportedComments)
+}
+
+// ModerateComment hides or restores a reported comment and resolves its
+// reports
+func (h *CommentHandler) ModerateComment(c *gin.Context) {
+	commentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	var req CommentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	comment, err := h.queries.ModerateComment(ctx, commentID, userID, req.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to moderate comment"})
+		return
+	}
+	h.mentions.Notify(ctx, comment)
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// checkSpam returns why a comment body looks like spam to the film's spam
+// filter and the platform's keywords, or ""
+func (h *CommentHandler) checkSpam(ctx context.Context, eval *policy.Evaluator, body string) string {
+	var keywords []string
+	if err := h.settings.Decode(ctx, settings.KeySpamKeywords, &keywords); err != nil {
+		log.Printf("[Comments] Failed to load spam keywords: %v", err)
+	}
+	return comments.CheckSpam(body, eval.SpamFilter(), keywords)
+}
+
+// maskShadowHeld shows a shadow-held comment, which only its author is
+// given, as posted
+func maskShadowHeld(comment *models.Comment) {
+	if comment.Status == models.CommentShadowHeld {
+		comment.Status = models.CommentVisible
+	}
+	comment.SpamReason = nil
+}
+
+// filmComment loads the :commentId comment, writing a 404 unless it
+// belongs to the :id film
+func (h *CommentHandler) filmComment(c *gin.Context) (*models.Comment, bool) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return nil, false
+	}
+	commentID, err := uuid.Parse(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return nil, false
+	}
+
+	comment, err := h.queries.GetComment(c.Request.Context(), commentID)
+	if err != nil || comment.FilmID != filmID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return nil, false
+	}
+	return comment, true
+}
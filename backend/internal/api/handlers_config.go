@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportConfig returns the platform configuration bundle as JSON or YAML
+func (h *SettingsHandler) ExportConfig(c *gin.Context) {
+	bundle, err := h.settings.Export(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export configuration"})
+		return
+	}
+
+	if c.DefaultQuery("format", "json") == "yaml" {
+		data, err := yaml.Marshal(bundle)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encode configuration"})
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="filmtube-config.yaml"`)
+		c.Data(http.StatusOK, "application/yaml", data)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="filmtube-config.json"`)
+	c.JSON(http.StatusOK, bundle)
+}
+
+// ImportConfig applies a configuration bundle. With ?dry_run=true the
+// changes are only previewed.
+func (h *SettingsHandler) ImportConfig(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+
+	var bundle settings.Bundle
+	if strings.Contains(c.ContentType(), "yaml") {
+		err = yaml.Unmarshal(body, &bundle)
+	} else {
+		err = json.Unmarshal(body, &bundle)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid configuration bundle: " + err.Error()})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	userID, _ := GetUserID(c)
+
+	changes, err := h.settings.Import(c.Request.Context(), &bundle, dryRun, userID)
+	var validationErr *settings.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import configuration"})
+		return
+	}
+
+	if changes == nil {
+		changes = []settings.Change{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run": dryRun,
+		"changes": changes,
+	})
+}
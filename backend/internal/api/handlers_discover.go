@@ -0,0 +1,58 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// discoverPoolSize is roughly how many rows discover samples before
+// weighting; small catalogs are scanned in full
+const discoverPoolSize = 2000
+
+// DiscoverFilms returns a random selection of published films for
+// "surprise me", favouring titles with fewer views. Accepts the same
+// filters as ListFilms; each call returns a different sample.
+func (h *FilmHandler) DiscoverFilms(c *gin.Context) {
+	filter, err := parseFilmFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.Status = models.StatusReady
+
+	country := GetCountry(c)
+	filter.Region = &country
+	filter.Listed = true
+
+	ctx := c.Request.Context()
+	limit := pagination.ParseLimit(c)
+
+	samplePercent := 100.0
+	if estimate, err := h.queries.EstimateFilmRows(ctx); err != nil {
+		log.Printf("Failed to estimate film rows: %v", err)
+	} else if estimate > discoverPoolSize {
+		samplePercent = discoverPoolSize / estimate * 100
+	}
+
+	films, err := h.queries.DiscoverFilms(ctx, filter, samplePercent, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to discover films"})
+		return
+	}
+
+	// Narrow filters can leave a sample short; fall back to a full scan
+	if len(films) < limit && samplePercent < 100 {
+		films, err = h.queries.DiscoverFilms(ctx, filter, 100, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to discover films"})
+			return
+		}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"items": films})
+}
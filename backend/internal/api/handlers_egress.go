@@ -0,0 +1,139 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// topEgressConsumers is how many films and creators the platform
+	// egress report ranks
+	topEgressConsumers = 25
+
+	// maxCDNEgressRecords bounds one CDN egress upload
+	maxCDNEgressRecords = 10000
+)
+
+// CDNEgressRequest uploads per-film daily byte counts from CDN logs
+type CDNEgressRequest struct {
+	Records []models.CDNEgress `json:"records" binding:"required,dive"`
+}
+
+// egressTotals sums daily egress
+func egressTotals(days []models.EgressDay) gin.H {
+	var estimated, bytes int64
+	cdnDays := 0
+	for _, d := range days {
+		estimated += d.EstimatedBytes
+		bytes += d.Bytes
+		if d.CDNBytes != nil {
+			cdnDays++
+		}
+	}
+	return gin.H{
+		"bytes":           bytes,
+		"estimated_bytes": estimated,
+		"days":            len(days),
+		"cdn_days":        cdnDays,
+	}
+}
+
+// GetCreatorEgress reports the bytes the creator's films delivered over a
+// range of days (from, to; default the last 30), in total, per day and per
+// film, for cost attribution. Bytes come from CDN logs where they were
+// ingested and are estimated from watch time otherwise. Days are rolled up
+// nightly, so today is not included.
+func (h *AnalyticsHandler) GetCreatorEgress(c *gin.Context) {
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	days, err := h.queries.ListEgressByDay(ctx, &userID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load egress"})
+		return
+	}
+	films, err := h.queries.ListFilmEgress(ctx, &userID, from, to.AddDate(0, 0, 1), 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load egress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":   from.Format("2006-01-02"),
+		"to":     to.Format("2006-01-02"),
+		"totals": egressTotals(days),
+		"daily":  days,
+		"films":  films,
+	})
+}
+
+// GetPlatformEgress reports platform-wide bytes delivered over a range of
+// days (from, to; default the last 30), per day, with the films and
+// creators that delivered the most
+func (h *AnalyticsHandler) GetPlatformEgress(c *gin.Context) {
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	days, err := h.queries.ListEgressByDay(ctx, nil, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load egress"})
+		return
+	}
+	films, err := h.queries.ListFilmEgress(ctx, nil, from, to.AddDate(0, 0, 1), topEgressConsumers)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rank films"})
+		return
+	}
+	creators, err := h.queries.ListCreatorEgress(ctx, from, to.AddDate(0, 0, 1), topEgressConsumers)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rank creators"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":         from.Format("2006-01-02"),
+		"to":           to.Format("2006-01-02"),
+		"totals":       egressTotals(days),
+		"daily":        days,
+		"top_films":    films,
+		"top_creators": creators,
+	})
+}
+
+// IngestCDNEgress stores per-film daily byte counts processed from CDN
+// logs. They replace the watch-time estimate for those films and days.
+func (h *AnalyticsHandler) IngestCDNEgress(c *gin.Context) {
+	var req CDNEgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Records) > maxCDNEgressRecords {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many records"})
+		return
+	}
+	for _, r := range req.Records {
+		if _, err := time.Parse("2006-01-02", r.Day); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid day " + r.Day})
+			return
+		}
+	}
+
+	stored, err := h.queries.RecordCDNEgress(c.Request.Context(), req.Records)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store CDN egress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stored": stored, "skipped": int64(len(req.Records)) - stored})
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/mail"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// EmailTemplateHandler manages organizations' and creators' overrides of
+// system email templates
+type EmailTemplateHandler struct {
+	queries       *db.Queries
+	organizations *OrganizationHandler
+}
+
+func NewEmailTemplateHandler(queries *db.Queries) *EmailTemplateHandler {
+	return &EmailTemplateHandler{queries: queries, organizations: NewOrganizationHandler(queries)}
+}
+
+// EmailTemplateRequest is an override of a system template. Subject and
+// text are optional and keep the system template's when empty.
+type EmailTemplateRequest struct {
+	Format  models.EmailFormat `json:"format" binding:"required"`
+	Subject string             `json:"subject"`
+	Body    string             `json:"body" binding:"required"`
+	Text    string             `json:"text"`
+}
+
+// PreviewEmailTemplateRequest renders a system template, or an override of
+// it when a body is given, with its sample variables and any given
+type PreviewEmailTemplateRequest struct {
+	Key       string                 `json:"key" binding:"required"`
+	Format    models.EmailFormat     `json:"format"`
+	Subject   string                 `json:"subject"`
+	Body      string                 `json:"body"`
+	Text      string                 `json:"text"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// ListSystemTemplates lists the system email templates with their
+// variables and defaults
+func (h *EmailTemplateHandler) ListSystemTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"templates": mail.SystemTemplates()})
+}
+
+// PreviewTemplate renders a template without saving it
+func (h *EmailTemplateHandler) PreviewTemplate(c *gin.Context) {
+	var req PreviewEmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t, ok := mail.SystemTemplate(req.Key)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "email template not found"})
+		return
+	}
+
+	var override *models.EmailTemplate
+	if req.Body != "" {
+		override = &models.EmailTemplate{Key: t.Key, Format: req.Format, Subject: req.Subject, Body: req.Body, TextBody: req.Text}
+		if override.Format == "" {
+			override.Format = models.EmailFormatHTML
+		}
+		if err := t.Compile(override); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	vars := make(map[string]interface{}, len(t.Sample))
+	for k, v := range t.Sample {
+		vars[k] = v
+	}
+	for k, v := range req.Variables {
+		if _, ok := t.Variables[k]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown variable " + k})
+			return
+		}
+		vars[k] = v
+	}
+
+	msg, err := t.Render(override, vars)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, msg)
+}
+
+// ListMyTemplates lists the current creator's template overrides
+func (h *EmailTemplateHandler) ListMyTemplates(c *gin.Context) {
+	h.listTemplates(c, h.creatorScope(c))
+}
+
+// SaveMyTemplate sets the current creator's override of a template
+func (h *EmailTemplateHandler) SaveMyTemplate(c *gin.Context) {
+	h.saveTemplate(c, h.creatorScope(c))
+}
+
+// DeleteMyTemplate drops the current creator's override of a template
+func (h *EmailTemplateHandler) DeleteMyTemplate(c *gin.Context) {
+	h.deleteTemplate(c, h.creatorScope(c))
+}
+
+// ListOrganizationTemplates lists an organization's template overrides
+func (h *EmailTemplateHandler) ListOrganizationTemplates(c *gin.Context) {
+	if scope, ok := h.organizationScope(c); ok {
+		h.listTemplates(c, scope)
+	}
+}
+
+// SaveOrganizationTemplate sets an organization's override of a template;
+// organization owners only
+func (h *EmailTemplateHandler) SaveOrganizationTemplate(c *gin.Context) {
+	if scope, ok := h.organizationScope(c); ok {
+		h.saveTemplate(c, scope)
+	}
+}
+
+// DeleteOrganizationTemplate drops an organization's override of a
+// template; organization owners only
+func (h *EmailTemplateHandler) DeleteOrganizationTemplate(c *gin.Context) {
+	if scope, ok := h.organizationScope(c); ok {
+		h.deleteTemplate(c, scope)
+	}
+}
+
+func (h *EmailTemplateHandler) creatorScope(c *gin.Context) models.EmailTemplateScope {
+	userID, _ := GetUserID(c)
+	return models.EmailTemplateScope{CreatorID: &userID}
+}
+
+// organizationScope is the scope of the organization named by :id, whose
+// templates only its owners manage
+func (h *EmailTemplateHandler) organizationScope(c *gin.Context) (models.EmailTemplateScope, bool) {
+	tenant, ok := h.organizations.requireOwner(c)
+	if !ok {
+		return models.EmailTemplateScope{}, false
+	}
+	return models.EmailTemplateScope{TenantID: &tenant.ID}, true
+}
+
+func (h *EmailTemplateHandler) listTemplates(c *gin.Context, scope models.EmailTemplateScope) {
+	templates, err := h.queries.ListEmailTemplates(c.Request.Context(), scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list email templates"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+func (h *EmailTemplateHandler) saveTemplate(c *gin.Context, scope models.EmailTemplateScope) {
+	t, ok := mail.SystemTemplate(c.Param("key"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "email template not found"})
+		return
+	}
+
+	var req EmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	template := &models.EmailTemplate{
+		Key:         t.Key,
+		TenantID:    scope.TenantID,
+		CreatorID:   scope.CreatorID,
+		Format:      req.Format,
+		Subject:     req.Subject,
+		Body:        req.Body,
+		TextBody:    req.Text,
+		UpdatedByID: &userID,
+	}
+	if err := t.Compile(template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := t.Render(template, t.Sample); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.queries.UpsertEmailTemplate(c.Request.Context(), template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save email template"})
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *EmailTemplateHandler) deleteTemplate(c *gin.Context, scope models.EmailTemplateScope) {
+	deleted, err := h.queries.DeleteEmailTemplate(c.Request.Context(), c.Param("key"), scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete email template"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "email template not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Email template reset to the default"})
+}
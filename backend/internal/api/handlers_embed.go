@@ -0,0 +1,185 @@
+package api
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	oembedProvider      = "FilmTube"
+	oembedDefaultWidth  = 640
+	oembedDefaultHeight = 360
+)
+
+// UpdateEmbedSettingsRequest sets whether and where a film may be embedded
+type UpdateEmbedSettingsRequest struct {
+	Embeds *bool `json:"embeds" binding:"required"`
+	// Domains limits embedding to these sites and their subdomains; empty
+	// allows any site
+	Domains []string `json:"domains"`
+}
+
+// UpdateEmbedSettings sets the embed part of a film's policy
+func (h *FilmHandler) UpdateEmbedSettings(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateEmbedSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	domains, err := policy.NormalizeEmbedDomains(req.Domains)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "domains: " + err.Error()})
+		return
+	}
+
+	if !h.updatePolicy(c, film, func(p *models.FilmPolicy) {
+		p.Embeds = *req.Embeds
+		p.EmbedDomains = domains
+	}) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"embeds":  *req.Embeds,
+		"domains": domains,
+	})
+}
+
+// OEmbedHandler describes films to oEmbed consumers, such as chat apps
+// and CMSs unfurling a pasted link
+type OEmbedHandler struct {
+	queries *db.Queries
+	appURL  string
+}
+
+func NewOEmbedHandler(queries *db.Queries, appURL string) *OEmbedHandler {
+	return &OEmbedHandler{
+		queries: queries,
+		appURL:  strings.TrimRight(appURL, "/"),
+	}
+}
+
+// GetOEmbed returns the oEmbed video response for a film page, embed or
+// share link URL. Only published films that are not private and allow
+// embedding are described; share links keep their start offset.
+func (h *OEmbedHandler) GetOEmbed(c *gin.Context) {
+	if format := c.DefaultQuery("format", "json"); format != "json" {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "only the json format is supported"})
+		return
+	}
+
+	filmID, start, ok := h.resolve(c, c.Query("url"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	film, err := h.queries.GetFilmByID(c.Request.Context(), filmID)
+	if err != nil || film.Status != models.StatusReady || film.PublishedAt == nil || !policy.For(film).CanSee(policy.Viewer{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	// oEmbed answers 401 for resources that exist but may not be embedded
+	if !policy.For(film).AllowsEmbeds() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": policy.ErrEmbed.Error()})
+		return
+	}
+
+	width, height := oembedSize(c.Query("maxwidth"), c.Query("maxheight"))
+	src := h.appURL + "/embed/" + film.ID.String()
+	if start > 0 {
+		src += "?t=" + strconv.Itoa(start)
+	}
+	iframe := fmt.Sprintf(
+		`<iframe src="%s" width="%d" height="%d" frameborder="0" allow="autoplay; fullscreen; picture-in-picture" allowfullscreen title="%s"></iframe>`,
+		html.EscapeString(src), width, height, html.EscapeString(film.Title),
+	)
+
+	resp := gin.H{
+		"version":       "1.0",
+		"type":          "video",
+		"title":         film.Title,
+		"provider_name": oembedProvider,
+		"provider_url":  h.appURL,
+		"html":          iframe,
+		"width":         width,
+		"height":        height,
+	}
+	if film.Attribution != "" {
+		resp["author_name"] = film.Attribution
+	} else if creator, err := h.queries.GetUserByID(c.Request.Context(), film.CreatedByID); err == nil {
+		resp["author_name"] = creator.Name
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, resp)
+}
+
+// resolve maps a film page (/films/:id), embed (/embed/:id) or share link
+// (/s/:code) URL on the app to a film and start offset
+func (h *OEmbedHandler) resolve(c *gin.Context, raw string) (uuid.UUID, int, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" {
+		return uuid.Nil, 0, false
+	}
+	app, err := url.Parse(h.appURL)
+	if err != nil || !strings.EqualFold(u.Hostname(), app.Hostname()) {
+		return uuid.Nil, 0, false
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 2 {
+		return uuid.Nil, 0, false
+	}
+	switch parts[0] {
+	case "films", "embed":
+		filmID, err := uuid.Parse(parts[1])
+		if err != nil {
+			return uuid.Nil, 0, false
+		}
+		start := 0
+		if t := u.Query().Get("t"); t != "" {
+			// The player range-checks the offset against the film
+			if start, err = parseStartOffset(t, 0); err != nil {
+				start = 0
+			}
+		}
+		return filmID, start, true
+	case "s":
+		link, err := h.queries.GetShareLink(c.Request.Context(), parts[1])
+		if err != nil {
+			return uuid.Nil, 0, false
+		}
+		return link.FilmID, link.StartSeconds, true
+	}
+	return uuid.Nil, 0, false
+}
+
+// oembedSize fits the player's 16:9 frame within the consumer's
+// maxwidth and maxheight
+func oembedSize(maxWidth, maxHeight string) (int, int) {
+	width, height := oembedDefaultWidth, oembedDefaultHeight
+	if w, err := strconv.Atoi(maxWidth); err == nil && w > 0 && w < width {
+		width, height = w, w*9/16
+	}
+	if h, err := strconv.Atoi(maxHeight); err == nil && h > 0 && h < height {
+		width, height = h*16/9, h
+	}
+	return width, height
+}
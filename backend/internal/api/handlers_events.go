@@ -0,0 +1,119 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/events"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultBackfillLimit = 100
+	defaultBackfillRate  = 50
+)
+
+// EventHandler handles the film event stream
+type EventHandler struct {
+	queries *db.Queries
+	redis   *redis.Client
+}
+
+func NewEventHandler(queries *db.Queries, redisClient *redis.Client) *EventHandler {
+	return &EventHandler{
+		queries: queries,
+		redis:   redisClient,
+	}
+}
+
+// EventBackfillRequest selects the films and events to re-emit
+type EventBackfillRequest struct {
+	// FilmIDs replays these films; otherwise films are paged in ID order
+	// from AfterID
+	FilmIDs []uuid.UUID            `json:"film_ids" binding:"omitempty,max=500"`
+	AfterID *uuid.UUID             `json:"after_id"`
+	Limit   int                    `json:"limit" binding:"omitempty,min=1,max=500"` // films per call
+	Types   []models.FilmEventType `json:"types"`                                   // default all
+	Rate    int                    `json:"rate" binding:"omitempty,min=1,max=1000"` // events per second
+	DryRun  bool                   `json:"dry_run"`
+}
+
+// BackfillEvents re-emits the lifecycle events films have been through to
+// the film event stream, so a new consumer can catch up on history.
+// Events are marked replayed and published no faster than rate per
+// second. A dry run lists them without publishing. When paging,
+// next_after_id continues with the following films.
+func (h *EventHandler) BackfillEvents(c *gin.Context) {
+	var req EventBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, t := range req.Types {
+		if !events.ValidType(t) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown event type %q", t)})
+			return
+		}
+	}
+	if len(req.FilmIDs) > 0 && req.AfterID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "film_ids and after_id cannot be combined"})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultBackfillLimit
+	}
+	if req.Rate == 0 {
+		req.Rate = defaultBackfillRate
+	}
+	if len(req.FilmIDs) > 0 {
+		req.Limit = len(req.FilmIDs)
+	}
+
+	ctx := c.Request.Context()
+	films, err := h.queries.ListFilmLifecycles(ctx, req.FilmIDs, req.AfterID, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list films"})
+		return
+	}
+
+	replay := []models.FilmEvent{}
+	for i := range films {
+		replay = append(replay, events.Lifecycle(&films[i], req.Types)...)
+	}
+
+	var nextAfter *uuid.UUID
+	if len(req.FilmIDs) == 0 && len(films) == req.Limit {
+		nextAfter = &films[len(films)-1].FilmID
+	}
+
+	if req.DryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"dry_run":       true,
+			"films":         len(films),
+			"emitted":       0,
+			"events":        replay,
+			"next_after_id": nextAfter,
+		})
+		return
+	}
+
+	emitted, err := events.Replay(ctx, h.redis, replay, req.Rate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to emit events",
+			"emitted": emitted,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run":       false,
+		"films":         len(films),
+		"emitted":       emitted,
+		"next_after_id": nextAfter,
+	})
+}
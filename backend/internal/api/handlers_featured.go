@@ -0,0 +1,281 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const featuredArtworkExpiration = 30 * time.Minute
+
+// featuredArtworkTypes maps accepted hero artwork content types to the
+// extension used in the storage key
+var featuredArtworkTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// FeaturedRequest schedules or edits a hero slot; on update, omitted fields
+// keep their current value
+type FeaturedRequest struct {
+	FilmID       *uuid.UUID `json:"film_id"`
+	Headline     *string    `json:"headline"`
+	Tagline      *string    `json:"tagline"`
+	HeroImageKey *string    `json:"hero_image_key"` // from artwork-url; "" clears it
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	ClearEndsAt  bool       `json:"clear_ends_at"` // pin indefinitely
+	Priority     *int       `json:"priority"`
+}
+
+// GetFeatured returns the hero film to show on the home page right now, or
+// null when nothing is scheduled for the viewer's region
+func (h *FilmHandler) GetFeatured(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	slots, err := h.queries.ListLiveFeaturedFilms(ctx, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load featured film"})
+		return
+	}
+
+	country := GetCountry(c)
+	for _, slot := range slots {
+		film, err := h.queries.GetFilmByID(ctx, slot.FilmID)
+		if err != nil || !policy.For(film).Listed(country) {
+			continue
+		}
+		h.resolveHeroImage(&slot, film)
+		c.JSON(http.StatusOK, gin.H{"featured": models.Featured{FeaturedFilm: slot, Film: *film}})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"featured": nil})
+}
+
+// ListFeatured returns every scheduled hero slot, past and future
+func (h *FilmHandler) ListFeatured(c *gin.Context) {
+	slots, err := h.queries.ListFeaturedFilms(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list featured films"})
+		return
+	}
+
+	now := time.Now()
+	items := make([]gin.H, len(slots))
+	for i := range slots {
+		h.resolveHeroImage(&slots[i], nil)
+		items[i] = gin.H{"slot": slots[i], "live": slots[i].LiveAt(now)}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"featured": items})
+}
+
+// CreateFeatured schedules a film into the hero slot
+func (h *FilmHandler) CreateFeatured(c *gin.Context) {
+	var req FeaturedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.FilmID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "film_id is required"})
+		return
+	}
+	if req.HeroImageKey != nil && *req.HeroImageKey != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "upload hero artwork after creating the slot"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	slot := &models.FeaturedFilm{StartsAt: time.Now(), CreatedByID: &userID}
+	if !h.applyFeaturedRequest(c, slot, &req) {
+		return
+	}
+
+	if err := h.queries.CreateFeaturedFilm(c.Request.Context(), slot); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create featured film"})
+		return
+	}
+
+	h.resolveHeroImage(slot, nil)
+	c.JSON(http.StatusCreated, slot)
+}
+
+// UpdateFeatured edits a hero slot's film, copy, artwork or schedule
+func (h *FilmHandler) UpdateFeatured(c *gin.Context) {
+	slot, ok := h.requireFeatured(c)
+	if !ok {
+		return
+	}
+
+	var req FeaturedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.HeroImageKey != nil {
+		switch key := *req.HeroImageKey; {
+		case key == "":
+			slot.HeroImageKey = nil
+		case strings.HasPrefix(key, r2.GetFeaturedArtworkPrefix(slot.ID)):
+			slot.HeroImageKey = &key
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "hero_image_key must come from this slot's artwork-url"})
+			return
+		}
+	}
+	if !h.applyFeaturedRequest(c, slot, &req) {
+		return
+	}
+
+	if err := h.queries.UpdateFeaturedFilm(c.Request.Context(), slot); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update featured film"})
+		return
+	}
+
+	h.resolveHeroImage(slot, nil)
+	c.JSON(http.StatusOK, slot)
+}
+
+// DeleteFeatured removes a hero slot
+func (h *FilmHandler) DeleteFeatured(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid featured ID"})
+		return
+	}
+
+	err = h.queries.DeleteFeaturedFilm(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "featured film not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete featured film"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Featured film removed"})
+}
+
+// GetFeaturedArtworkURL generates a pre-signed URL for uploading custom hero
+// artwork; the returned key is then set as hero_image_key via update
+func (h *FilmHandler) GetFeaturedArtworkURL(c *gin.Context) {
+	slot, ok := h.requireFeatured(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ContentType string `json:"content_type" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ext, ok := featuredArtworkTypes[req.ContentType]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "content_type must be image/jpeg, image/png or image/webp"})
+		return
+	}
+
+	key := r2.GetFeaturedArtworkKey(slot.ID, ext)
+	uploadURL, err := h.r2Client.GeneratePresignedUploadURLForKey(c.Request.Context(), key, req.ContentType, featuredArtworkExpiration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate upload URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"upload_url": uploadURL,
+		"key":        key,
+		"expiration": featuredArtworkExpiration.String(),
+	})
+}
+
+// requireFeatured loads the hero slot named by the :id param, writing the
+// error response itself when it is missing
+func (h *FilmHandler) requireFeatured(c *gin.Context) (*models.FeaturedFilm, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid featured ID"})
+		return nil, false
+	}
+
+	slot, err := h.queries.GetFeaturedFilm(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "featured film not found"})
+		return nil, false
+	}
+	return slot, true
+}
+
+// applyFeaturedRequest copies the request's film, copy and schedule fields
+// onto the slot and validates the result
+func (h *FilmHandler) applyFeaturedRequest(c *gin.Context, slot *models.FeaturedFilm, req *FeaturedRequest) bool {
+	if req.FilmID != nil && *req.FilmID != slot.FilmID {
+		film, err := h.queries.GetFilmByID(c.Request.Context(), *req.FilmID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "film not found"})
+			return false
+		}
+		if film.Status != models.StatusReady || film.PublishedAt == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "only published films can be featured"})
+			return false
+		}
+		slot.FilmID = film.ID
+	}
+	if req.Headline != nil {
+		slot.Headline = nullIfEmpty(*req.Headline)
+	}
+	if req.Tagline != nil {
+		slot.Tagline = nullIfEmpty(*req.Tagline)
+	}
+	if req.StartsAt != nil {
+		slot.StartsAt = *req.StartsAt
+	}
+	if req.ClearEndsAt {
+		slot.EndsAt = nil
+	} else if req.EndsAt != nil {
+		slot.EndsAt = req.EndsAt
+	}
+	if req.Priority != nil {
+		slot.Priority = *req.Priority
+	}
+
+	if slot.EndsAt != nil && !slot.EndsAt.After(slot.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return false
+	}
+	return true
+}
+
+// resolveHeroImage fills HeroImageURL from the custom artwork, falling back
+// to the film's thumbnail when a film is given
+func (h *FilmHandler) resolveHeroImage(slot *models.FeaturedFilm, film *models.Film) {
+	switch {
+	case slot.HeroImageKey != nil:
+		slot.HeroImageURL = h.r2Client.GetPublicURL(*slot.HeroImageKey)
+	case film != nil:
+		slot.HeroImageURL = film.ThumbnailURL
+	}
+}
+
+// nullIfEmpty maps an empty string to NULL for optional text columns
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/feeds"
+	"github.com/arjunaayasa/filmtube/internal/playlists"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// feedTTL is how long rendered sitemaps and feeds are cached, in Redis
+	// and by clients
+	feedTTL = 15 * time.Minute
+
+	// feedItems is how many films an RSS feed carries
+	feedItems = 50
+)
+
+// FeedHandler serves the sitemap and RSS feeds of published films
+type FeedHandler struct {
+	queries   *db.Queries
+	redis     *redis.Client
+	playlists *playlists.Service
+	appURL    string
+}
+
+func NewFeedHandler(queries *db.Queries, redisClient *redis.Client, playlistService *playlists.Service, appURL string) *FeedHandler {
+	return &FeedHandler{
+		queries:   queries,
+		redis:     redisClient,
+		playlists: playlistService,
+		appURL:    strings.TrimRight(appURL, "/"),
+	}
+}
+
+// GetSitemap returns the sitemap of the home page and every published film
+func (h *FeedHandler) GetSitemap(c *gin.Context) {
+	h.serve(c, "sitemap", "application/xml; charset=utf-8", func() ([]byte, error) {
+		films, err := h.queries.ListSitemapFilms(c.Request.Context(), feeds.MaxSitemapURLs-1)
+		if err != nil {
+			return nil, err
+		}
+		return feeds.Sitemap(h.appURL, films)
+	})
+}
+
+// GetFilmsFeed returns an RSS feed of the latest published films
+func (h *FeedHandler) GetFilmsFeed(c *gin.Context) {
+	h.serve(c, "films", "application/rss+xml; charset=utf-8", func() ([]byte, error) {
+		films, err := h.queries.ListFeedFilms(c.Request.Context(), nil, feedItems)
+		if err != nil {
+			return nil, err
+		}
+		channel := feeds.Channel{
+			Title:       "FilmTube: New films",
+			Link:        h.appURL + "/",
+			Description: "The latest films published on FilmTube",
+		}
+		return feeds.RSS(h.appURL, channel, films, time.Now())
+	})
+}
+
+// GetCreatorFeed returns an RSS feed of a creator's latest published films
+func (h *FeedHandler) GetCreatorFeed(c *gin.Context) {
+	creatorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid creator ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	creator, err := h.queries.GetUserByID(ctx, creatorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "creator not found"})
+		return
+	}
+
+	h.serve(c, "creator:"+creatorID.String(), "application/rss+xml; charset=utf-8", func() ([]byte, error) {
+		films, err := h.queries.ListFeedFilms(ctx, &creatorID, feedItems)
+		if err != nil {
+			return nil, err
+		}
+		channel := feeds.Channel{
+			Title:       "FilmTube: " + creator.Name,
+			Link:        h.appURL + "/",
+			Description: fmt.Sprintf("The latest films from %s on FilmTube", creator.Name),
+		}
+		return feeds.RSS(h.appURL, channel, films, time.Now())
+	})
+}
+
+// GetPlaylistFeed returns an RSS feed of a public playlist's films in
+// playlist order
+func (h *FeedHandler) GetPlaylistFeed(c *gin.Context) {
+	playlistID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid playlist ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	playlist, err := h.queries.GetPlaylist(ctx, playlistID)
+	if err != nil || !playlist.Public {
+		c.JSON(http.StatusNotFound, gin.H{"error": "playlist not found"})
+		return
+	}
+
+	h.serve(c, "playlist:"+playlistID.String(), "application/rss+xml; charset=utf-8", func() ([]byte, error) {
+		ids, err := h.playlists.FilmIDs(ctx, playlist)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > feedItems {
+			ids = ids[:feedItems]
+		}
+		films, err := h.queries.ListFeedFilmsByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		description := playlist.Description
+		if description == "" {
+			description = fmt.Sprintf("Films in the %s playlist on FilmTube", playlist.Title)
+		}
+		channel := feeds.Channel{
+			Title:       "FilmTube: " + playlist.Title,
+			Link:        fmt.Sprintf("%s/playlists/%s", h.appURL, playlist.ID),
+			Description: description,
+		}
+		return feeds.RSS(h.appURL, channel, films, time.Now())
+	})
+}
+
+// serve writes the cached document called name, rendering and caching it
+// first on a miss. A failed cache read or write only costs a render.
+func (h *FeedHandler) serve(c *gin.Context, name, contentType string, render func() ([]byte, error)) {
+	ctx := c.Request.Context()
+
+	data, err := h.redis.GetFeed(ctx, name)
+	if err != nil {
+		data, err = render()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate feed"})
+			return
+		}
+		h.redis.SetFeed(ctx, name, data, feedTTL)
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(feedTTL.Seconds())))
+	c.Data(http.StatusOK, contentType, data)
+}
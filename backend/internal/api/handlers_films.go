@@ -1,15 +1,39 @@
 package api
 
 import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/arjunaayasa/filmtube/internal/admission"
+	"github.com/arjunaayasa/filmtube/internal/approval"
+	"github.com/arjunaayasa/filmtube/internal/beacon"
+	"github.com/arjunaayasa/filmtube/internal/comments"
 	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/entitlements"
+	"github.com/arjunaayasa/filmtube/internal/events"
 	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/playbacklog"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/arjunaayasa/filmtube/internal/positions"
+	"github.com/arjunaayasa/filmtube/internal/press"
 	"github.com/arjunaayasa/filmtube/internal/r2"
 	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/search"
+	"github.com/arjunaayasa/filmtube/internal/settings"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 )
 
 // FilmHandler handles film endpoints
@@ -17,14 +41,38 @@ type FilmHandler struct {
 	queries    *db.Queries
 	r2Client   *r2.Client
 	redis      *redis.Client
+	search     search.Search
+	indexer    *search.Indexer
+	approvals  *approval.Workflow
+	press      *press.Service
+	settings   *settings.Service
+	beacons    *beacon.Signer
+	mentions   *comments.Mentions
+	playback   *playbacklog.Logger
+	positions  *positions.Store
+	plans      *entitlements.Service
+	admission  *admission.Controller
+	apiURL     string // base URL of capped playback manifests
 	expiration int // minutes for upload URLs
 }
 
-func NewFilmHandler(queries *db.Queries, r2Client *r2.Client, redisClient *redis.Client, uploadExpirationMinutes int) *FilmHandler {
+func NewFilmHandler(queries *db.Queries, r2Client *r2.Client, redisClient *redis.Client, searchBackend search.Search, indexer *search.Indexer, approvals *approval.Workflow, pressService *press.Service, settingsService *settings.Service, beacons *beacon.Signer, mentions *comments.Mentions, playbackLogs *playbacklog.Logger, positionStore *positions.Store, plans *entitlements.Service, admissionControl *admission.Controller, apiURL string, uploadExpirationMinutes int) *FilmHandler {
 	return &FilmHandler{
 		queries:    queries,
 		r2Client:   r2Client,
 		redis:      redisClient,
+		search:     searchBackend,
+		indexer:    indexer,
+		approvals:  approvals,
+		press:      pressService,
+		settings:   settingsService,
+		beacons:    beacons,
+		mentions:   mentions,
+		playback:   playbackLogs,
+		positions:  positionStore,
+		plans:      plans,
+		admission:  admissionControl,
+		apiURL:     strings.TrimRight(apiURL, "/"),
 		expiration: uploadExpirationMinutes,
 	}
 }
@@ -34,6 +82,27 @@ type CreateFilmRequest struct {
 	Title       string `json:"title" binding:"required,max=500"`
 	Description string `json:"description"`
 	Type        string `json:"type" binding:"required,oneof=SHORT_FILM FEATURE_FILM"`
+	Tags        []string `json:"tags" binding:"omitempty,max=20,dive,max=50"`
+	Genre       string `json:"genre" binding:"omitempty,max=50"`
+	TenantID    *uuid.UUID `json:"tenant_id"` // organization the film belongs to
+}
+
+// UploadURLRequest declares the source about to be uploaded. The upload URL
+// only accepts FileSize bytes of ContentType and, when SHA256 is given,
+// only a body with that hex SHA-256. Multipart starts a multipart upload
+// instead of issuing a single PUT URL.
+type UploadURLRequest struct {
+	FileSize    int64  `json:"file_size" binding:"required,min=1"`
+	ContentType string `json:"content_type" binding:"required"`
+	SHA256      string `json:"sha256" binding:"omitempty,len=64,hexadecimal"`
+	TotalParts  int    `json:"total_parts" binding:"omitempty,min=1,max=10000"`
+	Multipart   bool   `json:"multipart"`
+}
+
+// ConfirmUploadRequest optionally carries the client's hex SHA-256 of the
+// source, checked against the stored object before transcoding
+type ConfirmUploadRequest struct {
+	SHA256 string `json:"sha256" binding:"omitempty,len=64,hexadecimal"`
 }
 
 // UpdateFilmRequest represents film update input
@@ -52,13 +121,23 @@ func (h *FilmHandler) CreateFilm(c *gin.Context) {
 
 	userID, _ := GetUserID(c)
 
+	if req.TenantID != nil {
+		if _, err := h.queries.GetTenantMemberRole(c.Request.Context(), *req.TenantID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not a member of this organization"})
+			return
+		}
+	}
+
 	film := &models.Film{
 		ID:           uuid.New(),
 		Title:        req.Title,
 		Description:  req.Description,
 		Type:         models.FilmType(req.Type),
 		Status:       models.StatusDraft,
+		Tags:         req.Tags,
+		Genre:        req.Genre,
 		CreatedByID:  userID,
+		TenantID:     req.TenantID,
 	}
 
 	if err := h.queries.CreateFilm(c.Request.Context(), film); err != nil {
@@ -66,10 +145,14 @@ func (h *FilmHandler) CreateFilm(c *gin.Context) {
 		return
 	}
 
+	h.indexer.SyncFilmAsync(film.ID)
+	events.Emit(c.Request.Context(), h.redis, models.FilmEventCreated, film.ID)
+
 	c.JSON(http.StatusCreated, film)
 }
 
-// GetFilm retrieves a film by ID
+// GetFilm retrieves a film by ID; private films only for their creator
+// and admins
 func (h *FilmHandler) GetFilm(c *gin.Context) {
 	idParam := c.Param("id")
 	filmID, err := uuid.Parse(idParam)
@@ -79,49 +162,336 @@ func (h *FilmHandler) GetFilm(c *gin.Context) {
 	}
 
 	film, err := h.queries.GetFilmByID(c.Request.Context(), filmID)
-	if err != nil {
+	if err != nil || !policy.For(film).CanSee(policyViewer(c, h.queries, film)) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, film)
+	films := []models.Film{*film}
+	attachViewerState(c, h.queries, films)
+
+	c.JSON(http.StatusOK, films[0])
 }
 
 // ListFilms retrieves films with pagination
 func (h *FilmHandler) ListFilms(c *gin.Context) {
 	// Parse pagination params
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	statusStr := c.DefaultQuery("status", "")
+	params := pagination.ParseOffset(c)
+	sort := c.DefaultQuery("sort", db.DefaultFilmSort)
 
-	if page < 1 {
-		page = 1
+	if _, ok := db.FilmSortOrders[sort]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be one of views, newest, oldest, duration, title"})
+		return
 	}
-	if limit < 1 || limit > 100 {
-		limit = 20
+
+	filter, err := parseFilmFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	offset := (page - 1) * limit
-	var status models.FilmStatus
-	if statusStr == "READY" {
-		status = models.StatusReady
-	} else {
-		status = ""
+	// Hide titles not licensed for the viewer's region or not listed
+	country := GetCountry(c)
+	filter.Region = &country
+	filter.Listed = true
+
+	ctx := c.Request.Context()
+
+	// Newest-first listings use cursor pagination unless the client asks
+	// for a page number (legacy mode, also used for the other sort orders)
+	if sort == db.DefaultFilmSort && c.Query("page") == "" {
+		h.listFilmsByCursor(c, filter)
+		return
 	}
 
-	films, err := h.queries.ListFilms(c.Request.Context(), limit, offset, status)
+	films, err := h.queries.ListFilms(ctx, filter, sort, params.Limit, params.Offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve films"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"films": films,
-		"page":  page,
-		"limit": limit,
+	total, cached, err := h.countFilms(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count films"})
+		return
+	}
+
+	h.respondFilmList(c, filter, pagination.NewOffsetPage(films, params, total, cached))
+}
+
+// listFilmsByCursor serves ListFilms with opaque keyset cursors
+func (h *FilmHandler) listFilmsByCursor(c *gin.Context, filter db.FilmFilter) {
+	limit := pagination.ParseLimit(c)
+	ctx := c.Request.Context()
+
+	var after *db.FilmCursor
+	if cursor := c.Query("cursor"); cursor != "" {
+		after = &db.FilmCursor{}
+		if err := pagination.DecodeCursor(cursor, after); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+	}
+
+	films, err := h.queries.ListFilmsAfter(ctx, filter, after, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve films"})
+		return
+	}
+
+	total, cached, err := h.countFilms(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count films"})
+		return
+	}
+
+	h.respondFilmList(c, filter, pagination.NewCursorPage(films, limit, total, cached, func(last models.Film) string {
+		return pagination.EncodeCursor(db.FilmCursor{
+			PublishedAt: last.PublishedAt,
+			CreatedAt:   last.CreatedAt,
+			ID:          last.ID,
+		})
+	}))
+}
+
+// filmListResponse is the film listing envelope, with facet counts when
+// requested
+type filmListResponse struct {
+	pagination.Page[models.Film]
+	Facets *models.FilmFacets `json:"facets,omitempty"`
+}
+
+// respondFilmList writes a listing page, adding facet counts for
+// ?facets=true
+func (h *FilmHandler) respondFilmList(c *gin.Context, filter db.FilmFilter, page pagination.Page[models.Film]) {
+	attachViewerState(c, h.queries, page.Items)
+	resp := filmListResponse{Page: page}
+	if c.Query("facets") == "true" {
+		facets, err := h.filmFacets(c.Request.Context(), filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count facets"})
+			return
+		}
+		resp.Facets = facets
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseFilmFilter reads catalog filters from the query string
+func parseFilmFilter(c *gin.Context) (db.FilmFilter, error) {
+	var filter db.FilmFilter
+
+	if c.Query("status") == string(models.StatusReady) {
+		filter.Status = models.StatusReady
+	}
+
+	if v := c.Query("creator_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid creator_id")
+		}
+		filter.CreatorID = &id
+	}
+
+	switch t := models.FilmType(c.Query("type")); t {
+	case "":
+	case models.FilmTypeShortFilm, models.FilmTypeFeatureFilm:
+		filter.Type = t
+	default:
+		return filter, fmt.Errorf("type must be SHORT_FILM or FEATURE_FILM")
+	}
+
+	filter.Genre = c.Query("genre")
+
+	for _, p := range []struct {
+		name string
+		dst  **int
+	}{
+		{"min_duration", &filter.MinDuration},
+		{"max_duration", &filter.MaxDuration},
+	} {
+		if v := c.Query(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return filter, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = &n
+		}
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"published_after", &filter.PublishedAfter},
+		{"published_before", &filter.PublishedBefore},
+	} {
+		if v := c.Query(p.name); v != "" {
+			t, err := parseDateParam(v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: use YYYY-MM-DD or RFC3339", p.name)
+			}
+			*p.dst = &t
+		}
+	}
+
+	if v := c.Query("min_rating"); v != "" {
+		rating, err := strconv.ParseFloat(v, 64)
+		if err != nil || rating < 0 || rating > 5 {
+			return filter, fmt.Errorf("min_rating must be between 0 and 5")
+		}
+		filter.MinRating = &rating
+	}
+
+	// Anonymous viewers have no watch history, so the flag is a no-op
+	if c.Query("exclude_watched") == "true" {
+		if userID, ok := GetUserID(c); ok {
+			filter.ExcludeWatchedBy = &userID
+		}
+	}
+
+	return filter, nil
+}
+
+// parseDateParam accepts a date (YYYY-MM-DD) or an RFC3339 timestamp
+func parseDateParam(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// countFilms returns the total for a listing, using the Redis cache for the
+// unfiltered default listing (per viewer region). cached reports whether the
+// total may be stale.
+func (h *FilmHandler) countFilms(ctx context.Context, filter db.FilmFilter) (total int, cached bool, err error) {
+	unscoped := filter
+	unscoped.Region = nil
+	unscoped.Listed = false
+	if !unscoped.IsZero() {
+		total, err = h.queries.CountFilms(ctx, filter)
+		return total, false, err
+	}
+
+	var country string
+	if filter.Region != nil {
+		country = *filter.Region
+	}
+
+	if total, err := h.redis.GetFilmCount(ctx, country); err == nil {
+		return total, true, nil
+	}
+
+	total, err = h.queries.CountFilms(ctx, filter)
+	if err != nil {
+		return 0, false, err
+	}
+	h.redis.SetFilmCount(ctx, country, total)
+	return total, false, nil
+}
+
+// facetsTTL bounds how stale cached facet counts may get
+const facetsTTL = time.Minute
+
+// filmFacets returns facet counts for a filter, cached briefly in Redis
+func (h *FilmHandler) filmFacets(ctx context.Context, filter db.FilmFilter) (*models.FilmFacets, error) {
+	key, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	digest := hex.EncodeToString(sum[:16])
+
+	if facets, err := h.redis.GetFilmFacets(ctx, digest); err == nil {
+		return facets, nil
+	}
+
+	facets, err := h.queries.GetFilmFacets(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	h.redis.SetFilmFacets(ctx, digest, facets, facetsTTL)
+	return facets, nil
+}
+
+// searchResponse is the search envelope, with the query and match mode
+type searchResponse struct {
+	pagination.Page[models.SearchHit]
+	Query string `json:"query"`
+	Fuzzy bool   `json:"fuzzy"`
+}
+
+// SearchFilms searches ready films using the configured search backend,
+// ranked with the search.boosts setting and with highlighted snippets
+func (h *FilmHandler) SearchFilms(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "search query is required"})
+		return
+	}
+
+	// Parse pagination params
+	params := pagination.ParseOffset(c)
+
+	ctx := c.Request.Context()
+	var boosts models.SearchBoosts
+	if err := h.settings.Decode(ctx, settings.KeySearchBoosts, &boosts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load search boosts"})
+		return
+	}
+
+	// Hide titles not licensed for the viewer's region
+	country := GetCountry(c)
+	result, err := h.search.Search(ctx, search.Query{Text: query, Boosts: boosts, Region: &country, Limit: params.Limit, Offset: params.Offset})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search films"})
+		return
+	}
+
+	c.JSON(http.StatusOK, searchResponse{
+		Page:  pagination.NewOffsetPage(result.Hits, params, result.Total, false),
+		Query: query,
+		Fuzzy: result.Fuzzy,
 	})
 }
 
+// SetUserVerifiedRequest marks a creator verified or not
+type SetUserVerifiedRequest struct {
+	Verified bool `json:"verified"`
+}
+
+// SetUserVerified marks a creator verified, which boosts their films in
+// search, and reindexes their films
+func (h *FilmHandler) SetUserVerified(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req SetUserVerifiedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filmIDs, err := h.queries.SetUserVerified(c.Request.Context(), userID, req.Verified)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update verification"})
+		return
+	}
+
+	for _, filmID := range filmIDs {
+		h.indexer.SyncFilmAsync(filmID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user_id": userID, "verified": req.Verified})
+}
+
 // GetUploadURL generates a pre-signed URL for video upload
 func (h *FilmHandler) GetUploadURL(c *gin.Context) {
 	idParam := c.Param("id")
@@ -147,30 +517,118 @@ func (h *FilmHandler) GetUploadURL(c *gin.Context) {
 		return
 	}
 
+	// The declared size and type are signed into the upload URL
+	var req UploadURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !sourceContentTypes[req.ContentType] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "content_type must be a supported video type"})
+		return
+	}
+	maxFileSize := h.settings.Int(ctx, settings.KeyMaxUploadBytes)
+	if req.Multipart {
+		maxFileSize = maxMultipartFileSize
+	}
+	if req.FileSize > maxFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file_size must be at most %d bytes", maxFileSize)})
+		return
+	}
+
+	// The creator's sources count against their storage quota; this
+	// upload replaces the film's current source
+	if quota := h.settings.Int(ctx, settings.KeyCreatorQuotaBytes); quota > 0 {
+		used, err := h.queries.GetCreatorSourceBytes(ctx, userID, filmID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check storage quota"})
+			return
+		}
+		if used+req.FileSize > quota {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":       "upload would exceed your storage quota",
+				"quota_bytes": quota,
+				"used_bytes":  used,
+			})
+			return
+		}
+	}
+
 	// Generate upload URL
 	expiration := h.redis.Client.Options().ReadTimeout
 	if expiration == 0 {
 		expiration = 30 * 60 // 30 minutes default
 	}
 
-	uploadURL, err := h.r2Client.GeneratePresignedUploadURL(ctx, filmID, expiration)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate upload URL"})
+	// The source goes to this deployment's regional bucket; jobs reading it
+	// are routed to workers in that region
+	region := h.r2Client.SourceRegion()
+
+	// A new upload replaces an unfinished multipart one
+	h.abortMultipartUpload(ctx, filmID)
+
+	response := gin.H{
+		"expiration":    expiration.String(),
+		"max_file_size": maxFileSize,
+		"content_type":  req.ContentType,
+		"file_size":     req.FileSize,
+	}
+	if req.Multipart {
+		upload, err := h.startMultipartUpload(ctx, filmID, region, req.ContentType, req.FileSize)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start multipart upload"})
+			return
+		}
+		req.TotalParts = upload.TotalParts
+		response["upload_id"] = upload.UploadID
+		response["part_size"] = upload.PartSize
+		response["total_parts"] = upload.TotalParts
+		response["expiration"] = h.uploadURLExpiration().String()
+	} else {
+		checksum := ""
+		if req.SHA256 != "" {
+			checksum, _ = r2.SHA256Checksum(strings.ToLower(req.SHA256))
+		}
+		uploadURL, err := h.r2Client.ForRegion(region).GeneratePresignedUploadURL(ctx, filmID, req.ContentType, req.FileSize, checksum, expiration)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate upload URL"})
+			return
+		}
+		response["upload_url"] = uploadURL
+		if checksum != "" {
+			response["checksum_sha256"] = checksum
+		}
+	}
+	if err := h.queries.SetFilmSourceRegion(ctx, filmID, region); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record source region"})
+		return
+	}
+	if err := h.queries.SetFilmSourceSize(ctx, filmID, req.FileSize); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record source size"})
 		return
 	}
+	if err := h.redis.SetFilmRegion(ctx, filmID, region); err != nil {
+		log.Printf("Failed to cache source region of film %s: %v", filmID, err)
+	}
+	h.recordUploadMilestone(ctx, filmID, models.UploadStarted)
 
-	// Update film status to UPLOADED (in transaction)
-	tx, err := h.queries.db.BeginTx(ctx, nil)
-	if err == nil {
-		h.queries.UpdateFilmStatus(ctx, tx, filmID, models.StatusUploaded)
-		tx.Commit()
+	// Reset upload progress tracking
+	totalParts := req.TotalParts
+	if totalParts == 0 {
+		totalParts = 1
 	}
+	h.redis.InitUploadProgress(ctx, filmID, req.FileSize, totalParts)
 
-	c.JSON(http.StatusOK, gin.H{
-		"upload_url":    uploadURL,
-		"expiration":    expiration.String(),
-		"max_file_size": 2147483648, // 2GB in bytes
+	// Update film status to UPLOADED (in transaction)
+	err = h.queries.WithTx(ctx, func(tx *sqlx.Tx) error {
+		return h.queries.UpdateFilmStatus(ctx, tx, filmID, models.StatusUploaded)
 	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update film status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // ConfirmUpload is called after successful upload to trigger transcoding
@@ -198,6 +656,69 @@ func (h *FilmHandler) ConfirmUpload(c *gin.Context) {
 		return
 	}
 
+	var req ConfirmUploadRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// A multipart upload has no source until it is completed
+	if upload, err := h.redis.GetMultipartUpload(ctx, filmID); err == nil && upload != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "complete the multipart upload before confirming it"})
+		return
+	}
+
+	// Nothing is queued until the source is actually in storage
+	source := h.r2Client.ForRegion(film.SourceRegion)
+	key := r2.GetOriginalKey(filmID)
+	object, err := source.HeadObject(ctx, key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check the uploaded source"})
+		return
+	}
+	if object == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "no source has been uploaded", "key": key})
+		return
+	}
+	if object.Size == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "uploaded source is empty", "source": object})
+		return
+	}
+
+	// A corrupted upload fails here rather than in the worker; the creator
+	// can upload again to the same film
+	if req.SHA256 != "" {
+		stored, err := source.ObjectSHA256(ctx, key)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to checksum the uploaded source"})
+			return
+		}
+		if expected := strings.ToLower(req.SHA256); stored != expected {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":    "uploaded source does not match its checksum; upload it again",
+				"expected": expected,
+				"actual":   stored,
+			})
+			return
+		}
+	}
+
+	// Above the admission limits the upload is rejected, or confirmed and
+	// queued once there is room
+	policy := h.admission.Policy(ctx)
+	admit, load := h.admission.Admit(ctx, policy)
+	if !admit && policy.Mode != models.AdmissionDefer {
+		c.Header("Retry-After", strconv.Itoa(policy.RetryAfterSeconds))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       "transcoding is at capacity; confirm the upload again later",
+			"retry_after": policy.RetryAfterSeconds,
+			"load":        load,
+		})
+		return
+	}
+
 	// Create transcode job
 	job := &models.TranscodeJob{
 		ID:       uuid.New(),
@@ -210,27 +731,60 @@ func (h *FilmHandler) ConfirmUpload(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create transcode job"})
 		return
 	}
+	h.recordUploadMilestone(ctx, filmID, models.UploadConfirmed)
 
-	// Enqueue job for worker
-	if err := h.redis.EnqueueTranscodeJob(ctx, filmID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue job"})
+	// Transcoding waits for the malware scan of the source; the worker
+	// submits the film once the scan passes
+	if err := h.queueSourceScan(ctx, film); err != nil {
+		log.Printf("Failed to queue source scan of film %s: %v", filmID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue security scan"})
 		return
 	}
 
-	// Update film status to TRANSCODING
-	tx, _ := h.queries.db.BeginTx(ctx, nil)
-	h.queries.UpdateFilmStatus(ctx, tx, filmID, models.StatusTranscoding)
-	tx.Commit()
-
-	// Cache status in Redis
-	h.redis.SetFilmStatus(ctx, filmID, models.StatusTranscoding)
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Upload confirmed. Transcoding started.",
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Upload confirmed. Transcoding starts once the file passes its security scan.",
 		"job_id":  job.ID,
+		"status":  models.StatusScanning,
 	})
 }
 
+// queueSourceScan marks a film SCANNING and queues the malware scan of its
+// uploaded source. A scan task that can't be queued is left for the
+// worker's retry sweep.
+func (h *FilmHandler) queueSourceScan(ctx context.Context, film *models.Film) error {
+	scan, err := h.queries.CreateFileScan(ctx, film.ID, models.ScanKindSource, r2.GetOriginalKey(film.ID))
+	if err != nil {
+		return err
+	}
+	// The status is set first so a scan finishing at once isn't undone
+	if err := h.queries.SetFilmStatus(ctx, film.ID, models.StatusScanning); err != nil {
+		return err
+	}
+	h.redis.SetFilmStatus(ctx, film.ID, models.StatusScanning)
+	h.indexer.SyncFilmAsync(film.ID)
+
+	task := &models.WorkerTask{
+		ID:        uuid.New(),
+		Type:      models.TaskScanFile,
+		FilmID:    film.ID,
+		Params:    map[string]string{"scan_id": scan.ID.String()},
+		CreatedAt: time.Now(),
+	}
+	if err := h.redis.EnqueueTask(ctx, task); err != nil {
+		log.Printf("Failed to queue scan of film %s, retrying later: %v", film.ID, err)
+		return h.queries.SetFileScanNextAttempt(ctx, scan.ID, time.Now())
+	}
+	return nil
+}
+
+// recordUploadMilestone timestamps an upload lifecycle stage for latency
+// stats; a failure only loses the sample
+func (h *FilmHandler) recordUploadMilestone(ctx context.Context, filmID uuid.UUID, milestone models.UploadMilestone) {
+	if err := h.queries.RecordUploadMilestone(ctx, filmID, milestone); err != nil {
+		log.Printf("Failed to record upload milestone of film %s: %v", filmID, err)
+	}
+}
+
 // PublishFilm publishes a film (makes it publicly visible)
 func (h *FilmHandler) PublishFilm(c *gin.Context) {
 	idParam := c.Param("id")
@@ -262,21 +816,37 @@ func (h *FilmHandler) PublishFilm(c *gin.Context) {
 		return
 	}
 
+	// Organizations may require sign-off before publishing
+	allowed, err := h.approvals.CanPublish(ctx, film)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check approval"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusConflict, gin.H{"error": "film must be approved by its organization before publishing"})
+		return
+	}
+
 	// Publish film
-	tx, _ := h.queries.db.BeginTx(ctx, nil)
-	if err := h.queries.PublishFilm(ctx, tx, filmID); err != nil {
-		tx.Rollback()
+	err = h.queries.WithTx(ctx, func(tx *sqlx.Tx) error {
+		return h.queries.PublishFilm(ctx, tx, filmID)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to publish film"})
 		return
 	}
-	tx.Commit()
+
+	h.indexer.SyncFilmAsync(filmID)
+	events.Emit(ctx, h.redis, models.FilmEventPublished, filmID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Film published successfully",
 	})
 }
 
-// GetPlaybackURL returns the HLS playback URL for a film
+// GetPlaybackURL returns the HLS playback URL for a film. Embedded players
+// pass embed=true. A t= start offset from a time-coded link is echoed as
+// start_offset, which players prefer over resume_position.
 func (h *FilmHandler) GetPlaybackURL(c *gin.Context) {
 	idParam := c.Param("id")
 	filmID, err := uuid.Parse(idParam)
@@ -286,33 +856,140 @@ func (h *FilmHandler) GetPlaybackURL(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	variant := c.DefaultQuery("variant", models.VariantDefault)
+
+	// Log each step for support, whatever the outcome
+	plog := h.startPlaybackLog(c, filmID, "public")
+	defer h.playback.Save(plog)
+	plog.Record(models.PlaybackStageRequest, models.PlaybackOK, "", map[string]interface{}{
+		"variant": variant,
+		"embed":   c.Query("embed") == "true",
+	})
 
 	// Get film
 	film, err := h.queries.GetFilmByID(ctx, filmID)
 	if err != nil {
+		plog.Record(models.PlaybackStageError, models.PlaybackError, "film not found", nil)
 		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
 		return
 	}
 
 	// Check if film is ready
 	if film.Status != models.StatusReady {
+		plog.Record(models.PlaybackStageError, models.PlaybackError, "film is not ready for playback",
+			map[string]interface{}{"status": film.Status})
 		c.JSON(http.StatusBadRequest, gin.H{"error": "film is not ready for playback"})
 		return
 	}
 
+	// Optional start offset from a time-coded link, e.g. t=90 or t=1m30s
+	startOffset := 0
+	if t := c.Query("t"); t != "" {
+		startOffset, err = parseStartOffset(t, film.Duration)
+		if err != nil {
+			plog.Record(models.PlaybackStageError, models.PlaybackError, "invalid start offset",
+				map[string]interface{}{"t": t})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Enforce the film's policy: visibility, licensing regions, embedding
+	// and age gate
+	eval := policy.For(film)
+	viewer := policyViewer(c, h.queries, film)
+	err = eval.CanWatch(viewer)
+	recordPolicyDecision(plog, eval, viewer, err)
+	if err != nil {
+		respondCannotWatch(c, err)
+		return
+	}
+
 	// Increment view count asynchronously
 	go h.queries.IncrementViewCount(ctx, filmID)
 
-	// Get video assets
-	assets, err := h.queries.GetVideoAssetsByFilmID(ctx, filmID)
+	// Get video assets for the requested rendition variant
+	// Press screeners are only served through the press endpoints
+	if strings.HasPrefix(variant, models.ScreenerVariantPrefix) {
+		plog.Record(models.PlaybackStageError, models.PlaybackDenied, "screener variants are press only", nil)
+		c.JSON(http.StatusNotFound, gin.H{"error": "rendition variant not available"})
+		return
+	}
+	// Re-transcodes awaiting review are only shown to admins comparing them
+	if variant == models.RetranscodeVariant {
+		plog.Record(models.PlaybackStageError, models.PlaybackDenied, "re-transcodes are admin only", nil)
+		c.JSON(http.StatusNotFound, gin.H{"error": "rendition variant not available"})
+		return
+	}
+	assets, err := h.queries.GetVideoAssetsByVariant(ctx, filmID, variant)
 	if err != nil {
 		assets = []models.VideoAsset{}
 	}
 
+	masterURL := film.HLSMasterURL
+	if variant != models.VariantDefault {
+		if len(assets) == 0 {
+			plog.Record(models.PlaybackStageError, models.PlaybackError, "rendition variant not available", nil)
+			c.JSON(http.StatusNotFound, gin.H{"error": "rendition variant not available"})
+			return
+		}
+		masterURL = h.r2Client.GetHLSVariantMasterURL(filmID, variant)
+	}
+
+	// Cap renditions at the viewer's plan; creators and admins see them all
+	plan := entitlements.Unlimited
+	if !viewer.Privileged {
+		plan, err = h.plans.PlanFor(ctx, film, viewerUserID(c))
+		if err != nil {
+			log.Printf("[Playback] Failed to resolve plan for film %s: %v", filmID, err)
+			plog.Record(models.PlaybackStageError, models.PlaybackError, "failed to resolve playback plan", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve playback plan"})
+			return
+		}
+	}
+	assets, capped := entitlements.FilterAssets(assets, plan.MaxHeight)
+	plog.Record(models.PlaybackStageEntitlement, models.PlaybackOK, "", map[string]interface{}{
+		"plan":       plan.Key,
+		"max_height": plan.MaxHeight,
+		"capped":     capped,
+	})
+
+	// Start a playback session; its analytics events must carry the token
+	beaconToken, sessionID := h.issueBeacon(c, filmID)
+	recordTokenIssued(plog, sessionID, len(assets))
+
+	// The stored master playlist lists every rendition, so capped viewers
+	// load it through the manifest proxy instead
+	if capped {
+		masterURL = h.manifestURL(filmID, variant, beaconToken)
+	}
+
 	// Return playback info
+	c.Header("Cache-Control", "no-store")
 	c.JSON(http.StatusOK, gin.H{
-		"hls_master_url": film.HLSMasterURL,
+		"variant":        variant,
+		"hls_master_url": masterURL,
 		"thumbnail_url":   film.ThumbnailURL,
 		"assets":         assets,
+		"plan":           plan,
+		"session_id":     sessionID,
+		"beacon_token":   beaconToken,
+		"resume_position": resumePosition(c, h.positions, filmID),
+		"start_offset":    startOffset,
 	})
 }
+
+// issueBeacon starts a playback session of a film for the current viewer,
+// returning its beacon token and session id
+func (h *FilmHandler) issueBeacon(c *gin.Context, filmID uuid.UUID) (token string, sessionID string) {
+	return h.beacons.Issue(filmID, viewerUserID(c), time.Now())
+}
+
+// viewerUserID returns the signed-in viewer's ID, or nil for anonymous
+// viewers
+func viewerUserID(c *gin.Context) *uuid.UUID {
+	if id, ok := GetUserID(c); ok {
+		return &id
+	}
+	return nil
+}
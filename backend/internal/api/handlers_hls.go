@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RepairHLSRequest picks the films to repair; none means every ready film
+type RepairHLSRequest struct {
+	FilmIDs []uuid.UUID `json:"film_ids"`
+}
+
+// RepairHLS queues a worker task per film that checks every segment its
+// rendition playlists reference is in R2 and puts back missing ones
+func (h *FilmHandler) RepairHLS(c *gin.Context) {
+	var req RepairHLSRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	filmIDs := req.FilmIDs
+	if len(filmIDs) == 0 {
+		ids, err := h.queries.ListReadyFilmIDs(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list films"})
+			return
+		}
+		filmIDs = ids
+	} else {
+		films, err := h.queries.GetFilmsByIDs(ctx, filmIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get films"})
+			return
+		}
+		ready := map[uuid.UUID]bool{}
+		for _, film := range films {
+			ready[film.ID] = film.Status == models.StatusReady
+		}
+		for _, id := range filmIDs {
+			if !ready[id] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "film " + id.String() + " not found or not transcoded"})
+				return
+			}
+		}
+	}
+
+	userID, _ := GetUserID(c)
+	tasks := []*models.WorkerTask{}
+	for _, filmID := range filmIDs {
+		task := &models.WorkerTask{
+			ID:          uuid.New(),
+			Type:        models.TaskRepairHLS,
+			FilmID:      filmID,
+			RequestedBy: userID,
+			CreatedAt:   time.Now(),
+		}
+		if err := h.redis.EnqueueTask(ctx, task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue task"})
+			return
+		}
+		tasks = append(tasks, task)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "HLS repair queued",
+		"tasks":   tasks,
+	})
+}
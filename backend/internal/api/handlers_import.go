@@ -0,0 +1,90 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/arjunaayasa/filmtube/internal/urlimport"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ImportURLRequest names a video file hosted elsewhere to use as a film's
+// source
+type ImportURLRequest struct {
+	URL string `json:"url" binding:"required,max=2048"`
+}
+
+// ImportURL queues a worker task that downloads a remote video file and
+// stores it as the film's source, with progress reported like an upload.
+// Confirm the upload once the task completes to start transcoding.
+func (h *FilmHandler) ImportURL(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req ImportURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var schemes []string
+	if err := h.settings.Decode(ctx, settings.KeyImportURLSchemes, &schemes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load import settings"})
+		return
+	}
+	u, err := urlimport.ValidateURL(req.URL, schemes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "schemes": schemes})
+		return
+	}
+
+	// The source goes to this deployment's regional bucket, like an upload
+	region := h.r2Client.SourceRegion()
+	h.abortMultipartUpload(ctx, film.ID)
+	if err := h.queries.SetFilmSourceRegion(ctx, film.ID, region); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record source region"})
+		return
+	}
+	if err := h.redis.SetFilmRegion(ctx, film.ID, region); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record source region"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	task := &models.WorkerTask{
+		ID:          uuid.New(),
+		Type:        models.TaskImportURL,
+		FilmID:      film.ID,
+		Region:      region,
+		Params:      map[string]string{"url": u.String()},
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.redis.EnqueueTask(ctx, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue task"})
+		return
+	}
+	h.recordUploadMilestone(ctx, film.ID, models.UploadStarted)
+	h.redis.InitUploadProgress(ctx, film.ID, 0, 1)
+
+	err = h.queries.WithTx(ctx, func(tx *sqlx.Tx) error {
+		return h.queries.UpdateFilmStatus(ctx, tx, film.ID, models.StatusUploaded)
+	})
+	if err != nil {
+		log.Printf("Failed to mark film %s uploaded: %v", film.ID, err)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":       "Import queued",
+		"task":          task,
+		"max_file_size": h.settings.Int(ctx, settings.KeyMaxUploadBytes),
+	})
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/lut"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxLUTPreviewFrames = 5
+
+// UploadFilmLUT stores a .cube LUT applied when this film is encoded
+func (h *FilmHandler) UploadFilmLUT(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+	h.storeLUT(c, models.LUTScopeFilm, film.ID)
+}
+
+// DeleteFilmLUT removes a film's own LUT; creator or platform LUTs still apply
+func (h *FilmHandler) DeleteFilmLUT(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+	h.deleteLUT(c, models.LUTScopeFilm, film.ID)
+}
+
+// GetFilmLUT returns the LUT that will be applied when the film is encoded
+func (h *FilmHandler) GetFilmLUT(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	effective, err := h.queries.GetEffectiveLUT(c.Request.Context(), film.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no LUT applies to this film"})
+		return
+	}
+
+	c.JSON(http.StatusOK, effective)
+}
+
+// UploadCreatorLUT stores a .cube LUT applied to all of the current
+// creator's films that have no LUT of their own
+func (h *FilmHandler) UploadCreatorLUT(c *gin.Context) {
+	userID, _ := GetUserID(c)
+	h.storeLUT(c, models.LUTScopeCreator, userID)
+}
+
+// DeleteCreatorLUT removes the current creator's default LUT
+func (h *FilmHandler) DeleteCreatorLUT(c *gin.Context) {
+	userID, _ := GetUserID(c)
+	h.deleteLUT(c, models.LUTScopeCreator, userID)
+}
+
+// UploadPlatformLUT stores the platform-wide default LUT
+func (h *FilmHandler) UploadPlatformLUT(c *gin.Context) {
+	h.storeLUT(c, models.LUTScopePlatform, uuid.Nil)
+}
+
+// DeletePlatformLUT removes the platform-wide default LUT
+func (h *FilmHandler) DeletePlatformLUT(c *gin.Context) {
+	h.deleteLUT(c, models.LUTScopePlatform, uuid.Nil)
+}
+
+// storeLUT validates the multipart "file" as a 3D .cube LUT and saves it
+// into the given slot, replacing any previous LUT there
+func (h *FilmHandler) storeLUT(c *gin.Context, scope models.LUTScope, ownerID uuid.UUID) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if !strings.EqualFold(filepath.Ext(fileHeader.Filename), ".cube") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "LUT must be a .cube file"})
+		return
+	}
+	if fileHeader.Size > lut.MaxFileBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("file exceeds %d bytes", lut.MaxFileBytes)})
+		return
+	}
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, lut.MaxFileBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+
+	cube, err := lut.Parse(data)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid LUT: " + err.Error()})
+		return
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		name = cube.Title
+	}
+	if name == "" {
+		name = strings.TrimSuffix(fileHeader.Filename, filepath.Ext(fileHeader.Filename))
+	}
+
+	ctx := c.Request.Context()
+	key := r2.GetLUTKey(string(scope), ownerID)
+	if err := h.r2Client.UploadFile(ctx, key, bytes.NewReader(data), "text/plain"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store LUT"})
+		return
+	}
+
+	record := &models.LUT{
+		ID:         uuid.New(),
+		Scope:      scope,
+		Name:       name,
+		StorageKey: key,
+		Size:       cube.Size,
+	}
+	switch scope {
+	case models.LUTScopeCreator:
+		record.CreatorID = &ownerID
+	case models.LUTScopeFilm:
+		record.FilmID = &ownerID
+	}
+	if err := h.queries.ReplaceLUT(ctx, record); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save LUT"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, record)
+}
+
+func (h *FilmHandler) deleteLUT(c *gin.Context, scope models.LUTScope, ownerID uuid.UUID) {
+	if err := h.queries.DeleteLUT(c.Request.Context(), scope, ownerID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete LUT"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// LUTPreviewRequest selects the frames to render; timestamps are seconds
+type LUTPreviewRequest struct {
+	Timestamps []float64 `json:"timestamps"`
+}
+
+// RequestLUTPreview queues rendering of before/after frames with the
+// effective LUT so creators can check the look before transcoding
+func (h *FilmHandler) RequestLUTPreview(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	// The original must have been uploaded
+	if film.Status == models.StatusDraft {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "upload the video before previewing a LUT"})
+		return
+	}
+
+	var req LUTPreviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if len(req.Timestamps) > maxLUTPreviewFrames {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d timestamps", maxLUTPreviewFrames)})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if _, err := h.queries.GetEffectiveLUT(ctx, film.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no LUT applies to this film"})
+		return
+	}
+
+	timestamps := make([]string, len(req.Timestamps))
+	for i, ts := range req.Timestamps {
+		if ts < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "timestamps must not be negative"})
+			return
+		}
+		timestamps[i] = strconv.FormatFloat(ts, 'f', -1, 64)
+	}
+
+	userID, _ := GetUserID(c)
+	task := &models.WorkerTask{
+		ID:          uuid.New(),
+		Type:        models.TaskLUTPreview,
+		FilmID:      film.ID,
+		Params:      map[string]string{"timestamps": strings.Join(timestamps, ",")},
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.redis.EnqueueTask(ctx, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue task"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "LUT preview queued",
+		"task":    task,
+	})
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// metricsWindow is how far back the latency summaries in /metrics look
+const metricsWindow = 24 * time.Hour
+
+// MetricsHandler serves metrics in the Prometheus text format
+type MetricsHandler struct {
+	queries *db.Queries
+	token   string
+}
+
+func NewMetricsHandler(queries *db.Queries, token string) *MetricsHandler {
+	return &MetricsHandler{queries: queries, token: token}
+}
+
+// GetMetrics writes the upload latency of films that became ready in the
+// last 24 hours as a summary per stage. Scrapers authenticate with the
+// metrics token as a bearer token; the endpoint is disabled without one.
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	if h.token == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(h.token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid metrics token"})
+		return
+	}
+
+	now := time.Now()
+	stages, err := h.queries.GetUploadLatency(c.Request.Context(), now.Add(-metricsWindow), now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to summarise upload latency"})
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("# HELP filmtube_upload_latency_seconds Time each upload stage took, for films ready in the last 24 hours.\n")
+	b.WriteString("# TYPE filmtube_upload_latency_seconds summary\n")
+	for _, s := range orderLatencyStages(stages) {
+		for _, q := range []struct {
+			quantile string
+			seconds  float64
+		}{{"0.5", s.P50Seconds}, {"0.9", s.P90Seconds}, {"0.99", s.P99Seconds}} {
+			fmt.Fprintf(&b, "filmtube_upload_latency_seconds{stage=%q,quantile=%q} %g\n", s.Stage, q.quantile, q.seconds)
+		}
+		fmt.Fprintf(&b, "filmtube_upload_latency_seconds_sum{stage=%q} %g\n", s.Stage, s.SumSeconds)
+		fmt.Fprintf(&b, "filmtube_upload_latency_seconds_count{stage=%q} %d\n", s.Stage, s.Films)
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/adminlists"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/moderation"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ModerationHandler serves the admin moderation queue and its actions
+type ModerationHandler struct {
+	queries *db.Queries
+	queue   *moderation.Queue
+}
+
+func NewModerationHandler(queries *db.Queries, queue *moderation.Queue) *ModerationHandler {
+	return &ModerationHandler{queries: queries, queue: queue}
+}
+
+// ModerationActionRequest takes an action on a reported film or comment
+type ModerationActionRequest struct {
+	TargetType string    `json:"target_type" binding:"required"`
+	TargetID   uuid.UUID `json:"target_id" binding:"required"`
+	Action     string    `json:"action" binding:"required"`
+	Reason     string    `json:"reason" binding:"max=500"`
+}
+
+// ListQueue lists reported films and comments, most reported first, with
+// their owners. It takes the reports listing's filters.
+func (h *ModerationHandler) ListQueue(c *gin.Context) {
+	filter, ok := parseAdminListFilter(c, adminlists.Reports)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	params := pagination.ParseOffset(c)
+	items, err := h.queries.ListModerationQueue(ctx, filter, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list moderation queue"})
+		return
+	}
+	total, err := h.queries.CountReportedTargets(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count moderation queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(items, params, total, false))
+}
+
+// TakeAction dismisses, age restricts or unpublishes a reported film or
+// comment, or bans its creator, and records it in the audit log
+func (h *ModerationHandler) TakeAction(c *gin.Context) {
+	var req ModerationActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, _ := GetUserID(c)
+	entry, err := h.queue.Apply(c.Request.Context(), moderation.Action{
+		AdminID:    adminID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+	})
+	switch {
+	case errors.Is(err, moderation.ErrInvalidAction), errors.Is(err, policy.ErrInvalidPolicy):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, moderation.ErrTargetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, moderation.ErrProtectedUser):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply moderation action"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// ListFilmClassifications lists a film's content classifications, newest
+// first, with each label's score and what was done about a flag
+func (h *ModerationHandler) ListFilmClassifications(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+	classifications, err := h.queries.ListContentClassifications(c.Request.Context(), filmID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list classifications"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"classifications": classifications})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// NotificationHandler serves the current user's in-app notifications
+type NotificationHandler struct {
+	queries *db.Queries
+}
+
+func NewNotificationHandler(queries *db.Queries) *NotificationHandler {
+	return &NotificationHandler{queries: queries}
+}
+
+// ListNotifications returns the current user's notifications, newest first;
+// ?unread=true limits the list to unread ones
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	unreadOnly := c.Query("unread") == "true"
+	params := pagination.ParseOffset(c)
+
+	notifications, err := h.queries.ListNotifications(ctx, userID, unreadOnly, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list notifications"})
+		return
+	}
+	total, err := h.queries.CountNotifications(ctx, userID, unreadOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(notifications, params, total, false))
+}
+
+// MarkNotificationRead marks one notification read
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification ID"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	err = h.queries.MarkNotificationRead(c.Request.Context(), userID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update notification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked read"})
+}
+
+// MarkAllNotificationsRead marks every notification of the current user read
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userID, _ := GetUserID(c)
+	if err := h.queries.MarkAllNotificationsRead(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked read"})
+}
@@ -0,0 +1,326 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// OrganizationHandler handles organization membership and approval policy
+type OrganizationHandler struct {
+	queries *db.Queries
+}
+
+func NewOrganizationHandler(queries *db.Queries) *OrganizationHandler {
+	return &OrganizationHandler{queries: queries}
+}
+
+// CreateOrganizationRequest represents organization creation input
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	Slug string `json:"slug" binding:"omitempty,max=100"`
+}
+
+// ApprovalPolicyRequest configures an organization's publishing approvals
+type ApprovalPolicyRequest struct {
+	ApprovalRequired  bool     `json:"approval_required"`
+	ApproverRoles     []string `json:"approver_roles" binding:"omitempty,min=1,dive,oneof=OWNER PRODUCER EDITOR"`
+	RequiredApprovals int      `json:"required_approvals" binding:"omitempty,min=1,max=10"`
+}
+
+// PrivacyModeRequest turns an organization's privacy mode on or off
+type PrivacyModeRequest struct {
+	PrivacyMode *bool `json:"privacy_mode" binding:"required"`
+}
+
+// MemberRequest sets a member's role
+type MemberRequest struct {
+	Role models.TenantRole `json:"role" binding:"required,oneof=OWNER PRODUCER EDITOR"`
+}
+
+// ListMyOrganizations returns the organizations the current user belongs to
+func (h *OrganizationHandler) ListMyOrganizations(c *gin.Context) {
+	userID, _ := GetUserID(c)
+
+	memberships, err := h.queries.ListUserTenants(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list organizations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": memberships})
+}
+
+// CreateOrganization creates an organization owned by the current user
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slug := req.Slug
+	if slug == "" {
+		slug = strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(req.Name), "-"), "-")
+	}
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slug is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	exists, err := h.queries.TenantSlugExists(ctx, slug)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create organization"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "slug is already taken"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	tenant := &models.Tenant{ID: uuid.New(), Name: req.Name, Slug: slug}
+	if err := h.queries.CreateTenant(ctx, tenant, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create organization"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tenant)
+}
+
+// GetOrganization returns an organization with its members
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	tenant, role, ok := h.requireMember(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.queries.ListTenantMembers(c.Request.Context(), tenant.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"organization": tenant,
+		"role":         role,
+		"members":      members,
+	})
+}
+
+// UpdateApprovalPolicy sets whether publishing needs approval, which roles
+// may approve and how many distinct approvals are required
+func (h *OrganizationHandler) UpdateApprovalPolicy(c *gin.Context) {
+	tenant, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	var req ApprovalPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenant.ApprovalRequired = req.ApprovalRequired
+	if len(req.ApproverRoles) > 0 {
+		tenant.ApproverRoles = pq.StringArray(req.ApproverRoles)
+	}
+	if req.RequiredApprovals > 0 {
+		tenant.RequiredApprovals = req.RequiredApprovals
+	}
+
+	if err := h.queries.UpdateTenantApprovalPolicy(c.Request.Context(), tenant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update approval policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// UpdatePrivacyMode turns privacy mode on or off. In privacy mode no watch
+// history, playback positions or per-user analytics are kept for the
+// organization's films; turning it on forgets what was kept so far.
+func (h *OrganizationHandler) UpdatePrivacyMode(c *gin.Context) {
+	tenant, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	var req PrivacyModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenant.PrivacyMode = *req.PrivacyMode
+	if err := h.queries.UpdateTenantPrivacyMode(c.Request.Context(), tenant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update privacy mode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// SetMember adds a user to the organization or changes their role
+func (h *OrganizationHandler) SetMember(c *gin.Context) {
+	tenant, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req MemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.queries.GetUserByID(ctx, memberID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if req.Role != models.TenantRoleOwner && !h.keepsAnOwner(c, tenant.ID, memberID) {
+		return
+	}
+
+	if err := h.queries.UpsertTenantMember(ctx, tenant.ID, memberID, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tenant_id": tenant.ID, "user_id": memberID, "role": req.Role})
+}
+
+// RemoveMember removes a user from the organization
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	tenant, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	if !h.keepsAnOwner(c, tenant.ID, memberID) {
+		return
+	}
+
+	err = h.queries.RemoveTenantMember(c.Request.Context(), tenant.ID, memberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// ListPendingApprovals returns the organization's films waiting on an
+// approver
+func (h *OrganizationHandler) ListPendingApprovals(c *gin.Context) {
+	tenant, role, ok := h.requireMember(c)
+	if !ok {
+		return
+	}
+	if !tenant.CanApprove(role) && !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "your role cannot approve films in this organization"})
+		return
+	}
+
+	films, err := h.queries.ListTenantFilmsAwaitingApproval(c.Request.Context(), tenant.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list pending approvals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"films": films})
+}
+
+// requireMember loads the organization named by :id and the current user's
+// role in it; platform admins are treated as owners
+func (h *OrganizationHandler) requireMember(c *gin.Context) (*models.Tenant, models.TenantRole, bool) {
+	tenantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return nil, "", false
+	}
+
+	ctx := c.Request.Context()
+	tenant, err := h.queries.GetTenant(ctx, tenantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		return nil, "", false
+	}
+
+	userID, _ := GetUserID(c)
+	role, err := h.queries.GetTenantMemberRole(ctx, tenantID, userID)
+	switch {
+	case err == nil:
+		return tenant, role, true
+	case isAdmin(c):
+		return tenant, models.TenantRoleOwner, true
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a member of this organization"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check membership"})
+	}
+	return nil, "", false
+}
+
+// requireOwner is requireMember restricted to organization owners
+func (h *OrganizationHandler) requireOwner(c *gin.Context) (*models.Tenant, bool) {
+	tenant, role, ok := h.requireMember(c)
+	if !ok {
+		return nil, false
+	}
+	if role != models.TenantRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "organization owner access required"})
+		return nil, false
+	}
+	return tenant, true
+}
+
+// keepsAnOwner rejects demoting or removing the organization's last owner
+func (h *OrganizationHandler) keepsAnOwner(c *gin.Context, tenantID, memberID uuid.UUID) bool {
+	ctx := c.Request.Context()
+	role, err := h.queries.GetTenantMemberRole(ctx, tenantID, memberID)
+	if err != nil || role != models.TenantRoleOwner {
+		return true
+	}
+
+	owners, err := h.queries.CountTenantOwners(ctx, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check owners"})
+		return false
+	}
+	if owners <= 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "an organization must keep at least one owner"})
+		return false
+	}
+	return true
+}
+
+// isAdmin reports whether the current user is a platform admin
+func isAdmin(c *gin.Context) bool {
+	role, _ := GetUserRole(c)
+	return auth.IsAdmin(role)
+}
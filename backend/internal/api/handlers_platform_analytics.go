@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/analytics"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// platformTopFilms is how many films the platform analytics rank
+const platformTopFilms = 10
+
+// GetPlatformAnalytics reports platform-wide signups, uploads, transcode
+// throughput and failure rate, storage and the most viewed films over a
+// window of complete days (window=7d, 30d or 90d; default 30d), from the
+// nightly rollups. With breakdown=country, device or os, event counts per
+// value of that dimension are included.
+func (h *AnalyticsHandler) GetPlatformAnalytics(c *gin.Context) {
+	window := c.DefaultQuery("window", "30d")
+	days, ok := models.PlatformWindows[window]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be one of 7d, 30d, 90d"})
+		return
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, -days)
+	dimension, ok := parseBreakdown(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	daily, err := h.queries.ListPlatformDailyStats(ctx, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load platform stats"})
+		return
+	}
+	topFilms, err := h.queries.ListPlatformTopFilms(ctx, from, to, platformTopFilms)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rank films"})
+		return
+	}
+
+	var signups, uploads, completed, failed int64
+	var storage *models.PlatformDailyStats
+	for i, d := range daily {
+		signups += d.Signups
+		uploads += d.Uploads
+		completed += d.TranscodesCompleted
+		failed += d.TranscodesFailed
+		if d.StorageBytes != nil {
+			storage = &daily[i]
+		}
+	}
+	var failureRate float64
+	if completed+failed > 0 {
+		failureRate = float64(failed) / float64(completed+failed)
+	}
+
+	storageReport := gin.H{}
+	if storage != nil {
+		storageReport["bytes"] = *storage.StorageBytes
+		storageReport["as_of"] = storage.RolledUpAt
+	}
+
+	response := gin.H{
+		"window": window,
+		"from":   from.Format("2006-01-02"),
+		"to":     to.AddDate(0, 0, -1).Format("2006-01-02"),
+		"totals": gin.H{
+			"signups":              signups,
+			"uploads":              uploads,
+			"transcodes_completed": completed,
+			"transcodes_failed":    failed,
+			"transcodes_per_day":   float64(completed) / float64(days),
+			"failure_rate":         failureRate,
+		},
+		"storage":   storageReport,
+		"daily":     daily,
+		"top_films": topFilms,
+	}
+	if dimension != "" {
+		counts, err := h.queries.GetBreakdownEventCounts(ctx, nil, dimension, from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve breakdown"})
+			return
+		}
+		response["breakdown"] = gin.H{"dimension": dimension, "values": analytics.Breakdown(counts)}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
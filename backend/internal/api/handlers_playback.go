@@ -0,0 +1,153 @@
+package api
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/arjunaayasa/filmtube/internal/positions"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// A film counts as finished once this share of it has been played, so
+// skipping the end credits still completes it
+const finishedThreshold = 0.95
+
+// UpdatePlaybackPositionRequest is a player progress report
+type UpdatePlaybackPositionRequest struct {
+	PositionSeconds *int `json:"position_seconds" binding:"required,min=0"`
+	// DurationSeconds is the length reported by the player; the film's
+	// stored duration is used when omitted
+	DurationSeconds *int `json:"duration_seconds" binding:"omitempty,min=1"`
+	Completed       bool `json:"completed"`
+}
+
+// UpdatePlaybackPosition stores the current user's playback position for a
+// film. The player heartbeats it while playing; GetPlaybackURL returns it
+// as resume_position on any device.
+func (h *RecommendationHandler) UpdatePlaybackPosition(c *gin.Context) {
+	idParam := c.Param("id")
+	filmID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	var req UpdatePlaybackPositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	duration := film.Duration
+	if req.DurationSeconds != nil {
+		duration = *req.DurationSeconds
+	}
+
+	position := *req.PositionSeconds
+	if duration > 0 && position > duration {
+		position = duration
+	}
+
+	completed := req.Completed ||
+		(duration > 0 && float64(position) >= float64(duration)*finishedThreshold)
+
+	// Nothing is kept for films of organizations in privacy mode
+	private, err := h.queries.IsPrivateFilm(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save playback position"})
+		return
+	}
+	if !private {
+		userID, _ := GetUserID(c)
+		err := h.positions.Save(ctx, &models.PlaybackPosition{
+			UserID:          userID,
+			FilmID:          filmID,
+			PositionSeconds: position,
+			DurationSeconds: req.DurationSeconds,
+			Completed:       completed,
+			UpdatedAt:       time.Now(),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save playback position"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"position_seconds": position,
+		"completed":        completed,
+	})
+}
+
+// GetContinueWatching returns the current user's unfinished films with
+// how much has been watched and where to resume
+func (h *RecommendationHandler) GetContinueWatching(c *gin.Context) {
+	userID, _ := GetUserID(c)
+	country := GetCountry(c)
+
+	inProgress, err := h.queries.GetInProgressFilms(c.Request.Context(), userID, pagination.ParseLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve continue watching"})
+		return
+	}
+
+	items := make([]models.ContinueWatchingItem, 0, len(inProgress))
+	for _, p := range inProgress {
+		// Hide films that can't be played from the viewer's country or
+		// have since been made private
+		if !policy.For(&p.Film).Reachable(country) {
+			continue
+		}
+
+		percent := 0.0
+		if p.DurationSeconds > 0 {
+			percent = math.Min(100, math.Round(float64(p.PositionSeconds)*1000/float64(p.DurationSeconds))/10)
+		}
+		// Positions saved before the duration was known may be past the
+		// threshold without being marked complete
+		if percent >= finishedThreshold*100 {
+			continue
+		}
+
+		items = append(items, models.ContinueWatchingItem{
+			Film:           p.Film,
+			PercentWatched: percent,
+			ResumeOffset:   p.PositionSeconds,
+			LastPlayedAt:   p.LastPlayedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// resumePosition returns where the signed-in viewer should resume a film,
+// in seconds; 0 to start from the beginning. Finished films start over.
+func resumePosition(c *gin.Context, store *positions.Store, filmID uuid.UUID) int {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return 0
+	}
+	position, err := store.Get(c.Request.Context(), userID, filmID)
+	if err != nil {
+		log.Printf("Failed to load playback position: %v", err)
+		return 0
+	}
+	if position == nil || position.Completed {
+		return 0
+	}
+	return position.PositionSeconds
+}
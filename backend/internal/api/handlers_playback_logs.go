@@ -0,0 +1,149 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/playbacklog"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PlaybackLogHandler lets admins search playback session logs
+type PlaybackLogHandler struct {
+	queries *db.Queries
+}
+
+func NewPlaybackLogHandler(queries *db.Queries) *PlaybackLogHandler {
+	return &PlaybackLogHandler{queries: queries}
+}
+
+// ListPlaybackLogs searches playback session logs, newest first. At least
+// one of user_id, email, film_id or session_id is required; email is only
+// used to look up the user and is not stored. from and to take a date or
+// RFC 3339 time.
+func (h *PlaybackLogHandler) ListPlaybackLogs(c *gin.Context) {
+	ctx := c.Request.Context()
+	var filter db.PlaybackLogFilter
+
+	for param, dst := range map[string]**uuid.UUID{
+		"user_id":    &filter.UserID,
+		"film_id":    &filter.FilmID,
+		"session_id": &filter.SessionID,
+	} {
+		v := c.Query(param)
+		if v == "" {
+			continue
+		}
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+			return
+		}
+		*dst = &id
+	}
+
+	if email := strings.TrimSpace(c.Query("email")); email != "" {
+		user, err := h.queries.GetUserByEmail(ctx, email)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		if filter.UserID != nil && *filter.UserID != user.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "email and user_id refer to different users"})
+			return
+		}
+		filter.UserID = &user.ID
+	}
+
+	if filter.UserID == nil && filter.FilmID == nil && filter.SessionID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id, email, film_id or session_id is required"})
+		return
+	}
+
+	if v := c.Query("from"); v != "" {
+		from, err := parseDateParam(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return
+		}
+		filter.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := parseDateParam(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return
+		}
+		filter.To = &to
+	}
+
+	params := pagination.ParseOffset(c)
+	entries, err := h.queries.ListPlaybackLogs(ctx, filter, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list playback logs"})
+		return
+	}
+	total, err := h.queries.CountPlaybackLogs(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count playback logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(entries, params, total, false))
+}
+
+// startPlaybackLog begins the support log of a playback request; callers
+// defer h.playback.Save on the result
+func (h *FilmHandler) startPlaybackLog(c *gin.Context, filmID uuid.UUID, endpoint string) *playbacklog.Session {
+	var userID *uuid.UUID
+	if id, ok := GetUserID(c); ok {
+		userID = &id
+	}
+	return h.playback.Start(filmID, userID, endpoint, GetCountry(c), GetClientIP(c), c.Request.UserAgent())
+}
+
+// recordPolicyDecision logs the geo check and entitlement decision for a
+// viewer. The viewer's age is not logged, only whether it is known.
+func recordPolicyDecision(s *playbacklog.Session, eval *policy.Evaluator, viewer policy.Viewer, err error) {
+	geo := map[string]interface{}{"country": viewer.Country}
+	if eval.AvailableIn(viewer.Country) {
+		s.Record(models.PlaybackStageGeo, models.PlaybackOK, "", geo)
+	} else {
+		s.Record(models.PlaybackStageGeo, models.PlaybackDenied, policy.ErrRegion.Error(), geo)
+	}
+
+	details := map[string]interface{}{
+		"privileged": viewer.Privileged,
+		"embedded":   viewer.Embedded,
+		"age_gated":  eval.AgeGated(),
+		"age_known":  viewer.Age != nil,
+	}
+	if viewer.Embedded {
+		details["embed_host"] = viewer.EmbedHost
+	}
+	switch {
+	case err == nil:
+		s.Record(models.PlaybackStageEntitlement, models.PlaybackOK, "", details)
+	case errors.Is(err, policy.ErrPrivate):
+		// Viewers are told "not found"; support needs the real reason
+		s.Record(models.PlaybackStageEntitlement, models.PlaybackDenied, "film is private", details)
+	default:
+		s.Record(models.PlaybackStageEntitlement, models.PlaybackDenied, err.Error(), details)
+	}
+}
+
+// recordTokenIssued logs that playback was granted. The session log takes
+// the playback session's id so both can be looked up together; the token
+// itself is never logged.
+func recordTokenIssued(s *playbacklog.Session, sessionID string, assets int) {
+	if id, err := uuid.Parse(sessionID); err == nil {
+		s.ID = id
+	}
+	s.Record(models.PlaybackStageToken, models.PlaybackOK, "", map[string]interface{}{"assets": assets})
+}
@@ -0,0 +1,347 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/entitlements"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetPlaybackManifest serves a film's HLS master playlist without the
+// renditions above the viewer's plan. The viewer is the one the playback
+// session's beacon token was issued to; their plan is resolved again so a
+// change of entitlement applies on the next load.
+func (h *FilmHandler) GetPlaybackManifest(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+	claims, err := h.beacons.Verify(c.Query("token"), time.Now())
+	if err != nil || claims.FilmID != filmID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid playback token"})
+		return
+	}
+
+	variant := c.DefaultQuery("variant", models.VariantDefault)
+	if strings.HasPrefix(variant, models.ScreenerVariantPrefix) || variant == models.RetranscodeVariant {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rendition variant not available"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	if film.Status != models.StatusReady {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "film is not ready for playback"})
+		return
+	}
+
+	plan := entitlements.Unlimited
+	if !h.privilegedViewer(c, film, claims.UserID) {
+		plan, err = h.plans.PlanFor(ctx, film, claims.UserID)
+		if err != nil {
+			log.Printf("[Playback] Failed to resolve plan for film %s: %v", filmID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve playback plan"})
+			return
+		}
+	}
+
+	playlist, err := h.r2Client.DownloadFile(ctx, r2.GetHLSVariantMasterKey(filmID, variant))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rendition variant not available"})
+		return
+	}
+	base, err := url.Parse(h.r2Client.GetHLSVariantMasterURL(filmID, variant))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid playlist URL"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=60")
+	c.Data(http.StatusOK, "application/vnd.apple.mpegurl", entitlements.CapMasterPlaylist(playlist, plan.MaxHeight, base))
+}
+
+// manifestURL links a playback session to its capped master playlist
+func (h *FilmHandler) manifestURL(filmID uuid.UUID, variant, beaconToken string) string {
+	query := url.Values{"token": {beaconToken}}
+	if variant != models.VariantDefault {
+		query.Set("variant", variant)
+	}
+	return fmt.Sprintf("%s/api/films/%s/manifest.m3u8?%s", h.apiURL, filmID, query.Encode())
+}
+
+// privilegedViewer reports whether a playback session's viewer is the
+// film's creator or an admin, who are never capped
+func (h *FilmHandler) privilegedViewer(c *gin.Context, film *models.Film, userID *uuid.UUID) bool {
+	if userID == nil {
+		return false
+	}
+	if *userID == film.CreatedByID {
+		return true
+	}
+	user, err := h.queries.GetUserByID(c.Request.Context(), *userID)
+	return err == nil && auth.IsAdmin(user.Role)
+}
+
+// EntitlementHandler manages playback plan catalogs and the entitlements
+// putting viewers on them
+type EntitlementHandler struct {
+	queries       *db.Queries
+	plans         *entitlements.Service
+	organizations *OrganizationHandler
+}
+
+func NewEntitlementHandler(queries *db.Queries, plans *entitlements.Service) *EntitlementHandler {
+	return &EntitlementHandler{queries: queries, plans: plans, organizations: NewOrganizationHandler(queries)}
+}
+
+// EntitlementRequest puts a user on a plan, until ExpiresAt when set
+type EntitlementRequest struct {
+	PlanKey   string     `json:"plan_key" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ListMyEntitlements returns the current user's entitlements
+func (h *EntitlementHandler) ListMyEntitlements(c *gin.Context) {
+	userID, _ := GetUserID(c)
+	h.listUserEntitlements(c, userID)
+}
+
+// ListUserEntitlements returns a user's entitlements, platform-wide and
+// from organizations
+func (h *EntitlementHandler) ListUserEntitlements(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	h.listUserEntitlements(c, userID)
+}
+
+// SetPlatformEntitlement puts a user on a platform plan
+func (h *EntitlementHandler) SetPlatformEntitlement(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	h.setEntitlement(c, userID, nil)
+}
+
+// DeletePlatformEntitlement returns a user to the platform's default plan
+func (h *EntitlementHandler) DeletePlatformEntitlement(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	h.deleteEntitlement(c, userID, nil)
+}
+
+// GetOrganizationPlans returns the plans that apply to an organization's
+// films, and whether they are its own
+func (h *EntitlementHandler) GetOrganizationPlans(c *gin.Context) {
+	tenant, _, ok := h.organizations.requireMember(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	catalog, err := h.plans.Catalog(ctx, &tenant.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load playback plans"})
+		return
+	}
+	_, err = h.queries.GetTenantPlanCatalog(ctx, tenant.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load playback plans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"catalog": catalog, "own": err == nil})
+}
+
+// SetOrganizationPlans replaces the platform's plans for the
+// organization's films with its own; organization owners only
+func (h *EntitlementHandler) SetOrganizationPlans(c *gin.Context) {
+	tenant, ok := h.organizations.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	var catalog models.PlanCatalog
+	if err := c.ShouldBindJSON(&catalog); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := catalog.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data, err := json.Marshal(catalog)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save playback plans"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	saved, err := h.queries.SetTenantPlanCatalog(c.Request.Context(), tenant.ID, data, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save playback plans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteOrganizationPlans returns the organization's films to the
+// platform's plans; organization owners only
+func (h *EntitlementHandler) DeleteOrganizationPlans(c *gin.Context) {
+	tenant, ok := h.organizations.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	err := h.queries.DeleteTenantPlanCatalog(c.Request.Context(), tenant.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization uses the platform's plans"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete playback plans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Organization playback plans deleted"})
+}
+
+// ListOrganizationEntitlements returns the entitlements an organization
+// granted; organization owners only
+func (h *EntitlementHandler) ListOrganizationEntitlements(c *gin.Context) {
+	tenant, ok := h.organizations.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.queries.ListTenantEntitlements(c.Request.Context(), tenant.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list entitlements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entitlements": list})
+}
+
+// SetOrganizationEntitlement puts a user on one of the plans of the
+// organization's films; organization owners only
+func (h *EntitlementHandler) SetOrganizationEntitlement(c *gin.Context) {
+	tenant, ok := h.organizations.requireOwner(c)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	h.setEntitlement(c, userID, &tenant.ID)
+}
+
+// DeleteOrganizationEntitlement removes a user's entitlement from the
+// organization; organization owners only
+func (h *EntitlementHandler) DeleteOrganizationEntitlement(c *gin.Context) {
+	tenant, ok := h.organizations.requireOwner(c)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	h.deleteEntitlement(c, userID, &tenant.ID)
+}
+
+func (h *EntitlementHandler) listUserEntitlements(c *gin.Context, userID uuid.UUID) {
+	list, err := h.queries.ListUserEntitlements(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list entitlements"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entitlements": list})
+}
+
+// setEntitlement puts a user on a plan of the catalog that applies in the
+// scope, platform-wide for a nil tenant
+func (h *EntitlementHandler) setEntitlement(c *gin.Context, userID uuid.UUID, tenantID *uuid.UUID) {
+	var req EntitlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.queries.GetUserByID(ctx, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	catalog, err := h.plans.Catalog(ctx, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load playback plans"})
+		return
+	}
+	if _, ok := catalog.Plan(req.PlanKey); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown plan " + req.PlanKey})
+		return
+	}
+
+	grantedBy, _ := GetUserID(c)
+	entitlement := &models.Entitlement{
+		UserID:      userID,
+		TenantID:    tenantID,
+		PlanKey:     req.PlanKey,
+		ExpiresAt:   req.ExpiresAt,
+		GrantedByID: &grantedBy,
+	}
+	if err := h.queries.SetEntitlement(ctx, entitlement); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save entitlement"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entitlement)
+}
+
+func (h *EntitlementHandler) deleteEntitlement(c *gin.Context, userID uuid.UUID, tenantID *uuid.UUID) {
+	err := h.queries.DeleteEntitlement(c.Request.Context(), userID, tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entitlement not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete entitlement"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Entitlement deleted"})
+}
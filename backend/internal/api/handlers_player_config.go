@@ -0,0 +1,192 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/playerconfig"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PlayerConfigHandler serves remote player configuration and manages the
+// rules it is resolved from
+type PlayerConfigHandler struct {
+	queries *db.Queries
+	player  *playerconfig.Service
+}
+
+func NewPlayerConfigHandler(queries *db.Queries, player *playerconfig.Service) *PlayerConfigHandler {
+	return &PlayerConfigHandler{queries: queries, player: player}
+}
+
+// PlayerConfigRuleRequest creates or replaces a rule. An empty platform
+// targets every platform; RolloutPercent defaults to 100 and Enabled to
+// true.
+type PlayerConfigRuleRequest struct {
+	Name           string          `json:"name" binding:"required"`
+	Description    string          `json:"description"`
+	Platform       string          `json:"platform"`
+	MinAppVersion  string          `json:"min_app_version"`
+	MaxAppVersion  string          `json:"max_app_version"`
+	RolloutPercent *int            `json:"rollout_percent"`
+	Priority       int             `json:"priority"`
+	Enabled        *bool           `json:"enabled"`
+	Patch          json.RawMessage `json:"patch"`
+}
+
+// GetPlayerConfig returns the player configuration for the client's
+// platform and app version. device_id, or the signed-in user, places the
+// client in staged rollouts.
+func (h *PlayerConfigHandler) GetPlayerConfig(c *gin.Context) {
+	platform := strings.ToLower(c.Query("platform"))
+	if !slices.Contains(models.PlayerPlatforms, platform) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "platform must be one of " + strings.Join(models.PlayerPlatforms, ", ")})
+		return
+	}
+	appVersion := strings.TrimSpace(c.Query("app_version"))
+
+	subject := strings.TrimSpace(c.Query("device_id"))
+	if subject == "" {
+		if userID, ok := GetUserID(c); ok {
+			subject = userID.String()
+		}
+	}
+
+	resolved := h.player.Resolve(platform, appVersion, subject)
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", resolved.TTLSeconds))
+	c.JSON(http.StatusOK, resolved)
+}
+
+// ListPlayerConfigRules returns every rule in the order they apply, with
+// the default configuration they apply to
+func (h *PlayerConfigHandler) ListPlayerConfigRules(c *gin.Context) {
+	rules, err := h.queries.ListPlayerConfigRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list player config rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"default": playerconfig.Default,
+		"rules":   rules,
+	})
+}
+
+// CreatePlayerConfigRule adds a rule
+func (h *PlayerConfigHandler) CreatePlayerConfigRule(c *gin.Context) {
+	rule, ok := h.bindRule(c)
+	if !ok {
+		return
+	}
+
+	if err := h.queries.CreatePlayerConfigRule(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create player config rule"})
+		return
+	}
+	h.reload(c)
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdatePlayerConfigRule replaces a rule
+func (h *PlayerConfigHandler) UpdatePlayerConfigRule(c *gin.Context) {
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule ID"})
+		return
+	}
+	rule, ok := h.bindRule(c)
+	if !ok {
+		return
+	}
+
+	rule.ID = ruleID
+	err = h.queries.UpdatePlayerConfigRule(c.Request.Context(), rule)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "player config rule not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update player config rule"})
+		return
+	}
+	h.reload(c)
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeletePlayerConfigRule removes a rule
+func (h *PlayerConfigHandler) DeletePlayerConfigRule(c *gin.Context) {
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule ID"})
+		return
+	}
+
+	err = h.queries.DeletePlayerConfigRule(c.Request.Context(), ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "player config rule not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete player config rule"})
+		return
+	}
+	h.reload(c)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Player config rule deleted"})
+}
+
+// bindRule reads and validates a rule from the request body
+func (h *PlayerConfigHandler) bindRule(c *gin.Context) (*models.PlayerConfigRule, bool) {
+	var req PlayerConfigRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	userID, _ := GetUserID(c)
+	rule := &models.PlayerConfigRule{
+		Name:           req.Name,
+		Description:    strings.TrimSpace(req.Description),
+		MinAppVersion:  strings.TrimSpace(req.MinAppVersion),
+		MaxAppVersion:  strings.TrimSpace(req.MaxAppVersion),
+		RolloutPercent: 100,
+		Priority:       req.Priority,
+		Enabled:        true,
+		Patch:          req.Patch,
+		UpdatedByID:    &userID,
+	}
+	if platform := strings.ToLower(strings.TrimSpace(req.Platform)); platform != "" {
+		rule.Platform = &platform
+	}
+	if req.RolloutPercent != nil {
+		rule.RolloutPercent = *req.RolloutPercent
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := playerconfig.ValidateRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return rule, true
+}
+
+// reload applies a rule change on this instance right away; the others
+// pick it up on their next sync
+func (h *PlayerConfigHandler) reload(c *gin.Context) {
+	if err := h.player.Reload(c.Request.Context()); err != nil {
+		log.Printf("[Player] Failed to reload config rules: %v", err)
+	}
+}
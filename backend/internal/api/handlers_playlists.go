@@ -0,0 +1,376 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/playlists"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxPlaylists bounds how many playlists one user may create
+const maxPlaylists = 100
+
+// PlaylistHandler manages manual and smart playlists
+type PlaylistHandler struct {
+	queries   *db.Queries
+	playlists *playlists.Service
+}
+
+func NewPlaylistHandler(queries *db.Queries, playlistService *playlists.Service) *PlaylistHandler {
+	return &PlaylistHandler{queries: queries, playlists: playlistService}
+}
+
+// PlaylistRequest creates or replaces a playlist. Rules are required for
+// smart playlists and not allowed for manual ones.
+type PlaylistRequest struct {
+	Title       string              `json:"title" binding:"required,max=255"`
+	Description string              `json:"description" binding:"max=2000"`
+	Kind        models.PlaylistKind `json:"kind" binding:"omitempty,oneof=MANUAL SMART"` // default MANUAL
+	Rules       json.RawMessage     `json:"rules"`
+	Public      *bool               `json:"public"` // default true
+}
+
+// PlaylistItemRequest adds a film to a manual playlist
+type PlaylistItemRequest struct {
+	FilmID uuid.UUID `json:"film_id" binding:"required"`
+}
+
+// ReorderPlaylistRequest orders a manual playlist's films
+type ReorderPlaylistRequest struct {
+	FilmIDs []uuid.UUID `json:"film_ids" binding:"required,max=200"`
+}
+
+// CreatePlaylist creates a playlist owned by the current user
+func (h *PlaylistHandler) CreatePlaylist(c *gin.Context) {
+	var req PlaylistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = models.PlaylistManual
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+
+	count, err := h.queries.CountUserPlaylists(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count playlists"})
+		return
+	}
+	if count >= maxPlaylists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d playlists per user", maxPlaylists)})
+		return
+	}
+
+	playlist := &models.Playlist{OwnerID: userID, Kind: req.Kind}
+	if !applyPlaylistRequest(c, playlist, &req) {
+		return
+	}
+	if err := h.queries.CreatePlaylist(ctx, playlist); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create playlist"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, playlist)
+}
+
+// ListMyPlaylists lists the current user's playlists
+func (h *PlaylistHandler) ListMyPlaylists(c *gin.Context) {
+	userID, _ := GetUserID(c)
+	list, err := h.queries.ListUserPlaylists(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list playlists"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"playlists": list})
+}
+
+// GetPlaylist returns a playlist with the films the viewer may watch, in
+// order. Private playlists are only visible to their owner.
+func (h *PlaylistHandler) GetPlaylist(c *gin.Context) {
+	playlist, ok := h.requireVisiblePlaylist(c)
+	if !ok {
+		return
+	}
+
+	films, err := h.playlists.Films(c.Request.Context(), playlist, GetCountry(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load playlist films"})
+		return
+	}
+	attachViewerState(c, h.queries, films)
+
+	c.JSON(http.StatusOK, models.PlaylistWithFilms{Playlist: *playlist, Films: films})
+}
+
+// GetNextFilm returns the film to play after ?after= in a playlist, with
+// its zero-based position. It returns 204 at the end of the playlist.
+func (h *PlaylistHandler) GetNextFilm(c *gin.Context) {
+	playlist, ok := h.requireVisiblePlaylist(c)
+	if !ok {
+		return
+	}
+
+	var after uuid.UUID
+	if param := c.Query("after"); param != "" {
+		id, err := uuid.Parse(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+			return
+		}
+		after = id
+	}
+
+	film, position, err := h.playlists.Next(c.Request.Context(), playlist, after, GetCountry(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load playlist films"})
+		return
+	}
+	if film == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"film": film, "position": position})
+}
+
+// UpdatePlaylist replaces a playlist's title, description, rules and
+// visibility
+func (h *PlaylistHandler) UpdatePlaylist(c *gin.Context) {
+	playlist, ok := h.requireOwnedPlaylist(c)
+	if !ok {
+		return
+	}
+
+	var req PlaylistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Kind != "" && req.Kind != playlist.Kind {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a playlist's kind cannot change"})
+		return
+	}
+	if !applyPlaylistRequest(c, playlist, &req) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.queries.UpdatePlaylist(ctx, playlist); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update playlist"})
+		return
+	}
+	h.playlists.Invalidate(ctx, playlist.ID)
+
+	c.JSON(http.StatusOK, playlist)
+}
+
+// DeletePlaylist removes one of the current user's playlists
+func (h *PlaylistHandler) DeletePlaylist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid playlist ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	err = h.queries.DeletePlaylist(ctx, userID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "playlist not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete playlist"})
+		return
+	}
+	h.playlists.Invalidate(ctx, id)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Playlist deleted"})
+}
+
+// AddPlaylistItem appends a published film to a manual playlist
+func (h *PlaylistHandler) AddPlaylistItem(c *gin.Context) {
+	playlist, ok := h.requireManualPlaylist(c)
+	if !ok {
+		return
+	}
+
+	var req PlaylistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	film, err := h.queries.GetFilmByID(ctx, req.FilmID)
+	if err != nil || film.Status != models.StatusReady || film.PublishedAt == nil || !policy.For(film).Reachable(GetCountry(c)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	count, err := h.queries.CountPlaylistItems(ctx, playlist.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count playlist films"})
+		return
+	}
+	if count >= playlists.MaxItems {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d films per playlist", playlists.MaxItems)})
+		return
+	}
+
+	if err := h.queries.AddPlaylistItem(ctx, playlist.ID, film.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add film"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Film added to playlist"})
+}
+
+// RemovePlaylistItem removes a film from a manual playlist
+func (h *PlaylistHandler) RemovePlaylistItem(c *gin.Context) {
+	playlist, ok := h.requireManualPlaylist(c)
+	if !ok {
+		return
+	}
+
+	filmID, err := uuid.Parse(c.Param("filmId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	err = h.queries.RemovePlaylistItem(c.Request.Context(), playlist.ID, filmID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film is not in the playlist"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove film"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Film removed from playlist"})
+}
+
+// ReorderPlaylist orders a manual playlist's films as listed; unlisted
+// films follow in their current order
+func (h *PlaylistHandler) ReorderPlaylist(c *gin.Context) {
+	playlist, ok := h.requireManualPlaylist(c)
+	if !ok {
+		return
+	}
+
+	var req ReorderPlaylistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.queries.ReorderPlaylistItems(c.Request.Context(), playlist.ID, req.FilmIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reorder playlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Playlist reordered"})
+}
+
+// applyPlaylistRequest copies a request onto a playlist, checking rules
+// against its kind. It writes the error response itself and returns false
+// when the request should stop.
+func applyPlaylistRequest(c *gin.Context, playlist *models.Playlist, req *PlaylistRequest) bool {
+	hasRules := len(req.Rules) > 0 && string(req.Rules) != "null"
+	switch {
+	case playlist.Kind == models.PlaylistSmart && !hasRules:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "smart playlists need rules"})
+		return false
+	case playlist.Kind == models.PlaylistManual && hasRules:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "manual playlists cannot have rules"})
+		return false
+	}
+
+	playlist.Rules = nil
+	if hasRules {
+		rules, err := playlists.ParseRules(req.Rules)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+		// Store the normalized rules
+		playlist.Rules, _ = json.Marshal(rules)
+	}
+
+	playlist.Title = strings.TrimSpace(req.Title)
+	playlist.Description = strings.TrimSpace(req.Description)
+	playlist.Public = req.Public == nil || *req.Public
+	return true
+}
+
+// requireVisiblePlaylist loads the playlist from the :id param, hiding
+// other users' private playlists
+func (h *PlaylistHandler) requireVisiblePlaylist(c *gin.Context) (*models.Playlist, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid playlist ID"})
+		return nil, false
+	}
+
+	playlist, err := h.queries.GetPlaylist(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "playlist not found"})
+		return nil, false
+	}
+	if !playlist.Public {
+		if userID, ok := GetUserID(c); !ok || userID != playlist.OwnerID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "playlist not found"})
+			return nil, false
+		}
+	}
+
+	return playlist, true
+}
+
+// requireOwnedPlaylist loads the playlist from the :id param and checks
+// that the current user owns it
+func (h *PlaylistHandler) requireOwnedPlaylist(c *gin.Context) (*models.Playlist, bool) {
+	playlist, ok := h.requireVisiblePlaylist(c)
+	if !ok {
+		return nil, false
+	}
+
+	userID, _ := GetUserID(c)
+	if playlist.OwnerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+		return nil, false
+	}
+
+	return playlist, true
+}
+
+// requireManualPlaylist is requireOwnedPlaylist for playlists whose items
+// are edited by hand
+func (h *PlaylistHandler) requireManualPlaylist(c *gin.Context) (*models.Playlist, bool) {
+	playlist, ok := h.requireOwnedPlaylist(c)
+	if !ok {
+		return nil, false
+	}
+
+	if playlist.Kind != models.PlaylistManual {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "smart playlists are edited through their rules"})
+		return nil, false
+	}
+
+	return playlist, true
+}
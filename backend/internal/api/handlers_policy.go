@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateFilmPolicy replaces a film's policy document. Fields left out take
+// their defaults; see policy.Default.
+func (h *FilmHandler) UpdateFilmPolicy(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	raw, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	p, err := policy.Parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.savePolicy(c, film, p) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"film_id": film.ID, "policy": p})
+}
+
+// updatePolicy applies change to a film's current policy and saves it,
+// for endpoints that set one part of the policy
+func (h *FilmHandler) updatePolicy(c *gin.Context, film *models.Film, change func(p *models.FilmPolicy)) bool {
+	p := policy.For(film).Policy()
+	change(&p)
+	if err := policy.Validate(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return h.savePolicy(c, film, &p)
+}
+
+// savePolicy stores a validated policy and reindexes the film, whose
+// visibility search filters on
+func (h *FilmHandler) savePolicy(c *gin.Context, film *models.Film, p *models.FilmPolicy) bool {
+	if err := h.queries.UpdateFilmPolicy(c.Request.Context(), film.ID, p); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update film policy"})
+		return false
+	}
+	h.indexer.SyncFilmAsync(film.ID)
+	return true
+}
+
+// policyViewer describes the current request's viewer to a film's policy.
+// Their age is only looked up when the film is age gated, and the
+// embedding site only for embedded players.
+func policyViewer(c *gin.Context, queries *db.Queries, film *models.Film) policy.Viewer {
+	viewer := policy.Viewer{Country: GetCountry(c), Embedded: c.Query("embed") == "true"}
+	if viewer.Embedded {
+		viewer.EmbedHost = embedHost(c)
+	}
+
+	userID, signedIn := GetUserID(c)
+	if !signedIn {
+		return viewer
+	}
+	viewer.Privileged = userID == film.CreatedByID || isAdmin(c)
+
+	if !viewer.Privileged && policy.For(film).AgeGated() {
+		if user, err := queries.GetUserByID(c.Request.Context(), userID); err == nil && user.BirthDate != nil {
+			age := policy.Age(*user.BirthDate, time.Now())
+			viewer.Age = &age
+		}
+	}
+	return viewer
+}
+
+// respondCannotWatch reports why policy.CanWatch turned the viewer away
+func respondCannotWatch(c *gin.Context, err error) {
+	status := http.StatusForbidden
+	switch {
+	case errors.Is(err, policy.ErrPrivate):
+		status = http.StatusNotFound
+	case errors.Is(err, policy.ErrRegion):
+		status = http.StatusUnavailableForLegalReasons
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// embedHost returns the host name of the page embedding the player, from
+// the Referer header or, when browsers strip that, the Origin header
+func embedHost(c *gin.Context) string {
+	if referer := c.GetHeader("Referer"); referer != "" {
+		return policy.HostOf(referer)
+	}
+	return policy.HostOf(c.GetHeader("Origin"))
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PressEmbargoRequest sets when a film's press embargo lifts; null clears
+// it, which ends all press access
+type PressEmbargoRequest struct {
+	EmbargoUntil *time.Time `json:"embargo_until"`
+}
+
+// PressGrantRequest adds a press account to a film's press list
+type PressGrantRequest struct {
+	Email     string     `json:"email" binding:"required,email"`
+	ExpiresAt *time.Time `json:"expires_at"` // optional cutoff before the embargo
+}
+
+// UserRoleRequest changes a user's platform role
+type UserRoleRequest struct {
+	Role models.UserRole `json:"role" binding:"required,oneof=USER CREATOR PRESS ADMIN"`
+}
+
+// SetPressEmbargo sets when the press embargo on a film lifts; press
+// access ends automatically at that time
+func (h *FilmHandler) SetPressEmbargo(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req PressEmbargoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.EmbargoUntil != nil && !req.EmbargoUntil.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "embargo_until must be in the future"})
+		return
+	}
+
+	if err := h.queries.SetFilmPressEmbargo(c.Request.Context(), film.ID, req.EmbargoUntil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update embargo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"film_id": film.ID, "embargo_until": req.EmbargoUntil})
+}
+
+// ListPressAccess returns a film's press list with each entry's screener
+// status and when its access ends
+func (h *FilmHandler) ListPressAccess(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.queries.ListFilmPressAccess(c.Request.Context(), film.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list press access"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"film_id":       film.ID,
+		"embargo_until": film.PressEmbargoUntil,
+		"press":         pressEntries(entries),
+	})
+}
+
+// GrantPressAccess adds a press account to the film's press list and
+// queues their watermarked screener
+func (h *FilmHandler) GrantPressAccess(c *gin.Context) {
+	film, ok := h.requireReadyOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req PressGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	if film.PressEmbargoUntil == nil || !film.PressEmbargoUntil.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "set a future press embargo before granting access"})
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.queries.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if user.Role != models.RolePress {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user does not have a press account"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	task, err := h.press.Grant(ctx, film, user, userID, req.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to grant press access"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Press access granted; screener queued",
+		"user_id": user.ID,
+		"variant": models.ScreenerVariant(user.ID),
+		"task":    task,
+	})
+}
+
+// RevokePressAccess removes a press account from the film's press list and
+// deletes their screener
+func (h *FilmHandler) RevokePressAccess(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	err = h.press.Revoke(c.Request.Context(), film.ID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "press access not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke press access"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Press access revoked"})
+}
+
+// ListPressFilms returns the films the current press user can screen
+func (h *FilmHandler) ListPressFilms(c *gin.Context) {
+	userID, _ := GetUserID(c)
+
+	entries, err := h.queries.ListUserPressAccess(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list screeners"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"films": pressEntries(entries)})
+}
+
+// GetPressPlayback returns the current press user's watermarked screener
+// of a film while their access lasts
+func (h *FilmHandler) GetPressPlayback(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+
+	plog := h.startPlaybackLog(c, filmID, "press")
+	defer h.playback.Save(plog)
+	plog.Record(models.PlaybackStageRequest, models.PlaybackOK, "", nil)
+
+	access, err := h.queries.GetPressAccess(ctx, filmID, userID)
+	if err != nil || !access.ActiveAt(time.Now()) {
+		reason := "no press access to this film"
+		if err == nil {
+			reason = "press access is not active"
+		}
+		plog.Record(models.PlaybackStageEntitlement, models.PlaybackDenied, reason, nil)
+		c.JSON(http.StatusForbidden, gin.H{"error": "no press access to this film"})
+		return
+	}
+	plog.Record(models.PlaybackStageEntitlement, models.PlaybackOK, "", nil)
+
+	variant := models.ScreenerVariant(userID)
+	assets, err := h.queries.GetVideoAssetsByVariant(ctx, filmID, variant)
+	if err != nil || len(assets) == 0 {
+		plog.Record(models.PlaybackStageError, models.PlaybackError, "screener is still being prepared", nil)
+		c.JSON(http.StatusConflict, gin.H{"error": "screener is still being prepared"})
+		return
+	}
+
+	beaconToken, sessionID := h.issueBeacon(c, filmID)
+	recordTokenIssued(plog, sessionID, len(assets))
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"variant":           variant,
+		"hls_master_url":    h.r2Client.GetHLSVariantMasterURL(filmID, variant),
+		"assets":            assets,
+		"embargo_until":     access.EmbargoUntil,
+		"access_expires_at": access.AccessEndsAt(),
+		"session_id":        sessionID,
+		"beacon_token":      beaconToken,
+	})
+}
+
+// UpdateUserRole changes a user's platform role, e.g. to PRESS; it applies
+// from the user's next login
+func (h *FilmHandler) UpdateUserRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.queries.UpdateUserRole(c.Request.Context(), userID, req.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update role"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user_id": userID, "role": req.Role})
+}
+
+// pressEntries adds each entry's effective access end for the response
+func pressEntries(entries []models.FilmPressAccess) []gin.H {
+	out := make([]gin.H, len(entries))
+	for i := range entries {
+		out[i] = gin.H{
+			"access":            entries[i],
+			"access_expires_at": entries[i].AccessEndsAt(),
+		}
+	}
+	return out
+}
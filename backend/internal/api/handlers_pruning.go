@@ -0,0 +1,93 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/pruning"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PruningHandler reports and controls rendition pruning
+type PruningHandler struct {
+	queries *db.Queries
+	pruner  *pruning.Pruner
+}
+
+func NewPruningHandler(queries *db.Queries, pruner *pruning.Pruner) *PruningHandler {
+	return &PruningHandler{queries: queries, pruner: pruner}
+}
+
+// GetPruning returns the pruning policy, the storage it has saved so far
+// and the films it pruned, most recent first
+func (h *PruningHandler) GetPruning(c *gin.Context) {
+	ctx := c.Request.Context()
+	params := pagination.ParseOffset(c)
+
+	savings, err := h.queries.GetPruningSavings(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to total savings"})
+		return
+	}
+	prunes, err := h.queries.ListRenditionPrunes(ctx, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list pruned films"})
+		return
+	}
+	total, err := h.queries.CountRenditionPrunes(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count pruned films"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policy":  h.pruner.Policy(ctx),
+		"savings": savings,
+		"films":   pagination.NewOffsetPage(prunes, params, total, false),
+	})
+}
+
+// RunPruning queues pruning for the next batch of films the policy applies
+// to, even while scheduled pruning is disabled
+func (h *PruningHandler) RunPruning(c *gin.Context) {
+	userID, _ := GetUserID(c)
+	tasks, err := h.pruner.RunOnce(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue pruning"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Rendition pruning queued",
+		"tasks":   tasks,
+	})
+}
+
+// RestoreRenditions queues re-transcoding a film's pruned renditions
+func (h *PruningHandler) RestoreRenditions(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	task, err := h.pruner.Restore(c.Request.Context(), filmID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film has no pruned renditions"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue restore"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Rendition restore queued",
+		"task":    task,
+	})
+}
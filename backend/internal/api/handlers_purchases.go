@@ -0,0 +1,417 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// purchaseDownloadExpiration bounds how long a download link stays valid
+const purchaseDownloadExpiration = 15 * time.Minute
+
+// PurchaseHandler handles download-to-own purchases and their licenses
+type PurchaseHandler struct {
+	queries       *db.Queries
+	r2Client      *r2.Client
+	redis         *redis.Client
+	settings      *settings.Service
+	webhookSecret string
+}
+
+func NewPurchaseHandler(queries *db.Queries, r2Client *r2.Client, redisClient *redis.Client, settingsService *settings.Service, webhookSecret string) *PurchaseHandler {
+	return &PurchaseHandler{
+		queries:       queries,
+		r2Client:      r2Client,
+		redis:         redisClient,
+		settings:      settingsService,
+		webhookSecret: webhookSecret,
+	}
+}
+
+// DownloadPriceRequest sets a film's download-to-own price; a null
+// price_cents takes it off sale
+type DownloadPriceRequest struct {
+	PriceCents *int64 `json:"price_cents" binding:"omitempty,min=1"`
+	Currency   string `json:"currency" binding:"required_with=PriceCents,omitempty,len=3,uppercase"`
+}
+
+// PurchaseEventRequest is a completed payment reported by the payment
+// provider
+type PurchaseEventRequest struct {
+	ExternalRef string     `json:"external_ref" binding:"required,max=255"`
+	FilmID      *uuid.UUID `json:"film_id" binding:"required"`
+	UserID      *uuid.UUID `json:"user_id" binding:"required"`
+	AmountCents int64      `json:"amount_cents" binding:"required,min=1"`
+	Currency    string     `json:"currency" binding:"required,len=3,uppercase"`
+	OccurredAt  *time.Time `json:"occurred_at"`
+}
+
+// RefundEventRequest is a refund reported by the payment provider;
+// refund_of is the external_ref of the refunded purchase
+type RefundEventRequest struct {
+	ExternalRef string     `json:"external_ref" binding:"required,max=255"`
+	RefundOf    string     `json:"refund_of" binding:"required,max=255"`
+	AmountCents int64      `json:"amount_cents" binding:"required,min=1"` // positive; stored negated
+	Currency    string     `json:"currency" binding:"required,len=3,uppercase"`
+	OccurredAt  *time.Time `json:"occurred_at"`
+}
+
+// SetDownloadPrice puts a film up for sale as a download or takes it off
+// sale. Only films whose policy allows downloads can be put up for sale.
+func (h *FilmHandler) SetDownloadPrice(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req DownloadPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.PriceCents != nil && !policy.For(film).AllowsDownloads() {
+		c.JSON(http.StatusConflict, gin.H{"error": "downloads are turned off in the film's policy"})
+		return
+	}
+
+	var currency *string
+	if req.PriceCents != nil {
+		currency = &req.Currency
+	}
+	if err := h.queries.SetFilmDownloadPrice(c.Request.Context(), film.ID, req.PriceCents, currency); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update download price"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"film_id":              film.ID,
+		"download_price_cents": req.PriceCents,
+		"download_currency":    currency,
+	})
+}
+
+// RecordPurchase issues a license for a completed download purchase and
+// queues its watermarked file. Replayed callbacks return the existing
+// purchase.
+func (h *PurchaseHandler) RecordPurchase(c *gin.Context) {
+	if !h.requireWebhookSecret(c) {
+		return
+	}
+
+	var req PurchaseEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if existing, err := h.queries.GetPurchaseByExternalRef(ctx, req.ExternalRef); err == nil {
+		c.JSON(http.StatusOK, existing)
+		return
+	}
+
+	film, err := h.queries.GetFilmByID(ctx, *req.FilmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	user, err := h.queries.GetUserByID(ctx, *req.UserID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	licenseKey, err := newLicenseKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue license"})
+		return
+	}
+
+	occurredAt := time.Now()
+	if req.OccurredAt != nil {
+		occurredAt = *req.OccurredAt
+	}
+	transaction := &models.FilmTransaction{
+		FilmID:      film.ID,
+		UserID:      &user.ID,
+		Kind:        models.TransactionPurchase,
+		AmountCents: req.AmountCents,
+		Currency:    req.Currency,
+		ExternalRef: &req.ExternalRef,
+		OccurredAt:  occurredAt,
+	}
+	purchase := &models.FilmPurchase{
+		FilmID:        film.ID,
+		UserID:        user.ID,
+		LicenseKey:    licenseKey,
+		DownloadLimit: int(h.settings.Int(ctx, settings.KeyPurchaseDownloads)),
+		FilmTitle:     film.Title,
+	}
+
+	created, err := h.queries.RecordPurchase(ctx, transaction, purchase)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record purchase"})
+		return
+	}
+	if !created {
+		// A concurrent callback for the same payment got there first
+		existing, err := h.queries.GetPurchaseByExternalRef(ctx, req.ExternalRef)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load purchase"})
+			return
+		}
+		c.JSON(http.StatusOK, existing)
+		return
+	}
+
+	task, err := h.queueDownload(ctx, purchase, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "purchase recorded but its download could not be queued"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"purchase": purchase, "task": task})
+}
+
+// RecordRefund records a refund of a download purchase, revokes its
+// license and deletes its file
+func (h *PurchaseHandler) RecordRefund(c *gin.Context) {
+	if !h.requireWebhookSecret(c) {
+		return
+	}
+
+	var req RefundEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	occurredAt := time.Now()
+	if req.OccurredAt != nil {
+		occurredAt = *req.OccurredAt
+	}
+	refund := &models.FilmTransaction{
+		Kind:        models.TransactionRefund,
+		AmountCents: -req.AmountCents,
+		Currency:    req.Currency,
+		ExternalRef: &req.ExternalRef,
+		OccurredAt:  occurredAt,
+	}
+
+	ctx := c.Request.Context()
+	purchase, err := h.queries.RefundPurchase(ctx, refund, req.RefundOf)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "purchase not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record refund"})
+		return
+	}
+
+	// A file still being generated is deleted by the worker when it finishes
+	if purchase.DownloadKey != nil {
+		if err := h.r2Client.DeleteFile(ctx, *purchase.DownloadKey); err != nil {
+			log.Printf("[Purchases] Failed to delete download of revoked purchase %s: %v", purchase.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, purchase)
+}
+
+// ListMyPurchases returns the current user's download purchases
+func (h *PurchaseHandler) ListMyPurchases(c *gin.Context) {
+	userID, _ := GetUserID(c)
+
+	purchases, err := h.queries.ListUserPurchases(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list purchases"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purchases": purchases})
+}
+
+// DownloadPurchase counts a download against the purchase's limit and
+// returns a short-lived link to the buyer's watermarked file
+func (h *PurchaseHandler) DownloadPurchase(c *gin.Context) {
+	purchaseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid purchase ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	purchase, err := h.queries.ClaimPurchaseDownload(ctx, purchaseID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		h.explainUnavailable(c, purchaseID, userID)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start download"})
+		return
+	}
+
+	filename := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(purchase.FilmTitle), "-"), "-")
+	if filename == "" {
+		filename = "film"
+	}
+	url, err := h.r2Client.GeneratePresignedDownloadURL(ctx, *purchase.DownloadKey, filename+".mp4", purchaseDownloadExpiration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate download URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"download_url":   url,
+		"expires_at":     time.Now().Add(purchaseDownloadExpiration),
+		"downloads_left": purchase.DownloadsLeft(),
+	})
+}
+
+// RetryPurchase queues the watermarked file of a purchase whose
+// generation failed again
+func (h *PurchaseHandler) RetryPurchase(c *gin.Context) {
+	purchaseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid purchase ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	purchase, err := h.queries.GetPurchase(ctx, purchaseID)
+	if err != nil || purchase.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "purchase not found"})
+		return
+	}
+	if err := h.queries.RetryPurchase(ctx, purchase.ID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "only failed downloads can be retried"})
+		return
+	}
+	user, err := h.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load user"})
+		return
+	}
+
+	task, err := h.queueDownload(ctx, purchase, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue download"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Download queued", "task": task})
+}
+
+// GetLicense verifies a license key, e.g. for players checking a
+// downloaded file
+func (h *PurchaseHandler) GetLicense(c *gin.Context) {
+	purchase, err := h.queries.GetPurchaseByLicenseKey(c.Request.Context(), strings.ToUpper(c.Param("key")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "license not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"license_key":  purchase.LicenseKey,
+		"film_id":      purchase.FilmID,
+		"film_title":   purchase.FilmTitle,
+		"valid":        purchase.Status != models.PurchaseRevoked,
+		"purchased_at": purchase.CreatedAt,
+		"revoked_at":   purchase.RevokedAt,
+	})
+}
+
+// queueDownload queues generation of a purchase's watermarked file. If
+// queueing fails the purchase is marked failed so the buyer can retry.
+func (h *PurchaseHandler) queueDownload(ctx context.Context, purchase *models.FilmPurchase, user *models.User) (*models.WorkerTask, error) {
+	task := &models.WorkerTask{
+		ID:     uuid.New(),
+		Type:   models.TaskPurchaseDownload,
+		FilmID: purchase.FilmID,
+		Params: map[string]string{
+			"purchase_id": purchase.ID.String(),
+			"watermark":   fmt.Sprintf("Licensed to %s - %s", user.Email, purchase.LicenseKey),
+		},
+		RequestedBy: user.ID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.redis.EnqueueTask(ctx, task); err != nil {
+		if markErr := h.queries.MarkPurchaseFailed(ctx, purchase.ID); markErr != nil {
+			log.Printf("[Purchases] Failed to mark purchase %s failed: %v", purchase.ID, markErr)
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// explainUnavailable reports why a purchase could not be downloaded
+func (h *PurchaseHandler) explainUnavailable(c *gin.Context, purchaseID, userID uuid.UUID) {
+	purchase, err := h.queries.GetPurchase(c.Request.Context(), purchaseID)
+	if err != nil || purchase.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "purchase not found"})
+		return
+	}
+
+	switch purchase.Status {
+	case models.PurchasePending:
+		c.JSON(http.StatusConflict, gin.H{"error": "download is still being prepared"})
+	case models.PurchaseFailed:
+		c.JSON(http.StatusConflict, gin.H{"error": "download could not be prepared; retry it"})
+	case models.PurchaseRevoked:
+		c.JSON(http.StatusGone, gin.H{"error": "license has been revoked"})
+	default:
+		c.JSON(http.StatusForbidden, gin.H{"error": "download limit reached"})
+	}
+}
+
+// requireWebhookSecret checks the shared secret on payment provider
+// callbacks, which are disabled unless one is configured
+func (h *PurchaseHandler) requireWebhookSecret(c *gin.Context) bool {
+	if h.webhookSecret == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payments are disabled"})
+		return false
+	}
+
+	provided := c.GetHeader("X-Payments-Secret")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(h.webhookSecret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid payments secret"})
+		return false
+	}
+	return true
+}
+
+// newLicenseKey returns a random license key like 1A2B-3C4D-...
+func newLicenseKey() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	raw := strings.ToUpper(hex.EncodeToString(buf))
+	groups := make([]string, 0, len(raw)/4)
+	for i := 0; i < len(raw); i += 4 {
+		groups = append(groups, raw[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
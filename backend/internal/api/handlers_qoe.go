@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// unknownRendition labels QoE of events that did not name a rendition
+const unknownRendition = "unknown"
+
+// GetQoE reports playback quality of experience over a range of days
+// (from, to; default the last 30): average startup time, rebuffer ratio
+// and average bitrate overall, per rendition and per day, from the daily
+// rollups, so today is not included
+func (h *AnalyticsHandler) GetQoE(c *gin.Context) {
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	rollups, err := h.queries.ListQoERollups(c.Request.Context(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load QoE"})
+		return
+	}
+
+	var totals models.QoEStats
+	byRendition := map[string]*models.QoEStats{}
+	daily := []*models.QoEStats{}
+	for _, r := range rollups {
+		totals.Add(r)
+
+		rendition := r.Rendition
+		if rendition == "" {
+			rendition = unknownRendition
+		}
+		if byRendition[rendition] == nil {
+			byRendition[rendition] = &models.QoEStats{Rendition: rendition}
+		}
+		byRendition[rendition].Add(r)
+
+		if n := len(daily); n == 0 || !daily[n-1].Day.Equal(*r.Day) {
+			daily = append(daily, &models.QoEStats{Day: r.Day})
+		}
+		daily[len(daily)-1].Add(r)
+	}
+
+	totals.Summarize()
+	qualities := make([]*models.QoEStats, 0, len(byRendition))
+	for _, q := range byRendition {
+		q.Summarize()
+		qualities = append(qualities, q)
+	}
+	// Most watched renditions first
+	sort.Slice(qualities, func(i, j int) bool {
+		if qualities[i].WatchSeconds != qualities[j].WatchSeconds {
+			return qualities[i].WatchSeconds > qualities[j].WatchSeconds
+		}
+		return qualities[i].Rendition < qualities[j].Rendition
+	})
+	for _, d := range daily {
+		d.Summarize()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":      from.Format("2006-01-02"),
+		"to":        to.Format("2006-01-02"),
+		"totals":    totals,
+		"qualities": qualities,
+		"daily":     daily,
+	})
+}
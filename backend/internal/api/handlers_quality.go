@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/adminlists"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// QualityHandler reports and runs the post-encode quality check
+type QualityHandler struct {
+	queries  *db.Queries
+	redis    *redis.Client
+	settings *settings.Service
+}
+
+func NewQualityHandler(queries *db.Queries, redisClient *redis.Client, settingsService *settings.Service) *QualityHandler {
+	return &QualityHandler{queries: queries, redis: redisClient, settings: settingsService}
+}
+
+// GetQuality returns the quality check policy and the measured quality of
+// each rendition of the ladder across films, for tuning its bitrates
+func (h *QualityHandler) GetQuality(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var policy models.QualityCheckPolicy
+	if err := h.settings.Decode(ctx, settings.KeyQualityCheck, &policy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load quality check policy"})
+		return
+	}
+	renditions, err := h.queries.GetRenditionQuality(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to summarise rendition quality"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policy":     policy,
+		"renditions": renditions,
+	})
+}
+
+// ListQualityAlerts returns renditions that scored too low, most recent
+// first
+func (h *QualityHandler) ListQualityAlerts(c *gin.Context) {
+	serveAdminListing(c, h.queries, adminlists.QualityAlerts)
+}
+
+// RunQualityCheck queues scoring a film's default renditions, even while
+// the automatic check is disabled
+func (h *QualityHandler) RunQualityCheck(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	if film.Status != models.StatusReady {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "film must be in READY status"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	task := &models.WorkerTask{
+		ID:          uuid.New(),
+		Type:        models.TaskQualityCheck,
+		FilmID:      film.ID,
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.redis.EnqueueTask(ctx, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue task"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Quality check queued",
+		"task":    task,
+	})
+}
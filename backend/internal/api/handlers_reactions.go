@@ -0,0 +1,85 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ReactionHandler handles film likes and dislikes
+type ReactionHandler struct {
+	queries *db.Queries
+}
+
+func NewReactionHandler(queries *db.Queries) *ReactionHandler {
+	return &ReactionHandler{queries: queries}
+}
+
+// LikeFilm likes a film, or removes the like when the user already liked it
+func (h *ReactionHandler) LikeFilm(c *gin.Context) {
+	h.toggle(c, models.ReactionLike)
+}
+
+// DislikeFilm dislikes a film, or removes the dislike when the user already
+// disliked it
+func (h *ReactionHandler) DislikeFilm(c *gin.Context) {
+	h.toggle(c, models.ReactionDislike)
+}
+
+func (h *ReactionHandler) toggle(c *gin.Context, reaction models.Reaction) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	result, err := h.queries.ToggleFilmReaction(c.Request.Context(), userID, filmID, reaction)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save reaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// attachViewerState fills in the signed-in viewer's reaction to each film
+// and whether they saved it for later
+func attachViewerState(c *gin.Context, queries *db.Queries, films []models.Film) {
+	attachViewerReactions(c, queries, films)
+	attachViewerWatchlist(c, queries, films)
+}
+
+// attachViewerReactions fills in the signed-in viewer's reaction on each
+// film. It is best effort: on failure the films go out without it.
+func attachViewerReactions(c *gin.Context, queries *db.Queries, films []models.Film) {
+	userID, ok := GetUserID(c)
+	if !ok || len(films) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, len(films))
+	for i := range films {
+		ids[i] = films[i].ID
+	}
+	reactions, err := queries.GetUserReactions(c.Request.Context(), userID, ids)
+	if err != nil {
+		log.Printf("Failed to load viewer reactions: %v", err)
+		return
+	}
+	for i := range films {
+		if reaction, ok := reactions[films[i].ID]; ok {
+			films[i].ViewerReaction = &reaction
+		}
+	}
+}
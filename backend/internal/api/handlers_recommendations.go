@@ -0,0 +1,239 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/arjunaayasa/filmtube/internal/positions"
+	"github.com/arjunaayasa/filmtube/internal/recommend"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RecommendationHandler handles viewing signals and personalised recommendations
+type RecommendationHandler struct {
+	queries   *db.Queries
+	engine    *recommend.Engine
+	settings  *settings.Service
+	positions *positions.Store
+}
+
+func NewRecommendationHandler(queries *db.Queries, engine *recommend.Engine, settingsService *settings.Service, positionStore *positions.Store) *RecommendationHandler {
+	return &RecommendationHandler{
+		queries:   queries,
+		engine:    engine,
+		settings:  settingsService,
+		positions: positionStore,
+	}
+}
+
+// RecordWatch records that the current user watched a film
+func (h *RecommendationHandler) RecordWatch(c *gin.Context) {
+	idParam := c.Param("id")
+	filmID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if _, err := h.queries.GetFilmByID(ctx, filmID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	if err := h.queries.RecordWatch(ctx, userID, filmID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record watch"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// FollowCreator makes the current user follow a creator
+func (h *RecommendationHandler) FollowCreator(c *gin.Context) {
+	creatorID, ok := h.parseCreator(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	if creatorID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot follow yourself"})
+		return
+	}
+
+	if err := h.queries.FollowCreator(c.Request.Context(), userID, creatorID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to follow creator"})
+		return
+	}
+	h.engine.FollowsChanged(c.Request.Context(), userID)
+
+	c.Status(http.StatusNoContent)
+}
+
+// UnfollowCreator removes the current user's follow of a creator
+func (h *RecommendationHandler) UnfollowCreator(c *gin.Context) {
+	creatorID, ok := h.parseCreator(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	if err := h.queries.UnfollowCreator(c.Request.Context(), userID, creatorID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unfollow creator"})
+		return
+	}
+	h.engine.FollowsChanged(c.Request.Context(), userID)
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseCreator resolves the :id param to an existing creator account
+func (h *RecommendationHandler) parseCreator(c *gin.Context) (uuid.UUID, bool) {
+	idParam := c.Param("id")
+	creatorID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid creator ID"})
+		return uuid.Nil, false
+	}
+
+	user, err := h.queries.GetUserByID(c.Request.Context(), creatorID)
+	if err != nil || (user.Role != models.RoleCreator && user.Role != models.RoleAdmin) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "creator not found"})
+		return uuid.Nil, false
+	}
+
+	return creatorID, true
+}
+
+// GetRecommendations returns the current user's personalised recommendations
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	params := pagination.ParseOffset(c)
+
+	candidates, err := h.engine.Candidates(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve recommendations"})
+		return
+	}
+
+	total := len(candidates)
+	start := params.Offset
+	if start > total {
+		start = total
+	}
+	end := start + params.Limit
+	if end > total {
+		end = total
+	}
+	window := candidates[start:end]
+
+	// Load the recommended films and any "because you watched" seeds together
+	ids := make([]uuid.UUID, 0, len(window)*2)
+	for _, candidate := range window {
+		ids = append(ids, candidate.FilmID)
+		if candidate.BecauseOf != nil {
+			ids = append(ids, *candidate.BecauseOf)
+		}
+	}
+	films, err := h.queries.GetFilmsByIDs(ctx, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve recommendations"})
+		return
+	}
+	filmsByID := make(map[uuid.UUID]models.Film, len(films))
+	for _, film := range films {
+		filmsByID[film.ID] = film
+	}
+
+	items := make([]models.Recommendation, 0, len(window))
+	for _, candidate := range window {
+		film, ok := filmsByID[candidate.FilmID]
+		// Skip films unpublished, unlisted or deleted since the list was
+		// computed
+		if !ok || film.Status != models.StatusReady || film.PublishedAt == nil || !policy.For(&film).Listed(GetCountry(c)) {
+			continue
+		}
+		rec := models.Recommendation{
+			Film:      film,
+			Score:     candidate.Score,
+			Reason:    candidate.Reason,
+			BecauseOf: candidate.BecauseOf,
+		}
+		if candidate.BecauseOf != nil {
+			rec.BecauseOfFilm = filmsByID[*candidate.BecauseOf].Title
+		}
+		items = append(items, rec)
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(items, params, total, false))
+}
+
+// GetNewFromFollows returns films released in the last week by creators
+// the current user follows
+func (h *RecommendationHandler) GetNewFromFollows(c *gin.Context) {
+	userID, _ := GetUserID(c)
+	country := GetCountry(c)
+	limit := pagination.ParseLimit(c)
+
+	films, err := h.engine.NewFromFollows(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve new releases"})
+		return
+	}
+
+	// The cache is per user, not per country, so regions and visibility
+	// are checked here
+	items := make([]models.Film, 0, len(films))
+	for _, film := range films {
+		if len(items) == limit {
+			break
+		}
+		if policy.For(&film).Listed(country) {
+			items = append(items, film)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// GetRelatedFilms returns films related to a film by creator, genre and
+// tags, for "up next" strips. Signal weights come from related.weights.
+func (h *RecommendationHandler) GetRelatedFilms(c *gin.Context) {
+	idParam := c.Param("id")
+	filmID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	var weights models.RelatedWeights
+	if err := h.settings.Decode(ctx, settings.KeyRelatedWeights, &weights); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load related weights"})
+		return
+	}
+
+	related, err := h.queries.GetRelatedFilms(ctx, film, weights, pagination.ParseLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve related films"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"related": related})
+}
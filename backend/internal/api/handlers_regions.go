@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateRegionsRequest sets a film's licensing regions (ISO 3166-1 alpha-2)
+type UpdateRegionsRequest struct {
+	AllowedRegions []string `json:"allowed_regions"`
+	BlockedRegions []string `json:"blocked_regions"`
+}
+
+// UpdateFilmRegions sets where a film may be listed and played, the
+// regions part of its policy. An empty allow list means everywhere except
+// the blocked regions.
+func (h *FilmHandler) UpdateFilmRegions(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateRegionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	allowed, err := policy.NormalizeRegions(req.AllowedRegions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "allowed_regions: " + err.Error()})
+		return
+	}
+	blocked, err := policy.NormalizeRegions(req.BlockedRegions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "blocked_regions: " + err.Error()})
+		return
+	}
+
+	if !h.updatePolicy(c, film, func(p *models.FilmPolicy) {
+		p.Regions = models.RegionRules{Allowed: allowed, Blocked: blocked}
+	}) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"allowed_regions": allowed,
+		"blocked_regions": blocked,
+	})
+}
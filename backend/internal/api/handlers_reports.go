@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ReportHandler handles reports of films and their resolution
+type ReportHandler struct {
+	queries *db.Queries
+}
+
+func NewReportHandler(queries *db.Queries) *ReportHandler {
+	return &ReportHandler{queries: queries}
+}
+
+// ReportFilmRequest reports a film under a category, with optional details
+type ReportFilmRequest struct {
+	Category models.ReportCategory `json:"category" binding:"required"`
+	Reason   string                `json:"reason" binding:"max=500"`
+}
+
+// ReportFilm reports someone else's ready film for moderation
+func (h *ReportHandler) ReportFilm(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	var req ReportFilmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.Category.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report category"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil || film.Status != models.StatusReady {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	if film.CreatedByID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot report your own film"})
+		return
+	}
+
+	if _, err := h.queries.ReportFilm(ctx, film.ID, userID, req.Category, strings.TrimSpace(req.Reason)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to report film"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Film reported for moderation"})
+}
+
+// ListFilmReports lists a film's open reports, newest first
+func (h *ReportHandler) ListFilmReports(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	params := pagination.ParseOffset(c)
+	reports, err := h.queries.ListFilmReports(ctx, filmID, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list reports"})
+		return
+	}
+	total, err := h.queries.CountFilmReports(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(reports, params, total, false))
+}
+
+// ResolveFilmReports closes a film's open reports once a moderator has
+// dealt with them
+func (h *ReportHandler) ResolveFilmReports(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	resolved, err := h.queries.ResolveFilmReports(c.Request.Context(), filmID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"resolved": resolved})
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RetranscodeRequest configures a re-transcode
+type RetranscodeRequest struct {
+	// QC scores the new and current renditions against the original with
+	// VMAF and PSNR once the encode finishes
+	QC bool `json:"qc"`
+}
+
+// RetranscodeSide is one version of a film in a re-transcode comparison,
+// with its own playback session for side-by-side review
+type RetranscodeSide struct {
+	Variant      string              `json:"variant"`
+	HLSMasterURL string              `json:"hls_master_url"`
+	Assets       []models.VideoAsset `json:"assets"`
+	SessionID    string              `json:"session_id"`
+	BeaconToken  string              `json:"beacon_token"`
+}
+
+// QualityDelta is how a re-transcoded rendition scores against the
+// current one of the same quality; positive is better. A delta is nil
+// unless both renditions were measured.
+type QualityDelta struct {
+	Quality string   `json:"quality"`
+	VMAF    *float64 `json:"vmaf"`
+	PSNR    *float64 `json:"psnr"`
+}
+
+// Retranscode queues transcoding a film again into a candidate rendition
+// set, optionally scored by the QC stage, for an admin to compare with the
+// current one before swapping it in. A previous candidate is replaced.
+func (h *FilmHandler) Retranscode(c *gin.Context) {
+	film, ok := h.requireReadyFilm(c)
+	if !ok {
+		return
+	}
+
+	var req RetranscodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params := map[string]string{}
+	if req.QC {
+		params["qc"] = "true"
+	}
+	h.enqueueRetranscodeTask(c, film.ID, models.TaskRetranscode, params, "Re-transcode queued")
+}
+
+// CompareRetranscode returns a film's current and re-transcoded rendition
+// sets side by side: their asset metadata with any QC scores, the score
+// differences per quality and a playback session for each
+func (h *FilmHandler) CompareRetranscode(c *gin.Context) {
+	film, ok := h.requireReadyFilm(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	candidate, err := h.queries.GetVideoAssetsByVariant(ctx, film.ID, models.RetranscodeVariant)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get video assets"})
+		return
+	}
+	if len(candidate) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film has no re-transcode to compare"})
+		return
+	}
+	current, err := h.queries.GetVideoAssetsByVariant(ctx, film.ID, models.VariantDefault)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get video assets"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"film_id":   film.ID,
+		"current":   h.retranscodeSide(c, film, models.VariantDefault, current),
+		"candidate": h.retranscodeSide(c, film, models.RetranscodeVariant, candidate),
+		"deltas":    qualityDeltas(current, candidate),
+	})
+}
+
+// SwapRetranscode queues making a film's re-transcode its default
+// rendition set
+func (h *FilmHandler) SwapRetranscode(c *gin.Context) {
+	film, ok := h.requireReadyFilm(c)
+	if !ok {
+		return
+	}
+
+	candidate, err := h.queries.GetVideoAssetsByVariant(c.Request.Context(), film.ID, models.RetranscodeVariant)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get video assets"})
+		return
+	}
+	if len(candidate) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film has no re-transcode to swap in"})
+		return
+	}
+
+	h.enqueueRetranscodeTask(c, film.ID, models.TaskSwapRetranscode, nil, "Re-transcode swap queued")
+}
+
+func (h *FilmHandler) retranscodeSide(c *gin.Context, film *models.Film, variant string, assets []models.VideoAsset) RetranscodeSide {
+	masterURL := film.HLSMasterURL
+	if variant != models.VariantDefault {
+		masterURL = h.r2Client.GetHLSVariantMasterURL(film.ID, variant)
+	}
+	if assets == nil {
+		assets = []models.VideoAsset{}
+	}
+
+	beaconToken, sessionID := h.issueBeacon(c, film.ID)
+	return RetranscodeSide{
+		Variant:      variant,
+		HLSMasterURL: masterURL,
+		Assets:       assets,
+		SessionID:    sessionID,
+		BeaconToken:  beaconToken,
+	}
+}
+
+// qualityDeltas compares the candidate's scores with the current
+// rendition's, per quality the candidate has
+func qualityDeltas(current, candidate []models.VideoAsset) []QualityDelta {
+	byQuality := map[string]models.VideoAsset{}
+	for _, asset := range current {
+		byQuality[asset.Quality] = asset
+	}
+
+	deltas := []QualityDelta{}
+	for _, asset := range candidate {
+		delta := QualityDelta{Quality: asset.Quality}
+		if cur, ok := byQuality[asset.Quality]; ok {
+			delta.VMAF = scoreDelta(cur.VMAF, asset.VMAF)
+			delta.PSNR = scoreDelta(cur.PSNR, asset.PSNR)
+		}
+		deltas = append(deltas, delta)
+	}
+	return deltas
+}
+
+func scoreDelta(current, candidate *float64) *float64 {
+	if current == nil || candidate == nil {
+		return nil
+	}
+	d := *candidate - *current
+	return &d
+}
+
+func (h *FilmHandler) enqueueRetranscodeTask(c *gin.Context, filmID uuid.UUID, taskType models.TaskType, params map[string]string, message string) {
+	userID, _ := GetUserID(c)
+	task := &models.WorkerTask{
+		ID:          uuid.New(),
+		Type:        taskType,
+		FilmID:      filmID,
+		Params:      params,
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.redis.EnqueueTask(c.Request.Context(), task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue task"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": message,
+		"task":    task,
+	})
+}
+
+// requireReadyFilm loads the film from the :id param for admin endpoints,
+// which need no ownership, and checks it has finished transcoding
+func (h *FilmHandler) requireReadyFilm(c *gin.Context) (*models.Film, bool) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return nil, false
+	}
+
+	film, err := h.queries.GetFilmByID(c.Request.Context(), filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return nil, false
+	}
+	if film.Status != models.StatusReady {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "film must be in READY status"})
+		return nil, false
+	}
+	return film, true
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/adminlists"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ReviewHandler handles film ratings, written reviews and their moderation
+type ReviewHandler struct {
+	queries  *db.Queries
+	settings *settings.Service
+}
+
+func NewReviewHandler(queries *db.Queries, settingsService *settings.Service) *ReviewHandler {
+	return &ReviewHandler{queries: queries, settings: settingsService}
+}
+
+// ReviewRequest rates a film with optional review text
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Body   string `json:"body" binding:"max=5000"`
+}
+
+// FlagReviewRequest flags a review for moderators
+type FlagReviewRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// ModerateReviewRequest hides or restores a review
+type ModerateReviewRequest struct {
+	Hidden *bool `json:"hidden" binding:"required"`
+}
+
+// ListFilmReviews lists a film's visible reviews, newest first
+func (h *ReviewHandler) ListFilmReviews(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.queries.GetFilmByID(ctx, filmID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	params := pagination.ParseOffset(c)
+	reviews, err := h.queries.ListFilmReviews(ctx, filmID, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list reviews"})
+		return
+	}
+	total, err := h.queries.CountFilmReviews(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count reviews"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(reviews, params, total, false))
+}
+
+// GetMyReview returns the current user's review of a film, including a
+// hidden one
+func (h *ReviewHandler) GetMyReview(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	review, err := h.queries.GetUserReview(c.Request.Context(), filmID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// SaveReview rates a ready film, optionally with review text, replacing
+// the current user's previous review
+func (h *ReviewHandler) SaveReview(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	var req ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	review, summary, err := h.queries.UpsertReview(c.Request.Context(), filmID, userID, req.Rating, strings.TrimSpace(req.Body))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"review":         review,
+		"average_rating": summary.AverageRating,
+		"rating_count":   summary.RatingCount,
+	})
+}
+
+// DeleteReview removes the current user's review of a film
+func (h *ReviewHandler) DeleteReview(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	summary, err := h.queries.DeleteReview(c.Request.Context(), filmID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// FlagReview flags another user's review for moderation. Reviews flagged by
+// enough users are hidden until a moderator looks at them.
+func (h *ReviewHandler) FlagReview(c *gin.Context) {
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review ID"})
+		return
+	}
+
+	var req FlagReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	review, err := h.queries.GetReview(ctx, reviewID)
+	if err != nil || review.Hidden {
+		c.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+		return
+	}
+	if review.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot flag your own review"})
+		return
+	}
+
+	autoHideAt := int(h.settings.Int(ctx, settings.KeyReviewAutoHide))
+	if _, err := h.queries.FlagReview(ctx, reviewID, userID, strings.TrimSpace(req.Reason), autoHideAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to flag review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Review flagged for moderation"})
+}
+
+// ListFlaggedReviews lists reviews with open flags, most recently flagged
+// first
+func (h *ReviewHandler) ListFlaggedReviews(c *gin.Context) {
+	serveAdminListing(c, h.queries, adminlists.FlaggedReviews)
+}
+
+// ModerateReview hides or restores a review and resolves its flags
+func (h *ReviewHandler) ModerateReview(c *gin.Context) {
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review ID"})
+		return
+	}
+
+	var req ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	review, err := h.queries.ModerateReview(c.Request.Context(), reviewID, userID, *req.Hidden)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to moderate review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
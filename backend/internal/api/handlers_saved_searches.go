@@ -0,0 +1,179 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxSavedSearches bounds how many searches one user may save
+const maxSavedSearches = 25
+
+// SavedSearchHandler manages the current user's saved searches
+type SavedSearchHandler struct {
+	queries *db.Queries
+}
+
+func NewSavedSearchHandler(queries *db.Queries) *SavedSearchHandler {
+	return &SavedSearchHandler{queries: queries}
+}
+
+// SavedSearchRequest creates or replaces a saved search; omitted filters
+// match any film
+type SavedSearchRequest struct {
+	Name        string           `json:"name" binding:"required,max=100"`
+	Query       string           `json:"query" binding:"max=200"`
+	Type        *models.FilmType `json:"type" binding:"omitempty,oneof=SHORT_FILM FEATURE_FILM"`
+	Genre       *string          `json:"genre" binding:"omitempty,max=50"`
+	CreatorID   *uuid.UUID       `json:"creator_id"`
+	MinDuration *int             `json:"min_duration" binding:"omitempty,min=0"`
+	MaxDuration *int             `json:"max_duration" binding:"omitempty,min=0"`
+	MinRating   *float64         `json:"min_rating" binding:"omitempty,min=0,max=5"`
+	EmailAlerts bool             `json:"email_alerts"`
+}
+
+// ListSavedSearches returns the current user's saved searches
+func (h *SavedSearchHandler) ListSavedSearches(c *gin.Context) {
+	userID, _ := GetUserID(c)
+
+	searches, err := h.queries.ListUserSavedSearches(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list saved searches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"saved_searches": searches})
+}
+
+// CreateSavedSearch saves a search; the user is alerted about matching
+// films published from now on
+func (h *SavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+	var req SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	count, err := h.queries.CountUserSavedSearches(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save search"})
+		return
+	}
+	if count >= maxSavedSearches {
+		c.JSON(http.StatusConflict, gin.H{"error": "saved search limit reached; delete one first"})
+		return
+	}
+
+	search := &models.SavedSearch{UserID: userID}
+	if !applySavedSearchRequest(c, search, &req) {
+		return
+	}
+
+	if err := h.queries.CreateSavedSearch(ctx, search); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save search"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+// UpdateSavedSearch replaces a saved search's name, criteria and alert
+// settings; films already alerted on are not alerted on again
+func (h *SavedSearchHandler) UpdateSavedSearch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid saved search ID"})
+		return
+	}
+
+	var req SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	search, err := h.queries.GetSavedSearch(ctx, userID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "saved search not found"})
+		return
+	}
+	if !applySavedSearchRequest(c, search, &req) {
+		return
+	}
+
+	err = h.queries.UpdateSavedSearch(ctx, search)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "saved search not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update saved search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// DeleteSavedSearch removes a saved search and stops its alerts
+func (h *SavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid saved search ID"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	err = h.queries.DeleteSavedSearch(c.Request.Context(), userID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "saved search not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete saved search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Saved search deleted"})
+}
+
+// applySavedSearchRequest copies the request onto the search and validates
+// it, writing the error response itself
+func applySavedSearchRequest(c *gin.Context, search *models.SavedSearch, req *SavedSearchRequest) bool {
+	search.Name = strings.TrimSpace(req.Name)
+	search.Query = strings.TrimSpace(req.Query)
+	search.Type = req.Type
+	search.Genre = nil
+	if req.Genre != nil {
+		search.Genre = nullIfEmpty(strings.TrimSpace(*req.Genre))
+	}
+	search.CreatorID = req.CreatorID
+	search.MinDuration = req.MinDuration
+	search.MaxDuration = req.MaxDuration
+	search.MinRating = req.MinRating
+	search.EmailAlerts = req.EmailAlerts
+
+	if search.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must not be blank"})
+		return false
+	}
+	if search.MinDuration != nil && search.MaxDuration != nil && *search.MinDuration > *search.MaxDuration {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_duration must not exceed max_duration"})
+		return false
+	}
+	if search.Query == "" && search.Type == nil && search.Genre == nil && search.CreatorID == nil &&
+		search.MinDuration == nil && search.MaxDuration == nil && search.MinRating == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "set a query or at least one filter"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/search"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchSyncHandler reports and repairs search index sync
+type SearchSyncHandler struct {
+	syncer *search.Syncer
+}
+
+func NewSearchSyncHandler(syncer *search.Syncer) *SearchSyncHandler {
+	return &SearchSyncHandler{syncer: syncer}
+}
+
+// GetSearchSync returns the index's sync watermark, event cursor and latest
+// consistency report
+func (h *SearchSyncHandler) GetSearchSync(c *gin.Context) {
+	state, err := h.syncer.State(c.Request.Context())
+	if errors.Is(err, search.ErrNotReplica) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load sync state"})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// CheckSearchIndex diffs the index against Postgres shard by shard
+func (h *SearchSyncHandler) CheckSearchIndex(c *gin.Context) {
+	report, err := h.syncer.Check(c.Request.Context())
+	if errors.Is(err, search.ErrNotReplica) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check search index"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RepairSearchIndex reindexes only the films a fresh check finds divergent
+// and deletes documents of films that no longer exist
+func (h *SearchSyncHandler) RepairSearchIndex(c *gin.Context) {
+	report, result, err := h.syncer.Repair(c.Request.Context())
+	if errors.Is(err, search.ErrNotReplica) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to repair search index"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"check":  report,
+		"repair": result,
+	})
+}
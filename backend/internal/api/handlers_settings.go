@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+)
+
+// SettingsHandler handles admin settings endpoints
+type SettingsHandler struct {
+	settings *settings.Service
+}
+
+func NewSettingsHandler(settingsService *settings.Service) *SettingsHandler {
+	return &SettingsHandler{
+		settings: settingsService,
+	}
+}
+
+// UpdateSettingRequest represents a setting update
+type UpdateSettingRequest struct {
+	Value json.RawMessage `json:"value" binding:"required"`
+}
+
+// ListSettings returns all known settings with their current values
+func (h *SettingsHandler) ListSettings(c *gin.Context) {
+	list, err := h.settings.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": list})
+}
+
+// GetSetting returns the current value of a single setting
+func (h *SettingsHandler) GetSetting(c *gin.Context) {
+	key := c.Param("key")
+
+	value, err := h.settings.Get(c.Request.Context(), key)
+	if errors.Is(err, settings.ErrUnknownSetting) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown setting"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve setting"})
+		return
+	}
+
+	def := settings.Definitions[key]
+	c.JSON(http.StatusOK, gin.H{
+		"key":         key,
+		"type":        def.Type,
+		"value":       value,
+		"description": def.Description,
+	})
+}
+
+// UpdateSetting validates and stores a setting value
+func (h *SettingsHandler) UpdateSetting(c *gin.Context) {
+	key := c.Param("key")
+
+	var req UpdateSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+
+	setting, err := h.settings.Set(c.Request.Context(), key, req.Value, userID)
+	var validationErr *settings.ValidationError
+	switch {
+	case errors.Is(err, settings.ErrUnknownSetting):
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown setting"})
+		return
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update setting"})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// ResetSetting reverts a setting to its default value
+func (h *SettingsHandler) ResetSetting(c *gin.Context) {
+	key := c.Param("key")
+
+	err := h.settings.Reset(c.Request.Context(), key)
+	if errors.Is(err, settings.ErrUnknownSetting) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown setting"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset setting"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Setting reset to default"})
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	shareCodeLength   = 8
+	shareCodeAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// startOffsetPattern matches t= values: seconds ("90") or YouTube style
+// ("1m30s", "1h2m")
+var startOffsetPattern = regexp.MustCompile(`^(?:(\d{1,3})h)?(?:(\d{1,5})m)?(?:(\d{1,6})s?)?$`)
+
+var errInvalidStartOffset = errors.New("t must be seconds (90) or a duration like 1m30s")
+
+// ShareHandler creates and resolves short, time-coded share links
+type ShareHandler struct {
+	queries *db.Queries
+	appURL  string
+}
+
+func NewShareHandler(queries *db.Queries, appURL string) *ShareHandler {
+	return &ShareHandler{
+		queries: queries,
+		appURL:  strings.TrimRight(appURL, "/"),
+	}
+}
+
+// ShareFilmRequest sets where a shared film starts playing
+type ShareFilmRequest struct {
+	T string `json:"t"` // start offset, e.g. "90" or "1m30s"; default the beginning
+}
+
+// ShareFilm returns a short link that opens a published film at an
+// optional start offset. Sharing the same film and offset again returns
+// the same link.
+func (h *ShareHandler) ShareFilm(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	var req ShareFilmRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	// Only films anyone following the link could open can be shared
+	if err != nil || film.Status != models.StatusReady || film.PublishedAt == nil || !policy.For(film).CanSee(policy.Viewer{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	start := 0
+	if req.T != "" {
+		start, err = parseStartOffset(req.T, film.Duration)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var link *models.ShareLink
+	for attempt := 0; attempt < 3; attempt++ {
+		code, err := newShareCode()
+		if err != nil {
+			break
+		}
+		link, err = h.queries.CreateShareLink(ctx, filmID, start, code)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The code is taken; try another
+			link = nil
+			continue
+		}
+		if err != nil {
+			link = nil
+		}
+		break
+	}
+	if link == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create share link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.linkResponse(link))
+}
+
+// GetShareLink resolves a share link's code to its film and start offset
+func (h *ShareHandler) GetShareLink(c *gin.Context) {
+	link, err := h.queries.GetShareLink(c.Request.Context(), c.Param("code"))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share link not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get share link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.linkResponse(link))
+}
+
+// linkResponse adds the short URL and the film page URL it opens
+func (h *ShareHandler) linkResponse(link *models.ShareLink) gin.H {
+	filmURL := h.appURL + "/films/" + link.FilmID.String()
+	if link.StartSeconds > 0 {
+		filmURL += "?t=" + strconv.Itoa(link.StartSeconds)
+	}
+	return gin.H{
+		"code":     link.Code,
+		"film_id":  link.FilmID,
+		"t":        link.StartSeconds,
+		"url":      h.appURL + "/s/" + link.Code,
+		"film_url": filmURL,
+	}
+}
+
+// parseStartOffset reads a t= start offset in seconds. It must fall
+// within the film when its duration is known.
+func parseStartOffset(v string, duration int) (int, error) {
+	m := startOffsetPattern.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil || strings.TrimSpace(v) == "" {
+		return 0, errInvalidStartOffset
+	}
+
+	seconds := 0
+	for i, unit := range []int{3600, 60, 1} {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return 0, errInvalidStartOffset
+		}
+		seconds += n * unit
+	}
+
+	if duration > 0 && seconds >= duration {
+		return 0, fmt.Errorf("t is past the end of the film (%d seconds)", duration)
+	}
+	return seconds, nil
+}
+
+// newShareCode returns a random share link code without look-alike
+// characters
+func newShareCode() (string, error) {
+	max := big.NewInt(int64(len(shareCodeAlphabet)))
+	code := make([]byte, shareCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = shareCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/retention"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SourceRetentionHandler controls what happens to uploaded sources once
+// their film is ready
+type SourceRetentionHandler struct {
+	queries  *db.Queries
+	enforcer *retention.Enforcer
+}
+
+func NewSourceRetentionHandler(queries *db.Queries, enforcer *retention.Enforcer) *SourceRetentionHandler {
+	return &SourceRetentionHandler{queries: queries, enforcer: enforcer}
+}
+
+// SetSourceRetentionRequest sets a film's retention action; null follows
+// the policy again
+type SetSourceRetentionRequest struct {
+	Action *models.SourceRetentionAction `json:"action"`
+}
+
+// RunSourceRetention applies the retention policy to the sources now due,
+// rather than waiting for the next scheduled run
+func (h *SourceRetentionHandler) RunSourceRetention(c *gin.Context) {
+	ctx := c.Request.Context()
+	result, err := h.enforcer.RunOnce(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply source retention"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": h.enforcer.Policy(ctx), "result": result})
+}
+
+// SetSourceRetention overrides the retention policy for one film. Delete
+// and archive overrides are applied on the next run without waiting for
+// the policy's after_days.
+func (h *SourceRetentionHandler) SetSourceRetention(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+	var req SetSourceRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Action != nil && !req.Action.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be keep, delete, archive or null"})
+		return
+	}
+
+	state, err := h.queries.SetSourceRetention(c.Request.Context(), filmID, req.Action)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set source retention"})
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
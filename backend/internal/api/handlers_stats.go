@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler handles aggregate read endpoints
+type StatsHandler struct {
+	queries *db.Queries
+}
+
+func NewStatsHandler(queries *db.Queries) *StatsHandler {
+	return &StatsHandler{
+		queries: queries,
+	}
+}
+
+// filmCountsMaxAge is how long clients may cache film counts (seconds)
+const filmCountsMaxAge = 60
+
+// GetFilmCounts returns film counts grouped by creator, status or genre.
+// The counts cover every film, private and unfinished ones included, so
+// they are for admin dashboards only.
+func (h *StatsHandler) GetFilmCounts(c *gin.Context) {
+	dimension := c.DefaultQuery("by", "status")
+	if dimension != "creator" && dimension != "status" && dimension != "genre" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "by must be one of creator, status, genre"})
+		return
+	}
+
+	counts, err := h.queries.ListFilmCounts(c.Request.Context(), dimension)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve film counts"})
+		return
+	}
+	if counts == nil {
+		counts = []models.FilmCount{}
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", filmCountsMaxAge))
+	c.JSON(http.StatusOK, gin.H{
+		"by":     dimension,
+		"counts": counts,
+	})
+}
+
+// GetUploadLatency returns how long uploads took to become ready films
+// between the from and to dates (inclusive, default the last 30 days): the
+// percentiles of each stage over the whole range, and per day from the
+// rollups
+func (h *StatsHandler) GetUploadLatency(c *gin.Context) {
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+	end := to.AddDate(0, 0, 1)
+
+	ctx := c.Request.Context()
+	stages, err := h.queries.GetUploadLatency(ctx, from, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to summarise upload latency"})
+		return
+	}
+	daily, err := h.queries.ListUploadLatencyRollups(ctx, from, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load upload latency rollups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":   from.Format("2006-01-02"),
+		"to":     to.Format("2006-01-02"),
+		"stages": orderLatencyStages(stages),
+		"daily":  daily,
+	})
+}
+
+// orderLatencyStages lists every latency stage in lifecycle order, with
+// zero films for stages no film completed
+func orderLatencyStages(stats []models.UploadLatency) []models.UploadLatency {
+	byStage := make(map[string]models.UploadLatency, len(stats))
+	for _, s := range stats {
+		byStage[s.Stage] = s
+	}
+	ordered := make([]models.UploadLatency, 0, len(models.LatencyStages))
+	for _, stage := range models.LatencyStages {
+		s, ok := byStage[stage]
+		if !ok {
+			s.Stage = stage
+		}
+		ordered = append(ordered, s)
+	}
+	return ordered
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/subtitles"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	maxSubtitleFiles     = 20
+	maxSubtitleFileBytes = 2 << 20 // 2MB
+)
+
+var languageTagRegex = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})?$`)
+
+// SubtitleImportResult reports the outcome for one uploaded file
+type SubtitleImportResult struct {
+	Filename string           `json:"filename"`
+	Language string           `json:"language,omitempty"`
+	Status   string           `json:"status"` // imported, failed
+	Error    string           `json:"error,omitempty"`
+	Encoding string           `json:"encoding,omitempty"`
+	Subtitle *models.Subtitle `json:"subtitle,omitempty"`
+}
+
+// ImportSubtitles converts one or more SRT/ASS/VTT files to WebVTT and
+// attaches them to a film. Files are sent as multipart "files" fields; the
+// language of each comes from the matching "languages" field or, if absent,
+// from the file name (e.g. movie.en.srt). Each file succeeds or fails on its own.
+func (h *FilmHandler) ImportSubtitles(c *gin.Context) {
+	idParam := c.Param("id")
+	filmID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Get film to verify ownership
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	// Check ownership
+	userID, _ := GetUserID(c)
+	if film.CreatedByID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expected multipart form upload"})
+		return
+	}
+
+	files := form.File["files"]
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no subtitle files provided"})
+		return
+	}
+	if len(files) > maxSubtitleFiles {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d files per request", maxSubtitleFiles)})
+		return
+	}
+	languages := form.Value["languages"]
+	labels := form.Value["labels"]
+
+	results := make([]SubtitleImportResult, 0, len(files))
+	imported := 0
+
+	for i, fh := range files {
+		result := SubtitleImportResult{Filename: fh.Filename, Status: "failed"}
+
+		language := ""
+		if i < len(languages) {
+			language = strings.TrimSpace(languages[i])
+		}
+		if language == "" {
+			language = languageFromFilename(fh.Filename)
+		}
+		result.Language = language
+
+		label := ""
+		if i < len(labels) {
+			label = strings.TrimSpace(labels[i])
+		}
+
+		subtitle, encoding, err := h.importSubtitleFile(c, filmID, fh.Filename, language, label, fh.Size, func() (io.ReadCloser, error) {
+			return fh.Open()
+		})
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Status = "imported"
+			result.Encoding = encoding
+			result.Subtitle = subtitle
+			imported++
+		}
+		results = append(results, result)
+	}
+
+	status := http.StatusCreated
+	switch {
+	case imported == 0:
+		status = http.StatusUnprocessableEntity
+	case imported < len(files):
+		status = http.StatusMultiStatus
+	}
+
+	c.JSON(status, gin.H{
+		"imported": imported,
+		"failed":   len(files) - imported,
+		"results":  results,
+	})
+}
+
+func (h *FilmHandler) importSubtitleFile(c *gin.Context, filmID uuid.UUID, filename, language, label string, size int64, open func() (io.ReadCloser, error)) (*models.Subtitle, string, error) {
+	if !languageTagRegex.MatchString(language) {
+		return nil, "", fmt.Errorf("missing or invalid language tag %q", language)
+	}
+	if size > maxSubtitleFileBytes {
+		return nil, "", fmt.Errorf("file exceeds %d bytes", maxSubtitleFileBytes)
+	}
+
+	format, err := subtitles.FormatFromFilename(filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	f, err := open()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file")
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSubtitleFileBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file")
+	}
+
+	converted, err := subtitles.ConvertToVTT(data, format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx := c.Request.Context()
+	vttURL, err := h.r2Client.UploadSubtitle(ctx, filmID, language, strings.NewReader(converted.VTT))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to store subtitle")
+	}
+
+	subtitle := &models.Subtitle{
+		ID:           uuid.New(),
+		FilmID:       filmID,
+		Language:     language,
+		Label:        label,
+		VTTURL:       vttURL,
+		SourceFormat: format,
+		CueCount:     converted.Cues,
+	}
+	if err := h.queries.UpsertSubtitle(ctx, subtitle); err != nil {
+		return nil, "", fmt.Errorf("failed to save subtitle")
+	}
+	// Cue density on the film's timeline changed
+	if err := h.redis.InvalidateFilmTimeline(ctx, filmID); err != nil {
+		log.Printf("Failed to invalidate timeline of film %s: %v", filmID, err)
+	}
+
+	return subtitle, converted.Encoding, nil
+}
+
+// languageFromFilename extracts "en" from names like "movie.en.srt"
+func languageFromFilename(name string) string {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	if ext := filepath.Ext(base); ext != "" {
+		return strings.TrimPrefix(ext, ".")
+	}
+	return ""
+}
+
+// ListSubtitles returns the subtitle tracks available for a film
+func (h *FilmHandler) ListSubtitles(c *gin.Context) {
+	idParam := c.Param("id")
+	filmID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	subs, err := h.queries.GetSubtitlesByFilmID(c.Request.Context(), filmID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve subtitles"})
+		return
+	}
+	if subs == nil {
+		subs = []models.Subtitle{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subtitles": subs})
+}
+
+// BurnInRequest selects the subtitle track to hardcode into a rendition set
+type BurnInRequest struct {
+	Language string `json:"language" binding:"required"`
+}
+
+// RequestBurnIn queues an additional rendition set with a subtitle track
+// burned into the picture, for distribution targets that need hardcoded subs
+func (h *FilmHandler) RequestBurnIn(c *gin.Context) {
+	idParam := c.Param("id")
+	filmID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	var req BurnInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Get film to verify ownership and status
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	// Check ownership
+	userID, _ := GetUserID(c)
+	if film.CreatedByID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+		return
+	}
+
+	if film.Status != models.StatusReady {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "film must be in READY status"})
+		return
+	}
+
+	// The subtitle track must already be imported
+	subs, err := h.queries.GetSubtitlesByFilmID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve subtitles"})
+		return
+	}
+	found := false
+	for _, sub := range subs {
+		if sub.Language == req.Language {
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no subtitle track for language " + req.Language})
+		return
+	}
+
+	task := &models.WorkerTask{
+		ID:          uuid.New(),
+		Type:        models.TaskBurnInSubtitles,
+		FilmID:      filmID,
+		Params:      map[string]string{"language": req.Language},
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.redis.EnqueueTask(ctx, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue task"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Burn-in rendition queued",
+		"task":    task,
+		"variant": models.BurnInVariant(req.Language),
+	})
+}
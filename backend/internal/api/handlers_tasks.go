@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetTask returns the status of a worker task requested by the current user
+func (h *FilmHandler) GetTask(c *gin.Context) {
+	idParam := c.Param("id")
+	taskID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	task, err := h.redis.GetTask(c.Request.Context(), taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	role, _ := GetUserRole(c)
+	if task.RequestedBy != userID && !auth.IsAdmin(role) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
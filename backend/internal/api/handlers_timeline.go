@@ -0,0 +1,145 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/timeline"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TimelineHandler serves film timelines to editing and review UIs
+type TimelineHandler struct {
+	queries  *db.Queries
+	timeline *timeline.Builder
+}
+
+func NewTimelineHandler(queries *db.Queries, builder *timeline.Builder) *TimelineHandler {
+	return &TimelineHandler{queries: queries, timeline: builder}
+}
+
+// MarkerInput is one chapter, ad break, intro or credits marker; end is
+// optional for chapters and ad breaks
+type MarkerInput struct {
+	Kind  models.MarkerKind `json:"kind" binding:"required"`
+	Title string            `json:"title"`
+	Start float64           `json:"start"`
+	End   *float64          `json:"end"`
+}
+
+// SetMarkersRequest replaces all of a film's timeline markers
+type SetMarkersRequest struct {
+	Markers []MarkerInput `json:"markers"`
+}
+
+// GetTimeline returns a film's timeline: chapters, ad markers, intro and
+// credits ranges, subtitle cue density and the thumbnail sprite sheet.
+// Only the film's creator and admins can read it.
+func (h *TimelineHandler) GetTimeline(c *gin.Context) {
+	film, ok := h.ownedFilm(c)
+	if !ok {
+		return
+	}
+
+	doc, err := h.timeline.Get(c.Request.Context(), film)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build timeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+// ListMarkers returns the markers a film's creator set
+func (h *TimelineHandler) ListMarkers(c *gin.Context) {
+	film, ok := h.ownedFilm(c)
+	if !ok {
+		return
+	}
+
+	markers, err := h.queries.ListFilmMarkers(c.Request.Context(), film.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve markers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"markers": markers})
+}
+
+// SetMarkers replaces a film's timeline markers and regenerates its timeline
+func (h *TimelineHandler) SetMarkers(c *gin.Context) {
+	film, ok := h.ownedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req SetMarkersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	markers := make([]models.FilmMarker, len(req.Markers))
+	for i, m := range req.Markers {
+		markers[i] = models.FilmMarker{
+			ID:           uuid.New(),
+			FilmID:       film.ID,
+			Kind:         m.Kind,
+			Title:        m.Title,
+			StartSeconds: m.Start,
+			EndSeconds:   m.End,
+		}
+	}
+	if err := timeline.ValidateMarkers(markers, film.Duration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.queries.ReplaceFilmMarkers(ctx, film.ID, markers); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save markers"})
+		return
+	}
+	saved, err := h.queries.ListFilmMarkers(ctx, film.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve markers"})
+		return
+	}
+
+	doc, err := h.timeline.Build(ctx, film)
+	if err != nil {
+		// The markers are saved; the next read regenerates the timeline
+		h.timeline.Invalidate(ctx, film.ID)
+		c.JSON(http.StatusOK, gin.H{"markers": saved})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"markers": saved, "timeline": doc})
+}
+
+// ownedFilm loads the film in the path and checks the caller created it or
+// is an admin, writing the error response when not
+func (h *TimelineHandler) ownedFilm(c *gin.Context) (*models.Film, bool) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return nil, false
+	}
+
+	film, err := h.queries.GetFilmByID(c.Request.Context(), filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return nil, false
+	}
+
+	userID, _ := GetUserID(c)
+	role, _ := GetUserRole(c)
+	if film.CreatedByID != userID && !auth.IsAdmin(role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+		return nil, false
+	}
+	return film, true
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// topFilmsMaxAge is how long clients may cache a top films list (seconds);
+// the counters themselves refresh every TOP_FILMS_INTERVAL_MINUTES
+const topFilmsMaxAge = 60
+
+// GetTopFilms returns the most watched published films of a window
+// (?window=day|week|month, default week) from the materialized counters.
+// Accepts the same filters as ListFilms.
+func (h *FilmHandler) GetTopFilms(c *gin.Context) {
+	window := models.TopWindow(c.DefaultQuery("window", string(models.TopWindowWeek)))
+	if _, ok := models.TopWindowDays[window]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be one of day, week, month"})
+		return
+	}
+
+	filter, err := parseFilmFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.Status = models.StatusReady
+
+	country := GetCountry(c)
+	filter.Region = &country
+	filter.Listed = true
+
+	films, computedAt, err := h.queries.ListTopFilms(c.Request.Context(), window, filter, pagination.ParseLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve top films"})
+		return
+	}
+
+	// Lists vary by viewer region, so only the client may cache them
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", topFilmsMaxAge))
+	c.JSON(http.StatusOK, gin.H{
+		"window":      window,
+		"computed_at": computedAt,
+		"items":       films,
+	})
+}
@@ -0,0 +1,485 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/preflight"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxMultipartFileSize bounds the sources uploaded in parts
+const maxMultipartFileSize = 100 << 30
+
+// sourceContentTypes are the MIME types a film's source may be uploaded as
+var sourceContentTypes = map[string]bool{
+	"video/mp4":        true,
+	"video/quicktime":  true,
+	"video/x-matroska": true,
+	"video/webm":       true,
+	"video/x-msvideo":  true,
+	"video/mpeg":       true,
+	"video/mp2t":       true,
+}
+
+// ConfirmUploadPartRequest represents a completed multipart upload part
+type ConfirmUploadPartRequest struct {
+	PartNumber int    `json:"part_number" binding:"required,min=1,max=10000"`
+	SizeBytes  int64  `json:"size_bytes" binding:"required,min=1"`
+	ETag       string `json:"etag"`
+}
+
+// PresignUploadPartsRequest asks for upload URLs for up to 100 parts of
+// the film's multipart upload
+type PresignUploadPartsRequest struct {
+	PartNumbers []int `json:"part_numbers" binding:"required,min=1,max=100,dive,min=1,max=10000"`
+}
+
+// CompleteMultipartUploadRequest lists the uploaded parts with the ETags
+// storage returned for them. Without parts, the parts in storage are used.
+type CompleteMultipartUploadRequest struct {
+	Parts []r2.UploadedPart `json:"parts" binding:"omitempty,max=10000,dive"`
+}
+
+// UploadPreflightRequest describes a source file before it is uploaded,
+// as read by the client, e.g. with ffprobe
+type UploadPreflightRequest struct {
+	Container  string `json:"container" binding:"required,max=16"`
+	VideoCodec string `json:"video_codec" binding:"required,max=32"`
+	AudioCodec string `json:"audio_codec" binding:"max=32"`
+	Width      int    `json:"width" binding:"required,min=1"`
+	Height     int    `json:"height" binding:"required,min=1"`
+	FileSize   int64  `json:"file_size" binding:"required,min=1"`
+}
+
+// UploadPreflight checks a source file against the rules the worker
+// enforces, so a file that would fail transcoding isn't uploaded. Sources
+// above the single upload cap are accepted when they fit a multipart
+// upload, which the response asks for.
+func (h *FilmHandler) UploadPreflight(c *gin.Context) {
+	if _, ok := h.requireOwnedFilm(c); !ok {
+		return
+	}
+	var req UploadPreflightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reasons := preflight.Check(preflight.Source{
+		Container:  req.Container,
+		VideoCodec: req.VideoCodec,
+		AudioCodec: req.AudioCodec,
+		Width:      req.Width,
+		Height:     req.Height,
+		Size:       req.FileSize,
+	}, maxMultipartFileSize)
+	if reasons == nil {
+		reasons = []string{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"accepted":  len(reasons) == 0,
+		"reasons":   reasons,
+		"multipart": req.FileSize > h.settings.Int(c.Request.Context(), settings.KeyMaxUploadBytes),
+	})
+}
+
+// PresignUploadParts returns pre-signed URLs for uploading parts of the
+// film's multipart upload. Parts can be asked for again, e.g. to retry one
+// whose URL expired.
+func (h *FilmHandler) PresignUploadParts(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req PresignUploadPartsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	upload, ok := h.requireMultipartUpload(c, film.ID)
+	if !ok {
+		return
+	}
+
+	expiration := h.uploadURLExpiration()
+	source := h.r2Client.ForRegion(upload.Region)
+	urls := make([]gin.H, 0, len(req.PartNumbers))
+	for _, partNumber := range req.PartNumbers {
+		if partNumber > upload.TotalParts {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("the upload has %d parts", upload.TotalParts)})
+			return
+		}
+		url, err := source.GeneratePresignedUploadPartURL(ctx, upload.Key, upload.UploadID, partNumber, upload.PartLength(partNumber), expiration)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate upload part URL"})
+			return
+		}
+		urls = append(urls, gin.H{"part_number": partNumber, "upload_url": url, "size": upload.PartLength(partNumber)})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"upload_id":  upload.UploadID,
+		"parts":      urls,
+		"expiration": expiration.String(),
+	})
+}
+
+// CompleteMultipartUpload assembles the film's uploaded parts into its
+// source. Confirm the upload afterwards to start transcoding.
+func (h *FilmHandler) CompleteMultipartUpload(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	var req CompleteMultipartUploadRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	upload, ok := h.requireMultipartUpload(c, film.ID)
+	if !ok {
+		return
+	}
+	source := h.r2Client.ForRegion(upload.Region)
+
+	// Browsers often can't read ETag headers, so the parts can come from
+	// storage instead
+	parts := req.Parts
+	if len(parts) == 0 {
+		stored, err := source.ListUploadedParts(ctx, upload.Key, upload.UploadID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list uploaded parts"})
+			return
+		}
+		parts = stored
+	}
+
+	uploaded := map[int]bool{}
+	for _, part := range parts {
+		if part.PartNumber < 1 || part.PartNumber > upload.TotalParts || part.ETag == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parts need a part_number within the upload and an etag"})
+			return
+		}
+		uploaded[part.PartNumber] = true
+	}
+	missing := []int{}
+	for n := 1; n <= upload.TotalParts; n++ {
+		if !uploaded[n] {
+			missing = append(missing, n)
+		}
+	}
+	if len(missing) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":         "not every part has been uploaded",
+			"missing_parts": missing,
+		})
+		return
+	}
+
+	if err := source.CompleteMultipartUpload(ctx, upload.Key, upload.UploadID, parts); err != nil {
+		log.Printf("Failed to complete multipart upload of film %s: %v", film.ID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "storage refused to complete the upload; check the parts and their etags"})
+		return
+	}
+	if err := h.redis.ClearMultipartUpload(ctx, film.ID); err != nil {
+		log.Printf("Failed to clear multipart upload of film %s: %v", film.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Upload completed. Confirm it to start transcoding.",
+		"parts":   len(parts),
+	})
+}
+
+// AbortMultipartUpload discards the film's unfinished multipart upload and
+// the parts uploaded so far
+func (h *FilmHandler) AbortMultipartUpload(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	upload, ok := h.requireMultipartUpload(c, film.ID)
+	if !ok {
+		return
+	}
+
+	if err := h.r2Client.ForRegion(upload.Region).AbortMultipartUpload(ctx, upload.Key, upload.UploadID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to abort multipart upload"})
+		return
+	}
+	if err := h.redis.ClearMultipartUpload(ctx, film.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear multipart upload"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Multipart upload aborted"})
+}
+
+// requireMultipartUpload loads the film's unfinished multipart upload. It
+// writes the error response itself and returns false when there is none.
+func (h *FilmHandler) requireMultipartUpload(c *gin.Context, filmID uuid.UUID) (*models.MultipartUpload, bool) {
+	upload, err := h.redis.GetMultipartUpload(c.Request.Context(), filmID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get multipart upload"})
+		return nil, false
+	}
+	if upload == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film has no multipart upload in progress"})
+		return nil, false
+	}
+	return upload, true
+}
+
+// startMultipartUpload starts a multipart upload of a film's source to the
+// region's bucket and records it
+func (h *FilmHandler) startMultipartUpload(ctx context.Context, filmID uuid.UUID, region, contentType string, fileSize int64) (*models.MultipartUpload, error) {
+	key := r2.GetOriginalKey(filmID)
+	uploadID, err := h.r2Client.ForRegion(region).CreateMultipartUpload(ctx, key, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	partSize := r2.PartSize(fileSize)
+	upload := &models.MultipartUpload{
+		FilmID:     filmID,
+		UploadID:   uploadID,
+		Key:        key,
+		Region:     region,
+		FileSize:   fileSize,
+		PartSize:   partSize,
+		TotalParts: int((fileSize + partSize - 1) / partSize),
+		StartedAt:  time.Now(),
+	}
+	if err := h.redis.SetMultipartUpload(ctx, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// abortMultipartUpload discards a film's unfinished multipart upload, if
+// any, before it is replaced. Failures are logged; storage lifecycle rules
+// clean up parts left behind.
+func (h *FilmHandler) abortMultipartUpload(ctx context.Context, filmID uuid.UUID) {
+	upload, err := h.redis.GetMultipartUpload(ctx, filmID)
+	if err != nil || upload == nil {
+		return
+	}
+	if err := h.r2Client.ForRegion(upload.Region).AbortMultipartUpload(ctx, upload.Key, upload.UploadID); err != nil {
+		log.Printf("Failed to abort multipart upload of film %s: %v", filmID, err)
+	}
+	if err := h.redis.ClearMultipartUpload(ctx, filmID); err != nil {
+		log.Printf("Failed to clear multipart upload of film %s: %v", filmID, err)
+	}
+}
+
+// uploadURLExpiration is how long pre-signed upload part URLs stay valid
+func (h *FilmHandler) uploadURLExpiration() time.Duration {
+	if h.expiration <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(h.expiration) * time.Minute
+}
+
+// ConfirmUploadPart records a completed part of a multipart upload
+func (h *FilmHandler) ConfirmUploadPart(c *gin.Context) {
+	idParam := c.Param("id")
+	filmID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	var req ConfirmUploadPartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Get film to verify ownership
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	// Check ownership
+	userID, _ := GetUserID(c)
+	if film.CreatedByID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+		return
+	}
+
+	progress, err := h.redis.RecordUploadPart(ctx, filmID, req.PartNumber, req.SizeBytes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record upload part"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// GetUploadProgress returns byte-level progress of a film's upload
+func (h *FilmHandler) GetUploadProgress(c *gin.Context) {
+	idParam := c.Param("id")
+	filmID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Get film to verify ownership
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	// Check ownership
+	userID, _ := GetUserID(c)
+	if film.CreatedByID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+		return
+	}
+
+	progress, err := h.redis.GetUploadProgress(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve upload progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// StreamUploadProgress pushes upload progress updates as server-sent events
+func (h *FilmHandler) StreamUploadProgress(c *gin.Context) {
+	idParam := c.Param("id")
+	filmID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Get film to verify ownership
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	// Check ownership
+	userID, _ := GetUserID(c)
+	if film.CreatedByID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not authorized"})
+		return
+	}
+
+	pubsub := h.redis.SubscribeUploadProgress(ctx, filmID)
+	defer pubsub.Close()
+	messages := pubsub.Channel()
+
+	// Send the current state first so clients don't wait for the next part
+	if progress, err := h.redis.GetUploadProgress(ctx, filmID); err == nil {
+		c.SSEvent("progress", progress)
+		c.Writer.Flush()
+	}
+
+	draining := drainSignal(c)
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-draining:
+			sendShutdownEvent(c)
+			return false
+		case msg, ok := <-messages:
+			if !ok {
+				return false
+			}
+			var progress models.UploadProgress
+			if err := json.Unmarshal([]byte(msg.Payload), &progress); err != nil {
+				return true
+			}
+			c.SSEvent("progress", progress)
+			return true
+		}
+	})
+}
+
+// StreamTranscodeStatus pushes a film's status changes and transcode
+// progress as server-sent events. The stream ends once the film is READY
+// or FAILED.
+func (h *FilmHandler) StreamTranscodeStatus(c *gin.Context) {
+	film, ok := h.requireOwnedFilm(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	pubsub := h.redis.SubscribeFilmStatus(ctx, film.ID)
+	defer pubsub.Close()
+	messages := pubsub.Channel()
+
+	// Send the current status first, read after subscribing so no change
+	// is missed; it may already be final
+	if current, err := h.queries.GetFilmByID(ctx, film.ID); err == nil {
+		film = current
+	}
+	c.SSEvent("status", models.FilmStatusUpdate{FilmID: film.ID, Status: film.Status})
+	c.Writer.Flush()
+	if transcodeFinished(film.Status) {
+		return
+	}
+
+	draining := drainSignal(c)
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-draining:
+			sendShutdownEvent(c)
+			return false
+		case msg, ok := <-messages:
+			if !ok {
+				return false
+			}
+			var update models.FilmStatusUpdate
+			if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+				return true
+			}
+			c.SSEvent("status", update)
+			return !transcodeFinished(update.Status)
+		}
+	})
+}
+
+// transcodeFinished reports whether a film status ends transcoding
+func transcodeFinished(status models.FilmStatus) bool {
+	return status == models.StatusReady || status == models.StatusFailed
+}
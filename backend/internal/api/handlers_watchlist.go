@@ -0,0 +1,126 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WatchlistHandler handles films users save for later
+type WatchlistHandler struct {
+	queries *db.Queries
+}
+
+func NewWatchlistHandler(queries *db.Queries) *WatchlistHandler {
+	return &WatchlistHandler{queries: queries}
+}
+
+// AddToWatchlist saves a published film the user may watch for later.
+// Saving it again is a no-op.
+func (h *WatchlistHandler) AddToWatchlist(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	film, err := h.queries.GetFilmByID(ctx, filmID)
+	if err != nil || film.Status != models.StatusReady || film.PublishedAt == nil || !policy.For(film).Reachable(GetCountry(c)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "film not found"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	addedAt, err := h.queries.AddToWatchlist(ctx, userID, filmID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save film"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"film_id": filmID, "in_watchlist": true, "added_at": addedAt})
+}
+
+// RemoveFromWatchlist removes a film from the user's watchlist. Removing
+// one that isn't saved is a no-op.
+func (h *WatchlistHandler) RemoveFromWatchlist(c *gin.Context) {
+	filmID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid film ID"})
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	if err := h.queries.RemoveFromWatchlist(c.Request.Context(), userID, filmID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove film"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"film_id": filmID, "in_watchlist": false})
+}
+
+// ListWatchlist returns the films the user saved and may still watch,
+// most recently added first, or oldest first with ?order=oldest
+func (h *WatchlistHandler) ListWatchlist(c *gin.Context) {
+	var oldestFirst bool
+	switch c.DefaultQuery("order", "newest") {
+	case "newest":
+	case "oldest":
+		oldestFirst = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order must be newest or oldest"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	country := GetCountry(c)
+	params := pagination.ParseOffset(c)
+
+	films, err := h.queries.ListWatchlist(ctx, userID, country, oldestFirst, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list watchlist"})
+		return
+	}
+	total, err := h.queries.CountWatchlist(ctx, userID, country)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count watchlist"})
+		return
+	}
+
+	saved := true
+	for i := range films {
+		films[i].InWatchlist = &saved
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(films, params, total, false))
+}
+
+// attachViewerWatchlist marks each film with whether the signed-in viewer
+// saved it. It is best effort: on failure the films go out without it.
+func attachViewerWatchlist(c *gin.Context, queries *db.Queries, films []models.Film) {
+	userID, ok := GetUserID(c)
+	if !ok || len(films) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, len(films))
+	for i := range films {
+		ids[i] = films[i].ID
+	}
+	saved, err := queries.GetWatchlistedFilmIDs(c.Request.Context(), userID, ids)
+	if err != nil {
+		log.Printf("Failed to load viewer watchlist: %v", err)
+		return
+	}
+	for i := range films {
+		inWatchlist := saved[films[i].ID]
+		films[i].InWatchlist = &inWatchlist
+	}
+}
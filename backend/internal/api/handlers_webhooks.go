@@ -0,0 +1,479 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/events"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/pagination"
+	"github.com/arjunaayasa/filmtube/internal/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxWebhookEndpoints bounds how many endpoints one user may have
+	maxWebhookEndpoints = 10
+
+	// Bulk replays queue this many deliveries by default and at most
+	defaultWebhookReplay = 100
+	maxWebhookReplay     = 1000
+
+	// webhookHealthWindow is how far back endpoint health looks
+	webhookHealthWindow = 24 * time.Hour
+)
+
+// WebhookHandler manages webhook endpoints and their deliveries
+type WebhookHandler struct {
+	queries    *db.Queries
+	dispatcher *webhooks.Dispatcher
+}
+
+func NewWebhookHandler(queries *db.Queries, dispatcher *webhooks.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{queries: queries, dispatcher: dispatcher}
+}
+
+// CreateWebhookRequest registers an endpoint. EventTypes limits the film
+// events sent (default all); AllFilms, for admins, sends every film's
+// events instead of only the user's own films'.
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,max=2048"`
+	EventTypes []string `json:"event_types"`
+	AllFilms   bool     `json:"all_films"`
+}
+
+// EnableWebhookRequest re-enables a disabled endpoint. A test delivery is
+// sent first and must succeed, unless Force is set.
+type EnableWebhookRequest struct {
+	Force bool `json:"force"`
+}
+
+// ReplayWebhooksRequest selects deliveries to send again: by status
+// (default failed), event type and creation time, oldest first
+type ReplayWebhooksRequest struct {
+	Status    models.WebhookDeliveryStatus `json:"status"`
+	EventType string                       `json:"event_type"`
+	From      *time.Time                   `json:"from"`
+	To        *time.Time                   `json:"to"`
+	Limit     int                          `json:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// VerifyWebhookSignatureRequest checks a signature a consumer received
+// against the endpoint's secret
+type VerifyWebhookSignatureRequest struct {
+	Payload   string `json:"payload" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+// webhookAttemptDetail is an attempt with its signature recomputed from
+// the stored payload and the endpoint's current secret
+type webhookAttemptDetail struct {
+	models.WebhookAttempt
+	SignatureHeader   string `json:"signature_header"`
+	ExpectedSignature string `json:"expected_signature"`
+	SignatureValid    bool   `json:"signature_valid"`
+}
+
+// requireWebhookEndpoint loads the endpoint named in the path, which must
+// be the current user's unless they are an admin
+func (h *WebhookHandler) requireWebhookEndpoint(c *gin.Context) (*models.WebhookEndpoint, bool) {
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return nil, false
+	}
+
+	endpoint, err := h.queries.GetWebhookEndpoint(c.Request.Context(), endpointID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get webhook"})
+		return nil, false
+	}
+
+	userID, _ := GetUserID(c)
+	role, _ := GetUserRole(c)
+	if endpoint.UserID != userID && !auth.IsAdmin(role) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return nil, false
+	}
+	return endpoint, true
+}
+
+// requireWebhookDelivery loads the endpoint and the delivery named in the
+// path, which must belong to it
+func (h *WebhookHandler) requireWebhookDelivery(c *gin.Context) (*models.WebhookEndpoint, *models.WebhookDelivery, bool) {
+	endpoint, ok := h.requireWebhookEndpoint(c)
+	if !ok {
+		return nil, nil, false
+	}
+	deliveryID, err := uuid.Parse(c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery ID"})
+		return nil, nil, false
+	}
+
+	delivery, err := h.queries.GetWebhookDelivery(c.Request.Context(), deliveryID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && delivery.EndpointID != endpoint.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "delivery not found"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get delivery"})
+		return nil, nil, false
+	}
+	return endpoint, delivery, true
+}
+
+// ListWebhooks returns the current user's webhook endpoints
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userID, _ := GetUserID(c)
+
+	endpoints, err := h.queries.ListWebhookEndpoints(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": endpoints})
+}
+
+// CreateWebhook registers an endpoint for the current user. Its signing
+// secret is only returned here.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := webhooks.ValidateURL(req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, t := range req.EventTypes {
+		if !events.ValidType(models.FilmEventType(t)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown event type " + t})
+			return
+		}
+	}
+	role, _ := GetUserRole(c)
+	if req.AllFilms && !auth.IsAdmin(role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can receive events of all films"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := GetUserID(c)
+	existing, err := h.queries.ListWebhookEndpoints(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create webhook"})
+		return
+	}
+	if len(existing) >= maxWebhookEndpoints {
+		c.JSON(http.StatusConflict, gin.H{"error": "webhook limit reached; delete one first"})
+		return
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create webhook"})
+		return
+	}
+	endpoint := &models.WebhookEndpoint{
+		ID:         uuid.New(),
+		UserID:     userID,
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: req.EventTypes,
+		AllFilms:   req.AllFilms,
+	}
+	if endpoint.EventTypes == nil {
+		endpoint.EventTypes = []string{}
+	}
+	if err := h.queries.CreateWebhookEndpoint(ctx, endpoint); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"webhook": endpoint, "secret": secret})
+}
+
+// DeleteWebhook deletes an endpoint and its delivery history
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	endpoint, ok := h.requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	if err := h.queries.DeleteWebhookEndpoint(c.Request.Context(), endpoint.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "webhook deleted"})
+}
+
+// GetWebhookHealth reports an endpoint's status, failure streak and, over
+// the last 24 hours, its attempts, success rate and latency, with its
+// pending and failed deliveries
+func (h *WebhookHandler) GetWebhookHealth(c *gin.Context) {
+	endpoint, ok := h.requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	health, err := h.queries.GetWebhookHealth(c.Request.Context(), endpoint.ID, time.Now().Add(-webhookHealthWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load webhook health"})
+		return
+	}
+	var successRate *float64
+	if health.Attempts > 0 {
+		rate := float64(health.Successes) / float64(health.Attempts)
+		successRate = &rate
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhook":      endpoint,
+		"window_hours": int(webhookHealthWindow.Hours()),
+		"health":       health,
+		"success_rate": successRate,
+	})
+}
+
+// EnableWebhook re-enables a disabled endpoint after a successful test
+// delivery, or without one when forced. Deliveries queued while it was
+// disabled are sent next; ones that ran out of retries can be replayed.
+func (h *WebhookHandler) EnableWebhook(c *gin.Context) {
+	endpoint, ok := h.requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	var req EnableWebhookRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if endpoint.Status == models.WebhookEndpointActive {
+		c.JSON(http.StatusOK, gin.H{"webhook": endpoint})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var ping *models.WebhookAttempt
+	if !req.Force {
+		attempt, err := h.dispatcher.Ping(ctx, endpoint)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send test delivery"})
+			return
+		}
+		if !attempt.Succeeded() {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": "test delivery failed; fix the endpoint or enable with force",
+				"ping":  attempt,
+			})
+			return
+		}
+		ping = attempt
+	}
+
+	endpoint, err := h.queries.EnableWebhookEndpoint(ctx, endpoint.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enable webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhook": endpoint, "ping": ping})
+}
+
+// ListWebhookDeliveries lists an endpoint's deliveries, newest first,
+// optionally by status, event_type and creation time (from, to; RFC 3339)
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	endpoint, ok := h.requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	filter := db.WebhookDeliveryFilter{
+		Status:    models.WebhookDeliveryStatus(c.Query("status")),
+		EventType: c.Query("event_type"),
+	}
+	if filter.Status != "" && !validDeliveryStatus(filter.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, succeeded or failed"})
+		return
+	}
+	if filter.From, ok = parseTimeQuery(c, "from"); !ok {
+		return
+	}
+	if filter.To, ok = parseTimeQuery(c, "to"); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	params := pagination.ParseOffset(c)
+	deliveries, err := h.queries.ListWebhookDeliveries(ctx, endpoint.ID, filter, params.Offset, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list deliveries"})
+		return
+	}
+	total, err := h.queries.CountWebhookDeliveries(ctx, endpoint.ID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewOffsetPage(deliveries, params, total, false))
+}
+
+// GetWebhookDelivery returns a delivery with the payload sent and every
+// attempt, each with the response received and its signature recomputed
+// with the endpoint's current secret
+func (h *WebhookHandler) GetWebhookDelivery(c *gin.Context) {
+	endpoint, delivery, ok := h.requireWebhookDelivery(c)
+	if !ok {
+		return
+	}
+
+	attempts, err := h.queries.ListWebhookAttempts(c.Request.Context(), delivery.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list attempts"})
+		return
+	}
+	details := make([]webhookAttemptDetail, 0, len(attempts))
+	for _, a := range attempts {
+		expected := webhooks.Sign(endpoint.Secret, a.SignedAt, delivery.Payload)
+		details = append(details, webhookAttemptDetail{
+			WebhookAttempt:    a,
+			SignatureHeader:   webhooks.SignatureValue(a.SignedAt, a.Signature),
+			ExpectedSignature: expected,
+			SignatureValid:    expected == a.Signature,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"delivery": delivery,
+		"payload":  json.RawMessage(delivery.Payload),
+		"attempts": details,
+	})
+}
+
+// ReplayWebhookDelivery queues a delivery's payload to be sent again as a
+// new delivery
+func (h *WebhookHandler) ReplayWebhookDelivery(c *gin.Context) {
+	_, delivery, ok := h.requireWebhookDelivery(c)
+	if !ok {
+		return
+	}
+
+	original := delivery.ID
+	if delivery.ReplayOf != nil {
+		original = *delivery.ReplayOf
+	}
+	replay := &models.WebhookDelivery{
+		ID:         uuid.New(),
+		EndpointID: delivery.EndpointID,
+		EventID:    delivery.EventID,
+		EventType:  delivery.EventType,
+		Payload:    delivery.Payload,
+		ReplayOf:   &original,
+	}
+	if err := h.queries.CreateWebhookDelivery(c.Request.Context(), replay); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to replay delivery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"delivery": replay})
+}
+
+// ReplayWebhookDeliveries queues matching deliveries of an endpoint to be
+// sent again, such as everything that failed during an outage
+func (h *WebhookHandler) ReplayWebhookDeliveries(c *gin.Context) {
+	endpoint, ok := h.requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	var req ReplayWebhooksRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Status == "" {
+		req.Status = models.WebhookDeliveryFailed
+	}
+	if !validDeliveryStatus(req.Status) || req.Status == models.WebhookDeliveryPending {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be succeeded or failed"})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultWebhookReplay
+	}
+
+	filter := db.WebhookDeliveryFilter{
+		Status:    req.Status,
+		EventType: req.EventType,
+		From:      req.From,
+		To:        req.To,
+	}
+	queued, err := h.queries.ReplayWebhookDeliveries(c.Request.Context(), endpoint.ID, filter, min(req.Limit, maxWebhookReplay))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to replay deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"queued": queued})
+}
+
+// VerifyWebhookSignature checks a payload and signature header a consumer
+// received against the endpoint's secret, to debug their verification
+func (h *WebhookHandler) VerifyWebhookSignature(c *gin.Context) {
+	endpoint, ok := h.requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	var req VerifyWebhookSignatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": webhooks.Verify(endpoint.Secret, req.Signature, req.Payload)})
+}
+
+// validDeliveryStatus reports whether s names a delivery status
+func validDeliveryStatus(s models.WebhookDeliveryStatus) bool {
+	switch s {
+	case models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryFailed:
+		return true
+	}
+	return false
+}
+
+// parseTimeQuery reads an optional RFC 3339 time query parameter. Writes a
+// 400 and returns false when it is invalid.
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name + " time"})
+		return nil, false
+	}
+	return &t, true
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/gin-gonic/gin"
+)
+
+// WorkerHandler reports worker capacity per region
+type WorkerHandler struct {
+	redis *redis.Client
+}
+
+func NewWorkerHandler(redisClient *redis.Client) *WorkerHandler {
+	return &WorkerHandler{redis: redisClient}
+}
+
+// GetWorkerRegions returns the live workers and queued work of each region,
+// the work waiting in the shared queues for any worker and the transcodes
+// deferred by admission control
+func (h *WorkerHandler) GetWorkerRegions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	regions, err := h.redis.WorkerRegions(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load worker regions"})
+		return
+	}
+	jobs, err := h.redis.LLen(ctx, redis.TranscodeQueue).Result()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load shared queue"})
+		return
+	}
+	tasks, err := h.redis.LLen(ctx, redis.TaskQueue).Result()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load shared queue"})
+		return
+	}
+	deferred, err := h.redis.DeferredTranscodeJobs(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load deferred queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"regions": regions,
+		"shared": gin.H{
+			"queued_jobs":  jobs,
+			"queued_tasks": tasks,
+		},
+		"deferred_jobs": deferred,
+	})
+}
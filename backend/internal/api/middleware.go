@@ -1,11 +1,18 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/arjunaayasa/filmtube/internal/apikeys"
 	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/clientip"
+	"github.com/arjunaayasa/filmtube/internal/geo"
 	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/shutdown"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -16,11 +23,21 @@ const (
 	UserKey contextKey = "user"
 	UserIDKey contextKey = "user_id"
 	UserRoleKey contextKey = "user_role"
+	CountryKey contextKey = "country"
+	ClientIPKey contextKey = "client_ip"
+	ShutdownKey contextKey = "shutdown"
+	APIKeyIDKey contextKey = "api_key_id"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+// AuthMiddleware validates JWT tokens and refuses those of banned users
+func AuthMiddleware(jwtManager *auth.JWTManager, bans *BanCache) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Already authenticated by APIKeyMiddleware
+		if _, ok := c.Get(string(UserIDKey)); ok {
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
@@ -44,6 +61,13 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		// A ban outlives the tokens issued before it
+		if bans.Banned(c.Request.Context(), claims.UserID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "account is banned"})
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set(string(UserIDKey), claims.UserID)
 		c.Set(string(UserRoleKey), claims.Role)
@@ -53,6 +77,115 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware sets user info when a valid token is present but
+// lets anonymous requests through
+func OptionalAuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := jwtManager.ValidateToken(parts[1]); err == nil {
+				c.Set(string(UserIDKey), claims.UserID)
+				c.Set(string(UserRoleKey), claims.Role)
+				c.Set(string(UserKey), claims)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// APIKeyMiddleware authenticates requests carrying an X-API-Key header as
+// the key's user, enforces the key's rate limit and daily quota and
+// records its usage. Requests without the header pass through to
+// AuthMiddleware.
+func APIKeyMiddleware(keys *apikeys.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader("X-API-Key")
+		if raw == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key, err := keys.Authenticate(ctx, raw)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+			c.Abort()
+			return
+		}
+
+		start := time.Now()
+		decision := keys.Allow(ctx, &key.APIKey)
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(decision.Limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(decision.Remaining, 10))
+		if !decision.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(decision.RetryAfter.Seconds())+1))
+			msg := "API key rate limit exceeded"
+			if decision.QuotaExceeded {
+				msg = "API key daily quota exceeded"
+			}
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": msg})
+			c.Abort()
+			keys.Record(ctx, key.ID, http.StatusTooManyRequests, 0, true)
+			return
+		}
+
+		c.Set(string(UserIDKey), key.UserID)
+		c.Set(string(UserRoleKey), key.Role)
+		c.Set(string(UserKey), &auth.Claims{UserID: key.UserID, Email: key.Email, Role: key.Role})
+		c.Set(string(APIKeyIDKey), key.ID)
+
+		c.Next()
+
+		keys.Record(ctx, key.ID, c.Writer.Status(), time.Since(start), false)
+	}
+}
+
+// ClientIPMiddleware resolves the client's IP once, behind any trusted
+// proxies, for every later middleware and handler. Use GetClientIP rather
+// than c.ClientIP.
+func ClientIPMiddleware(resolver *clientip.Resolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(string(ClientIPKey), clientip.String(resolver.Resolve(c.Request)))
+		c.Next()
+	}
+}
+
+// CountryMiddleware resolves the viewer's country for region checks
+func CountryMiddleware(resolver *geo.Resolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(string(CountryKey), resolver.Country(c.Request, GetClientIP(c)))
+		c.Next()
+	}
+}
+
+// DrainMiddleware makes the shutdown coordinator available to handlers and,
+// once the instance is draining, asks clients to close keep-alive
+// connections so their next request goes to another instance
+func DrainMiddleware(coord *shutdown.Coordinator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(string(ShutdownKey), coord)
+		if coord.Draining() {
+			c.Header("Connection", "close")
+		}
+		c.Next()
+	}
+}
+
+// RejectWhileDraining refuses requests that start new work, such as
+// uploads, once the instance is shutting down; clients retry elsewhere
+func RejectWhileDraining(coord *shutdown.Coordinator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if coord.Draining() {
+			c.Header("Retry-After", strconv.Itoa(int(shutdown.ReconnectAfter.Seconds())))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "server is shutting down, retry shortly"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // RequireCreator middleware ensures user has creator or admin role
 func RequireCreator() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -95,6 +228,27 @@ func RequireAdmin() gin.HandlerFunc {
 	}
 }
 
+// RequirePress middleware ensures user has press or admin role
+func RequirePress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := c.Get(string(UserRoleKey))
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+
+		userRole := role.(models.UserRole)
+		if !auth.IsPress(userRole) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "press access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // GetUserID retrieves user ID from context
 func GetUserID(c *gin.Context) (uuid.UUID, bool) {
 	userID, exists := c.Get(string(UserIDKey))
@@ -112,3 +266,39 @@ func GetUserRole(c *gin.Context) (models.UserRole, bool) {
 	}
 	return role.(models.UserRole), true
 }
+
+// GetAPIKeyID retrieves the ID of the API key authenticating the request
+func GetAPIKeyID(c *gin.Context) (uuid.UUID, bool) {
+	keyID, exists := c.Get(string(APIKeyIDKey))
+	if !exists {
+		return uuid.Nil, false
+	}
+	return keyID.(uuid.UUID), true
+}
+
+// GetCountry retrieves the viewer's country code ("" when unknown)
+func GetCountry(c *gin.Context) string {
+	return c.GetString(string(CountryKey))
+}
+
+// GetClientIP retrieves the client's IP address ("" when unknown)
+func GetClientIP(c *gin.Context) string {
+	return c.GetString(string(ClientIPKey))
+}
+
+// drainSignal returns a channel closed when the instance starts shutting
+// down; without DrainMiddleware it is nil and never fires
+func drainSignal(c *gin.Context) <-chan struct{} {
+	if coord, ok := c.Get(string(ShutdownKey)); ok {
+		return coord.(*shutdown.Coordinator).Done()
+	}
+	return nil
+}
+
+// sendShutdownEvent ends a server-sent event stream with a "shutdown"
+// event whose retry field tells the client when to reconnect
+func sendShutdownEvent(c *gin.Context) {
+	after := shutdown.ReconnectAfter.Milliseconds()
+	fmt.Fprintf(c.Writer, "retry: %d\n", after)
+	c.SSEvent("shutdown", gin.H{"reconnect_after_ms": after})
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/gin-gonic/gin"
+)
+
+// APIRateLimit limits how many API requests each client can make per
+// minute, per the ratelimit.api_requests_per_minute setting. Signed-in
+// users are counted by user and anonymous callers by client IP; requests
+// made with an API key are left to that key's own limits. When Redis is
+// unavailable requests go through.
+func APIRateLimit(redisClient *redis.Client, settingsService *settings.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(string(APIKeyIDKey)); ok {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		limit := settingsService.Int(ctx, settings.KeyAPIRateLimit)
+		client := "ip:" + GetClientIP(c)
+		if userID, ok := GetUserID(c); ok {
+			client = "user:" + userID.String()
+		}
+
+		now := time.Now()
+		count, err := redisClient.CountAPIRequest(ctx, client, now)
+		if err != nil {
+			log.Printf("[RateLimit] Failed to count request, allowing it: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(max(limit-count, 0), 10))
+		if count > limit {
+			retryAfter := now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded, try again later"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ReportRateLimit limits how many film and comment reports each user can
+// send per hour, per the ratelimit.reports_per_hour setting. It runs after
+// AuthMiddleware; when Redis is unavailable reports go through.
+func ReportRateLimit(redisClient *redis.Client, settingsService *settings.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID, ok := GetUserID(c)
+		limit := settingsService.Int(ctx, settings.KeyReportRateLimit)
+		if !ok || limit <= 0 {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		count, err := redisClient.CountReport(ctx, userID, now)
+		if err != nil {
+			log.Printf("[Reports] Failed to count report, allowing it: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(max(limit-count, 0), 10))
+		if count > limit {
+			retryAfter := now.Truncate(time.Hour).Add(time.Hour).Sub(now)
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many reports, try again later"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CommentRateLimit limits how many comments and replies each user can post
+// per minute and per hour, per the ratelimit.comments_per_minute and
+// ratelimit.comments_per_hour settings. It runs after AuthMiddleware; when
+// Redis is unavailable comments go through.
+func CommentRateLimit(redisClient *redis.Client, settingsService *settings.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID, ok := GetUserID(c)
+		perMinute := settingsService.Int(ctx, settings.KeyCommentRateMinute)
+		perHour := settingsService.Int(ctx, settings.KeyCommentRateHour)
+		if !ok || (perMinute <= 0 && perHour <= 0) {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		minute, hour, err := redisClient.CountComment(ctx, userID, now)
+		if err != nil {
+			log.Printf("[Comments] Failed to count comment, allowing it: %v", err)
+			c.Next()
+			return
+		}
+
+		var retryAfter time.Duration
+		switch {
+		case perHour > 0 && hour > perHour:
+			retryAfter = now.Truncate(time.Hour).Add(time.Hour).Sub(now)
+		case perMinute > 0 && minute > perMinute:
+			retryAfter = now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+		default:
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many comments, try again later"})
+		c.Abort()
+	}
+}
@@ -0,0 +1,214 @@
+// Package apikeys issues API keys, authenticates requests made with them,
+// enforces each key's rate limit and daily quota and records its usage.
+// Limits are counted per key in Redis, separately from any per-user
+// limits, so one busy integration can't starve a user's other keys.
+// Usage is counted in Redis and flushed to Postgres hourly buckets.
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/google/uuid"
+)
+
+const (
+	// Prefix starts every key, so leaked keys are easy to spot
+	Prefix = "ftk_"
+
+	// displayPrefixLen is how much of a key is kept to identify it
+	displayPrefixLen = 12
+
+	// flushBatchSize bounds how many hourly usage buckets one flush reads
+	flushBatchSize = 500
+)
+
+// ErrInvalidKey is returned for unknown and revoked keys
+var ErrInvalidKey = errors.New("invalid API key")
+
+// Decision is the outcome of counting a request against a key's limits
+type Decision struct {
+	Allowed bool
+	// Limit and Remaining describe the per-minute rate limit
+	Limit     int64
+	Remaining int64
+	// QuotaExceeded is set when the daily quota, not the rate limit,
+	// refused the request
+	QuotaExceeded bool
+	RetryAfter    time.Duration
+}
+
+// Service manages API keys
+type Service struct {
+	queries  *db.Queries
+	redis    *redis.Client
+	settings *settings.Service
+}
+
+// New creates an API key service
+func New(queries *db.Queries, redisClient *redis.Client, settingsService *settings.Service) *Service {
+	return &Service{
+		queries:  queries,
+		redis:    redisClient,
+		settings: settingsService,
+	}
+}
+
+// Create issues a new key for a user and returns it with the key itself,
+// which is not stored and can't be shown again
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, name string) (*models.APIKey, string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	raw := Prefix + hex.EncodeToString(secret)
+
+	key := &models.APIKey{
+		ID:      uuid.New(),
+		UserID:  userID,
+		Name:    name,
+		Prefix:  raw[:displayPrefixLen],
+		KeyHash: hash(raw),
+	}
+	if err := s.queries.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return key, raw, nil
+}
+
+// Authenticate returns the active key matching raw, with its user
+func (s *Service) Authenticate(ctx context.Context, raw string) (*models.APIKeyOwner, error) {
+	key, err := s.queries.GetAPIKeyByHash(ctx, hash(raw))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidKey
+	}
+	return key, err
+}
+
+// Limits returns a key's requests per minute and per day (0 = unlimited)
+func (s *Service) Limits(ctx context.Context, key *models.APIKey) (perMinute, perDay int64) {
+	perMinute = s.settings.Int(ctx, settings.KeyAPIKeyRateLimit)
+	if key.RateLimitPerMinute != nil {
+		perMinute = int64(*key.RateLimitPerMinute)
+	}
+	perDay = s.settings.Int(ctx, settings.KeyAPIKeyDailyQuota)
+	if key.DailyQuota != nil {
+		perDay = int64(*key.DailyQuota)
+	}
+	return perMinute, perDay
+}
+
+// Allow counts a request against a key's rate limit and daily quota. When
+// Redis is unavailable requests are allowed rather than failing the API.
+func (s *Service) Allow(ctx context.Context, key *models.APIKey) Decision {
+	perMinute, perDay := s.Limits(ctx, key)
+	decision := Decision{Allowed: true, Limit: perMinute, Remaining: perMinute}
+
+	now := time.Now()
+	minute, day, err := s.redis.CountAPIKeyRequest(ctx, key.ID, now)
+	if err != nil {
+		log.Printf("[APIKeys] Failed to count request, allowing it: %v", err)
+		return decision
+	}
+
+	decision.Remaining = max(perMinute-minute, 0)
+	switch {
+	case perDay > 0 && day > perDay:
+		decision.Allowed = false
+		decision.QuotaExceeded = true
+		decision.RetryAfter = now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+	case minute > perMinute:
+		decision.Allowed = false
+		decision.RetryAfter = now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+	}
+	return decision
+}
+
+// Record counts a finished request in the key's usage
+func (s *Service) Record(ctx context.Context, keyID uuid.UUID, status int, latency time.Duration, rateLimited bool) {
+	usage := models.APIKeyUsage{Period: time.Now(), Requests: 1}
+	switch {
+	case rateLimited:
+		usage.RateLimited = 1
+	case status >= 500:
+		usage.ServerErrors = 1
+	case status >= 400:
+		usage.ClientErrors = 1
+	}
+	if !rateLimited {
+		usage.LatencyMs = latency.Milliseconds()
+	}
+	if err := s.redis.AddAPIKeyUsage(ctx, keyID, usage); err != nil {
+		log.Printf("[APIKeys] Failed to record usage of key %s: %v", keyID, err)
+	}
+}
+
+// Flush writes usage counted since the last flush to Postgres and returns
+// how many hourly buckets it wrote. Instances may flush concurrently;
+// each bucket is taken by one of them.
+func (s *Service) Flush(ctx context.Context) (int, error) {
+	flushed := 0
+	for {
+		batch, err := s.redis.PopAPIKeyUsage(ctx, flushBatchSize)
+		if err != nil {
+			return flushed, err
+		}
+		if len(batch) == 0 {
+			return flushed, nil
+		}
+
+		for i, delta := range batch {
+			if err := s.queries.AddAPIKeyUsage(ctx, delta.KeyID, &delta.Usage); err != nil {
+				s.restore(ctx, batch[i:])
+				return flushed, err
+			}
+			flushed++
+		}
+		if len(batch) < flushBatchSize {
+			return flushed, nil
+		}
+	}
+}
+
+// restore puts usage that failed to flush back in Redis for the next flush
+func (s *Service) restore(ctx context.Context, deltas []redis.APIKeyUsageDelta) {
+	for _, delta := range deltas {
+		if err := s.redis.AddAPIKeyUsage(ctx, delta.KeyID, delta.Usage); err != nil {
+			log.Printf("[APIKeys] Lost usage of key %s: %v", delta.KeyID, err)
+		}
+	}
+}
+
+// RunFlushLoop flushes usage on every interval until ctx is cancelled
+func (s *Service) RunFlushLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.Flush(ctx)
+			if err != nil {
+				log.Printf("[APIKeys] Usage flush failed after %d buckets: %v", n, err)
+			}
+		}
+	}
+}
+
+// hash is the stored form of a key
+func hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,261 @@
+package approval
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoOrganization  = errors.New("film does not belong to an organization")
+	ErrNotMember       = errors.New("not a member of the film's organization")
+	ErrNotApprover     = errors.New("your role cannot approve films in this organization")
+	ErrNotReady        = errors.New("only transcoded, unpublished films can be submitted")
+	ErrInvalidState    = errors.New("film is not in a state that allows this action")
+	ErrSelfApproval    = errors.New("submitters cannot approve their own film")
+	ErrAlreadyApproved = errors.New("you already approved this submission")
+)
+
+// Result reports where a film stands after an approval action
+type Result struct {
+	State     models.ApprovalState      `json:"approval_state"`
+	Approvals int                       `json:"approvals"`
+	Required  int                       `json:"required_approvals"`
+	Event     *models.FilmApprovalEvent `json:"event"`
+}
+
+// Workflow drives films through their organization's approval chain
+// (submit → review → approve/reject) and routes notifications to the
+// people who need to act next
+type Workflow struct {
+	queries *db.Queries
+}
+
+// NewWorkflow creates an approval workflow
+func NewWorkflow(queries *db.Queries) *Workflow {
+	return &Workflow{queries: queries}
+}
+
+// Submit asks the organization's approvers to sign off on a film
+func (w *Workflow) Submit(ctx context.Context, film *models.Film, actorID uuid.UUID, comment *string) (*Result, error) {
+	tenant, _, err := w.member(ctx, film, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if film.Status != models.StatusReady || film.PublishedAt != nil {
+		return nil, ErrNotReady
+	}
+
+	result, err := w.record(ctx, film, tenant, actorID, models.ApprovalActionSubmit, comment,
+		[]models.ApprovalState{models.ApprovalNone, models.ApprovalRejected},
+		func(int) models.ApprovalState { return models.ApprovalSubmitted },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	approvers, err := w.queries.ListTenantMemberIDsByRole(ctx, tenant.ID, tenant.ApproverRoles)
+	if err != nil {
+		log.Printf("[Approval] Failed to list approvers for %s: %v", tenant.ID, err)
+		return result, nil
+	}
+	recipients := make([]uuid.UUID, 0, len(approvers))
+	for _, id := range approvers {
+		if id != actorID {
+			recipients = append(recipients, id)
+		}
+	}
+	w.notify(ctx, film, tenant, recipients, models.NotifyApprovalRequested,
+		fmt.Sprintf("%q was submitted for approval", film.Title))
+	return result, nil
+}
+
+// Review marks a submitted film as being reviewed by an approver
+func (w *Workflow) Review(ctx context.Context, film *models.Film, actorID uuid.UUID) (*Result, error) {
+	tenant, err := w.approver(ctx, film, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := w.record(ctx, film, tenant, actorID, models.ApprovalActionReview, nil,
+		[]models.ApprovalState{models.ApprovalSubmitted},
+		func(int) models.ApprovalState { return models.ApprovalInReview },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	w.notifySubmitter(ctx, film, tenant, models.NotifyApprovalReview,
+		fmt.Sprintf("%q is being reviewed", film.Title))
+	return result, nil
+}
+
+// Approve records an approver's sign-off; the film becomes approved once
+// the organization's required number of distinct approvers have signed
+func (w *Workflow) Approve(ctx context.Context, film *models.Film, actorID uuid.UUID, comment *string) (*Result, error) {
+	tenant, err := w.approver(ctx, film, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if submit, err := w.queries.GetLatestApprovalEvent(ctx, film.ID, models.ApprovalActionSubmit); err == nil &&
+		submit.ActorID != nil && *submit.ActorID == actorID {
+		return nil, ErrSelfApproval
+	}
+	approved, err := w.queries.HasApprovedSinceSubmit(ctx, film.ID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if approved {
+		return nil, ErrAlreadyApproved
+	}
+
+	result, err := w.record(ctx, film, tenant, actorID, models.ApprovalActionApprove, comment,
+		[]models.ApprovalState{models.ApprovalSubmitted, models.ApprovalInReview},
+		func(approvals int) models.ApprovalState {
+			if approvals >= tenant.RequiredApprovals {
+				return models.ApprovalApproved
+			}
+			return models.ApprovalInReview
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.State == models.ApprovalApproved {
+		w.notifySubmitter(ctx, film, tenant, models.NotifyApprovalApproved,
+			fmt.Sprintf("%q was approved and can be published", film.Title))
+	} else {
+		w.notifySubmitter(ctx, film, tenant, models.NotifyApprovalReview,
+			fmt.Sprintf("%q has %d of %d approvals", film.Title, result.Approvals, result.Required))
+	}
+	return result, nil
+}
+
+// Reject sends a film back to its submitter with an optional reason
+func (w *Workflow) Reject(ctx context.Context, film *models.Film, actorID uuid.UUID, comment *string) (*Result, error) {
+	tenant, err := w.approver(ctx, film, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := w.record(ctx, film, tenant, actorID, models.ApprovalActionReject, comment,
+		[]models.ApprovalState{models.ApprovalSubmitted, models.ApprovalInReview},
+		func(int) models.ApprovalState { return models.ApprovalRejected },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("%q was rejected", film.Title)
+	if comment != nil && *comment != "" {
+		message += ": " + *comment
+	}
+	w.notifySubmitter(ctx, film, tenant, models.NotifyApprovalRejected, message)
+	return result, nil
+}
+
+// CanPublish reports whether the film's organization allows it to be
+// published in its current approval state
+func (w *Workflow) CanPublish(ctx context.Context, film *models.Film) (bool, error) {
+	if film.TenantID == nil {
+		return true, nil
+	}
+	tenant, err := w.queries.GetTenant(ctx, *film.TenantID)
+	if err != nil {
+		return false, err
+	}
+	return !tenant.ApprovalRequired || film.ApprovalState == models.ApprovalApproved, nil
+}
+
+// member loads the film's organization and the user's role in it
+func (w *Workflow) member(ctx context.Context, film *models.Film, userID uuid.UUID) (*models.Tenant, models.TenantRole, error) {
+	if film.TenantID == nil {
+		return nil, "", ErrNoOrganization
+	}
+	tenant, err := w.queries.GetTenant(ctx, *film.TenantID)
+	if err != nil {
+		return nil, "", err
+	}
+	role, err := w.queries.GetTenantMemberRole(ctx, tenant.ID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", ErrNotMember
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return tenant, role, nil
+}
+
+// approver is member, additionally requiring an approver role
+func (w *Workflow) approver(ctx context.Context, film *models.Film, userID uuid.UUID) (*models.Tenant, error) {
+	tenant, role, err := w.member(ctx, film, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.CanApprove(role) {
+		return nil, ErrNotApprover
+	}
+	return tenant, nil
+}
+
+// record appends an approval event and applies the resulting state
+func (w *Workflow) record(ctx context.Context, film *models.Film, tenant *models.Tenant, actorID uuid.UUID, action models.ApprovalAction, comment *string, from []models.ApprovalState, next func(approvals int) models.ApprovalState) (*Result, error) {
+	event := &models.FilmApprovalEvent{
+		FilmID:   film.ID,
+		TenantID: tenant.ID,
+		ActorID:  &actorID,
+		Action:   action,
+		Comment:  comment,
+	}
+	state, approvals, ok, err := w.queries.RecordApprovalAction(ctx, event, from, next)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidState
+	}
+	film.ApprovalState = state
+
+	return &Result{
+		State:     state,
+		Approvals: approvals,
+		Required:  tenant.RequiredApprovals,
+		Event:     event,
+	}, nil
+}
+
+// notifySubmitter notifies whoever last submitted the film, falling back
+// to its creator
+func (w *Workflow) notifySubmitter(ctx context.Context, film *models.Film, tenant *models.Tenant, kind, message string) {
+	recipient := film.CreatedByID
+	if submit, err := w.queries.GetLatestApprovalEvent(ctx, film.ID, models.ApprovalActionSubmit); err == nil && submit.ActorID != nil {
+		recipient = *submit.ActorID
+	}
+	w.notify(ctx, film, tenant, []uuid.UUID{recipient}, kind, message)
+}
+
+// notify stores one notification per recipient; failures are logged since
+// the approval action itself has already been recorded
+func (w *Workflow) notify(ctx context.Context, film *models.Film, tenant *models.Tenant, recipients []uuid.UUID, kind, message string) {
+	notifications := make([]models.Notification, len(recipients))
+	for i, userID := range recipients {
+		notifications[i] = models.Notification{
+			UserID:   userID,
+			Kind:     kind,
+			FilmID:   &film.ID,
+			TenantID: &tenant.ID,
+			Message:  message,
+		}
+	}
+	if err := w.queries.CreateNotifications(ctx, notifications); err != nil {
+		log.Printf("[Approval] Failed to notify %d users about film %s: %v", len(recipients), film.ID, err)
+	}
+}
@@ -95,3 +95,8 @@ func IsCreator(role models.UserRole) bool {
 func IsAdmin(role models.UserRole) bool {
 	return role == models.RoleAdmin
 }
+
+// IsPress checks if user has press or admin role
+func IsPress(role models.UserRole) bool {
+	return role == models.RolePress || role == models.RoleAdmin
+}
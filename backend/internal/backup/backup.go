@@ -0,0 +1,388 @@
+// Package backup takes nightly encrypted snapshots of platform data and
+// restores them.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	// Prefix is where backups are stored in the backup bucket, one
+	// directory per backup ID
+	Prefix = "backups/"
+
+	manifestName    = "manifest.json"
+	manifestVersion = 1
+	idLayout        = "20060102T150405Z"
+
+	backupLock  = "backup"
+	backupLease = 2 * time.Hour
+)
+
+// ErrDisabled is returned when no backup bucket is configured
+var ErrDisabled = errors.New("backups are not configured")
+
+// Tables are the tables backed up, each after the tables it references.
+// Analytics, logs, queues and other derived or disposable data are left
+// out.
+var Tables = []string{
+	"users", "tenants", "tenant_members", "platform_bootstrap", "settings", "transcode_profiles",
+	"films", "film_counts", "luts", "video_assets", "subtitles", "film_markers",
+	"film_approval_events", "featured_films", "release_events", "film_press_access",
+	"film_transactions", "film_purchases",
+	"creator_follows", "watchlist_items", "watch_history", "film_reactions", "film_reviews", "comments",
+	"playlists", "playlist_items", "saved_searches", "share_links",
+	"api_keys", "webhook_endpoints", "email_templates", "player_config_rules",
+	"tenant_playback_plans", "user_entitlements",
+}
+
+// InventoryPrefixes are the storage prefixes whose object keys each backup
+// records, so a restore can tell which objects are gone
+var InventoryPrefixes = []string{
+	r2.HLSPath + "/", r2.ThumbnailPath + "/", r2.SubtitlePath + "/",
+	r2.LUTPath + "/", r2.FeaturedPath + "/", r2.OriginalPath + "/",
+}
+
+// Manifest describes a backup. It is stored unencrypted beside the
+// backup's objects, is written last so only complete backups have one,
+// and holds no platform data.
+type Manifest struct {
+	Version    int         `json:"version"`
+	ID         string      `json:"id"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Encryption string      `json:"encryption"`
+	Tables     []TableFile `json:"tables"`
+	Playlists  File        `json:"playlists"` // every HLS playlist, by key
+	Inventory  File        `json:"inventory"` // object keys under InventoryPrefixes
+}
+
+// File is one sealed object of a backup
+type File struct {
+	Object string `json:"object"` // key in the backup bucket
+	Count  int64  `json:"count"`  // rows, playlists or object keys
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"` // of the sealed object
+}
+
+// TableFile is a backed-up table, one JSON object per line
+type TableFile struct {
+	Table string `json:"table"`
+	File
+}
+
+// Playlist is a backed-up HLS playlist
+type Playlist struct {
+	Key  string `json:"key"`
+	Body string `json:"body"`
+}
+
+// Service takes backups of the platform's tables and HLS playlists into
+// a separate bucket, and reads them back for restores
+type Service struct {
+	queries   *db.Queries
+	source    *r2.Client // the platform's bucket
+	bucket    *r2.Client // the backup bucket; nil when backups are off
+	redis     *redis.Client
+	key       []byte
+	hour      int // UTC hour the nightly backup is due
+	retention time.Duration
+	token     string
+	mu        sync.Mutex
+}
+
+// New creates a backup service. A nil bucket turns backups off.
+func New(queries *db.Queries, source, bucket *r2.Client, redisClient *redis.Client, key []byte, hour, retentionDays int) *Service {
+	return &Service{
+		queries:   queries,
+		source:    source,
+		bucket:    bucket,
+		redis:     redisClient,
+		key:       key,
+		hour:      hour,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		token:     uuid.New().String(),
+	}
+}
+
+// Enabled reports whether a backup bucket is configured
+func (s *Service) Enabled() bool {
+	return s.bucket != nil
+}
+
+// Hour returns the UTC hour the nightly backup is due
+func (s *Service) Hour() int {
+	return s.hour
+}
+
+// Run takes a backup now and records it, then deletes backups past the
+// retention. Concurrent calls on the same instance are serialized.
+func (s *Service) Run(ctx context.Context) (*models.BackupRun, error) {
+	if !s.Enabled() {
+		return nil, ErrDisabled
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	id := now.Format(idLayout)
+	run, err := s.queries.CreateBackupRun(ctx, Prefix+id+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	run.Status = models.BackupSucceeded
+	if err := s.run(ctx, run, id, now); err != nil {
+		msg := err.Error()
+		run.Status = models.BackupFailed
+		run.Error = &msg
+	}
+	if err := s.queries.FinishBackupRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	if run.Status == models.BackupSucceeded {
+		if err := s.prune(ctx, now); err != nil {
+			log.Printf("[Backup] Failed to delete old backups: %v", err)
+		}
+	}
+	return run, nil
+}
+
+func (s *Service) run(ctx context.Context, run *models.BackupRun, id string, now time.Time) error {
+	manifest := &Manifest{
+		Version:    manifestVersion,
+		ID:         id,
+		CreatedAt:  now,
+		Encryption: Encryption,
+		Tables:     []TableFile{},
+	}
+
+	// Tables, from one consistent snapshot
+	dumps := make(map[string]*bytes.Buffer, len(Tables))
+	counts := make(map[string]int64, len(Tables))
+	for _, table := range Tables {
+		dumps[table] = &bytes.Buffer{}
+	}
+	err := s.queries.DumpTables(ctx, Tables, func(table string, row []byte) error {
+		dumps[table].Write(row)
+		dumps[table].WriteByte('\n')
+		counts[table]++
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("dump tables: %w", err)
+	}
+	for _, table := range Tables {
+		file, err := s.put(ctx, fmt.Sprintf("%s%s/tables/%s.jsonl.enc", Prefix, id, table), dumps[table].Bytes(), counts[table])
+		if err != nil {
+			return fmt.Errorf("store %s: %w", table, err)
+		}
+		manifest.Tables = append(manifest.Tables, TableFile{Table: table, File: *file})
+		run.Tables++
+		run.Rows += counts[table]
+		run.Bytes += file.Bytes
+	}
+
+	// Object inventory, and the HLS playlists themselves: segments can be
+	// re-encoded from the original, but playlists are small and tie them
+	// together
+	var inventory []string
+	for _, prefix := range InventoryPrefixes {
+		keys, err := s.source.ListKeys(ctx, prefix)
+		if err != nil {
+			return fmt.Errorf("list %s: %w", prefix, err)
+		}
+		inventory = append(inventory, keys...)
+	}
+	playlists := []Playlist{}
+	for _, key := range inventory {
+		if !strings.HasSuffix(key, ".m3u8") {
+			continue
+		}
+		body, err := s.source.DownloadFile(ctx, key)
+		if err != nil {
+			return fmt.Errorf("download %s: %w", key, err)
+		}
+		playlists = append(playlists, Playlist{Key: key, Body: string(body)})
+	}
+
+	data, err := json.Marshal(playlists)
+	if err != nil {
+		return err
+	}
+	file, err := s.put(ctx, Prefix+id+"/playlists.json.enc", data, int64(len(playlists)))
+	if err != nil {
+		return fmt.Errorf("store playlists: %w", err)
+	}
+	manifest.Playlists = *file
+	run.Playlists = len(playlists)
+	run.Bytes += file.Bytes
+
+	file, err = s.put(ctx, Prefix+id+"/inventory.txt.enc", []byte(strings.Join(inventory, "\n")), int64(len(inventory)))
+	if err != nil {
+		return fmt.Errorf("store inventory: %w", err)
+	}
+	manifest.Inventory = *file
+	run.Objects = int64(len(inventory))
+	run.Bytes += file.Bytes
+
+	data, err = json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.bucket.UploadFile(ctx, Prefix+id+"/"+manifestName, bytes.NewReader(data), "application/json"); err != nil {
+		return fmt.Errorf("store manifest: %w", err)
+	}
+	run.Bytes += int64(len(data))
+	return nil
+}
+
+// put seals data and stores it in the backup bucket
+func (s *Service) put(ctx context.Context, key string, data []byte, count int64) (*File, error) {
+	sealed, err := seal(s.key, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.bucket.UploadFile(ctx, key, bytes.NewReader(sealed), "application/octet-stream"); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(sealed)
+	return &File{Object: key, Count: count, Bytes: int64(len(sealed)), SHA256: hex.EncodeToString(sum[:])}, nil
+}
+
+// prune deletes backups older than the retention, always keeping the
+// newest
+func (s *Service) prune(ctx context.Context, now time.Time) error {
+	ids, err := s.ids(ctx)
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if i == 0 {
+			continue
+		}
+		created, err := time.Parse(idLayout, id)
+		if err != nil || now.Sub(created) <= s.retention {
+			continue
+		}
+		if err := s.bucket.DeletePrefix(ctx, Prefix+id+"/"); err != nil {
+			return fmt.Errorf("delete backup %s: %w", id, err)
+		}
+		log.Printf("[Backup] Deleted backup %s", id)
+	}
+	return nil
+}
+
+// ids returns the IDs of the backups in the bucket, complete or not,
+// newest first
+func (s *Service) ids(ctx context.Context) ([]string, error) {
+	keys, err := s.bucket.ListKeys(ctx, Prefix)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, key := range keys {
+		id, _, ok := strings.Cut(strings.TrimPrefix(key, Prefix), "/")
+		if ok && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids, nil
+}
+
+// RunLoop takes the nightly backup. On every interval, once the day's
+// backup hour (UTC) has passed and no backup has run since, one instance
+// takes it. A failed backup is not retried until the next night; admins
+// can run one by hand. Blocks until ctx is cancelled.
+func (s *Service) RunLoop(ctx context.Context, interval time.Duration) {
+	if !s.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick(ctx)
+		}
+	}
+}
+
+func (s *Service) tick(ctx context.Context) {
+	due, err := s.due(ctx, time.Now().UTC())
+	if err != nil {
+		log.Printf("[Backup] Failed to check backup schedule: %v", err)
+		return
+	}
+	if !due {
+		return
+	}
+
+	ok, err := s.redis.AcquireLock(ctx, backupLock, s.token, backupLease)
+	if err != nil {
+		log.Printf("[Backup] Failed to acquire backup lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := s.redis.ReleaseLock(context.Background(), backupLock, s.token); err != nil {
+			log.Printf("[Backup] Failed to release backup lock: %v", err)
+		}
+	}()
+
+	// Another instance may have finished it while we waited for the lock
+	if due, err := s.due(ctx, time.Now().UTC()); err != nil || !due {
+		return
+	}
+
+	run, err := s.Run(ctx)
+	if err != nil {
+		log.Printf("[Backup] Backup failed: %v", err)
+		return
+	}
+	if run.Error != nil {
+		log.Printf("[Backup] Backup %s failed: %s", run.Prefix, *run.Error)
+		return
+	}
+	log.Printf("[Backup] Backed up %d rows of %d tables and %d playlists to %s", run.Rows, run.Tables, run.Playlists, run.Prefix)
+}
+
+// due reports whether no backup has started since the latest backup hour
+func (s *Service) due(ctx context.Context, now time.Time) (bool, error) {
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), s.hour, 0, 0, 0, time.UTC)
+	if now.Before(scheduled) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+
+	runs, err := s.queries.ListBackupRuns(ctx, 1)
+	if err != nil {
+		return false, err
+	}
+	return len(runs) == 0 || runs[0].StartedAt.Before(scheduled), nil
+}
@@ -0,0 +1,314 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxExamples caps the values or keys a report lists per problem
+const maxExamples = 5
+
+// Backup is a backup read back from the bucket and checked against its
+// manifest
+type Backup struct {
+	Manifest  Manifest
+	Rows      map[string][]json.RawMessage
+	Playlists []Playlist
+	Inventory []string
+}
+
+// Report is the outcome of checking a backup against the database and
+// bucket it would be restored into
+type Report struct {
+	BackupID string        `json:"backup_id"`
+	Tables   []TableReport `json:"tables"`
+	// Violations are references between backed-up rows that don't resolve
+	Violations []Violation `json:"violations"`
+	// MissingPlaylists are gone from the bucket and restored from the
+	// backup
+	MissingPlaylists []string `json:"missing_playlists"`
+	// MissingObjects counts other inventoried objects gone from the
+	// bucket, which a restore cannot bring back
+	MissingObjects        int      `json:"missing_objects"`
+	MissingObjectExamples []string `json:"missing_object_examples"`
+}
+
+// TableReport compares a backed-up table with the target database
+type TableReport struct {
+	Table    string `json:"table"`
+	Rows     int    `json:"rows"`
+	Existing int64  `json:"existing"` // rows already in the target
+}
+
+// Violation is a foreign key some backed-up rows break
+type Violation struct {
+	Table     string   `json:"table"`
+	Column    string   `json:"column"`
+	RefTable  string   `json:"ref_table"`
+	RefColumn string   `json:"ref_column"`
+	Missing   int      `json:"missing"`
+	Examples  []string `json:"examples"`
+}
+
+// Restorable reports whether the backup can be restored: its references
+// resolve and every table it restores is empty in the target
+func (r *Report) Restorable() error {
+	if len(r.Violations) > 0 {
+		return fmt.Errorf("backup breaks %d foreign keys", len(r.Violations))
+	}
+	for _, t := range r.Tables {
+		if t.Existing > 0 {
+			return fmt.Errorf("table %s already has %d rows; restore into a freshly migrated database", t.Table, t.Existing)
+		}
+	}
+	return nil
+}
+
+// List returns the manifests of the complete backups in the bucket,
+// newest first
+func (s *Service) List(ctx context.Context) ([]Manifest, error) {
+	if !s.Enabled() {
+		return nil, ErrDisabled
+	}
+	ids, err := s.ids(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []Manifest{}
+	for _, id := range ids {
+		data, err := s.bucket.DownloadFile(ctx, Prefix+id+"/"+manifestName)
+		if err != nil {
+			continue // incomplete or being written
+		}
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("backup %s has an invalid manifest: %w", id, err)
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+// Load reads a backup, checking each object's checksum and count against
+// the manifest
+func (s *Service) Load(ctx context.Context, id string) (*Backup, error) {
+	if !s.Enabled() {
+		return nil, ErrDisabled
+	}
+	data, err := s.bucket.DownloadFile(ctx, Prefix+id+"/"+manifestName)
+	if err != nil {
+		return nil, fmt.Errorf("backup %s not found or incomplete: %w", id, err)
+	}
+	b := &Backup{Rows: map[string][]json.RawMessage{}}
+	if err := json.Unmarshal(data, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if b.Manifest.Version != manifestVersion || b.Manifest.Encryption != Encryption {
+		return nil, fmt.Errorf("unsupported backup version %d (%s)", b.Manifest.Version, b.Manifest.Encryption)
+	}
+
+	for _, t := range b.Manifest.Tables {
+		data, err := s.get(ctx, t.File)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", t.Table, err)
+		}
+		rows := []json.RawMessage{}
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if len(line) > 0 {
+				rows = append(rows, json.RawMessage(line))
+			}
+		}
+		if int64(len(rows)) != t.Count {
+			return nil, fmt.Errorf("table %s has %d rows, manifest says %d", t.Table, len(rows), t.Count)
+		}
+		b.Rows[t.Table] = rows
+	}
+
+	data, err = s.get(ctx, b.Manifest.Playlists)
+	if err != nil {
+		return nil, fmt.Errorf("playlists: %w", err)
+	}
+	if err := json.Unmarshal(data, &b.Playlists); err != nil {
+		return nil, fmt.Errorf("playlists: %w", err)
+	}
+
+	data, err = s.get(ctx, b.Manifest.Inventory)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: %w", err)
+	}
+	if len(data) > 0 {
+		b.Inventory = strings.Split(string(data), "\n")
+	}
+	return b, nil
+}
+
+// get downloads a sealed object, checks its checksum and opens it
+func (s *Service) get(ctx context.Context, file File) ([]byte, error) {
+	sealed, err := s.bucket.DownloadFile(ctx, file.Object)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(sealed)
+	if hex.EncodeToString(sum[:]) != file.SHA256 {
+		return nil, errors.New("checksum mismatch")
+	}
+	return open(s.key, sealed)
+}
+
+// Verify checks a backup against the target: that every reference between
+// its rows resolves under the target schema's foreign keys, which tables
+// already hold rows, and which inventoried objects are gone from the
+// bucket
+func (s *Service) Verify(ctx context.Context, b *Backup) (*Report, error) {
+	report := &Report{
+		BackupID:              b.Manifest.ID,
+		Tables:                []TableReport{},
+		Violations:            []Violation{},
+		MissingPlaylists:      []string{},
+		MissingObjectExamples: []string{},
+	}
+
+	for _, t := range b.Manifest.Tables {
+		existing, err := s.queries.CountTableRows(ctx, t.Table)
+		if err != nil {
+			return nil, fmt.Errorf("count %s: %w", t.Table, err)
+		}
+		report.Tables = append(report.Tables, TableReport{Table: t.Table, Rows: len(b.Rows[t.Table]), Existing: existing})
+	}
+
+	violations, err := s.checkReferences(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	report.Violations = violations
+
+	present := map[string]bool{}
+	for _, prefix := range InventoryPrefixes {
+		keys, err := s.source.ListKeys(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", prefix, err)
+		}
+		for _, key := range keys {
+			present[key] = true
+		}
+	}
+	backedUp := map[string]bool{}
+	for _, p := range b.Playlists {
+		backedUp[p.Key] = true
+	}
+	for _, key := range b.Inventory {
+		switch {
+		case present[key]:
+		case backedUp[key]:
+			report.MissingPlaylists = append(report.MissingPlaylists, key)
+		default:
+			report.MissingObjects++
+			if len(report.MissingObjectExamples) < maxExamples {
+				report.MissingObjectExamples = append(report.MissingObjectExamples, key)
+			}
+		}
+	}
+	return report, nil
+}
+
+// checkReferences finds backed-up rows whose foreign key values are not
+// among the backed-up rows of the table they reference
+func (s *Service) checkReferences(ctx context.Context, b *Backup) ([]Violation, error) {
+	keys, err := s.queries.ListForeignKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list foreign keys: %w", err)
+	}
+
+	decoded := map[string][]map[string]json.RawMessage{}
+	rowsOf := func(table string) ([]map[string]json.RawMessage, error) {
+		if rows, ok := decoded[table]; ok {
+			return rows, nil
+		}
+		rows := make([]map[string]json.RawMessage, 0, len(b.Rows[table]))
+		for _, raw := range b.Rows[table] {
+			var row map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &row); err != nil {
+				return nil, fmt.Errorf("table %s has an invalid row: %w", table, err)
+			}
+			rows = append(rows, row)
+		}
+		decoded[table] = rows
+		return rows, nil
+	}
+
+	violations := []Violation{}
+	for _, fk := range keys {
+		if _, ok := b.Rows[fk.Table]; !ok {
+			continue
+		}
+		rows, err := rowsOf(fk.Table)
+		if err != nil {
+			return nil, err
+		}
+		refRows, err := rowsOf(fk.RefTable)
+		if err != nil {
+			return nil, err
+		}
+		refs := make(map[string]bool, len(refRows))
+		for _, row := range refRows {
+			refs[string(row[fk.RefColumn])] = true
+		}
+
+		v := Violation{Table: fk.Table, Column: fk.Column, RefTable: fk.RefTable, RefColumn: fk.RefColumn, Examples: []string{}}
+		for _, row := range rows {
+			value := row[fk.Column]
+			if len(value) == 0 || string(value) == "null" || refs[string(value)] {
+				continue
+			}
+			v.Missing++
+			if len(v.Examples) < maxExamples {
+				v.Examples = append(v.Examples, strings.Trim(string(value), `"`))
+			}
+		}
+		if v.Missing > 0 {
+			violations = append(violations, v)
+		}
+	}
+	return violations, nil
+}
+
+// Restore writes a verified backup into the target: its tables in one
+// transaction, then the playlists missing from the bucket unless
+// skipPlaylists is set. It refuses unless the report is restorable.
+func (s *Service) Restore(ctx context.Context, b *Backup, report *Report, skipPlaylists bool) error {
+	if err := report.Restorable(); err != nil {
+		return err
+	}
+
+	tables := make([]string, 0, len(b.Manifest.Tables))
+	for _, t := range b.Manifest.Tables {
+		tables = append(tables, t.Table)
+	}
+	if err := s.queries.RestoreTables(ctx, tables, b.Rows); err != nil {
+		return err
+	}
+
+	if skipPlaylists {
+		return nil
+	}
+	missing := map[string]bool{}
+	for _, key := range report.MissingPlaylists {
+		missing[key] = true
+	}
+	for _, p := range b.Playlists {
+		if !missing[p.Key] {
+			continue
+		}
+		if err := s.source.UploadFile(ctx, p.Key, strings.NewReader(p.Body), "application/x-mpegURL"); err != nil {
+			return fmt.Errorf("restore playlist %s: %w", p.Key, err)
+		}
+	}
+	return nil
+}
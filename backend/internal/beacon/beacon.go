@@ -0,0 +1,104 @@
+package beacon
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenTTL is how long a playback session may send events with its token
+const TokenTTL = 12 * time.Hour
+
+// ErrInvalidToken is returned for malformed, forged or expired tokens
+var ErrInvalidToken = errors.New("invalid beacon token")
+
+// Claims is what a beacon token binds analytics events to
+type Claims struct {
+	FilmID    uuid.UUID
+	UserID    *uuid.UUID // nil for anonymous playback
+	SessionID string
+	ExpiresAt time.Time
+}
+
+// Signer issues and verifies HMAC-signed beacon tokens, one per playback
+// session, so analytics events can only be sent for playbacks the API
+// actually started
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a signer with the given HMAC secret
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: []byte(secret)}
+}
+
+// Issue starts a playback session for a film and returns its token and
+// session id
+func (s *Signer) Issue(filmID uuid.UUID, userID *uuid.UUID, now time.Time) (token string, sessionID string) {
+	sessionID = uuid.New().String()
+	user := ""
+	if userID != nil {
+		user = userID.String()
+	}
+
+	payload := strings.Join([]string{
+		filmID.String(),
+		user,
+		sessionID,
+		strconv.FormatInt(now.Add(TokenTTL).Unix(), 10),
+	}, "|")
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + s.sign(encoded), sessionID
+}
+
+// Verify checks a token's signature and expiry and returns its claims
+func (s *Signer) Verify(token string, now time.Time) (*Claims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	parts := strings.Split(string(payload), "|")
+	if len(parts) != 4 {
+		return nil, ErrInvalidToken
+	}
+
+	filmID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	claims := &Claims{FilmID: filmID, SessionID: parts[2]}
+	if parts[1] != "" {
+		userID, err := uuid.Parse(parts[1])
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+		claims.UserID = &userID
+	}
+	exp, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	claims.ExpiresAt = time.Unix(exp, 0)
+
+	if now.After(claims.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (s *Signer) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
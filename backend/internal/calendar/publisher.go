@@ -0,0 +1,133 @@
+package calendar
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/approval"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/events"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/search"
+	"github.com/google/uuid"
+)
+
+const (
+	publishLock = "calendar-publish"
+
+	// publishBatchSize bounds how many due releases one pass publishes
+	publishBatchSize = 50
+)
+
+// Publisher runs the scheduled publishes on the content calendar
+type Publisher struct {
+	queries   *db.Queries
+	approvals *approval.Workflow
+	indexer   *search.Indexer
+	redis     *redis.Client
+	token     string
+}
+
+// NewPublisher creates a scheduled publish runner
+func NewPublisher(queries *db.Queries, approvals *approval.Workflow, indexer *search.Indexer, redisClient *redis.Client) *Publisher {
+	return &Publisher{
+		queries:   queries,
+		approvals: approvals,
+		indexer:   indexer,
+		redis:     redisClient,
+		token:     uuid.New().String(),
+	}
+}
+
+// PublishDue publishes every film whose scheduled release has come. A
+// release whose film cannot be published records why and is skipped until
+// rescheduled.
+func (p *Publisher) PublishDue(ctx context.Context) (int, error) {
+	due, err := p.queries.ListDueReleases(ctx, time.Now(), publishBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for i := range due {
+		release := &due[i]
+		if reason, err := p.blocker(ctx, release); err != nil {
+			return published, err
+		} else if reason != "" {
+			log.Printf("[Calendar] Scheduled publish %s skipped: %s", release.ID, reason)
+			if err := p.queries.FailScheduledRelease(ctx, release.ID, reason); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := p.queries.PublishScheduledRelease(ctx, release); err != nil {
+			return published, err
+		}
+		p.indexer.SyncFilmAsync(release.FilmID)
+		events.Emit(ctx, p.redis, models.FilmEventPublished, release.FilmID)
+		published++
+	}
+	return published, nil
+}
+
+// blocker returns why a release's film cannot be published now, or ""
+func (p *Publisher) blocker(ctx context.Context, release *models.ReleaseEvent) (string, error) {
+	film, err := p.queries.GetFilmByID(ctx, release.FilmID)
+	if err != nil {
+		return "", err
+	}
+	if film.Status != models.StatusReady {
+		return "film must be in READY status to publish", nil
+	}
+	allowed, err := p.approvals.CanPublish(ctx, film)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		return "film must be approved by its organization before publishing", nil
+	}
+	return "", nil
+}
+
+// RunLoop publishes due releases on every interval. A Redis lock keeps a
+// single instance publishing at a time. It blocks until ctx is cancelled.
+func (p *Publisher) RunLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.publish(ctx, interval)
+		}
+	}
+}
+
+func (p *Publisher) publish(ctx context.Context, interval time.Duration) {
+	ok, err := p.redis.AcquireLock(ctx, publishLock, p.token, interval)
+	if err != nil {
+		log.Printf("[Calendar] Failed to acquire publish lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := p.redis.ReleaseLock(context.Background(), publishLock, p.token); err != nil {
+			log.Printf("[Calendar] Failed to release publish lock: %v", err)
+		}
+	}()
+
+	n, err := p.PublishDue(ctx)
+	if err != nil {
+		log.Printf("[Calendar] Failed to publish scheduled releases: %v", err)
+	}
+	if n > 0 {
+		log.Printf("[Calendar] Published %d scheduled releases", n)
+	}
+}
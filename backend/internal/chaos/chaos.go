@@ -0,0 +1,201 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Faults that can be injected
+const (
+	FaultR2Timeout     = "r2_timeout"
+	FaultRedisDrop     = "redis_drop"
+	FaultFFmpegFailure = "ffmpeg_failure"
+)
+
+// ErrInjected marks every failure produced by fault injection, so logs and
+// retry paths can tell them apart from real outages
+var ErrInjected = errors.New("chaos: injected fault")
+
+// bypassKey marks contexts whose Redis calls are never faulted, so the
+// injector can always read its own configuration
+type bypassKey struct{}
+
+// Injector fails a configurable share of R2, Redis and FFmpeg calls for
+// resilience testing. It does nothing unless enabled at startup; the rates
+// live in Redis so every API and worker instance applies the same ones.
+type Injector struct {
+	redis   *redis.Client
+	enabled bool
+
+	mu     sync.RWMutex
+	faults *models.ChaosFaults
+}
+
+// New creates a fault injector; when enabled is false it never injects and
+// its rates cannot be set
+func New(redisClient *redis.Client, enabled bool) *Injector {
+	return &Injector{redis: redisClient, enabled: enabled}
+}
+
+// Enabled reports whether fault injection was enabled at startup
+func (i *Injector) Enabled() bool {
+	return i.enabled
+}
+
+// Faults returns the rates in effect, or nil when nothing is injected
+func (i *Injector) Faults() *models.ChaosFaults {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if i.faults == nil || !i.faults.ActiveAt(time.Now()) {
+		return nil
+	}
+	faults := *i.faults
+	return &faults
+}
+
+// Set starts injecting faults at the given rates on every instance
+func (i *Injector) Set(ctx context.Context, faults *models.ChaosFaults) error {
+	if !i.enabled {
+		return errors.New("fault injection is disabled")
+	}
+	if err := i.redis.SetChaosFaults(bypass(ctx), faults); err != nil {
+		return err
+	}
+	i.store(faults)
+	return nil
+}
+
+// Clear stops injecting faults on every instance
+func (i *Injector) Clear(ctx context.Context) error {
+	if err := i.redis.ClearChaosFaults(bypass(ctx)); err != nil {
+		return err
+	}
+	i.store(nil)
+	return nil
+}
+
+// Fail returns an error wrapping ErrInjected for the configured share of
+// calls to fault, and nil otherwise
+func (i *Injector) Fail(fault string) error {
+	if !i.enabled {
+		return nil
+	}
+	faults := i.Faults()
+	if faults == nil {
+		return nil
+	}
+
+	var rate float64
+	var cause error
+	switch fault {
+	case FaultR2Timeout:
+		rate, cause = faults.R2TimeoutRate, context.DeadlineExceeded
+	case FaultRedisDrop:
+		rate, cause = faults.RedisDropRate, io.ErrUnexpectedEOF
+	case FaultFFmpegFailure:
+		rate, cause = faults.FFmpegFailureRate, errors.New("exit status 1")
+	}
+	if rate <= 0 || rand.Float64() >= rate {
+		return nil
+	}
+	return fmt.Errorf("%w (%s): %w", ErrInjected, fault, cause)
+}
+
+// R2Fault fails R2 operations as timeouts; pass it to r2.Client.InjectFaults
+func (i *Injector) R2Fault(ctx context.Context, operation string) error {
+	if err := i.Fail(FaultR2Timeout); err != nil {
+		return fmt.Errorf("R2 %s: %w", operation, err)
+	}
+	return nil
+}
+
+// RedisHook returns a go-redis hook that fails commands as dropped
+// connections
+func (i *Injector) RedisHook() goredis.Hook {
+	return redisHook{i}
+}
+
+// RunSync reloads the rates from Redis on every interval so changes made
+// through any instance apply everywhere. It blocks until ctx is cancelled.
+func (i *Injector) RunSync(ctx context.Context, interval time.Duration) {
+	if !i.enabled {
+		return
+	}
+	log.Println("[Chaos] Fault injection is enabled")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		i.sync(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (i *Injector) sync(ctx context.Context) {
+	faults, err := i.redis.GetChaosFaults(bypass(ctx))
+	if err != nil {
+		log.Printf("[Chaos] Failed to load fault rates: %v", err)
+		return
+	}
+	i.store(faults)
+}
+
+func (i *Injector) store(faults *models.ChaosFaults) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults = faults
+}
+
+func bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// redisHook injects FaultRedisDrop into commands and pipelines
+type redisHook struct {
+	i *Injector
+}
+
+func (h redisHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return next
+}
+
+func (h redisHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		if ctx.Value(bypassKey{}) == nil {
+			if err := h.i.Fail(FaultRedisDrop); err != nil {
+				cmd.SetErr(err)
+				return err
+			}
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h redisHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		if ctx.Value(bypassKey{}) == nil {
+			if err := h.i.Fail(FaultRedisDrop); err != nil {
+				for _, cmd := range cmds {
+					cmd.SetErr(err)
+				}
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
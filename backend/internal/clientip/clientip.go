@@ -0,0 +1,175 @@
+// Package clientip resolves the address of the client behind any trusted
+// reverse proxies or CDN, so rate limiting, geo checks and analytics all
+// see the same IP.
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Header names understood by the resolver
+const (
+	HeaderCloudflare   = "CF-Connecting-IP"
+	HeaderForwardedFor = "X-Forwarded-For"
+	HeaderRealIP       = "X-Real-IP"
+)
+
+// Resolver finds a request's client IP. Forwarding headers are only read
+// when the connection comes from a trusted proxy, so clients cannot spoof
+// their address by sending them.
+type Resolver struct {
+	trusted []netip.Prefix
+	headers []string
+}
+
+// New creates a resolver. trusted lists the proxies' addresses or CIDR
+// ranges; headers lists the forwarding headers to read, first match wins.
+func New(trusted, headers []string) (*Resolver, error) {
+	r := &Resolver{}
+	for _, entry := range trusted {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, err := parsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		r.trusted = append(r.trusted, prefix)
+	}
+	for _, header := range headers {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		if header == "" {
+			continue
+		}
+		switch header {
+		case http.CanonicalHeaderKey(HeaderCloudflare), http.CanonicalHeaderKey(HeaderForwardedFor), http.CanonicalHeaderKey(HeaderRealIP):
+		default:
+			return nil, fmt.Errorf("unsupported client IP header %q", header)
+		}
+		r.headers = append(r.headers, header)
+	}
+	return r, nil
+}
+
+func parsePrefix(entry string) (netip.Prefix, error) {
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if prefix.Addr().Is4In6() {
+			// ::ffff:10.0.0.0/104 is 10.0.0.0/8
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = Normalize(addr)
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Resolve returns the client IP of a request, or the zero Addr when even
+// the connection's address does not parse
+func (r *Resolver) Resolve(req *http.Request) netip.Addr {
+	remote := ParseAddr(req.RemoteAddr)
+	if !remote.IsValid() || !r.isTrusted(remote) {
+		return remote
+	}
+
+	for _, header := range r.headers {
+		values := req.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+		if header == http.CanonicalHeaderKey(HeaderForwardedFor) {
+			if addr := r.fromForwardedFor(values); addr.IsValid() {
+				return addr
+			}
+			continue
+		}
+		if addr := ParseAddr(values[0]); addr.IsValid() {
+			return addr
+		}
+	}
+	return remote
+}
+
+// fromForwardedFor walks X-Forwarded-For from the nearest hop back and
+// returns the first address that is not a trusted proxy. Hops before it
+// were added by the client and cannot be believed.
+func (r *Resolver) fromForwardedFor(values []string) netip.Addr {
+	var hops []string
+	for _, v := range values {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	var last netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr := ParseAddr(hops[i])
+		if !addr.IsValid() {
+			// A hop we cannot read; the ones before it are unverifiable
+			return last
+		}
+		if !r.isTrusted(addr) {
+			return addr
+		}
+		last = addr
+	}
+	// Every hop is a trusted proxy; the first is the closest to a client
+	return last
+}
+
+// FromTrustedProxy reports whether a request's connection comes from a
+// trusted proxy, whose other headers, e.g. a CDN's country, can be believed
+func (r *Resolver) FromTrustedProxy(req *http.Request) bool {
+	remote := ParseAddr(req.RemoteAddr)
+	return remote.IsValid() && r.isTrusted(remote)
+}
+
+func (r *Resolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAddr reads an IP address as found in RemoteAddr or a forwarding
+// header: with or without a port, brackets or IPv6 zone. It returns the
+// zero Addr when s is not an address.
+func ParseAddr(s string) netip.Addr {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return Normalize(addr)
+}
+
+// Normalize returns the canonical form of an address: IPv4-mapped IPv6
+// addresses become IPv4 and IPv6 zones are dropped, so one client always
+// has one key
+func Normalize(addr netip.Addr) netip.Addr {
+	return addr.Unmap().WithZone("")
+}
+
+// String returns addr as text, or "" for the zero Addr
+func String(addr netip.Addr) string {
+	if !addr.IsValid() {
+		return ""
+	}
+	return addr.String()
+}
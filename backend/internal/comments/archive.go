@@ -0,0 +1,143 @@
+package comments
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+const (
+	// MaxArchiveComments bounds how many comments one import may carry
+	MaxArchiveComments = 50000
+
+	// MaxReportErrors bounds how many rejected comments a report lists
+	MaxReportErrors = 100
+
+	maxExternalIDLength = 255
+	maxAuthorLength     = 100
+	maxBodyLength       = 10000
+
+	// clockSkew tolerates source timestamps slightly in the future
+	clockSkew = 5 * time.Minute
+)
+
+// Validate checks an archive and returns the comments to import, parents
+// before replies, with a report of what was rejected or skipped. existing
+// maps the archive ids already stored for the film to their top-level
+// comment's id ("" for a top-level comment); those are skipped.
+func Validate(archive *models.CommentArchive, existing map[string]string, now time.Time) ([]models.CommentImport, *models.CommentImportReport) {
+	report := &models.CommentImportReport{Total: len(archive.Comments), Errors: []models.CommentImportError{}}
+	reject := func(i int, id, format string, args ...interface{}) {
+		report.Invalid++
+		if len(report.Errors) < MaxReportErrors {
+			report.Errors = append(report.Errors, models.CommentImportError{Index: i, ID: id, Error: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	// First pass: check each comment on its own
+	valid := make(map[string]*models.CommentImport, len(archive.Comments))
+	parents := make(map[string]string, len(archive.Comments))
+	indexes := make(map[string]int, len(archive.Comments))
+	order := make([]string, 0, len(archive.Comments))
+	for i, c := range archive.Comments {
+		id := strings.TrimSpace(c.ID)
+		switch {
+		case id == "":
+			reject(i, "", "id is required")
+			continue
+		case len(id) > maxExternalIDLength:
+			reject(i, "", "id is longer than %d characters", maxExternalIDLength)
+			continue
+		}
+		if _, dup := indexes[id]; dup {
+			reject(i, id, "duplicate id")
+			continue
+		}
+		indexes[id] = i
+
+		author := strings.TrimSpace(c.Author)
+		body := strings.TrimSpace(c.Body)
+		createdAt, err := time.Parse(time.RFC3339, c.CreatedAt)
+		switch {
+		case author == "":
+			reject(i, id, "author is required")
+			continue
+		case utf8.RuneCountInString(author) > maxAuthorLength:
+			reject(i, id, "author is longer than %d characters", maxAuthorLength)
+			continue
+		case body == "":
+			reject(i, id, "body is empty")
+			continue
+		case utf8.RuneCountInString(body) > maxBodyLength:
+			reject(i, id, "body is longer than %d characters", maxBodyLength)
+			continue
+		case err != nil:
+			reject(i, id, "created_at must be an RFC3339 timestamp")
+			continue
+		case createdAt.After(now.Add(clockSkew)):
+			reject(i, id, "created_at is in the future")
+			continue
+		}
+
+		valid[id] = &models.CommentImport{
+			ExternalID: id,
+			Author:     author,
+			Body:       body,
+			CreatedAt:  createdAt,
+		}
+		parents[id] = strings.TrimSpace(c.ParentID)
+		order = append(order, id)
+	}
+
+	// Second pass: resolve each reply to its thread's top-level comment
+	var roots, replies []models.CommentImport
+	for _, id := range order {
+		if _, ok := existing[id]; ok {
+			report.Skipped++
+			continue
+		}
+		root, err := resolveRoot(id, parents, valid, existing)
+		if err != nil {
+			reject(indexes[id], id, "%v", err)
+			continue
+		}
+
+		c := valid[id]
+		c.RootID = root
+		if root == "" {
+			roots = append(roots, *c)
+		} else {
+			replies = append(replies, *c)
+		}
+	}
+
+	return append(roots, replies...), report
+}
+
+// resolveRoot follows a comment's parents up to its top-level comment and
+// returns that comment's id, or "" when the comment is top-level itself
+func resolveRoot(id string, parents map[string]string, valid map[string]*models.CommentImport, existing map[string]string) (string, error) {
+	root := ""
+	seen := map[string]bool{id: true}
+	for parent := parents[id]; parent != ""; parent = parents[parent] {
+		if seen[parent] {
+			return "", fmt.Errorf("parent_id %q forms a cycle", parent)
+		}
+		seen[parent] = true
+
+		if existingRoot, ok := existing[parent]; ok {
+			if existingRoot != "" {
+				return existingRoot, nil
+			}
+			return parent, nil
+		}
+		if _, ok := valid[parent]; !ok {
+			return "", fmt.Errorf("parent_id %q is not a valid comment in this archive or on the film", parent)
+		}
+		root = parent
+	}
+	return root, nil
+}
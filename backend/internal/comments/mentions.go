@@ -0,0 +1,123 @@
+package comments
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// MaxMentions bounds how many users one comment can mention
+const MaxMentions = 10
+
+var (
+	handlePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,30}$`)
+
+	// A mention starts the body or follows a character that cannot be part
+	// of a handle or an email address, so "a@b.com" mentions nobody
+	mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_@.])@([A-Za-z0-9_]{3,30})\b`)
+)
+
+// ValidHandle reports whether handle can be used as an @handle: 3 to 30
+// letters, digits or underscores
+func ValidHandle(handle string) bool {
+	return handlePattern.MatchString(handle)
+}
+
+// ParseMentions returns the distinct handles mentioned in a comment body,
+// lowercased, in order of appearance and at most MaxMentions
+func ParseMentions(body string) []string {
+	handles := []string{}
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(match[1])
+		if seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		handles = append(handles, handle)
+		if len(handles) == MaxMentions {
+			break
+		}
+	}
+	return handles
+}
+
+// Mentions stores the users a comment mentions and notifies them once the
+// comment is visible
+type Mentions struct {
+	queries *db.Queries
+}
+
+// NewMentions creates a mention tracker
+func NewMentions(queries *db.Queries) *Mentions {
+	return &Mentions{queries: queries}
+}
+
+// Update records the users a comment mentions, replacing those it
+// mentioned before an edit, and notifies them if the comment is visible.
+// Unknown handles, ghost users and the author are left out, and nobody is
+// notified twice for one comment. Failures are logged since the comment
+// itself has already been saved.
+func (m *Mentions) Update(ctx context.Context, comment *models.Comment) {
+	users, err := m.queries.GetUsersByHandles(ctx, ParseMentions(comment.Body))
+	if err != nil {
+		log.Printf("[Comments] Failed to resolve mentions in comment %s: %v", comment.ID, err)
+		return
+	}
+
+	mentioned := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		if user.ID != comment.UserID && user.GhostOwnerID == nil {
+			mentioned = append(mentioned, user.ID)
+		}
+	}
+	if err := m.queries.SetCommentMentions(ctx, comment.ID, mentioned); err != nil {
+		log.Printf("[Comments] Failed to save mentions in comment %s: %v", comment.ID, err)
+		return
+	}
+
+	m.Notify(ctx, comment)
+}
+
+// Notify notifies the users a comment mentions who have not been notified
+// yet, once the comment is visible (e.g. after a held comment is approved)
+func (m *Mentions) Notify(ctx context.Context, comment *models.Comment) {
+	if comment.Status != models.CommentVisible {
+		return
+	}
+	if err := m.notify(ctx, comment); err != nil {
+		log.Printf("[Comments] Failed to notify mentions in comment %s: %v", comment.ID, err)
+	}
+}
+
+func (m *Mentions) notify(ctx context.Context, comment *models.Comment) error {
+	userIDs, err := m.queries.ClaimCommentMentions(ctx, comment.ID)
+	if err != nil || len(userIDs) == 0 {
+		return err
+	}
+	film, err := m.queries.GetFilmByID(ctx, comment.FilmID)
+	if err != nil {
+		return err
+	}
+	author, err := m.queries.GetUserByID(ctx, comment.UserID)
+	if err != nil {
+		return err
+	}
+
+	notifications := make([]models.Notification, len(userIDs))
+	for i, userID := range userIDs {
+		notifications[i] = models.Notification{
+			UserID:  userID,
+			Kind:    models.NotifyCommentMention,
+			FilmID:  &film.ID,
+			Message: fmt.Sprintf("%s mentioned you in a comment on %q", author.Name, film.Title),
+		}
+	}
+	return m.queries.CreateNotifications(ctx, notifications)
+}
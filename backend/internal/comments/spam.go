@@ -0,0 +1,68 @@
+package comments
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// linkPattern matches URLs, www. hosts and bare domains on common TLDs,
+// which is how spam links usually dodge URL detection
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+|\b[a-z0-9][a-z0-9-]*(?:\.[a-z0-9-]+)*\.(?:com|net|org|info|biz|io|co|ru|cn|xyz|top|site|online|click|link|shop|store|live|app)\b(?:/[^\s<>"]*)?`)
+
+// CountLinks returns how many links a comment body contains
+func CountLinks(body string) int {
+	return len(linkPattern.FindAllStringIndex(body, -1))
+}
+
+// CheckSpam returns why a comment body looks like spam to a film's spam
+// filter and the platform's keywords, or "" when it does not or the filter
+// is off. Keywords match whole words or phrases, ignoring case.
+func CheckSpam(body string, filter models.SpamFilter, platformKeywords []string) string {
+	if !filter.Enabled {
+		return ""
+	}
+	if links := CountLinks(body); links > filter.MaxLinks {
+		return fmt.Sprintf("%d links (at most %d allowed)", links, filter.MaxLinks)
+	}
+
+	lower := strings.ToLower(body)
+	for _, keywords := range [][]string{filter.Keywords, platformKeywords} {
+		for _, keyword := range keywords {
+			if containsWord(lower, strings.ToLower(strings.TrimSpace(keyword))) {
+				return fmt.Sprintf("keyword %q", keyword)
+			}
+		}
+	}
+	return ""
+}
+
+// containsWord reports whether keyword appears in s not as part of a
+// longer word
+func containsWord(s, keyword string) bool {
+	if keyword == "" {
+		return false
+	}
+	for from := 0; ; {
+		i := strings.Index(s[from:], keyword)
+		if i < 0 {
+			return false
+		}
+		start, end := from+i, from+i+len(keyword)
+		if !wordByte(s, start-1) && !wordByte(s, end) {
+			return true
+		}
+		from = start + 1
+	}
+}
+
+// wordByte reports whether s has a letter or digit at i
+func wordByte(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	b := s[i]
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '_' || b >= 0x80
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// FilmFilter holds optional catalog filters; nil/empty fields are ignored
+type FilmFilter struct {
+	Status          models.FilmStatus
+	CreatorID       *uuid.UUID
+	Type            models.FilmType
+	Genre           string
+	MinDuration     *int // seconds
+	MaxDuration     *int // seconds
+	PublishedAfter  *time.Time
+	PublishedBefore *time.Time
+	MinRating       *float64
+	// Region restricts to films available in a viewer country; "" is an
+	// unknown country, nil disables region checks
+	Region *string
+	// Listed restricts to films whose policy lists them publicly
+	Listed bool
+	// ExcludeWatchedBy hides films this user has finished watching
+	ExcludeWatchedBy *uuid.UUID
+}
+
+// IsZero reports whether no filter is set
+func (f FilmFilter) IsZero() bool {
+	return f == FilmFilter{}
+}
+
+// AdminListFilter narrows an admin listing; nil and empty fields are
+// ignored. What the actor and action are depends on the listing.
+type AdminListFilter struct {
+	ActorID *uuid.UUID
+	From    *time.Time
+	To      *time.Time
+	Action  string
+	// Target is the type of target, for listings covering several
+	Target string
+}
+
+// AdminCursor is a keyset position in an admin listing, which runs newest
+// first by the listing's time
+type AdminCursor struct {
+	At time.Time `json:"t"`
+	ID uuid.UUID `json:"i"`
+}
+
+// applyActorAndRange adds the actor and date range conditions of a filter;
+// actorCol is empty for listings without an actor
+func (f AdminListFilter) applyActorAndRange(w *whereBuilder, actorCol, atCol string) {
+	if f.ActorID != nil && actorCol != "" {
+		w.add(actorCol+" = ?", *f.ActorID)
+	}
+	if f.From != nil {
+		w.add(atCol+" >= ?", *f.From)
+	}
+	if f.To != nil {
+		w.add(atCol+" < ?", *f.To)
+	}
+}
+
+// adminOrder adds the keyset condition for after and returns the ORDER BY
+// and paging of an admin listing. Call it before rendering w.
+func adminOrder(w *whereBuilder, atCol, idCol string, after *AdminCursor, offset, limit int) string {
+	if after != nil {
+		w.add("("+atCol+", "+idCol+") < (?, ?)", after.At, after.ID)
+	}
+	return fmt.Sprintf("ORDER BY %s DESC, %s DESC OFFSET %s LIMIT %s", atCol, idCol, w.arg(offset), w.arg(limit))
+}
+
+// whereBuilder composes a WHERE clause with numbered placeholders
+type whereBuilder struct {
+	clauses []string
+	args    []interface{}
+}
+
+// add appends a condition; each "?" in cond is replaced by the next placeholder
+func (w *whereBuilder) add(cond string, args ...interface{}) {
+	for _, arg := range args {
+		w.args = append(w.args, arg)
+		cond = strings.Replace(cond, "?", fmt.Sprintf("$%d", len(w.args)), 1)
+	}
+	w.clauses = append(w.clauses, cond)
+}
+
+// arg registers an extra argument (e.g. LIMIT) and returns its placeholder
+func (w *whereBuilder) arg(v interface{}) string {
+	w.args = append(w.args, v)
+	return fmt.Sprintf("$%d", len(w.args))
+}
+
+// sql renders the WHERE clause, or an empty string when there are no conditions
+func (w *whereBuilder) sql() string {
+	if len(w.clauses) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(w.clauses, " AND ")
+}
+
+// filmWhere builds the WHERE clause for a film filter against alias f
+func filmWhere(filter FilmFilter) *whereBuilder {
+	w := &whereBuilder{}
+	if filter.Status != "" {
+		w.add("f.status = ?", filter.Status)
+	}
+	if filter.CreatorID != nil {
+		w.add("f.created_by_id = ?", *filter.CreatorID)
+	}
+	if filter.Type != "" {
+		w.add("f.type = ?", filter.Type)
+	}
+	if filter.Genre != "" {
+		w.add("f.genre = ?", filter.Genre)
+	}
+	if filter.MinDuration != nil {
+		w.add("f.duration >= ?", *filter.MinDuration)
+	}
+	if filter.MaxDuration != nil {
+		w.add("f.duration <= ?", *filter.MaxDuration)
+	}
+	if filter.PublishedAfter != nil {
+		w.add("f.published_at >= ?", *filter.PublishedAfter)
+	}
+	if filter.PublishedBefore != nil {
+		w.add("f.published_at < ?", *filter.PublishedBefore)
+	}
+	if filter.MinRating != nil {
+		w.add("f.average_rating >= ?", *filter.MinRating)
+	}
+	if filter.Region != nil {
+		if country := *filter.Region; country == "" {
+			w.add("cardinality(f.allowed_regions) = 0")
+		} else {
+			w.add("(cardinality(f.allowed_regions) = 0 OR ? = ANY(f.allowed_regions))", country)
+			w.add("NOT (? = ANY(f.blocked_regions))", country)
+		}
+	}
+	if filter.Listed {
+		w.add("f.visibility = ?", models.VisibilityPublic)
+	}
+	if filter.ExcludeWatchedBy != nil {
+		w.add(`NOT EXISTS (
+			SELECT 1 FROM watch_history wh
+			WHERE wh.user_id = ? AND wh.film_id = f.id AND wh.completed_at IS NOT NULL
+		)`, *filter.ExcludeWatchedBy)
+	}
+	return w
+}
@@ -2,11 +2,13 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/arjunaayasa/filmtube/internal/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // Queries contains all database operations
@@ -19,17 +21,33 @@ func NewQueries(db *DB) *Queries {
 	return &Queries{db: db}
 }
 
+// WithTx runs fn in a transaction, committing it when fn succeeds and
+// rolling it back otherwise. It is for callers outside this package that
+// combine queries taking a *sqlx.Tx.
+func (q *Queries) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // ========== USER QUERIES ==========
 
 // CreateUser inserts a new user
 func (q *Queries) CreateUser(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, role, name, avatar_url, bio)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO users (id, email, password_hash, role, name, avatar_url, bio, handle)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 	_, err := q.db.ExecContext(ctx, query,
 		user.ID, user.Email, user.PasswordHash, user.Role,
-		user.Name, user.AvatarURL, user.Bio,
+		user.Name, user.AvatarURL, user.Bio, user.Handle,
 	)
 	return err
 }
@@ -56,18 +74,75 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (*models.Use
 	return &user, nil
 }
 
+// GetUserByHandle retrieves a user by handle, ignoring case
+func (q *Queries) GetUserByHandle(ctx context.Context, handle string) (*models.User, error) {
+	var user models.User
+	query := `SELECT * FROM users WHERE LOWER(handle) = LOWER($1)`
+	if err := q.db.GetContext(ctx, &user, query, handle); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUsersByHandles retrieves the users with the given lowercase handles
+func (q *Queries) GetUsersByHandles(ctx context.Context, handles []string) ([]models.User, error) {
+	users := []models.User{}
+	if len(handles) == 0 {
+		return users, nil
+	}
+	query := `SELECT * FROM users WHERE LOWER(handle) = ANY($1)`
+	err := q.db.SelectContext(ctx, &users, query, pq.StringArray(handles))
+	return users, err
+}
+
+// SetUserHandle sets or, with nil, clears a user's handle
+func (q *Queries) SetUserHandle(ctx context.Context, userID uuid.UUID, handle *string) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE users SET handle = $2 WHERE id = $1`, userID, handle)
+	return err
+}
+
+// SetUserBirthDate sets a user's birth date, used by age-gated films
+func (q *Queries) SetUserBirthDate(ctx context.Context, userID uuid.UUID, birthDate time.Time) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE users SET birth_date = $2 WHERE id = $1`, userID, birthDate)
+	return err
+}
+
+// SetUserVerified marks a creator verified or not and returns the IDs of
+// their films, which need reindexing; returns sql.ErrNoRows for an unknown
+// user
+func (q *Queries) SetUserVerified(ctx context.Context, userID uuid.UUID, verified bool) ([]uuid.UUID, error) {
+	result, err := q.db.ExecContext(ctx, `UPDATE users SET verified = $2 WHERE id = $1`, userID, verified)
+	if err != nil {
+		return nil, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	filmIDs := []uuid.UUID{}
+	err = q.db.SelectContext(ctx, &filmIDs, `SELECT id FROM films WHERE created_by_id = $1`, userID)
+	return filmIDs, err
+}
+
 // ========== FILM QUERIES ==========
 
 // CreateFilm inserts a new film
 func (q *Queries) CreateFilm(ctx context.Context, film *models.Film) error {
 	query := `
-		INSERT INTO films (id, title, description, duration, type, status, created_by_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO films (id, title, description, duration, type, status, created_by_id, tags, genre, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING *
 	`
+	if film.Tags == nil {
+		film.Tags = pq.StringArray{}
+	}
 	rows, err := q.db.QueryxContext(ctx, query,
 		film.ID, film.Title, film.Description, film.Duration,
-		film.Type, film.Status, film.CreatedByID,
+		film.Type, film.Status, film.CreatedByID, film.Tags, film.Genre, film.TenantID,
 	)
 	if err != nil {
 		return err
@@ -98,9 +173,68 @@ func (q *Queries) GetFilmByID(ctx context.Context, id uuid.UUID) (*models.Film,
 	return &film, nil
 }
 
-// ListFilms retrieves films with pagination
-func (q *Queries) ListFilms(ctx context.Context, limit int, offset int, status models.FilmStatus) ([]models.Film, error) {
+// FilmSortOrders maps ListFilms sort options to ORDER BY clauses.
+// Every clause ends with the primary key so pagination is stable.
+var FilmSortOrders = map[string]string{
+	"newest":   "f.published_at DESC NULLS LAST, f.created_at DESC, f.id DESC",
+	"oldest":   "f.published_at ASC NULLS LAST, f.created_at ASC, f.id ASC",
+	"views":    "f.view_count DESC, f.id DESC",
+	"duration": "f.duration DESC, f.id DESC",
+	"title":    "LOWER(f.title) ASC, f.id ASC",
+}
+
+// DefaultFilmSort is used when no sort option is given
+const DefaultFilmSort = "newest"
+
+// ListFilms retrieves films matching filter with pagination
+func (q *Queries) ListFilms(ctx context.Context, filter FilmFilter, sort string, limit int, offset int) ([]models.Film, error) {
+	orderBy, ok := FilmSortOrders[sort]
+	if !ok {
+		orderBy = FilmSortOrders[DefaultFilmSort]
+	}
+
+	where := filmWhere(filter)
+	query := `
+		SELECT f.*,
+		       COALESCE(jsonb_build_object(
+		           'id', u.id,
+		           'email', u.email,
+		           'name', u.name,
+		           'avatar_url', u.avatar_url
+		       )::json, '{}'::json) as created_by
+		FROM films f
+		LEFT JOIN users u ON f.created_by_id = u.id
+		` + where.sql() + `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + where.arg(limit) + ` OFFSET ` + where.arg(offset)
+
 	var films []models.Film
+	err := q.db.SelectContext(ctx, &films, query, where.args...)
+	return films, err
+}
+
+// FilmCursor is a keyset position in the newest-first film listing
+type FilmCursor struct {
+	PublishedAt *time.Time `json:"p,omitempty"`
+	CreatedAt   time.Time  `json:"c"`
+	ID          uuid.UUID  `json:"i"`
+}
+
+// ListFilmsAfter retrieves films newest-first using keyset pagination,
+// starting after the given cursor (nil for the first page). It returns up
+// to limit+1 films so callers can tell whether another page exists.
+func (q *Queries) ListFilmsAfter(ctx context.Context, filter FilmFilter, after *FilmCursor, limit int) ([]models.Film, error) {
+	where := filmWhere(filter)
+	if after != nil {
+		// Mirrors ORDER BY published_at DESC NULLS LAST, created_at DESC, id DESC
+		if after.PublishedAt != nil {
+			where.add("(f.published_at < ? OR (f.published_at = ? AND (f.created_at, f.id) < (?, ?)) OR f.published_at IS NULL)",
+				*after.PublishedAt, *after.PublishedAt, after.CreatedAt, after.ID)
+		} else {
+			where.add("(f.published_at IS NULL AND (f.created_at, f.id) < (?, ?))", after.CreatedAt, after.ID)
+		}
+	}
+
 	query := `
 		SELECT f.*,
 		       COALESCE(jsonb_build_object(
@@ -111,14 +245,235 @@ func (q *Queries) ListFilms(ctx context.Context, limit int, offset int, status m
 		       )::json, '{}'::json) as created_by
 		FROM films f
 		LEFT JOIN users u ON f.created_by_id = u.id
-		WHERE ($1 = '' OR status = $1)
-		ORDER BY published_at DESC NULLS LAST, created_at DESC
-		LIMIT $2 OFFSET $3
+		` + where.sql() + `
+		ORDER BY ` + FilmSortOrders["newest"] + `
+		LIMIT ` + where.arg(limit+1)
+
+	var films []models.Film
+	err := q.db.SelectContext(ctx, &films, query, where.args...)
+	return films, err
+}
+
+// EstimateFilmRows returns the planner's row estimate for the films table,
+// which is cheap to read and good enough to size samples
+func (q *Queries) EstimateFilmRows(ctx context.Context) (float64, error) {
+	var rows float64
+	err := q.db.GetContext(ctx, &rows, `SELECT GREATEST(reltuples, 0) FROM pg_class WHERE oid = 'films'::regclass`)
+	return rows, err
+}
+
+// DiscoverFilms draws a weighted random sample of published films matching
+// filter. Each film's weight falls with its view count, so under-exposed
+// titles surface more often (Efraimidis-Spirakis weighted sampling). When
+// samplePercent < 100 rows are pre-sampled with TABLESAMPLE BERNOULLI.
+func (q *Queries) DiscoverFilms(ctx context.Context, filter FilmFilter, samplePercent float64, limit int) ([]models.Film, error) {
+	where := filmWhere(filter)
+	where.add("f.published_at IS NOT NULL")
+
+	from := "films f"
+	if samplePercent < 100 {
+		from = "films f TABLESAMPLE BERNOULLI (" + where.arg(samplePercent) + ")"
+	}
+
+	query := `
+		SELECT f.*,
+		       COALESCE(jsonb_build_object(
+		           'id', u.id,
+		           'email', u.email,
+		           'name', u.name,
+		           'avatar_url', u.avatar_url
+		       )::json, '{}'::json) as created_by
+		FROM ` + from + `
+		LEFT JOIN users u ON f.created_by_id = u.id
+		` + where.sql() + `
+		ORDER BY -ln(1 - random()) * (1 + ln(1 + f.view_count))
+		LIMIT ` + where.arg(limit)
+
+	films := []models.Film{}
+	err := q.db.SelectContext(ctx, &films, query, where.args...)
+	return films, err
+}
+
+// CountFilms returns the number of films matching filter
+func (q *Queries) CountFilms(ctx context.Context, filter FilmFilter) (int, error) {
+	where := filmWhere(filter)
+	query := `SELECT COUNT(*) FROM films f ` + where.sql()
+
+	var total int
+	err := q.db.GetContext(ctx, &total, query, where.args...)
+	return total, err
+}
+
+// GetListedFilmsByIDs retrieves the films among ids that are ready,
+// public, published and available in region (see FilmFilter.Region),
+// preserving the order of ids. Films that no longer qualify, e.g. hits from
+// a stale search index, are left out.
+func (q *Queries) GetListedFilmsByIDs(ctx context.Context, ids []uuid.UUID, region *string) ([]models.Film, error) {
+	films := []models.Film{}
+	if len(ids) == 0 {
+		return films, nil
+	}
+
+	idStrings := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	where := filmWhere(FilmFilter{Status: models.StatusReady, Region: region, Listed: true})
+	where.add("f.published_at IS NOT NULL")
+	ordered := where.arg(idStrings)
+	where.add("f.id = ANY(" + ordered + "::uuid[])")
+
+	query := `
+		SELECT f.*,
+		       COALESCE(jsonb_build_object(
+		           'id', u.id,
+		           'email', u.email,
+		           'name', u.name,
+		           'avatar_url', u.avatar_url
+		       )::json, '{}'::json) as created_by
+		FROM films f
+		LEFT JOIN users u ON f.created_by_id = u.id
+		` + where.sql() + `
+		ORDER BY array_position(` + ordered + `::uuid[], f.id)
 	`
-	err := q.db.SelectContext(ctx, &films, query, status, limit, offset)
+	err := q.db.SelectContext(ctx, &films, query, where.args...)
 	return films, err
 }
 
+// GetFilmsByIDs retrieves films by ID, preserving the order of ids
+func (q *Queries) GetFilmsByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Film, error) {
+	films := []models.Film{}
+	if len(ids) == 0 {
+		return films, nil
+	}
+
+	idStrings := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	query := `
+		SELECT f.*,
+		       COALESCE(jsonb_build_object(
+		           'id', u.id,
+		           'email', u.email,
+		           'name', u.name,
+		           'avatar_url', u.avatar_url
+		       )::json, '{}'::json) as created_by
+		FROM films f
+		LEFT JOIN users u ON f.created_by_id = u.id
+		WHERE f.id = ANY($1::uuid[])
+		ORDER BY array_position($1::uuid[], f.id)
+	`
+	err := q.db.SelectContext(ctx, &films, query, idStrings)
+	return films, err
+}
+
+// searchScore scales a relevance expression by the recency ($4, with the
+// half-life in days at $5), view count ($6) and verified creator ($7) boosts
+func searchScore(relevance string) string {
+	return relevance + `
+		       * (1 + $4::float8 * power(0.5, EXTRACT(EPOCH FROM NOW() - COALESCE(f.published_at, f.created_at)) / 86400 / $5::float8))
+		       * (1 + $6::float8 * log((1 + f.view_count)::float8))
+		       * (1 + $7::float8 * CASE WHEN u.verified THEN 1 ELSE 0 END)`
+}
+
+// searchHeadline is the ts_headline option string marking matched terms
+var searchHeadline = "StartSel=" + models.HighlightStart + ", StopSel=" + models.HighlightStop
+
+// searchListed restricts a search to ready, public and published films
+// available in the region at placeholder param, as FilmFilter.Region does
+// (NULL disables the check)
+func searchListed(param string) string {
+	return `f.status = 'READY' AND f.visibility = 'PUBLIC' AND f.published_at IS NOT NULL
+		  AND (` + param + `::text IS NULL OR (
+		      (cardinality(f.allowed_regions) = 0 OR ` + param + `::text = ANY(f.allowed_regions))
+		      AND NOT (` + param + `::text = ANY(f.blocked_regions))))`
+}
+
+// SearchFilms runs a ranked full-text search over listed films available
+// in region, boosting the text rank and returning marked-up title and
+// description snippets
+func (q *Queries) SearchFilms(ctx context.Context, search string, region *string, boosts models.SearchBoosts, limit int, offset int) ([]models.SearchHit, int, error) {
+	var total int
+	countQuery := `
+		SELECT COUNT(*) FROM films f
+		WHERE ` + searchListed("$2") + `
+		  AND f.search_vector @@ websearch_to_tsquery('english', $1)
+	`
+	if err := q.db.GetContext(ctx, &total, countQuery, search, region); err != nil {
+		return nil, 0, err
+	}
+
+	// Headlines are expensive, so they are built for the page only
+	hits := []models.SearchHit{}
+	query := `
+		SELECT page.*,
+		       ts_headline('english', page.title, websearch_to_tsquery('english', $1),
+		                   $8 || ', HighlightAll=true') AS title_highlight,
+		       ts_headline('english', COALESCE(page.description, ''), websearch_to_tsquery('english', $1),
+		                   $8 || ', MaxWords=35, MinWords=15, MaxFragments=2') AS description_highlight
+		FROM (
+			SELECT f.*,
+			       COALESCE(jsonb_build_object(
+			           'id', u.id,
+			           'email', u.email,
+			           'name', u.name,
+			           'avatar_url', u.avatar_url
+			       )::json, '{}'::json) as created_by,
+			       ` + searchScore(`ts_rank_cd(f.search_vector, websearch_to_tsquery('english', $1))`) + ` AS score
+			FROM films f
+			LEFT JOIN users u ON f.created_by_id = u.id
+			WHERE ` + searchListed("$9") + `
+			  AND f.search_vector @@ websearch_to_tsquery('english', $1)
+			ORDER BY score DESC, f.id
+			LIMIT $2 OFFSET $3
+		) page
+		ORDER BY page.score DESC, page.id
+	`
+	err := q.db.SelectContext(ctx, &hits, query, search, limit, offset,
+		boosts.Recency, boosts.RecencyHalfLifeDays, boosts.Views, boosts.Verified, searchHeadline, region)
+	return hits, total, err
+}
+
+// SearchFilmsFuzzy matches listed films available in region by title
+// trigram similarity, used as a fallback when full-text search finds
+// nothing (e.g. typos). Hits carry the unmarked title since no term matched
+// exactly.
+func (q *Queries) SearchFilmsFuzzy(ctx context.Context, search string, region *string, boosts models.SearchBoosts, limit int, offset int) ([]models.SearchHit, int, error) {
+	var total int
+	countQuery := `
+		SELECT COUNT(*) FROM films f
+		WHERE ` + searchListed("$2") + ` AND f.title % $1
+	`
+	if err := q.db.GetContext(ctx, &total, countQuery, search, region); err != nil {
+		return nil, 0, err
+	}
+
+	hits := []models.SearchHit{}
+	query := `
+		SELECT f.*,
+		       COALESCE(jsonb_build_object(
+		           'id', u.id,
+		           'email', u.email,
+		           'name', u.name,
+		           'avatar_url', u.avatar_url
+		       )::json, '{}'::json) as created_by,
+		       ` + searchScore(`similarity(f.title, $1)`) + ` AS score,
+		       f.title AS title_highlight,
+		       '' AS description_highlight
+		FROM films f
+		LEFT JOIN users u ON f.created_by_id = u.id
+		WHERE ` + searchListed("$8") + ` AND f.title % $1
+		ORDER BY score DESC, f.id
+		LIMIT $2 OFFSET $3
+	`
+	err := q.db.SelectContext(ctx, &hits, query, search, limit, offset,
+		boosts.Recency, boosts.RecencyHalfLifeDays, boosts.Views, boosts.Verified, region)
+	return hits, total, err
+}
+
 // UpdateFilmStatus updates the status of a film
 func (q *Queries) UpdateFilmStatus(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, status models.FilmStatus) error {
 	query := `UPDATE films SET status = $1 WHERE id = $2`
@@ -207,18 +562,33 @@ func (q *Queries) UpdateTranscodeJobStatus(ctx context.Context, id uuid.UUID, st
 
 // ========== VIDEO ASSET QUERIES ==========
 
-// CreateVideoAsset inserts a new video asset
+// CreateVideoAsset inserts a video asset, or replaces the one of the same
+// quality and variant. A replaced rendition's QC scores no longer apply,
+// so they are cleared.
 func (q *Queries) CreateVideoAsset(ctx context.Context, asset *models.VideoAsset) error {
 	query := `
-		INSERT INTO video_assets (id, film_id, quality, hls_index_url, size_bytes)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (film_id, quality) DO UPDATE
+		INSERT INTO video_assets (id, film_id, quality, variant, hls_index_url, size_bytes,
+			first_segment_url, segment_count, avg_segment_bytes, avg_segment_seconds, bandwidth)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (film_id, quality, variant) DO UPDATE
 		SET hls_index_url = EXCLUDED.hls_index_url,
-		    size_bytes = EXCLUDED.size_bytes
+		    size_bytes = EXCLUDED.size_bytes,
+		    first_segment_url = EXCLUDED.first_segment_url,
+		    segment_count = EXCLUDED.segment_count,
+		    avg_segment_bytes = EXCLUDED.avg_segment_bytes,
+		    avg_segment_seconds = EXCLUDED.avg_segment_seconds,
+		    bandwidth = EXCLUDED.bandwidth,
+		    vmaf = NULL,
+		    psnr = NULL,
+		    qc_at = NULL
 	`
+	if asset.Variant == "" {
+		asset.Variant = models.VariantDefault
+	}
 	_, err := q.db.ExecContext(ctx, query,
-		asset.ID, asset.FilmID, asset.Quality,
+		asset.ID, asset.FilmID, asset.Quality, asset.Variant,
 		asset.HLSIndexURL, asset.SizeBytes,
+		asset.FirstSegmentURL, asset.SegmentCount, asset.AvgSegmentBytes, asset.AvgSegmentSeconds, asset.Bandwidth,
 	)
 	return err
 }
@@ -226,7 +596,15 @@ func (q *Queries) CreateVideoAsset(ctx context.Context, asset *models.VideoAsset
 // GetVideoAssetsByFilmID retrieves all video assets for a film
 func (q *Queries) GetVideoAssetsByFilmID(ctx context.Context, filmID uuid.UUID) ([]models.VideoAsset, error) {
 	var assets []models.VideoAsset
-	query := `SELECT * FROM video_assets WHERE film_id = $1 ORDER BY quality DESC`
+	query := `SELECT * FROM video_assets WHERE film_id = $1 ORDER BY variant, quality DESC`
 	err := q.db.SelectContext(ctx, &assets, query, filmID)
 	return assets, err
 }
+
+// GetVideoAssetsByVariant retrieves the video assets of one rendition variant
+func (q *Queries) GetVideoAssetsByVariant(ctx context.Context, filmID uuid.UUID, variant string) ([]models.VideoAsset, error) {
+	var assets []models.VideoAsset
+	query := `SELECT * FROM video_assets WHERE film_id = $1 AND variant = $2 ORDER BY quality DESC`
+	err := q.db.SelectContext(ctx, &assets, query, filmID, variant)
+	return assets, err
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== ACCOUNT DELETION QUERIES ==========
+
+// ListFilmsByOwner returns every film a user owns, whatever its state
+func (q *Queries) ListFilmsByOwner(ctx context.Context, userID uuid.UUID) ([]models.Film, error) {
+	films := []models.Film{}
+	err := q.db.SelectContext(ctx, &films, `SELECT * FROM films WHERE created_by_id = $1 ORDER BY created_at`, userID)
+	return films, err
+}
+
+// SetFilmOwnerDeletion sets what happens to a film when its owner's
+// account is deleted
+func (q *Queries) SetFilmOwnerDeletion(ctx context.Context, filmID uuid.UUID, policy models.OwnerDeletionPolicy) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE films SET on_owner_deletion = $2 WHERE id = $1`, filmID, policy)
+	return err
+}
+
+// DeletedFilm is a film removed with its owner's account, whose storage
+// must be cleaned up
+type DeletedFilm struct {
+	ID           uuid.UUID `db:"id"`
+	SourceRegion string    `db:"source_region"`
+}
+
+// DeleteUserAccount deletes a user in one transaction. The films in keep
+// are reassigned to the ghost account with the user's name as their
+// attribution, as are the ghost users of comments the user imported, so
+// comments on kept films survive. The user's other films are deleted and
+// returned. Everything else of the user goes with the row.
+func (q *Queries) DeleteUserAccount(ctx context.Context, user *models.User, keep []uuid.UUID, deletedByID *uuid.UUID) (*models.AccountDeletion, []DeletedFilm, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	ids := pq.StringArray{}
+	for _, id := range keep {
+		ids = append(ids, id.String())
+	}
+	result, err := tx.ExecContext(ctx, `
+		UPDATE films
+		SET created_by_id = $3, attribution = COALESCE(NULLIF(attribution, ''), $4)
+		WHERE created_by_id = $1 AND id = ANY($2::uuid[])
+	`, user.ID, ids, models.GhostAccountID, user.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	kept, err := result.RowsAffected()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Imported comment authors are unique by name per owner; suffix those
+	// whose name the ghost account already has
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users g
+		SET ghost_owner_id = $2,
+		    name = CASE WHEN EXISTS (
+		               SELECT 1 FROM users o WHERE o.ghost_owner_id = $2 AND o.name = g.name
+		           ) THEN g.name || ' #' || LEFT(g.id::text, 8) ELSE g.name END
+		WHERE g.ghost_owner_id = $1
+	`, user.ID, models.GhostAccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deleted := []DeletedFilm{}
+	err = tx.SelectContext(ctx, &deleted, `DELETE FROM films WHERE created_by_id = $1 RETURNING id, source_region`, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, user.ID); err != nil {
+		return nil, nil, err
+	}
+
+	var record models.AccountDeletion
+	err = tx.GetContext(ctx, &record, `
+		INSERT INTO account_deletions (user_id, email, deleted_by_id, films_kept, films_deleted)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING *
+	`, user.ID, user.Email, deletedByID, kept, len(deleted))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &record, deleted, tx.Commit()
+}
+
+// ListAccountDeletions returns completed account deletions, most recent
+// first. The actor is the admin who deleted the account; action is self
+// or admin.
+func (q *Queries) ListAccountDeletions(ctx context.Context, filter AdminListFilter, after *AdminCursor, offset, limit int) ([]models.AccountDeletion, error) {
+	w := accountDeletionWhere(filter)
+	order := adminOrder(w, "d.created_at", "d.id", after, offset, limit)
+
+	deletions := []models.AccountDeletion{}
+	err := q.db.SelectContext(ctx, &deletions, `SELECT d.* FROM account_deletions d `+w.sql()+` `+order, w.args...)
+	return deletions, err
+}
+
+// CountAccountDeletions counts account deletions matching the filter
+func (q *Queries) CountAccountDeletions(ctx context.Context, filter AdminListFilter) (int, error) {
+	w := accountDeletionWhere(filter)
+	var count int
+	err := q.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM account_deletions d `+w.sql(), w.args...)
+	return count, err
+}
+
+func accountDeletionWhere(filter AdminListFilter) *whereBuilder {
+	w := &whereBuilder{}
+	filter.applyActorAndRange(w, "d.deleted_by_id", "d.created_at")
+	switch filter.Action {
+	case models.AccountDeletionSelf:
+		w.add("d.deleted_by_id IS NULL")
+	case models.AccountDeletionAdmin:
+		w.add("d.deleted_by_id IS NOT NULL")
+	}
+	return w
+}
@@ -0,0 +1,531 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== ANALYTICS EVENT QUERIES ==========
+
+// InsertAnalyticsEvents stores a batch of raw events. Events on films of
+// organizations in privacy mode are anonymized first, in place, so later
+// consumers of the batch see them as stored.
+func (q *Queries) InsertAnalyticsEvents(ctx context.Context, events []models.AnalyticsEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := q.anonymizePrivateEvents(ctx, events); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO analytics_events
+			(event_type, film_id, user_id, session_id, ip_address, user_agent, country, device, os, surface, properties, occurred_at, unverified)
+		VALUES
+			(:event_type, :film_id, :user_id, :session_id, :ip_address, :user_agent, :country, :device, :os, :surface, :properties, :occurred_at, :unverified)
+	`
+	_, err := q.db.NamedExecContext(ctx, query, events)
+	return err
+}
+
+// ListAnalyticsEventTypes returns the event types present in raw storage
+func (q *Queries) ListAnalyticsEventTypes(ctx context.Context) ([]models.EventType, error) {
+	var types []models.EventType
+	err := q.db.SelectContext(ctx, &types, `SELECT DISTINCT event_type FROM analytics_events`)
+	return types, err
+}
+
+// ========== DATA LIFECYCLE QUERIES ==========
+
+// watchSeconds is the playback time an event accounts for: a heartbeat's
+// "seconds" property, the time played since the previous heartbeat, capped
+// at models.MaxHeartbeatSeconds, or models.DefaultHeartbeatSeconds when it
+// is missing; zero for other events
+const watchSeconds = `
+	CASE WHEN event_type = 'heartbeat' THEN
+		LEAST(COALESCE(CASE WHEN jsonb_typeof(properties->'seconds') = 'number'
+		                    THEN GREATEST((properties->>'seconds')::numeric, 0) END, 30), 300)
+	ELSE 0 END
+`
+
+// hasBitrate matches heartbeats reporting the bitrate they played at
+const hasBitrate = `event_type = 'heartbeat' AND jsonb_typeof(properties->'bitrate') = 'number'`
+
+// renditionBytesPerSecond is what playing a rendition (va, of film f)
+// delivers per second: its average segment size over segment length,
+// falling back to its advertised bandwidth, then to its size over the
+// film's duration; NULL when none is known
+const renditionBytesPerSecond = `
+	CASE WHEN va.avg_segment_bytes > 0 AND va.avg_segment_seconds > 0
+	     THEN va.avg_segment_bytes / va.avg_segment_seconds::numeric
+	     WHEN va.bandwidth > 0 THEN va.bandwidth / 8.0
+	     WHEN va.size_bytes > 0 AND f.duration > 0 THEN va.size_bytes / f.duration::numeric
+	END
+`
+
+// lastRolledDay is the start of the newest daily rollup, or -infinity when
+// nothing has been rolled up yet
+const lastRolledDay = `
+	(SELECT COALESCE(MAX(period_start)::timestamptz, '-infinity'::timestamptz)
+	 FROM analytics_rollups WHERE period = 'day')
+`
+
+// RollupAnalyticsEvents rolls raw events up into daily per-film counters,
+// and per-surface and per-country, device and OS counters, for every
+// complete day since the newest daily rollup (recomputing that day to pick
+// up late events), along with per-rendition QoE counters and estimated
+// per-film egress. Unverified events are left out. Returns the number of
+// per-film rollup rows written.
+func (q *Queries) RollupAnalyticsEvents(ctx context.Context) (int64, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Surfaces, breakdowns, QoE and egress first: the per-film rollup moves
+	// lastRolledDay forward
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO analytics_surface_rollups (day, film_id, surface, event_type, event_count)
+		SELECT date_trunc('day', occurred_at)::date, film_id, surface, event_type, COUNT(*)
+		FROM analytics_events
+		WHERE film_id IS NOT NULL AND NOT unverified
+		  AND occurred_at >= `+lastRolledDay+`
+		  AND occurred_at < date_trunc('day', NOW())
+		GROUP BY 1, 2, 3, 4
+		ON CONFLICT (day, film_id, surface, event_type) DO UPDATE
+		SET event_count = EXCLUDED.event_count
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO analytics_breakdown_rollups (day, film_id, dimension, value, event_type, event_count)
+		SELECT date_trunc('day', occurred_at)::date, film_id, d.dimension, d.value, event_type, COUNT(*)
+		FROM analytics_events
+		CROSS JOIN LATERAL (VALUES
+			('country', COALESCE(country, '')),
+			('device', device),
+			('os', os)
+		) AS d(dimension, value)
+		WHERE film_id IS NOT NULL AND NOT unverified
+		  AND occurred_at >= `+lastRolledDay+`
+		  AND occurred_at < date_trunc('day', NOW())
+		GROUP BY 1, 2, 3, 4, 5
+		ON CONFLICT (day, film_id, dimension, value, event_type) DO UPDATE
+		SET event_count = EXCLUDED.event_count
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO qoe_rollups (day, rendition, startups, startup_ms, rebuffers, rebuffer_ms,
+		                         watch_seconds, bits_played, bitrate_seconds)
+		SELECT date_trunc('day', occurred_at)::date,
+		       LEFT(COALESCE(properties->>'rendition', ''), 16),
+		       COUNT(*) FILTER (WHERE event_type = 'startup'),
+		       COALESCE(ROUND(SUM((properties->>'startup_ms')::numeric) FILTER (WHERE event_type = 'startup')), 0),
+		       COUNT(*) FILTER (WHERE event_type = 'rebuffer'),
+		       COALESCE(ROUND(SUM((properties->>'duration_ms')::numeric) FILTER (WHERE event_type = 'rebuffer')), 0),
+		       ROUND(SUM(`+watchSeconds+`)),
+		       COALESCE(ROUND(SUM((properties->>'bitrate')::numeric * `+watchSeconds+`) FILTER (WHERE `+hasBitrate+`)), 0),
+		       COALESCE(ROUND(SUM(`+watchSeconds+`) FILTER (WHERE `+hasBitrate+`)), 0)
+		FROM analytics_events
+		WHERE event_type IN ('startup', 'rebuffer', 'heartbeat') AND NOT unverified
+		  AND occurred_at >= `+lastRolledDay+`
+		  AND occurred_at < date_trunc('day', NOW())
+		GROUP BY 1, 2
+		ON CONFLICT (day, rendition) DO UPDATE
+		SET startups = EXCLUDED.startups,
+		    startup_ms = EXCLUDED.startup_ms,
+		    rebuffers = EXCLUDED.rebuffers,
+		    rebuffer_ms = EXCLUDED.rebuffer_ms,
+		    watch_seconds = EXCLUDED.watch_seconds,
+		    bits_played = EXCLUDED.bits_played,
+		    bitrate_seconds = EXCLUDED.bitrate_seconds
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	// Estimated egress: watch time per rendition at that rendition's bytes
+	// per second, from its segment sizes. Heartbeats naming no rendition,
+	// or one the film doesn't have, are charged at the average of the
+	// film's renditions.
+	_, err = tx.ExecContext(ctx, `
+		WITH watched AS (
+			SELECT date_trunc('day', occurred_at)::date AS day, film_id,
+			       COALESCE(properties->>'rendition', '') AS rendition,
+			       SUM(`+watchSeconds+`) AS seconds
+			FROM analytics_events
+			WHERE event_type = 'heartbeat' AND film_id IS NOT NULL AND NOT unverified
+			  AND occurred_at >= `+lastRolledDay+`
+			  AND occurred_at < date_trunc('day', NOW())
+			GROUP BY 1, 2, 3
+		),
+		rates AS (
+			SELECT va.film_id, va.quality, `+renditionBytesPerSecond+` AS bytes_per_second
+			FROM video_assets va
+			JOIN films f ON f.id = va.film_id
+			WHERE va.variant = 'default' AND va.film_id IN (SELECT film_id FROM watched)
+		)
+		INSERT INTO film_egress_daily (day, film_id, estimated_bytes)
+		SELECT w.day, w.film_id, COALESCE(ROUND(SUM(w.seconds * COALESCE(r.bytes_per_second, a.bytes_per_second))), 0)
+		FROM watched w
+		LEFT JOIN rates r ON r.film_id = w.film_id AND r.quality = w.rendition
+		LEFT JOIN (
+			SELECT film_id, AVG(bytes_per_second) AS bytes_per_second FROM rates GROUP BY film_id
+		) a ON a.film_id = w.film_id
+		GROUP BY 1, 2
+		ON CONFLICT (day, film_id) DO UPDATE
+		SET estimated_bytes = EXCLUDED.estimated_bytes
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO analytics_rollups (period, period_start, film_id, event_type, event_count, unique_viewers, watch_seconds)
+		SELECT 'day', date_trunc('day', occurred_at)::date, film_id, event_type,
+		       COUNT(*),
+		       COUNT(DISTINCT COALESCE(user_id::text, session_id)),
+		       ROUND(SUM(`+watchSeconds+`))
+		FROM analytics_events
+		WHERE film_id IS NOT NULL AND NOT unverified
+		  AND occurred_at >= `+lastRolledDay+`
+		  AND occurred_at < date_trunc('day', NOW())
+		GROUP BY 2, 3, 4
+		ON CONFLICT (period, period_start, film_id, event_type) DO UPDATE
+		SET event_count = EXCLUDED.event_count,
+		    unique_viewers = EXCLUDED.unique_viewers,
+		    watch_seconds = EXCLUDED.watch_seconds
+	`)
+	if err != nil {
+		return 0, err
+	}
+	rolledUp, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rolledUp, tx.Commit()
+}
+
+// AnonymizeAnalyticsEvents strips personal fields from events of one type
+// that occurred before the cutoff
+func (q *Queries) AnonymizeAnalyticsEvents(ctx context.Context, eventType models.EventType, before time.Time) (int64, error) {
+	query := `
+		UPDATE analytics_events
+		SET user_id = NULL, ip_address = NULL, user_agent = NULL, anonymized_at = NOW()
+		WHERE event_type = $1 AND occurred_at < $2 AND anonymized_at IS NULL
+	`
+	result, err := q.db.ExecContext(ctx, query, eventType, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteAnalyticsEvents deletes events of one type that occurred before the
+// cutoff. Events not yet covered by a daily rollup are always kept.
+func (q *Queries) DeleteAnalyticsEvents(ctx context.Context, eventType models.EventType, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM analytics_events
+		WHERE event_type = $1
+		  AND occurred_at < LEAST($2::timestamptz, ` + lastRolledDay + `)
+	`
+	result, err := q.db.ExecContext(ctx, query, eventType, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CompactAnalyticsRollups folds daily rollups before the cutoff into
+// monthly rollups and removes the daily rows. The newest day is always kept
+// because it marks where RollupAnalyticsEvents resumes. Returns the number
+// of daily rows compacted.
+func (q *Queries) CompactAnalyticsRollups(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO analytics_rollups (period, period_start, film_id, event_type, event_count, unique_viewers, watch_seconds)
+		SELECT 'month', date_trunc('month', period_start)::date, film_id, event_type,
+		       SUM(event_count), SUM(unique_viewers), SUM(watch_seconds)
+		FROM analytics_rollups
+		WHERE period = 'day' AND period_start < LEAST($1::date, `+lastRolledDay+`::date)
+		GROUP BY 2, 3, 4
+		ON CONFLICT (period, period_start, film_id, event_type) DO UPDATE
+		SET event_count = analytics_rollups.event_count + EXCLUDED.event_count,
+		    unique_viewers = analytics_rollups.unique_viewers + EXCLUDED.unique_viewers,
+		    watch_seconds = analytics_rollups.watch_seconds + EXCLUDED.watch_seconds
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM analytics_rollups
+		WHERE period = 'day' AND period_start < LEAST($1::date, `+lastRolledDay+`::date)
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+	compacted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return compacted, tx.Commit()
+}
+
+// CreateDataLifecycleRun records the start of a lifecycle run
+func (q *Queries) CreateDataLifecycleRun(ctx context.Context) (*models.DataLifecycleRun, error) {
+	var run models.DataLifecycleRun
+	err := q.db.GetContext(ctx, &run, `INSERT INTO data_lifecycle_runs (id) VALUES ($1) RETURNING *`, uuid.New())
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FinishDataLifecycleRun stores the outcome of a lifecycle run
+func (q *Queries) FinishDataLifecycleRun(ctx context.Context, run *models.DataLifecycleRun) error {
+	query := `
+		UPDATE data_lifecycle_runs
+		SET finished_at = NOW(), rolled_up = $2, anonymized = $3, deleted = $4, compacted = $5, error = $6
+		WHERE id = $1
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, run, query,
+		run.ID, run.RolledUp, run.Anonymized, run.Deleted, run.Compacted, run.Error,
+	)
+}
+
+// ListDataLifecycleRuns returns the most recent lifecycle runs
+func (q *Queries) ListDataLifecycleRuns(ctx context.Context, limit int) ([]models.DataLifecycleRun, error) {
+	runs := []models.DataLifecycleRun{}
+	query := `SELECT * FROM data_lifecycle_runs ORDER BY started_at DESC LIMIT $1`
+	err := q.db.SelectContext(ctx, &runs, query, limit)
+	return runs, err
+}
+
+// GetEventRetentionStats summarises raw event storage per event type
+func (q *Queries) GetEventRetentionStats(ctx context.Context) ([]models.EventRetentionStats, error) {
+	stats := []models.EventRetentionStats{}
+	query := `
+		SELECT event_type,
+		       COUNT(*) AS total,
+		       COUNT(anonymized_at) AS anonymized,
+		       COUNT(*) FILTER (WHERE unverified) AS unverified,
+		       MIN(occurred_at) AS oldest,
+		       MAX(occurred_at) AS newest
+		FROM analytics_events
+		GROUP BY event_type
+		ORDER BY event_type
+	`
+	err := q.db.SelectContext(ctx, &stats, query)
+	return stats, err
+}
+
+// GetRollupStats summarises rollup storage per period
+func (q *Queries) GetRollupStats(ctx context.Context) ([]models.RollupStats, error) {
+	stats := []models.RollupStats{}
+	query := `
+		SELECT period,
+		       COUNT(*) AS rows,
+		       MIN(period_start)::timestamptz AS oldest,
+		       MAX(period_start)::timestamptz AS newest
+		FROM analytics_rollups
+		GROUP BY period
+		ORDER BY period
+	`
+	err := q.db.SelectContext(ctx, &stats, query)
+	return stats, err
+}
+
+// ========== ANALYTICS SINK QUERIES ==========
+
+// ListAnalyticsEventsAfter returns raw events with id > afterID in id order
+func (q *Queries) ListAnalyticsEventsAfter(ctx context.Context, afterID int64, limit int) ([]models.AnalyticsEvent, error) {
+	var events []models.AnalyticsEvent
+	query := `SELECT * FROM analytics_events WHERE id > $1 ORDER BY id LIMIT $2`
+	err := q.db.SelectContext(ctx, &events, query, afterID, limit)
+	return events, err
+}
+
+// GetAnalyticsSinkState returns the export state of a sink, creating it on
+// first use
+func (q *Queries) GetAnalyticsSinkState(ctx context.Context, sink string) (*models.AnalyticsSinkState, error) {
+	var state models.AnalyticsSinkState
+	query := `
+		INSERT INTO analytics_sink_state (sink) VALUES ($1)
+		ON CONFLICT (sink) DO UPDATE SET sink = EXCLUDED.sink
+		RETURNING *
+	`
+	if err := q.db.GetContext(ctx, &state, query, sink); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// AdvanceAnalyticsSinkState moves a sink's watermark after a successful export
+func (q *Queries) AdvanceAnalyticsSinkState(ctx context.Context, sink string, lastEventID int64, exported int) error {
+	query := `
+		UPDATE analytics_sink_state
+		SET last_event_id = $2,
+		    exported_count = exported_count + $3,
+		    last_exported_at = NOW(),
+		    last_error = NULL
+		WHERE sink = $1
+	`
+	_, err := q.db.ExecContext(ctx, query, sink, lastEventID, exported)
+	return err
+}
+
+// RecordAnalyticsSinkError stores the last export failure for a sink
+func (q *Queries) RecordAnalyticsSinkError(ctx context.Context, sink, message string) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE analytics_sink_state SET last_error = $2 WHERE sink = $1`, sink, message)
+	return err
+}
+
+// GetMaxAnalyticsEventID returns the newest raw event id (0 when empty)
+func (q *Queries) GetMaxAnalyticsEventID(ctx context.Context) (int64, error) {
+	var id int64
+	err := q.db.GetContext(ctx, &id, `SELECT COALESCE(MAX(id), 0) FROM analytics_events`)
+	return id, err
+}
+
+// ========== ANALYTICS REPORTING QUERIES ==========
+
+// GetFilmDailyEventCounts returns per-day event counts for a film in
+// [from, to), from daily rollups plus raw events not yet rolled up
+func (q *Queries) GetFilmDailyEventCounts(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.DailyEventCount, error) {
+	counts := []models.DailyEventCount{}
+	query := `
+		SELECT period_start::timestamptz AS day, event_type, event_count, unique_viewers
+		FROM analytics_rollups
+		WHERE period = 'day' AND film_id = $1
+		  AND period_start >= $2::date AND period_start < $3::date
+		UNION ALL
+		SELECT date_trunc('day', occurred_at) AS day, event_type,
+		       COUNT(*) AS event_count,
+		       COUNT(DISTINCT COALESCE(user_id::text, session_id)) AS unique_viewers
+		FROM analytics_events
+		WHERE film_id = $1 AND NOT unverified
+		  AND occurred_at >= GREATEST($2::timestamptz, ` + lastRolledDay + ` + INTERVAL '1 day')
+		  AND occurred_at < $3
+		GROUP BY 1, 2
+		ORDER BY day, event_type
+	`
+	err := q.db.SelectContext(ctx, &counts, query, filmID, from, to)
+	return counts, err
+}
+
+// GetFilmSurfaceEventCounts returns a film's event counts per surface and
+// type in [from, to), from the surface rollups plus raw events not yet
+// rolled up
+func (q *Queries) GetFilmSurfaceEventCounts(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.SurfaceEventCount, error) {
+	counts := []models.SurfaceEventCount{}
+	query := `
+		SELECT surface, event_type, SUM(event_count)::bigint AS event_count
+		FROM (
+			SELECT surface, event_type, event_count
+			FROM analytics_surface_rollups
+			WHERE film_id = $1 AND day >= $2::date AND day < $3::date
+			UNION ALL
+			SELECT surface, event_type, COUNT(*)
+			FROM analytics_events
+			WHERE film_id = $1 AND NOT unverified
+			  AND occurred_at >= GREATEST($2::timestamptz, ` + lastRolledDay + ` + INTERVAL '1 day')
+			  AND occurred_at < $3
+			GROUP BY 1, 2
+		) counts
+		GROUP BY surface, event_type
+		ORDER BY surface, event_type
+	`
+	err := q.db.SelectContext(ctx, &counts, query, filmID, from, to)
+	return counts, err
+}
+
+// breakdownColumns maps each breakdown dimension to its raw event column
+var breakdownColumns = map[string]string{
+	models.DimensionCountry: "COALESCE(country, '')",
+	models.DimensionDevice:  "device",
+	models.DimensionOS:      "os",
+}
+
+// GetBreakdownEventCounts returns event counts in [from, to) per value of
+// a breakdown dimension, for one film or, when filmID is nil, every film,
+// from the daily rollups plus raw events not yet rolled up
+func (q *Queries) GetBreakdownEventCounts(ctx context.Context, filmID *uuid.UUID, dimension string, from, to time.Time) ([]models.BreakdownEventCount, error) {
+	column, ok := breakdownColumns[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unknown breakdown dimension %q", dimension)
+	}
+
+	counts := []models.BreakdownEventCount{}
+	query := `
+		SELECT value, event_type, SUM(event_count)::bigint AS event_count
+		FROM (
+			SELECT value, event_type, event_count
+			FROM analytics_breakdown_rollups
+			WHERE ($1::uuid IS NULL OR film_id = $1) AND dimension = $4
+			  AND day >= $2::date AND day < $3::date
+			UNION ALL
+			SELECT ` + column + `, event_type, COUNT(*)
+			FROM analytics_events
+			WHERE film_id IS NOT NULL AND ($1::uuid IS NULL OR film_id = $1) AND NOT unverified
+			  AND occurred_at >= GREATEST($2::timestamptz, ` + lastRolledDay + ` + INTERVAL '1 day')
+			  AND occurred_at < $3
+			GROUP BY 1, 2
+		) counts
+		GROUP BY value, event_type
+		ORDER BY value, event_type
+	`
+	err := q.db.SelectContext(ctx, &counts, query, filmID, from, to, dimension)
+	return counts, err
+}
+
+// GetFilmEventSeries returns a film's event counts, unique viewers and
+// watch time per type in [from, to), bucketed by day or week (weeks start
+// on Monday), from the daily rollups plus raw events not yet rolled up.
+// Unique viewers of a week are summed from its days.
+func (q *Queries) GetFilmEventSeries(ctx context.Context, filmID uuid.UUID, from, to time.Time, interval string) ([]models.EventSeriesCount, error) {
+	counts := []models.EventSeriesCount{}
+	query := `
+		SELECT date_trunc($4, day) AS bucket, event_type,
+		       SUM(event_count)::bigint AS event_count,
+		       SUM(unique_viewers)::bigint AS unique_viewers,
+		       SUM(watch_seconds)::bigint AS watch_seconds
+		FROM (
+			SELECT period_start::timestamptz AS day, event_type, event_count, unique_viewers, watch_seconds
+			FROM analytics_rollups
+			WHERE period = 'day' AND film_id = $1
+			  AND period_start >= $2::date AND period_start < $3::date
+			UNION ALL
+			SELECT date_trunc('day', occurred_at), event_type,
+			       COUNT(*),
+			       COUNT(DISTINCT COALESCE(user_id::text, session_id)),
+			       ROUND(SUM(` + watchSeconds + `))
+			FROM analytics_events
+			WHERE film_id = $1 AND NOT unverified
+			  AND occurred_at >= GREATEST($2::timestamptz, ` + lastRolledDay + ` + INTERVAL '1 day')
+			  AND occurred_at < $3
+			GROUP BY 1, 2
+		) days
+		GROUP BY 1, 2
+		ORDER BY bucket, event_type
+	`
+	err := q.db.SelectContext(ctx, &counts, query, filmID, from, to, interval)
+	return counts, err
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== API KEY QUERIES ==========
+
+// CreateAPIKey stores a new API key
+func (q *Queries) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+	return q.db.QueryRowContext(ctx, query, key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash).Scan(&key.CreatedAt)
+}
+
+// ListAPIKeys returns a user's API keys, newest first, including revoked
+// ones
+func (q *Queries) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	query := `SELECT * FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+	err := q.db.SelectContext(ctx, &keys, query, userID)
+	return keys, err
+}
+
+// GetAPIKey retrieves an API key by ID
+func (q *Queries) GetAPIKey(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := q.db.GetContext(ctx, &key, `SELECT * FROM api_keys WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAPIKeyByHash retrieves an unrevoked API key by the hash of the key,
+// with the user it acts as. Keys of banned users are not found.
+func (q *Queries) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKeyOwner, error) {
+	var key models.APIKeyOwner
+	query := `
+		SELECT k.*, u.email, u.role
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND u.banned_at IS NULL
+	`
+	if err := q.db.GetContext(ctx, &key, query, hash); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey revokes one of a user's API keys, reporting whether it was
+// active
+func (q *Queries) RevokeAPIKey(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	result, err := q.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// SetAPIKeyLimits sets an API key's own rate limit and daily quota; nil
+// falls back to the platform settings
+func (q *Queries) SetAPIKeyLimits(ctx context.Context, id uuid.UUID, ratePerMinute, dailyQuota *int) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `
+		UPDATE api_keys SET rate_limit_per_minute = $2, daily_quota = $3
+		WHERE id = $1
+		RETURNING *
+	`
+	if err := q.db.GetContext(ctx, &key, query, id, ratePerMinute, dailyQuota); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// AddAPIKeyUsage adds usage counted in Redis to an API key's hour. Usage
+// of keys deleted meanwhile is dropped.
+func (q *Queries) AddAPIKeyUsage(ctx context.Context, keyID uuid.UUID, usage *models.APIKeyUsage) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO api_key_usage (key_id, hour, requests, client_errors, server_errors, rate_limited, latency_ms)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE EXISTS (SELECT 1 FROM api_keys WHERE id = $1)
+		ON CONFLICT (key_id, hour) DO UPDATE SET
+			requests = api_key_usage.requests + EXCLUDED.requests,
+			client_errors = api_key_usage.client_errors + EXCLUDED.client_errors,
+			server_errors = api_key_usage.server_errors + EXCLUDED.server_errors,
+			rate_limited = api_key_usage.rate_limited + EXCLUDED.rate_limited,
+			latency_ms = api_key_usage.latency_ms + EXCLUDED.latency_ms
+	`, keyID, usage.Period, usage.Requests, usage.ClientErrors, usage.ServerErrors, usage.RateLimited, usage.LatencyMs)
+	if err != nil {
+		return err
+	}
+	// Usage is flushed within minutes, close enough for last use
+	if _, err := tx.ExecContext(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, keyID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAPIKeyUsage returns an API key's usage in [from, to) per hour or day,
+// oldest first; periods without requests are left out
+func (q *Queries) GetAPIKeyUsage(ctx context.Context, keyID uuid.UUID, from, to time.Time, interval string) ([]models.APIKeyUsage, error) {
+	usage := []models.APIKeyUsage{}
+	query := `
+		SELECT date_trunc($4, hour) AS period,
+		       SUM(requests) AS requests,
+		       SUM(client_errors) AS client_errors,
+		       SUM(server_errors) AS server_errors,
+		       SUM(rate_limited) AS rate_limited,
+		       SUM(latency_ms) AS latency_ms
+		FROM api_key_usage
+		WHERE key_id = $1 AND hour >= $2 AND hour < $3
+		GROUP BY period
+		ORDER BY period
+	`
+	err := q.db.SelectContext(ctx, &usage, query, keyID, from, to, interval)
+	return usage, err
+}
+
+// ListTopAPIKeyConsumers ranks API keys by requests in [from, to)
+func (q *Queries) ListTopAPIKeyConsumers(ctx context.Context, from, to time.Time, limit int) ([]models.APIKeyConsumer, error) {
+	consumers := []models.APIKeyConsumer{}
+	query := `
+		SELECT k.id AS key_id, k.name, k.prefix, k.user_id, u.email,
+		       SUM(a.requests) AS requests,
+		       SUM(a.client_errors) AS client_errors,
+		       SUM(a.server_errors) AS server_errors,
+		       SUM(a.rate_limited) AS rate_limited,
+		       SUM(a.latency_ms) AS latency_ms
+		FROM api_key_usage a
+		JOIN api_keys k ON k.id = a.key_id
+		JOIN users u ON u.id = k.user_id
+		WHERE a.hour >= $1 AND a.hour < $2
+		GROUP BY k.id, u.email
+		ORDER BY requests DESC, k.id
+		LIMIT $3
+	`
+	err := q.db.SelectContext(ctx, &consumers, query, from, to, limit)
+	return consumers, err
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== BACKUP QUERIES ==========
+
+// CreateBackupRun records the start of a backup run
+func (q *Queries) CreateBackupRun(ctx context.Context, prefix string) (*models.BackupRun, error) {
+	var run models.BackupRun
+	err := q.db.GetContext(ctx, &run, `INSERT INTO backup_runs (id, prefix) VALUES ($1, $2) RETURNING *`, uuid.New(), prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FinishBackupRun stores the outcome of a backup run
+func (q *Queries) FinishBackupRun(ctx context.Context, run *models.BackupRun) error {
+	query := `
+		UPDATE backup_runs
+		SET finished_at = NOW(), status = $2, tables = $3, row_count = $4, playlists = $5, objects = $6, bytes = $7, error = $8
+		WHERE id = $1
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, run, query,
+		run.ID, run.Status, run.Tables, run.Rows, run.Playlists, run.Objects, run.Bytes, run.Error,
+	)
+}
+
+// ListBackupRuns returns the most recent backup runs
+func (q *Queries) ListBackupRuns(ctx context.Context, limit int) ([]models.BackupRun, error) {
+	runs := []models.BackupRun{}
+	query := `SELECT * FROM backup_runs ORDER BY started_at DESC LIMIT $1`
+	err := q.db.SelectContext(ctx, &runs, query, limit)
+	return runs, err
+}
+
+// GetLastSuccessfulBackupRun returns the latest backup that succeeded;
+// sql.ErrNoRows when none has
+func (q *Queries) GetLastSuccessfulBackupRun(ctx context.Context) (*models.BackupRun, error) {
+	var run models.BackupRun
+	query := `SELECT * FROM backup_runs WHERE status = $1 ORDER BY started_at DESC LIMIT 1`
+	if err := q.db.GetContext(ctx, &run, query, models.BackupSucceeded); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// DumpTables reads every row of the tables as JSON objects, all from one
+// snapshot so the rows are consistent with each other. Rows are passed to
+// emit in created_at order where a table has it, so parents come before
+// the rows referencing them.
+func (q *Queries) DumpTables(ctx context.Context, tables []string, emit func(table string, row []byte) error) error {
+	tx, err := q.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		var ordered bool
+		err := tx.GetContext(ctx, &ordered, `
+			SELECT EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = $1 AND column_name = 'created_at'
+			)
+		`, table)
+		if err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf(`SELECT row_to_json(t)::text FROM %s t`, pq.QuoteIdentifier(table))
+		if ordered {
+			query += ` ORDER BY t.created_at`
+		}
+		rows, err := tx.QueryContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("dump %s: %w", table, err)
+		}
+		for rows.Next() {
+			var row []byte
+			if err := rows.Scan(&row); err != nil {
+				rows.Close()
+				return err
+			}
+			if err := emit(table, row); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("dump %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// CountTableRows returns how many rows a table holds
+func (q *Queries) CountTableRows(ctx context.Context, table string) (int64, error) {
+	var n int64
+	err := q.db.GetContext(ctx, &n, fmt.Sprintf(`SELECT COUNT(*) FROM %s`, pq.QuoteIdentifier(table)))
+	return n, err
+}
+
+// ListForeignKeys returns the single-column foreign keys of the schema
+func (q *Queries) ListForeignKeys(ctx context.Context) ([]models.ForeignKey, error) {
+	keys := []models.ForeignKey{}
+	query := `
+		SELECT c.conrelid::regclass::text AS table_name, a.attname AS column_name,
+		       c.confrelid::regclass::text AS ref_table, af.attname AS ref_column
+		FROM pg_constraint c
+		JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+		JOIN pg_attribute af ON af.attrelid = c.confrelid AND af.attnum = c.confkey[1]
+		WHERE c.contype = 'f' AND array_length(c.conkey, 1) = 1
+		  AND c.connamespace = current_schema()::regnamespace
+		ORDER BY 1, 2
+	`
+	err := q.db.SelectContext(ctx, &keys, query)
+	return keys, err
+}
+
+// RestoreTables inserts rows into the tables in order, in one transaction.
+// The tables' own triggers are off while rows go in, so counters they
+// maintain keep their backed-up values instead of counting the restored
+// rows again; foreign keys are still enforced.
+func (q *Queries) RestoreTables(ctx context.Context, tables []string, rows map[string][]json.RawMessage) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		name := pq.QuoteIdentifier(table)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s DISABLE TRIGGER USER`, name)); err != nil {
+			return fmt.Errorf("disable triggers on %s: %w", table, err)
+		}
+		insert := fmt.Sprintf(`INSERT INTO %s SELECT * FROM json_populate_record(NULL::%s, $1)`, name, name)
+		for _, row := range rows[table] {
+			if _, err := tx.ExecContext(ctx, insert, []byte(row)); err != nil {
+				return fmt.Errorf("restore %s: %w", table, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s ENABLE TRIGGER USER`, name)); err != nil {
+			return fmt.Errorf("enable triggers on %s: %w", table, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== CALENDAR QUERIES ==========
+
+// CalendarFilter selects content calendar entries overlapping [From, To)
+type CalendarFilter struct {
+	From      time.Time
+	To        time.Time
+	Kinds     []models.CalendarKind // empty means every kind
+	FilmID    *uuid.UUID
+	CreatorID *uuid.UUID
+}
+
+// calendarEntries unions release events with featured slots and press
+// embargo lifts into calendar entries (alias c)
+const calendarEntries = `
+	SELECT e.id, e.kind, e.film_id, e.name, e.starts_at, e.ends_at
+	FROM release_events e
+	UNION ALL
+	SELECT ff.id, 'FEATURED', ff.film_id, ff.headline, ff.starts_at, ff.ends_at
+	FROM featured_films ff
+	UNION ALL
+	SELECT f.id, 'EMBARGO', f.id, NULL, f.press_embargo_until, NULL
+	FROM films f
+	WHERE f.press_embargo_until IS NOT NULL
+`
+
+// ListCalendar returns the calendar entries matching filter, earliest
+// first. Featured slots without an end stay on the calendar once started.
+func (q *Queries) ListCalendar(ctx context.Context, filter CalendarFilter) ([]models.CalendarEntry, error) {
+	w := &whereBuilder{}
+	w.add("c.starts_at < ?", filter.To)
+	w.add("(COALESCE(c.ends_at, c.starts_at) >= ? OR (c.kind = 'FEATURED' AND c.ends_at IS NULL))", filter.From)
+	if len(filter.Kinds) > 0 {
+		kinds := make([]string, len(filter.Kinds))
+		for i, k := range filter.Kinds {
+			kinds[i] = string(k)
+		}
+		w.add("c.kind = ANY(?)", pq.StringArray(kinds))
+	}
+	if filter.FilmID != nil {
+		w.add("c.film_id = ?", *filter.FilmID)
+	}
+	if filter.CreatorID != nil {
+		w.add("f.created_by_id = ?", *filter.CreatorID)
+	}
+
+	query := `
+		SELECT c.*, f.title AS film_title, f.created_by_id AS creator_id, u.name AS creator_name
+		FROM (` + calendarEntries + `) c
+		JOIN films f ON f.id = c.film_id
+		JOIN users u ON u.id = f.created_by_id
+		` + w.sql() + `
+		ORDER BY c.starts_at, c.id
+	`
+
+	entries := []models.CalendarEntry{}
+	if err := q.db.SelectContext(ctx, &entries, query, w.args...); err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		switch entries[i].Kind {
+		case models.CalendarPublish, models.CalendarPremiere, models.CalendarFestival:
+			entries[i].Reschedulable = true
+		}
+	}
+	return entries, nil
+}
+
+// CreateReleaseEvent schedules a publish, premiere or festival window. It
+// returns sql.ErrNoRows for a publish when the film already has one pending.
+func (q *Queries) CreateReleaseEvent(ctx context.Context, e *models.ReleaseEvent) error {
+	query := `
+		INSERT INTO release_events (film_id, kind, name, starts_at, ends_at, created_by_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, e, query, e.FilmID, e.Kind, e.Name, e.StartsAt, e.EndsAt, e.CreatedByID)
+}
+
+// GetReleaseEvent retrieves a release event by ID
+func (q *Queries) GetReleaseEvent(ctx context.Context, id uuid.UUID) (*models.ReleaseEvent, error) {
+	var e models.ReleaseEvent
+	if err := q.db.GetContext(ctx, &e, `SELECT * FROM release_events WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// RescheduleReleaseEvent moves a release event and clears any failure
+// from an earlier publish attempt. It returns sql.ErrNoRows if the event
+// does not exist or is a publish that already ran.
+func (q *Queries) RescheduleReleaseEvent(ctx context.Context, e *models.ReleaseEvent) error {
+	query := `
+		UPDATE release_events
+		SET starts_at = $2, ends_at = $3, last_error = NULL
+		WHERE id = $1 AND published_at IS NULL
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, e, query, e.ID, e.StartsAt, e.EndsAt)
+}
+
+// DeleteReleaseEvent removes a release event, returning sql.ErrNoRows if
+// it does not exist
+func (q *Queries) DeleteReleaseEvent(ctx context.Context, id uuid.UUID) error {
+	result, err := q.db.ExecContext(ctx, `DELETE FROM release_events WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListDueReleases returns scheduled publishes whose time has come and that
+// have neither run nor failed, oldest first
+func (q *Queries) ListDueReleases(ctx context.Context, at time.Time, limit int) ([]models.ReleaseEvent, error) {
+	events := []models.ReleaseEvent{}
+	query := `
+		SELECT * FROM release_events
+		WHERE kind = 'PUBLISH' AND starts_at <= $1
+		  AND published_at IS NULL AND last_error IS NULL
+		ORDER BY starts_at
+		LIMIT $2
+	`
+	err := q.db.SelectContext(ctx, &events, query, at, limit)
+	return events, err
+}
+
+// PublishScheduledRelease publishes a scheduled release's film and marks
+// the release as run in one transaction
+func (q *Queries) PublishScheduledRelease(ctx context.Context, e *models.ReleaseEvent) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := q.PublishFilm(ctx, tx, e.FilmID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE release_events SET published_at = NOW() WHERE id = $1`, e.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FailScheduledRelease records why a scheduled publish could not run; it
+// is retried once rescheduled
+func (q *Queries) FailScheduledRelease(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE release_events SET last_error = $2 WHERE id = $1`, id, reason)
+	return err
+}
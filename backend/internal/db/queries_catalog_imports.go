@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== CATALOG IMPORT QUERIES ==========
+
+const catalogImportColumns = `
+	i.*,
+	(SELECT COUNT(*) FROM catalog_import_items it WHERE it.import_id = i.id AND it.status = 'QUEUED') AS queued,
+	(SELECT COUNT(*) FROM catalog_import_items it WHERE it.import_id = i.id AND it.status = 'FAILED') AS failed
+`
+
+const catalogImportItemColumns = `it.*, f.status AS film_status`
+
+// CreateCatalogImport stores an import and its items, in order
+func (q *Queries) CreateCatalogImport(ctx context.Context, imp *models.CatalogImport, items []models.CatalogImportItem) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.GetContext(ctx, imp, `
+		INSERT INTO catalog_imports (id, created_by_id, tenant_id, film_type, total)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING *, 0 AS queued, 0 AS failed
+	`, imp.ID, imp.CreatedByID, imp.TenantID, imp.FilmType, len(items))
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		item := &items[i]
+		err := tx.GetContext(ctx, item, `
+			INSERT INTO catalog_import_items (id, import_id, position, source_url, provider)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING *
+		`, item.ID, imp.ID, item.Position, item.SourceURL, item.Provider)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetCatalogImport returns an import with its item counts
+func (q *Queries) GetCatalogImport(ctx context.Context, id uuid.UUID) (*models.CatalogImport, error) {
+	var imp models.CatalogImport
+	query := `SELECT ` + catalogImportColumns + ` FROM catalog_imports i WHERE i.id = $1`
+	if err := q.db.GetContext(ctx, &imp, query, id); err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// ListCatalogImports returns a creator's imports, most recent first
+func (q *Queries) ListCatalogImports(ctx context.Context, userID uuid.UUID, limit int) ([]models.CatalogImport, error) {
+	imports := []models.CatalogImport{}
+	query := `
+		SELECT ` + catalogImportColumns + `
+		FROM catalog_imports i
+		WHERE i.created_by_id = $1
+		ORDER BY i.created_at DESC
+		LIMIT $2
+	`
+	err := q.db.SelectContext(ctx, &imports, query, userID, limit)
+	return imports, err
+}
+
+// ListCatalogImportItems returns an import's items in order, with the
+// status of the films created for them
+func (q *Queries) ListCatalogImportItems(ctx context.Context, importID uuid.UUID) ([]models.CatalogImportItem, error) {
+	items := []models.CatalogImportItem{}
+	query := `
+		SELECT ` + catalogImportItemColumns + `
+		FROM catalog_import_items it
+		LEFT JOIN films f ON f.id = it.film_id
+		WHERE it.import_id = $1
+		ORDER BY it.position
+	`
+	err := q.db.SelectContext(ctx, &items, query, importID)
+	return items, err
+}
+
+// GetCatalogImportItem returns one import item
+func (q *Queries) GetCatalogImportItem(ctx context.Context, id uuid.UUID) (*models.CatalogImportItem, error) {
+	var item models.CatalogImportItem
+	query := `
+		SELECT ` + catalogImportItemColumns + `
+		FROM catalog_import_items it
+		LEFT JOIN films f ON f.id = it.film_id
+		WHERE it.id = $1
+	`
+	if err := q.db.GetContext(ctx, &item, query, id); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateCatalogImportItem stores an item's progress
+func (q *Queries) UpdateCatalogImportItem(ctx context.Context, item *models.CatalogImportItem) error {
+	query := `UPDATE catalog_import_items SET status = $2, film_id = $3, title = $4, error = $5 WHERE id = $1`
+	_, err := q.db.ExecContext(ctx, query, item.ID, item.Status, item.FilmID, item.Title, item.Error)
+	return err
+}
+
+// ResetFailedCatalogImportItems puts an import's failed items back to
+// pending and returns them
+func (q *Queries) ResetFailedCatalogImportItems(ctx context.Context, importID uuid.UUID) ([]models.CatalogImportItem, error) {
+	items := []models.CatalogImportItem{}
+	query := `
+		UPDATE catalog_import_items
+		SET status = 'PENDING', error = NULL
+		WHERE import_id = $1 AND status = 'FAILED'
+		RETURNING *
+	`
+	err := q.db.SelectContext(ctx, &items, query, importID)
+	return items, err
+}
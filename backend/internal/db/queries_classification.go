@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== CONTENT CLASSIFICATION QUERIES ==========
+
+// RecordContentClassification stores a classification of a film. Scores
+// must be set, to {} when nothing was scored.
+func (q *Queries) RecordContentClassification(ctx context.Context, c *models.ContentClassification) error {
+	query := `
+		INSERT INTO content_classifications (film_id, classifier, status, frames, scores, label, action, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, c, query,
+		c.FilmID, c.Classifier, c.Status, c.Frames, c.Scores, c.Label, c.Action, c.Error,
+	)
+}
+
+// ListContentClassifications returns a film's classifications, newest
+// first
+func (q *Queries) ListContentClassifications(ctx context.Context, filmID uuid.UUID) ([]models.ContentClassification, error) {
+	classifications := []models.ContentClassification{}
+	query := `
+		SELECT * FROM content_classifications
+		WHERE film_id = $1
+		ORDER BY created_at DESC
+	`
+	err := q.db.SelectContext(ctx, &classifications, query, filmID)
+	return classifications, err
+}
@@ -0,0 +1,577 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ========== COMMENT QUERIES ==========
+
+// CommentSortOrders maps ListComments sort options to ORDER BY clauses.
+// "top" puts the most discussed threads first.
+var CommentSortOrders = map[string]string{
+	"newest": "c.created_at DESC, c.id DESC",
+	"top":    "c.reply_count DESC, c.created_at DESC, c.id DESC",
+}
+
+// DefaultCommentSort is used when no sort option is given
+const DefaultCommentSort = "newest"
+
+// commentSelect selects comments (alias c) with their author
+const commentSelect = `
+	SELECT c.*, COALESCE(u.name, '') AS author_name, COALESCE(u.avatar_url, '') AS author_avatar_url
+	FROM comments c
+	JOIN users u ON u.id = c.user_id
+`
+
+// CreateComment adds a comment or reply to a ready film with the comment's
+// status (visible by default) and updates the film's and parent's counts.
+// It returns sql.ErrNoRows if the film is not ready. The parent must be a
+// top-level comment on the same film.
+func (q *Queries) CreateComment(ctx context.Context, comment *models.Comment) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockReadyFilm(ctx, tx, comment.FilmID); err != nil {
+		return err
+	}
+
+	if comment.Status == "" {
+		comment.Status = models.CommentVisible
+	}
+	query := `
+		INSERT INTO comments (film_id, user_id, parent_id, body, status, spam_reason)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`
+	if err := tx.GetContext(ctx, comment, query, comment.FilmID, comment.UserID, comment.ParentID, comment.Body, comment.Status, comment.SpamReason); err != nil {
+		return err
+	}
+	if comment.Status == models.CommentVisible {
+		if err := shiftCommentCounts(ctx, tx, comment, 1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetComment retrieves a comment with its author
+func (q *Queries) GetComment(ctx context.Context, id uuid.UUID) (*models.Comment, error) {
+	var comment models.Comment
+	if err := q.db.GetContext(ctx, &comment, commentSelect+`WHERE c.id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// visibleTo matches comments (alias c) a viewer sees: visible ones and
+// their own shadow-held ones. uuid.Nil is a signed-out viewer.
+const visibleTo = `(c.status = 'VISIBLE' OR (c.status = 'SHADOW_HELD' AND c.user_id = $2))`
+
+// ListComments lists a film's top-level comments visible to viewerID, the
+// pinned one first and the rest in a CommentSortOrders order
+func (q *Queries) ListComments(ctx context.Context, filmID, viewerID uuid.UUID, sort string, offset, limit int) ([]models.Comment, error) {
+	orderBy, ok := CommentSortOrders[sort]
+	if !ok {
+		orderBy = CommentSortOrders[DefaultCommentSort]
+	}
+
+	comments := []models.Comment{}
+	query := commentSelect + `
+		WHERE c.film_id = $1 AND c.parent_id IS NULL AND ` + visibleTo + `
+		ORDER BY c.pinned_at IS NULL, ` + orderBy + `
+		OFFSET $3 LIMIT $4
+	`
+	err := q.db.SelectContext(ctx, &comments, query, filmID, viewerID, offset, limit)
+	return comments, err
+}
+
+// CountComments returns how many top-level comments on a film are visible
+// to viewerID
+func (q *Queries) CountComments(ctx context.Context, filmID, viewerID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM comments c WHERE c.film_id = $1 AND c.parent_id IS NULL AND ` + visibleTo
+	err := q.db.GetContext(ctx, &count, query, filmID, viewerID)
+	return count, err
+}
+
+// ListReplies lists the replies to a comment visible to viewerID, oldest
+// first
+func (q *Queries) ListReplies(ctx context.Context, parentID, viewerID uuid.UUID, offset, limit int) ([]models.Comment, error) {
+	replies := []models.Comment{}
+	query := commentSelect + `
+		WHERE c.parent_id = $1 AND ` + visibleTo + `
+		ORDER BY c.created_at, c.id
+		OFFSET $3 LIMIT $4
+	`
+	err := q.db.SelectContext(ctx, &replies, query, parentID, viewerID, offset, limit)
+	return replies, err
+}
+
+// UpdateCommentBody replaces a comment's text and marks it edited
+func (q *Queries) UpdateCommentBody(ctx context.Context, id uuid.UUID, body string) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE comments SET body = $2, edited_at = NOW() WHERE id = $1`, id, body)
+	return err
+}
+
+// DeleteComment removes a comment with its replies and updates the film's
+// and parent's counts, returning sql.ErrNoRows if it does not exist
+func (q *Queries) DeleteComment(ctx context.Context, id uuid.UUID) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	comment, err := lockComment(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM comments WHERE id = $1`, id); err != nil {
+		return err
+	}
+	if comment.Status == models.CommentVisible {
+		if err := shiftCommentCounts(ctx, tx, comment, -1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ModerateComment sets a comment's status, resolving its open reports, and
+// updates the film's and parent's counts. A comment that stops being
+// visible is unpinned, and one made visible loses its spam reason. It
+// returns sql.ErrNoRows for an unknown comment.
+func (q *Queries) ModerateComment(ctx context.Context, id, moderatorID uuid.UUID, status models.CommentStatus) (*models.Comment, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	comment, err := lockComment(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	wasVisible := comment.Status == models.CommentVisible
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM comment_reports WHERE comment_id = $1`, id); err != nil {
+		return nil, err
+	}
+	query := `
+		UPDATE comments
+		SET status = $2, report_count = 0, moderated_by_id = $3, moderated_at = NOW(),
+		    pinned_at = CASE WHEN $2 = 'VISIBLE' THEN pinned_at END,
+		    spam_reason = CASE WHEN $2 = 'VISIBLE' THEN NULL ELSE spam_reason END
+		WHERE id = $1
+		RETURNING *
+	`
+	if err := tx.GetContext(ctx, comment, query, id, status, moderatorID); err != nil {
+		return nil, err
+	}
+
+	if isVisible := comment.Status == models.CommentVisible; isVisible != wasVisible {
+		sign := 1
+		if wasVisible {
+			sign = -1
+		}
+		if err := shiftCommentCounts(ctx, tx, comment, sign); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// ShadowHoldComment holds a comment as suspected spam for the reason
+// given, updating the film's and parent's counts if it was visible and
+// unpinning it
+func (q *Queries) ShadowHoldComment(ctx context.Context, id uuid.UUID, reason string) (*models.Comment, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	comment, err := lockComment(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	wasVisible := comment.Status == models.CommentVisible
+
+	query := `
+		UPDATE comments SET status = 'SHADOW_HELD', spam_reason = $2, pinned_at = NULL
+		WHERE id = $1
+		RETURNING *
+	`
+	if err := tx.GetContext(ctx, comment, query, id, reason); err != nil {
+		return nil, err
+	}
+	if wasVisible {
+		if err := shiftCommentCounts(ctx, tx, comment, -1); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// PinComment pins a top-level comment to the top of its film's comments,
+// replacing any pinned one
+func (q *Queries) PinComment(ctx context.Context, filmID, commentID uuid.UUID) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Unpin first: a film's pinned comment is unique
+	query := `UPDATE comments SET pinned_at = NULL WHERE film_id = $1 AND pinned_at IS NOT NULL AND id <> $2`
+	if _, err := tx.ExecContext(ctx, query, filmID, commentID); err != nil {
+		return err
+	}
+	query = `UPDATE comments SET pinned_at = NOW() WHERE id = $2 AND film_id = $1`
+	if _, err := tx.ExecContext(ctx, query, filmID, commentID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UnpinComment unpins a film's pinned comment, if any
+func (q *Queries) UnpinComment(ctx context.Context, filmID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE comments SET pinned_at = NULL WHERE film_id = $1 AND pinned_at IS NOT NULL`, filmID)
+	return err
+}
+
+// ReportComment records a user's report of a comment; reporting twice has
+// no effect
+func (q *Queries) ReportComment(ctx context.Context, commentID, userID uuid.UUID, category models.ReportCategory, reason string) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO comment_reports (comment_id, user_id, category, reason) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (comment_id, user_id) DO NOTHING
+	`, commentID, userID, category, reason)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE comments SET report_count = report_count + 1 WHERE id = $1`, commentID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListReportedComments lists comments with open reports, most recently
+// reported first. The actor is the reporter and the range applies to
+// report times; last_reported_at is the latest matching report.
+func (q *Queries) ListReportedComments(ctx context.Context, filter AdminListFilter, after *AdminCursor, offset, limit int) ([]models.ReportedComment, error) {
+	reports := &whereBuilder{}
+	reports.add("cr.comment_id = c.id")
+	filter.applyActorAndRange(reports, "cr.user_id", "cr.created_at")
+
+	w := &whereBuilder{args: reports.args}
+	w.add("c.report_count > 0")
+	order := adminOrder(w, "r.last_reported_at", "c.id", after, offset, limit)
+
+	comments := []models.ReportedComment{}
+	query := commentSelect + `
+		JOIN LATERAL (
+			SELECT MAX(cr.created_at) AS last_reported_at
+			FROM comment_reports cr
+			` + reports.sql() + `
+		) r ON r.last_reported_at IS NOT NULL
+		` + w.sql() + `
+		` + order
+	err := q.db.SelectContext(ctx, &comments, query, w.args...)
+	return comments, err
+}
+
+// CountReportedComments returns how many comments have open reports
+// matching the filter
+func (q *Queries) CountReportedComments(ctx context.Context, filter AdminListFilter) (int, error) {
+	w := &whereBuilder{}
+	w.add("cr.comment_id = c.id")
+	filter.applyActorAndRange(w, "cr.user_id", "cr.created_at")
+
+	var count int
+	query := `
+		SELECT COUNT(*) FROM comments c
+		WHERE c.report_count > 0
+		  AND EXISTS (SELECT 1 FROM comment_reports cr ` + w.sql() + `)
+	`
+	err := q.db.GetContext(ctx, &count, query, w.args...)
+	return count, err
+}
+
+// ListHeldComments lists a film's comments awaiting approval, held or
+// shadow-held as suspected spam, oldest first
+func (q *Queries) ListHeldComments(ctx context.Context, filmID uuid.UUID, offset, limit int) ([]models.Comment, error) {
+	comments := []models.Comment{}
+	query := commentSelect + `
+		WHERE c.film_id = $1 AND c.status IN ('HELD', 'SHADOW_HELD')
+		ORDER BY c.created_at, c.id
+		OFFSET $2 LIMIT $3
+	`
+	err := q.db.SelectContext(ctx, &comments, query, filmID, offset, limit)
+	return comments, err
+}
+
+// CountHeldComments returns how many of a film's comments await approval
+func (q *Queries) CountHeldComments(ctx context.Context, filmID uuid.UUID) (int, error) {
+	var count int
+	err := q.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM comments WHERE film_id = $1 AND status IN ('HELD', 'SHADOW_HELD')`, filmID)
+	return count, err
+}
+
+// SetCommentMentions replaces the users a comment mentions. Users it still
+// mentions keep their notified state.
+func (q *Queries) SetCommentMentions(ctx context.Context, commentID uuid.UUID, userIDs []uuid.UUID) error {
+	ids := make(pq.StringArray, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM comment_mentions WHERE comment_id = $1 AND NOT (user_id = ANY($2::uuid[]))`
+	if _, err := tx.ExecContext(ctx, query, commentID, ids); err != nil {
+		return err
+	}
+	query = `
+		INSERT INTO comment_mentions (comment_id, user_id)
+		SELECT $1::uuid, unnest($2::uuid[])
+		ON CONFLICT (comment_id, user_id) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, query, commentID, ids); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ClaimCommentMentions marks a comment's not yet notified mentions
+// notified and returns the mentioned users
+func (q *Queries) ClaimCommentMentions(ctx context.Context, commentID uuid.UUID) ([]uuid.UUID, error) {
+	userIDs := []uuid.UUID{}
+	query := `
+		UPDATE comment_mentions SET notified_at = NOW()
+		WHERE comment_id = $1 AND notified_at IS NULL
+		RETURNING user_id
+	`
+	err := q.db.SelectContext(ctx, &userIDs, query, commentID)
+	return userIDs, err
+}
+
+// lockComment locks a comment and its film, film first like the other
+// comment writes, and returns the comment
+func lockComment(ctx context.Context, tx *sqlx.Tx, commentID uuid.UUID) (*models.Comment, error) {
+	var filmID uuid.UUID
+	if err := tx.GetContext(ctx, &filmID, `SELECT film_id FROM comments WHERE id = $1`, commentID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM films WHERE id = $1 FOR UPDATE`, filmID); err != nil {
+		return nil, err
+	}
+
+	var comment models.Comment
+	if err := tx.GetContext(ctx, &comment, `SELECT * FROM comments WHERE id = $1 FOR UPDATE`, commentID); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// shiftCommentCounts adds (sign 1) or removes (sign -1) a comment that
+// becomes visible or stops being visible. A top-level comment carries its
+// visible replies with it; a reply counts towards the film only while its
+// parent is visible.
+func shiftCommentCounts(ctx context.Context, tx *sqlx.Tx, comment *models.Comment, sign int) error {
+	filmDelta := sign * (1 + comment.ReplyCount)
+	if comment.ParentID != nil {
+		var parentVisible bool
+		query := `UPDATE comments SET reply_count = GREATEST(reply_count + $2, 0) WHERE id = $1 RETURNING status = 'VISIBLE'`
+		if err := tx.QueryRowContext(ctx, query, *comment.ParentID, sign).Scan(&parentVisible); err != nil {
+			return err
+		}
+		if !parentVisible {
+			return nil
+		}
+		filmDelta = sign
+	}
+
+	_, err := tx.ExecContext(ctx,
+		`UPDATE films SET comment_count = GREATEST(comment_count + $2, 0) WHERE id = $1`, comment.FilmID, filmDelta,
+	)
+	return err
+}
+
+// ListCommentArchiveIDs maps the archive id of every comment on a film to
+// the archive id of its top-level comment ("" for a top-level comment). A
+// comment's archive id is the id it had on its original platform, or its
+// own id for comments written here.
+func (q *Queries) ListCommentArchiveIDs(ctx context.Context, filmID uuid.UUID) (map[string]string, error) {
+	query := `
+		SELECT COALESCE(c.external_id, c.id::text) AS id,
+		       COALESCE(p.external_id, p.id::text, '') AS root_id
+		FROM comments c
+		LEFT JOIN comments p ON p.id = c.parent_id
+		WHERE c.film_id = $1
+	`
+	rows, err := q.db.QueryContext(ctx, query, filmID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]string)
+	for rows.Next() {
+		var id, rootID string
+		if err := rows.Scan(&id, &rootID); err != nil {
+			return nil, err
+		}
+		ids[id] = rootID
+	}
+	return ids, rows.Err()
+}
+
+// ImportComments stores validated comments on a film in one transaction,
+// top-level comments before replies. Authors become ghost users owned by
+// ownerID, one per display name, reused across imports. Comments already
+// imported are skipped; it returns how many were imported and skipped.
+func (q *Queries) ImportComments(ctx context.Context, filmID, ownerID uuid.UUID, comments []models.CommentImport) (imported, skipped int, err error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	ghosts := make(map[string]uuid.UUID)
+	roots := make(map[string]uuid.UUID)
+
+	for _, comment := range comments {
+		authorID, ok := ghosts[comment.Author]
+		if !ok {
+			query := `
+				INSERT INTO users (email, password_hash, role, name, ghost_owner_id)
+				VALUES ('ghost-' || uuid_generate_v4() || '@ghost.filmtube.invalid', '', 'USER', $2, $1)
+				ON CONFLICT (ghost_owner_id, name) WHERE ghost_owner_id IS NOT NULL
+				DO UPDATE SET name = EXCLUDED.name
+				RETURNING id
+			`
+			if err := tx.QueryRowContext(ctx, query, ownerID, comment.Author).Scan(&authorID); err != nil {
+				return 0, 0, err
+			}
+			ghosts[comment.Author] = authorID
+		}
+
+		var parentID *uuid.UUID
+		if comment.RootID != "" {
+			rootID, ok := roots[comment.RootID]
+			if !ok {
+				query := `
+					SELECT id FROM comments
+					WHERE film_id = $1 AND COALESCE(external_id, id::text) = $2
+				`
+				if err := tx.QueryRowContext(ctx, query, filmID, comment.RootID).Scan(&rootID); err != nil {
+					return 0, 0, err
+				}
+				roots[comment.RootID] = rootID
+			}
+			parentID = &rootID
+		}
+
+		var id uuid.UUID
+		query := `
+			INSERT INTO comments (film_id, user_id, parent_id, body, external_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6)
+			ON CONFLICT (film_id, external_id) WHERE external_id IS NOT NULL DO NOTHING
+			RETURNING id
+		`
+		err := tx.QueryRowContext(ctx, query,
+			filmID, authorID, parentID, comment.Body, comment.ExternalID, comment.CreatedAt,
+		).Scan(&id)
+		if err == sql.ErrNoRows {
+			// Imported concurrently since the archive was validated
+			skipped++
+			continue
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if comment.RootID == "" {
+			roots[comment.ExternalID] = id
+		}
+		inserted := &models.Comment{FilmID: filmID, ParentID: parentID}
+		if err := shiftCommentCounts(ctx, tx, inserted, 1); err != nil {
+			return 0, 0, err
+		}
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return imported, skipped, nil
+}
+
+// ListCommentsForExport returns a film's comments as archive entries,
+// oldest first
+func (q *Queries) ListCommentsForExport(ctx context.Context, filmID uuid.UUID) ([]models.ArchivedComment, error) {
+	query := `
+		SELECT COALESCE(c.external_id, c.id::text) AS id,
+		       COALESCE(p.external_id, p.id::text, '') AS parent_id,
+		       COALESCE(NULLIF(u.name, ''), 'Unknown') AS author,
+		       c.body, c.created_at
+		FROM comments c
+		JOIN users u ON u.id = c.user_id
+		LEFT JOIN comments p ON p.id = c.parent_id
+		WHERE c.film_id = $1
+		ORDER BY c.created_at, c.id
+	`
+	rows, err := q.db.QueryContext(ctx, query, filmID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []models.ArchivedComment{}
+	for rows.Next() {
+		var comment models.ArchivedComment
+		var createdAt time.Time
+		if err := rows.Scan(&comment.ID, &comment.ParentID, &comment.Author, &comment.Body, &createdAt); err != nil {
+			return nil, err
+		}
+		comment.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== EGRESS QUERIES ==========
+
+// egressBytes is the bytes a film delivered on a day: the CDN count when
+// one was ingested, otherwise the estimate
+const egressBytes = `COALESCE(e.cdn_bytes, e.estimated_bytes)`
+
+// ListEgressByDay returns the bytes delivered per day in [from, to), by
+// all films or only those of one creator
+func (q *Queries) ListEgressByDay(ctx context.Context, creatorID *uuid.UUID, from, to time.Time) ([]models.EgressDay, error) {
+	days := []models.EgressDay{}
+	query := `
+		SELECT e.day::timestamptz AS day,
+		       SUM(e.estimated_bytes)::bigint AS estimated_bytes,
+		       SUM(e.cdn_bytes)::bigint AS cdn_bytes,
+		       SUM(` + egressBytes + `)::bigint AS bytes
+		FROM film_egress_daily e
+		JOIN films f ON f.id = e.film_id
+		WHERE ($1::uuid IS NULL OR f.created_by_id = $1)
+		  AND e.day >= $2::date AND e.day < $3::date
+		GROUP BY e.day
+		ORDER BY e.day
+	`
+	err := q.db.SelectContext(ctx, &days, query, creatorID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	return days, err
+}
+
+// ListFilmEgress returns the bytes each film delivered in [from, to), most
+// first, for all films or only those of one creator. limit <= 0 returns
+// every film.
+func (q *Queries) ListFilmEgress(ctx context.Context, creatorID *uuid.UUID, from, to time.Time, limit int) ([]models.FilmEgress, error) {
+	films := []models.FilmEgress{}
+	query := `
+		SELECT e.film_id, f.title, f.created_by_id AS creator_id,
+		       SUM(e.estimated_bytes)::bigint AS estimated_bytes,
+		       SUM(e.cdn_bytes)::bigint AS cdn_bytes,
+		       SUM(` + egressBytes + `)::bigint AS bytes
+		FROM film_egress_daily e
+		JOIN films f ON f.id = e.film_id
+		WHERE ($1::uuid IS NULL OR f.created_by_id = $1)
+		  AND e.day >= $2::date AND e.day < $3::date
+		GROUP BY e.film_id, f.title, f.created_by_id
+		ORDER BY bytes DESC, e.film_id
+		LIMIT NULLIF($4, 0)
+	`
+	if limit < 0 {
+		limit = 0
+	}
+	err := q.db.SelectContext(ctx, &films, query, creatorID, from.Format("2006-01-02"), to.Format("2006-01-02"), limit)
+	return films, err
+}
+
+// ListCreatorEgress returns the bytes each creator's films delivered in
+// [from, to), most first
+func (q *Queries) ListCreatorEgress(ctx context.Context, from, to time.Time, limit int) ([]models.CreatorEgress, error) {
+	creators := []models.CreatorEgress{}
+	query := `
+		SELECT f.created_by_id AS creator_id, u.name,
+		       COUNT(DISTINCT e.film_id) AS films,
+		       SUM(` + egressBytes + `)::bigint AS bytes
+		FROM film_egress_daily e
+		JOIN films f ON f.id = e.film_id
+		JOIN users u ON u.id = f.created_by_id
+		WHERE e.day >= $1::date AND e.day < $2::date
+		GROUP BY f.created_by_id, u.name
+		ORDER BY bytes DESC, f.created_by_id
+		LIMIT $3
+	`
+	err := q.db.SelectContext(ctx, &creators, query, from.Format("2006-01-02"), to.Format("2006-01-02"), limit)
+	return creators, err
+}
+
+// RecordCDNEgress stores per-film daily byte counts from CDN logs,
+// replacing earlier counts for the same film and day. Films that no longer
+// exist are skipped. Returns the number of counts stored.
+func (q *Queries) RecordCDNEgress(ctx context.Context, records []models.CDNEgress) (int64, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var stored int64
+	for _, r := range records {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO film_egress_daily (day, film_id, cdn_bytes)
+			SELECT $1::date, id, $3 FROM films WHERE id = $2
+			ON CONFLICT (day, film_id) DO UPDATE
+			SET cdn_bytes = EXCLUDED.cdn_bytes
+		`, r.Day, r.FilmID, r.Bytes)
+		if err != nil {
+			return 0, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		stored += n
+	}
+
+	return stored, tx.Commit()
+}
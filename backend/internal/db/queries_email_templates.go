@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== EMAIL TEMPLATE QUERIES ==========
+
+// ListEmailTemplates returns the template overrides of an organization or
+// creator, by key
+func (q *Queries) ListEmailTemplates(ctx context.Context, scope models.EmailTemplateScope) ([]models.EmailTemplate, error) {
+	templates := []models.EmailTemplate{}
+	query := `
+		SELECT * FROM email_templates
+		WHERE tenant_id IS NOT DISTINCT FROM $1 AND creator_id IS NOT DISTINCT FROM $2
+		ORDER BY template_key
+	`
+	err := q.db.SelectContext(ctx, &templates, query, scope.TenantID, scope.CreatorID)
+	return templates, err
+}
+
+// GetEmailTemplate retrieves an organization's or creator's override of
+// one template
+func (q *Queries) GetEmailTemplate(ctx context.Context, key string, scope models.EmailTemplateScope) (*models.EmailTemplate, error) {
+	var template models.EmailTemplate
+	query := `
+		SELECT * FROM email_templates
+		WHERE template_key = $1 AND tenant_id IS NOT DISTINCT FROM $2 AND creator_id IS NOT DISTINCT FROM $3
+	`
+	if err := q.db.GetContext(ctx, &template, query, key, scope.TenantID, scope.CreatorID); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// UpsertEmailTemplate creates or replaces an override of a template
+func (q *Queries) UpsertEmailTemplate(ctx context.Context, template *models.EmailTemplate) error {
+	conflict := `(template_key, creator_id) WHERE creator_id IS NOT NULL`
+	if template.TenantID != nil {
+		conflict = `(template_key, tenant_id) WHERE tenant_id IS NOT NULL`
+	}
+	query := `
+		INSERT INTO email_templates (template_key, tenant_id, creator_id, format, subject, body, html, text_body, updated_by_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT ` + conflict + ` DO UPDATE
+		SET format = EXCLUDED.format,
+		    subject = EXCLUDED.subject,
+		    body = EXCLUDED.body,
+		    html = EXCLUDED.html,
+		    text_body = EXCLUDED.text_body,
+		    updated_by_id = EXCLUDED.updated_by_id
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, template, query,
+		template.Key, template.TenantID, template.CreatorID, template.Format,
+		template.Subject, template.Body, template.HTML, template.TextBody, template.UpdatedByID,
+	)
+}
+
+// DeleteEmailTemplate removes an override so the system template is sent
+// again. It reports whether there was one.
+func (q *Queries) DeleteEmailTemplate(ctx context.Context, key string, scope models.EmailTemplateScope) (bool, error) {
+	result, err := q.db.ExecContext(ctx, `
+		DELETE FROM email_templates
+		WHERE template_key = $1 AND tenant_id IS NOT DISTINCT FROM $2 AND creator_id IS NOT DISTINCT FROM $3
+	`, key, scope.TenantID, scope.CreatorID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ResolveEmailTemplate returns the override of a template that brands
+// email about a film of an organization and creator: the organization's
+// if it has one, else the creator's. tenantID may be nil.
+func (q *Queries) ResolveEmailTemplate(ctx context.Context, key string, tenantID *uuid.UUID, creatorID uuid.UUID) (*models.EmailTemplate, error) {
+	var template models.EmailTemplate
+	query := `
+		SELECT * FROM email_templates
+		WHERE template_key = $1 AND (tenant_id = $2 OR creator_id = $3)
+		ORDER BY tenant_id IS NULL
+		LIMIT 1
+	`
+	if err := q.db.GetContext(ctx, &template, query, key, tenantID, creatorID); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== PLAYBACK PLAN & ENTITLEMENT QUERIES ==========
+
+// GetTenantPlanCatalog returns an organization's own plan catalog;
+// sql.ErrNoRows when it uses the platform's
+func (q *Queries) GetTenantPlanCatalog(ctx context.Context, tenantID uuid.UUID) (*models.TenantPlanCatalog, error) {
+	var catalog models.TenantPlanCatalog
+	err := q.db.GetContext(ctx, &catalog, `SELECT * FROM tenant_playback_plans WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// SetTenantPlanCatalog creates or replaces an organization's plan catalog
+func (q *Queries) SetTenantPlanCatalog(ctx context.Context, tenantID uuid.UUID, catalog json.RawMessage, updatedByID uuid.UUID) (*models.TenantPlanCatalog, error) {
+	var saved models.TenantPlanCatalog
+	query := `
+		INSERT INTO tenant_playback_plans (tenant_id, catalog, updated_by_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE SET catalog = EXCLUDED.catalog, updated_by_id = EXCLUDED.updated_by_id
+		RETURNING *
+	`
+	err := q.db.GetContext(ctx, &saved, query, tenantID, []byte(catalog), updatedByID)
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// DeleteTenantPlanCatalog returns an organization to the platform's plans;
+// sql.ErrNoRows when it had none of its own
+func (q *Queries) DeleteTenantPlanCatalog(ctx context.Context, tenantID uuid.UUID) error {
+	result, err := q.db.ExecContext(ctx, `DELETE FROM tenant_playback_plans WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListActiveEntitlements returns a user's unexpired entitlements that apply
+// to a film of the tenant, or platform films for a nil tenant: the
+// tenant's first, then the platform-wide one
+func (q *Queries) ListActiveEntitlements(ctx context.Context, userID uuid.UUID, tenantID *uuid.UUID) ([]models.Entitlement, error) {
+	entitlements := []models.Entitlement{}
+	query := `
+		SELECT * FROM user_entitlements
+		WHERE user_id = $1
+		  AND (tenant_id IS NULL OR tenant_id = $2)
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY tenant_id NULLS LAST
+	`
+	err := q.db.SelectContext(ctx, &entitlements, query, userID, tenantID)
+	return entitlements, err
+}
+
+// ListUserEntitlements returns all of a user's entitlements, expired ones
+// included
+func (q *Queries) ListUserEntitlements(ctx context.Context, userID uuid.UUID) ([]models.Entitlement, error) {
+	entitlements := []models.Entitlement{}
+	query := `SELECT * FROM user_entitlements WHERE user_id = $1 ORDER BY tenant_id NULLS FIRST`
+	err := q.db.SelectContext(ctx, &entitlements, query, userID)
+	return entitlements, err
+}
+
+// ListTenantEntitlements returns the entitlements an organization granted
+func (q *Queries) ListTenantEntitlements(ctx context.Context, tenantID uuid.UUID) ([]models.Entitlement, error) {
+	entitlements := []models.Entitlement{}
+	query := `SELECT * FROM user_entitlements WHERE tenant_id = $1 ORDER BY created_at`
+	err := q.db.SelectContext(ctx, &entitlements, query, tenantID)
+	return entitlements, err
+}
+
+// SetEntitlement creates or replaces a user's entitlement in its scope
+func (q *Queries) SetEntitlement(ctx context.Context, e *models.Entitlement) error {
+	query := `
+		INSERT INTO user_entitlements (user_id, tenant_id, plan_key, expires_at, granted_by_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'::uuid))
+		DO UPDATE SET plan_key = EXCLUDED.plan_key, expires_at = EXCLUDED.expires_at, granted_by_id = EXCLUDED.granted_by_id
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, e, query, e.UserID, e.TenantID, e.PlanKey, e.ExpiresAt, e.GrantedByID)
+}
+
+// DeleteEntitlement removes a user's entitlement in a scope, platform-wide
+// for a nil tenant; sql.ErrNoRows when there is none
+func (q *Queries) DeleteEntitlement(ctx context.Context, userID uuid.UUID, tenantID *uuid.UUID) error {
+	query := `DELETE FROM user_entitlements WHERE user_id = $1 AND tenant_id IS NOT DISTINCT FROM $2`
+	result, err := q.db.ExecContext(ctx, query, userID, tenantID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
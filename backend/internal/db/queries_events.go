@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== FILM EVENT QUERIES ==========
+
+// ListFilmLifecycles returns when films were created, became ready and
+// were published, in film ID order. With filmIDs only those films are
+// returned; otherwise films after afterID (nil to start) up to limit.
+func (q *Queries) ListFilmLifecycles(ctx context.Context, filmIDs []uuid.UUID, afterID *uuid.UUID, limit int) ([]models.FilmLifecycle, error) {
+	w := &whereBuilder{}
+	if len(filmIDs) > 0 {
+		idStrings := make(pq.StringArray, len(filmIDs))
+		for i, id := range filmIDs {
+			idStrings[i] = id.String()
+		}
+		w.add("f.id = ANY(?::uuid[])", idStrings)
+	}
+	if afterID != nil {
+		w.add("f.id > ?", *afterID)
+	}
+
+	query := `
+		SELECT f.id AS film_id, f.created_at, f.published_at,
+		       CASE WHEN f.status = 'READY' THEN COALESCE(tj.completed_at, f.updated_at) END AS ready_at
+		FROM films f
+		LEFT JOIN transcode_jobs tj ON tj.film_id = f.id
+		` + w.sql() + `
+		ORDER BY f.id
+		LIMIT ` + w.arg(limit)
+
+	lifecycles := []models.FilmLifecycle{}
+	err := q.db.SelectContext(ctx, &lifecycles, query, w.args...)
+	return lifecycles, err
+}
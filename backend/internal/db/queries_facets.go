@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// ========== FACET QUERIES ==========
+
+// durationBucket is a facet bucket over films.duration, in seconds
+type durationBucket struct {
+	key      string
+	min, max *int // inclusive; nil is unbounded
+}
+
+func intPtr(v int) *int { return &v }
+
+// durationBuckets are the duration facet buckets, shortest first
+var durationBuckets = []durationBucket{
+	{key: "under_10m", max: intPtr(599)},
+	{key: "10m_to_40m", min: intPtr(600), max: intPtr(2399)},
+	{key: "40m_to_90m", min: intPtr(2400), max: intPtr(5399)},
+	{key: "over_90m", min: intPtr(5400)},
+}
+
+// GetFilmFacets counts films per genre, type and duration bucket for a
+// listing filter. Each facet ignores its own filter.
+func (q *Queries) GetFilmFacets(ctx context.Context, filter FilmFilter) (*models.FilmFacets, error) {
+	facets := &models.FilmFacets{
+		Genres:    []models.FacetCount{},
+		Types:     []models.FacetCount{},
+		Durations: make([]models.DurationFacet, 0, len(durationBuckets)),
+	}
+
+	withoutGenre := filter
+	withoutGenre.Genre = ""
+	where := filmWhere(withoutGenre)
+	where.add("f.genre <> ''")
+	query := `
+		SELECT f.genre AS value, COUNT(*) AS count
+		FROM films f ` + where.sql() + `
+		GROUP BY f.genre
+		ORDER BY count DESC, value
+	`
+	if err := q.db.SelectContext(ctx, &facets.Genres, query, where.args...); err != nil {
+		return nil, err
+	}
+
+	withoutType := filter
+	withoutType.Type = ""
+	where = filmWhere(withoutType)
+	query = `
+		SELECT f.type AS value, COUNT(*) AS count
+		FROM films f ` + where.sql() + `
+		GROUP BY f.type
+		ORDER BY count DESC, value
+	`
+	if err := q.db.SelectContext(ctx, &facets.Types, query, where.args...); err != nil {
+		return nil, err
+	}
+
+	withoutDuration := filter
+	withoutDuration.MinDuration = nil
+	withoutDuration.MaxDuration = nil
+	where = filmWhere(withoutDuration)
+	columns := make([]string, len(durationBuckets))
+	for i, b := range durationBuckets {
+		var conds []string
+		if b.min != nil {
+			conds = append(conds, fmt.Sprintf("f.duration >= %d", *b.min))
+		}
+		if b.max != nil {
+			conds = append(conds, fmt.Sprintf("f.duration <= %d", *b.max))
+		}
+		columns[i] = fmt.Sprintf("COUNT(*) FILTER (WHERE %s)", strings.Join(conds, " AND "))
+	}
+	query = `SELECT ` + strings.Join(columns, ", ") + ` FROM films f ` + where.sql()
+
+	row := q.db.QueryRowxContext(ctx, query, where.args...)
+	counts, err := row.SliceScan()
+	if err != nil {
+		return nil, err
+	}
+	for i, b := range durationBuckets {
+		count, _ := counts[i].(int64)
+		facets.Durations = append(facets.Durations, models.DurationFacet{
+			Bucket:     b.key,
+			MinSeconds: b.min,
+			MaxSeconds: b.max,
+			Count:      int(count),
+		})
+	}
+
+	return facets, nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ========== FEATURED QUERIES ==========
+
+// CreateFeaturedFilm schedules a new hero slot
+func (q *Queries) CreateFeaturedFilm(ctx context.Context, f *models.FeaturedFilm) error {
+	return createFeaturedFilm(ctx, q.db, f)
+}
+
+func createFeaturedFilm(ctx context.Context, ext sqlx.ExtContext, f *models.FeaturedFilm) error {
+	query := `
+		INSERT INTO featured_films (film_id, headline, tagline, starts_at, ends_at, priority, created_by_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING *
+	`
+	return sqlx.GetContext(ctx, ext, f, query,
+		f.FilmID, f.Headline, f.Tagline, f.StartsAt, f.EndsAt, f.Priority, f.CreatedByID,
+	)
+}
+
+// GetFeaturedFilm retrieves a hero slot by ID
+func (q *Queries) GetFeaturedFilm(ctx context.Context, id uuid.UUID) (*models.FeaturedFilm, error) {
+	var f models.FeaturedFilm
+	err := q.db.GetContext(ctx, &f, `SELECT * FROM featured_films WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// UpdateFeaturedFilm saves every editable field of a hero slot
+func (q *Queries) UpdateFeaturedFilm(ctx context.Context, f *models.FeaturedFilm) error {
+	return updateFeaturedFilm(ctx, q.db, f)
+}
+
+func updateFeaturedFilm(ctx context.Context, ext sqlx.ExtContext, f *models.FeaturedFilm) error {
+	query := `
+		UPDATE featured_films
+		SET film_id = $2, headline = $3, tagline = $4, hero_image_key = $5,
+		    starts_at = $6, ends_at = $7, priority = $8
+		WHERE id = $1
+		RETURNING *
+	`
+	return sqlx.GetContext(ctx, ext, f, query,
+		f.ID, f.FilmID, f.Headline, f.Tagline, f.HeroImageKey, f.StartsAt, f.EndsAt, f.Priority,
+	)
+}
+
+// DeleteFeaturedFilm removes a hero slot, returning sql.ErrNoRows if it
+// does not exist
+func (q *Queries) DeleteFeaturedFilm(ctx context.Context, id uuid.UUID) error {
+	return deleteFeaturedFilm(ctx, q.db, id)
+}
+
+func deleteFeaturedFilm(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) error {
+	result, err := ext.ExecContext(ctx, `DELETE FROM featured_films WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListFeaturedFilms returns every hero slot, newest schedule first
+func (q *Queries) ListFeaturedFilms(ctx context.Context) ([]models.FeaturedFilm, error) {
+	slots := []models.FeaturedFilm{}
+	query := `SELECT * FROM featured_films ORDER BY starts_at DESC, priority DESC`
+	err := q.db.SelectContext(ctx, &slots, query)
+	return slots, err
+}
+
+// ListLiveFeaturedFilms returns the hero slots live at the given time whose
+// film is ready and published, highest priority first
+func (q *Queries) ListLiveFeaturedFilms(ctx context.Context, at time.Time) ([]models.FeaturedFilm, error) {
+	slots := []models.FeaturedFilm{}
+	query := `
+		SELECT ff.*
+		FROM featured_films ff
+		JOIN films f ON f.id = ff.film_id
+		WHERE ff.starts_at <= $1
+		  AND (ff.ends_at IS NULL OR ff.ends_at > $1)
+		  AND f.status = 'READY'
+		  AND f.published_at IS NOT NULL
+		ORDER BY ff.priority DESC, ff.starts_at DESC
+	`
+	err := q.db.SelectContext(ctx, &slots, query, at)
+	return slots, err
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== FEED QUERIES ==========
+
+// feedWhere restricts to published, listed films that every country may
+// see, since sitemaps and feeds are shared by all viewers
+func feedWhere() *whereBuilder {
+	w := filmWhere(FilmFilter{Status: models.StatusReady, Region: new(string), Listed: true})
+	w.add("f.published_at IS NOT NULL")
+	return w
+}
+
+// ListFeedFilms returns the most recently published films for RSS,
+// optionally for a single creator
+func (q *Queries) ListFeedFilms(ctx context.Context, creatorID *uuid.UUID, limit int) ([]models.FeedFilm, error) {
+	where := feedWhere()
+	if creatorID != nil {
+		where.add("f.created_by_id = ?", *creatorID)
+	}
+	query := `
+		SELECT f.*, COALESCE(NULLIF(f.attribution, ''), u.name, '') AS creator_name
+		FROM films f
+		LEFT JOIN users u ON f.created_by_id = u.id
+		` + where.sql() + `
+		ORDER BY f.published_at DESC, f.id DESC
+		LIMIT ` + where.arg(limit)
+
+	films := []models.FeedFilm{}
+	err := q.db.SelectContext(ctx, &films, query, where.args...)
+	return films, err
+}
+
+// ListFeedFilmsByIDs returns the given films for RSS, in the order of ids,
+// leaving out any a feed may not carry
+func (q *Queries) ListFeedFilmsByIDs(ctx context.Context, ids []uuid.UUID) ([]models.FeedFilm, error) {
+	films := []models.FeedFilm{}
+	if len(ids) == 0 {
+		return films, nil
+	}
+
+	idStrings := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	where := feedWhere()
+	placeholder := where.arg(idStrings)
+	where.add("f.id = ANY(" + placeholder + "::uuid[])")
+	query := `
+		SELECT f.*, COALESCE(NULLIF(f.attribution, ''), u.name, '') AS creator_name
+		FROM films f
+		LEFT JOIN users u ON f.created_by_id = u.id
+		` + where.sql() + `
+		ORDER BY array_position(` + placeholder + `::uuid[], f.id)`
+
+	err := q.db.SelectContext(ctx, &films, query, where.args...)
+	return films, err
+}
+
+// ListSitemapFilms returns the ids and last change of up to limit
+// published films, most recently published first
+func (q *Queries) ListSitemapFilms(ctx context.Context, limit int) ([]models.SitemapFilm, error) {
+	where := feedWhere()
+	query := `
+		SELECT f.id, f.updated_at
+		FROM films f
+		` + where.sql() + `
+		ORDER BY f.published_at DESC, f.id DESC
+		LIMIT ` + where.arg(limit)
+
+	films := []models.SitemapFilm{}
+	err := q.db.SelectContext(ctx, &films, query, where.args...)
+	return films, err
+}
@@ -0,0 +1,16 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ========== HLS REPAIR QUERIES ==========
+
+// ListReadyFilmIDs returns every transcoded film, oldest first
+func (q *Queries) ListReadyFilmIDs(ctx context.Context) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	err := q.db.SelectContext(ctx, &ids, `SELECT id FROM films WHERE status = 'READY' ORDER BY created_at`)
+	return ids, err
+}
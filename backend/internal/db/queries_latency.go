@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== UPLOAD LATENCY QUERIES ==========
+
+// milestoneColumns are the upload_timings columns of each milestone, in
+// lifecycle order
+var milestoneColumns = []string{
+	models.UploadStarted:   "upload_started_at",
+	models.UploadConfirmed: "confirmed_at",
+	models.UploadQueued:    "queued_at",
+	models.EncodeStarted:   "encode_started_at",
+	models.UploadReady:     "ready_at",
+}
+
+// uploadStageSeconds yields the seconds each latency stage took, per film
+// that became ready between $1 and $2
+const uploadStageSeconds = `
+	SELECT t.ready_at, s.stage, EXTRACT(EPOCH FROM s.ended - s.started) AS seconds
+	FROM upload_timings t
+	CROSS JOIN LATERAL (VALUES
+		('upload', t.upload_started_at, t.confirmed_at),
+		('queue', t.queued_at, t.encode_started_at),
+		('encode', t.encode_started_at, t.ready_at),
+		('total', t.upload_started_at, t.ready_at)
+	) AS s(stage, started, ended)
+	WHERE t.ready_at >= $1 AND t.ready_at < $2
+	  AND s.started IS NOT NULL AND s.ended >= s.started
+`
+
+// uploadLatencyColumns aggregate uploadStageSeconds rows
+const uploadLatencyColumns = `
+	stage,
+	COUNT(*) AS films,
+	AVG(seconds) AS avg_seconds,
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY seconds) AS p50_seconds,
+	percentile_cont(0.9) WITHIN GROUP (ORDER BY seconds) AS p90_seconds,
+	percentile_cont(0.99) WITHIN GROUP (ORDER BY seconds) AS p99_seconds
+`
+
+// RecordUploadMilestone records that a film's upload reached a milestone
+// now. Later milestones and any failure are cleared, since they belong to
+// an earlier attempt.
+func (q *Queries) RecordUploadMilestone(ctx context.Context, filmID uuid.UUID, milestone models.UploadMilestone) error {
+	if int(milestone) < 0 || int(milestone) >= len(milestoneColumns) {
+		return fmt.Errorf("unknown upload milestone %d", milestone)
+	}
+
+	column := milestoneColumns[milestone]
+	sets := []string{fmt.Sprintf("%s = EXCLUDED.%s", column, column), "failed_at = NULL"}
+	for _, later := range milestoneColumns[milestone+1:] {
+		sets = append(sets, later+" = NULL")
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO upload_timings (film_id, %s)
+		VALUES ($1, NOW())
+		ON CONFLICT (film_id) DO UPDATE SET %s
+	`, column, strings.Join(sets, ", "))
+	_, err := q.db.ExecContext(ctx, query, filmID)
+	return err
+}
+
+// RecordUploadFailure records that a film's transcode failed now
+func (q *Queries) RecordUploadFailure(ctx context.Context, filmID uuid.UUID) error {
+	query := `
+		INSERT INTO upload_timings (film_id, failed_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (film_id) DO UPDATE SET failed_at = EXCLUDED.failed_at
+	`
+	_, err := q.db.ExecContext(ctx, query, filmID)
+	return err
+}
+
+// GetUploadLatency summarises each latency stage for films that became
+// ready in [from, to)
+func (q *Queries) GetUploadLatency(ctx context.Context, from, to time.Time) ([]models.UploadLatency, error) {
+	stats := []models.UploadLatency{}
+	query := `
+		SELECT ` + uploadLatencyColumns + `, SUM(seconds) AS sum_seconds
+		FROM (` + uploadStageSeconds + `) stages
+		GROUP BY stage
+	`
+	err := q.db.SelectContext(ctx, &stats, query, from, to)
+	return stats, err
+}
+
+// RollupUploadLatency rebuilds the daily latency rollups of the days from
+// since, so a partial day is completed by later runs
+func (q *Queries) RollupUploadLatency(ctx context.Context, since time.Time) error {
+	query := `
+		INSERT INTO upload_latency_rollups (day, stage, films, avg_seconds, p50_seconds, p90_seconds, p99_seconds)
+		SELECT date_trunc('day', ready_at)::date AS day, ` + uploadLatencyColumns + `
+		FROM (` + uploadStageSeconds + `) stages
+		GROUP BY day, stage
+		ON CONFLICT (day, stage) DO UPDATE SET
+			films = EXCLUDED.films,
+			avg_seconds = EXCLUDED.avg_seconds,
+			p50_seconds = EXCLUDED.p50_seconds,
+			p90_seconds = EXCLUDED.p90_seconds,
+			p99_seconds = EXCLUDED.p99_seconds
+	`
+	day := since.Truncate(24 * time.Hour)
+	_, err := q.db.ExecContext(ctx, query, day, time.Now().Add(time.Minute))
+	return err
+}
+
+// ListUploadLatencyRollups returns the daily latency rollups of days in
+// [from, to), oldest first
+func (q *Queries) ListUploadLatencyRollups(ctx context.Context, from, to time.Time) ([]models.UploadLatencyRollup, error) {
+	rollups := []models.UploadLatencyRollup{}
+	query := `
+		SELECT day, stage, films, avg_seconds, p50_seconds, p90_seconds, p99_seconds
+		FROM upload_latency_rollups
+		WHERE day >= $1::date AND day < $2::date
+		ORDER BY day, stage
+	`
+	err := q.db.SelectContext(ctx, &rollups, query, from, to)
+	return rollups, err
+}
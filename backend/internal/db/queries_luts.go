@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== LUT QUERIES ==========
+
+// lutOwnerClause matches the single LUT slot for a scope; expects the scope
+// as $1 and the owner ID (creator or film, ignored for platform) as $2
+const lutOwnerClause = `
+	scope = $1 AND (
+		(scope = 'platform') OR
+		(scope = 'creator' AND creator_id = $2) OR
+		(scope = 'film' AND film_id = $2)
+	)
+`
+
+// ReplaceLUT stores a LUT, replacing any existing LUT in the same slot
+func (q *Queries) ReplaceLUT(ctx context.Context, lut *models.LUT) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM luts WHERE `+lutOwnerClause, lut.Scope, lutOwnerID(lut)); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO luts (id, scope, creator_id, film_id, name, storage_key, size)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING *
+	`
+	if err := tx.GetContext(ctx, lut, query,
+		lut.ID, lut.Scope, lut.CreatorID, lut.FilmID, lut.Name, lut.StorageKey, lut.Size,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetLUT retrieves the LUT in one slot; ownerID is ignored for platform scope
+func (q *Queries) GetLUT(ctx context.Context, scope models.LUTScope, ownerID uuid.UUID) (*models.LUT, error) {
+	var lut models.LUT
+	query := `SELECT * FROM luts WHERE ` + lutOwnerClause
+	err := q.db.GetContext(ctx, &lut, query, scope, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return &lut, nil
+}
+
+// DeleteLUT removes the LUT in one slot
+func (q *Queries) DeleteLUT(ctx context.Context, scope models.LUTScope, ownerID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, `DELETE FROM luts WHERE `+lutOwnerClause, scope, ownerID)
+	return err
+}
+
+// GetEffectiveLUT returns the LUT to apply when encoding a film: the film's
+// own, else its creator's, else the platform default
+func (q *Queries) GetEffectiveLUT(ctx context.Context, filmID uuid.UUID) (*models.LUT, error) {
+	var lut models.LUT
+	query := `
+		SELECT l.*
+		FROM films f
+		JOIN luts l ON (l.scope = 'film' AND l.film_id = f.id)
+		            OR (l.scope = 'creator' AND l.creator_id = f.created_by_id)
+		            OR l.scope = 'platform'
+		WHERE f.id = $1
+		ORDER BY CASE l.scope WHEN 'film' THEN 0 WHEN 'creator' THEN 1 ELSE 2 END
+		LIMIT 1
+	`
+	err := q.db.GetContext(ctx, &lut, query, filmID)
+	if err != nil {
+		return nil, err
+	}
+	return &lut, nil
+}
+
+func lutOwnerID(lut *models.LUT) uuid.UUID {
+	switch {
+	case lut.FilmID != nil:
+		return *lut.FilmID
+	case lut.CreatorID != nil:
+		return *lut.CreatorID
+	}
+	return uuid.Nil
+}
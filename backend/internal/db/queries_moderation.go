@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== MODERATION LOG QUERIES ==========
+
+// moderationSelect is the latest moderation decision on each comment and
+// review and every moderation queue action, as rows of alias m
+const moderationSelect = `
+	SELECT * FROM (
+		SELECT c.id, 'comment' AS target, c.film_id, c.user_id AS author_id,
+		       c.moderated_by_id AS moderator_id, c.moderated_at,
+		       CASE WHEN c.status = 'HIDDEN' THEN 'comment_hidden' ELSE 'comment_visible' END AS action
+		FROM comments c
+		WHERE c.moderated_at IS NOT NULL
+		UNION ALL
+		SELECT r.id, 'review', r.film_id, r.user_id,
+		       r.moderated_by_id, r.moderated_at,
+		       CASE WHEN r.hidden THEN 'review_hidden' ELSE 'review_visible' END
+		FROM film_reviews r
+		WHERE r.moderated_at IS NOT NULL
+		UNION ALL
+		SELECT a.target_id, a.target_type, a.film_id, a.subject_user_id,
+		       a.admin_id, a.created_at, a.action
+		FROM moderation_audit_log a
+	) m
+`
+
+// ListModerationActions returns the latest moderation decision on each
+// comment and review and the moderation queue actions, most recent first.
+// The actor is the moderator.
+func (q *Queries) ListModerationActions(ctx context.Context, filter AdminListFilter, after *AdminCursor, offset, limit int) ([]models.ModerationAction, error) {
+	w := moderationWhere(filter)
+	order := adminOrder(w, "m.moderated_at", "m.id", after, offset, limit)
+
+	actions := []models.ModerationAction{}
+	err := q.db.SelectContext(ctx, &actions, moderationSelect+w.sql()+` `+order, w.args...)
+	return actions, err
+}
+
+// CountModerationActions counts moderation decisions matching the filter
+func (q *Queries) CountModerationActions(ctx context.Context, filter AdminListFilter) (int, error) {
+	w := moderationWhere(filter)
+	var count int
+	query := `SELECT COUNT(*) FROM (` + moderationSelect + w.sql() + `) counted`
+	err := q.db.GetContext(ctx, &count, query, w.args...)
+	return count, err
+}
+
+func moderationWhere(filter AdminListFilter) *whereBuilder {
+	w := &whereBuilder{}
+	filter.applyActorAndRange(w, "m.moderator_id", "m.moderated_at")
+	if filter.Action != "" {
+		w.add("m.action = ?", filter.Action)
+	}
+	return w
+}
+
+// ========== MODERATION QUEUE QUERIES ==========
+
+// ListModerationQueue lists the films and comments with open reports
+// matching the filter, most reported first, with their owners
+func (q *Queries) ListModerationQueue(ctx context.Context, filter AdminListFilter, offset, limit int) ([]models.ModerationQueueItem, error) {
+	cte, w := reportedTargetsCTE(filter)
+
+	items := []models.ModerationQueueItem{}
+	query := cte + `
+		SELECT t.*, f.id AS film_id, f.title AS film_title, COALESCE(LEFT(c.body, 200), '') AS excerpt,
+		       COALESCE(c.user_id, f.created_by_id) AS owner_id
+		FROM targets t
+		LEFT JOIN comments c ON t.target_type = 'comment' AND c.id = t.target_id
+		JOIN films f ON f.id = COALESCE(c.film_id, t.target_id)
+		ORDER BY t.reports DESC, t.last_reported_at DESC, t.target_id DESC
+		OFFSET ` + w.arg(offset) + ` LIMIT ` + w.arg(limit)
+	err := q.db.SelectContext(ctx, &items, query, w.args...)
+	return items, err
+}
+
+// DismissCommentReports drops a comment's reports without changing its
+// status and returns how many there were
+func (q *Queries) DismissCommentReports(ctx context.Context, commentID uuid.UUID) (int64, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM comment_reports WHERE comment_id = $1`, commentID)
+	if err != nil {
+		return 0, err
+	}
+	dismissed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE comments SET report_count = 0 WHERE id = $1`, commentID); err != nil {
+		return 0, err
+	}
+
+	return dismissed, tx.Commit()
+}
+
+// UnpublishFilm takes a film out of the catalog; its policy decides who
+// may still watch it
+func (q *Queries) UnpublishFilm(ctx context.Context, filmID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE films SET published_at = NULL WHERE id = $1`, filmID)
+	return err
+}
+
+// BanUser marks a user banned and revokes their API keys
+func (q *Queries) BanUser(ctx context.Context, userID uuid.UUID) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET banned_at = COALESCE(banned_at, NOW()) WHERE id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE api_keys SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// IsUserBanned reports whether a user is banned
+func (q *Queries) IsUserBanned(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var banned bool
+	err := q.db.GetContext(ctx, &banned, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND banned_at IS NOT NULL)`, userID)
+	return banned, err
+}
+
+// RecordModerationAudit writes a moderation queue action to the audit log
+func (q *Queries) RecordModerationAudit(ctx context.Context, entry *models.ModerationAuditEntry) error {
+	query := `
+		INSERT INTO moderation_audit_log (admin_id, action, target_type, target_id, film_id, subject_user_id, reports_resolved, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, entry, query,
+		entry.AdminID, entry.Action, entry.TargetType, entry.TargetID,
+		entry.FilmID, entry.SubjectUserID, entry.ReportsResolved, entry.Reason,
+	)
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== NOTIFICATION QUERIES ==========
+
+// CreateNotifications inserts a batch of notifications
+func (q *Queries) CreateNotifications(ctx context.Context, notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO notifications (user_id, kind, film_id, tenant_id, message)
+		VALUES (:user_id, :kind, :film_id, :tenant_id, :message)
+	`
+	_, err := q.db.NamedExecContext(ctx, query, notifications)
+	return err
+}
+
+// ListNotifications returns a user's notifications, newest first
+func (q *Queries) ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset, limit int) ([]models.Notification, error) {
+	notifications := []models.Notification{}
+	query := `
+		SELECT * FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC
+		OFFSET $3 LIMIT $4
+	`
+	err := q.db.SelectContext(ctx, &notifications, query, userID, unreadOnly, offset, limit)
+	return notifications, err
+}
+
+// CountNotifications returns how many notifications a user has
+func (q *Queries) CountNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)`
+	err := q.db.GetContext(ctx, &count, query, userID, unreadOnly)
+	return count, err
+}
+
+// MarkNotificationRead marks one of a user's notifications read, returning
+// sql.ErrNoRows if it does not exist
+func (q *Queries) MarkNotificationRead(ctx context.Context, userID, id uuid.UUID) error {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`
+	result, err := q.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead marks every unread notification of a user read
+func (q *Queries) MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+	_, err := q.db.ExecContext(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== ORGANIZATION QUERIES ==========
+
+// CreateTenant creates an organization with the given user as its owner
+func (q *Queries) CreateTenant(ctx context.Context, tenant *models.Tenant, ownerID uuid.UUID) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO tenants (id, name, slug)
+		VALUES ($1, $2, $3)
+		RETURNING *
+	`
+	if err := tx.GetContext(ctx, tenant, query, tenant.ID, tenant.Name, tenant.Slug); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tenant_members (tenant_id, user_id, role)
+		VALUES ($1, $2, $3)
+	`, tenant.ID, ownerID, models.TenantRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetTenant retrieves an organization by ID
+func (q *Queries) GetTenant(ctx context.Context, id uuid.UUID) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := q.db.GetContext(ctx, &tenant, `SELECT * FROM tenants WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// TenantSlugExists reports whether an organization already uses the slug
+func (q *Queries) TenantSlugExists(ctx context.Context, slug string) (bool, error) {
+	var exists bool
+	err := q.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM tenants WHERE slug = $1)`, slug)
+	return exists, err
+}
+
+// ListUserTenants returns the organizations a user belongs to with their role
+func (q *Queries) ListUserTenants(ctx context.Context, userID uuid.UUID) ([]models.TenantMembership, error) {
+	memberships := []models.TenantMembership{}
+	query := `
+		SELECT t.*, m.role
+		FROM tenant_members m
+		JOIN tenants t ON t.id = m.tenant_id
+		WHERE m.user_id = $1
+		ORDER BY t.name
+	`
+	err := q.db.SelectContext(ctx, &memberships, query, userID)
+	return memberships, err
+}
+
+// UpdateTenantApprovalPolicy saves an organization's approval policy
+func (q *Queries) UpdateTenantApprovalPolicy(ctx context.Context, tenant *models.Tenant) error {
+	query := `
+		UPDATE tenants
+		SET approval_required = $2, approver_roles = $3, required_approvals = $4
+		WHERE id = $1
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, tenant, query,
+		tenant.ID, tenant.ApprovalRequired, tenant.ApproverRoles, tenant.RequiredApprovals,
+	)
+}
+
+// GetTenantMemberRole returns a user's role in an organization,
+// sql.ErrNoRows when they are not a member
+func (q *Queries) GetTenantMemberRole(ctx context.Context, tenantID, userID uuid.UUID) (models.TenantRole, error) {
+	var role models.TenantRole
+	query := `SELECT role FROM tenant_members WHERE tenant_id = $1 AND user_id = $2`
+	err := q.db.GetContext(ctx, &role, query, tenantID, userID)
+	return role, err
+}
+
+// ListTenantMembers returns an organization's members, owners first
+func (q *Queries) ListTenantMembers(ctx context.Context, tenantID uuid.UUID) ([]models.TenantMember, error) {
+	members := []models.TenantMember{}
+	query := `
+		SELECT m.*, u.email, u.name
+		FROM tenant_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.tenant_id = $1
+		ORDER BY CASE m.role WHEN 'OWNER' THEN 0 WHEN 'PRODUCER' THEN 1 ELSE 2 END, u.name
+	`
+	err := q.db.SelectContext(ctx, &members, query, tenantID)
+	return members, err
+}
+
+// ListTenantMemberIDsByRole returns the members holding any of the roles
+func (q *Queries) ListTenantMemberIDsByRole(ctx context.Context, tenantID uuid.UUID, roles []string) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	query := `SELECT user_id FROM tenant_members WHERE tenant_id = $1 AND role = ANY($2)`
+	err := q.db.SelectContext(ctx, &ids, query, tenantID, pq.StringArray(roles))
+	return ids, err
+}
+
+// CountTenantOwners returns how many owners an organization has
+func (q *Queries) CountTenantOwners(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM tenant_members WHERE tenant_id = $1 AND role = 'OWNER'`
+	err := q.db.GetContext(ctx, &count, query, tenantID)
+	return count, err
+}
+
+// UpsertTenantMember adds a user to an organization or changes their role
+func (q *Queries) UpsertTenantMember(ctx context.Context, tenantID, userID uuid.UUID, role models.TenantRole) error {
+	query := `
+		INSERT INTO tenant_members (tenant_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := q.db.ExecContext(ctx, query, tenantID, userID, role)
+	return err
+}
+
+// RemoveTenantMember removes a user from an organization, returning
+// sql.ErrNoRows if they were not a member
+func (q *Queries) RemoveTenantMember(ctx context.Context, tenantID, userID uuid.UUID) error {
+	result, err := q.db.ExecContext(ctx, `DELETE FROM tenant_members WHERE tenant_id = $1 AND user_id = $2`, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ========== APPROVAL QUERIES ==========
+
+// SetFilmTenant moves a film into (or, with nil, out of) an organization,
+// resetting its approval state. Moving it into an organization in privacy
+// mode forgets its viewing data.
+func (q *Queries) SetFilmTenant(ctx context.Context, filmID uuid.UUID, tenantID *uuid.UUID) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE films SET tenant_id = $2, approval_state = 'NONE' WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, filmID, tenantID); err != nil {
+		return err
+	}
+	var private bool
+	if err := tx.GetContext(ctx, &private, `SELECT `+privateFilm("$1"), filmID); err != nil {
+		return err
+	}
+	if private {
+		if err := forgetFilmViewers(ctx, tx, `SELECT $1::uuid`, filmID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RecordApprovalAction locks the film and, if its approval state is one of
+// from, appends the event and moves the film to the state chosen by next.
+// next receives the number of distinct approvers since the latest
+// submission, this event included. ok is false when the film was not in an
+// allowed state.
+func (q *Queries) RecordApprovalAction(ctx context.Context, event *models.FilmApprovalEvent, from []models.ApprovalState, next func(approvals int) models.ApprovalState) (state models.ApprovalState, approvals int, ok bool, err error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", 0, false, err
+	}
+	defer tx.Rollback()
+
+	var current models.ApprovalState
+	err = tx.GetContext(ctx, &current, `SELECT approval_state FROM films WHERE id = $1 FOR UPDATE`, event.FilmID)
+	if err != nil {
+		return "", 0, false, err
+	}
+	allowed := false
+	for _, s := range from {
+		if s == current {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return current, 0, false, nil
+	}
+
+	query := `
+		INSERT INTO film_approval_events (film_id, tenant_id, actor_id, action, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING *
+	`
+	if err := tx.GetContext(ctx, event, query,
+		event.FilmID, event.TenantID, event.ActorID, event.Action, event.Comment,
+	); err != nil {
+		return "", 0, false, err
+	}
+
+	// Approvals only count towards the current submission
+	err = tx.GetContext(ctx, &approvals, `
+		SELECT COUNT(DISTINCT actor_id)
+		FROM film_approval_events
+		WHERE film_id = $1 AND action = 'APPROVE'
+		  AND created_at >= COALESCE((
+		      SELECT MAX(created_at) FROM film_approval_events
+		      WHERE film_id = $1 AND action = 'SUBMIT'
+		  ), '-infinity')
+	`, event.FilmID)
+	if err != nil {
+		return "", 0, false, err
+	}
+
+	state = next(approvals)
+	if _, err := tx.ExecContext(ctx, `UPDATE films SET approval_state = $2 WHERE id = $1`, event.FilmID, state); err != nil {
+		return "", 0, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", 0, false, err
+	}
+	return state, approvals, true, nil
+}
+
+// HasApprovedSinceSubmit reports whether the user already approved the
+// film's current submission
+func (q *Queries) HasApprovedSinceSubmit(ctx context.Context, filmID, userID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM film_approval_events
+			WHERE film_id = $1 AND actor_id = $2 AND action = 'APPROVE'
+			  AND created_at >= COALESCE((
+			      SELECT MAX(created_at) FROM film_approval_events
+			      WHERE film_id = $1 AND action = 'SUBMIT'
+			  ), '-infinity')
+		)
+	`
+	err := q.db.GetContext(ctx, &exists, query, filmID, userID)
+	return exists, err
+}
+
+// GetLatestApprovalEvent returns the most recent approval action of the
+// given kind on a film
+func (q *Queries) GetLatestApprovalEvent(ctx context.Context, filmID uuid.UUID, action models.ApprovalAction) (*models.FilmApprovalEvent, error) {
+	var event models.FilmApprovalEvent
+	query := `
+		SELECT * FROM film_approval_events
+		WHERE film_id = $1 AND action = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	if err := q.db.GetContext(ctx, &event, query, filmID, action); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// ListFilmApprovalEvents returns a film's approval history, oldest first
+func (q *Queries) ListFilmApprovalEvents(ctx context.Context, filmID uuid.UUID) ([]models.FilmApprovalEvent, error) {
+	events := []models.FilmApprovalEvent{}
+	query := `SELECT * FROM film_approval_events WHERE film_id = $1 ORDER BY created_at`
+	err := q.db.SelectContext(ctx, &events, query, filmID)
+	return events, err
+}
+
+// ListTenantFilmsAwaitingApproval returns an organization's films that are
+// submitted or in review, oldest submission first
+func (q *Queries) ListTenantFilmsAwaitingApproval(ctx context.Context, tenantID uuid.UUID) ([]models.Film, error) {
+	films := []models.Film{}
+	query := `
+		SELECT f.*,
+		       COALESCE(jsonb_build_object(
+		           'id', u.id,
+		           'email', u.email,
+		           'name', u.name,
+		           'avatar_url', u.avatar_url
+		       )::json, '{}'::json) as created_by
+		FROM films f
+		LEFT JOIN users u ON f.created_by_id = u.id
+		WHERE f.tenant_id = $1 AND f.approval_state IN ('SUBMITTED', 'IN_REVIEW')
+		ORDER BY f.updated_at
+	`
+	err := q.db.SelectContext(ctx, &films, query, tenantID)
+	return films, err
+}
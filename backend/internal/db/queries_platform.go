@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ========== BOOTSTRAP QUERIES ==========
+
+// ErrBootstrapEmailTaken means an account already uses the bootstrap admin
+// email. Bootstrap never takes over an existing account: whoever registered
+// it first would keep its password.
+var ErrBootstrapEmailTaken = errors.New("admin email is already registered")
+
+// GetBootstrapState returns the bootstrap record, or nil if setup hasn't run
+func (q *Queries) GetBootstrapState(ctx context.Context) (*models.BootstrapState, error) {
+	var state models.BootstrapState
+	query := `SELECT admin_id, tenant_id, bootstrapped_at FROM platform_bootstrap`
+	err := q.db.GetContext(ctx, &state, query)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Bootstrap creates the initial admin, tenant and transcode profiles in a
+// single transaction. If setup has already completed the existing state is
+// returned and nothing is modified. It fails with ErrBootstrapEmailTaken
+// when the admin email belongs to an existing account.
+func (q *Queries) Bootstrap(ctx context.Context, admin *models.User, tenant *models.Tenant, profiles []models.TranscodeProfile) (*models.BootstrapState, bool, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	// Serialize concurrent bootstrap calls
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE platform_bootstrap IN EXCLUSIVE MODE`); err != nil {
+		return nil, false, err
+	}
+
+	var existing models.BootstrapState
+	err = tx.GetContext(ctx, &existing, `SELECT admin_id, tenant_id, bootstrapped_at FROM platform_bootstrap`)
+	if err == nil {
+		return &existing, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, err
+	}
+
+	err = tx.GetContext(ctx, &admin.ID, `
+		INSERT INTO users (id, email, password_hash, role, name)
+		VALUES ($1, $2, $3, 'ADMIN', $4)
+		ON CONFLICT (email) DO NOTHING
+		RETURNING id
+	`, admin.ID, admin.Email, admin.PasswordHash, admin.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, ErrBootstrapEmailTaken
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = tx.GetContext(ctx, &tenant.ID, `
+		INSERT INTO tenants (id, name, slug)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (slug) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id
+	`, tenant.ID, tenant.Name, tenant.Slug)
+	if err != nil {
+		return nil, false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tenant_members (tenant_id, user_id, role)
+		VALUES ($1, $2, 'OWNER')
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET role = 'OWNER'
+	`, tenant.ID, admin.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, p := range profiles {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO transcode_profiles (id, name, width, height, video_bitrate, audio_bitrate, enabled)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (name) DO NOTHING
+		`, uuid.New(), p.Name, p.Width, p.Height, p.VideoBitrate, p.AudioBitrate, p.Enabled)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	var state models.BootstrapState
+	err = tx.GetContext(ctx, &state, `
+		INSERT INTO platform_bootstrap (admin_id, tenant_id)
+		VALUES ($1, $2)
+		RETURNING admin_id, tenant_id, bootstrapped_at
+	`, admin.ID, tenant.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return &state, true, nil
+}
+
+// ========== TRANSCODE PROFILE QUERIES ==========
+
+// ListTranscodeProfiles retrieves all transcode profiles ordered by height
+func (q *Queries) ListTranscodeProfiles(ctx context.Context) ([]models.TranscodeProfile, error) {
+	var profiles []models.TranscodeProfile
+	query := `SELECT * FROM transcode_profiles ORDER BY height ASC`
+	err := q.db.SelectContext(ctx, &profiles, query)
+	return profiles, err
+}
+
+// UpsertTranscodeProfile creates or updates a transcode profile by name
+func (q *Queries) UpsertTranscodeProfile(ctx context.Context, profile *models.TranscodeProfile) error {
+	return upsertTranscodeProfile(ctx, q.db, profile)
+}
+
+func upsertTranscodeProfile(ctx context.Context, ext sqlx.ExtContext, profile *models.TranscodeProfile) error {
+	query := `
+		INSERT INTO transcode_profiles (id, name, width, height, video_bitrate, audio_bitrate, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (name) DO UPDATE
+		SET width = EXCLUDED.width,
+		    height = EXCLUDED.height,
+		    video_bitrate = EXCLUDED.video_bitrate,
+		    audio_bitrate = EXCLUDED.audio_bitrate,
+		    enabled = EXCLUDED.enabled
+	`
+	_, err := ext.ExecContext(ctx, query,
+		profile.ID, profile.Name, profile.Width, profile.Height,
+		profile.VideoBitrate, profile.AudioBitrate, profile.Enabled,
+	)
+	return err
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// ========== PLATFORM STATS QUERIES ==========
+
+// GetLastPlatformStatsDay returns the latest day rolled up into the
+// platform stats, or nil before the first rollup
+func (q *Queries) GetLastPlatformStatsDay(ctx context.Context) (*time.Time, error) {
+	var day *time.Time
+	err := q.db.GetContext(ctx, &day, `SELECT MAX(day) FROM platform_daily_stats`)
+	return day, err
+}
+
+// RollupPlatformDay rebuilds the platform stats of one day. Storage is
+// only snapshotted when snapshotStorage is set, as it can't be measured
+// for past days.
+func (q *Queries) RollupPlatformDay(ctx context.Context, day time.Time, snapshotStorage bool) error {
+	query := `
+		WITH bounds AS (
+			SELECT $1::date::timestamptz AS day_start, ($1::date + 1)::timestamptz AS day_end
+		)
+		INSERT INTO platform_daily_stats
+			(day, signups, uploads, transcodes_completed, transcodes_failed, storage_bytes, rolled_up_at)
+		SELECT $1::date,
+		       (SELECT COUNT(*) FROM users WHERE created_at >= b.day_start AND created_at < b.day_end),
+		       (SELECT COUNT(*) FROM upload_timings WHERE confirmed_at >= b.day_start AND confirmed_at < b.day_end),
+		       (SELECT COUNT(*) FROM upload_timings WHERE ready_at >= b.day_start AND ready_at < b.day_end),
+		       (SELECT COUNT(*) FROM upload_timings WHERE failed_at >= b.day_start AND failed_at < b.day_end),
+		       CASE WHEN $2 THEN
+		           (SELECT COALESCE(SUM(size_bytes), 0) FROM video_assets) +
+		           (SELECT COALESCE(SUM(file_size), 0) FROM film_purchases)
+		       END,
+		       NOW()
+		FROM bounds b
+		ON CONFLICT (day) DO UPDATE SET
+			signups = EXCLUDED.signups,
+			uploads = EXCLUDED.uploads,
+			transcodes_completed = EXCLUDED.transcodes_completed,
+			transcodes_failed = EXCLUDED.transcodes_failed,
+			storage_bytes = COALESCE(EXCLUDED.storage_bytes, platform_daily_stats.storage_bytes),
+			rolled_up_at = EXCLUDED.rolled_up_at
+	`
+	_, err := q.db.ExecContext(ctx, query, day.Format("2006-01-02"), snapshotStorage)
+	return err
+}
+
+// ListPlatformDailyStats returns the platform stats of days in [from, to),
+// oldest first
+func (q *Queries) ListPlatformDailyStats(ctx context.Context, from, to time.Time) ([]models.PlatformDailyStats, error) {
+	stats := []models.PlatformDailyStats{}
+	query := `
+		SELECT * FROM platform_daily_stats
+		WHERE day >= $1::date AND day < $2::date
+		ORDER BY day
+	`
+	err := q.db.SelectContext(ctx, &stats, query, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	return stats, err
+}
+
+// ListPlatformTopFilms returns the most viewed films of days in [from, to)
+// from the daily analytics rollups
+func (q *Queries) ListPlatformTopFilms(ctx context.Context, from, to time.Time, limit int) ([]models.PlatformTopFilm, error) {
+	films := []models.PlatformTopFilm{}
+	query := `
+		SELECT r.film_id, f.title, SUM(r.event_count) AS views
+		FROM analytics_rollups r
+		JOIN films f ON f.id = r.film_id
+		WHERE r.period = 'day' AND r.event_type = 'view'
+		  AND r.period_start >= $1::date AND r.period_start < $2::date
+		GROUP BY r.film_id, f.title
+		ORDER BY views DESC, r.film_id
+		LIMIT $3
+	`
+	err := q.db.SelectContext(ctx, &films, query, from.Format("2006-01-02"), to.Format("2006-01-02"), limit)
+	return films, err
+}
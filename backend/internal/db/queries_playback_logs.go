@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== PLAYBACK LOG QUERIES ==========
+
+// PlaybackLogFilter narrows a playback log search; nil fields are ignored
+type PlaybackLogFilter struct {
+	UserID    *uuid.UUID
+	FilmID    *uuid.UUID
+	SessionID *uuid.UUID
+	From      *time.Time
+	To        *time.Time
+}
+
+func playbackLogWhere(filter PlaybackLogFilter) *whereBuilder {
+	w := &whereBuilder{}
+	if filter.UserID != nil {
+		w.add("l.user_id = ?", *filter.UserID)
+	}
+	if filter.FilmID != nil {
+		w.add("l.film_id = ?", *filter.FilmID)
+	}
+	if filter.SessionID != nil {
+		w.add("l.session_id = ?", *filter.SessionID)
+	}
+	if filter.From != nil {
+		w.add("l.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		w.add("l.created_at < ?", *filter.To)
+	}
+	return w
+}
+
+// InsertPlaybackLogs stores the steps of one playback session. Sessions on
+// films of organizations in privacy mode are stored without the user, IP
+// prefix and user agent.
+func (q *Queries) InsertPlaybackLogs(ctx context.Context, entries []models.PlaybackLogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	private, err := q.IsPrivateFilm(ctx, entries[0].FilmID)
+	if err != nil {
+		return err
+	}
+	if private {
+		for i := range entries {
+			entries[i].UserID = nil
+			entries[i].IPPrefix = nil
+			entries[i].UserAgent = nil
+		}
+	}
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO playback_logs
+			(session_id, film_id, user_id, endpoint, stage, outcome, reason, details, country, ip_prefix, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	for _, e := range entries {
+		_, err := tx.ExecContext(ctx, query, e.SessionID, e.FilmID, e.UserID, e.Endpoint, e.Stage, e.Outcome,
+			e.Reason, string(e.Details), e.Country, e.IPPrefix, e.UserAgent, e.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListPlaybackLogs returns playback log entries matching filter, newest
+// first
+func (q *Queries) ListPlaybackLogs(ctx context.Context, filter PlaybackLogFilter, offset, limit int) ([]models.PlaybackLogEntry, error) {
+	where := playbackLogWhere(filter)
+	query := `
+		SELECT l.* FROM playback_logs l
+		` + where.sql() + `
+		ORDER BY l.created_at DESC, l.id DESC
+		LIMIT ` + where.arg(limit) + ` OFFSET ` + where.arg(offset)
+
+	entries := []models.PlaybackLogEntry{}
+	err := q.db.SelectContext(ctx, &entries, query, where.args...)
+	return entries, err
+}
+
+// CountPlaybackLogs counts the playback log entries matching filter
+func (q *Queries) CountPlaybackLogs(ctx context.Context, filter PlaybackLogFilter) (int, error) {
+	where := playbackLogWhere(filter)
+	var count int
+	err := q.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM playback_logs l `+where.sql(), where.args...)
+	return count, err
+}
+
+// DeletePlaybackLogsBefore deletes playback log entries older than cutoff
+func (q *Queries) DeletePlaybackLogsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, `DELETE FROM playback_logs WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
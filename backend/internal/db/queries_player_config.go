@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== PLAYER CONFIG QUERIES ==========
+
+// ListPlayerConfigRules returns every rule in the order they apply
+func (q *Queries) ListPlayerConfigRules(ctx context.Context) ([]models.PlayerConfigRule, error) {
+	rules := []models.PlayerConfigRule{}
+	query := `SELECT * FROM player_config_rules ORDER BY priority, created_at`
+	err := q.db.SelectContext(ctx, &rules, query)
+	return rules, err
+}
+
+// ListEnabledPlayerConfigRules returns the enabled rules in the order they
+// apply
+func (q *Queries) ListEnabledPlayerConfigRules(ctx context.Context) ([]models.PlayerConfigRule, error) {
+	rules := []models.PlayerConfigRule{}
+	query := `SELECT * FROM player_config_rules WHERE enabled ORDER BY priority, created_at`
+	err := q.db.SelectContext(ctx, &rules, query)
+	return rules, err
+}
+
+// CreatePlayerConfigRule stores a new rule
+func (q *Queries) CreatePlayerConfigRule(ctx context.Context, r *models.PlayerConfigRule) error {
+	query := `
+		INSERT INTO player_config_rules
+			(name, description, platform, min_app_version, max_app_version, rollout_percent, priority, enabled, patch, updated_by_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, r, query, r.Name, r.Description, r.Platform, r.MinAppVersion, r.MaxAppVersion,
+		r.RolloutPercent, r.Priority, r.Enabled, []byte(r.Patch), r.UpdatedByID)
+}
+
+// UpdatePlayerConfigRule replaces a rule
+func (q *Queries) UpdatePlayerConfigRule(ctx context.Context, r *models.PlayerConfigRule) error {
+	query := `
+		UPDATE player_config_rules
+		SET name = $2, description = $3, platform = $4, min_app_version = $5, max_app_version = $6,
+		    rollout_percent = $7, priority = $8, enabled = $9, patch = $10, updated_by_id = $11
+		WHERE id = $1
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, r, query, r.ID, r.Name, r.Description, r.Platform, r.MinAppVersion, r.MaxAppVersion,
+		r.RolloutPercent, r.Priority, r.Enabled, []byte(r.Patch), r.UpdatedByID)
+}
+
+// DeletePlayerConfigRule deletes a rule; returns sql.ErrNoRows for an
+// unknown rule
+func (q *Queries) DeletePlayerConfigRule(ctx context.Context, id uuid.UUID) error {
+	result, err := q.db.ExecContext(ctx, `DELETE FROM player_config_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
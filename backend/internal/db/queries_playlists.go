@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== PLAYLIST QUERIES ==========
+
+// CreatePlaylist saves a new playlist
+func (q *Queries) CreatePlaylist(ctx context.Context, p *models.Playlist) error {
+	query := `
+		INSERT INTO playlists (owner_id, title, description, kind, rules, public)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, p, query, p.OwnerID, p.Title, p.Description, p.Kind, nullJSON(p.Rules), p.Public)
+}
+
+// GetPlaylist retrieves a playlist by ID
+func (q *Queries) GetPlaylist(ctx context.Context, id uuid.UUID) (*models.Playlist, error) {
+	var p models.Playlist
+	if err := q.db.GetContext(ctx, &p, `SELECT * FROM playlists WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListUserPlaylists returns a user's playlists, oldest first
+func (q *Queries) ListUserPlaylists(ctx context.Context, ownerID uuid.UUID) ([]models.Playlist, error) {
+	playlists := []models.Playlist{}
+	query := `SELECT * FROM playlists WHERE owner_id = $1 ORDER BY created_at`
+	err := q.db.SelectContext(ctx, &playlists, query, ownerID)
+	return playlists, err
+}
+
+// CountUserPlaylists returns how many playlists a user has
+func (q *Queries) CountUserPlaylists(ctx context.Context, ownerID uuid.UUID) (int, error) {
+	var count int
+	err := q.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM playlists WHERE owner_id = $1`, ownerID)
+	return count, err
+}
+
+// UpdatePlaylist saves a playlist's title, description, rules and
+// visibility; its kind cannot change
+func (q *Queries) UpdatePlaylist(ctx context.Context, p *models.Playlist) error {
+	query := `
+		UPDATE playlists
+		SET title = $3, description = $4, rules = $5, public = $6
+		WHERE id = $1 AND owner_id = $2
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, p, query, p.ID, p.OwnerID, p.Title, p.Description, nullJSON(p.Rules), p.Public)
+}
+
+// DeletePlaylist removes one of a user's playlists, returning
+// sql.ErrNoRows if it does not exist
+func (q *Queries) DeletePlaylist(ctx context.Context, ownerID, id uuid.UUID) error {
+	result, err := q.db.ExecContext(ctx, `DELETE FROM playlists WHERE id = $1 AND owner_id = $2`, id, ownerID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// AddPlaylistItem appends a film to a manual playlist; adding a film twice
+// has no effect
+func (q *Queries) AddPlaylistItem(ctx context.Context, playlistID, filmID uuid.UUID) error {
+	query := `
+		INSERT INTO playlist_items (playlist_id, film_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM playlist_items WHERE playlist_id = $1
+		ON CONFLICT (playlist_id, film_id) DO NOTHING
+	`
+	_, err := q.db.ExecContext(ctx, query, playlistID, filmID)
+	return err
+}
+
+// RemovePlaylistItem removes a film from a manual playlist, returning
+// sql.ErrNoRows if it is not in it
+func (q *Queries) RemovePlaylistItem(ctx context.Context, playlistID, filmID uuid.UUID) error {
+	result, err := q.db.ExecContext(ctx, `DELETE FROM playlist_items WHERE playlist_id = $1 AND film_id = $2`, playlistID, filmID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ReorderPlaylistItems puts a manual playlist's films in the given order.
+// Films left out keep their relative order after the listed ones.
+func (q *Queries) ReorderPlaylistItems(ctx context.Context, playlistID uuid.UUID, filmIDs []uuid.UUID) error {
+	ids := make(pq.StringArray, len(filmIDs))
+	for i, id := range filmIDs {
+		ids[i] = id.String()
+	}
+
+	query := `
+		UPDATE playlist_items pi
+		SET position = ranked.position
+		FROM (
+			SELECT film_id, ROW_NUMBER() OVER (
+				ORDER BY array_position($2::uuid[], film_id) NULLS LAST, position
+			) AS position
+			FROM playlist_items
+			WHERE playlist_id = $1
+		) ranked
+		WHERE pi.playlist_id = $1 AND pi.film_id = ranked.film_id
+	`
+	_, err := q.db.ExecContext(ctx, query, playlistID, ids)
+	return err
+}
+
+// CountPlaylistItems returns how many films a manual playlist holds
+func (q *Queries) CountPlaylistItems(ctx context.Context, playlistID uuid.UUID) (int, error) {
+	var count int
+	err := q.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM playlist_items WHERE playlist_id = $1`, playlistID)
+	return count, err
+}
+
+// ListPlaylistFilmIDs returns a manual playlist's published films in order
+func (q *Queries) ListPlaylistFilmIDs(ctx context.Context, playlistID uuid.UUID) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	query := `
+		SELECT pi.film_id
+		FROM playlist_items pi
+		JOIN films f ON f.id = pi.film_id
+		WHERE pi.playlist_id = $1 AND f.status = 'READY' AND f.published_at IS NOT NULL
+		ORDER BY pi.position, pi.added_at
+	`
+	err := q.db.SelectContext(ctx, &ids, query, playlistID)
+	return ids, err
+}
+
+// ListSmartPlaylistFilmIDs evaluates smart playlist rules: published films
+// matching filter that carry every tag, in a FilmSortOrders order
+func (q *Queries) ListSmartPlaylistFilmIDs(ctx context.Context, filter FilmFilter, tags []string, sort string, limit int) ([]uuid.UUID, error) {
+	orderBy, ok := FilmSortOrders[sort]
+	if !ok {
+		orderBy = FilmSortOrders[DefaultFilmSort]
+	}
+
+	where := filmWhere(filter)
+	where.add("f.published_at IS NOT NULL")
+	if len(tags) > 0 {
+		where.add("f.tags @> ?::text[]", pq.StringArray(tags))
+	}
+	query := `SELECT f.id FROM films f ` + where.sql() + `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + where.arg(limit)
+
+	ids := []uuid.UUID{}
+	err := q.db.SelectContext(ctx, &ids, query, where.args...)
+	return ids, err
+}
+
+// nullJSON stores empty JSON as NULL
+func nullJSON(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== FILM POLICY QUERIES ==========
+
+// UpdateFilmPolicy stores a validated policy document along with the
+// columns listings filter on (visibility, comment mode, regions). Turning
+// downloads off also takes the film off sale; licenses already sold keep
+// working.
+func (q *Queries) UpdateFilmPolicy(ctx context.Context, filmID uuid.UUID, policy *models.FilmPolicy) error {
+	doc, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE films
+		SET policy = $2,
+		    visibility = $3,
+		    comment_mode = $4,
+		    allowed_regions = $5,
+		    blocked_regions = $6,
+		    download_price_cents = CASE WHEN $7::boolean THEN download_price_cents END,
+		    download_currency = CASE WHEN $7::boolean THEN download_currency END
+		WHERE id = $1
+	`
+	_, err = q.db.ExecContext(ctx, query, filmID, string(doc), policy.Visibility, policy.Comments,
+		pq.StringArray(policy.Regions.Allowed), pq.StringArray(policy.Regions.Blocked), policy.Downloads)
+	return err
+}
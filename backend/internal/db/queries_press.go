@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== PRESS QUERIES ==========
+
+// pressAccessSelect selects press list entries with the recipient, film
+// and screener status; expects a WHERE clause to be appended
+const pressAccessSelect = `
+	SELECT a.*, u.email, u.name, f.title AS film_title, f.press_embargo_until AS embargo_until,
+	       EXISTS (
+	           SELECT 1 FROM video_assets v
+	           WHERE v.film_id = a.film_id
+	             AND v.variant = 'screener-' || substr(replace(a.user_id::text, '-', ''), 1, 16)
+	       ) AS screener_ready
+	FROM film_press_access a
+	JOIN users u ON u.id = a.user_id
+	JOIN films f ON f.id = a.film_id
+`
+
+// pressAccessActive matches entries whose embargo has not lifted and whose
+// own expiry has not passed
+const pressAccessActive = `
+	f.press_embargo_until > NOW() AND (a.expires_at IS NULL OR a.expires_at > NOW())
+`
+
+// SetFilmPressEmbargo sets (or with nil clears) when a film's press embargo
+// lifts
+func (q *Queries) SetFilmPressEmbargo(ctx context.Context, filmID uuid.UUID, until *time.Time) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE films SET press_embargo_until = $2 WHERE id = $1`, filmID, until)
+	return err
+}
+
+// UpsertPressAccess adds a user to a film's press list or updates their
+// expiry
+func (q *Queries) UpsertPressAccess(ctx context.Context, access *models.FilmPressAccess) error {
+	query := `
+		INSERT INTO film_press_access (film_id, user_id, granted_by_id, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (film_id, user_id) DO UPDATE
+		SET granted_by_id = EXCLUDED.granted_by_id,
+		    expires_at = EXCLUDED.expires_at
+	`
+	_, err := q.db.ExecContext(ctx, query, access.FilmID, access.UserID, access.GrantedByID, access.ExpiresAt)
+	return err
+}
+
+// GetPressAccess returns a press list entry, active or not
+func (q *Queries) GetPressAccess(ctx context.Context, filmID, userID uuid.UUID) (*models.FilmPressAccess, error) {
+	var access models.FilmPressAccess
+	query := pressAccessSelect + `WHERE a.film_id = $1 AND a.user_id = $2`
+	if err := q.db.GetContext(ctx, &access, query, filmID, userID); err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
+
+// ListFilmPressAccess returns a film's press list, including expired
+// entries not yet cleaned up
+func (q *Queries) ListFilmPressAccess(ctx context.Context, filmID uuid.UUID) ([]models.FilmPressAccess, error) {
+	entries := []models.FilmPressAccess{}
+	query := pressAccessSelect + `WHERE a.film_id = $1 ORDER BY u.name`
+	err := q.db.SelectContext(ctx, &entries, query, filmID)
+	return entries, err
+}
+
+// ListUserPressAccess returns the films a press user can currently screen,
+// soonest embargo first
+func (q *Queries) ListUserPressAccess(ctx context.Context, userID uuid.UUID) ([]models.FilmPressAccess, error) {
+	entries := []models.FilmPressAccess{}
+	query := pressAccessSelect + `WHERE a.user_id = $1 AND ` + pressAccessActive + ` ORDER BY f.press_embargo_until`
+	err := q.db.SelectContext(ctx, &entries, query, userID)
+	return entries, err
+}
+
+// ListExpiredPressAccess returns entries whose access has ended
+func (q *Queries) ListExpiredPressAccess(ctx context.Context, limit int) ([]models.FilmPressAccess, error) {
+	entries := []models.FilmPressAccess{}
+	query := pressAccessSelect + `WHERE NOT (` + pressAccessActive + `) OR f.press_embargo_until IS NULL LIMIT $1`
+	err := q.db.SelectContext(ctx, &entries, query, limit)
+	return entries, err
+}
+
+// DeletePressAccess removes a press list entry and its screener asset
+// records, returning sql.ErrNoRows if there was no entry
+func (q *Queries) DeletePressAccess(ctx context.Context, filmID, userID uuid.UUID) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM film_press_access WHERE film_id = $1 AND user_id = $2`, filmID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM video_assets WHERE film_id = $1 AND variant = $2`,
+		filmID, models.ScreenerVariant(userID))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateUserRole changes a user's platform role
+func (q *Queries) UpdateUserRole(ctx context.Context, userID uuid.UUID, role models.UserRole) error {
+	result, err := q.db.ExecContext(ctx, `UPDATE users SET role = $2 WHERE id = $1`, userID, role)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ========== PRIVACY QUERIES ==========
+
+// Films of organizations in privacy mode keep no per-user viewing data.
+// The watch history and analytics writes check it here so every caller is
+// covered; aggregate counts such as view_count are unaffected.
+
+// privateFilm is a condition true when the film whose ID is in param
+// belongs to an organization in privacy mode
+func privateFilm(param string) string {
+	return `EXISTS (
+		SELECT 1 FROM films pf JOIN tenants pt ON pt.id = pf.tenant_id
+		WHERE pf.id = ` + param + ` AND pt.privacy_mode
+	)`
+}
+
+// IsPrivateFilm reports whether a film belongs to an organization in
+// privacy mode
+func (q *Queries) IsPrivateFilm(ctx context.Context, filmID uuid.UUID) (bool, error) {
+	var private bool
+	err := q.db.GetContext(ctx, &private, `SELECT `+privateFilm("$1"), filmID)
+	return private, err
+}
+
+// UpdateTenantPrivacyMode turns an organization's privacy mode on or off.
+// Turning it on also forgets the viewing data already kept for its films.
+func (q *Queries) UpdateTenantPrivacyMode(ctx context.Context, tenant *models.Tenant) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE tenants SET privacy_mode = $2 WHERE id = $1 RETURNING *`
+	if err := tx.GetContext(ctx, tenant, query, tenant.ID, tenant.PrivacyMode); err != nil {
+		return err
+	}
+	if tenant.PrivacyMode {
+		if err := forgetFilmViewers(ctx, tx, `SELECT id FROM films WHERE tenant_id = $1`, tenant.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// anonymizePrivateEvents strips the user, IP address and user agent from
+// events on films of organizations in privacy mode
+func (q *Queries) anonymizePrivateEvents(ctx context.Context, events []models.AnalyticsEvent) error {
+	ids := pq.StringArray{}
+	for _, e := range events {
+		if e.FilmID != nil {
+			ids = append(ids, e.FilmID.String())
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var private []uuid.UUID
+	query := `
+		SELECT f.id FROM films f JOIN tenants t ON t.id = f.tenant_id
+		WHERE f.id = ANY($1::uuid[]) AND t.privacy_mode
+	`
+	if err := q.db.SelectContext(ctx, &private, query, ids); err != nil {
+		return err
+	}
+
+	for _, filmID := range private {
+		for i := range events {
+			if events[i].FilmID != nil && *events[i].FilmID == filmID {
+				events[i].UserID = nil
+				events[i].IPAddress = nil
+				events[i].UserAgent = nil
+			}
+		}
+	}
+	return nil
+}
+
+// forgetFilmViewers deletes the watch history and recommendation feature
+// logs of, and anonymizes the playback logs and analytics events on, the
+// films selected by filmsQuery (taking arg as $1)
+func forgetFilmViewers(ctx context.Context, tx *sqlx.Tx, filmsQuery string, arg interface{}) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM watch_history WHERE film_id IN (`+filmsQuery+`)`, arg); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recommendation_feature_logs WHERE film_id IN (`+filmsQuery+`)`, arg); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE playback_logs SET user_id = NULL, ip_prefix = NULL, user_agent = NULL
+		WHERE film_id IN (`+filmsQuery+`)
+	`, arg); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE analytics_events
+		SET user_id = NULL, ip_address = NULL, user_agent = NULL, anonymized_at = COALESCE(anonymized_at, NOW())
+		WHERE film_id IN (` + filmsQuery + `)
+		  AND (user_id IS NOT NULL OR ip_address IS NOT NULL OR user_agent IS NOT NULL)
+	`
+	_, err := tx.ExecContext(ctx, query, arg)
+	return err
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== RENDITION PRUNING QUERIES ==========
+
+// pruneSelect selects rendition prunes with their film title; expects a
+// WHERE clause to be appended
+const pruneSelect = `
+	SELECT rp.*, f.title AS film_title
+	FROM rendition_prunes rp
+	JOIN films f ON f.id = rp.film_id
+`
+
+// ListPruneCandidates returns ready films with fewer than maxViews views,
+// published (or uploaded, if unpublished) before cutoff, that the pruning
+// job has not taken on yet; least watched first
+func (q *Queries) ListPruneCandidates(ctx context.Context, maxViews int, cutoff time.Time, limit int) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	query := `
+		SELECT f.id
+		FROM films f
+		WHERE f.status = 'READY'
+		  AND f.view_count < $1
+		  AND COALESCE(f.published_at, f.created_at) < $2
+		  AND NOT EXISTS (SELECT 1 FROM rendition_prunes rp WHERE rp.film_id = f.id)
+		ORDER BY f.view_count, f.created_at
+		LIMIT $3
+	`
+	err := q.db.SelectContext(ctx, &ids, query, maxViews, cutoff, limit)
+	return ids, err
+}
+
+// CreateRenditionPrune records that a film's pruning task was queued, so
+// later runs skip it
+func (q *Queries) CreateRenditionPrune(ctx context.Context, prune *models.RenditionPrune) error {
+	query := `
+		INSERT INTO rendition_prunes (film_id, task_id, kept)
+		VALUES ($1, $2, $3)
+		RETURNING id, queued_at
+	`
+	return q.db.QueryRowContext(ctx, query, prune.FilmID, prune.TaskID, prune.Kept).Scan(&prune.ID, &prune.QueuedAt)
+}
+
+// GetRenditionPrune returns a film's rendition prune
+func (q *Queries) GetRenditionPrune(ctx context.Context, filmID uuid.UUID) (*models.RenditionPrune, error) {
+	var prune models.RenditionPrune
+	if err := q.db.GetContext(ctx, &prune, pruneSelect+`WHERE rp.film_id = $1`, filmID); err != nil {
+		return nil, err
+	}
+	return &prune, nil
+}
+
+// FinishRenditionPrune records the renditions a pruning task deleted and
+// the bytes it freed
+func (q *Queries) FinishRenditionPrune(ctx context.Context, filmID uuid.UUID, pruned []string, bytesFreed int64) error {
+	query := `
+		UPDATE rendition_prunes
+		SET pruned = $2, bytes_freed = $3, pruned_at = NOW()
+		WHERE film_id = $1
+	`
+	_, err := q.db.ExecContext(ctx, query, filmID, pq.StringArray(pruned), bytesFreed)
+	return err
+}
+
+// DeleteRenditionPrune forgets a film's prune, so a failed pruning task is
+// retried by the next run
+func (q *Queries) DeleteRenditionPrune(ctx context.Context, filmID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, `DELETE FROM rendition_prunes WHERE film_id = $1 AND pruned_at IS NULL`, filmID)
+	return err
+}
+
+// StartRenditionRestore records the task restoring a film's pruned
+// renditions. It returns sql.ErrNoRows if the film has none to restore.
+func (q *Queries) StartRenditionRestore(ctx context.Context, filmID, taskID uuid.UUID) error {
+	query := `
+		UPDATE rendition_prunes
+		SET restore_task_id = $2
+		WHERE film_id = $1 AND pruned_at IS NOT NULL AND restored_at IS NULL AND cardinality(pruned) > 0
+	`
+	result, err := q.db.ExecContext(ctx, query, filmID, taskID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkRenditionsRestored records that a film's pruned renditions were
+// transcoded again
+func (q *Queries) MarkRenditionsRestored(ctx context.Context, filmID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE rendition_prunes SET restored_at = NOW() WHERE film_id = $1`, filmID)
+	return err
+}
+
+// ListRenditionPrunes returns films whose renditions were pruned, most
+// recent first
+func (q *Queries) ListRenditionPrunes(ctx context.Context, offset, limit int) ([]models.RenditionPrune, error) {
+	prunes := []models.RenditionPrune{}
+	query := pruneSelect + `
+		WHERE rp.pruned_at IS NOT NULL AND cardinality(rp.pruned) > 0
+		ORDER BY rp.pruned_at DESC
+		OFFSET $1 LIMIT $2
+	`
+	err := q.db.SelectContext(ctx, &prunes, query, offset, limit)
+	return prunes, err
+}
+
+// CountRenditionPrunes counts films whose renditions were pruned
+func (q *Queries) CountRenditionPrunes(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM rendition_prunes WHERE pruned_at IS NOT NULL AND cardinality(pruned) > 0`
+	err := q.db.GetContext(ctx, &count, query)
+	return count, err
+}
+
+// GetPruningSavings totals the storage freed by renditions still pruned
+func (q *Queries) GetPruningSavings(ctx context.Context) (*models.PruningSavings, error) {
+	var savings models.PruningSavings
+	query := `
+		SELECT COUNT(*) AS films,
+		       COALESCE(SUM(cardinality(pruned)), 0) AS renditions,
+		       COALESCE(SUM(bytes_freed), 0) AS bytes_freed
+		FROM rendition_prunes
+		WHERE restored_at IS NULL AND cardinality(pruned) > 0
+	`
+	if err := q.db.GetContext(ctx, &savings, query); err != nil {
+		return nil, err
+	}
+	return &savings, nil
+}
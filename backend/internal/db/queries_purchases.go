@@ -0,0 +1,225 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== PURCHASE QUERIES ==========
+
+// purchaseSelect selects purchases with their film title; expects a WHERE
+// clause to be appended
+const purchaseSelect = `
+	SELECT p.*, f.title AS film_title
+	FROM film_purchases p
+	JOIN films f ON f.id = p.film_id
+`
+
+// SetFilmDownloadPrice puts a film up for sale as a download, or with a nil
+// price takes it off sale. Existing purchases are unaffected.
+func (q *Queries) SetFilmDownloadPrice(ctx context.Context, filmID uuid.UUID, priceCents *int64, currency *string) error {
+	query := `UPDATE films SET download_price_cents = $2, download_currency = $3 WHERE id = $1`
+	_, err := q.db.ExecContext(ctx, query, filmID, priceCents, currency)
+	return err
+}
+
+// RecordPurchase records a paid purchase in the revenue ledger and issues
+// its license in one transaction. A purchase whose external_ref is already
+// recorded is not recorded again; created reports whether one was issued.
+func (q *Queries) RecordPurchase(ctx context.Context, t *models.FilmTransaction, p *models.FilmPurchase) (created bool, err error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO film_transactions (film_id, user_id, kind, amount_cents, currency, external_ref, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (external_ref) DO NOTHING
+		RETURNING id, created_at
+	`, t.FilmID, t.UserID, t.Kind, t.AmountCents, t.Currency, t.ExternalRef, t.OccurredAt).Scan(&t.ID, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	p.TransactionID = t.ID
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO film_purchases (film_id, user_id, transaction_id, license_key, download_limit)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, created_at
+	`, p.FilmID, p.UserID, p.TransactionID, p.LicenseKey, p.DownloadLimit).Scan(&p.ID, &p.Status, &p.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// GetPurchase returns a purchase by ID
+func (q *Queries) GetPurchase(ctx context.Context, id uuid.UUID) (*models.FilmPurchase, error) {
+	var purchase models.FilmPurchase
+	if err := q.db.GetContext(ctx, &purchase, purchaseSelect+`WHERE p.id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &purchase, nil
+}
+
+// GetPurchaseByLicenseKey returns the purchase a license key was issued for
+func (q *Queries) GetPurchaseByLicenseKey(ctx context.Context, licenseKey string) (*models.FilmPurchase, error) {
+	var purchase models.FilmPurchase
+	if err := q.db.GetContext(ctx, &purchase, purchaseSelect+`WHERE p.license_key = $1`, licenseKey); err != nil {
+		return nil, err
+	}
+	return &purchase, nil
+}
+
+// GetPurchaseByExternalRef returns the purchase recorded for a payment
+// provider reference
+func (q *Queries) GetPurchaseByExternalRef(ctx context.Context, externalRef string) (*models.FilmPurchase, error) {
+	var purchase models.FilmPurchase
+	query := purchaseSelect + `
+		JOIN film_transactions t ON t.id = p.transaction_id
+		WHERE t.external_ref = $1
+	`
+	if err := q.db.GetContext(ctx, &purchase, query, externalRef); err != nil {
+		return nil, err
+	}
+	return &purchase, nil
+}
+
+// ListUserPurchases returns a user's purchases, newest first
+func (q *Queries) ListUserPurchases(ctx context.Context, userID uuid.UUID) ([]models.FilmPurchase, error) {
+	purchases := []models.FilmPurchase{}
+	err := q.db.SelectContext(ctx, &purchases, purchaseSelect+`WHERE p.user_id = $1 ORDER BY p.created_at DESC`, userID)
+	return purchases, err
+}
+
+// MarkPurchaseReady records a purchase's generated file. It returns
+// sql.ErrNoRows if the purchase was revoked while the file was generated.
+func (q *Queries) MarkPurchaseReady(ctx context.Context, id uuid.UUID, downloadKey string, fileSize int64) error {
+	query := `
+		UPDATE film_purchases
+		SET status = 'READY', download_key = $2, file_size = $3, ready_at = NOW()
+		WHERE id = $1 AND status <> 'REVOKED'
+	`
+	result, err := q.db.ExecContext(ctx, query, id, downloadKey, fileSize)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkPurchaseFailed records that a purchase's file could not be generated
+func (q *Queries) MarkPurchaseFailed(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE film_purchases SET status = 'FAILED' WHERE id = $1 AND status = 'PENDING'`
+	_, err := q.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// RetryPurchase puts a failed purchase back to pending; it returns
+// sql.ErrNoRows unless the purchase had failed
+func (q *Queries) RetryPurchase(ctx context.Context, id uuid.UUID) error {
+	result, err := q.db.ExecContext(ctx, `UPDATE film_purchases SET status = 'PENDING' WHERE id = $1 AND status = 'FAILED'`, id)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ClaimPurchaseDownload counts one download of a ready purchase against
+// its limit. It returns sql.ErrNoRows if the purchase is not the user's,
+// not ready or out of downloads.
+func (q *Queries) ClaimPurchaseDownload(ctx context.Context, id, userID uuid.UUID) (*models.FilmPurchase, error) {
+	var purchase models.FilmPurchase
+	query := `
+		WITH claimed AS (
+			UPDATE film_purchases
+			SET download_count = download_count + 1
+			WHERE id = $1 AND user_id = $2 AND status = 'READY' AND download_count < download_limit
+			RETURNING *
+		)
+		SELECT p.*, f.title AS film_title
+		FROM claimed p
+		JOIN films f ON f.id = p.film_id
+	`
+	if err := q.db.GetContext(ctx, &purchase, query, id, userID); err != nil {
+		return nil, err
+	}
+	return &purchase, nil
+}
+
+// RefundPurchase records a refund of the purchase paid with refundOf (a
+// payment provider reference) and revokes its license. A refund whose
+// external_ref is already recorded is not recorded again, so callbacks can
+// be replayed. Returns sql.ErrNoRows if no purchase was paid with refundOf.
+func (q *Queries) RefundPurchase(ctx context.Context, refund *models.FilmTransaction, refundOf string) (*models.FilmPurchase, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var original models.FilmTransaction
+	err = tx.GetContext(ctx, &original, `
+		SELECT * FROM film_transactions
+		WHERE external_ref = $1 AND kind = 'purchase'
+		FOR UPDATE
+	`, refundOf)
+	if err != nil {
+		return nil, err
+	}
+
+	refund.FilmID = original.FilmID
+	refund.UserID = original.UserID
+	refund.RefundOf = &original.ID
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO film_transactions (film_id, user_id, kind, amount_cents, currency, refund_of, external_ref, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (external_ref) DO NOTHING
+		RETURNING id, created_at
+	`, refund.FilmID, refund.UserID, refund.Kind, refund.AmountCents, refund.Currency,
+		refund.RefundOf, refund.ExternalRef, refund.OccurredAt).Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	var revoked models.FilmPurchase
+	err = tx.GetContext(ctx, &revoked, `
+		WITH revoked AS (
+			UPDATE film_purchases
+			SET status = 'REVOKED', revoked_at = COALESCE(revoked_at, NOW())
+			WHERE transaction_id = $1
+			RETURNING *
+		)
+		SELECT p.*, f.title AS film_title
+		FROM revoked p
+		JOIN films f ON f.id = p.film_id
+	`, original.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &revoked, tx.Commit()
+}
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// ========== QOE QUERIES ==========
+
+// ListQoERollups returns the QoE rollups of days in [from, to), by day and
+// rendition
+func (q *Queries) ListQoERollups(ctx context.Context, from, to time.Time) ([]models.QoEStats, error) {
+	rollups := []models.QoEStats{}
+	query := `
+		SELECT day::timestamptz AS day, rendition, startups, startup_ms, rebuffers, rebuffer_ms,
+		       watch_seconds, bits_played, bitrate_seconds
+		FROM qoe_rollups
+		WHERE day >= $1 AND day < $2
+		ORDER BY day, rendition
+	`
+	err := q.db.SelectContext(ctx, &rollups, query, from, to)
+	return rollups, err
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== QUALITY CHECK QUERIES ==========
+
+// GetQualityBaseline averages the VMAF of the most recently scored default
+// renditions of a quality on other films, returning nil when fewer than
+// minAssets have been scored
+func (q *Queries) GetQualityBaseline(ctx context.Context, quality string, excludeFilmID uuid.UUID, recent, minAssets int) (*float64, error) {
+	var row struct {
+		Avg   *float64 `db:"avg"`
+		Count int      `db:"count"`
+	}
+	query := `
+		SELECT AVG(vmaf) AS avg, COUNT(*) AS count
+		FROM (
+			SELECT vmaf FROM video_assets
+			WHERE quality = $1 AND variant = $2 AND film_id <> $3 AND vmaf IS NOT NULL
+			ORDER BY qc_at DESC
+			LIMIT $4
+		) scored
+	`
+	if err := q.db.GetContext(ctx, &row, query, quality, models.VariantDefault, excludeFilmID, recent); err != nil {
+		return nil, err
+	}
+	if row.Count < minAssets {
+		return nil, nil
+	}
+	return row.Avg, nil
+}
+
+// CreateQualityAlert records a rendition that scored too low
+func (q *Queries) CreateQualityAlert(ctx context.Context, alert *models.QualityAlert) error {
+	query := `
+		INSERT INTO quality_alerts (id, film_id, variant, quality, reason, vmaf, threshold)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+	return q.db.QueryRowContext(ctx, query,
+		alert.ID, alert.FilmID, alert.Variant, alert.Quality, alert.Reason, alert.VMAF, alert.Threshold,
+	).Scan(&alert.CreatedAt)
+}
+
+// ListQualityAlerts returns quality alerts, most recent first; action is
+// the alert reason
+func (q *Queries) ListQualityAlerts(ctx context.Context, filter AdminListFilter, after *AdminCursor, offset, limit int) ([]models.QualityAlert, error) {
+	w := qualityAlertWhere(filter)
+	order := adminOrder(w, "a.created_at", "a.id", after, offset, limit)
+
+	alerts := []models.QualityAlert{}
+	query := `
+		SELECT a.*, f.title AS film_title
+		FROM quality_alerts a
+		JOIN films f ON f.id = a.film_id
+		` + w.sql() + `
+		` + order
+	err := q.db.SelectContext(ctx, &alerts, query, w.args...)
+	return alerts, err
+}
+
+// CountQualityAlerts counts quality alerts matching the filter
+func (q *Queries) CountQualityAlerts(ctx context.Context, filter AdminListFilter) (int, error) {
+	w := qualityAlertWhere(filter)
+	var count int
+	err := q.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM quality_alerts a `+w.sql(), w.args...)
+	return count, err
+}
+
+func qualityAlertWhere(filter AdminListFilter) *whereBuilder {
+	w := &whereBuilder{}
+	filter.applyActorAndRange(w, "", "a.created_at")
+	if filter.Action != "" {
+		w.add("a.reason = ?", filter.Action)
+	}
+	return w
+}
+
+// GetRenditionQuality summarises the scored default renditions of each
+// quality, lowest bitrate first
+func (q *Queries) GetRenditionQuality(ctx context.Context) ([]models.RenditionQuality, error) {
+	stats := []models.RenditionQuality{}
+	query := `
+		SELECT quality,
+		       COUNT(*) AS renditions,
+		       AVG(vmaf) AS avg_vmaf,
+		       percentile_cont(0.1) WITHIN GROUP (ORDER BY vmaf) AS p10_vmaf,
+		       AVG(psnr) AS avg_psnr,
+		       COALESCE(AVG(NULLIF(bandwidth, 0)), 0) AS avg_bandwidth
+		FROM video_assets
+		WHERE variant = $1 AND qc_at IS NOT NULL
+		GROUP BY quality
+		ORDER BY avg_bandwidth, quality
+	`
+	err := q.db.SelectContext(ctx, &stats, query, models.VariantDefault)
+	return stats, err
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== REACTION QUERIES ==========
+
+// ToggleFilmReaction sets a user's reaction to a ready film, or removes it
+// when they already reacted that way, and updates the film's counts in the
+// same transaction. It returns sql.ErrNoRows if the film is not ready.
+func (q *Queries) ToggleFilmReaction(ctx context.Context, userID, filmID uuid.UUID, reaction models.Reaction) (*models.FilmReactions, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locking the film serializes reactions to it, so the counts stay exact
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT true FROM films WHERE id = $1 AND status = 'READY' FOR UPDATE`, filmID,
+	).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	var current models.Reaction
+	err = tx.QueryRowContext(ctx,
+		`SELECT reaction FROM film_reactions WHERE user_id = $1 AND film_id = $2`, userID, filmID,
+	).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	result := &models.FilmReactions{}
+	switch current {
+	case reaction:
+		_, err = tx.ExecContext(ctx, `DELETE FROM film_reactions WHERE user_id = $1 AND film_id = $2`, userID, filmID)
+	case "":
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO film_reactions (user_id, film_id, reaction) VALUES ($1, $2, $3)`, userID, filmID, reaction)
+		result.ViewerReaction = &reaction
+	default:
+		_, err = tx.ExecContext(ctx,
+			`UPDATE film_reactions SET reaction = $3, created_at = NOW() WHERE user_id = $1 AND film_id = $2`, userID, filmID, reaction)
+		result.ViewerReaction = &reaction
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var added models.Reaction
+	if result.ViewerReaction != nil {
+		added = *result.ViewerReaction
+	}
+	likes, dislikes := reactionDelta(current, -1)
+	addLikes, addDislikes := reactionDelta(added, 1)
+	query := `
+		UPDATE films SET like_count = like_count + $2, dislike_count = dislike_count + $3
+		WHERE id = $1
+		RETURNING like_count, dislike_count
+	`
+	if err := tx.QueryRowContext(ctx, query, filmID, likes+addLikes, dislikes+addDislikes).Scan(
+		&result.LikeCount, &result.DislikeCount,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// reactionDelta returns the like and dislike count changes for adding
+// (sign 1) or removing (sign -1) a reaction
+func reactionDelta(reaction models.Reaction, sign int) (likes, dislikes int) {
+	switch reaction {
+	case models.ReactionLike:
+		return sign, 0
+	case models.ReactionDislike:
+		return 0, sign
+	}
+	return 0, 0
+}
+
+// GetUserReactions returns a user's reactions to the given films, keyed by
+// film; films they have not reacted to are absent
+func (q *Queries) GetUserReactions(ctx context.Context, userID uuid.UUID, filmIDs []uuid.UUID) (map[uuid.UUID]models.Reaction, error) {
+	reactions := map[uuid.UUID]models.Reaction{}
+	if len(filmIDs) == 0 {
+		return reactions, nil
+	}
+
+	idStrings := make(pq.StringArray, len(filmIDs))
+	for i, id := range filmIDs {
+		idStrings[i] = id.String()
+	}
+
+	var rows []struct {
+		FilmID   uuid.UUID       `db:"film_id"`
+		Reaction models.Reaction `db:"reaction"`
+	}
+	query := `SELECT film_id, reaction FROM film_reactions WHERE user_id = $1 AND film_id = ANY($2::uuid[])`
+	if err := q.db.SelectContext(ctx, &rows, query, userID, idStrings); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		reactions[row.FilmID] = row.Reaction
+	}
+	return reactions, nil
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// ========== RECOMMENDATION FEATURE LOG QUERIES ==========
+
+// InsertRecommendationFeatureLogs stores the candidates of one computed
+// recommendation list. Candidates on films of organizations in privacy
+// mode are skipped.
+func (q *Queries) InsertRecommendationFeatureLogs(ctx context.Context, logs []models.RecommendationFeatureLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO recommendation_feature_logs (list_id, user_id, film_id, model, rank, score, features, created_at)
+		SELECT $1::uuid, $2::uuid, $3::uuid, $4, $5::integer, $6::double precision, $7::jsonb, $8::timestamptz
+		WHERE NOT ` + privateFilm("$3::uuid") + `
+	`
+	for _, l := range logs {
+		features, err := json.Marshal(l.Features)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, query, l.ListID, l.UserID, l.FilmID, l.Model, l.Rank, l.Score,
+			string(features), l.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteRecommendationFeatureLogsBefore deletes feature logs older than
+// cutoff
+func (q *Queries) DeleteRecommendationFeatureLogsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, `DELETE FROM recommendation_feature_logs WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
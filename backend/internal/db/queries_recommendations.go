@@ -0,0 +1,298 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== WATCH HISTORY QUERIES ==========
+
+// RecordWatch records that a user watched a film, unless the film's
+// organization is in privacy mode
+func (q *Queries) RecordWatch(ctx context.Context, userID, filmID uuid.UUID) error {
+	query := `
+		INSERT INTO watch_history (user_id, film_id)
+		SELECT $1::uuid, $2::uuid
+		WHERE NOT ` + privateFilm("$2::uuid") + `
+		ON CONFLICT (user_id, film_id) DO UPDATE
+		SET watch_count = watch_history.watch_count + 1,
+		    last_watched_at = NOW()
+	`
+	_, err := q.db.ExecContext(ctx, query, userID, filmID)
+	return err
+}
+
+// ========== PLAYBACK POSITION QUERIES ==========
+
+// SavePlaybackPosition stores how far a user has played a film. Completed
+// marks the film finished; a later position that is not completed (a
+// rewatch) clears it again. A position older than the one stored is
+// ignored, so flushes arriving out of order cannot rewind it. Nothing is
+// stored for films of organizations in privacy mode.
+func (q *Queries) SavePlaybackPosition(ctx context.Context, p *models.PlaybackPosition) error {
+	query := `
+		INSERT INTO watch_history (user_id, film_id, position_seconds, duration_seconds, position_updated_at, last_watched_at, completed_at)
+		SELECT $1::uuid, $2::uuid, $3::integer, $4::integer, $6::timestamptz, $6::timestamptz, CASE WHEN $5::boolean THEN $6::timestamptz END
+		WHERE NOT ` + privateFilm("$2::uuid") + `
+		ON CONFLICT (user_id, film_id) DO UPDATE
+		SET position_seconds = EXCLUDED.position_seconds,
+		    duration_seconds = COALESCE(EXCLUDED.duration_seconds, watch_history.duration_seconds),
+		    position_updated_at = EXCLUDED.position_updated_at,
+		    last_watched_at = GREATEST(watch_history.last_watched_at, EXCLUDED.last_watched_at),
+		    completed_at = CASE
+		        WHEN $5 THEN COALESCE(watch_history.completed_at, EXCLUDED.completed_at)
+		    END
+		WHERE watch_history.position_updated_at IS NULL
+		   OR watch_history.position_updated_at <= EXCLUDED.position_updated_at
+	`
+	_, err := q.db.ExecContext(ctx, query, p.UserID, p.FilmID, p.PositionSeconds, p.DurationSeconds, p.Completed, p.UpdatedAt)
+	return err
+}
+
+// GetPlaybackPosition returns a user's stored position in a film
+func (q *Queries) GetPlaybackPosition(ctx context.Context, userID, filmID uuid.UUID) (*models.PlaybackPosition, error) {
+	var row struct {
+		PositionSeconds int        `db:"position_seconds"`
+		DurationSeconds *int       `db:"duration_seconds"`
+		Completed       bool       `db:"completed"`
+		UpdatedAt       *time.Time `db:"position_updated_at"`
+	}
+	query := `
+		SELECT position_seconds, duration_seconds, completed_at IS NOT NULL AS completed, position_updated_at
+		FROM watch_history
+		WHERE user_id = $1 AND film_id = $2
+	`
+	if err := q.db.GetContext(ctx, &row, query, userID, filmID); err != nil {
+		return nil, err
+	}
+
+	p := &models.PlaybackPosition{
+		UserID:          userID,
+		FilmID:          filmID,
+		PositionSeconds: row.PositionSeconds,
+		DurationSeconds: row.DurationSeconds,
+		Completed:       row.Completed,
+	}
+	if row.UpdatedAt != nil {
+		p.UpdatedAt = *row.UpdatedAt
+	}
+	return p, nil
+}
+
+// GetInProgressFilms returns films the user started but has not finished,
+// most recently played first
+func (q *Queries) GetInProgressFilms(ctx context.Context, userID uuid.UUID, limit int) ([]models.InProgressFilm, error) {
+	films := []models.InProgressFilm{}
+	query := `
+		SELECT f.*,
+		       COALESCE(jsonb_build_object(
+		           'id', u.id,
+		           'email', u.email,
+		           'name', u.name,
+		           'avatar_url', u.avatar_url
+		       )::json, '{}'::json) as created_by,
+		       w.position_seconds,
+		       COALESCE(NULLIF(w.duration_seconds, 0), f.duration) AS watch_duration_seconds,
+		       w.position_updated_at
+		FROM watch_history w
+		JOIN films f ON f.id = w.film_id
+		LEFT JOIN users u ON f.created_by_id = u.id
+		WHERE w.user_id = $1
+		  AND w.completed_at IS NULL
+		  AND w.position_seconds > 0
+		  AND f.status = 'READY'
+		  AND f.published_at IS NOT NULL
+		ORDER BY w.position_updated_at DESC
+		LIMIT $2
+	`
+	err := q.db.SelectContext(ctx, &films, query, userID, limit)
+	return films, err
+}
+
+// ========== FOLLOW QUERIES ==========
+
+// FollowCreator makes follower follow creator; following twice is a no-op
+func (q *Queries) FollowCreator(ctx context.Context, followerID, creatorID uuid.UUID) error {
+	query := `
+		INSERT INTO creator_follows (follower_id, creator_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := q.db.ExecContext(ctx, query, followerID, creatorID)
+	return err
+}
+
+// UnfollowCreator removes a follow
+func (q *Queries) UnfollowCreator(ctx context.Context, followerID, creatorID uuid.UUID) error {
+	query := `DELETE FROM creator_follows WHERE follower_id = $1 AND creator_id = $2`
+	_, err := q.db.ExecContext(ctx, query, followerID, creatorID)
+	return err
+}
+
+// GetNewFromFollows returns films published since the given time by
+// creators the user follows, newest first
+func (q *Queries) GetNewFromFollows(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]models.Film, error) {
+	films := []models.Film{}
+	query := `
+		SELECT f.*,
+		       COALESCE(jsonb_build_object(
+		           'id', u.id,
+		           'email', u.email,
+		           'name', u.name,
+		           'avatar_url', u.avatar_url
+		       )::json, '{}'::json) as created_by
+		FROM creator_follows cf
+		JOIN films f ON f.created_by_id = cf.creator_id
+		LEFT JOIN users u ON f.created_by_id = u.id
+		WHERE cf.follower_id = $1
+		  AND f.status = 'READY'
+		  AND f.visibility = 'PUBLIC'
+		  AND f.published_at >= $2
+		ORDER BY f.published_at DESC, f.id DESC
+		LIMIT $3
+	`
+	err := q.db.SelectContext(ctx, &films, query, userID, since, limit)
+	return films, err
+}
+
+// ========== RECOMMENDATION QUERIES ==========
+
+// ScoredFilm is a raw recommendation signal for one film
+type ScoredFilm struct {
+	FilmID     uuid.UUID  `db:"film_id"`
+	Score      float64    `db:"score"`
+	SeedFilmID *uuid.UUID `db:"seed_film_id"`
+}
+
+// recommendableFilm restricts candidates to published, listed films the
+// user has not watched yet; expects the user ID as $1 and the film alias f
+const recommendableFilm = `
+	f.status = 'READY'
+	AND f.published_at IS NOT NULL
+	AND f.visibility = 'PUBLIC'
+	AND NOT EXISTS (
+		SELECT 1 FROM watch_history seen
+		WHERE seen.user_id = $1 AND seen.film_id = f.id
+	)
+`
+
+// CoViewCandidates scores films by how many other viewers of the user's
+// films also watched them. The seed is the user's most recent watch that
+// led to the candidate.
+func (q *Queries) CoViewCandidates(ctx context.Context, userID uuid.UUID, limit int) ([]ScoredFilm, error) {
+	var candidates []ScoredFilm
+	query := `
+		SELECT other.film_id,
+		       COUNT(DISTINCT other.user_id)::float8 AS score,
+		       (array_agg(mine.film_id ORDER BY mine.last_watched_at DESC))[1] AS seed_film_id
+		FROM watch_history mine
+		JOIN watch_history peer ON peer.film_id = mine.film_id AND peer.user_id <> mine.user_id
+		JOIN watch_history other ON other.user_id = peer.user_id AND other.film_id <> mine.film_id
+		JOIN films f ON f.id = other.film_id
+		WHERE mine.user_id = $1 AND ` + recommendableFilm + `
+		GROUP BY other.film_id
+		ORDER BY score DESC
+		LIMIT $2
+	`
+	err := q.db.SelectContext(ctx, &candidates, query, userID, limit)
+	return candidates, err
+}
+
+// GenreAffinityCandidates scores films by how often the user has watched
+// their genre, favouring recent releases within a genre
+func (q *Queries) GenreAffinityCandidates(ctx context.Context, userID uuid.UUID, limit int) ([]ScoredFilm, error) {
+	var candidates []ScoredFilm
+	query := `
+		WITH affinity AS (
+			SELECT wf.genre, SUM(w.watch_count)::float8 AS weight
+			FROM watch_history w
+			JOIN films wf ON wf.id = w.film_id
+			WHERE w.user_id = $1 AND wf.genre IS NOT NULL AND wf.genre <> ''
+			GROUP BY wf.genre
+		)
+		SELECT f.id AS film_id, a.weight AS score
+		FROM films f
+		JOIN affinity a ON a.genre = f.genre
+		WHERE ` + recommendableFilm + `
+		ORDER BY a.weight DESC, f.published_at DESC
+		LIMIT $2
+	`
+	err := q.db.SelectContext(ctx, &candidates, query, userID, limit)
+	return candidates, err
+}
+
+// FollowedCreatorCandidates returns recent films from creators the user
+// follows, scored by recency
+func (q *Queries) FollowedCreatorCandidates(ctx context.Context, userID uuid.UUID, limit int) ([]ScoredFilm, error) {
+	var candidates []ScoredFilm
+	query := `
+		SELECT f.id AS film_id,
+		       1.0 / (1 + EXTRACT(EPOCH FROM NOW() - f.published_at) / 86400) AS score
+		FROM creator_follows cf
+		JOIN films f ON f.created_by_id = cf.creator_id
+		WHERE cf.follower_id = $1 AND ` + recommendableFilm + `
+		ORDER BY f.published_at DESC
+		LIMIT $2
+	`
+	err := q.db.SelectContext(ctx, &candidates, query, userID, limit)
+	return candidates, err
+}
+
+// ListActiveViewers returns users who watched something or followed a
+// creator since the given time
+func (q *Queries) ListActiveViewers(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := `
+		SELECT user_id FROM watch_history WHERE last_watched_at >= $1
+		UNION
+		SELECT follower_id FROM creator_follows WHERE created_at >= $1
+	`
+	err := q.db.SelectContext(ctx, &ids, query, since)
+	return ids, err
+}
+
+// GetRelatedFilms scores published films against film by shared creator,
+// shared genre and tag overlap, mixed with the given weights
+func (q *Queries) GetRelatedFilms(ctx context.Context, film *models.Film, weights models.RelatedWeights, limit int) ([]models.RelatedFilm, error) {
+	related := []models.RelatedFilm{}
+	tags := film.Tags
+	if tags == nil {
+		tags = pq.StringArray{}
+	}
+
+	query := `
+		SELECT * FROM (
+			SELECT f.*,
+			       COALESCE(jsonb_build_object(
+			           'id', u.id,
+			           'email', u.email,
+			           'name', u.name,
+			           'avatar_url', u.avatar_url
+			       )::json, '{}'::json) as created_by,
+			       (CASE WHEN f.created_by_id = $2 THEN $5::float8 ELSE 0 END)
+			     + (CASE WHEN $3 <> '' AND f.genre = $3 THEN $6::float8 ELSE 0 END)
+			     + $7::float8 * COALESCE(cardinality(ARRAY(
+			           SELECT unnest(f.tags) INTERSECT SELECT unnest($4::text[])
+			       ))::float8 / NULLIF(cardinality($4::text[]), 0), 0) AS score
+			FROM films f
+			LEFT JOIN users u ON f.created_by_id = u.id
+			WHERE f.id <> $1
+			  AND f.status = 'READY'
+			  AND f.visibility = 'PUBLIC'
+			  AND f.published_at IS NOT NULL
+			  AND (f.created_by_id = $2 OR ($3 <> '' AND f.genre = $3) OR f.tags && $4::text[])
+		) scored
+		WHERE score > 0
+		ORDER BY score DESC, published_at DESC
+		LIMIT $8
+	`
+	err := q.db.SelectContext(ctx, &related, query,
+		film.ID, film.CreatedByID, film.Genre, tags,
+		weights.Creator, weights.Genre, weights.Tags, limit,
+	)
+	return related, err
+}
@@ -0,0 +1,16 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ========== SOURCE REGION QUERIES ==========
+
+// SetFilmSourceRegion records the region of the bucket a film's source is
+// uploaded to
+func (q *Queries) SetFilmSourceRegion(ctx context.Context, filmID uuid.UUID, region string) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE films SET source_region = $1 WHERE id = $2`, region, filmID)
+	return err
+}
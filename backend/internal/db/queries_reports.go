@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== CONTENT REPORT QUERIES ==========
+
+// ReportFilm records a user's report of a film. It returns false when the
+// user already has an open report of it.
+func (q *Queries) ReportFilm(ctx context.Context, filmID, userID uuid.UUID, category models.ReportCategory, reason string) (bool, error) {
+	result, err := q.db.ExecContext(ctx, `
+		INSERT INTO film_reports (film_id, user_id, category, reason) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (film_id, user_id) WHERE resolved_at IS NULL DO NOTHING
+	`, filmID, userID, category, reason)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ListFilmReports lists a film's open reports, newest first
+func (q *Queries) ListFilmReports(ctx context.Context, filmID uuid.UUID, offset, limit int) ([]models.FilmReport, error) {
+	reports := []models.FilmReport{}
+	query := `
+		SELECT * FROM film_reports
+		WHERE film_id = $1 AND resolved_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		OFFSET $2 LIMIT $3
+	`
+	err := q.db.SelectContext(ctx, &reports, query, filmID, offset, limit)
+	return reports, err
+}
+
+// CountFilmReports counts a film's open reports
+func (q *Queries) CountFilmReports(ctx context.Context, filmID uuid.UUID) (int, error) {
+	var count int
+	err := q.db.GetContext(ctx, &count,
+		`SELECT COUNT(*) FROM film_reports WHERE film_id = $1 AND resolved_at IS NULL`, filmID)
+	return count, err
+}
+
+// ResolveFilmReports closes a film's open reports and returns how many
+// there were
+func (q *Queries) ResolveFilmReports(ctx context.Context, filmID, resolverID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, `
+		UPDATE film_reports SET resolved_at = NOW(), resolved_by_id = $2
+		WHERE film_id = $1 AND resolved_at IS NULL
+	`, filmID, resolverID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// reportedTargetsCTE aggregates the open film and comment reports matching
+// the filter per target, as the CTE targets. The actor is the reporter,
+// the range applies to report times and action is the category.
+func reportedTargetsCTE(filter AdminListFilter) (string, *whereBuilder) {
+	w := &whereBuilder{}
+	filter.applyActorAndRange(w, "rp.user_id", "rp.created_at")
+	if filter.Action != "" {
+		w.add("rp.category = ?", filter.Action)
+	}
+	if filter.Target != "" {
+		w.add("rp.target_type = ?", filter.Target)
+	}
+
+	cte := `
+		WITH open_reports AS (
+			SELECT 'film' AS target_type, fr.film_id AS target_id, fr.category, fr.user_id, fr.created_at
+			FROM film_reports fr
+			WHERE fr.resolved_at IS NULL
+			UNION ALL
+			SELECT 'comment', cr.comment_id, cr.category, cr.user_id, cr.created_at
+			FROM comment_reports cr
+		), per_category AS (
+			SELECT rp.target_type, rp.target_id, rp.category, COUNT(*) AS reports,
+			       MIN(rp.created_at) AS first_reported_at, MAX(rp.created_at) AS last_reported_at
+			FROM open_reports rp
+			` + w.sql() + `
+			GROUP BY rp.target_type, rp.target_id, rp.category
+		), targets AS (
+			SELECT target_type, target_id, SUM(reports)::int AS reports,
+			       jsonb_object_agg(category, reports) AS categories,
+			       MIN(first_reported_at) AS first_reported_at, MAX(last_reported_at) AS last_reported_at
+			FROM per_category
+			GROUP BY target_type, target_id
+		)
+	`
+	return cte, w
+}
+
+// ListReportedTargets lists the films and comments with open reports
+// matching the filter, most recently reported first, with their reports
+// counted per category
+func (q *Queries) ListReportedTargets(ctx context.Context, filter AdminListFilter, after *AdminCursor, offset, limit int) ([]models.ReportedTarget, error) {
+	cte, reports := reportedTargetsCTE(filter)
+	w := &whereBuilder{args: reports.args}
+	order := adminOrder(w, "t.last_reported_at", "t.target_id", after, offset, limit)
+
+	targets := []models.ReportedTarget{}
+	query := cte + `
+		SELECT t.*, f.id AS film_id, f.title AS film_title, COALESCE(LEFT(c.body, 200), '') AS excerpt
+		FROM targets t
+		LEFT JOIN comments c ON t.target_type = 'comment' AND c.id = t.target_id
+		JOIN films f ON f.id = COALESCE(c.film_id, t.target_id)
+		` + w.sql() + `
+		` + order
+	err := q.db.SelectContext(ctx, &targets, query, w.args...)
+	return targets, err
+}
+
+// CountReportedTargets counts the films and comments with open reports
+// matching the filter
+func (q *Queries) CountReportedTargets(ctx context.Context, filter AdminListFilter) (int, error) {
+	cte, w := reportedTargetsCTE(filter)
+	var count int
+	err := q.db.GetContext(ctx, &count, cte+`SELECT COUNT(*) FROM targets`, w.args...)
+	return count, err
+}
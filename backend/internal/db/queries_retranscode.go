@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// SetVideoAssetQC records a rendition's quality scores against the
+// original video. A nil score was not measured.
+func (q *Queries) SetVideoAssetQC(ctx context.Context, filmID uuid.UUID, variant, quality string, vmaf, psnr *float64) error {
+	_, err := q.db.ExecContext(ctx, `
+		UPDATE video_assets SET vmaf = $4, psnr = $5, qc_at = NOW()
+		WHERE film_id = $1 AND variant = $2 AND quality = $3
+	`, filmID, variant, quality, vmaf, psnr)
+	return err
+}
+
+// DeleteVideoAssetVariant forgets every rendition of one variant of a film
+func (q *Queries) DeleteVideoAssetVariant(ctx context.Context, filmID uuid.UUID, variant string) error {
+	_, err := q.db.ExecContext(ctx, `DELETE FROM video_assets WHERE film_id = $1 AND variant = $2`, filmID, variant)
+	return err
+}
+
+// PromoteVideoAssetVariant makes a variant's renditions the film's default
+// set, keeping their stats and QC scores. Their URLs are rewritten from
+// fromPrefix to toPrefix, as their files were copied to the default paths.
+func (q *Queries) PromoteVideoAssetVariant(ctx context.Context, filmID uuid.UUID, variant, fromPrefix, toPrefix string) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM video_assets WHERE film_id = $1 AND variant = $2`,
+		filmID, models.VariantDefault)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO video_assets (id, film_id, quality, variant, hls_index_url, size_bytes,
+			first_segment_url, segment_count, avg_segment_bytes, avg_segment_seconds, bandwidth,
+			vmaf, psnr, qc_at)
+		SELECT uuid_generate_v4(), film_id, quality, $3, replace(hls_index_url, $4, $5), size_bytes,
+			replace(first_segment_url, $4, $5), segment_count, avg_segment_bytes, avg_segment_seconds, bandwidth,
+			vmaf, psnr, qc_at
+		FROM video_assets
+		WHERE film_id = $1 AND variant = $2
+	`, filmID, variant, models.VariantDefault, fromPrefix, toPrefix)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM video_assets WHERE film_id = $1 AND variant = $2`, filmID, variant)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== REVENUE LEDGER QUERIES ==========
+
+// RecordFilmTransaction appends a transaction to the revenue ledger.
+// Transactions with an external_ref already recorded are ignored, so
+// payment webhooks can be replayed safely; created reports whether a row
+// was written.
+func (q *Queries) RecordFilmTransaction(ctx context.Context, t *models.FilmTransaction) (created bool, err error) {
+	query := `
+		INSERT INTO film_transactions (film_id, user_id, kind, amount_cents, currency, refund_of, external_ref, occurred_at)
+		VALUES (:film_id, :user_id, :kind, :amount_cents, :currency, :refund_of, :external_ref, :occurred_at)
+		ON CONFLICT (external_ref) DO NOTHING
+		RETURNING id, created_at
+	`
+	rows, err := q.db.NamedQueryContext(ctx, query, t)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return true, rows.Scan(&t.ID, &t.CreatedAt)
+	}
+	return false, rows.Err()
+}
+
+// ========== REVENUE REPORTING QUERIES ==========
+
+// GetFilmRevenueByDay returns a film's revenue per day and currency in
+// [from, to)
+func (q *Queries) GetFilmRevenueByDay(ctx context.Context, filmID uuid.UUID, from, to time.Time) ([]models.RevenueDay, error) {
+	days := []models.RevenueDay{}
+	query := `
+		SELECT date_trunc('day', occurred_at) AS day,
+		       currency,
+		       COALESCE(SUM(amount_cents) FILTER (WHERE kind <> 'refund'), 0) AS gross_cents,
+		       COALESCE(-SUM(amount_cents) FILTER (WHERE kind = 'refund'), 0) AS refund_cents,
+		       SUM(amount_cents) AS net_cents,
+		       COUNT(*) FILTER (WHERE kind = 'rental') AS rentals,
+		       COUNT(*) FILTER (WHERE kind = 'purchase') AS purchases,
+		       COUNT(*) FILTER (WHERE kind = 'subscription') AS subscriptions,
+		       COUNT(*) FILTER (WHERE kind = 'refund') AS refunds
+		FROM film_transactions
+		WHERE film_id = $1 AND occurred_at >= $2 AND occurred_at < $3
+		GROUP BY 1, 2
+		ORDER BY day, currency
+	`
+	err := q.db.SelectContext(ctx, &days, query, filmID, from, to)
+	return days, err
+}
+
+// GetFilmRevenueConversion counts signed-in viewers who watched a film's
+// trailer in [from, to) and how many of them rented or bought it
+// afterwards (by to). Trailer views anonymized by retention no longer
+// count.
+func (q *Queries) GetFilmRevenueConversion(ctx context.Context, filmID uuid.UUID, from, to time.Time) (*models.RevenueConversion, error) {
+	var conversion models.RevenueConversion
+	query := `
+		WITH viewers AS (
+			SELECT user_id, MIN(occurred_at) AS first_seen
+			FROM analytics_events
+			WHERE film_id = $1 AND event_type = $4 AND user_id IS NOT NULL
+			  AND occurred_at >= $2 AND occurred_at < $3
+			GROUP BY user_id
+		)
+		SELECT COUNT(*) AS trailer_viewers,
+		       COUNT(*) FILTER (WHERE EXISTS (
+		           SELECT 1 FROM film_transactions t
+		           WHERE t.film_id = $1 AND t.user_id = v.user_id
+		             AND t.kind IN ('rental', 'purchase')
+		             AND t.occurred_at >= v.first_seen AND t.occurred_at < $3
+		       )) AS buyers
+		FROM viewers v
+	`
+	if err := q.db.GetContext(ctx, &conversion, query, filmID, from, to, models.EventTrailer); err != nil {
+		return nil, err
+	}
+	if conversion.TrailerViewers > 0 {
+		conversion.Rate = float64(conversion.Buyers) / float64(conversion.TrailerViewers)
+	}
+	return &conversion, nil
+}
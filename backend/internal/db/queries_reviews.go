@@ -0,0 +1,322 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ========== REVIEW QUERIES ==========
+
+// UpsertReview saves a user's rating and review of a ready film, replacing
+// their previous one, and refreshes the film's rating summary. It returns
+// sql.ErrNoRows if the film is not ready. Moderation state is kept.
+func (q *Queries) UpsertReview(ctx context.Context, filmID, userID uuid.UUID, rating int, body string) (*models.Review, *models.FilmRating, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockReadyFilm(ctx, tx, filmID); err != nil {
+		return nil, nil, err
+	}
+
+	var review models.Review
+	query := `
+		INSERT INTO film_reviews (film_id, user_id, rating, body)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (film_id, user_id) DO UPDATE SET rating = EXCLUDED.rating, body = EXCLUDED.body
+		RETURNING *
+	`
+	if err := tx.GetContext(ctx, &review, query, filmID, userID, rating, body); err != nil {
+		return nil, nil, err
+	}
+
+	summary, err := refreshFilmRating(ctx, tx, filmID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return &review, summary, nil
+}
+
+// DeleteReview removes a user's review of a film and refreshes the film's
+// rating summary, returning sql.ErrNoRows if they have none
+func (q *Queries) DeleteReview(ctx context.Context, filmID, userID uuid.UUID) (*models.FilmRating, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM films WHERE id = $1 FOR UPDATE`, filmID); err != nil {
+		return nil, err
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM film_reviews WHERE film_id = $1 AND user_id = $2`, filmID, userID)
+	if err != nil {
+		return nil, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	summary, err := refreshFilmRating(ctx, tx, filmID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// GetReview retrieves a review by ID
+func (q *Queries) GetReview(ctx context.Context, id uuid.UUID) (*models.Review, error) {
+	var review models.Review
+	query := `
+		SELECT r.*, COALESCE(u.name, '') AS author_name
+		FROM film_reviews r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.id = $1
+	`
+	if err := q.db.GetContext(ctx, &review, query, id); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetUserReview retrieves a user's own review of a film, hidden or not
+func (q *Queries) GetUserReview(ctx context.Context, filmID, userID uuid.UUID) (*models.Review, error) {
+	var review models.Review
+	query := `
+		SELECT r.*, COALESCE(u.name, '') AS author_name
+		FROM film_reviews r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.film_id = $1 AND r.user_id = $2
+	`
+	if err := q.db.GetContext(ctx, &review, query, filmID, userID); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ListFilmReviews lists a film's visible reviews, newest first
+func (q *Queries) ListFilmReviews(ctx context.Context, filmID uuid.UUID, offset, limit int) ([]models.Review, error) {
+	reviews := []models.Review{}
+	query := `
+		SELECT r.*, COALESCE(u.name, '') AS author_name
+		FROM film_reviews r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.film_id = $1 AND NOT r.hidden
+		ORDER BY r.created_at DESC, r.id
+		OFFSET $2 LIMIT $3
+	`
+	err := q.db.SelectContext(ctx, &reviews, query, filmID, offset, limit)
+	return reviews, err
+}
+
+// CountFilmReviews returns how many visible reviews a film has
+func (q *Queries) CountFilmReviews(ctx context.Context, filmID uuid.UUID) (int, error) {
+	var count int
+	err := q.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM film_reviews WHERE film_id = $1 AND NOT hidden`, filmID)
+	return count, err
+}
+
+// FlagReview records a user's flag on a review; flagging twice has no
+// effect. A review that reaches autoHideAt flags (0 = never) and has not
+// been moderated is hidden. It returns the updated review, or
+// sql.ErrNoRows for an unknown review.
+func (q *Queries) FlagReview(ctx context.Context, reviewID, userID uuid.UUID, reason string, autoHideAt int) (*models.Review, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	review, err := lockReview(ctx, tx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO review_flags (review_id, user_id, reason) VALUES ($1, $2, $3)
+		ON CONFLICT (review_id, user_id) DO NOTHING
+	`, reviewID, userID, reason)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return review, err
+	}
+
+	wasHidden := review.Hidden
+	query := `
+		UPDATE film_reviews
+		SET flag_count = flag_count + 1,
+		    hidden = hidden OR ($2 > 0 AND moderated_at IS NULL AND flag_count + 1 >= $2)
+		WHERE id = $1
+		RETURNING *
+	`
+	if err := tx.GetContext(ctx, review, query, reviewID, autoHideAt); err != nil {
+		return nil, err
+	}
+	if review.Hidden && !wasHidden {
+		if _, err := refreshFilmRating(ctx, tx, review.FilmID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// ListFlaggedReviews lists reviews with open flags, most recently flagged
+// first. The actor is the flagger and the range applies to flag times;
+// action is hidden or visible.
+func (q *Queries) ListFlaggedReviews(ctx context.Context, filter AdminListFilter, after *AdminCursor, offset, limit int) ([]models.FlaggedReview, error) {
+	flags := &whereBuilder{}
+	flags.add("rf.review_id = r.id")
+	filter.applyActorAndRange(flags, "rf.user_id", "rf.created_at")
+
+	w := &whereBuilder{args: flags.args}
+	w.add("r.flag_count > 0")
+	reviewHiddenFilter(w, filter.Action)
+	order := adminOrder(w, "fl.last_flagged_at", "r.id", after, offset, limit)
+
+	reviews := []models.FlaggedReview{}
+	query := `
+		SELECT r.*, COALESCE(u.name, '') AS author_name, fl.last_flagged_at
+		FROM film_reviews r
+		JOIN users u ON u.id = r.user_id
+		JOIN LATERAL (
+			SELECT MAX(rf.created_at) AS last_flagged_at
+			FROM review_flags rf
+			` + flags.sql() + `
+		) fl ON fl.last_flagged_at IS NOT NULL
+		` + w.sql() + `
+		` + order
+	err := q.db.SelectContext(ctx, &reviews, query, w.args...)
+	return reviews, err
+}
+
+// CountFlaggedReviews returns how many reviews have open flags matching
+// the filter
+func (q *Queries) CountFlaggedReviews(ctx context.Context, filter AdminListFilter) (int, error) {
+	flags := &whereBuilder{}
+	flags.add("rf.review_id = r.id")
+	filter.applyActorAndRange(flags, "rf.user_id", "rf.created_at")
+
+	w := &whereBuilder{args: flags.args}
+	w.add("r.flag_count > 0")
+	reviewHiddenFilter(w, filter.Action)
+	w.add("EXISTS (SELECT 1 FROM review_flags rf " + flags.sql() + ")")
+
+	var count int
+	err := q.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM film_reviews r `+w.sql(), w.args...)
+	return count, err
+}
+
+// reviewHiddenFilter restricts flagged reviews to the hidden or visible ones
+func reviewHiddenFilter(w *whereBuilder, action string) {
+	switch action {
+	case models.FlaggedReviewHidden:
+		w.add("r.hidden")
+	case models.FlaggedReviewVisible:
+		w.add("NOT r.hidden")
+	}
+}
+
+// ModerateReview hides or restores a review, resolving its open flags, and
+// refreshes the film's rating summary. A moderated review is no longer
+// auto-hidden by new flags. It returns sql.ErrNoRows for an unknown review.
+func (q *Queries) ModerateReview(ctx context.Context, reviewID, moderatorID uuid.UUID, hidden bool) (*models.Review, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	review, err := lockReview(ctx, tx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM review_flags WHERE review_id = $1`, reviewID); err != nil {
+		return nil, err
+	}
+	query := `
+		UPDATE film_reviews
+		SET hidden = $2, flag_count = 0, moderated_by_id = $3, moderated_at = NOW()
+		WHERE id = $1
+		RETURNING *
+	`
+	if err := tx.GetContext(ctx, review, query, reviewID, hidden, moderatorID); err != nil {
+		return nil, err
+	}
+	if _, err := refreshFilmRating(ctx, tx, review.FilmID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// lockReadyFilm locks a ready film's row, serializing changes to its
+// rating summary; it returns sql.ErrNoRows if the film is not ready
+func lockReadyFilm(ctx context.Context, tx *sqlx.Tx, filmID uuid.UUID) error {
+	var exists bool
+	return tx.QueryRowContext(ctx,
+		`SELECT true FROM films WHERE id = $1 AND status = 'READY' FOR UPDATE`, filmID,
+	).Scan(&exists)
+}
+
+// lockReview locks a review and its film, film first like the other review
+// writes, and returns the review
+func lockReview(ctx context.Context, tx *sqlx.Tx, reviewID uuid.UUID) (*models.Review, error) {
+	var filmID uuid.UUID
+	if err := tx.GetContext(ctx, &filmID, `SELECT film_id FROM film_reviews WHERE id = $1`, reviewID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM films WHERE id = $1 FOR UPDATE`, filmID); err != nil {
+		return nil, err
+	}
+
+	var review models.Review
+	if err := tx.GetContext(ctx, &review, `SELECT * FROM film_reviews WHERE id = $1 FOR UPDATE`, reviewID); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// refreshFilmRating recomputes a film's rating summary from its visible
+// reviews
+func refreshFilmRating(ctx context.Context, tx *sqlx.Tx, filmID uuid.UUID) (*models.FilmRating, error) {
+	var summary models.FilmRating
+	query := `
+		UPDATE films SET
+			average_rating = COALESCE((
+				SELECT ROUND(AVG(rating), 2) FROM film_reviews WHERE film_id = $1 AND NOT hidden
+			), 0),
+			rating_count = (SELECT COUNT(*) FROM film_reviews WHERE film_id = $1 AND NOT hidden)
+		WHERE id = $1
+		RETURNING average_rating, rating_count
+	`
+	if err := tx.GetContext(ctx, &summary, query, filmID); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
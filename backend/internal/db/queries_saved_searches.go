@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== SAVED SEARCH QUERIES ==========
+
+// savedSearchMatchTitles is how many film titles a match summary carries
+const savedSearchMatchTitles = 3
+
+// CreateSavedSearch saves a search; it starts matching films published
+// from now on
+func (q *Queries) CreateSavedSearch(ctx context.Context, s *models.SavedSearch) error {
+	query := `
+		INSERT INTO saved_searches
+			(user_id, name, query, type, genre, creator_id, min_duration, max_duration, min_rating, email_alerts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, s, query,
+		s.UserID, s.Name, s.Query, s.Type, s.Genre, s.CreatorID,
+		s.MinDuration, s.MaxDuration, s.MinRating, s.EmailAlerts,
+	)
+}
+
+// GetSavedSearch returns one of a user's saved searches
+func (q *Queries) GetSavedSearch(ctx context.Context, userID, id uuid.UUID) (*models.SavedSearch, error) {
+	var s models.SavedSearch
+	query := `SELECT * FROM saved_searches WHERE id = $1 AND user_id = $2`
+	if err := q.db.GetContext(ctx, &s, query, id, userID); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListUserSavedSearches returns a user's saved searches, oldest first
+func (q *Queries) ListUserSavedSearches(ctx context.Context, userID uuid.UUID) ([]models.SavedSearch, error) {
+	searches := []models.SavedSearch{}
+	query := `SELECT * FROM saved_searches WHERE user_id = $1 ORDER BY created_at`
+	err := q.db.SelectContext(ctx, &searches, query, userID)
+	return searches, err
+}
+
+// CountUserSavedSearches returns how many searches a user has saved
+func (q *Queries) CountUserSavedSearches(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := q.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM saved_searches WHERE user_id = $1`, userID)
+	return count, err
+}
+
+// UpdateSavedSearch saves every editable field of a saved search. Matching
+// carries on from where it was, so films already alerted on are not
+// alerted on again.
+func (q *Queries) UpdateSavedSearch(ctx context.Context, s *models.SavedSearch) error {
+	query := `
+		UPDATE saved_searches
+		SET name = $3, query = $4, type = $5, genre = $6, creator_id = $7,
+		    min_duration = $8, max_duration = $9, min_rating = $10, email_alerts = $11
+		WHERE id = $1 AND user_id = $2
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, s, query,
+		s.ID, s.UserID, s.Name, s.Query, s.Type, s.Genre, s.CreatorID,
+		s.MinDuration, s.MaxDuration, s.MinRating, s.EmailAlerts,
+	)
+}
+
+// DeleteSavedSearch removes one of a user's saved searches, returning
+// sql.ErrNoRows if it does not exist
+func (q *Queries) DeleteSavedSearch(ctx context.Context, userID, id uuid.UUID) error {
+	result, err := q.db.ExecContext(ctx, `DELETE FROM saved_searches WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListSavedSearchMatches summarizes, per saved search, the ready films
+// published after its checked_until and up to until that match it. A
+// user's own films never match their searches.
+func (q *Queries) ListSavedSearchMatches(ctx context.Context, until time.Time) ([]models.SavedSearchMatch, error) {
+	matches := []models.SavedSearchMatch{}
+	query := `
+		SELECT s.id AS saved_search_id, s.user_id, u.email, s.name, s.email_alerts,
+		       COUNT(*) AS match_count,
+		       (array_agg(f.id ORDER BY f.published_at, f.id))[1] AS first_film_id,
+		       (array_agg(f.title ORDER BY f.published_at, f.id))[1:$2] AS titles
+		FROM saved_searches s
+		JOIN users u ON u.id = s.user_id
+		JOIN films f ON f.published_at > s.checked_until AND f.published_at <= $1
+		WHERE s.checked_until < $1
+		  AND f.status = 'READY'
+		  AND f.visibility = 'PUBLIC'
+		  AND f.created_by_id <> s.user_id
+		  AND (s.query = '' OR f.search_vector @@ websearch_to_tsquery('english', s.query))
+		  AND (s.type IS NULL OR f.type = s.type)
+		  AND (s.genre IS NULL OR f.genre = s.genre)
+		  AND (s.creator_id IS NULL OR f.created_by_id = s.creator_id)
+		  AND (s.min_duration IS NULL OR f.duration >= s.min_duration)
+		  AND (s.max_duration IS NULL OR f.duration <= s.max_duration)
+		  AND (s.min_rating IS NULL OR f.average_rating >= s.min_rating)
+		GROUP BY s.id, u.email
+	`
+	err := q.db.SelectContext(ctx, &matches, query, until, savedSearchMatchTitles)
+	return matches, err
+}
+
+// CommitSavedSearchAlerts stores the alerts for one matching pass and
+// advances every saved search to until in one transaction, so a pass is
+// never alerted twice
+func (q *Queries) CommitSavedSearchAlerts(ctx context.Context, until time.Time, notifications []models.Notification) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if len(notifications) > 0 {
+		query := `
+			INSERT INTO notifications (user_id, kind, film_id, tenant_id, message)
+			VALUES (:user_id, :kind, :film_id, :tenant_id, :message)
+		`
+		if _, err := tx.NamedExecContext(ctx, query, notifications); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE saved_searches SET checked_until = $1 WHERE checked_until < $1`, until); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== FILE SCAN QUERIES ==========
+
+// CreateFileScan records an uploaded file as waiting to be scanned. It is
+// queued again if no worker has scanned it within models.ScanStallTimeout.
+func (q *Queries) CreateFileScan(ctx context.Context, filmID uuid.UUID, kind models.ScanKind, objectKey string) (*models.FileScan, error) {
+	var scan models.FileScan
+	query := `
+		INSERT INTO file_scans (film_id, kind, object_key, next_attempt_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *
+	`
+	if err := q.db.GetContext(ctx, &scan, query, filmID, kind, objectKey, time.Now().Add(models.ScanStallTimeout)); err != nil {
+		return nil, err
+	}
+	return &scan, nil
+}
+
+// SetFileScanNextAttempt schedules when a scan is queued again
+func (q *Queries) SetFileScanNextAttempt(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE file_scans SET next_attempt_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+// ListDueSourceScans returns the source scans of films waiting in SCANNING
+// that reached no verdict, or were never picked up, and are due to be
+// queued again
+func (q *Queries) ListDueSourceScans(ctx context.Context, limit int) ([]models.FileScan, error) {
+	scans := []models.FileScan{}
+	query := `
+		SELECT s.* FROM file_scans s
+		JOIN films f ON f.id = s.film_id
+		WHERE s.kind = 'SOURCE'
+		  AND s.status IN ('PENDING', 'ERROR')
+		  AND s.next_attempt_at <= NOW()
+		  AND f.status = 'SCANNING'
+		  AND s.created_at = (
+		      SELECT MAX(created_at) FROM file_scans
+		      WHERE film_id = s.film_id AND kind = 'SOURCE'
+		  )
+		ORDER BY s.next_attempt_at
+		LIMIT $1
+	`
+	err := q.db.SelectContext(ctx, &scans, query, limit)
+	return scans, err
+}
+
+// ClaimFileScanRetry marks a due scan PENDING again and counts the attempt,
+// pushing its next attempt out by models.ScanStallTimeout. It reports false
+// when another worker claimed it first.
+func (q *Queries) ClaimFileScanRetry(ctx context.Context, scan *models.FileScan) (bool, error) {
+	query := `
+		UPDATE file_scans
+		SET status = 'PENDING', error = '', attempts = attempts + 1, next_attempt_at = $2
+		WHERE id = $1 AND next_attempt_at <= NOW() AND status IN ('PENDING', 'ERROR')
+		RETURNING *
+	`
+	err := q.db.GetContext(ctx, scan, query, scan.ID, time.Now().Add(models.ScanStallTimeout))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetFileScan retrieves a scan by ID
+func (q *Queries) GetFileScan(ctx context.Context, id uuid.UUID) (*models.FileScan, error) {
+	var scan models.FileScan
+	if err := q.db.GetContext(ctx, &scan, `SELECT * FROM file_scans WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &scan, nil
+}
+
+// CompleteFileScan stores a scan's verdict. A scan with a verdict is not
+// queued again.
+func (q *Queries) CompleteFileScan(ctx context.Context, scan *models.FileScan) error {
+	query := `
+		UPDATE file_scans
+		SET status = $2, scanner = $3, size_bytes = $4, sha256 = $5, signature = $6,
+		    error = $7, quarantine_key = $8, scanned_at = NOW(),
+		    next_attempt_at = CASE WHEN $9 THEN next_attempt_at END
+		WHERE id = $1
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, scan, query,
+		scan.ID, scan.Status, scan.Scanner, scan.SizeBytes, scan.SHA256,
+		scan.Signature, scan.Error, scan.QuarantineKey,
+		scan.Status == models.ScanPending || scan.Status == models.ScanError,
+	)
+}
+
+// FilmSourceRejected reports whether the latest scan of a film's source
+// found it infected
+func (q *Queries) FilmSourceRejected(ctx context.Context, filmID uuid.UUID) (bool, error) {
+	var rejected bool
+	query := `
+		SELECT COALESCE((
+			SELECT status = 'INFECTED' FROM file_scans
+			WHERE film_id = $1 AND kind = 'SOURCE'
+			ORDER BY created_at DESC
+			LIMIT 1
+		), FALSE)
+	`
+	err := q.db.GetContext(ctx, &rejected, query, filmID)
+	return rejected, err
+}
+
+// ListQuarantinedFiles returns infected uploads, most recently scanned
+// first. The actor is the film's creator and action the scanner.
+func (q *Queries) ListQuarantinedFiles(ctx context.Context, filter AdminListFilter, after *AdminCursor, offset, limit int) ([]models.QuarantinedFile, error) {
+	w := quarantineWhere(filter)
+	order := adminOrder(w, "s.scanned_at", "s.id", after, offset, limit)
+
+	files := []models.QuarantinedFile{}
+	query := `
+		SELECT s.*, f.title AS film_title, f.created_by_id AS creator_id
+		FROM file_scans s
+		JOIN films f ON f.id = s.film_id
+		` + w.sql() + `
+		` + order
+	err := q.db.SelectContext(ctx, &files, query, w.args...)
+	return files, err
+}
+
+// CountQuarantinedFiles counts infected uploads matching the filter
+func (q *Queries) CountQuarantinedFiles(ctx context.Context, filter AdminListFilter) (int, error) {
+	w := quarantineWhere(filter)
+	var count int
+	query := `SELECT COUNT(*) FROM file_scans s JOIN films f ON f.id = s.film_id ` + w.sql()
+	err := q.db.GetContext(ctx, &count, query, w.args...)
+	return count, err
+}
+
+func quarantineWhere(filter AdminListFilter) *whereBuilder {
+	w := &whereBuilder{}
+	w.add("s.status = 'INFECTED'")
+	filter.applyActorAndRange(w, "f.created_by_id", "s.scanned_at")
+	if filter.Action != "" {
+		w.add("s.scanner = ?", filter.Action)
+	}
+	return w
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== SEARCH SYNC QUERIES ==========
+
+// GetSearchSyncState returns an index's sync state, creating it the first
+// time the index is synced
+func (q *Queries) GetSearchSyncState(ctx context.Context, indexName string) (*models.SearchSyncState, error) {
+	var state models.SearchSyncState
+	query := `
+		INSERT INTO search_sync_state (index_name) VALUES ($1)
+		ON CONFLICT (index_name) DO UPDATE SET index_name = EXCLUDED.index_name
+		RETURNING *
+	`
+	if err := q.db.GetContext(ctx, &state, query, indexName); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SetSearchEventCursor records the film event stream entry an index has
+// been synced up to
+func (q *Queries) SetSearchEventCursor(ctx context.Context, indexName, cursor string) error {
+	query := `UPDATE search_sync_state SET event_cursor = $2, last_synced_at = NOW() WHERE index_name = $1`
+	_, err := q.db.ExecContext(ctx, query, indexName, cursor)
+	return err
+}
+
+// SetSearchWatermark records that films updated up to (at, id) are indexed
+func (q *Queries) SetSearchWatermark(ctx context.Context, indexName string, at time.Time, id uuid.UUID) error {
+	query := `
+		UPDATE search_sync_state SET watermark_at = $2, watermark_id = $3, last_synced_at = NOW()
+		WHERE index_name = $1
+	`
+	_, err := q.db.ExecContext(ctx, query, indexName, at, id)
+	return err
+}
+
+// SaveSearchCheck stores an index's latest consistency report
+func (q *Queries) SaveSearchCheck(ctx context.Context, report *models.SearchConsistencyReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE search_sync_state SET last_check = $2, last_checked_at = $3
+		WHERE index_name = $1
+	`
+	_, err = q.db.ExecContext(ctx, query, report.Index, data, report.CheckedAt)
+	return err
+}
+
+// ListFilmsUpdatedAfter returns up to limit films updated after (at, id)
+// and before until, in update order. A zero at starts from the first film.
+func (q *Queries) ListFilmsUpdatedAfter(ctx context.Context, at time.Time, id uuid.UUID, until time.Time, limit int) ([]models.FilmVersion, error) {
+	films := []models.FilmVersion{}
+	query := `
+		SELECT id, updated_at FROM films
+		WHERE (updated_at, id) > ($1, $2) AND updated_at < $3
+		ORDER BY updated_at, id
+		LIMIT $4
+	`
+	err := q.db.SelectContext(ctx, &films, query, at, id, until, limit)
+	return films, err
+}
+
+// ListFilmVersions returns the films of a search shard: those whose ID
+// starts with the shard's hex digit
+func (q *Queries) ListFilmVersions(ctx context.Context, shard int) ([]models.FilmVersion, error) {
+	films := []models.FilmVersion{}
+	query := `SELECT id, updated_at FROM films WHERE LEFT(id::text, 1) = $1`
+	err := q.db.SelectContext(ctx, &films, query, fmt.Sprintf("%x", shard))
+	return films, err
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ========== SETTINGS QUERIES ==========
+
+// ListSettings retrieves all stored settings
+func (q *Queries) ListSettings(ctx context.Context) ([]models.Setting, error) {
+	var settings []models.Setting
+	query := `SELECT * FROM settings ORDER BY key`
+	err := q.db.SelectContext(ctx, &settings, query)
+	return settings, err
+}
+
+// GetSetting retrieves a stored setting by key
+func (q *Queries) GetSetting(ctx context.Context, key string) (*models.Setting, error) {
+	var setting models.Setting
+	query := `SELECT * FROM settings WHERE key = $1`
+	err := q.db.GetContext(ctx, &setting, query, key)
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// UpsertSetting creates or replaces a setting value
+func (q *Queries) UpsertSetting(ctx context.Context, key string, settingType models.SettingType, value json.RawMessage, updatedByID *uuid.UUID) (*models.Setting, error) {
+	return upsertSetting(ctx, q.db, key, settingType, value, updatedByID)
+}
+
+func upsertSetting(ctx context.Context, ext sqlx.ExtContext, key string, settingType models.SettingType, value json.RawMessage, updatedByID *uuid.UUID) (*models.Setting, error) {
+	var setting models.Setting
+	query := `
+		INSERT INTO settings (key, type, value, updated_by_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO UPDATE
+		SET type = EXCLUDED.type,
+		    value = EXCLUDED.value,
+		    updated_by_id = EXCLUDED.updated_by_id
+		RETURNING *
+	`
+	err := sqlx.GetContext(ctx, ext, &setting, query, key, settingType, []byte(value), updatedByID)
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// DeleteSetting removes a stored setting so it falls back to its default
+func (q *Queries) DeleteSetting(ctx context.Context, key string) error {
+	return deleteSetting(ctx, q.db, key)
+}
+
+func deleteSetting(ctx context.Context, ext sqlx.ExtContext, key string) error {
+	query := `DELETE FROM settings WHERE key = $1`
+	_, err := ext.ExecContext(ctx, query, key)
+	return err
+}
+
+// ========== CONFIG BUNDLE QUERIES ==========
+
+// ConfigBundleWrites are the changes a configuration bundle import makes
+type ConfigBundleWrites struct {
+	// Settings are stored with their Key, Type, Value and UpdatedByID
+	Settings       []models.Setting
+	ResetSettings  []string
+	Profiles       []models.TranscodeProfile
+	CreateFeatured []*models.FeaturedFilm
+	UpdateFeatured []*models.FeaturedFilm
+	DeleteFeatured []uuid.UUID
+}
+
+// ApplyConfigBundle makes every write of a bundle import in a single
+// transaction, so a failure part way leaves the configuration as it was
+func (q *Queries) ApplyConfigBundle(ctx context.Context, writes ConfigBundleWrites) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, setting := range writes.Settings {
+		if _, err := upsertSetting(ctx, tx, setting.Key, setting.Type, setting.Value, setting.UpdatedByID); err != nil {
+			return fmt.Errorf("setting %s: %w", setting.Key, err)
+		}
+	}
+	for _, key := range writes.ResetSettings {
+		if err := deleteSetting(ctx, tx, key); err != nil {
+			return fmt.Errorf("setting %s: %w", key, err)
+		}
+	}
+	for i := range writes.Profiles {
+		if err := upsertTranscodeProfile(ctx, tx, &writes.Profiles[i]); err != nil {
+			return fmt.Errorf("transcode profile %s: %w", writes.Profiles[i].Name, err)
+		}
+	}
+	for _, id := range writes.DeleteFeatured {
+		if err := deleteFeaturedFilm(ctx, tx, id); err != nil {
+			return fmt.Errorf("featured film %s: %w", id, err)
+		}
+	}
+	for _, f := range writes.UpdateFeatured {
+		if err := updateFeaturedFilm(ctx, tx, f); err != nil {
+			return fmt.Errorf("featured film %s: %w", f.ID, err)
+		}
+	}
+	for _, f := range writes.CreateFeatured {
+		if err := createFeaturedFilm(ctx, tx, f); err != nil {
+			return fmt.Errorf("featured film %s: %w", f.FilmID, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== SHARE LINK QUERIES ==========
+
+// CreateShareLink returns the share link for a film and start offset,
+// creating it under code when there is none yet. It returns sql.ErrNoRows
+// when code is already taken by another link, so the caller can retry
+// with a new one.
+func (q *Queries) CreateShareLink(ctx context.Context, filmID uuid.UUID, startSeconds int, code string) (*models.ShareLink, error) {
+	var link models.ShareLink
+	query := `
+		INSERT INTO share_links (code, film_id, start_seconds)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		RETURNING *
+	`
+	err := q.db.GetContext(ctx, &link, query, code, filmID, startSeconds)
+	if err == nil {
+		return &link, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// Either the link exists already or the code is taken
+	err = q.db.GetContext(ctx, &link, `SELECT * FROM share_links WHERE film_id = $1 AND start_seconds = $2`, filmID, startSeconds)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// GetShareLink retrieves a share link by its code
+func (q *Queries) GetShareLink(ctx context.Context, code string) (*models.ShareLink, error) {
+	var link models.ShareLink
+	err := q.db.GetContext(ctx, &link, `SELECT * FROM share_links WHERE code = $1`, code)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== SOURCE RETENTION QUERIES ==========
+
+// ListSourceRetentionCandidates returns ready films whose source is due for
+// a delete or archive action: their override's right away, otherwise the
+// policy's action once they have been ready since before cutoff. A source
+// uploaded again after its last action is due again, and an archived one
+// is due when the action becomes delete.
+func (q *Queries) ListSourceRetentionCandidates(ctx context.Context, action models.SourceRetentionAction, cutoff time.Time, limit int) ([]models.SourceRetentionCandidate, error) {
+	candidates := []models.SourceRetentionCandidate{}
+	query := `
+		SELECT id, source_region, action
+		FROM (
+			SELECT f.id, f.source_region, f.source_retention, f.source_disposition, f.source_disposed_at,
+			       COALESCE(f.source_retention, $1) AS action,
+			       COALESCE(t.ready_at, f.created_at) AS ready_at
+			FROM films f
+			LEFT JOIN upload_timings t ON t.film_id = f.id
+			WHERE f.status = 'READY'
+		) c
+		WHERE action IN ('delete', 'archive')
+		  AND (source_retention IS NOT NULL OR ready_at < $2)
+		  AND (source_disposition IS NULL
+		       OR source_disposed_at < ready_at
+		       OR (source_disposition = 'archived' AND action = 'delete'))
+		ORDER BY ready_at
+		LIMIT $3
+	`
+	err := q.db.SelectContext(ctx, &candidates, query, action, cutoff, limit)
+	return candidates, err
+}
+
+// SetSourceDisposition records what the retention job did to a film's
+// source
+func (q *Queries) SetSourceDisposition(ctx context.Context, filmID uuid.UUID, disposition string) error {
+	query := `UPDATE films SET source_disposition = $2, source_disposed_at = NOW() WHERE id = $1`
+	_, err := q.db.ExecContext(ctx, query, filmID, disposition)
+	return err
+}
+
+// SetSourceRetention sets or, with nil, clears a film's retention override
+// and returns its retention state
+func (q *Queries) SetSourceRetention(ctx context.Context, filmID uuid.UUID, action *models.SourceRetentionAction) (*models.SourceRetention, error) {
+	var retention models.SourceRetention
+	query := `
+		UPDATE films SET source_retention = $2 WHERE id = $1
+		RETURNING id, source_retention, source_disposition, source_disposed_at
+	`
+	if err := q.db.GetContext(ctx, &retention, query, filmID, action); err != nil {
+		return nil, err
+	}
+	return &retention, nil
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// ========== FILM COUNT QUERIES ==========
+
+// ListFilmCounts retrieves maintained film counters for a dimension
+func (q *Queries) ListFilmCounts(ctx context.Context, dimension string) ([]models.FilmCount, error) {
+	var counts []models.FilmCount
+	query := `
+		SELECT dimension, value, count FROM film_counts
+		WHERE dimension = $1 AND count > 0
+		ORDER BY count DESC, value
+	`
+	err := q.db.SelectContext(ctx, &counts, query, dimension)
+	return counts, err
+}
+
+// ReconcileFilmCounts rebuilds the film counters from the films table,
+// correcting any drift from the incremental trigger updates
+func (q *Queries) ReconcileFilmCounts(ctx context.Context) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Block concurrent film writes so the rebuilt counts are consistent
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE films IN SHARE MODE`); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM film_counts`); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO film_counts (dimension, value, count)
+		SELECT 'creator', created_by_id::text, COUNT(*) FROM films GROUP BY created_by_id
+		UNION ALL
+		SELECT 'status', status, COUNT(*) FROM films GROUP BY status
+		UNION ALL
+		SELECT 'genre', genre, COUNT(*) FROM films GROUP BY genre
+	`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
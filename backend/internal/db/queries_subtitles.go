@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== SUBTITLE QUERIES ==========
+
+// UpsertSubtitle creates or replaces the subtitle track for a film language
+func (q *Queries) UpsertSubtitle(ctx context.Context, subtitle *models.Subtitle) error {
+	query := `
+		INSERT INTO subtitles (id, film_id, language, label, vtt_url, source_format, cue_count)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (film_id, language) DO UPDATE
+		SET label = EXCLUDED.label,
+		    vtt_url = EXCLUDED.vtt_url,
+		    source_format = EXCLUDED.source_format,
+		    cue_count = EXCLUDED.cue_count
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, subtitle, query,
+		subtitle.ID, subtitle.FilmID, subtitle.Language, subtitle.Label,
+		subtitle.VTTURL, subtitle.SourceFormat, subtitle.CueCount,
+	)
+}
+
+// GetSubtitlesByFilmID retrieves all subtitle tracks for a film
+func (q *Queries) GetSubtitlesByFilmID(ctx context.Context, filmID uuid.UUID) ([]models.Subtitle, error) {
+	var subtitles []models.Subtitle
+	query := `SELECT * FROM subtitles WHERE film_id = $1 ORDER BY language`
+	err := q.db.SelectContext(ctx, &subtitles, query, filmID)
+	return subtitles, err
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== TIMELINE QUERIES ==========
+
+// ListFilmMarkers returns a film's timeline markers in start order
+func (q *Queries) ListFilmMarkers(ctx context.Context, filmID uuid.UUID) ([]models.FilmMarker, error) {
+	markers := []models.FilmMarker{}
+	query := `SELECT * FROM film_markers WHERE film_id = $1 ORDER BY start_seconds, kind`
+	err := q.db.SelectContext(ctx, &markers, query, filmID)
+	return markers, err
+}
+
+// ReplaceFilmMarkers swaps all of a film's timeline markers for markers
+func (q *Queries) ReplaceFilmMarkers(ctx context.Context, filmID uuid.UUID, markers []models.FilmMarker) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM film_markers WHERE film_id = $1`, filmID); err != nil {
+		return err
+	}
+	for _, marker := range markers {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO film_markers (id, film_id, kind, title, start_seconds, end_seconds)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, marker.ID, filmID, marker.Kind, marker.Title, marker.StartSeconds, marker.EndSeconds)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UpsertFilmSprite records the thumbnail sprite sheet cut for a film
+func (q *Queries) UpsertFilmSprite(ctx context.Context, sprite *models.FilmSprite) error {
+	query := `
+		INSERT INTO film_sprites (film_id, url, interval_seconds, frame_count, columns, rows, tile_width, tile_height)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (film_id) DO UPDATE
+		SET url = EXCLUDED.url,
+		    interval_seconds = EXCLUDED.interval_seconds,
+		    frame_count = EXCLUDED.frame_count,
+		    columns = EXCLUDED.columns,
+		    rows = EXCLUDED.rows,
+		    tile_width = EXCLUDED.tile_width,
+		    tile_height = EXCLUDED.tile_height,
+		    created_at = NOW()
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, sprite, query,
+		sprite.FilmID, sprite.URL, sprite.IntervalSeconds, sprite.FrameCount,
+		sprite.Columns, sprite.Rows, sprite.TileWidth, sprite.TileHeight,
+	)
+}
+
+// GetFilmSprite retrieves a film's thumbnail sprite sheet
+func (q *Queries) GetFilmSprite(ctx context.Context, filmID uuid.UUID) (*models.FilmSprite, error) {
+	var sprite models.FilmSprite
+	err := q.db.GetContext(ctx, &sprite, `SELECT * FROM film_sprites WHERE film_id = $1`, filmID)
+	if err != nil {
+		return nil, err
+	}
+	return &sprite, nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// ========== TOP FILMS QUERIES ==========
+
+// topFilmsMax bounds how many films are kept per window; listings filter
+// by region and catalog fields at read time so the table holds more than
+// any single page
+const topFilmsMax = 1000
+
+// RefreshTopFilms rebuilds the view counters of one window covering the
+// given number of calendar days up to now. Complete days come from the
+// daily rollups; days not yet rolled up are counted from raw view events.
+func (q *Queries) RefreshTopFilms(ctx context.Context, window models.TopWindow, days int) (int64, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM film_top_counters WHERE time_window = $1`, window); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		WITH bounds AS (
+			SELECT (date_trunc('day', NOW()) - ($2::int - 1) * INTERVAL '1 day')::date AS window_start,
+			       COALESCE((SELECT MAX(period_start) + 1 FROM analytics_rollups WHERE period = 'day'),
+			                '-infinity'::date) AS raw_from
+		),
+		views AS (
+			SELECT r.film_id, r.event_count AS n
+			FROM analytics_rollups r, bounds b
+			WHERE r.period = 'day' AND r.event_type = 'view'
+			  AND r.period_start >= b.window_start AND r.period_start < b.raw_from
+			UNION ALL
+			SELECT e.film_id, COUNT(*)
+			FROM analytics_events e, bounds b
+			WHERE e.event_type = 'view' AND e.film_id IS NOT NULL AND NOT e.unverified
+			  AND e.occurred_at >= GREATEST(b.window_start, b.raw_from)
+			GROUP BY e.film_id
+		)
+		INSERT INTO film_top_counters (time_window, film_id, views)
+		SELECT $1, film_id, SUM(n)
+		FROM views
+		GROUP BY film_id
+		ORDER BY SUM(n) DESC
+		LIMIT $3
+	`, window, days, topFilmsMax)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return n, tx.Commit()
+}
+
+// ListTopFilms returns the most viewed films of a window matching filter,
+// along with when the window was last computed
+func (q *Queries) ListTopFilms(ctx context.Context, window models.TopWindow, filter FilmFilter, limit int) ([]models.TopFilm, *time.Time, error) {
+	where := filmWhere(filter)
+	where.add("f.published_at IS NOT NULL")
+	where.add("t.time_window = ?", window)
+
+	query := `
+		SELECT f.*,
+		       COALESCE(jsonb_build_object(
+		           'id', u.id,
+		           'email', u.email,
+		           'name', u.name,
+		           'avatar_url', u.avatar_url
+		       )::json, '{}'::json) as created_by,
+		       t.views AS window_views
+		FROM film_top_counters t
+		JOIN films f ON f.id = t.film_id
+		LEFT JOIN users u ON f.created_by_id = u.id
+		` + where.sql() + `
+		ORDER BY t.views DESC, f.id
+		LIMIT ` + where.arg(limit)
+
+	films := []models.TopFilm{}
+	if err := q.db.SelectContext(ctx, &films, query, where.args...); err != nil {
+		return nil, nil, err
+	}
+
+	var computedAt *time.Time
+	err := q.db.GetContext(ctx, &computedAt, `SELECT MAX(computed_at) FROM film_top_counters WHERE time_window = $1`, window)
+	return films, computedAt, err
+}
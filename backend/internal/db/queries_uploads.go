@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== UPLOAD LIFECYCLE QUERIES ==========
+
+// ListAbandonedFilms returns draft and uploaded films untouched since
+// cutoff that never had an upload confirmed, oldest first
+func (q *Queries) ListAbandonedFilms(ctx context.Context, cutoff time.Time, limit int) ([]models.AbandonedFilm, error) {
+	films := []models.AbandonedFilm{}
+	query := `
+		SELECT f.id, f.status, f.source_region
+		FROM films f
+		WHERE f.status IN ('DRAFT', 'UPLOADED')
+		  AND f.updated_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM transcode_jobs j WHERE j.film_id = f.id)
+		ORDER BY f.updated_at
+		LIMIT $2
+	`
+	err := q.db.SelectContext(ctx, &films, query, cutoff, limit)
+	return films, err
+}
+
+// ExpireFilm marks an abandoned film EXPIRED. It returns false, leaving
+// the film alone, when it was touched since cutoff, e.g. by a new upload.
+func (q *Queries) ExpireFilm(ctx context.Context, id uuid.UUID, cutoff time.Time) (bool, error) {
+	query := `
+		UPDATE films
+		SET status = 'EXPIRED'
+		WHERE id = $1 AND status IN ('DRAFT', 'UPLOADED') AND updated_at < $2
+	`
+	result, err := q.db.ExecContext(ctx, query, id, cutoff)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// CountTranscodingFilms counts the films whose transcode is queued or
+// running
+func (q *Queries) CountTranscodingFilms(ctx context.Context) (int64, error) {
+	var count int64
+	err := q.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM films WHERE status = 'TRANSCODING'`)
+	return count, err
+}
+
+// StartFilmTranscoding marks a film TRANSCODING once its deferred
+// transcode is queued
+func (q *Queries) StartFilmTranscoding(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE films SET status = 'TRANSCODING' WHERE id = $1`, id)
+	return err
+}
+
+// SetFilmSourceSize records the declared size of a film's source
+func (q *Queries) SetFilmSourceSize(ctx context.Context, id uuid.UUID, size int64) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE films SET source_size_bytes = $2 WHERE id = $1`, id, size)
+	return err
+}
+
+// GetCreatorSourceBytes sums the sizes of a creator's stored sources,
+// leaving out one film whose source is about to be replaced. Sources the
+// retention policy deleted and those of expired films are not counted.
+func (q *Queries) GetCreatorSourceBytes(ctx context.Context, creatorID, excludeFilmID uuid.UUID) (int64, error) {
+	var total int64
+	err := q.db.GetContext(ctx, &total, `
+		SELECT COALESCE(SUM(source_size_bytes), 0)
+		FROM films
+		WHERE created_by_id = $1 AND id <> $2
+		  AND status <> 'EXPIRED'
+		  AND source_disposition IS DISTINCT FROM 'deleted'
+	`, creatorID, excludeFilmID)
+	return total, err
+}
+
+// SetFilmStatus sets a film's status outside a transaction, as the steps
+// between confirming an upload and transcoding it do
+func (q *Queries) SetFilmStatus(ctx context.Context, id uuid.UUID, status models.FilmStatus) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE films SET status = $2 WHERE id = $1`, id, status)
+	return err
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ========== WATCHLIST QUERIES ==========
+
+// AddToWatchlist saves a film to a user's watchlist and returns when it
+// was added; saving it again keeps the original time
+func (q *Queries) AddToWatchlist(ctx context.Context, userID, filmID uuid.UUID) (time.Time, error) {
+	var addedAt time.Time
+	query := `
+		INSERT INTO watchlist_items (user_id, film_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, film_id) DO UPDATE SET added_at = watchlist_items.added_at
+		RETURNING added_at
+	`
+	err := q.db.QueryRowContext(ctx, query, userID, filmID).Scan(&addedAt)
+	return addedAt, err
+}
+
+// RemoveFromWatchlist removes a film from a user's watchlist; removing one
+// that isn't there is a no-op
+func (q *Queries) RemoveFromWatchlist(ctx context.Context, userID, filmID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, `DELETE FROM watchlist_items WHERE user_id = $1 AND film_id = $2`, userID, filmID)
+	return err
+}
+
+// watchlistWhere restricts a user's watchlist to films they may still
+// watch from country. Films made private or unavailable stay saved but are
+// left out until that changes.
+func watchlistWhere(userID uuid.UUID, country string) *whereBuilder {
+	w := filmWhere(FilmFilter{Status: models.StatusReady, Region: &country})
+	w.add("wl.user_id = ?", userID)
+	w.add("f.published_at IS NOT NULL")
+	w.add("f.visibility <> ?", models.VisibilityPrivate)
+	return w
+}
+
+// ListWatchlist returns the films on a user's watchlist, most recently
+// added first unless oldestFirst
+func (q *Queries) ListWatchlist(ctx context.Context, userID uuid.UUID, country string, oldestFirst bool, offset, limit int) ([]models.WatchlistFilm, error) {
+	order := "wl.added_at DESC, f.id"
+	if oldestFirst {
+		order = "wl.added_at, f.id"
+	}
+
+	where := watchlistWhere(userID, country)
+	query := `
+		SELECT f.*,
+		       COALESCE(jsonb_build_object(
+		           'id', u.id,
+		           'email', u.email,
+		           'name', u.name,
+		           'avatar_url', u.avatar_url
+		       )::json, '{}'::json) as created_by,
+		       wl.added_at
+		FROM watchlist_items wl
+		JOIN films f ON f.id = wl.film_id
+		LEFT JOIN users u ON f.created_by_id = u.id
+		` + where.sql() + `
+		ORDER BY ` + order + `
+		LIMIT ` + where.arg(limit) + ` OFFSET ` + where.arg(offset)
+
+	films := []models.WatchlistFilm{}
+	err := q.db.SelectContext(ctx, &films, query, where.args...)
+	return films, err
+}
+
+// CountWatchlist counts the films ListWatchlist returns across all pages
+func (q *Queries) CountWatchlist(ctx context.Context, userID uuid.UUID, country string) (int, error) {
+	where := watchlistWhere(userID, country)
+	query := `
+		SELECT COUNT(*)
+		FROM watchlist_items wl
+		JOIN films f ON f.id = wl.film_id
+		` + where.sql()
+
+	var count int
+	err := q.db.GetContext(ctx, &count, query, where.args...)
+	return count, err
+}
+
+// GetWatchlistedFilmIDs returns which of filmIDs are on a user's watchlist
+func (q *Queries) GetWatchlistedFilmIDs(ctx context.Context, userID uuid.UUID, filmIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	saved := map[uuid.UUID]bool{}
+	if len(filmIDs) == 0 {
+		return saved, nil
+	}
+
+	idStrings := make(pq.StringArray, len(filmIDs))
+	for i, id := range filmIDs {
+		idStrings[i] = id.String()
+	}
+
+	var ids []uuid.UUID
+	query := `SELECT film_id FROM watchlist_items WHERE user_id = $1 AND film_id = ANY($2::uuid[])`
+	if err := q.db.SelectContext(ctx, &ids, query, userID, idStrings); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		saved[id] = true
+	}
+	return saved, nil
+}
@@ -0,0 +1,328 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// ========== WEBHOOK ENDPOINT QUERIES ==========
+
+// CreateWebhookEndpoint stores a new endpoint
+func (q *Queries) CreateWebhookEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	query := `
+		INSERT INTO webhook_endpoints (id, user_id, url, secret, event_types, all_films)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, e, query, e.ID, e.UserID, e.URL, e.Secret, e.EventTypes, e.AllFilms)
+}
+
+// ListWebhookEndpoints returns a user's endpoints, newest first
+func (q *Queries) ListWebhookEndpoints(ctx context.Context, userID uuid.UUID) ([]models.WebhookEndpoint, error) {
+	endpoints := []models.WebhookEndpoint{}
+	query := `SELECT * FROM webhook_endpoints WHERE user_id = $1 ORDER BY created_at DESC`
+	err := q.db.SelectContext(ctx, &endpoints, query, userID)
+	return endpoints, err
+}
+
+// GetWebhookEndpoint returns an endpoint by ID
+func (q *Queries) GetWebhookEndpoint(ctx context.Context, id uuid.UUID) (*models.WebhookEndpoint, error) {
+	var e models.WebhookEndpoint
+	err := q.db.GetContext(ctx, &e, `SELECT * FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// DeleteWebhookEndpoint deletes an endpoint with its delivery history
+func (q *Queries) DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	return err
+}
+
+// EnableWebhookEndpoint re-enables an endpoint with a clean failure streak
+// and makes its waiting deliveries due now
+func (q *Queries) EnableWebhookEndpoint(ctx context.Context, id uuid.UUID) (*models.WebhookEndpoint, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var e models.WebhookEndpoint
+	err = tx.GetContext(ctx, &e, `
+		UPDATE webhook_endpoints
+		SET status = 'active', consecutive_failures = 0, failing_since = NULL,
+		    disabled_at = NULL, disabled_reason = NULL, updated_at = NOW()
+		WHERE id = $1
+		RETURNING *
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries SET next_attempt_at = NOW()
+		WHERE endpoint_id = $1 AND status = 'pending' AND next_attempt_at > NOW()
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, tx.Commit()
+}
+
+// GetWebhookHealth summarizes an endpoint's attempts since a time and its
+// pending and failed deliveries
+func (q *Queries) GetWebhookHealth(ctx context.Context, endpointID uuid.UUID, since time.Time) (*models.WebhookHealth, error) {
+	var h models.WebhookHealth
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM webhook_attempts WHERE endpoint_id = $1 AND attempted_at >= $2) AS attempts,
+			(SELECT COUNT(*) FROM webhook_attempts
+			 WHERE endpoint_id = $1 AND attempted_at >= $2 AND status_code BETWEEN 200 AND 299) AS successes,
+			(SELECT COALESCE(AVG(duration_ms), 0) FROM webhook_attempts
+			 WHERE endpoint_id = $1 AND attempted_at >= $2) AS avg_duration_ms,
+			(SELECT COUNT(*) FROM webhook_deliveries WHERE endpoint_id = $1 AND status = 'pending') AS pending,
+			(SELECT COUNT(*) FROM webhook_deliveries WHERE endpoint_id = $1 AND status = 'failed') AS failed
+	`
+	err := q.db.GetContext(ctx, &h, query, endpointID, since)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// ========== WEBHOOK DELIVERY QUERIES ==========
+
+// WebhookDeliveryFilter narrows an endpoint's deliveries. Empty fields
+// match everything; the range is [From, To).
+type WebhookDeliveryFilter struct {
+	Status    models.WebhookDeliveryStatus
+	EventType string
+	From      *time.Time
+	To        *time.Time
+}
+
+// webhookDeliveryWhere matches an endpoint's deliveries against a filter
+// passed as $1 endpoint, $2 status, $3 event type, $4 from and $5 to
+const webhookDeliveryWhere = `
+	WHERE endpoint_id = $1
+	  AND ($2 = '' OR status = $2)
+	  AND ($3 = '' OR event_type = $3)
+	  AND ($4::timestamptz IS NULL OR created_at >= $4)
+	  AND ($5::timestamptz IS NULL OR created_at < $5)
+`
+
+// EnqueueWebhookEvent queues a film event for every endpoint that wants
+// it: endpoints subscribed to its type and owned by the film's creator or
+// receiving all films. Disabled endpoints get it too, for when they are
+// re-enabled. An event is queued once per endpoint. Returns the number of
+// deliveries queued.
+func (q *Queries) EnqueueWebhookEvent(ctx context.Context, event *models.FilmEvent, payload string) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload)
+		SELECT e.id, $1, $2, $4
+		FROM webhook_endpoints e
+		WHERE (cardinality(e.event_types) = 0 OR $2 = ANY(e.event_types))
+		  AND (e.all_films OR e.user_id = (SELECT created_by_id FROM films WHERE id = $3))
+		ON CONFLICT (endpoint_id, event_id) WHERE replay_of IS NULL DO NOTHING
+	`
+	result, err := q.db.ExecContext(ctx, query, event.ID, string(event.Type), event.FilmID, payload)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CreateWebhookDelivery queues a delivery of a payload to one endpoint,
+// due now
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, endpoint_id, event_id, event_type, payload, replay_of)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`
+	return q.db.GetContext(ctx, d, query, d.ID, d.EndpointID, d.EventID, d.EventType, d.Payload, d.ReplayOf)
+}
+
+// ReplayWebhookDeliveries queues a new delivery of each of an endpoint's
+// original deliveries matching the filter, oldest first, up to limit.
+// Deliveries with a replay pending or succeeded already are skipped.
+// Returns the number queued.
+func (q *Queries) ReplayWebhookDeliveries(ctx context.Context, endpointID uuid.UUID, filter WebhookDeliveryFilter, limit int) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, replay_of)
+		SELECT endpoint_id, event_id, event_type, payload, id
+		FROM (
+			SELECT * FROM webhook_deliveries d
+			` + webhookDeliveryWhere + ` AND replay_of IS NULL
+			  AND NOT EXISTS (
+				SELECT 1 FROM webhook_deliveries r
+				WHERE r.replay_of = d.id AND r.status IN ('pending', 'succeeded')
+			  )
+			ORDER BY created_at
+			LIMIT $6
+		) matched
+	`
+	result, err := q.db.ExecContext(ctx, query, endpointID, string(filter.Status), filter.EventType,
+		filter.From, filter.To, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListWebhookDeliveries lists an endpoint's deliveries matching the
+// filter, newest first
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, endpointID uuid.UUID, filter WebhookDeliveryFilter, offset, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	query := `
+		SELECT * FROM webhook_deliveries
+		` + webhookDeliveryWhere + `
+		ORDER BY created_at DESC, id
+		OFFSET $6 LIMIT $7
+	`
+	err := q.db.SelectContext(ctx, &deliveries, query, endpointID, string(filter.Status), filter.EventType,
+		filter.From, filter.To, offset, limit)
+	return deliveries, err
+}
+
+// CountWebhookDeliveries returns how many of an endpoint's deliveries
+// match the filter
+func (q *Queries) CountWebhookDeliveries(ctx context.Context, endpointID uuid.UUID, filter WebhookDeliveryFilter) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM webhook_deliveries ` + webhookDeliveryWhere
+	err := q.db.GetContext(ctx, &count, query, endpointID, string(filter.Status), filter.EventType,
+		filter.From, filter.To)
+	return count, err
+}
+
+// GetWebhookDelivery returns a delivery by ID
+func (q *Queries) GetWebhookDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	err := q.db.GetContext(ctx, &d, `SELECT * FROM webhook_deliveries WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListWebhookAttempts returns a delivery's attempts, oldest first
+func (q *Queries) ListWebhookAttempts(ctx context.Context, deliveryID uuid.UUID) ([]models.WebhookAttempt, error) {
+	attempts := []models.WebhookAttempt{}
+	query := `SELECT * FROM webhook_attempts WHERE delivery_id = $1 ORDER BY attempted_at, id`
+	err := q.db.SelectContext(ctx, &attempts, query, deliveryID)
+	return attempts, err
+}
+
+// ClaimDueWebhookDeliveries returns up to limit pending deliveries that
+// are due, to active endpoints, oldest first. They are not due again for
+// lease, so a crashed sender's deliveries are retried.
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhook_endpoints e ON e.id = d.endpoint_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND e.status = 'active'
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING *
+	`
+	err := q.db.SelectContext(ctx, &deliveries, query, limit, lease.Seconds())
+	return deliveries, err
+}
+
+// RecordWebhookAttempt stores an attempt and moves its delivery to status,
+// due again at next while pending. The endpoint's failure streak is reset
+// on success and extended on failure; an active endpoint whose streak
+// reaches disableAfter failures over at least disableHours is disabled.
+// Reports whether the endpoint was disabled.
+func (q *Queries) RecordWebhookAttempt(ctx context.Context, a *models.WebhookAttempt, status models.WebhookDeliveryStatus, next time.Time, disableAfter, disableHours int) (bool, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	err = tx.GetContext(ctx, a, `
+		INSERT INTO webhook_attempts (delivery_id, endpoint_id, signed_at, signature, status_code, error, response_body, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING *
+	`, a.DeliveryID, a.EndpointID, a.SignedAt, a.Signature, a.StatusCode, a.Error, a.ResponseBody, a.DurationMs)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, status = $2, next_attempt_at = $3,
+		    finished_at = CASE WHEN $2 = 'pending' THEN NULL ELSE NOW() END
+		WHERE id = $1
+	`, a.DeliveryID, string(status), next)
+	if err != nil {
+		return false, err
+	}
+
+	if a.Succeeded() {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE webhook_endpoints
+			SET consecutive_failures = 0, failing_since = NULL, last_success_at = NOW(), updated_at = NOW()
+			WHERE id = $1
+		`, a.EndpointID)
+		if err != nil {
+			return false, err
+		}
+		return false, tx.Commit()
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_endpoints
+		SET consecutive_failures = consecutive_failures + 1,
+		    failing_since = COALESCE(failing_since, NOW()),
+		    last_failure_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, a.EndpointID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE webhook_endpoints
+		SET status = 'disabled', disabled_at = NOW(), disabled_reason = 'sustained delivery failures'
+		WHERE id = $1 AND status = 'active'
+		  AND consecutive_failures >= $2
+		  AND failing_since <= NOW() - make_interval(hours => $3)
+	`, a.EndpointID, disableAfter, disableHours)
+	if err != nil {
+		return false, err
+	}
+	disabled, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return disabled > 0, tx.Commit()
+}
+
+// DeleteWebhookDeliveries deletes deliveries created before the cutoff
+// that are no longer pending, with their attempts
+func (q *Queries) DeleteWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, `
+		DELETE FROM webhook_deliveries WHERE created_at < $1 AND status <> 'pending'
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,120 @@
+package entitlements
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/google/uuid"
+)
+
+// Unlimited is the plan of viewers never capped: a film's creator and
+// admins
+var Unlimited = models.PlaybackPlan{Key: "unlimited", Name: "Unlimited"}
+
+// Service resolves which plan, and so which renditions, a viewer gets for
+// a film
+type Service struct {
+	queries  *db.Queries
+	settings *settings.Service
+}
+
+// New creates an entitlement service
+func New(queries *db.Queries, settingsService *settings.Service) *Service {
+	return &Service{queries: queries, settings: settingsService}
+}
+
+// Catalog returns the plans that apply to a tenant's films: its own if it
+// defined any, otherwise the platform's. A nil tenant gets the platform's.
+func (s *Service) Catalog(ctx context.Context, tenantID *uuid.UUID) (*models.PlanCatalog, error) {
+	if tenantID != nil {
+		own, err := s.queries.GetTenantPlanCatalog(ctx, *tenantID)
+		switch {
+		case err == nil:
+			var catalog models.PlanCatalog
+			if err := json.Unmarshal(own.Catalog, &catalog); err != nil {
+				return nil, fmt.Errorf("invalid plan catalog of tenant %s: %w", tenantID, err)
+			}
+			return &catalog, nil
+		case !errors.Is(err, sql.ErrNoRows):
+			return nil, err
+		}
+	}
+
+	var catalog models.PlanCatalog
+	if err := s.settings.Decode(ctx, settings.KeyPlaybackPlans, &catalog); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// PlanFor returns a viewer's plan for a film, nil userID for anonymous
+// viewers. The first unexpired entitlement naming a plan of the film's
+// catalog wins, the organization's own before a platform-wide one;
+// without one the catalog's default applies.
+func (s *Service) PlanFor(ctx context.Context, film *models.Film, userID *uuid.UUID) (models.PlaybackPlan, error) {
+	catalog, err := s.Catalog(ctx, film.TenantID)
+	if err != nil {
+		return models.PlaybackPlan{}, err
+	}
+
+	if userID != nil {
+		entitlements, err := s.queries.ListActiveEntitlements(ctx, *userID, film.TenantID)
+		if err != nil {
+			return models.PlaybackPlan{}, err
+		}
+		for _, e := range entitlements {
+			if plan, ok := catalog.Plan(e.PlanKey); ok {
+				return plan, nil
+			}
+		}
+	}
+
+	plan, ok := catalog.Plan(catalog.Default)
+	if !ok {
+		return models.PlaybackPlan{}, fmt.Errorf("default plan %q is not in the catalog", catalog.Default)
+	}
+	return plan, nil
+}
+
+// QualityHeight returns the height of a rendition quality name like 720p,
+// or 0 when the name has none
+func QualityHeight(quality string) int {
+	height, err := strconv.Atoi(strings.TrimSuffix(quality, "p"))
+	if err != nil || height < 0 {
+		return 0
+	}
+	return height
+}
+
+// FilterAssets drops the renditions taller than maxHeight, reporting
+// whether any were. When every rendition is taller the shortest is kept so
+// the film still plays; renditions of unknown height are always kept.
+func FilterAssets(assets []models.VideoAsset, maxHeight int) ([]models.VideoAsset, bool) {
+	if maxHeight <= 0 {
+		return assets, false
+	}
+
+	lowest := 0
+	for _, a := range assets {
+		if h := QualityHeight(a.Quality); h > 0 && (lowest == 0 || h < lowest) {
+			lowest = h
+		}
+	}
+	limit := max(maxHeight, lowest)
+
+	kept := make([]models.VideoAsset, 0, len(assets))
+	for _, a := range assets {
+		if QualityHeight(a.Quality) <= limit {
+			kept = append(kept, a)
+		}
+	}
+	return kept, len(kept) < len(assets)
+}
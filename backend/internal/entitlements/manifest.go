@@ -0,0 +1,92 @@
+package entitlements
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	resolutionAttr = regexp.MustCompile(`RESOLUTION=\d+x(\d+)`)
+	uriAttr        = regexp.MustCompile(`URI="([^"]*)"`)
+)
+
+// CapMasterPlaylist rewrites an HLS master playlist for a plan: variant
+// and I-frame streams taller than maxHeight are dropped, keeping the
+// shortest when all are, like FilterAssets. Relative URIs are resolved
+// against base, the playlist's own URL, so the result can be served from
+// the API.
+func CapMasterPlaylist(playlist []byte, maxHeight int, base *url.URL) []byte {
+	lines := strings.Split(strings.ReplaceAll(string(playlist), "\r\n", "\n"), "\n")
+
+	lowest := 0
+	for _, line := range lines {
+		if h := streamHeight(line); h > 0 && (lowest == 0 || h < lowest) {
+			lowest = h
+		}
+	}
+	limit := 0
+	if maxHeight > 0 {
+		limit = max(maxHeight, lowest)
+	}
+	tooTall := func(line string) bool {
+		return limit > 0 && streamHeight(line) > limit
+	}
+
+	out := make([]string, 0, len(lines))
+	skipURI := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			out = append(out, line)
+		case strings.HasPrefix(trimmed, "#EXT-X-STREAM-INF:"):
+			// The variant's URI is on the next line
+			skipURI = tooTall(trimmed)
+			if !skipURI {
+				out = append(out, trimmed)
+			}
+		case strings.HasPrefix(trimmed, "#EXT-X-I-FRAME-STREAM-INF:"):
+			if !tooTall(trimmed) {
+				out = append(out, resolveURIAttrs(trimmed, base))
+			}
+		case strings.HasPrefix(trimmed, "#"):
+			out = append(out, resolveURIAttrs(trimmed, base))
+		default:
+			if !skipURI {
+				out = append(out, resolve(trimmed, base))
+			}
+			skipURI = false
+		}
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// streamHeight returns the RESOLUTION height of a stream tag, 0 for other
+// lines and audio-only streams
+func streamHeight(line string) int {
+	if !strings.HasPrefix(line, "#EXT-X-STREAM-INF:") && !strings.HasPrefix(line, "#EXT-X-I-FRAME-STREAM-INF:") {
+		return 0
+	}
+	m := resolutionAttr.FindStringSubmatch(line)
+	if m == nil {
+		return 0
+	}
+	h, _ := strconv.Atoi(m[1])
+	return h
+}
+
+func resolveURIAttrs(line string, base *url.URL) string {
+	return uriAttr.ReplaceAllStringFunc(line, func(attr string) string {
+		return `URI="` + resolve(uriAttr.FindStringSubmatch(attr)[1], base) + `"`
+	})
+}
+
+func resolve(ref string, base *url.URL) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
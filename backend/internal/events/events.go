@@ -0,0 +1,99 @@
+// Package events emits film lifecycle events (film.created, film.ready,
+// film.published) to the film event stream in Redis, and replays them for
+// consumers that need history.
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+// Emit publishes a lifecycle event for a film that just went through it.
+// It is best effort: a failure is logged and the caller carries on; a
+// backfill can re-emit the event later.
+func Emit(ctx context.Context, redisClient *redis.Client, eventType models.FilmEventType, filmID uuid.UUID) {
+	event := &models.FilmEvent{
+		ID:         uuid.New(),
+		Type:       eventType,
+		FilmID:     filmID,
+		OccurredAt: time.Now(),
+	}
+	if err := redisClient.PublishFilmEvent(ctx, event); err != nil {
+		log.Printf("[Events] Failed to emit %s for film %s: %v", eventType, filmID, err)
+	}
+}
+
+// ValidType reports whether t is a known lifecycle event type
+func ValidType(t models.FilmEventType) bool {
+	for _, known := range models.FilmEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Lifecycle returns the events a film has been through, oldest first,
+// marked as replayed. Only the given types are returned, or all when
+// types is empty.
+func Lifecycle(f *models.FilmLifecycle, types []models.FilmEventType) []models.FilmEvent {
+	want := func(t models.FilmEventType) bool {
+		if len(types) == 0 {
+			return true
+		}
+		for _, w := range types {
+			if w == t {
+				return true
+			}
+		}
+		return false
+	}
+
+	var out []models.FilmEvent
+	add := func(t models.FilmEventType, at *time.Time) {
+		if at == nil || !want(t) {
+			return
+		}
+		out = append(out, models.FilmEvent{
+			ID:         uuid.New(),
+			Type:       t,
+			FilmID:     f.FilmID,
+			OccurredAt: *at,
+			Replayed:   true,
+		})
+	}
+	add(models.FilmEventCreated, &f.CreatedAt)
+	add(models.FilmEventReady, f.ReadyAt)
+	add(models.FilmEventPublished, f.PublishedAt)
+	return out
+}
+
+// Replay publishes events in order at no more than rate per second and
+// returns how many were published. It stops at the first failure or when
+// ctx is cancelled.
+func Replay(ctx context.Context, redisClient *redis.Client, events []models.FilmEvent, rate int) (int, error) {
+	if rate <= 0 {
+		rate = 1
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	for i := range events {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return i, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		if err := redisClient.PublishFilmEvent(ctx, &events[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
@@ -0,0 +1,134 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// MaxSitemapURLs is the most URLs the sitemap protocol allows in one file
+const MaxSitemapURLs = 50000
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+}
+
+// Sitemap renders a sitemap with the home page and a page per film. Links
+// point at the frontend at appURL.
+func Sitemap(appURL string, films []models.SitemapFilm) ([]byte, error) {
+	appURL = strings.TrimRight(appURL, "/")
+
+	set := urlSet{
+		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  make([]sitemapURL, 0, len(films)+1),
+	}
+	set.URLs = append(set.URLs, sitemapURL{Loc: appURL + "/", ChangeFreq: "daily"})
+	for _, film := range films {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:     filmURL(appURL, film.ID.String()),
+			LastMod: film.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return encode(set)
+}
+
+// Channel describes an RSS feed
+type Channel struct {
+	Title       string
+	Link        string
+	Description string
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	XmlnsDC string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description,omitempty"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Category    string   `xml:"category,omitempty"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Enclosure   *rssFile `xml:"enclosure,omitempty"`
+}
+
+type rssFile struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// RSS renders an RSS 2.0 feed of films, newest first as given. Links point
+// at the frontend at appURL.
+func RSS(appURL string, channel Channel, films []models.FeedFilm, now time.Time) ([]byte, error) {
+	appURL = strings.TrimRight(appURL, "/")
+
+	feed := rss{
+		Version: "2.0",
+		XmlnsDC: "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         channel.Title,
+			Link:          channel.Link,
+			Description:   channel.Description,
+			Language:      "en",
+			LastBuildDate: now.UTC().Format(time.RFC1123Z),
+			Items:         make([]rssItem, len(films)),
+		},
+	}
+	for i, film := range films {
+		link := filmURL(appURL, film.ID.String())
+		item := rssItem{
+			Title:       film.Title,
+			Link:        link,
+			GUID:        link,
+			Description: film.Description,
+			Creator:     film.CreatorName,
+			Category:    film.Genre,
+		}
+		if film.PublishedAt != nil {
+			item.PubDate = film.PublishedAt.UTC().Format(time.RFC1123Z)
+		}
+		// Readers show the enclosure as artwork; the size is unknown
+		if film.ThumbnailURL != "" {
+			item.Enclosure = &rssFile{URL: film.ThumbnailURL, Type: "image/jpeg"}
+		}
+		feed.Channel.Items[i] = item
+	}
+	return encode(feed)
+}
+
+func filmURL(appURL, filmID string) string {
+	return appURL + "/films/" + filmID
+}
+
+func encode(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,152 @@
+// Package geo resolves the viewer's country from a CDN header or a GeoIP
+// range database.
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/clientip"
+)
+
+var countryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// ValidCountryCode reports whether code looks like an ISO 3166-1 alpha-2 code
+func ValidCountryCode(code string) bool {
+	return countryCodeRegex.MatchString(code)
+}
+
+// Resolver determines a request's country. The CDN header wins when the
+// request came through a trusted proxy; the GeoIP database is the fallback
+// for other requests and deployments without one.
+type Resolver struct {
+	header  string
+	proxies *clientip.Resolver
+	db      *Database
+}
+
+// NewResolver creates a resolver; header may be empty and db may be nil.
+// The header is only read from requests proxies trusts.
+func NewResolver(header string, proxies *clientip.Resolver, db *Database) *Resolver {
+	return &Resolver{
+		header:  header,
+		proxies: proxies,
+		db:      db,
+	}
+}
+
+// Country returns the ISO country code for a request, or "" when unknown
+func (r *Resolver) Country(req *http.Request, clientIP string) string {
+	if r.header != "" && r.proxies.FromTrustedProxy(req) {
+		country := strings.ToUpper(strings.TrimSpace(req.Header.Get(r.header)))
+		// Cloudflare uses XX for unknown and T1 for Tor
+		if ValidCountryCode(country) && country != "XX" && country != "T1" {
+			return country
+		}
+	}
+
+	if r.db != nil {
+		if addr, err := netip.ParseAddr(clientIP); err == nil {
+			return r.db.Lookup(addr)
+		}
+	}
+
+	return ""
+}
+
+type ipRange struct {
+	start, end netip.Addr
+	country    string
+}
+
+// Database is an in-memory IP range to country table
+type Database struct {
+	ranges []ipRange
+}
+
+// LoadCSV loads a GeoIP database from a CSV file of
+// "start_ip,end_ip,country" rows, such as the DB-IP country lite export
+func LoadCSV(path string) (*Database, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseCSV(f)
+}
+
+// ParseCSV reads a GeoIP database in the LoadCSV format
+func ParseCSV(r io.Reader) (*Database, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	db := &Database{}
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("line %d: expected start_ip,end_ip,country", line)
+		}
+
+		start, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+		if err != nil {
+			// Tolerate a header row
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid start IP", line)
+		}
+		end, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+		if err != nil || end.Less(start) || start.Is4() != end.Is4() {
+			return nil, fmt.Errorf("line %d: invalid end IP", line)
+		}
+		country := strings.ToUpper(strings.TrimSpace(record[2]))
+		if !ValidCountryCode(country) {
+			continue // e.g. ZZ/blank for reserved ranges
+		}
+
+		db.ranges = append(db.ranges, ipRange{start: start, end: end, country: country})
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+	return db, nil
+}
+
+// Lookup returns the country for an address, or "" when not covered
+func (db *Database) Lookup(addr netip.Addr) string {
+	addr = addr.Unmap()
+	// First range starting after addr; the candidate is the one before it
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return addr.Less(db.ranges[i].start)
+	})
+	if i == 0 {
+		return ""
+	}
+	r := db.ranges[i-1]
+	if addr.Is4() != r.start.Is4() || r.end.Less(addr) {
+		return ""
+	}
+	return r.country
+}
+
+// Len returns the number of ranges loaded
+func (db *Database) Len() int {
+	return len(db.ranges)
+}
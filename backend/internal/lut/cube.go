@@ -0,0 +1,88 @@
+// Package lut validates 3D LUTs in the Adobe/Resolve .cube format.
+package lut
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxFileBytes bounds uploaded .cube files; a 65-point LUT is ~8MB
+	MaxFileBytes = 16 << 20
+
+	minSize = 2
+	maxSize = 256
+)
+
+// Cube describes a parsed .cube file
+type Cube struct {
+	Title string
+	Size  int
+}
+
+// Parse validates a .cube file: it must declare LUT_3D_SIZE and contain
+// exactly size^3 RGB rows. 1D LUTs are rejected because FFmpeg's lut3d
+// filter can't apply them.
+func Parse(data []byte) (*Cube, error) {
+	cube := &Cube{}
+	rows := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "TITLE":
+			cube.Title = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "TITLE")), `"`)
+			continue
+		case "LUT_1D_SIZE":
+			return nil, fmt.Errorf("1D LUTs are not supported")
+		case "LUT_3D_SIZE":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: malformed LUT_3D_SIZE", lineNo)
+			}
+			size, err := strconv.Atoi(fields[1])
+			if err != nil || size < minSize || size > maxSize {
+				return nil, fmt.Errorf("line %d: LUT_3D_SIZE must be between %d and %d", lineNo, minSize, maxSize)
+			}
+			cube.Size = size
+			continue
+		case "DOMAIN_MIN", "DOMAIN_MAX", "LUT_3D_INPUT_RANGE":
+			continue
+		}
+
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected 3 values, got %d", lineNo, len(fields))
+		}
+		for _, field := range fields {
+			if _, err := strconv.ParseFloat(field, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid value %q", lineNo, field)
+			}
+		}
+		if cube.Size == 0 {
+			return nil, fmt.Errorf("line %d: data before LUT_3D_SIZE", lineNo)
+		}
+		rows++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if cube.Size == 0 {
+		return nil, fmt.Errorf("missing LUT_3D_SIZE")
+	}
+	if want := cube.Size * cube.Size * cube.Size; rows != want {
+		return nil, fmt.Errorf("expected %d rows for size %d, got %d", want, cube.Size, rows)
+	}
+
+	return cube, nil
+}
@@ -0,0 +1,102 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+)
+
+// Sender delivers plain-text and HTML email through an SMTP relay
+type Sender struct {
+	addr     string
+	auth     smtp.Auth
+	from     string // From header, e.g. "FilmTube <no-reply@example.com>"
+	envelope string // bare sender address
+}
+
+// New creates an SMTP sender, or returns nil when host is empty so email
+// is disabled
+func New(host, port, username, password, from string) *Sender {
+	if host == "" {
+		return nil
+	}
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	envelope := from
+	if addr, err := netmail.ParseAddress(from); err == nil {
+		envelope = addr.Address
+	}
+	return &Sender{addr: net.JoinHostPort(host, port), auth: auth, from: from, envelope: envelope}
+}
+
+// headerValue strips line breaks so values cannot inject headers
+var headerValue = strings.NewReplacer("\r", " ", "\n", " ")
+
+// Send delivers one message
+func (s *Sender) Send(to, subject, body string) error {
+	msg := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + headerValue.Replace(to),
+		"Subject: " + headerValue.Replace(subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+	return s.deliver(to, []byte(msg))
+}
+
+// SendMessage delivers a rendered template as a multipart message with
+// plain-text and HTML alternatives
+func (s *Sender) SendMessage(to string, m *Message) error {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", m.Text},
+		{"text/html; charset=UTF-8", m.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	header := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + headerValue.Replace(to),
+		"Subject: " + headerValue.Replace(m.Subject),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + parts.Boundary(),
+		"",
+		"",
+	}, "\r\n")
+	return s.deliver(to, append([]byte(header), body.Bytes()...))
+}
+
+func (s *Sender) deliver(to string, msg []byte) error {
+	if err := smtp.SendMail(s.addr, s.auth, s.envelope, []string{to}, msg); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package mail
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// mjmlNode is an element of an MJML document. Inner keeps the raw markup
+// of elements whose content is passed through as HTML.
+type mjmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []mjmlNode `xml:",any"`
+	Inner    string     `xml:",innerxml"`
+}
+
+func (n *mjmlNode) attr(name, fallback string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return fallback
+}
+
+// mjmlBodyWidth is the width of the email body in pixels, as in MJML
+const mjmlBodyWidth = "600px"
+
+// CompileMJML compiles an MJML document to table-based HTML. It supports
+// the layout subset email templates need: mj-head with mj-title and
+// mj-preview, and mj-body with mj-section, mj-column, mj-text, mj-button,
+// mj-image, mj-divider, mj-spacer and mj-raw. HTML entities and void
+// elements such as <br> are accepted in content. Template actions
+// ({{...}}) pass through untouched, in text and attribute values alike.
+func CompileMJML(src string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader(src))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	var root mjmlNode
+	if err := dec.Decode(&root); err != nil {
+		return "", fmt.Errorf("invalid MJML: %v", err)
+	}
+	if root.XMLName.Local != "mjml" {
+		return "", fmt.Errorf("invalid MJML: root element must be mjml, not %s", root.XMLName.Local)
+	}
+
+	var title, preview string
+	var body *mjmlNode
+	for i := range root.Children {
+		child := &root.Children[i]
+		switch child.XMLName.Local {
+		case "mj-head":
+			for _, h := range child.Children {
+				switch h.XMLName.Local {
+				case "mj-title":
+					title = h.Inner
+				case "mj-preview":
+					preview = h.Inner
+				}
+			}
+		case "mj-body":
+			body = child
+		default:
+			return "", fmt.Errorf("invalid MJML: unsupported element %s in mjml", child.XMLName.Local)
+		}
+	}
+	if body == nil {
+		return "", fmt.Errorf("invalid MJML: missing mj-body")
+	}
+
+	var b strings.Builder
+	b.WriteString(`<!doctype html><html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">`)
+	fmt.Fprintf(&b, `<title>%s</title></head>`, title)
+	fmt.Fprintf(&b, `<body style="margin:0;padding:0;background-color:%s;">`, escapeAttr(body.attr("background-color", "#ffffff")))
+	if preview != "" {
+		fmt.Fprintf(&b, `<div style="display:none;max-height:0;overflow:hidden;">%s</div>`, preview)
+	}
+	width := body.attr("width", mjmlBodyWidth)
+	fmt.Fprintf(&b, `<table role="presentation" align="center" width="100%%" cellpadding="0" cellspacing="0" border="0" style="max-width:%s;margin:0 auto;">`, escapeAttr(width))
+	for i := range body.Children {
+		section := &body.Children[i]
+		switch section.XMLName.Local {
+		case "mj-section":
+			if err := writeSection(&b, section); err != nil {
+				return "", err
+			}
+		case "mj-raw":
+			fmt.Fprintf(&b, `<tr><td>%s</td></tr>`, section.Inner)
+		default:
+			return "", fmt.Errorf("invalid MJML: unsupported element %s in mj-body", section.XMLName.Local)
+		}
+	}
+	b.WriteString(`</table></body></html>`)
+	return b.String(), nil
+}
+
+// writeSection renders a section as a table row of columns
+func writeSection(b *strings.Builder, section *mjmlNode) error {
+	fmt.Fprintf(b, `<tr><td style="background-color:%s;padding:%s;"><table role="presentation" width="100%%" cellpadding="0" cellspacing="0" border="0"><tr>`,
+		escapeAttr(section.attr("background-color", "transparent")), escapeAttr(section.attr("padding", "20px 0")))
+
+	columns := 0
+	for _, child := range section.Children {
+		if child.XMLName.Local == "mj-column" {
+			columns++
+		}
+	}
+	for i := range section.Children {
+		column := &section.Children[i]
+		if column.XMLName.Local != "mj-column" {
+			return fmt.Errorf("invalid MJML: unsupported element %s in mj-section", column.XMLName.Local)
+		}
+		width := column.attr("width", fmt.Sprintf("%d%%", 100/columns))
+		fmt.Fprintf(b, `<td valign="top" width="%s" style="background-color:%s;">`,
+			escapeAttr(width), escapeAttr(column.attr("background-color", "transparent")))
+		for j := range column.Children {
+			if err := writeContent(b, &column.Children[j]); err != nil {
+				return err
+			}
+		}
+		b.WriteString(`</td>`)
+	}
+	b.WriteString(`</tr></table></td></tr>`)
+	return nil
+}
+
+// writeContent renders one content element of a column
+func writeContent(b *strings.Builder, n *mjmlNode) error {
+	padding := escapeAttr(n.attr("padding", "10px 25px"))
+	align := escapeAttr(n.attr("align", "left"))
+	switch n.XMLName.Local {
+	case "mj-text":
+		fmt.Fprintf(b, `<div style="padding:%s;text-align:%s;color:%s;font-family:%s;font-size:%s;line-height:%s;">%s</div>`,
+			padding, align,
+			escapeAttr(n.attr("color", "#000000")),
+			escapeAttr(n.attr("font-family", "Ubuntu, Helvetica, Arial, sans-serif")),
+			escapeAttr(n.attr("font-size", "13px")),
+			escapeAttr(n.attr("line-height", "1.5")),
+			n.Inner)
+	case "mj-button":
+		fmt.Fprintf(b, `<div style="padding:%s;text-align:%s;"><a href="%s" style="display:inline-block;padding:10px 25px;border-radius:3px;background-color:%s;color:%s;font-family:Ubuntu, Helvetica, Arial, sans-serif;font-size:13px;text-decoration:none;">%s</a></div>`,
+			padding, escapeAttr(n.attr("align", "center")),
+			escapeAttr(n.attr("href", "#")),
+			escapeAttr(n.attr("background-color", "#414141")),
+			escapeAttr(n.attr("color", "#ffffff")),
+			n.Inner)
+	case "mj-image":
+		img := fmt.Sprintf(`<img src="%s" alt="%s" width="%s" style="display:block;border:0;max-width:100%%;">`,
+			escapeAttr(n.attr("src", "")), escapeAttr(n.attr("alt", "")), escapeAttr(strings.TrimSuffix(n.attr("width", "100%"), "px")))
+		if href := n.attr("href", ""); href != "" {
+			img = fmt.Sprintf(`<a href="%s">%s</a>`, escapeAttr(href), img)
+		}
+		fmt.Fprintf(b, `<div style="padding:%s;text-align:%s;">%s</div>`, padding, escapeAttr(n.attr("align", "center")), img)
+	case "mj-divider":
+		fmt.Fprintf(b, `<div style="padding:%s;"><hr style="border:none;border-top:%s %s %s;margin:0;"></div>`,
+			padding,
+			escapeAttr(n.attr("border-width", "4px")),
+			escapeAttr(n.attr("border-style", "solid")),
+			escapeAttr(n.attr("border-color", "#000000")))
+	case "mj-spacer":
+		fmt.Fprintf(b, `<div style="height:%s;line-height:%s;">&#8202;</div>`,
+			escapeAttr(n.attr("height", "20px")), escapeAttr(n.attr("height", "20px")))
+	case "mj-raw":
+		b.WriteString(n.Inner)
+	default:
+		return fmt.Errorf("invalid MJML: unsupported element %s in mj-column", n.XMLName.Local)
+	}
+	return nil
+}
+
+// escapeAttr escapes an attribute value for HTML, leaving template actions
+// as written so they still parse
+func escapeAttr(v string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(v, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(v[start:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start + 2
+		b.WriteString(html.EscapeString(v[:start]))
+		b.WriteString(v[start:end])
+		v = v[end:]
+	}
+	b.WriteString(html.EscapeString(v))
+	return b.String()
+}
@@ -0,0 +1,347 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// TemplateSavedSearchMatch alerts a user to new films matching a saved
+// search
+const TemplateSavedSearchMatch = "saved_search_match"
+
+const (
+	maxSubjectLength = 300
+	maxBodyLength    = 100 * 1024
+)
+
+// ErrInvalidTemplate is wrapped by template validation errors
+var ErrInvalidTemplate = errors.New("invalid email template")
+
+// funcs are the functions templates may call besides the builtins
+var funcs = map[string]interface{}{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// Template is a system email template. Subject and Text are text/template
+// sources and HTML an html/template source; all three see Variables, and
+// Sample holds example values for previews.
+type Template struct {
+	Key         string                 `json:"key"`
+	Description string                 `json:"description"`
+	Variables   map[string]string      `json:"variables"`
+	Subject     string                 `json:"subject"`
+	Text        string                 `json:"text"`
+	HTML        string                 `json:"html"`
+	Sample      map[string]interface{} `json:"sample"`
+}
+
+var systemTemplates = map[string]*Template{
+	TemplateSavedSearchMatch: {
+		Key:         TemplateSavedSearchMatch,
+		Description: "New films match one of a user's saved searches",
+		Variables: map[string]string{
+			"name":      "The saved search's name",
+			"summary":   "One line describing the match, as in the in-app alert",
+			"count":     "How many films matched",
+			"titles":    "The titles of the first few films, earliest published first",
+			"more":      "How many matching films are not in titles",
+			"watch_url": "The film's page when exactly one film matched, else empty",
+			"app_url":   "FilmTube's address",
+		},
+		Subject: `{{.summary}}`,
+		Text: `New films were published that match your saved search "{{.name}}":
+
+{{range .titles}}  - {{.}}
+{{end}}{{if .more}}  ...and {{.more}} more
+{{end}}
+{{if .watch_url}}Watch it: {{.watch_url}}{{else}}See them on FilmTube: {{.app_url}}{{end}}
+
+To stop these emails, turn off email alerts for this saved search.
+`,
+		HTML: `<!doctype html>
+<html><body style="font-family:Helvetica,Arial,sans-serif;color:#222;">
+<p>New films were published that match your saved search <strong>{{.name}}</strong>:</p>
+<ul>{{range .titles}}<li>{{.}}</li>{{end}}{{if .more}}<li>...and {{.more}} more</li>{{end}}</ul>
+<p>{{if .watch_url}}<a href="{{.watch_url}}">Watch it</a>{{else}}<a href="{{.app_url}}">See them on FilmTube</a>{{end}}</p>
+<p style="color:#888;font-size:12px;">To stop these emails, turn off email alerts for this saved search.</p>
+</body></html>
+`,
+		Sample: map[string]interface{}{
+			"name":      "Noir shorts",
+			"summary":   `2 new films match "Noir shorts": Night Train, The Last Call`,
+			"count":     2,
+			"titles":    []string{"Night Train", "The Last Call"},
+			"more":      0,
+			"watch_url": "",
+			"app_url":   "https://filmtube.example",
+		},
+	},
+}
+
+// SystemTemplate returns the system template with the given key
+func SystemTemplate(key string) (*Template, bool) {
+	t, ok := systemTemplates[key]
+	return t, ok
+}
+
+// SystemTemplates returns all system templates, by key
+func SystemTemplates() []*Template {
+	templates := make([]*Template, 0, len(systemTemplates))
+	for _, t := range systemTemplates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Key < templates[j].Key })
+	return templates
+}
+
+// Message is a rendered email with plain text and HTML parts
+type Message struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// Compile checks an override of the template and fills in its compiled
+// HTML. Every variable it uses must be one of the template's.
+func (t *Template) Compile(o *models.EmailTemplate) error {
+	o.Subject = strings.TrimSpace(o.Subject)
+	switch {
+	case strings.TrimSpace(o.Body) == "":
+		return fmt.Errorf("%w: body is required", ErrInvalidTemplate)
+	case len(o.Body) > maxBodyLength || len(o.TextBody) > maxBodyLength:
+		return fmt.Errorf("%w: body exceeds %d bytes", ErrInvalidTemplate, maxBodyLength)
+	case len(o.Subject) > maxSubjectLength:
+		return fmt.Errorf("%w: subject exceeds %d characters", ErrInvalidTemplate, maxSubjectLength)
+	}
+
+	switch o.Format {
+	case models.EmailFormatHTML:
+		o.HTML = o.Body
+	case models.EmailFormatMJML:
+		html, err := CompileMJML(o.Body)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+		o.HTML = html
+	default:
+		return fmt.Errorf("%w: format must be html or mjml", ErrInvalidTemplate)
+	}
+
+	if _, err := t.parseText("subject", o.Subject); err != nil {
+		return err
+	}
+	if _, err := t.parseText("text", o.TextBody); err != nil {
+		return err
+	}
+	_, err := t.parseHTML(o.HTML)
+	return err
+}
+
+// Render renders the template with an override's parts in place of its
+// own; override may be nil
+func (t *Template) Render(override *models.EmailTemplate, vars map[string]interface{}) (*Message, error) {
+	subject, text, html := t.Subject, t.Text, t.HTML
+	if override != nil {
+		html = override.HTML
+		if override.Subject != "" {
+			subject = override.Subject
+		}
+		if override.TextBody != "" {
+			text = override.TextBody
+		}
+	}
+
+	var msg Message
+	var err error
+	if msg.Subject, err = t.executeText("subject", subject, vars); err != nil {
+		return nil, err
+	}
+	msg.Subject = headerValue.Replace(strings.TrimSpace(msg.Subject))
+	if msg.Text, err = t.executeText("text", text, vars); err != nil {
+		return nil, err
+	}
+	tmpl, err := t.parseHTML(html)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return nil, fmt.Errorf("failed to render html: %w", err)
+	}
+	msg.HTML = b.String()
+	return &msg, nil
+}
+
+func (t *Template) executeText(name, src string, vars map[string]interface{}) (string, error) {
+	tmpl, err := t.parseText(name, src)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+func (t *Template) parseText(name, src string) (*texttemplate.Template, error) {
+	tmpl, err := texttemplate.New(name).Option("missingkey=zero").Funcs(funcs).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, name, err)
+	}
+	if err := t.checkVariables(name, tmpl.Tree); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func (t *Template) parseHTML(src string) (*htmltemplate.Template, error) {
+	tmpl, err := htmltemplate.New("html").Option("missingkey=zero").Funcs(funcs).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: html: %v", ErrInvalidTemplate, err)
+	}
+	if err := t.checkVariables("html", tmpl.Tree); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// checkVariables rejects templates that define or call other templates or
+// use variables the template does not have. Fields inside range and with
+// blocks refer to the current element and are not checked.
+func (t *Template) checkVariables(name string, tree *parse.Tree) error {
+	if tree == nil || tree.Root == nil {
+		return nil
+	}
+	var walk func(node parse.Node, top bool) error
+	walk = func(node parse.Node, top bool) error {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return nil
+			}
+			for _, child := range n.Nodes {
+				if err := walk(child, top); err != nil {
+					return err
+				}
+			}
+		case *parse.ActionNode:
+			return walk(n.Pipe, top)
+		case *parse.IfNode:
+			return walkBranch(walk, &n.BranchNode, top, top)
+		case *parse.RangeNode:
+			return walkBranch(walk, &n.BranchNode, top, false)
+		case *parse.WithNode:
+			return walkBranch(walk, &n.BranchNode, top, false)
+		case *parse.PipeNode:
+			if n == nil {
+				return nil
+			}
+			for _, cmd := range n.Cmds {
+				for _, arg := range cmd.Args {
+					if err := walk(arg, top); err != nil {
+						return err
+					}
+				}
+			}
+		case *parse.ChainNode:
+			return walk(n.Node, top)
+		case *parse.FieldNode:
+			if top {
+				return t.checkVariable(name, n.Ident[0])
+			}
+		case *parse.VariableNode:
+			if n.Ident[0] == "$" && len(n.Ident) > 1 {
+				return t.checkVariable(name, n.Ident[1])
+			}
+		case *parse.TemplateNode:
+			return fmt.Errorf("%w: %s: templates cannot include other templates", ErrInvalidTemplate, name)
+		}
+		return nil
+	}
+	if len(tree.Root.Nodes) > 0 {
+		if err := walk(tree.Root, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkBranch walks an if, range or with block. Its pipeline sees the
+// enclosing dot; its body sees the enclosing dot only when bodyTop is set.
+func walkBranch(walk func(parse.Node, bool) error, n *parse.BranchNode, top, bodyTop bool) error {
+	if err := walk(n.Pipe, top); err != nil {
+		return err
+	}
+	if err := walk(n.List, bodyTop); err != nil {
+		return err
+	}
+	if n.ElseList != nil {
+		return walk(n.ElseList, top)
+	}
+	return nil
+}
+
+func (t *Template) checkVariable(name, variable string) error {
+	if _, ok := t.Variables[variable]; !ok {
+		return fmt.Errorf("%w: %s: unknown variable %q", ErrInvalidTemplate, name, variable)
+	}
+	return nil
+}
+
+// Brand is whose email a message is: the organization and creator of the
+// film it is about
+type Brand struct {
+	TenantID  *uuid.UUID
+	CreatorID uuid.UUID
+}
+
+// Templates renders system templates with the overrides of the
+// organization or creator an email is about
+type Templates struct {
+	queries *db.Queries
+}
+
+// NewTemplates creates a template renderer
+func NewTemplates(queries *db.Queries) *Templates {
+	return &Templates{queries: queries}
+}
+
+// Render renders a system template for brand, with the organization's
+// override if it has one, else the creator's. A nil brand, a missing
+// override or one that fails to render falls back to the system template.
+func (ts *Templates) Render(ctx context.Context, key string, brand *Brand, vars map[string]interface{}) (*Message, error) {
+	t, ok := SystemTemplate(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", key)
+	}
+	if brand != nil {
+		override, err := ts.queries.ResolveEmailTemplate(ctx, key, brand.TenantID, brand.CreatorID)
+		switch {
+		case err == nil:
+			msg, err := t.Render(override, vars)
+			if err == nil {
+				return msg, nil
+			}
+			log.Printf("[Mail] Template %s override %s failed to render, sending the default: %v", key, override.ID, err)
+		case !errors.Is(err, sql.ErrNoRows):
+			log.Printf("[Mail] Failed to look up template %s override: %v", key, err)
+		}
+	}
+	return t.Render(nil, vars)
+}
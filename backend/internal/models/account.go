@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GhostAccountID is the system account that takes over films kept when
+// their owner's account is deleted. It cannot sign in.
+var GhostAccountID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// ClassifierAccountID is the system account that reports films the content
+// classifier flags to the moderation queue. It cannot sign in.
+var ClassifierAccountID = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
+// OwnerDeletionPolicy is what happens to a film when its owner's account
+// is deleted
+type OwnerDeletionPolicy string
+
+const (
+	// OwnerDeletionAuto keeps the film when it is published or owned by
+	// an organization, and deletes it otherwise
+	OwnerDeletionAuto   OwnerDeletionPolicy = "auto"
+	OwnerDeletionKeep   OwnerDeletionPolicy = "keep"
+	OwnerDeletionDelete OwnerDeletionPolicy = "delete"
+)
+
+// Valid reports whether p is a known policy
+func (p OwnerDeletionPolicy) Valid() bool {
+	return p == OwnerDeletionAuto || p == OwnerDeletionKeep || p == OwnerDeletionDelete
+}
+
+// FilmDisposition is what an account deletion does with one of the
+// user's films, and why
+type FilmDisposition struct {
+	FilmID uuid.UUID           `json:"film_id"`
+	Title  string              `json:"title"`
+	Keep   bool                `json:"keep"`
+	Policy OwnerDeletionPolicy `json:"policy"`
+	// Override is set when the deletion request decided this film
+	Override bool `json:"override,omitempty"`
+}
+
+// AccountDeletion records a completed account deletion
+type AccountDeletion struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	UserID       uuid.UUID  `db:"user_id" json:"user_id"`
+	Email        string     `db:"email" json:"email"`
+	DeletedByID  *uuid.UUID `db:"deleted_by_id" json:"deleted_by_id,omitempty"`
+	FilmsKept    int        `db:"films_kept" json:"films_kept"`
+	FilmsDeleted int        `db:"films_deleted" json:"films_deleted"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Account deletion actions, for filtering the deletions admins list
+const (
+	AccountDeletionSelf  = "self"  // the user deleted their own account
+	AccountDeletionAdmin = "admin" // an admin deleted it
+)
@@ -0,0 +1,32 @@
+package models
+
+// AdmissionMode is what confirming an upload does while transcoding is
+// saturated
+type AdmissionMode string
+
+const (
+	AdmissionReject AdmissionMode = "reject" // 429 with Retry-After; confirm again later
+	AdmissionDefer  AdmissionMode = "defer"  // accept and queue once there is room
+)
+
+// AdmissionPolicy caps the transcode work waiting and running, so a flood
+// of uploads can't bury the workers. A limit of 0 is no limit.
+type AdmissionPolicy struct {
+	MaxQueued         int64         `json:"max_queued"`    // jobs waiting in the transcode queues
+	MaxInFlight       int64         `json:"max_in_flight"` // films queued or being transcoded
+	Mode              AdmissionMode `json:"mode"`
+	RetryAfterSeconds int           `json:"retry_after_seconds"`
+}
+
+// AdmissionLoad is the transcode work admission is measured against
+type AdmissionLoad struct {
+	Queued   int64 `json:"queued"`
+	InFlight int64 `json:"in_flight"`
+	Deferred int64 `json:"deferred"`
+}
+
+// Saturated reports whether load is at either of the policy's limits
+func (p AdmissionPolicy) Saturated(load AdmissionLoad) bool {
+	return (p.MaxQueued > 0 && load.Queued >= p.MaxQueued) ||
+		(p.MaxInFlight > 0 && load.InFlight >= p.MaxInFlight)
+}
@@ -0,0 +1,306 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType identifies a kind of analytics event
+type EventType string
+
+const (
+	EventImpression EventType = "impression"
+	EventPlay       EventType = "play"
+	EventView       EventType = "view"
+	EventHeartbeat  EventType = "heartbeat"
+	EventCompletion EventType = "completion"
+	EventTrailer    EventType = "trailer_view"
+
+	// Player events, whose properties follow a fixed schema
+	EventPause         EventType = "pause"
+	EventSeek          EventType = "seek"
+	EventQualitySwitch EventType = "quality_switch"
+	EventError         EventType = "error"
+
+	// Quality-of-experience events
+	EventStartup  EventType = "startup"
+	EventRebuffer EventType = "rebuffer"
+)
+
+// EventTypes lists every accepted event type
+var EventTypes = []EventType{
+	EventImpression, EventPlay, EventView, EventHeartbeat, EventCompletion, EventTrailer,
+	EventPause, EventSeek, EventQualitySwitch, EventError,
+	EventStartup, EventRebuffer,
+}
+
+// AnalyticsEvent is a raw client event
+type AnalyticsEvent struct {
+	ID           int64           `db:"id" json:"id"`
+	EventType    EventType       `db:"event_type" json:"event_type"`
+	FilmID       *uuid.UUID      `db:"film_id" json:"film_id,omitempty"`
+	UserID       *uuid.UUID      `db:"user_id" json:"user_id,omitempty"`
+	SessionID    *string         `db:"session_id" json:"session_id,omitempty"`
+	IPAddress    *string         `db:"ip_address" json:"-"`
+	UserAgent    *string         `db:"user_agent" json:"-"`
+	Country      *string         `db:"country" json:"country,omitempty"`
+	Device       string          `db:"device" json:"device,omitempty"`
+	OS           string          `db:"os" json:"os,omitempty"`
+	Surface      string          `db:"surface" json:"surface,omitempty"`
+	Properties   json.RawMessage `db:"properties" json:"properties,omitempty"`
+	OccurredAt   time.Time       `db:"occurred_at" json:"occurred_at"`
+	AnonymizedAt *time.Time      `db:"anonymized_at" json:"anonymized_at,omitempty"`
+	// Unverified marks playback events stored without a valid beacon
+	// token; they are left out of rollups, rankings and exports
+	Unverified bool `db:"unverified" json:"unverified,omitempty"`
+}
+
+// PlaybackEventTypes must carry the beacon token of the playback session
+// they belong to
+var PlaybackEventTypes = []EventType{
+	EventPlay, EventView, EventHeartbeat, EventCompletion,
+	EventPause, EventSeek, EventQualitySwitch, EventError,
+	EventStartup, EventRebuffer,
+}
+
+// Beacon modes for playback events without a valid token
+const (
+	BeaconModeFlag = "flag" // store them marked unverified
+	BeaconModeDrop = "drop" // discard them
+)
+
+// RetentionPolicy controls how long raw events of one type keep personal
+// data and how long they are kept at all. Zero disables a step.
+type RetentionPolicy struct {
+	AnonymizeAfterDays int `json:"anonymize_after_days"`
+	DeleteAfterDays    int `json:"delete_after_days"`
+}
+
+// DefaultRetentionKey is the policy entry used for event types without one
+const DefaultRetentionKey = "*"
+
+// DataLifecycleRun records one pass of the analytics lifecycle job
+type DataLifecycleRun struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	StartedAt  time.Time  `db:"started_at" json:"started_at"`
+	FinishedAt *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+	RolledUp   int64      `db:"rolled_up" json:"rolled_up"`
+	Anonymized int64      `db:"anonymized" json:"anonymized"`
+	Deleted    int64      `db:"deleted" json:"deleted"`
+	Compacted  int64      `db:"compacted" json:"compacted"`
+	Error      *string    `db:"error" json:"error,omitempty"`
+}
+
+// EventRetentionStats summarises stored raw events of one type
+type EventRetentionStats struct {
+	EventType  EventType  `db:"event_type" json:"event_type"`
+	Total      int64      `db:"total" json:"total"`
+	Anonymized int64      `db:"anonymized" json:"anonymized"`
+	Unverified int64      `db:"unverified" json:"unverified"`
+	Oldest     *time.Time `db:"oldest" json:"oldest,omitempty"`
+	Newest     *time.Time `db:"newest" json:"newest,omitempty"`
+}
+
+// RollupStats summarises stored rollup rows of one period
+type RollupStats struct {
+	Period string     `db:"period" json:"period"`
+	Rows   int64      `db:"rows" json:"rows"`
+	Oldest *time.Time `db:"oldest" json:"oldest,omitempty"`
+	Newest *time.Time `db:"newest" json:"newest,omitempty"`
+}
+
+// DailyEventCount is the number of events of one type for a film on a day
+type DailyEventCount struct {
+	Day           time.Time `db:"day" json:"day"`
+	EventType     EventType `db:"event_type" json:"event_type"`
+	Count         int64     `db:"event_count" json:"count"`
+	UniqueViewers int64     `db:"unique_viewers" json:"unique_viewers"`
+}
+
+// EventSeriesCount is the number of events of one type for a film in one
+// bucket of a time series, with the watch time they account for
+type EventSeriesCount struct {
+	Bucket        time.Time `db:"bucket" json:"bucket"`
+	EventType     EventType `db:"event_type" json:"event_type"`
+	Count         int64     `db:"event_count" json:"count"`
+	UniqueViewers int64     `db:"unique_viewers" json:"unique_viewers"`
+	WatchSeconds  int64     `db:"watch_seconds" json:"watch_seconds"`
+}
+
+// Breakdown dimensions of analytics counts
+const (
+	DimensionCountry = "country"
+	DimensionDevice  = "device"
+	DimensionOS      = "os"
+)
+
+// BreakdownDimensions lists every dimension counts can be broken down by
+var BreakdownDimensions = []string{DimensionCountry, DimensionDevice, DimensionOS}
+
+// BreakdownEventCount is the number of events of one type for a film, or
+// the platform, from viewers sharing one value of a breakdown dimension.
+// Value is "" when it is unknown.
+type BreakdownEventCount struct {
+	Value     string    `db:"value" json:"value"`
+	EventType EventType `db:"event_type" json:"event_type"`
+	Count     int64     `db:"event_count" json:"count"`
+}
+
+// BreakdownRow is the event counts of one value of a breakdown dimension,
+// with its share of all views
+type BreakdownRow struct {
+	Value        string              `json:"value"`
+	Counts       map[EventType]int64 `json:"counts"`
+	Views        int64               `json:"views"`
+	ShareOfViews float64             `json:"share_of_views"`
+}
+
+// FilmAnalyticsBucket is one point of a film's analytics time series.
+// CompletionRate is completions per play, nil without plays.
+type FilmAnalyticsBucket struct {
+	Start          time.Time `json:"start"`
+	Views          int64     `json:"views"`
+	UniqueViewers  int64     `json:"unique_viewers"`
+	WatchSeconds   int64     `json:"watch_seconds"`
+	Plays          int64     `json:"plays"`
+	Completions    int64     `json:"completions"`
+	CompletionRate *float64  `json:"completion_rate"`
+}
+
+// Heartbeats account for the playback time since the previous one, sent as
+// their "seconds" property
+const (
+	DefaultHeartbeatSeconds = 30  // when a heartbeat does not say
+	MaxHeartbeatSeconds     = 300 // longer gaps are not counted in full
+)
+
+// AnalyticsSinkState tracks export progress to an external analytics sink
+type AnalyticsSinkState struct {
+	Sink           string     `db:"sink" json:"sink"`
+	LastEventID    int64      `db:"last_event_id" json:"last_event_id"`
+	ExportedCount  int64      `db:"exported_count" json:"exported_count"`
+	LastExportedAt *time.Time `db:"last_exported_at" json:"last_exported_at,omitempty"`
+	LastError      *string    `db:"last_error" json:"last_error,omitempty"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// SurfaceEventCount is the number of events of one type for a film that
+// happened on a UI surface
+type SurfaceEventCount struct {
+	Surface   string    `db:"surface" json:"surface"`
+	EventType EventType `db:"event_type" json:"event_type"`
+	Count     int64     `db:"event_count" json:"count"`
+}
+
+// FunnelStep is the impression -> play -> completion funnel for one surface
+type FunnelStep struct {
+	Surface        string  `json:"surface"`
+	Impressions    int64   `json:"impressions"`
+	Plays          int64   `json:"plays"`
+	Completions    int64   `json:"completions"`
+	ClickThrough   float64 `json:"click_through_rate"` // plays / impressions
+	CompletionRate float64 `json:"completion_rate"`    // completions / plays
+	ShareOfPlays   float64 `json:"share_of_plays"`
+}
+
+// MinuteCount is the number of play starts in one minute
+type MinuteCount struct {
+	Minute time.Time `json:"minute"`
+	Plays  int       `json:"plays"`
+}
+
+// ActiveFilm is a film with viewers right now
+type ActiveFilm struct {
+	FilmID  uuid.UUID `json:"film_id"`
+	Title   string    `json:"title"`
+	Viewers int       `json:"viewers"`
+}
+
+// RealtimeSnapshot is the live activity across a creator's films
+type RealtimeSnapshot struct {
+	At             time.Time     `json:"at"`
+	CurrentViewers int           `json:"current_viewers"`
+	PlaysPerMinute []MinuteCount `json:"plays_per_minute"`
+	TopFilms       []ActiveFilm  `json:"top_films"`
+}
+
+// PlatformWindows maps each platform analytics window to the complete days
+// it covers, ending yesterday
+var PlatformWindows = map[string]int{
+	"7d":  7,
+	"30d": 30,
+	"90d": 90,
+}
+
+// PlatformDailyStats is the platform-wide activity of one day. Storage is
+// a snapshot taken when the day was rolled up, if it was rolled up the
+// next day.
+type PlatformDailyStats struct {
+	Day                 time.Time `db:"day" json:"day"`
+	Signups             int64     `db:"signups" json:"signups"`
+	Uploads             int64     `db:"uploads" json:"uploads"`
+	TranscodesCompleted int64     `db:"transcodes_completed" json:"transcodes_completed"`
+	TranscodesFailed    int64     `db:"transcodes_failed" json:"transcodes_failed"`
+	StorageBytes        *int64    `db:"storage_bytes" json:"storage_bytes,omitempty"`
+	RolledUpAt          time.Time `db:"rolled_up_at" json:"rolled_up_at"`
+}
+
+// PlatformTopFilm is a film ranked by views over a platform analytics
+// window
+type PlatformTopFilm struct {
+	FilmID uuid.UUID `db:"film_id" json:"film_id"`
+	Title  string    `db:"title" json:"title"`
+	Views  int64     `db:"views" json:"views"`
+}
+
+// QoEStats is the playback quality of experience of one rendition on one
+// day, rolled up from startup, rebuffer and heartbeat events, or a sum of
+// them. The averages and ratio are nil without the events they need.
+type QoEStats struct {
+	Day       *time.Time `db:"day" json:"day,omitempty"`
+	Rendition string     `db:"rendition" json:"rendition,omitempty"`
+	Startups  int64      `db:"startups" json:"startups"`
+	StartupMs int64      `db:"startup_ms" json:"-"`
+	Rebuffers int64      `db:"rebuffers" json:"rebuffers"`
+	// RebufferMs is time spent stalled and WatchSeconds time spent playing
+	RebufferMs   int64 `db:"rebuffer_ms" json:"rebuffer_ms"`
+	WatchSeconds int64 `db:"watch_seconds" json:"watch_seconds"`
+	// BitsPlayed is bitrate times seconds over BitrateSeconds, the watch
+	// time of heartbeats that reported a bitrate
+	BitsPlayed     int64 `db:"bits_played" json:"-"`
+	BitrateSeconds int64 `db:"bitrate_seconds" json:"-"`
+
+	AvgStartupMs  *float64 `db:"-" json:"avg_startup_ms"`
+	RebufferRatio *float64 `db:"-" json:"rebuffer_ratio"`
+	AvgBitrate    *float64 `db:"-" json:"avg_bitrate"`
+}
+
+// Add sums another day's or rendition's counters into s
+func (s *QoEStats) Add(o QoEStats) {
+	s.Startups += o.Startups
+	s.StartupMs += o.StartupMs
+	s.Rebuffers += o.Rebuffers
+	s.RebufferMs += o.RebufferMs
+	s.WatchSeconds += o.WatchSeconds
+	s.BitsPlayed += o.BitsPlayed
+	s.BitrateSeconds += o.BitrateSeconds
+}
+
+// Summarize fills in the averages and the rebuffer ratio, the share of
+// playback time spent stalled
+func (s *QoEStats) Summarize() {
+	if s.Startups > 0 {
+		v := float64(s.StartupMs) / float64(s.Startups)
+		s.AvgStartupMs = &v
+	}
+	if total := float64(s.WatchSeconds)*1000 + float64(s.RebufferMs); total > 0 {
+		v := float64(s.RebufferMs) / total
+		s.RebufferRatio = &v
+	}
+	if s.BitrateSeconds > 0 {
+		v := float64(s.BitsPlayed) / float64(s.BitrateSeconds)
+		s.AvgBitrate = &v
+	}
+}
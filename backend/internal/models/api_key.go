@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey lets scripts and integrations call the API as its user. The key
+// itself is only shown once, when created.
+type APIKey struct {
+	ID                 uuid.UUID  `db:"id" json:"id"`
+	UserID             uuid.UUID  `db:"user_id" json:"user_id"`
+	Name               string     `db:"name" json:"name"`
+	Prefix             string     `db:"prefix" json:"prefix"`
+	KeyHash            string     `db:"key_hash" json:"-"`
+	RateLimitPerMinute *int       `db:"rate_limit_per_minute" json:"rate_limit_per_minute,omitempty"`
+	DailyQuota         *int       `db:"daily_quota" json:"daily_quota,omitempty"`
+	LastUsedAt         *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt          *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
+}
+
+// APIKeyOwner is an API key with the user it acts as
+type APIKeyOwner struct {
+	APIKey
+	Email string   `db:"email" json:"email"`
+	Role  UserRole `db:"role" json:"role"`
+}
+
+// APIKeyUsage is an API key's usage over one period. Rate-limited
+// requests are counted in Requests but not as client errors.
+type APIKeyUsage struct {
+	Period       time.Time `db:"period" json:"period"`
+	Requests     int64     `db:"requests" json:"requests"`
+	ClientErrors int64     `db:"client_errors" json:"client_errors"`
+	ServerErrors int64     `db:"server_errors" json:"server_errors"`
+	RateLimited  int64     `db:"rate_limited" json:"rate_limited"`
+	LatencyMs    int64     `db:"latency_ms" json:"-"`
+	// AvgLatencyMs is the mean latency of the requests that were served
+	AvgLatencyMs float64 `db:"-" json:"avg_latency_ms"`
+}
+
+// APIKeyConsumer is an API key ranked by requests over a window
+type APIKeyConsumer struct {
+	KeyID        uuid.UUID `db:"key_id" json:"key_id"`
+	Name         string    `db:"name" json:"name"`
+	Prefix       string    `db:"prefix" json:"prefix"`
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	Email        string    `db:"email" json:"email"`
+	Requests     int64     `db:"requests" json:"requests"`
+	ClientErrors int64     `db:"client_errors" json:"client_errors"`
+	ServerErrors int64     `db:"server_errors" json:"server_errors"`
+	RateLimited  int64     `db:"rate_limited" json:"rate_limited"`
+	LatencyMs    int64     `db:"latency_ms" json:"-"`
+	AvgLatencyMs float64   `db:"-" json:"avg_latency_ms"`
+}
+
+// AverageLatency returns the mean latency in milliseconds of served
+// requests
+func AverageLatency(latencyMs, requests, rateLimited int64) float64 {
+	served := requests - rateLimited
+	if served <= 0 {
+		return 0
+	}
+	return float64(latencyMs) / float64(served)
+}
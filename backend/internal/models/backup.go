@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackupStatus is the outcome of a backup run
+type BackupStatus string
+
+const (
+	BackupRunning   BackupStatus = "RUNNING"
+	BackupSucceeded BackupStatus = "SUCCEEDED"
+	BackupFailed    BackupStatus = "FAILED"
+)
+
+// BackupRun records one run of the backup job
+type BackupRun struct {
+	ID         uuid.UUID    `db:"id" json:"id"`
+	StartedAt  time.Time    `db:"started_at" json:"started_at"`
+	FinishedAt *time.Time   `db:"finished_at" json:"finished_at,omitempty"`
+	Status     BackupStatus `db:"status" json:"status"`
+	Prefix     string       `db:"prefix" json:"prefix"` // in the backup bucket
+	Tables     int          `db:"tables" json:"tables"`
+	Rows       int64        `db:"row_count" json:"rows"`
+	Playlists  int          `db:"playlists" json:"playlists"`
+	Objects    int64        `db:"objects" json:"objects"` // in the inventory
+	Bytes      int64        `db:"bytes" json:"bytes"`     // written to the backup bucket
+	Error      *string      `db:"error" json:"error,omitempty"`
+}
+
+// ForeignKey is a single-column foreign key constraint
+type ForeignKey struct {
+	Table     string `db:"table_name" json:"table"`
+	Column    string `db:"column_name" json:"column"`
+	RefTable  string `db:"ref_table" json:"ref_table"`
+	RefColumn string `db:"ref_column" json:"ref_column"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarKind identifies what a content calendar entry schedules
+type CalendarKind string
+
+const (
+	// Release events, managed through the calendar
+	CalendarPublish  CalendarKind = "PUBLISH"
+	CalendarPremiere CalendarKind = "PREMIERE"
+	CalendarFestival CalendarKind = "FESTIVAL"
+
+	// Shown for coordination; edited through their own endpoints
+	CalendarFeatured CalendarKind = "FEATURED"
+	CalendarEmbargo  CalendarKind = "EMBARGO"
+)
+
+// CalendarKinds lists every entry kind the calendar can filter on
+var CalendarKinds = []CalendarKind{CalendarPublish, CalendarPremiere, CalendarFestival, CalendarFeatured, CalendarEmbargo}
+
+// ReleaseEvent is a scheduled publish, premiere or festival window
+type ReleaseEvent struct {
+	ID          uuid.UUID    `db:"id" json:"id"`
+	FilmID      uuid.UUID    `db:"film_id" json:"film_id"`
+	Kind        CalendarKind `db:"kind" json:"kind"`
+	Name        *string      `db:"name" json:"name,omitempty"` // festival name or premiere venue
+	StartsAt    time.Time    `db:"starts_at" json:"starts_at"`
+	EndsAt      *time.Time   `db:"ends_at" json:"ends_at,omitempty"`
+	PublishedAt *time.Time   `db:"published_at" json:"published_at,omitempty"` // when a scheduled publish ran
+	LastError   *string      `db:"last_error" json:"last_error,omitempty"`     // why a scheduled publish did not
+	CreatedByID *uuid.UUID   `db:"created_by_id" json:"created_by_id,omitempty"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`
+}
+
+// CalendarEntry is one item on the admin content calendar. ID is the
+// release event or featured slot ID, or the film ID for embargoes.
+type CalendarEntry struct {
+	ID            uuid.UUID    `db:"id" json:"id"`
+	Kind          CalendarKind `db:"kind" json:"kind"`
+	FilmID        uuid.UUID    `db:"film_id" json:"film_id"`
+	FilmTitle     string       `db:"film_title" json:"film_title"`
+	CreatorID     uuid.UUID    `db:"creator_id" json:"creator_id"`
+	CreatorName   string       `db:"creator_name" json:"creator_name"`
+	Name          *string      `db:"name" json:"name,omitempty"`
+	StartsAt      time.Time    `db:"starts_at" json:"starts_at"`
+	EndsAt        *time.Time   `db:"ends_at" json:"ends_at,omitempty"`
+	Reschedulable bool         `db:"-" json:"reschedulable"`
+}
+
+// CalendarConflict pairs two premieres whose slots overlap
+type CalendarConflict struct {
+	EntryID       uuid.UUID `json:"entry_id"`
+	ConflictsWith uuid.UUID `json:"conflicts_with"`
+	StartsAt      time.Time `json:"starts_at"` // start of the overlap
+}
+
+// PremiereSlot returns the time a premiere occupies: its own window, or
+// slot from its start when it has no end
+func PremiereSlot(startsAt time.Time, endsAt *time.Time, slot time.Duration) (time.Time, time.Time) {
+	if endsAt != nil {
+		return startsAt, *endsAt
+	}
+	return startsAt, startsAt.Add(slot)
+}
+
+// PremiereConflicts returns every pair of premieres among entries whose
+// slots overlap, earliest first
+func PremiereConflicts(entries []CalendarEntry, slot time.Duration) []CalendarConflict {
+	premieres := []CalendarEntry{}
+	for _, e := range entries {
+		if e.Kind == CalendarPremiere {
+			premieres = append(premieres, e)
+		}
+	}
+	sort.Slice(premieres, func(i, j int) bool {
+		return premieres[i].StartsAt.Before(premieres[j].StartsAt)
+	})
+
+	conflicts := []CalendarConflict{}
+	for i := range premieres {
+		_, end := PremiereSlot(premieres[i].StartsAt, premieres[i].EndsAt, slot)
+		for j := i + 1; j < len(premieres) && premieres[j].StartsAt.Before(end); j++ {
+			conflicts = append(conflicts, CalendarConflict{
+				EntryID:       premieres[i].ID,
+				ConflictsWith: premieres[j].ID,
+				StartsAt:      premieres[j].StartsAt,
+			})
+		}
+	}
+	return conflicts
+}
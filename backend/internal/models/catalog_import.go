@@ -0,0 +1,75 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportProvider is where a catalog import item's video is hosted
+type ImportProvider string
+
+const (
+	ImportProviderYouTube ImportProvider = "youtube"
+	ImportProviderVimeo   ImportProvider = "vimeo"
+	ImportProviderDirect  ImportProvider = "direct" // a plain video file URL
+)
+
+// DetectImportProvider tells YouTube and Vimeo video pages from direct
+// file URLs by host
+func DetectImportProvider(u *url.URL) ImportProvider {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	switch {
+	case host == "youtube.com" || host == "m.youtube.com" || host == "youtu.be" || host == "youtube-nocookie.com":
+		return ImportProviderYouTube
+	case host == "vimeo.com" || host == "player.vimeo.com":
+		return ImportProviderVimeo
+	default:
+		return ImportProviderDirect
+	}
+}
+
+// ImportItemStatus is how far a catalog import item has got
+type ImportItemStatus string
+
+const (
+	ImportItemPending     ImportItemStatus = "PENDING"
+	ImportItemFetching    ImportItemStatus = "FETCHING"    // reading metadata
+	ImportItemDownloading ImportItemStatus = "DOWNLOADING" // film created, fetching the video
+	ImportItemQueued      ImportItemStatus = "QUEUED"      // source stored and transcode queued
+	ImportItemFailed      ImportItemStatus = "FAILED"
+)
+
+// CatalogImport is a creator's bulk import of films hosted elsewhere
+type CatalogImport struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	CreatedByID uuid.UUID  `db:"created_by_id" json:"created_by_id"`
+	TenantID    *uuid.UUID `db:"tenant_id" json:"tenant_id,omitempty"`
+	FilmType    FilmType   `db:"film_type" json:"film_type"`
+	Total       int        `db:"total" json:"total"`
+	// Queued and Failed count items by status; the rest are in progress
+	Queued    int       `db:"queued" json:"queued"`
+	Failed    int       `db:"failed" json:"failed"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CatalogImportItem is one source URL of a catalog import
+type CatalogImportItem struct {
+	ID        uuid.UUID        `db:"id" json:"id"`
+	ImportID  uuid.UUID        `db:"import_id" json:"import_id"`
+	Position  int              `db:"position" json:"position"`
+	SourceURL string           `db:"source_url" json:"source_url"`
+	Provider  ImportProvider   `db:"provider" json:"provider"`
+	Status    ImportItemStatus `db:"status" json:"status"`
+	FilmID    *uuid.UUID       `db:"film_id" json:"film_id,omitempty"`
+	Title     *string          `db:"title" json:"title,omitempty"`
+	Error     *string          `db:"error" json:"error,omitempty"`
+	// FilmStatus is the created film's own status, e.g. once transcoding
+	// finishes
+	FilmStatus *FilmStatus `db:"film_status" json:"film_status,omitempty"`
+	CreatedAt  time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time   `db:"updated_at" json:"updated_at"`
+}
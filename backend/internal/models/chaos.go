@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChaosFaults are the fault injection rates (each 0-1, the share of calls
+// that fail) applied while chaos testing is enabled
+type ChaosFaults struct {
+	R2TimeoutRate     float64    `json:"r2_timeout_rate"`
+	RedisDropRate     float64    `json:"redis_drop_rate"`
+	FFmpegFailureRate float64    `json:"ffmpeg_failure_rate"`
+	ExpiresAt         time.Time  `json:"expires_at"` // faults switch themselves off at this time
+	UpdatedByID       *uuid.UUID `json:"updated_by_id,omitempty"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// ActiveAt reports whether the faults still apply at t
+func (f *ChaosFaults) ActiveAt(t time.Time) bool {
+	return t.Before(f.ExpiresAt)
+}
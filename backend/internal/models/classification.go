@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClassificationAction is what happens to a film the content classifier
+// flags
+type ClassificationAction string
+
+const (
+	// ClassificationFlag reports the film to the moderation queue
+	ClassificationFlag ClassificationAction = "flag"
+	// ClassificationAgeRestrict raises the film's age gate right away
+	ClassificationAgeRestrict ClassificationAction = "age_restrict"
+)
+
+// Valid reports whether a is a known action
+func (a ClassificationAction) Valid() bool {
+	return a == ClassificationFlag || a == ClassificationAgeRestrict
+}
+
+// ClassificationPolicy controls the post-transcode content classification,
+// which scores frames sampled from a new film with the worker's classifier
+type ClassificationPolicy struct {
+	Enabled   bool                 `json:"enabled"`
+	Frames    int                  `json:"frames"`    // frames sampled per film
+	Labels    []string             `json:"labels"`    // labels acted on
+	Threshold float64              `json:"threshold"` // act at this confidence or above
+	Action    ClassificationAction `json:"action"`
+}
+
+// Classifiers a worker can be configured with
+const (
+	ClassifierNone = "none"
+	ClassifierHTTP = "http"
+)
+
+// ClassificationStatus is the outcome of classifying a film
+type ClassificationStatus string
+
+const (
+	ClassificationCompleted ClassificationStatus = "COMPLETED"
+	// ClassificationSkipped means no classifier is configured
+	ClassificationSkipped ClassificationStatus = "SKIPPED"
+	// ClassificationError means the classifier could not score the frames
+	ClassificationError ClassificationStatus = "ERROR"
+)
+
+// ContentClassification is one classification of a film. Scores maps each
+// label to its highest confidence across the sampled frames; Label and
+// Action are set when a score reached the policy's threshold.
+type ContentClassification struct {
+	ID         uuid.UUID             `db:"id" json:"id"`
+	FilmID     uuid.UUID             `db:"film_id" json:"film_id"`
+	Classifier string                `db:"classifier" json:"classifier"`
+	Status     ClassificationStatus  `db:"status" json:"status"`
+	Frames     int                   `db:"frames" json:"frames"`
+	Scores     json.RawMessage       `db:"scores" json:"scores"`
+	Label      *string               `db:"label" json:"label,omitempty"`
+	Action     *ClassificationAction `db:"action" json:"action,omitempty"`
+	Error      string                `db:"error" json:"error,omitempty"`
+	CreatedAt  time.Time             `db:"created_at" json:"created_at"`
+}
+
+// ClassificationCategory returns the report category a classifier label
+// files under in the moderation queue
+func ClassificationCategory(label string) ReportCategory {
+	switch label {
+	case "nsfw", "nudity", "sexual", "porn":
+		return ReportSexual
+	case "violence", "gore":
+		return ReportViolence
+	case "hate":
+		return ReportHate
+	default:
+		return ReportOther
+	}
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommentMode controls whether a film takes comments
+type CommentMode string
+
+const (
+	// CommentsEnabled publishes comments right away
+	CommentsEnabled CommentMode = "ENABLED"
+	// CommentsHeld holds comments until the film's creator approves them
+	CommentsHeld CommentMode = "HELD"
+	// CommentsDisabled takes no comments and shows none
+	CommentsDisabled CommentMode = "DISABLED"
+)
+
+// CommentStatus is a comment's moderation state; only visible comments are
+// listed and counted
+type CommentStatus string
+
+const (
+	CommentVisible CommentStatus = "VISIBLE"
+	CommentHeld    CommentStatus = "HELD"
+	CommentHidden  CommentStatus = "HIDDEN"
+	// CommentShadowHeld is suspected spam waiting for the creator's
+	// approval; its author sees it as posted
+	CommentShadowHeld CommentStatus = "SHADOW_HELD"
+)
+
+// Comment is a comment on a film; replies are one level deep
+type Comment struct {
+	ID            uuid.UUID     `db:"id" json:"id"`
+	FilmID        uuid.UUID     `db:"film_id" json:"film_id"`
+	UserID        uuid.UUID     `db:"user_id" json:"user_id"`
+	ParentID      *uuid.UUID    `db:"parent_id" json:"parent_id,omitempty"`
+	Body          string        `db:"body" json:"body"`
+	ExternalID    *string       `db:"external_id" json:"external_id,omitempty"`
+	ReplyCount    int           `db:"reply_count" json:"reply_count"`
+	EditedAt      *time.Time    `db:"edited_at" json:"edited_at,omitempty"`
+	Status        CommentStatus `db:"status" json:"status"`
+	PinnedAt      *time.Time    `db:"pinned_at" json:"pinned_at,omitempty"`
+	ReportCount   int           `db:"report_count" json:"report_count"`
+	ModeratedByID *uuid.UUID    `db:"moderated_by_id" json:"moderated_by_id,omitempty"`
+	ModeratedAt   *time.Time    `db:"moderated_at" json:"moderated_at,omitempty"`
+	SpamReason    *string       `db:"spam_reason" json:"spam_reason,omitempty"`
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time     `db:"updated_at" json:"updated_at"`
+	// Author fields are joined from users for listings
+	AuthorName      string `db:"author_name" json:"author_name"`
+	AuthorAvatarURL string `db:"author_avatar_url" json:"author_avatar_url,omitempty"`
+}
+
+// ReportedComment is a comment with open reports, as admins list them
+type ReportedComment struct {
+	Comment
+	LastReportedAt time.Time `db:"last_reported_at" json:"last_reported_at"`
+}
+
+// CommentArchive is the document comments are imported from and exported
+// to, so an export can be imported elsewhere
+type CommentArchive struct {
+	FilmID     *uuid.UUID        `json:"film_id,omitempty"`
+	ExportedAt *time.Time        `json:"exported_at,omitempty"`
+	Comments   []ArchivedComment `json:"comments"`
+}
+
+// ArchivedComment is one comment in an archive. IDs are the ones used on
+// the original platform; replies name their parent's id.
+type ArchivedComment struct {
+	ID        string `json:"id"`
+	ParentID  string `json:"parent_id,omitempty"`
+	Author    string `json:"author"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"` // RFC3339
+}
+
+// CommentImport is a validated archived comment ready to store. Replies to
+// replies are reattached to the thread's top-level comment.
+type CommentImport struct {
+	ExternalID string
+	RootID     string // external id of the top-level comment, "" for one
+	Author     string
+	Body       string
+	CreatedAt  time.Time
+}
+
+// CommentImportReport summarizes a comment import
+type CommentImportReport struct {
+	Total    int                  `json:"total"`
+	Imported int                  `json:"imported"`
+	Skipped  int                  `json:"skipped"` // already imported
+	Invalid  int                  `json:"invalid"`
+	Errors   []CommentImportError `json:"errors"`
+}
+
+// CommentImportError explains why one archived comment was rejected
+type CommentImportError struct {
+	Index int    `json:"index"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EgressDay is the bytes delivered on one day. Bytes is the CDN-reported
+// count where one was ingested and the estimate otherwise.
+type EgressDay struct {
+	Day            time.Time `db:"day" json:"day"`
+	EstimatedBytes int64     `db:"estimated_bytes" json:"estimated_bytes"`
+	CDNBytes       *int64    `db:"cdn_bytes" json:"cdn_bytes,omitempty"` // nil when no day had CDN counts
+	Bytes          int64     `db:"bytes" json:"bytes"`
+}
+
+// FilmEgress is the bytes one film delivered over a range of days
+type FilmEgress struct {
+	FilmID         uuid.UUID `db:"film_id" json:"film_id"`
+	Title          string    `db:"title" json:"title"`
+	CreatorID      uuid.UUID `db:"creator_id" json:"creator_id"`
+	EstimatedBytes int64     `db:"estimated_bytes" json:"estimated_bytes"`
+	CDNBytes       *int64    `db:"cdn_bytes" json:"cdn_bytes,omitempty"`
+	Bytes          int64     `db:"bytes" json:"bytes"`
+}
+
+// CreatorEgress is the bytes one creator's films delivered over a range of
+// days
+type CreatorEgress struct {
+	CreatorID uuid.UUID `db:"creator_id" json:"creator_id"`
+	Name      *string   `db:"name" json:"name,omitempty"`
+	Films     int       `db:"films" json:"films"`
+	Bytes     int64     `db:"bytes" json:"bytes"`
+}
+
+// CDNEgress is one film's delivered bytes on one day, as counted from CDN
+// logs
+type CDNEgress struct {
+	Day    string    `json:"day" binding:"required"` // YYYY-MM-DD
+	FilmID uuid.UUID `json:"film_id" binding:"required"`
+	Bytes  int64     `json:"bytes" binding:"min=0"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailFormat is the markup an email template body is written in
+type EmailFormat string
+
+const (
+	EmailFormatHTML EmailFormat = "html"
+	// EmailFormatMJML bodies are compiled to HTML when saved
+	EmailFormatMJML EmailFormat = "mjml"
+)
+
+// EmailTemplateScope is whose email a template override brands: an
+// organization's or a creator's. Exactly one is set.
+type EmailTemplateScope struct {
+	TenantID  *uuid.UUID
+	CreatorID *uuid.UUID
+}
+
+// EmailTemplate overrides a system email template for an organization or
+// a creator. Body is the source as written and HTML its compiled form; an
+// empty Subject or TextBody keeps the system template's.
+type EmailTemplate struct {
+	ID          uuid.UUID   `db:"id" json:"id"`
+	Key         string      `db:"template_key" json:"key"`
+	TenantID    *uuid.UUID  `db:"tenant_id" json:"tenant_id,omitempty"`
+	CreatorID   *uuid.UUID  `db:"creator_id" json:"creator_id,omitempty"`
+	Format      EmailFormat `db:"format" json:"format"`
+	Subject     string      `db:"subject" json:"subject"`
+	Body        string      `db:"body" json:"body"`
+	HTML        string      `db:"html" json:"-"`
+	TextBody    string      `db:"text_body" json:"text"`
+	UpdatedByID *uuid.UUID  `db:"updated_by_id" json:"updated_by_id,omitempty"`
+	CreatedAt   time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time   `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var planKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// PlaybackPlan caps the renditions its viewers are served
+type PlaybackPlan struct {
+	Key       string `json:"key"`
+	Name      string `json:"name"`
+	MaxHeight int    `json:"max_height"` // tallest rendition served, 0 for no cap
+}
+
+// PlanCatalog is the plans offered on the platform or by an organization,
+// and the plan of viewers without an entitlement
+type PlanCatalog struct {
+	Default string         `json:"default"`
+	Plans   []PlaybackPlan `json:"plans"`
+}
+
+// Plan returns the plan with a key
+func (c *PlanCatalog) Plan(key string) (PlaybackPlan, bool) {
+	for _, p := range c.Plans {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return PlaybackPlan{}, false
+}
+
+// Validate checks plan keys are unique slugs, heights are not negative and
+// the default plan is one of the plans
+func (c *PlanCatalog) Validate() error {
+	seen := make(map[string]bool, len(c.Plans))
+	for _, p := range c.Plans {
+		if !planKeyPattern.MatchString(p.Key) {
+			return fmt.Errorf("plan keys must be lowercase letters, digits, - and _ (at most 50)")
+		}
+		if seen[p.Key] {
+			return fmt.Errorf("plan %s is listed twice", p.Key)
+		}
+		seen[p.Key] = true
+		if p.MaxHeight < 0 {
+			return fmt.Errorf("plan %s max_height must not be negative", p.Key)
+		}
+	}
+	if !seen[c.Default] {
+		return fmt.Errorf("default must be one of the plans")
+	}
+	return nil
+}
+
+// TenantPlanCatalog is an organization's own plan catalog
+type TenantPlanCatalog struct {
+	TenantID    uuid.UUID       `db:"tenant_id" json:"tenant_id"`
+	Catalog     json.RawMessage `db:"catalog" json:"catalog"`
+	UpdatedByID *uuid.UUID      `db:"updated_by_id" json:"updated_by_id,omitempty"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// Entitlement puts a viewer on a plan, platform-wide or for one
+// organization's films
+type Entitlement struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	UserID      uuid.UUID  `db:"user_id" json:"user_id"`
+	TenantID    *uuid.UUID `db:"tenant_id" json:"tenant_id,omitempty"` // nil for platform-wide
+	PlanKey     string     `db:"plan_key" json:"plan_key"`
+	ExpiresAt   *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	GrantedByID *uuid.UUID `db:"granted_by_id" json:"granted_by_id,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
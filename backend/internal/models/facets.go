@@ -0,0 +1,25 @@
+package models
+
+// FacetCount is the number of films with one value of a facet
+type FacetCount struct {
+	Value string `db:"value" json:"value"`
+	Count int    `db:"count" json:"count"`
+}
+
+// DurationFacet is the number of films in a duration bucket. The bounds
+// map onto the min_duration/max_duration listing filters.
+type DurationFacet struct {
+	Bucket     string `json:"bucket"`
+	MinSeconds *int   `json:"min_duration,omitempty"`
+	MaxSeconds *int   `json:"max_duration,omitempty"` // inclusive
+	Count      int    `json:"count"`
+}
+
+// FilmFacets holds facet counts for a film listing. Each facet is counted
+// with every other active filter applied but not its own, so the UI can
+// show how many films selecting another value would return.
+type FilmFacets struct {
+	Genres    []FacetCount    `json:"genres"`
+	Types     []FacetCount    `json:"types"`
+	Durations []DurationFacet `json:"durations"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeaturedFilm is a scheduled hero slot on the home page
+type FeaturedFilm struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	FilmID       uuid.UUID  `db:"film_id" json:"film_id"`
+	Headline     *string    `db:"headline" json:"headline,omitempty"` // overrides the film title
+	Tagline      *string    `db:"tagline" json:"tagline,omitempty"`   // overrides the film description
+	HeroImageKey *string    `db:"hero_image_key" json:"-"`
+	HeroImageURL string     `db:"-" json:"hero_image_url,omitempty"`
+	StartsAt     time.Time  `db:"starts_at" json:"starts_at"`
+	EndsAt       *time.Time `db:"ends_at" json:"ends_at,omitempty"`
+	Priority     int        `db:"priority" json:"priority"`
+	CreatedByID  *uuid.UUID `db:"created_by_id" json:"created_by_id,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// LiveAt reports whether the slot's window contains t
+func (f *FeaturedFilm) LiveAt(t time.Time) bool {
+	return !f.StartsAt.After(t) && (f.EndsAt == nil || f.EndsAt.After(t))
+}
+
+// Featured is a live hero slot joined with its film for the frontend
+type Featured struct {
+	FeaturedFilm
+	Film Film `json:"film"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SitemapFilm is one film URL in the sitemap
+type SitemapFilm struct {
+	ID        uuid.UUID `db:"id"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// FeedFilm is a film in an RSS feed with its creator's display name
+type FeedFilm struct {
+	Film
+	CreatorName string `db:"creator_name"`
+}
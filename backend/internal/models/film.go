@@ -1,9 +1,11 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // FilmType represents the type of film content
@@ -20,9 +22,11 @@ type FilmStatus string
 const (
 	StatusDraft      FilmStatus = "DRAFT"
 	StatusUploaded   FilmStatus = "UPLOADED"
+	StatusScanning   FilmStatus = "SCANNING" // source being scanned for malware before transcoding
 	StatusTranscoding FilmStatus = "TRANSCODING"
 	StatusReady      FilmStatus = "READY"
 	StatusFailed     FilmStatus = "FAILED"
+	StatusExpired    FilmStatus = "EXPIRED" // upload abandoned; a new upload URL revives it
 )
 
 // Film represents a video content item
@@ -32,15 +36,100 @@ type Film struct {
 	Description  string     `db:"description" json:"description"`
 	Duration     int        `db:"duration" json:"duration"` // in seconds
 	Type         FilmType   `db:"type" json:"type"`
+	Genre        string     `db:"genre" json:"genre,omitempty"`
 	Status       FilmStatus `db:"status" json:"status"`
 	ThumbnailURL string     `db:"thumbnail_url" json:"thumbnail_url,omitempty"`
 	HLSMasterURL string     `db:"hls_master_url" json:"hls_master_url,omitempty"`
+	Tags         pq.StringArray `db:"tags" json:"tags"`
+	AllowedRegions pq.StringArray `db:"allowed_regions" json:"allowed_regions"`
+	BlockedRegions pq.StringArray `db:"blocked_regions" json:"blocked_regions"`
+	SearchVector string     `db:"search_vector" json:"-"`
 	CreatedByID  uuid.UUID  `db:"created_by_id" json:"created_by_id"`
 	CreatedBy    *User      `db:"created_by" json:"created_by,omitempty"`
+	TenantID     *uuid.UUID `db:"tenant_id" json:"tenant_id,omitempty"`
+	ApprovalState ApprovalState `db:"approval_state" json:"approval_state"`
 	ViewCount   int        `db:"view_count" json:"view_count"`
+	AverageRating float64  `db:"average_rating" json:"average_rating"`
+	RatingCount   int      `db:"rating_count" json:"rating_count"`
+	LikeCount     int      `db:"like_count" json:"like_count"`
+	DislikeCount  int      `db:"dislike_count" json:"dislike_count"`
+	CommentCount  int      `db:"comment_count" json:"comment_count"`
+	CommentMode   CommentMode `db:"comment_mode" json:"comment_mode"`
+	Visibility    Visibility  `db:"visibility" json:"visibility"`
+	Policy        json.RawMessage `db:"policy" json:"policy"` // FilmPolicy
+	// ViewerReaction is the signed-in viewer's own reaction, when they have one
+	ViewerReaction *Reaction `db:"-" json:"viewer_reaction,omitempty"`
+	// InWatchlist is whether the signed-in viewer saved the film for later
+	InWatchlist *bool `db:"-" json:"in_watchlist,omitempty"`
 	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
 	PublishedAt *time.Time `db:"published_at" json:"published_at,omitempty"`
+	PressEmbargoUntil *time.Time `db:"press_embargo_until" json:"press_embargo_until,omitempty"`
+	DownloadPriceCents *int64  `db:"download_price_cents" json:"download_price_cents,omitempty"`
+	DownloadCurrency   *string `db:"download_currency" json:"download_currency,omitempty"`
+	// SourceRegion labels the bucket holding the uploaded source; empty
+	// for the primary bucket
+	SourceRegion string `db:"source_region" json:"-"`
+	// SourceRetention overrides the source retention policy for the film;
+	// SourceDisposition is what the policy did to its source
+	SourceRetention   *SourceRetentionAction `db:"source_retention" json:"-"`
+	SourceDisposition *string                `db:"source_disposition" json:"-"`
+	SourceDisposedAt  *time.Time             `db:"source_disposed_at" json:"-"`
+	// SourceSizeBytes is the declared size of the source, counted against
+	// the creator's storage quota
+	SourceSizeBytes *int64 `db:"source_size_bytes" json:"-"`
+	// Attribution names the original creator of a film kept after their
+	// account was deleted and reassigned to the ghost account
+	Attribution string `db:"attribution" json:"attribution,omitempty"`
+	// OnOwnerDeletion is what happens to the film if its owner's account
+	// is deleted
+	OnOwnerDeletion OwnerDeletionPolicy `db:"on_owner_deletion" json:"on_owner_deletion"`
+}
+
+// Visibility controls where a film can be found
+type Visibility string
+
+const (
+	// VisibilityPublic lists the film in the catalog, search and rails
+	VisibilityPublic Visibility = "PUBLIC"
+	// VisibilityUnlisted keeps the film out of listings; anyone with its
+	// link may still watch it
+	VisibilityUnlisted Visibility = "UNLISTED"
+	// VisibilityPrivate shows the film only to its creator and admins
+	VisibilityPrivate Visibility = "PRIVATE"
+)
+
+// FilmPolicy is a film's policy document: who may find, watch, comment on,
+// download and embed it. The policy package validates and evaluates it.
+type FilmPolicy struct {
+	Visibility Visibility  `json:"visibility"`
+	Comments   CommentMode `json:"comments"`
+	// Downloads allows selling the film as a download-to-own
+	Downloads bool `json:"downloads"`
+	// Embeds allows playback in players embedded on other sites
+	Embeds bool `json:"embeds"`
+	// EmbedDomains limits embedding to these sites and their subdomains;
+	// empty allows any site
+	EmbedDomains []string `json:"embed_domains"`
+	// MinAge is the minimum viewer age; 0 means no age gate
+	MinAge  int         `json:"min_age"`
+	Regions RegionRules `json:"regions"`
+	Spam    SpamFilter  `json:"spam"`
+}
+
+// SpamFilter decides which comments on a film are held as suspected spam.
+// Comments with more than MaxLinks links or any of Keywords are held.
+type SpamFilter struct {
+	Enabled  bool     `json:"enabled"`
+	MaxLinks int      `json:"max_links"`
+	Keywords []string `json:"keywords"`
+}
+
+// RegionRules lists ISO 3166-1 alpha-2 countries a film is licensed or
+// blocked in. An empty allow list means everywhere but the blocked ones.
+type RegionRules struct {
+	Allowed []string `json:"allowed"`
+	Blocked []string `json:"blocked"`
 }
 
 // VideoAsset represents different quality versions of a film
@@ -48,9 +137,35 @@ type VideoAsset struct {
 	ID        uuid.UUID `db:"id" json:"id"`
 	FilmID    uuid.UUID `db:"film_id" json:"film_id"`
 	Quality   string    `db:"quality" json:"quality"` // 360p, 720p, etc.
+	Variant   string    `db:"variant" json:"variant"` // default, burnin-en, etc.
 	HLSIndexURL string   `db:"hls_index_url" json:"hls_index_url"`
 	SizeBytes int64     `db:"size_bytes" json:"size_bytes"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// Prefetch hints measured at transcode; zero for older renditions
+	FirstSegmentURL   string  `db:"first_segment_url" json:"first_segment_url,omitempty"`
+	SegmentCount      int     `db:"segment_count" json:"segment_count"`
+	AvgSegmentBytes   int64   `db:"avg_segment_bytes" json:"avg_segment_bytes"`
+	AvgSegmentSeconds float64 `db:"avg_segment_seconds" json:"avg_segment_seconds"`
+	Bandwidth         int     `db:"bandwidth" json:"bandwidth"` // average bits per second
+	// Quality against the original, from the optional QC stage of a
+	// re-transcode; nil until measured
+	VMAF  *float64   `db:"vmaf" json:"vmaf,omitempty"`
+	PSNR  *float64   `db:"psnr" json:"psnr,omitempty"`
+	QCAt  *time.Time `db:"qc_at" json:"qc_at,omitempty"`
+}
+
+// VariantDefault is the standard rendition set produced by transcoding
+const VariantDefault = "default"
+
+// RetranscodeVariant holds a film's renditions transcoded again, awaiting
+// an admin's review before they replace the default set; only admins are
+// served it
+const RetranscodeVariant = "retranscode"
+
+// BurnInVariant returns the variant name for renditions with the given
+// subtitle language burned in
+func BurnInVariant(language string) string {
+	return "burnin-" + language
 }
 
 // TranscodeJob represents a video processing job
@@ -64,3 +179,10 @@ type TranscodeJob struct {
 	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
 	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
 }
+
+// FilmCount is a maintained count of films for one dimension value
+type FilmCount struct {
+	Dimension string `db:"dimension" json:"dimension"` // creator, status, genre
+	Value     string `db:"value" json:"value"`
+	Count     int64  `db:"count" json:"count"`
+}
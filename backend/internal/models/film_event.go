@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FilmEventType names a step in a film's lifecycle
+type FilmEventType string
+
+const (
+	FilmEventCreated   FilmEventType = "film.created"
+	FilmEventReady     FilmEventType = "film.ready"
+	FilmEventPublished FilmEventType = "film.published"
+)
+
+// FilmEventTypes lists the lifecycle events in the order a film goes
+// through them
+var FilmEventTypes = []FilmEventType{FilmEventCreated, FilmEventReady, FilmEventPublished}
+
+// FilmEvent is a lifecycle event on the film event stream. Consumers load
+// the film for details, so an event stays valid however late it is read.
+type FilmEvent struct {
+	ID         uuid.UUID     `json:"id"`
+	Type       FilmEventType `json:"type"`
+	FilmID     uuid.UUID     `json:"film_id"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	// Replayed marks events re-emitted by a backfill; consumers must
+	// handle them idempotently
+	Replayed bool `json:"replayed,omitempty"`
+}
+
+// FilmLifecycle holds when a film went through each lifecycle step, for
+// replaying its events
+type FilmLifecycle struct {
+	FilmID      uuid.UUID  `db:"film_id"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ReadyAt     *time.Time `db:"ready_at"`
+	PublishedAt *time.Time `db:"published_at"`
+}
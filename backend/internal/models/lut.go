@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LUTScope is the reach of a LUT; film beats creator beats platform
+type LUTScope string
+
+const (
+	LUTScopePlatform LUTScope = "platform"
+	LUTScopeCreator  LUTScope = "creator"
+	LUTScopeFilm     LUTScope = "film"
+)
+
+// LUT is an uploaded 3D LUT (.cube) applied during encode
+type LUT struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	Scope      LUTScope   `db:"scope" json:"scope"`
+	CreatorID  *uuid.UUID `db:"creator_id" json:"creator_id,omitempty"`
+	FilmID     *uuid.UUID `db:"film_id" json:"film_id,omitempty"`
+	Name       string     `db:"name" json:"name"`
+	StorageKey string     `db:"storage_key" json:"-"`
+	Size       int        `db:"size" json:"size"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+}
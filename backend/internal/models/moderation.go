@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Moderation actions: the decision a moderator last made on a comment or
+// review, or an action taken from the moderation queue (below)
+const (
+	ModerationCommentHidden  = "comment_hidden"
+	ModerationCommentVisible = "comment_visible"
+	ModerationReviewHidden   = "review_hidden"
+	ModerationReviewVisible  = "review_visible"
+)
+
+// ModerationAction is the latest moderation decision on a comment or
+// review, or an action taken from the moderation queue. Target is comment,
+// review or film and ID is its ID; AuthorID is its author or creator.
+type ModerationAction struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	Target      string     `db:"target" json:"target"`
+	Action      string     `db:"action" json:"action"`
+	FilmID      uuid.UUID  `db:"film_id" json:"film_id"`
+	AuthorID    uuid.UUID  `db:"author_id" json:"author_id"`
+	ModeratorID *uuid.UUID `db:"moderator_id" json:"moderator_id,omitempty"`
+	ModeratedAt time.Time  `db:"moderated_at" json:"moderated_at"`
+}
+
+// Actions an admin takes on a reported film or comment from the moderation
+// queue. Each resolves the target's open reports.
+const (
+	// ModerationDismiss closes the reports and changes nothing else
+	ModerationDismiss = "dismiss"
+	// ModerationAgeRestrict raises the film's age gate
+	ModerationAgeRestrict = "age_restrict"
+	// ModerationUnpublish takes a film out of the catalog and makes it
+	// private, or hides a comment
+	ModerationUnpublish = "unpublish"
+	// ModerationBanCreator bans the film's creator or the comment's author
+	ModerationBanCreator = "ban_creator"
+)
+
+// ModerationQueueActions lists the actions the moderation queue takes
+var ModerationQueueActions = []string{
+	ModerationDismiss, ModerationAgeRestrict, ModerationUnpublish, ModerationBanCreator,
+}
+
+// ModerationQueueItem is a reported film or comment in the moderation
+// queue. OwnerID is the film's creator or the comment's author.
+type ModerationQueueItem struct {
+	ReportedTarget
+	OwnerID uuid.UUID `db:"owner_id" json:"owner_id"`
+}
+
+// ModerationAuditEntry records an action taken from the moderation queue
+type ModerationAuditEntry struct {
+	ID              uuid.UUID  `db:"id" json:"id"`
+	AdminID         *uuid.UUID `db:"admin_id" json:"admin_id,omitempty"`
+	Action          string     `db:"action" json:"action"`
+	TargetType      string     `db:"target_type" json:"target_type"`
+	TargetID        uuid.UUID  `db:"target_id" json:"target_id"`
+	FilmID          uuid.UUID  `db:"film_id" json:"film_id"`
+	SubjectUserID   uuid.UUID  `db:"subject_user_id" json:"subject_user_id"`
+	ReportsResolved int        `db:"reports_resolved" json:"reports_resolved"`
+	Reason          string     `db:"reason" json:"reason,omitempty"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TenantRole is a user's role within an organization
+type TenantRole string
+
+const (
+	TenantRoleOwner    TenantRole = "OWNER"
+	TenantRoleProducer TenantRole = "PRODUCER"
+	TenantRoleEditor   TenantRole = "EDITOR"
+)
+
+// Valid reports whether r is a known organization role
+func (r TenantRole) Valid() bool {
+	switch r {
+	case TenantRoleOwner, TenantRoleProducer, TenantRoleEditor:
+		return true
+	}
+	return false
+}
+
+// TenantMember links a user to an organization with a role
+type TenantMember struct {
+	TenantID  uuid.UUID  `db:"tenant_id" json:"tenant_id"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	Role      TenantRole `db:"role" json:"role"`
+	Email     string     `db:"email" json:"email"`
+	Name      string     `db:"name" json:"name"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// TenantMembership is one of the current user's organizations
+type TenantMembership struct {
+	Tenant
+	Role TenantRole `db:"role" json:"role"`
+}
+
+// CanApprove reports whether the role may approve films under the tenant's
+// approval policy
+func (t *Tenant) CanApprove(role TenantRole) bool {
+	for _, r := range t.ApproverRoles {
+		if TenantRole(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ApprovalState tracks a film through its organization's approval chain
+type ApprovalState string
+
+const (
+	ApprovalNone      ApprovalState = "NONE"
+	ApprovalSubmitted ApprovalState = "SUBMITTED"
+	ApprovalInReview  ApprovalState = "IN_REVIEW"
+	ApprovalApproved  ApprovalState = "APPROVED"
+	ApprovalRejected  ApprovalState = "REJECTED"
+)
+
+// ApprovalAction is a step taken in an approval chain
+type ApprovalAction string
+
+const (
+	ApprovalActionSubmit  ApprovalAction = "SUBMIT"
+	ApprovalActionReview  ApprovalAction = "REVIEW"
+	ApprovalActionApprove ApprovalAction = "APPROVE"
+	ApprovalActionReject  ApprovalAction = "REJECT"
+)
+
+// FilmApprovalEvent records one approval action on a film
+type FilmApprovalEvent struct {
+	ID        uuid.UUID      `db:"id" json:"id"`
+	FilmID    uuid.UUID      `db:"film_id" json:"film_id"`
+	TenantID  uuid.UUID      `db:"tenant_id" json:"tenant_id"`
+	ActorID   *uuid.UUID     `db:"actor_id" json:"actor_id,omitempty"`
+	Action    ApprovalAction `db:"action" json:"action"`
+	Comment   *string        `db:"comment" json:"comment,omitempty"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+}
+
+// Notification kinds
+const (
+	NotifyApprovalRequested = "approval_requested"
+	NotifyApprovalReview    = "approval_in_review"
+	NotifyApprovalApproved  = "approval_approved"
+	NotifyApprovalRejected  = "approval_rejected"
+	NotifySavedSearchMatch  = "saved_search_match"
+	NotifyCommentMention    = "comment_mention"
+	NotifyUploadQuarantined = "upload_quarantined"
+)
+
+// Notification is an in-app message for one user
+type Notification struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	Kind      string     `db:"kind" json:"kind"`
+	FilmID    *uuid.UUID `db:"film_id" json:"film_id,omitempty"`
+	TenantID  *uuid.UUID `db:"tenant_id" json:"tenant_id,omitempty"`
+	Message   string     `db:"message" json:"message"`
+	ReadAt    *time.Time `db:"read_at" json:"read_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Tenant represents an organization hosting films on the platform
+type Tenant struct {
+	ID                uuid.UUID      `db:"id" json:"id"`
+	Name              string         `db:"name" json:"name"`
+	Slug              string         `db:"slug" json:"slug"`
+	ApprovalRequired  bool           `db:"approval_required" json:"approval_required"`
+	ApproverRoles     pq.StringArray `db:"approver_roles" json:"approver_roles"`
+	RequiredApprovals int            `db:"required_approvals" json:"required_approvals"`
+	PrivacyMode       bool           `db:"privacy_mode" json:"privacy_mode"` // no per-user viewing data for the tenant's films
+	CreatedAt         time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// TranscodeProfile represents one rung of the transcoding quality ladder
+type TranscodeProfile struct {
+	ID           uuid.UUID `db:"id" json:"id"`
+	Name         string    `db:"name" json:"name"` // 360p, 720p, etc.
+	Width        int       `db:"width" json:"width"`
+	Height       int       `db:"height" json:"height"`
+	VideoBitrate string    `db:"video_bitrate" json:"video_bitrate"`
+	AudioBitrate string    `db:"audio_bitrate" json:"audio_bitrate"`
+	Enabled      bool      `db:"enabled" json:"enabled"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// DefaultTranscodeProfiles mirrors the worker's built-in quality ladder
+var DefaultTranscodeProfiles = []TranscodeProfile{
+	{Name: "360p", Width: 640, Height: 360, VideoBitrate: "800k", AudioBitrate: "128k", Enabled: true},
+	{Name: "720p", Width: 1280, Height: 720, VideoBitrate: "2500k", AudioBitrate: "192k", Enabled: true},
+}
+
+// BootstrapState records the result of first-run platform setup
+type BootstrapState struct {
+	AdminID        uuid.UUID `db:"admin_id" json:"admin_id"`
+	TenantID       uuid.UUID `db:"tenant_id" json:"tenant_id"`
+	BootstrappedAt time.Time `db:"bootstrapped_at" json:"bootstrapped_at"`
+}
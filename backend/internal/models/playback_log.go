@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlaybackStage is a step of handling a playback request
+type PlaybackStage string
+
+const (
+	PlaybackStageRequest     PlaybackStage = "REQUEST"
+	PlaybackStageGeo         PlaybackStage = "GEO"
+	PlaybackStageEntitlement PlaybackStage = "ENTITLEMENT"
+	PlaybackStageToken       PlaybackStage = "TOKEN"
+	PlaybackStageError       PlaybackStage = "ERROR"
+)
+
+// PlaybackOutcome is how a playback step went
+type PlaybackOutcome string
+
+const (
+	PlaybackOK     PlaybackOutcome = "OK"
+	PlaybackDenied PlaybackOutcome = "DENIED"
+	PlaybackError  PlaybackOutcome = "ERROR"
+)
+
+// PlaybackLogEntry is one step of a playback session, kept for support.
+// It holds no email, token or full IP address.
+type PlaybackLogEntry struct {
+	ID        int64           `db:"id" json:"id"`
+	SessionID uuid.UUID       `db:"session_id" json:"session_id"`
+	FilmID    uuid.UUID       `db:"film_id" json:"film_id"`
+	UserID    *uuid.UUID      `db:"user_id" json:"user_id,omitempty"`
+	Endpoint  string          `db:"endpoint" json:"endpoint"` // "public" or "press"
+	Stage     PlaybackStage   `db:"stage" json:"stage"`
+	Outcome   PlaybackOutcome `db:"outcome" json:"outcome"`
+	Reason    string          `db:"reason" json:"reason,omitempty"`
+	Details   json.RawMessage `db:"details" json:"details"`
+	Country   string          `db:"country" json:"country,omitempty"`
+	IPPrefix  *string         `db:"ip_prefix" json:"ip_prefix,omitempty"`
+	UserAgent *string         `db:"user_agent" json:"user_agent,omitempty"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+}
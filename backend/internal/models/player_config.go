@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Player platforms clients identify as
+const (
+	PlayerPlatformIOS     = "ios"
+	PlayerPlatformAndroid = "android"
+	PlayerPlatformTV      = "tv"
+	PlayerPlatformWeb     = "web"
+)
+
+// PlayerPlatforms lists the platforms player configuration can target
+var PlayerPlatforms = []string{PlayerPlatformIOS, PlayerPlatformAndroid, PlayerPlatformTV, PlayerPlatformWeb}
+
+// PlayerConfig is the server-controlled behavior of a client's player
+type PlayerConfig struct {
+	ABR   PlayerABR   `json:"abr"`
+	Retry PlayerRetry `json:"retry"`
+	// Features are kill-switches; clients treat a feature missing here as
+	// on, and a rule turns one off by setting it false
+	Features map[string]bool `json:"features"`
+}
+
+// PlayerABR caps adaptive bitrate selection; 0 leaves a cap off
+type PlayerABR struct {
+	MaxHeight      int `json:"max_height"`
+	MaxBitrateKbps int `json:"max_bitrate_kbps"`
+	StartupHeight  int `json:"startup_height"`
+}
+
+// PlayerRetry is how a player retries failed segment and playlist loads,
+// backing off exponentially between attempts
+type PlayerRetry struct {
+	MaxAttempts  int `json:"max_attempts"`
+	BackoffMs    int `json:"backoff_ms"`
+	MaxBackoffMs int `json:"max_backoff_ms"`
+}
+
+// PlayerConfigRule changes the player configuration of a platform and app
+// version range for a share of devices. Patch is a JSON merge patch over
+// the configuration; rules apply lowest priority first.
+type PlayerConfigRule struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	Name           string          `db:"name" json:"name"`
+	Description    string          `db:"description" json:"description"`
+	Platform       *string         `db:"platform" json:"platform,omitempty"` // nil = all
+	MinAppVersion  string          `db:"min_app_version" json:"min_app_version"`
+	MaxAppVersion  string          `db:"max_app_version" json:"max_app_version"`
+	RolloutPercent int             `db:"rollout_percent" json:"rollout_percent"`
+	Priority       int             `db:"priority" json:"priority"`
+	Enabled        bool            `db:"enabled" json:"enabled"`
+	Patch          json.RawMessage `db:"patch" json:"patch"`
+	UpdatedByID    *uuid.UUID      `db:"updated_by_id" json:"updated_by_id,omitempty"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// ResolvedPlayerConfig is the configuration served to one client, with
+// the rules that shaped it
+type ResolvedPlayerConfig struct {
+	Platform   string       `json:"platform"`
+	AppVersion string       `json:"app_version,omitempty"`
+	Config     PlayerConfig `json:"config"`
+	Rules      []uuid.UUID  `json:"rules"`
+	TTLSeconds int          `json:"ttl_seconds"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlaylistKind tells manual playlists from rule-based ones
+type PlaylistKind string
+
+const (
+	PlaylistManual PlaylistKind = "MANUAL"
+	PlaylistSmart  PlaylistKind = "SMART"
+)
+
+// Playlist is a user's ordered list of films. Smart playlists have Rules
+// instead of items and are evaluated when read.
+type Playlist struct {
+	ID          uuid.UUID       `db:"id" json:"id"`
+	OwnerID     uuid.UUID       `db:"owner_id" json:"owner_id"`
+	Title       string          `db:"title" json:"title"`
+	Description string          `db:"description" json:"description"`
+	Kind        PlaylistKind    `db:"kind" json:"kind"`
+	Rules       json.RawMessage `db:"rules" json:"rules,omitempty"` // PlaylistRules
+	Public      bool            `db:"public" json:"public"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// PlaylistRules selects a smart playlist's films from the published
+// catalog. Empty rules match any film.
+type PlaylistRules struct {
+	OwnFilms       bool       `json:"own_films,omitempty"` // only the playlist owner's films
+	CreatorID      *uuid.UUID `json:"creator_id,omitempty"`
+	Tags           []string   `json:"tags,omitempty"` // films must carry all of them
+	Type           FilmType   `json:"type,omitempty"`
+	Genre          string     `json:"genre,omitempty"`
+	MinDuration    *int       `json:"min_duration,omitempty"` // seconds
+	MaxDuration    *int       `json:"max_duration,omitempty"` // seconds
+	MinRating      *float64   `json:"min_rating,omitempty"`
+	PublishedAfter *time.Time `json:"published_after,omitempty"`
+	Sort           string     `json:"sort,omitempty"`  // a film listing sort, default newest
+	Limit          int        `json:"limit,omitempty"` // default 50
+}
+
+// PlaylistWithFilms is a playlist with the films the viewer may watch
+type PlaylistWithFilms struct {
+	Playlist
+	Films []Film `json:"films"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScreenerVariantPrefix marks per-recipient watermarked press renditions,
+// which are only served through press playback
+const ScreenerVariantPrefix = "screener-"
+
+// ScreenerVariant returns the rendition variant holding a press user's
+// watermarked screener of a film
+func ScreenerVariant(userID uuid.UUID) string {
+	return ScreenerVariantPrefix + strings.ReplaceAll(userID.String(), "-", "")[:16]
+}
+
+// FilmPressAccess is one entry on a film's press list
+type FilmPressAccess struct {
+	FilmID      uuid.UUID  `db:"film_id" json:"film_id"`
+	UserID      uuid.UUID  `db:"user_id" json:"user_id"`
+	GrantedByID *uuid.UUID `db:"granted_by_id" json:"granted_by_id,omitempty"`
+	ExpiresAt   *time.Time `db:"expires_at" json:"expires_at,omitempty"` // optional cutoff before the embargo
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+
+	Email         string     `db:"email" json:"email"`
+	Name          string     `db:"name" json:"name"`
+	FilmTitle     string     `db:"film_title" json:"film_title"`
+	EmbargoUntil  *time.Time `db:"embargo_until" json:"embargo_until,omitempty"`
+	ScreenerReady bool       `db:"screener_ready" json:"screener_ready"`
+}
+
+// AccessEndsAt returns when the entry stops granting access: the embargo
+// or the entry's own earlier expiry
+func (a *FilmPressAccess) AccessEndsAt() *time.Time {
+	if a.ExpiresAt != nil && (a.EmbargoUntil == nil || a.ExpiresAt.Before(*a.EmbargoUntil)) {
+		return a.ExpiresAt
+	}
+	return a.EmbargoUntil
+}
+
+// ActiveAt reports whether the entry grants access at t
+func (a *FilmPressAccess) ActiveAt(t time.Time) bool {
+	if a.EmbargoUntil == nil {
+		return false
+	}
+	return t.Before(*a.AccessEndsAt())
+}
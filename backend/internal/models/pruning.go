@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PruningPolicy decides which films the rendition pruning job takes on
+// and which of their renditions it keeps
+type PruningPolicy struct {
+	Enabled    bool     `json:"enabled"`
+	Keep       []string `json:"keep"`         // quality names kept, e.g. ["360p"]
+	MaxViews   int      `json:"max_views"`    // films with fewer views are pruned
+	MinAgeDays int      `json:"min_age_days"` // days since publishing (or upload)
+}
+
+// RenditionPrune records the pruning of one film's default renditions
+type RenditionPrune struct {
+	ID            uuid.UUID      `db:"id" json:"id"`
+	FilmID        uuid.UUID      `db:"film_id" json:"film_id"`
+	TaskID        uuid.UUID      `db:"task_id" json:"task_id"`
+	Kept          pq.StringArray `db:"kept" json:"kept"`
+	Pruned        pq.StringArray `db:"pruned" json:"pruned"`
+	BytesFreed    int64          `db:"bytes_freed" json:"bytes_freed"`
+	QueuedAt      time.Time      `db:"queued_at" json:"queued_at"`
+	PrunedAt      *time.Time     `db:"pruned_at" json:"pruned_at,omitempty"`
+	RestoreTaskID *uuid.UUID     `db:"restore_task_id" json:"restore_task_id,omitempty"`
+	RestoredAt    *time.Time     `db:"restored_at" json:"restored_at,omitempty"`
+
+	FilmTitle string `db:"film_title" json:"film_title"`
+}
+
+// PruningSavings totals the storage freed by renditions still pruned
+type PruningSavings struct {
+	Films      int   `db:"films" json:"films"`
+	Renditions int   `db:"renditions" json:"renditions"`
+	BytesFreed int64 `db:"bytes_freed" json:"bytes_freed"`
+}
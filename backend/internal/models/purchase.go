@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PurchaseStatus tracks a purchase's downloadable file
+type PurchaseStatus string
+
+const (
+	PurchasePending PurchaseStatus = "PENDING" // watermarked file being generated
+	PurchaseReady   PurchaseStatus = "READY"
+	PurchaseFailed  PurchaseStatus = "FAILED"
+	PurchaseRevoked PurchaseStatus = "REVOKED" // refunded; the file is deleted
+)
+
+// FilmPurchase is a download-to-own license for one buyer of a film
+type FilmPurchase struct {
+	ID            uuid.UUID      `db:"id" json:"id"`
+	FilmID        uuid.UUID      `db:"film_id" json:"film_id"`
+	UserID        uuid.UUID      `db:"user_id" json:"user_id"`
+	TransactionID uuid.UUID      `db:"transaction_id" json:"transaction_id"`
+	LicenseKey    string         `db:"license_key" json:"license_key"`
+	Status        PurchaseStatus `db:"status" json:"status"`
+	DownloadKey   *string        `db:"download_key" json:"-"`
+	FileSize      *int64         `db:"file_size" json:"file_size,omitempty"`
+	DownloadCount int            `db:"download_count" json:"download_count"`
+	DownloadLimit int            `db:"download_limit" json:"download_limit"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+	ReadyAt       *time.Time     `db:"ready_at" json:"ready_at,omitempty"`
+	RevokedAt     *time.Time     `db:"revoked_at" json:"revoked_at,omitempty"`
+
+	FilmTitle string `db:"film_title" json:"film_title"`
+}
+
+// DownloadsLeft returns how many more times the file may be downloaded
+func (p *FilmPurchase) DownloadsLeft() int {
+	if left := p.DownloadLimit - p.DownloadCount; left > 0 {
+		return left
+	}
+	return 0
+}
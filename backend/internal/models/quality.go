@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QualityCheckPolicy controls the post-encode quality check, which scores
+// a film's renditions against its source on sampled windows
+type QualityCheckPolicy struct {
+	Enabled       bool    `json:"enabled"`
+	Samples       int     `json:"samples"`        // windows scored per rendition
+	SampleSeconds int     `json:"sample_seconds"` // length of each window
+	MinVMAF       float64 `json:"min_vmaf"`       // alert below this score
+	MaxDrop       float64 `json:"max_drop"`       // alert this far below the baseline
+}
+
+// Quality alert reasons
+const (
+	QualityBelowFloor = "below_floor"
+	QualityRegression = "regression"
+)
+
+// QualityAlert flags a rendition that scored too low
+type QualityAlert struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	FilmID    uuid.UUID `db:"film_id" json:"film_id"`
+	FilmTitle string    `db:"film_title" json:"film_title"`
+	Variant   string    `db:"variant" json:"variant"`
+	Quality   string    `db:"quality" json:"quality"`
+	Reason    string    `db:"reason" json:"reason"`
+	VMAF      float64   `db:"vmaf" json:"vmaf"`
+	Threshold float64   `db:"threshold" json:"threshold"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// RenditionQuality summarises the measured quality of one rendition of
+// the ladder across films, the data for tuning its bitrate
+type RenditionQuality struct {
+	Quality      string   `db:"quality" json:"quality"`
+	Renditions   int      `db:"renditions" json:"renditions"`
+	AvgVMAF      *float64 `db:"avg_vmaf" json:"avg_vmaf"`
+	P10VMAF      *float64 `db:"p10_vmaf" json:"p10_vmaf"` // 10% of renditions score lower
+	AvgPSNR      *float64 `db:"avg_psnr" json:"avg_psnr"`
+	AvgBandwidth float64  `db:"avg_bandwidth" json:"avg_bandwidth"`
+}
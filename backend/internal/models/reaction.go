@@ -0,0 +1,17 @@
+package models
+
+// Reaction is a user's like or dislike of a film
+type Reaction string
+
+const (
+	ReactionLike    Reaction = "LIKE"
+	ReactionDislike Reaction = "DISLIKE"
+)
+
+// FilmReactions is a film's reaction counts and the viewer's own reaction,
+// nil when they have none
+type FilmReactions struct {
+	LikeCount      int       `db:"like_count" json:"like_count"`
+	DislikeCount   int       `db:"dislike_count" json:"dislike_count"`
+	ViewerReaction *Reaction `db:"-" json:"viewer_reaction"`
+}
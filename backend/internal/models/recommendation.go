@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecommendationReason explains why a film was recommended
+type RecommendationReason string
+
+const (
+	ReasonCoView RecommendationReason = "because_you_watched"
+	ReasonGenre  RecommendationReason = "genre"
+	ReasonFollow RecommendationReason = "followed_creator"
+)
+
+// RecommendationCandidate is a scored film for a user, as precomputed by
+// the recommendations batch job
+type RecommendationCandidate struct {
+	FilmID    uuid.UUID            `json:"film_id"`
+	Score     float64              `json:"score"`
+	Reason    RecommendationReason `json:"reason"`
+	BecauseOf *uuid.UUID           `json:"because_of,omitempty"` // seed film for co-view picks
+}
+
+// RecommendationFeatures are a candidate's named signals (co_view, genre,
+// follow), each normalised to 0..1, as a recommender ranks them
+type RecommendationFeatures map[string]float64
+
+// RecommendationFeatureLog is one candidate a recommender considered for a
+// user, kept as model training data
+type RecommendationFeatureLog struct {
+	ListID    uuid.UUID              `db:"list_id" json:"list_id"` // groups one computed list
+	UserID    uuid.UUID              `db:"user_id" json:"user_id"`
+	FilmID    uuid.UUID              `db:"film_id" json:"film_id"`
+	Model     string                 `db:"model" json:"model"`
+	Rank      *int                   `db:"rank" json:"rank,omitempty"` // 1-based; nil when cut from the list
+	Score     *float64               `db:"score" json:"score,omitempty"`
+	Features  RecommendationFeatures `db:"-" json:"features"`
+	CreatedAt time.Time              `db:"created_at" json:"created_at"`
+}
+
+// Recommendation is a candidate joined with its film for API responses
+type Recommendation struct {
+	Film          Film                 `json:"film"`
+	Score         float64              `json:"score"`
+	Reason        RecommendationReason `json:"reason"`
+	BecauseOf     *uuid.UUID           `json:"because_of,omitempty"`
+	BecauseOfFilm string               `json:"because_of_title,omitempty"`
+}
+
+// RelatedWeights controls how related-film signals are mixed
+type RelatedWeights struct {
+	Creator float64 `json:"creator"`
+	Genre   float64 `json:"genre"`
+	Tags    float64 `json:"tags"` // scaled by the share of the film's tags in common
+}
+
+// RelatedFilm is a film scored against another film
+type RelatedFilm struct {
+	Film
+	Score float64 `db:"score" json:"score"`
+}
+
+// InProgressFilm is a film a user started but has not finished
+type InProgressFilm struct {
+	Film
+	PositionSeconds int       `db:"position_seconds" json:"position_seconds"`
+	DurationSeconds int       `db:"watch_duration_seconds" json:"duration_seconds"`
+	LastPlayedAt    time.Time `db:"position_updated_at" json:"last_played_at"`
+}
+
+// PlaybackPosition is how far a user has played a film, as heartbeated
+// by the player
+type PlaybackPosition struct {
+	UserID          uuid.UUID `json:"user_id"`
+	FilmID          uuid.UUID `json:"film_id"`
+	PositionSeconds int       `json:"position_seconds"`
+	DurationSeconds *int      `json:"duration_seconds,omitempty"` // as reported by the player
+	Completed       bool      `json:"completed"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ContinueWatchingItem is an in-progress film with its resume point
+type ContinueWatchingItem struct {
+	Film           Film      `json:"film"`
+	PercentWatched float64   `json:"percent_watched"`
+	ResumeOffset   int       `json:"resume_offset"` // seconds
+	LastPlayedAt   time.Time `json:"last_played_at"`
+}
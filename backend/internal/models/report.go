@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportCategory is why a film or comment was reported
+type ReportCategory string
+
+const (
+	ReportSpam       ReportCategory = "spam"
+	ReportHarassment ReportCategory = "harassment"
+	ReportHate       ReportCategory = "hate"
+	ReportViolence   ReportCategory = "violence"
+	ReportSexual     ReportCategory = "sexual"
+	ReportCopyright  ReportCategory = "copyright"
+	ReportMisleading ReportCategory = "misleading"
+	ReportOther      ReportCategory = "other"
+)
+
+// ReportCategories lists the categories a report can have
+var ReportCategories = []ReportCategory{
+	ReportSpam, ReportHarassment, ReportHate, ReportViolence,
+	ReportSexual, ReportCopyright, ReportMisleading, ReportOther,
+}
+
+// Valid reports whether c is a known category
+func (c ReportCategory) Valid() bool {
+	for _, category := range ReportCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Report target types
+const (
+	ReportTargetFilm    = "film"
+	ReportTargetComment = "comment"
+)
+
+// FilmReport is a user's report of a film. Open reports have no
+// ResolvedAt.
+type FilmReport struct {
+	ID           uuid.UUID      `db:"id" json:"id"`
+	FilmID       uuid.UUID      `db:"film_id" json:"film_id"`
+	UserID       uuid.UUID      `db:"user_id" json:"user_id"`
+	Category     ReportCategory `db:"category" json:"category"`
+	Reason       string         `db:"reason" json:"reason,omitempty"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+	ResolvedAt   *time.Time     `db:"resolved_at" json:"resolved_at,omitempty"`
+	ResolvedByID *uuid.UUID     `db:"resolved_by_id" json:"resolved_by_id,omitempty"`
+}
+
+// ReportedTarget aggregates the open reports of a film or comment for the
+// moderation queue. Excerpt is the start of a reported comment.
+type ReportedTarget struct {
+	TargetType      string          `db:"target_type" json:"target_type"`
+	TargetID        uuid.UUID       `db:"target_id" json:"target_id"`
+	FilmID          uuid.UUID       `db:"film_id" json:"film_id"`
+	FilmTitle       string          `db:"film_title" json:"film_title"`
+	Excerpt         string          `db:"excerpt" json:"excerpt,omitempty"`
+	Reports         int             `db:"reports" json:"reports"`
+	Categories      json.RawMessage `db:"categories" json:"categories"` // category -> reports
+	FirstReportedAt time.Time       `db:"first_reported_at" json:"first_reported_at"`
+	LastReportedAt  time.Time       `db:"last_reported_at" json:"last_reported_at"`
+}
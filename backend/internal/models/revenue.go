@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransactionKind identifies what a film transaction paid for
+type TransactionKind string
+
+const (
+	TransactionRental       TransactionKind = "rental"
+	TransactionPurchase     TransactionKind = "purchase"
+	TransactionSubscription TransactionKind = "subscription" // attributed share of a subscription
+	TransactionRefund       TransactionKind = "refund"
+)
+
+// FilmTransaction is one entry in the per-film revenue ledger. Refunds
+// carry a negative amount and reference the refunded transaction.
+type FilmTransaction struct {
+	ID          uuid.UUID       `db:"id" json:"id"`
+	FilmID      uuid.UUID       `db:"film_id" json:"film_id"`
+	UserID      *uuid.UUID      `db:"user_id" json:"user_id,omitempty"`
+	Kind        TransactionKind `db:"kind" json:"kind"`
+	AmountCents int64           `db:"amount_cents" json:"amount_cents"`
+	Currency    string          `db:"currency" json:"currency"`
+	RefundOf    *uuid.UUID      `db:"refund_of" json:"refund_of,omitempty"`
+	ExternalRef *string         `db:"external_ref" json:"external_ref,omitempty"`
+	OccurredAt  time.Time       `db:"occurred_at" json:"occurred_at"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+}
+
+// RevenueDay is a film's revenue for one day in one currency
+type RevenueDay struct {
+	Day           time.Time `db:"day" json:"day"`
+	Currency      string    `db:"currency" json:"currency"`
+	GrossCents    int64     `db:"gross_cents" json:"gross_cents"`
+	RefundCents   int64     `db:"refund_cents" json:"refund_cents"` // positive
+	NetCents      int64     `db:"net_cents" json:"net_cents"`
+	Rentals       int       `db:"rentals" json:"rentals"`
+	Purchases     int       `db:"purchases" json:"purchases"`
+	Subscriptions int       `db:"subscriptions" json:"subscriptions"`
+	Refunds       int       `db:"refunds" json:"refunds"`
+}
+
+// RevenueConversion relates trailer viewers to paying customers
+type RevenueConversion struct {
+	TrailerViewers int     `db:"trailer_viewers" json:"trailer_viewers"`
+	Buyers         int     `db:"buyers" json:"buyers"` // trailer viewers who rented or bought
+	Rate           float64 `db:"-" json:"rate"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Review is a user's 1-5 star rating of a film with optional review text
+type Review struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	FilmID        uuid.UUID  `db:"film_id" json:"film_id"`
+	UserID        uuid.UUID  `db:"user_id" json:"user_id"`
+	Rating        int        `db:"rating" json:"rating"`
+	Body          string     `db:"body" json:"body"`
+	FlagCount     int        `db:"flag_count" json:"flag_count"`
+	Hidden        bool       `db:"hidden" json:"hidden"`
+	ModeratedByID *uuid.UUID `db:"moderated_by_id" json:"moderated_by_id,omitempty"`
+	ModeratedAt   *time.Time `db:"moderated_at" json:"moderated_at,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+	// AuthorName is joined from users for listings
+	AuthorName string `db:"author_name" json:"author_name"`
+}
+
+// FlaggedReview is a review with open flags, as admins list them
+type FlaggedReview struct {
+	Review
+	LastFlaggedAt time.Time `db:"last_flagged_at" json:"last_flagged_at"`
+}
+
+// Flagged review actions, for filtering flagged reviews
+const (
+	FlaggedReviewHidden  = "hidden"
+	FlaggedReviewVisible = "visible"
+)
+
+// FilmRating is a film's rating summary, kept on the film row
+type FilmRating struct {
+	AverageRating float64 `db:"average_rating" json:"average_rating"`
+	RatingCount   int     `db:"rating_count" json:"rating_count"`
+}
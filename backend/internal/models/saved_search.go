@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SavedSearch is a user's search text and listing filters; the user is
+// alerted when newly published films match. Nil filters match any film.
+type SavedSearch struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	UserID       uuid.UUID  `db:"user_id" json:"user_id"`
+	Name         string     `db:"name" json:"name"`
+	Query        string     `db:"query" json:"query"`
+	Type         *FilmType  `db:"type" json:"type,omitempty"`
+	Genre        *string    `db:"genre" json:"genre,omitempty"`
+	CreatorID    *uuid.UUID `db:"creator_id" json:"creator_id,omitempty"`
+	MinDuration  *int       `db:"min_duration" json:"min_duration,omitempty"` // seconds
+	MaxDuration  *int       `db:"max_duration" json:"max_duration,omitempty"` // seconds
+	MinRating    *float64   `db:"min_rating" json:"min_rating,omitempty"`
+	EmailAlerts  bool       `db:"email_alerts" json:"email_alerts"`
+	CheckedUntil time.Time  `db:"checked_until" json:"checked_until"` // films published later are still to be matched
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// SavedSearchMatch summarizes the newly published films matching one
+// saved search
+type SavedSearchMatch struct {
+	SavedSearchID uuid.UUID      `db:"saved_search_id"`
+	UserID        uuid.UUID      `db:"user_id"`
+	Email         string         `db:"email"`
+	Name          string         `db:"name"`
+	EmailAlerts   bool           `db:"email_alerts"`
+	MatchCount    int            `db:"match_count"`
+	FirstFilmID   uuid.UUID      `db:"first_film_id"`
+	Titles        pq.StringArray `db:"titles"` // the first few, earliest published first
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScanStatus is the outcome of a malware scan of an uploaded file
+type ScanStatus string
+
+const (
+	ScanPending  ScanStatus = "PENDING"
+	ScanClean    ScanStatus = "CLEAN"
+	ScanInfected ScanStatus = "INFECTED"
+	// ScanError means the scanner could not give a verdict
+	ScanError ScanStatus = "ERROR"
+	// ScanSkipped means no scanner is configured
+	ScanSkipped ScanStatus = "SKIPPED"
+)
+
+// ScanKind is what an uploaded file is to its film
+type ScanKind string
+
+const (
+	ScanKindSource ScanKind = "SOURCE"
+)
+
+// Scanners a worker can be configured with
+const (
+	ScannerNone       = "none"
+	ScannerClamAV     = "clamav"
+	ScannerVirusTotal = "virustotal"
+)
+
+// ScanStallTimeout is how long a queued scan may wait for a worker before
+// it is queued again, e.g. after its task was lost
+const ScanStallTimeout = 30 * time.Minute
+
+// ScanRejectedMessage is the error a film whose upload was found infected
+// fails with; it names no signature
+const ScanRejectedMessage = "The uploaded file did not pass our security scan and was removed. Please upload a different file."
+
+// ScanUnavailableMessage is the error a film fails with when its upload
+// could not be scanned after every retry
+const ScanUnavailableMessage = "The uploaded file could not be scanned for security. Please upload it again later."
+
+// FileScan is one malware scan of an uploaded file. Signature and Error
+// are for admins only.
+type FileScan struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	FilmID        uuid.UUID  `db:"film_id" json:"film_id"`
+	Kind          ScanKind   `db:"kind" json:"kind"`
+	ObjectKey     string     `db:"object_key" json:"object_key"`
+	Status        ScanStatus `db:"status" json:"status"`
+	Scanner       string     `db:"scanner" json:"scanner"`
+	SizeBytes     int64      `db:"size_bytes" json:"size_bytes"`
+	SHA256        string     `db:"sha256" json:"sha256,omitempty"`
+	Signature     string     `db:"signature" json:"signature,omitempty"`
+	Error         string     `db:"error" json:"error,omitempty"`
+	QuarantineKey *string    `db:"quarantine_key" json:"quarantine_key,omitempty"`
+	Attempts      int        `db:"attempts" json:"attempts"`
+	NextAttemptAt *time.Time `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	ScannedAt     *time.Time `db:"scanned_at" json:"scanned_at,omitempty"`
+}
+
+// QuarantinedFile is an infected upload in the admin quarantine report
+type QuarantinedFile struct {
+	FileScan
+	FilmTitle string    `db:"film_title" json:"film_title"`
+	CreatorID uuid.UUID `db:"creator_id" json:"creator_id"`
+}
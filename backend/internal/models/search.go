@@ -0,0 +1,33 @@
+package models
+
+// Search highlight delimiters. Private-use characters never occur in film
+// text, so snippets can be HTML-escaped before the delimiters become tags.
+const (
+	HighlightStart = "\uE000"
+	HighlightStop  = "\uE001"
+)
+
+// SearchBoosts controls how much recency, popularity and creator
+// verification lift a film above its text relevance. A zero boost leaves
+// ranking to relevance alone.
+type SearchBoosts struct {
+	Recency  float64 `json:"recency"`  // full lift for a film published now, halving every RecencyHalfLifeDays
+	Views    float64 `json:"views"`    // lift per tenfold increase in views
+	Verified float64 `json:"verified"` // lift for films by verified creators
+
+	RecencyHalfLifeDays int `json:"recency_half_life_days"`
+}
+
+// SearchHighlight holds HTML-escaped snippets of a search hit with the
+// matched terms wrapped in <mark> tags
+type SearchHighlight struct {
+	TitleHTML       string `db:"title_highlight" json:"title"`
+	DescriptionHTML string `db:"description_highlight" json:"description,omitempty"`
+}
+
+// SearchHit is a film found by search with its highlighted snippets
+type SearchHit struct {
+	Film
+	Score           float64 `db:"score" json:"score"`
+	SearchHighlight `json:"highlight"`
+}
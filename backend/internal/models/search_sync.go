@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchSyncState is how far a search index has been synced with Postgres
+// and the result of its latest consistency check
+type SearchSyncState struct {
+	IndexName     string          `db:"index_name" json:"index_name"`
+	EventCursor   string          `db:"event_cursor" json:"event_cursor"`
+	WatermarkAt   *time.Time      `db:"watermark_at" json:"watermark_at,omitempty"`
+	WatermarkID   *uuid.UUID      `db:"watermark_id" json:"watermark_id,omitempty"`
+	LastSyncedAt  *time.Time      `db:"last_synced_at" json:"last_synced_at,omitempty"`
+	LastCheck     json.RawMessage `db:"last_check" json:"last_check,omitempty"` // SearchConsistencyReport
+	LastCheckedAt *time.Time      `db:"last_checked_at" json:"last_checked_at,omitempty"`
+	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// FilmVersion identifies the version of a film a search document was
+// built from
+type FilmVersion struct {
+	ID        uuid.UUID `db:"id"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// SearchShardCheck compares one shard of films in Postgres and the index.
+// Films are only listed for shards whose counts or checksums differ.
+type SearchShardCheck struct {
+	Shard         int    `json:"shard"`
+	DBCount       int    `json:"db_count"`
+	IndexCount    int    `json:"index_count"`
+	DBChecksum    string `json:"db_checksum"`
+	IndexChecksum string `json:"index_checksum"`
+	// Missing films are in Postgres but not the index
+	Missing []uuid.UUID `json:"missing,omitempty"`
+	// Stale films were indexed from an older version
+	Stale []uuid.UUID `json:"stale,omitempty"`
+	// Orphaned documents are for films no longer in Postgres
+	Orphaned []uuid.UUID `json:"orphaned,omitempty"`
+}
+
+// SearchConsistencyReport is a consistency check of a search index.
+// Films updated after the watermark are still to be synced and left out.
+type SearchConsistencyReport struct {
+	Index      string     `json:"index"`
+	CheckedAt  time.Time  `json:"checked_at"`
+	Watermark  *time.Time `json:"watermark,omitempty"`
+	Consistent bool       `json:"consistent"`
+	// Divergent counts the films a repair reindexes or deletes
+	Divergent int `json:"divergent"`
+	// Unsharded counts documents indexed before shards were recorded
+	Unsharded int                `json:"unsharded"`
+	Shards    []SearchShardCheck `json:"shards"`
+}
+
+// SearchRepairResult is what a repair of a search index did
+type SearchRepairResult struct {
+	Reindexed int      `json:"reindexed"`
+	Deleted   int      `json:"deleted"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
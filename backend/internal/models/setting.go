@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SettingType represents the value type of a setting
+type SettingType string
+
+const (
+	SettingTypeInt    SettingType = "INT"
+	SettingTypeBool   SettingType = "BOOL"
+	SettingTypeString SettingType = "STRING"
+	SettingTypeJSON   SettingType = "JSON"
+)
+
+// Setting represents a runtime-tunable platform setting
+type Setting struct {
+	Key         string          `db:"key" json:"key"`
+	Type        SettingType     `db:"type" json:"type"`
+	Value       json.RawMessage `db:"value" json:"value"`
+	Description string          `db:"-" json:"description,omitempty"`
+	IsDefault   bool            `db:"-" json:"is_default"`
+	UpdatedByID *uuid.UUID      `db:"updated_by_id" json:"updated_by_id,omitempty"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time       `db:"updated_at" json:"updated_at"`
+}
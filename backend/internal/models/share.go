@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareLink is a short code that opens a film at a start offset
+type ShareLink struct {
+	Code         string    `db:"code" json:"code"`
+	FilmID       uuid.UUID `db:"film_id" json:"film_id"`
+	StartSeconds int       `db:"start_seconds" json:"t"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SourceRetentionAction is what happens to a film's uploaded source once
+// it has transcoded
+type SourceRetentionAction string
+
+const (
+	RetentionKeep    SourceRetentionAction = "keep"
+	RetentionDelete  SourceRetentionAction = "delete"
+	RetentionArchive SourceRetentionAction = "archive" // moved to infrequent-access storage
+)
+
+// Valid reports whether a is a known action
+func (a SourceRetentionAction) Valid() bool {
+	return a == RetentionKeep || a == RetentionDelete || a == RetentionArchive
+}
+
+// What the retention job did to a source
+const (
+	SourceDeleted  = "deleted"
+	SourceArchived = "archived"
+)
+
+// SourceRetentionPolicy decides what happens to sources once their film is
+// ready. Films with their own override use its action right away.
+type SourceRetentionPolicy struct {
+	Action    SourceRetentionAction `json:"action"`
+	AfterDays int                   `json:"after_days"` // days after the film became ready
+}
+
+// SourceRetentionCandidate is a ready film whose source is due for its
+// retention action
+type SourceRetentionCandidate struct {
+	FilmID       uuid.UUID             `db:"id"`
+	SourceRegion string                `db:"source_region"`
+	Action       SourceRetentionAction `db:"action"`
+}
+
+// SourceRetention is a film's retention override and what was done to its
+// source
+type SourceRetention struct {
+	FilmID      uuid.UUID              `db:"id" json:"film_id"`
+	Override    *SourceRetentionAction `db:"source_retention" json:"override"`
+	Disposition *string                `db:"source_disposition" json:"disposition"`
+	DisposedAt  *time.Time             `db:"source_disposed_at" json:"disposed_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subtitle represents a WebVTT subtitle track for a film
+type Subtitle struct {
+	ID           uuid.UUID `db:"id" json:"id"`
+	FilmID       uuid.UUID `db:"film_id" json:"film_id"`
+	Language     string    `db:"language" json:"language"`
+	Label        string    `db:"label" json:"label,omitempty"`
+	VTTURL       string    `db:"vtt_url" json:"vtt_url"`
+	SourceFormat string    `db:"source_format" json:"source_format"` // srt, ass, vtt
+	CueCount     int       `db:"cue_count" json:"cue_count"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
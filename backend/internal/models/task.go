@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskType identifies a kind of worker task beyond the main transcode
+type TaskType string
+
+const (
+	TaskBurnInSubtitles   TaskType = "BURN_IN_SUBTITLES"
+	TaskReplaceAudio      TaskType = "REPLACE_AUDIO"
+	TaskLUTPreview        TaskType = "LUT_PREVIEW"
+	TaskPressScreener     TaskType = "PRESS_SCREENER"
+	TaskPurchaseDownload  TaskType = "PURCHASE_DOWNLOAD"
+	TaskImportComments    TaskType = "IMPORT_COMMENTS"
+	TaskExportComments    TaskType = "EXPORT_COMMENTS"
+	TaskRepairHLS         TaskType = "REPAIR_HLS"
+	TaskPruneRenditions   TaskType = "PRUNE_RENDITIONS"
+	TaskRestoreRenditions TaskType = "RESTORE_RENDITIONS"
+	TaskRetranscode       TaskType = "RETRANSCODE"
+	TaskSwapRetranscode   TaskType = "SWAP_RETRANSCODE"
+	TaskQualityCheck      TaskType = "QUALITY_CHECK"
+	TaskAdminExport       TaskType = "ADMIN_EXPORT"
+	TaskScanFile          TaskType = "SCAN_FILE"
+	TaskImportURL         TaskType = "IMPORT_URL"
+	TaskImportCatalogItem TaskType = "IMPORT_CATALOG_ITEM"
+	TaskClassifyContent   TaskType = "CLASSIFY_CONTENT"
+)
+
+// TaskStatus represents the state of a worker task
+type TaskStatus string
+
+const (
+	TaskQueued    TaskStatus = "QUEUED"
+	TaskRunning   TaskStatus = "RUNNING"
+	TaskCompleted TaskStatus = "COMPLETED"
+	TaskFailed    TaskStatus = "FAILED"
+)
+
+// WorkerTask is a unit of work queued for the worker
+type WorkerTask struct {
+	ID          uuid.UUID         `json:"id"`
+	Type        TaskType          `json:"type"`
+	FilmID      uuid.UUID         `json:"film_id"`
+	Region      string            `json:"region,omitempty"` // source region of the film, when it has one
+	Params      map[string]string `json:"params,omitempty"`
+	Status      TaskStatus        `json:"status"`
+	Error       string            `json:"error,omitempty"`
+	Result      map[string]string `json:"result,omitempty"`
+	RequestedBy uuid.UUID         `json:"requested_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// WorkerRegion reports the workers and queued work of one region
+type WorkerRegion struct {
+	Region      string `json:"region"`
+	LiveWorkers int64  `json:"live_workers"`
+	QueuedJobs  int64  `json:"queued_jobs"`
+	QueuedTasks int64  `json:"queued_tasks"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MarkerKind is the kind of a point or range on a film's timeline
+type MarkerKind string
+
+const (
+	MarkerChapter MarkerKind = "CHAPTER"
+	MarkerAd      MarkerKind = "AD"
+	MarkerIntro   MarkerKind = "INTRO"
+	MarkerCredits MarkerKind = "CREDITS"
+)
+
+// FilmMarker is a chapter, ad break, intro or credits marker set by the
+// film's creator. EndSeconds is nil for point markers.
+type FilmMarker struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	FilmID       uuid.UUID  `db:"film_id" json:"film_id"`
+	Kind         MarkerKind `db:"kind" json:"kind"`
+	Title        string     `db:"title" json:"title,omitempty"`
+	StartSeconds float64    `db:"start_seconds" json:"start"`
+	EndSeconds   *float64   `db:"end_seconds" json:"end,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+// FilmSprite is a film's thumbnail sprite sheet. Frame i covers
+// i*IntervalSeconds and sits at column i%Columns, row i/Columns.
+type FilmSprite struct {
+	FilmID          uuid.UUID `db:"film_id" json:"-"`
+	URL             string    `db:"url" json:"url"`
+	IntervalSeconds float64   `db:"interval_seconds" json:"interval"`
+	FrameCount      int       `db:"frame_count" json:"frame_count"`
+	Columns         int       `db:"columns" json:"columns"`
+	Rows            int       `db:"rows" json:"rows"`
+	TileWidth       int       `db:"tile_width" json:"tile_width"`
+	TileHeight      int       `db:"tile_height" json:"tile_height"`
+	CreatedAt       time.Time `db:"created_at" json:"-"`
+}
+
+// TimelineRange is a span of a film in seconds
+type TimelineRange struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// TimelineChapter is a chapter running until the next one or the end
+type TimelineChapter struct {
+	Title string  `json:"title"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// TimelineAdMarker is an ad break; Duration is 0 for a point break
+type TimelineAdMarker struct {
+	At       float64 `json:"at"`
+	Duration float64 `json:"duration"`
+}
+
+// CueDensity counts the subtitle cues starting in each bucket of a track
+type CueDensity struct {
+	Language string `json:"language"`
+	Counts   []int  `json:"counts"`
+}
+
+// Timeline is the single document editing and review UIs read to draw a
+// film's timeline
+type Timeline struct {
+	FilmID    uuid.UUID          `json:"film_id"`
+	Duration  int                `json:"duration"`
+	Chapters  []TimelineChapter  `json:"chapters"`
+	AdMarkers []TimelineAdMarker `json:"ad_markers"`
+	Intro     *TimelineRange     `json:"intro,omitempty"`
+	Credits   *TimelineRange     `json:"credits,omitempty"`
+	// BucketSeconds is the width of each cue density bucket
+	BucketSeconds float64      `json:"bucket_seconds"`
+	CueDensity    []CueDensity `json:"cue_density"`
+	Sprite        *FilmSprite  `json:"sprite,omitempty"`
+	GeneratedAt   time.Time    `json:"generated_at"`
+}
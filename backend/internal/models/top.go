@@ -0,0 +1,23 @@
+package models
+
+// TopWindow names a "most watched" aggregation window
+type TopWindow string
+
+const (
+	TopWindowDay   TopWindow = "day"   // today so far
+	TopWindowWeek  TopWindow = "week"  // today and the previous 6 days
+	TopWindowMonth TopWindow = "month" // today and the previous 29 days
+)
+
+// TopWindowDays maps each window to the calendar days it covers
+var TopWindowDays = map[TopWindow]int{
+	TopWindowDay:   1,
+	TopWindowWeek:  7,
+	TopWindowMonth: 30,
+}
+
+// TopFilm is a film ranked by views within a window
+type TopFilm struct {
+	Film
+	WindowViews int64 `db:"window_views" json:"window_views"`
+}
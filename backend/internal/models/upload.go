@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadProgress represents byte-level progress of a multipart upload
+type UploadProgress struct {
+	FilmID         uuid.UUID `json:"film_id"`
+	TotalBytes     int64     `json:"total_bytes"`
+	UploadedBytes  int64     `json:"uploaded_bytes"`
+	TotalParts     int       `json:"total_parts"`
+	CompletedParts int       `json:"completed_parts"`
+	Percent        float64   `json:"percent"`
+}
+
+// FilmStatusUpdate is a change of a film's status, or of its transcode
+// progress, pushed to status stream subscribers
+type FilmStatusUpdate struct {
+	FilmID   uuid.UUID  `json:"film_id"`
+	Status   FilmStatus `json:"status"`
+	Progress *int       `json:"progress,omitempty"` // transcode progress, 0-100
+}
+
+// MultipartUpload is a film's unfinished multipart upload of its source.
+// Parts are uploaded to pre-signed URLs and assembled on completion.
+type MultipartUpload struct {
+	FilmID     uuid.UUID `json:"film_id"`
+	UploadID   string    `json:"upload_id"`
+	Key        string    `json:"key"`
+	Region     string    `json:"region,omitempty"`
+	FileSize   int64     `json:"file_size"`
+	PartSize   int64     `json:"part_size"`
+	TotalParts int       `json:"total_parts"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// PartLength returns the size of a part: the part size for all but the
+// last, which holds the rest of the file
+func (u *MultipartUpload) PartLength(partNumber int) int64 {
+	if partNumber < u.TotalParts {
+		return u.PartSize
+	}
+	return u.FileSize - int64(u.TotalParts-1)*u.PartSize
+}
+
+// UploadMilestone is a lifecycle stage an upload reaches on its way to a
+// ready film, in order
+type UploadMilestone int
+
+const (
+	UploadStarted   UploadMilestone = iota // upload URL issued
+	UploadConfirmed                        // creator confirmed the upload
+	UploadQueued                           // transcode job queued
+	EncodeStarted                          // worker picked the job up
+	UploadReady                            // film is ready to play
+)
+
+// Upload latency stages, each the time between two milestones
+const (
+	LatencyUpload = "upload" // started to confirmed
+	LatencyQueue  = "queue"  // queued to encode started
+	LatencyEncode = "encode" // encode started to ready
+	LatencyTotal  = "total"  // started to ready
+)
+
+// LatencyStages lists the upload latency stages in lifecycle order
+var LatencyStages = []string{LatencyUpload, LatencyQueue, LatencyEncode, LatencyTotal}
+
+// UploadLatency summarises how long one stage took for the films that
+// became ready in a period
+type UploadLatency struct {
+	Stage      string  `db:"stage" json:"stage"`
+	Films      int     `db:"films" json:"films"`
+	AvgSeconds float64 `db:"avg_seconds" json:"avg_seconds"`
+	P50Seconds float64 `db:"p50_seconds" json:"p50_seconds"`
+	P90Seconds float64 `db:"p90_seconds" json:"p90_seconds"`
+	P99Seconds float64 `db:"p99_seconds" json:"p99_seconds"`
+	SumSeconds float64 `db:"sum_seconds" json:"-"`
+}
+
+// UploadLatencyRollup is the upload latency of one stage for films that
+// became ready on a day
+type UploadLatencyRollup struct {
+	Day time.Time `db:"day" json:"day"`
+	UploadLatency
+}
+
+// AbandonedFilm is a draft or uploaded film whose upload was never
+// confirmed
+type AbandonedFilm struct {
+	ID           uuid.UUID  `db:"id"`
+	Status       FilmStatus `db:"status"`
+	SourceRegion string     `db:"source_region"`
+}
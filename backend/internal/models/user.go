@@ -12,6 +12,7 @@ type UserRole string
 const (
 	RoleUser    UserRole = "USER"
 	RoleCreator UserRole = "CREATOR"
+	RolePress   UserRole = "PRESS"
 	RoleAdmin  UserRole = "ADMIN"
 )
 
@@ -22,8 +23,17 @@ type User struct {
 	PasswordHash string `db:"password_hash" json:"-"`
 	Role      UserRole  `db:"role" json:"role"`
 	Name      string    `db:"name" json:"name"`
+	Handle    *string   `db:"handle" json:"handle,omitempty"` // @handle others mention the user by
 	AvatarURL string   `db:"avatar_url" json:"avatar_url,omitempty"`
 	Bio       string    `db:"bio" json:"bio,omitempty"`
+	Verified  bool      `db:"verified" json:"verified"`
+	BirthDate *time.Time `db:"birth_date" json:"birth_date,omitempty"` // for age-gated films
+	// GhostOwnerID is set on ghost users: comment authors imported by this
+	// creator from another platform, who cannot sign in
+	GhostOwnerID *uuid.UUID `db:"ghost_owner_id" json:"-"`
+	// BannedAt is set once a moderator banned the user; banned users
+	// cannot sign in
+	BannedAt  *time.Time `db:"banned_at" json:"banned_at,omitempty"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// WatchlistFilm is a film a user saved for later
+type WatchlistFilm struct {
+	Film
+	AddedAt time.Time `db:"added_at" json:"added_at"`
+}
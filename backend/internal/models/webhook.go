@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// WebhookEndpointStatus is whether an endpoint is sent events
+type WebhookEndpointStatus string
+
+const (
+	WebhookEndpointActive WebhookEndpointStatus = "active"
+	// WebhookEndpointDisabled endpoints failed for too long; their
+	// deliveries wait until they are re-enabled
+	WebhookEndpointDisabled WebhookEndpointStatus = "disabled"
+)
+
+// WebhookDeliveryStatus is where a delivery stands
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // out of retries
+)
+
+// WebhookPing is the event type of the test delivery sent when an
+// endpoint is re-enabled
+const WebhookPing = "webhook.ping"
+
+// WebhookEndpoint receives film lifecycle events over HTTP. Its secret
+// signs every delivery and is only shown when the endpoint is created.
+type WebhookEndpoint struct {
+	ID                  uuid.UUID             `db:"id" json:"id"`
+	UserID              uuid.UUID             `db:"user_id" json:"user_id"`
+	URL                 string                `db:"url" json:"url"`
+	Secret              string                `db:"secret" json:"-"`
+	EventTypes          pq.StringArray        `db:"event_types" json:"event_types"` // empty = all
+	AllFilms            bool                  `db:"all_films" json:"all_films"`
+	Status              WebhookEndpointStatus `db:"status" json:"status"`
+	ConsecutiveFailures int                   `db:"consecutive_failures" json:"consecutive_failures"`
+	FailingSince        *time.Time            `db:"failing_since" json:"failing_since,omitempty"`
+	LastSuccessAt       *time.Time            `db:"last_success_at" json:"last_success_at,omitempty"`
+	LastFailureAt       *time.Time            `db:"last_failure_at" json:"last_failure_at,omitempty"`
+	DisabledAt          *time.Time            `db:"disabled_at" json:"disabled_at,omitempty"`
+	DisabledReason      *string               `db:"disabled_reason" json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time             `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time             `db:"updated_at" json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one endpoint
+type WebhookDelivery struct {
+	ID            uuid.UUID             `db:"id" json:"id"`
+	EndpointID    uuid.UUID             `db:"endpoint_id" json:"endpoint_id"`
+	EventID       uuid.UUID             `db:"event_id" json:"event_id"`
+	EventType     string                `db:"event_type" json:"event_type"`
+	Payload       string                `db:"payload" json:"-"`
+	Status        WebhookDeliveryStatus `db:"status" json:"status"`
+	Attempts      int                   `db:"attempts" json:"attempts"`
+	NextAttemptAt time.Time             `db:"next_attempt_at" json:"next_attempt_at"`
+	ReplayOf      *uuid.UUID            `db:"replay_of" json:"replay_of,omitempty"`
+	CreatedAt     time.Time             `db:"created_at" json:"created_at"`
+	FinishedAt    *time.Time            `db:"finished_at" json:"finished_at,omitempty"`
+}
+
+// WebhookAttempt is one HTTP request made for a delivery. StatusCode is
+// nil when no response came back.
+type WebhookAttempt struct {
+	ID           int64     `db:"id" json:"id"`
+	DeliveryID   uuid.UUID `db:"delivery_id" json:"delivery_id"`
+	EndpointID   uuid.UUID `db:"endpoint_id" json:"endpoint_id"`
+	AttemptedAt  time.Time `db:"attempted_at" json:"attempted_at"`
+	SignedAt     int64     `db:"signed_at" json:"signed_at"`
+	Signature    string    `db:"signature" json:"signature"`
+	StatusCode   *int      `db:"status_code" json:"status_code,omitempty"`
+	Error        *string   `db:"error" json:"error,omitempty"`
+	ResponseBody *string   `db:"response_body" json:"response_body,omitempty"`
+	DurationMs   int       `db:"duration_ms" json:"duration_ms"`
+}
+
+// Succeeded reports whether the endpoint accepted the attempt
+func (a *WebhookAttempt) Succeeded() bool {
+	return a.StatusCode != nil && *a.StatusCode >= 200 && *a.StatusCode < 300
+}
+
+// WebhookHealth summarizes an endpoint's recent deliveries
+type WebhookHealth struct {
+	Attempts      int64   `db:"attempts" json:"attempts"`
+	Successes     int64   `db:"successes" json:"successes"`
+	AvgDurationMs float64 `db:"avg_duration_ms" json:"avg_duration_ms"`
+	Pending       int64   `db:"pending" json:"pending"`
+	Failed        int64   `db:"failed" json:"failed"`
+}
@@ -0,0 +1,258 @@
+// Package moderation carries out the actions admins take on reported films
+// and comments from the moderation queue. Every action resolves the
+// target's open reports and is written to the moderation audit log.
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/auth"
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/arjunaayasa/filmtube/internal/search"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidAction is returned for unknown actions and target types,
+	// and for age restricting a comment
+	ErrInvalidAction = errors.New("invalid moderation action")
+	// ErrTargetNotFound is returned when the film or comment does not exist
+	ErrTargetNotFound = errors.New("moderation target not found")
+	// ErrProtectedUser is returned for attempts to ban an admin or the
+	// ghost account
+	ErrProtectedUser = errors.New("this user cannot be banned")
+)
+
+// Action is an action taken on a reported film or comment
+type Action struct {
+	AdminID    uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   uuid.UUID
+	Reason     string
+}
+
+// Queue applies moderation queue actions
+type Queue struct {
+	queries  *db.Queries
+	indexer  *search.Indexer
+	settings *settings.Service
+}
+
+// NewQueue creates a moderation queue
+func NewQueue(queries *db.Queries, indexer *search.Indexer, settingsService *settings.Service) *Queue {
+	return &Queue{queries: queries, indexer: indexer, settings: settingsService}
+}
+
+// target is the film or comment an action is taken on
+type target struct {
+	film    *models.Film
+	comment *models.Comment
+	ownerID uuid.UUID
+}
+
+// Apply carries out an action and records it in the audit log
+func (q *Queue) Apply(ctx context.Context, a Action) (*models.ModerationAuditEntry, error) {
+	if !validAction(a.Action) {
+		return nil, fmt.Errorf("%w: action must be one of %s", ErrInvalidAction, strings.Join(models.ModerationQueueActions, ", "))
+	}
+	t, err := q.load(ctx, a.TargetType, a.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved int64
+	switch a.Action {
+	case models.ModerationDismiss:
+		resolved, err = q.resolve(ctx, t, a.AdminID)
+	case models.ModerationAgeRestrict:
+		if t.comment != nil {
+			return nil, fmt.Errorf("%w: only films can be age restricted", ErrInvalidAction)
+		}
+		if err = q.ageRestrict(ctx, t.film); err == nil {
+			resolved, err = q.resolve(ctx, t, a.AdminID)
+		}
+	case models.ModerationUnpublish:
+		resolved, err = q.takeDown(ctx, t, a.AdminID)
+	case models.ModerationBanCreator:
+		if err = q.ban(ctx, t.ownerID); err == nil {
+			resolved, err = q.takeDown(ctx, t, a.AdminID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.ModerationAuditEntry{
+		AdminID:         &a.AdminID,
+		Action:          a.Action,
+		TargetType:      a.TargetType,
+		TargetID:        a.TargetID,
+		FilmID:          t.film.ID,
+		SubjectUserID:   t.ownerID,
+		ReportsResolved: int(resolved),
+		Reason:          strings.TrimSpace(a.Reason),
+	}
+	if err := q.queries.RecordModerationAudit(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return entry, nil
+}
+
+// AgeRestrictFilm raises a film's age gate without an admin, as content
+// classification does, and records it in the audit log with the reason.
+// The film's reports are left open for moderators.
+func (q *Queue) AgeRestrictFilm(ctx context.Context, filmID uuid.UUID, reason string) (*models.ModerationAuditEntry, error) {
+	t, err := q.load(ctx, models.ReportTargetFilm, filmID)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.ageRestrict(ctx, t.film); err != nil {
+		return nil, err
+	}
+
+	entry := &models.ModerationAuditEntry{
+		Action:        models.ModerationAgeRestrict,
+		TargetType:    models.ReportTargetFilm,
+		TargetID:      filmID,
+		FilmID:        filmID,
+		SubjectUserID: t.ownerID,
+		Reason:        reason,
+	}
+	if err := q.queries.RecordModerationAudit(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return entry, nil
+}
+
+// load looks up a film or comment with its film and owner
+func (q *Queue) load(ctx context.Context, targetType string, id uuid.UUID) (*target, error) {
+	t := &target{}
+	filmID := id
+	switch targetType {
+	case models.ReportTargetFilm:
+	case models.ReportTargetComment:
+		comment, err := q.queries.GetComment(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTargetNotFound
+		} else if err != nil {
+			return nil, err
+		}
+		t.comment = comment
+		t.ownerID = comment.UserID
+		filmID = comment.FilmID
+	default:
+		return nil, fmt.Errorf("%w: target_type must be film or comment", ErrInvalidAction)
+	}
+
+	film, err := q.queries.GetFilmByID(ctx, filmID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTargetNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	t.film = film
+	if t.comment == nil {
+		t.ownerID = film.CreatedByID
+	}
+	return t, nil
+}
+
+// resolve closes the target's open reports and returns how many there were
+func (q *Queue) resolve(ctx context.Context, t *target, adminID uuid.UUID) (int64, error) {
+	if t.comment != nil {
+		return q.queries.DismissCommentReports(ctx, t.comment.ID)
+	}
+	return q.queries.ResolveFilmReports(ctx, t.film.ID, adminID)
+}
+
+// takeDown unpublishes a film or hides a comment, resolving its reports
+func (q *Queue) takeDown(ctx context.Context, t *target, adminID uuid.UUID) (int64, error) {
+	resolved, err := q.resolve(ctx, t, adminID)
+	if err != nil {
+		return 0, err
+	}
+	if t.comment != nil {
+		if _, err := q.queries.ModerateComment(ctx, t.comment.ID, adminID, models.CommentHidden); err != nil {
+			return 0, fmt.Errorf("failed to hide comment: %w", err)
+		}
+		return resolved, nil
+	}
+	return resolved, q.unpublish(ctx, t.film)
+}
+
+// ageRestrict raises a film's age gate to the configured minimum age. A
+// film already gated higher keeps its own.
+func (q *Queue) ageRestrict(ctx context.Context, film *models.Film) error {
+	p := policy.For(film).Policy()
+	if minAge := int(q.settings.Int(ctx, settings.KeyModerationMinAge)); p.MinAge < minAge {
+		p.MinAge = minAge
+	}
+	return q.savePolicy(ctx, film, &p)
+}
+
+// unpublish takes a film out of the catalog and makes it private, so only
+// its creator and admins can still watch it
+func (q *Queue) unpublish(ctx context.Context, film *models.Film) error {
+	p := policy.For(film).Policy()
+	p.Visibility = models.VisibilityPrivate
+	if err := q.queries.UnpublishFilm(ctx, film.ID); err != nil {
+		return fmt.Errorf("failed to unpublish film: %w", err)
+	}
+	return q.savePolicy(ctx, film, &p)
+}
+
+// ban bans a user and unpublishes all their films
+func (q *Queue) ban(ctx context.Context, userID uuid.UUID) error {
+	user, err := q.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load user: %w", err)
+	}
+	if auth.IsAdmin(user.Role) || user.GhostOwnerID != nil || user.ID == models.GhostAccountID {
+		return ErrProtectedUser
+	}
+
+	if err := q.queries.BanUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to ban user: %w", err)
+	}
+	films, err := q.queries.ListFilmsByOwner(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list films: %w", err)
+	}
+	for i := range films {
+		if err := q.unpublish(ctx, &films[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// savePolicy stores a film's policy and reindexes the film, whose
+// visibility and publication search filters on
+func (q *Queue) savePolicy(ctx context.Context, film *models.Film, p *models.FilmPolicy) error {
+	if err := policy.Validate(p); err != nil {
+		return err
+	}
+	if err := q.queries.UpdateFilmPolicy(ctx, film.ID, p); err != nil {
+		return fmt.Errorf("failed to update film policy: %w", err)
+	}
+	q.indexer.SyncFilmAsync(film.ID)
+	return nil
+}
+
+// validAction reports whether action is a moderation queue action
+func validAction(action string) bool {
+	for _, a := range models.ModerationQueueActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Page is the response envelope shared by all list endpoints
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Limit int `json:"limit"`
+	// Offset pagination
+	Page       int `json:"page,omitempty"`
+	TotalPages int `json:"total_pages,omitempty"`
+	// Total may come from a cache or planner estimate, see TotalEstimated
+	Total          int  `json:"total"`
+	TotalEstimated bool `json:"total_estimated"`
+	HasMore        bool `json:"has_more"`
+	// Cursor pagination
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Offset holds parsed page/limit parameters
+type Offset struct {
+	Page   int
+	Limit  int
+	Offset int
+}
+
+// ParseOffset reads ?page= and ?limit= with the shared defaults and bounds
+func ParseOffset(c *gin.Context) Offset {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit := ParseLimit(c)
+
+	if page < 1 {
+		page = 1
+	}
+
+	return Offset{
+		Page:   page,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	}
+}
+
+// ParseLimit reads ?limit= with the shared default and bounds
+func ParseLimit(c *gin.Context) int {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DefaultLimit)))
+	if limit < 1 || limit > MaxLimit {
+		limit = DefaultLimit
+	}
+	return limit
+}
+
+// NewOffsetPage builds an envelope for an offset-paginated listing
+func NewOffsetPage[T any](items []T, params Offset, total int, estimated bool) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	totalPages := (total + params.Limit - 1) / params.Limit
+
+	return Page[T]{
+		Items:          items,
+		Limit:          params.Limit,
+		Page:           params.Page,
+		TotalPages:     totalPages,
+		Total:          total,
+		TotalEstimated: estimated,
+		HasMore:        params.Page < totalPages,
+	}
+}
+
+// NewCursorPage builds an envelope for a keyset-paginated listing. Callers
+// fetch limit+1 rows; when the extra row exists, next is called with the
+// last item of the page to produce the cursor for the following page.
+func NewCursorPage[T any](items []T, limit int, total int, estimated bool, next func(last T) string) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	page := Page[T]{
+		Limit:          limit,
+		Total:          total,
+		TotalEstimated: estimated,
+	}
+
+	if len(items) > limit {
+		items = items[:limit]
+		page.HasMore = true
+		page.NextCursor = next(items[len(items)-1])
+	}
+	page.Items = items
+
+	return page
+}
+
+// EncodeCursor serializes a keyset position into an opaque cursor string
+func EncodeCursor(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor into v
+func DecodeCursor(cursor string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
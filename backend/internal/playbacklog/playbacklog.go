@@ -0,0 +1,146 @@
+// Package playbacklog keeps short-lived structured logs of playback
+// requests, so support can tell why a viewer could not play a film. Logs
+// hold no email, token or full IP address.
+package playbacklog
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/google/uuid"
+)
+
+// maxUserAgent bounds the stored user agent
+const maxUserAgent = 256
+
+// Logger records playback sessions and deletes them once past retention
+type Logger struct {
+	queries  *db.Queries
+	settings *settings.Service
+}
+
+// New creates a playback logger
+func New(queries *db.Queries, settingsService *settings.Service) *Logger {
+	return &Logger{
+		queries:  queries,
+		settings: settingsService,
+	}
+}
+
+// Session collects the steps of one playback request until it is saved
+type Session struct {
+	// ID groups the steps; set it to the playback session ID once issued
+	ID uuid.UUID
+
+	filmID    uuid.UUID
+	userID    *uuid.UUID
+	endpoint  string
+	country   string
+	ipPrefix  *string
+	userAgent *string
+	entries   []models.PlaybackLogEntry
+}
+
+// Start begins logging a playback request. The IP address is reduced to
+// its /24 (IPv4) or /48 (IPv6) network before it is kept.
+func (l *Logger) Start(filmID uuid.UUID, userID *uuid.UUID, endpoint, country, ip, userAgent string) *Session {
+	s := &Session{
+		ID:       uuid.New(),
+		filmID:   filmID,
+		userID:   userID,
+		endpoint: endpoint,
+		country:  country,
+	}
+	if prefix := RedactIP(ip); prefix != "" {
+		s.ipPrefix = &prefix
+	}
+	if userAgent != "" {
+		if len(userAgent) > maxUserAgent {
+			userAgent = userAgent[:maxUserAgent]
+		}
+		s.userAgent = &userAgent
+	}
+	return s
+}
+
+// Record adds a step. details must not carry personal data.
+func (s *Session) Record(stage models.PlaybackStage, outcome models.PlaybackOutcome, reason string, details map[string]interface{}) {
+	data := json.RawMessage(`{}`)
+	if len(details) > 0 {
+		if encoded, err := json.Marshal(details); err == nil {
+			data = encoded
+		}
+	}
+	s.entries = append(s.entries, models.PlaybackLogEntry{
+		Stage:     stage,
+		Outcome:   outcome,
+		Reason:    reason,
+		Details:   data,
+		CreatedAt: time.Now(),
+	})
+}
+
+// Save stores a session's steps in the background. It is best effort:
+// playback never waits on or fails because of its log.
+func (l *Logger) Save(s *Session) {
+	entries := s.entries
+	for i := range entries {
+		entries[i].SessionID = s.ID
+		entries[i].FilmID = s.filmID
+		entries[i].UserID = s.userID
+		entries[i].Endpoint = s.endpoint
+		entries[i].Country = s.country
+		entries[i].IPPrefix = s.ipPrefix
+		entries[i].UserAgent = s.userAgent
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := l.queries.InsertPlaybackLogs(ctx, entries); err != nil {
+			log.Printf("[PlaybackLog] Failed to save session %s: %v", s.ID, err)
+		}
+	}()
+}
+
+// RedactIP returns the network prefix of an IP address, or "" if it does
+// not parse
+func RedactIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// RunRetentionLoop deletes logs past the support.playback_log_retention_hours
+// setting on every interval. It blocks until ctx is cancelled.
+func (l *Logger) RunRetentionLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			hours := l.settings.Int(ctx, settings.KeyPlaybackLogHours)
+			cutoff := time.Now().Add(-time.Duration(hours) * time.Hour)
+			n, err := l.queries.DeletePlaybackLogsBefore(ctx, cutoff)
+			if err != nil {
+				log.Printf("[PlaybackLog] Failed to delete expired logs: %v", err)
+			} else if n > 0 {
+				log.Printf("[PlaybackLog] Deleted %d expired log entries", n)
+			}
+		}
+	}
+}
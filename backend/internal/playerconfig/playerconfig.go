@@ -0,0 +1,286 @@
+package playerconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// TTL is how long clients may cache a resolved configuration
+const TTL = 5 * time.Minute
+
+// Default is the player configuration before any rule applies
+var Default = models.PlayerConfig{
+	ABR: models.PlayerABR{StartupHeight: 480},
+	Retry: models.PlayerRetry{
+		MaxAttempts:  5,
+		BackoffMs:    500,
+		MaxBackoffMs: 8000,
+	},
+	Features: map[string]bool{},
+}
+
+// ErrInvalidRule is wrapped by ValidateRule errors
+var ErrInvalidRule = errors.New("invalid player config rule")
+
+var versionPattern = regexp.MustCompile(`^\d+(\.\d+){0,3}$`)
+
+// ValidateRule checks a rule and trims its name, and that its patch
+// applied to the default configuration gives a valid one
+func ValidateRule(rule *models.PlayerConfigRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" || len(rule.Name) > 200 {
+		return fmt.Errorf("%w: name must be 1-200 characters", ErrInvalidRule)
+	}
+	if rule.Platform != nil && !slices.Contains(models.PlayerPlatforms, *rule.Platform) {
+		return fmt.Errorf("%w: platform must be one of %s", ErrInvalidRule, strings.Join(models.PlayerPlatforms, ", "))
+	}
+	for _, v := range []string{rule.MinAppVersion, rule.MaxAppVersion} {
+		if v != "" && !versionPattern.MatchString(v) {
+			return fmt.Errorf("%w: app versions must look like 3.2.1", ErrInvalidRule)
+		}
+	}
+	if rule.MinAppVersion != "" && rule.MaxAppVersion != "" && CompareVersions(rule.MinAppVersion, rule.MaxAppVersion) > 0 {
+		return fmt.Errorf("%w: min_app_version is after max_app_version", ErrInvalidRule)
+	}
+	if rule.RolloutPercent < 0 || rule.RolloutPercent > 100 {
+		return fmt.Errorf("%w: rollout_percent must be 0-100", ErrInvalidRule)
+	}
+
+	if len(rule.Patch) == 0 {
+		rule.Patch = json.RawMessage(`{}`)
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal(rule.Patch, &patch); err != nil || patch == nil {
+		return fmt.Errorf("%w: patch must be a JSON object", ErrInvalidRule)
+	}
+	if _, err := apply(Default, rule.Patch); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return nil
+}
+
+// CompareVersions compares dotted app versions numerically, missing parts
+// counting as 0, and returns -1, 0 or 1
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x = leadingInt(as[i])
+		}
+		if i < len(bs) {
+			y = leadingInt(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// leadingInt parses the digits a version part starts with, so 2-beta is 2
+func leadingInt(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+// Service resolves player configuration from rules it keeps in memory,
+// reloading them on an interval so every instance serves the same ones
+type Service struct {
+	queries *db.Queries
+
+	mu    sync.RWMutex
+	rules []models.PlayerConfigRule
+}
+
+// New creates a player configuration service
+func New(queries *db.Queries) *Service {
+	return &Service{queries: queries}
+}
+
+// RunSync loads the rules now and again on every interval until ctx is
+// cancelled
+func (s *Service) RunSync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Reload(ctx); err != nil {
+			log.Printf("[Player] Failed to load config rules: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reload loads the enabled rules, after a change made through this instance
+func (s *Service) Reload(ctx context.Context) error {
+	rules, err := s.queries.ListEnabledPlayerConfigRules(ctx)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = rules
+	return nil
+}
+
+// Resolve returns the configuration for a client. subject, a device or
+// user ID, places the client in staged rollouts; without one only rules
+// rolled out to every device apply.
+func (s *Service) Resolve(platform, appVersion, subject string) *models.ResolvedPlayerConfig {
+	s.mu.RLock()
+	rules := s.rules
+	s.mu.RUnlock()
+
+	resolved := &models.ResolvedPlayerConfig{
+		Platform:   platform,
+		AppVersion: appVersion,
+		Config:     Default,
+		Rules:      []uuid.UUID{},
+		TTLSeconds: int(TTL.Seconds()),
+	}
+	for _, rule := range rules {
+		if !Matches(&rule, platform, appVersion, subject) {
+			continue
+		}
+		config, err := apply(resolved.Config, rule.Patch)
+		if err != nil {
+			log.Printf("[Player] Skipping config rule %s: %v", rule.ID, err)
+			continue
+		}
+		resolved.Config = *config
+		resolved.Rules = append(resolved.Rules, rule.ID)
+	}
+	return resolved
+}
+
+// Matches reports whether a rule applies to a client. Rules bounded by
+// app version skip clients that don't send one.
+func Matches(rule *models.PlayerConfigRule, platform, appVersion, subject string) bool {
+	if !rule.Enabled || rule.Platform != nil && *rule.Platform != platform {
+		return false
+	}
+	if rule.MinAppVersion != "" || rule.MaxAppVersion != "" {
+		if appVersion == "" {
+			return false
+		}
+		if rule.MinAppVersion != "" && CompareVersions(appVersion, rule.MinAppVersion) < 0 {
+			return false
+		}
+		if rule.MaxAppVersion != "" && CompareVersions(appVersion, rule.MaxAppVersion) > 0 {
+			return false
+		}
+	}
+	return InRollout(rule.ID, subject, rule.RolloutPercent)
+}
+
+// InRollout reports whether subject falls in the first percent of a rule's
+// buckets. Buckets are salted by rule, so separate rollouts reach
+// different devices, and raising percent only adds devices.
+func InRollout(ruleID uuid.UUID, subject string, percent int) bool {
+	if percent >= 100 {
+		return true
+	}
+	if percent <= 0 || subject == "" {
+		return false
+	}
+	h := fnv.New32a()
+	h.Write(ruleID[:])
+	h.Write([]byte(subject))
+	return int(h.Sum32()%100) < percent
+}
+
+// apply merges a patch onto a configuration (RFC 7386) and checks the
+// result
+func apply(config models.PlayerConfig, patch json.RawMessage) (*models.PlayerConfig, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var doc, p interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	merged, err := json.Marshal(mergePatch(doc, p))
+	if err != nil {
+		return nil, err
+	}
+
+	var out models.PlayerConfig
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("patch does not fit the player config: %v", err)
+	}
+	if out.Features == nil {
+		out.Features = map[string]bool{}
+	}
+	return &out, check(&out)
+}
+
+// check rejects configurations no player should be sent
+func check(c *models.PlayerConfig) error {
+	if c.ABR.MaxHeight < 0 || c.ABR.MaxBitrateKbps < 0 || c.ABR.StartupHeight < 0 {
+		return errors.New("abr values must not be negative")
+	}
+	if c.ABR.MaxHeight > 0 && c.ABR.StartupHeight > c.ABR.MaxHeight {
+		return errors.New("abr startup_height is above max_height")
+	}
+	if c.Retry.MaxAttempts < 0 || c.Retry.MaxAttempts > 20 {
+		return errors.New("retry max_attempts must be 0-20")
+	}
+	if c.Retry.BackoffMs < 0 || c.Retry.MaxBackoffMs < c.Retry.BackoffMs || c.Retry.MaxBackoffMs > 300000 {
+		return errors.New("retry backoff_ms must be at most max_backoff_ms, which is at most 300000")
+	}
+	return nil
+}
+
+// mergePatch applies an RFC 7386 JSON merge patch: objects merge, null
+// removes a member and anything else replaces the target
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = mergePatch(t[key], value)
+	}
+	return t
+}
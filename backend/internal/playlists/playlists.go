@@ -0,0 +1,182 @@
+package playlists
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/policy"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxItems bounds how many films a playlist holds or a smart playlist
+	// evaluates to
+	MaxItems = 200
+
+	// DefaultSmartLimit is how many films a smart playlist holds when its
+	// rules set no limit
+	DefaultSmartLimit = 50
+
+	// CacheTTL is how long a smart playlist's evaluated films are cached, so
+	// newly published films show up within this time
+	CacheTTL = 5 * time.Minute
+
+	maxRuleTags = 10
+)
+
+// ErrInvalidRules is wrapped by ParseRules errors
+var ErrInvalidRules = errors.New("invalid playlist rules")
+
+// ParseRules decodes and checks smart playlist rules
+func ParseRules(raw json.RawMessage) (*models.PlaylistRules, error) {
+	var rules models.PlaylistRules
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+
+	switch {
+	case rules.OwnFilms && rules.CreatorID != nil:
+		return nil, fmt.Errorf("%w: own_films and creator_id cannot be combined", ErrInvalidRules)
+	case len(rules.Tags) > maxRuleTags:
+		return nil, fmt.Errorf("%w: at most %d tags", ErrInvalidRules, maxRuleTags)
+	case rules.Type != "" && rules.Type != models.FilmTypeShortFilm && rules.Type != models.FilmTypeFeatureFilm:
+		return nil, fmt.Errorf("%w: type must be SHORT_FILM or FEATURE_FILM", ErrInvalidRules)
+	case rules.MinDuration != nil && *rules.MinDuration < 0, rules.MaxDuration != nil && *rules.MaxDuration < 0:
+		return nil, fmt.Errorf("%w: durations must not be negative", ErrInvalidRules)
+	case rules.MinDuration != nil && rules.MaxDuration != nil && *rules.MinDuration > *rules.MaxDuration:
+		return nil, fmt.Errorf("%w: min_duration must not exceed max_duration", ErrInvalidRules)
+	case rules.MinRating != nil && (*rules.MinRating < 0 || *rules.MinRating > 5):
+		return nil, fmt.Errorf("%w: min_rating must be between 0 and 5", ErrInvalidRules)
+	case rules.Limit < 0 || rules.Limit > MaxItems:
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidRules, MaxItems)
+	}
+	if rules.Sort != "" {
+		if _, ok := db.FilmSortOrders[rules.Sort]; !ok {
+			return nil, fmt.Errorf("%w: sort must be one of views, newest, oldest, duration, title", ErrInvalidRules)
+		}
+	}
+
+	tags := rules.Tags[:0]
+	for _, tag := range rules.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	rules.Tags = tags
+
+	return &rules, nil
+}
+
+// Service resolves playlists to films. Smart playlists are evaluated on
+// read and cached in Redis for CacheTTL.
+type Service struct {
+	queries *db.Queries
+	redis   *redis.Client
+}
+
+// NewService creates a playlist service
+func NewService(queries *db.Queries, redisClient *redis.Client) *Service {
+	return &Service{queries: queries, redis: redisClient}
+}
+
+// FilmIDs returns a playlist's published films in playlist order, before
+// region checks
+func (s *Service) FilmIDs(ctx context.Context, playlist *models.Playlist) ([]uuid.UUID, error) {
+	if playlist.Kind != models.PlaylistSmart {
+		return s.queries.ListPlaylistFilmIDs(ctx, playlist.ID)
+	}
+
+	if ids, err := s.redis.GetPlaylistFilms(ctx, playlist.ID); err == nil {
+		return ids, nil
+	}
+
+	rules, err := ParseRules(playlist.Rules)
+	if err != nil {
+		return nil, err
+	}
+	filter := db.FilmFilter{
+		Status:         models.StatusReady,
+		CreatorID:      rules.CreatorID,
+		Type:           rules.Type,
+		Genre:          rules.Genre,
+		MinDuration:    rules.MinDuration,
+		MaxDuration:    rules.MaxDuration,
+		MinRating:      rules.MinRating,
+		PublishedAfter: rules.PublishedAfter,
+		Listed:         !rules.OwnFilms,
+	}
+	if rules.OwnFilms {
+		filter.CreatorID = &playlist.OwnerID
+	}
+	limit := rules.Limit
+	if limit == 0 {
+		limit = DefaultSmartLimit
+	}
+
+	ids, err := s.queries.ListSmartPlaylistFilmIDs(ctx, filter, rules.Tags, rules.Sort, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redis.SetPlaylistFilms(ctx, playlist.ID, ids, CacheTTL); err != nil {
+		log.Printf("Failed to cache playlist %s: %v", playlist.ID, err)
+	}
+	return ids, nil
+}
+
+// Films returns the playlist's films a viewer in country may watch
+func (s *Service) Films(ctx context.Context, playlist *models.Playlist, country string) ([]models.Film, error) {
+	ids, err := s.FilmIDs(ctx, playlist)
+	if err != nil {
+		return nil, err
+	}
+	films, err := s.queries.GetFilmsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Films can change between a cached evaluation and now
+	visible := films[:0]
+	for _, film := range films {
+		if film.Status == models.StatusReady && film.PublishedAt != nil && policy.For(&film).Reachable(country) {
+			visible = append(visible, film)
+		}
+	}
+	return visible, nil
+}
+
+// Next returns the film after filmID in the playlist for a viewer in
+// country, or nil at the end. A film not in the playlist (e.g. a trailer
+// watched first) continues with the first film.
+func (s *Service) Next(ctx context.Context, playlist *models.Playlist, filmID uuid.UUID, country string) (*models.Film, int, error) {
+	films, err := s.Films(ctx, playlist, country)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	next := 0
+	for i := range films {
+		if films[i].ID == filmID {
+			next = i + 1
+			break
+		}
+	}
+	if next >= len(films) {
+		return nil, 0, nil
+	}
+	return &films[next], next, nil
+}
+
+// Invalidate drops a smart playlist's cached films after its rules change
+func (s *Service) Invalidate(ctx context.Context, playlistID uuid.UUID) {
+	if err := s.redis.InvalidatePlaylistFilms(ctx, playlistID); err != nil {
+		log.Printf("Failed to invalidate playlist %s: %v", playlistID, err)
+	}
+}
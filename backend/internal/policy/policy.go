@@ -0,0 +1,368 @@
+// Package policy validates film policy documents and answers every access
+// question about a film (listing, playback, comments, downloads, embeds)
+// from its policy.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/geo"
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// MaxMinAge bounds a policy's age gate
+const MaxMinAge = 21
+
+// MaxEmbedDomains bounds a policy's embed allow list
+const MaxEmbedDomains = 50
+
+// Bounds of a policy's spam filter
+const (
+	MaxSpamLinks    = 20
+	MaxSpamKeywords = 100
+	maxKeywordLen   = 100
+)
+
+// DefaultSpamMaxLinks is how many links a comment may have before the
+// default spam filter holds it
+const DefaultSpamMaxLinks = 2
+
+// ErrInvalidPolicy is wrapped by Parse and Validate errors
+var ErrInvalidPolicy = errors.New("invalid film policy")
+
+// Reasons CanWatch turns a viewer away
+var (
+	ErrPrivate    = errors.New("film not found")
+	ErrRegion     = errors.New("film is not available in your region")
+	ErrEmbed      = errors.New("film may not be played in embedded players")
+	ErrEmbedSite  = errors.New("film may not be embedded on this site")
+	ErrAgeUnknown = errors.New("film is age restricted; sign in and set your birth date to watch it")
+	ErrUnderage   = errors.New("film is age restricted")
+)
+
+// Default returns the policy of a film nobody has configured: public,
+// open to comments, downloads and embeds, everywhere and for all ages,
+// with comments filtered for spam
+func Default() models.FilmPolicy {
+	return models.FilmPolicy{
+		Visibility:   models.VisibilityPublic,
+		Comments:     models.CommentsEnabled,
+		Downloads:    true,
+		Embeds:       true,
+		EmbedDomains: []string{},
+		Regions:      models.RegionRules{Allowed: []string{}, Blocked: []string{}},
+		Spam:         models.SpamFilter{Enabled: true, MaxLinks: DefaultSpamMaxLinks, Keywords: []string{}},
+	}
+}
+
+// lockedDown is the policy of a film whose stored policy is unreadable
+func lockedDown() models.FilmPolicy {
+	return models.FilmPolicy{
+		Visibility:   models.VisibilityPrivate,
+		Comments:     models.CommentsDisabled,
+		EmbedDomains: []string{},
+		Regions:      models.RegionRules{Allowed: []string{}, Blocked: []string{}},
+		Spam:         models.SpamFilter{Keywords: []string{}},
+	}
+}
+
+// Parse decodes and checks a policy document. Fields it leaves out keep
+// their Default values; unknown fields are rejected.
+func Parse(raw json.RawMessage) (*models.FilmPolicy, error) {
+	p := Default()
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	if err := Validate(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks a policy and normalizes its region and embed domain
+// lists
+func Validate(p *models.FilmPolicy) error {
+	switch {
+	case p.Visibility != models.VisibilityPublic && p.Visibility != models.VisibilityUnlisted && p.Visibility != models.VisibilityPrivate:
+		return fmt.Errorf("%w: visibility must be PUBLIC, UNLISTED or PRIVATE", ErrInvalidPolicy)
+	case p.Comments != models.CommentsEnabled && p.Comments != models.CommentsHeld && p.Comments != models.CommentsDisabled:
+		return fmt.Errorf("%w: comments must be ENABLED, HELD or DISABLED", ErrInvalidPolicy)
+	case p.MinAge < 0 || p.MinAge > MaxMinAge:
+		return fmt.Errorf("%w: min_age must be between 0 and %d", ErrInvalidPolicy, MaxMinAge)
+	case p.Spam.MaxLinks < 0 || p.Spam.MaxLinks > MaxSpamLinks:
+		return fmt.Errorf("%w: spam.max_links must be between 0 and %d", ErrInvalidPolicy, MaxSpamLinks)
+	}
+
+	allowed, err := NormalizeRegions(p.Regions.Allowed)
+	if err != nil {
+		return fmt.Errorf("%w: regions.allowed: %v", ErrInvalidPolicy, err)
+	}
+	blocked, err := NormalizeRegions(p.Regions.Blocked)
+	if err != nil {
+		return fmt.Errorf("%w: regions.blocked: %v", ErrInvalidPolicy, err)
+	}
+	p.Regions = models.RegionRules{Allowed: allowed, Blocked: blocked}
+
+	domains, err := NormalizeEmbedDomains(p.EmbedDomains)
+	if err != nil {
+		return fmt.Errorf("%w: embed_domains: %v", ErrInvalidPolicy, err)
+	}
+	p.EmbedDomains = domains
+
+	keywords, err := NormalizeKeywords(p.Spam.Keywords)
+	if err != nil {
+		return fmt.Errorf("%w: spam.keywords: %v", ErrInvalidPolicy, err)
+	}
+	p.Spam.Keywords = keywords
+	return nil
+}
+
+// NormalizeKeywords lower-cases, trims and de-duplicates spam keywords
+func NormalizeKeywords(words []string) ([]string, error) {
+	if len(words) > MaxSpamKeywords {
+		return nil, fmt.Errorf("at most %d keywords", MaxSpamKeywords)
+	}
+	seen := map[string]bool{}
+	keywords := []string{}
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" || len(word) > maxKeywordLen {
+			return nil, fmt.Errorf("keywords must be 1 to %d characters", maxKeywordLen)
+		}
+		if !seen[word] {
+			seen[word] = true
+			keywords = append(keywords, word)
+		}
+	}
+	return keywords, nil
+}
+
+// NormalizeRegions upper-cases, validates and de-duplicates country codes
+func NormalizeRegions(codes []string) ([]string, error) {
+	seen := map[string]bool{}
+	regions := []string{}
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if !geo.ValidCountryCode(code) {
+			return nil, fmt.Errorf("invalid country code %q", code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			regions = append(regions, code)
+		}
+	}
+	return regions, nil
+}
+
+// NormalizeEmbedDomains reduces sites to lower-case host names, accepting
+// URLs and "*.example.com" for convenience, and de-duplicates them
+func NormalizeEmbedDomains(sites []string) ([]string, error) {
+	if len(sites) > MaxEmbedDomains {
+		return nil, fmt.Errorf("at most %d domains", MaxEmbedDomains)
+	}
+	seen := map[string]bool{}
+	domains := []string{}
+	for _, site := range sites {
+		domain := HostOf(site)
+		domain = strings.TrimPrefix(domain, "*.")
+		if !validDomain(domain) {
+			return nil, fmt.Errorf("invalid domain %q", site)
+		}
+		if !seen[domain] {
+			seen[domain] = true
+			domains = append(domains, domain)
+		}
+	}
+	return domains, nil
+}
+
+// HostOf returns the lower-case host name of a URL or bare host, without
+// its port
+func HostOf(site string) string {
+	site = strings.ToLower(strings.TrimSpace(site))
+	if i := strings.Index(site, "://"); i >= 0 {
+		site = site[i+3:]
+	}
+	if i := strings.IndexAny(site, "/?#"); i >= 0 {
+		site = site[:i]
+	}
+	if i := strings.LastIndex(site, "@"); i >= 0 {
+		site = site[i+1:]
+	}
+	if host, _, err := net.SplitHostPort(site); err == nil {
+		site = host
+	}
+	return strings.TrimSuffix(site, ".")
+}
+
+// validDomain reports whether domain looks like a host name
+func validDomain(domain string) bool {
+	if domain == "" || len(domain) > 253 || strings.HasPrefix(domain, ".") || strings.Contains(domain, "..") {
+		return false
+	}
+	for _, r := range domain {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// Viewer is who wants to watch a film
+type Viewer struct {
+	// Country is the viewer's country code, "" when unknown
+	Country string
+	// Age is nil when unknown (signed out or no birth date set)
+	Age *int
+	// Privileged is set for the film's creator and admins, who see past
+	// its visibility and age gate but not its licensing regions
+	Privileged bool
+	// Embedded is set when playing in a player embedded on another site
+	Embedded bool
+	// EmbedHost is the host name of the page embedding the player, ""
+	// when unknown
+	EmbedHost string
+}
+
+// Evaluator answers access questions about one film from its policy
+type Evaluator struct {
+	policy models.FilmPolicy
+}
+
+// For returns the evaluator of a film. A stored policy that no longer
+// parses locks the film down to its creator rather than opening it up.
+func For(film *models.Film) *Evaluator {
+	if len(film.Policy) == 0 {
+		return &Evaluator{policy: Default()}
+	}
+	p, err := Parse(film.Policy)
+	if err != nil {
+		log.Printf("Film %s has an unreadable policy: %v", film.ID, err)
+		return &Evaluator{policy: lockedDown()}
+	}
+	return &Evaluator{policy: *p}
+}
+
+// Policy returns the evaluated policy
+func (e *Evaluator) Policy() models.FilmPolicy {
+	return e.policy
+}
+
+// AvailableIn reports whether the film is licensed in a country. An
+// unknown country ("") only gets films without an allow list.
+func (e *Evaluator) AvailableIn(country string) bool {
+	if len(e.policy.Regions.Allowed) > 0 && !contains(e.policy.Regions.Allowed, country) {
+		return false
+	}
+	return !contains(e.policy.Regions.Blocked, country)
+}
+
+// Listed reports whether the film may appear in listings, rails and the
+// hero for a viewer in country
+func (e *Evaluator) Listed(country string) bool {
+	return e.policy.Visibility == models.VisibilityPublic && e.AvailableIn(country)
+}
+
+// Reachable reports whether a viewer in country who follows a link to the
+// film, e.g. from a playlist or their watch history, may find it
+func (e *Evaluator) Reachable(country string) bool {
+	return e.policy.Visibility != models.VisibilityPrivate && e.AvailableIn(country)
+}
+
+// CanSee reports whether a viewer may see the film's details at all
+func (e *Evaluator) CanSee(v Viewer) bool {
+	return v.Privileged || e.policy.Visibility != models.VisibilityPrivate
+}
+
+// CanWatch returns why a viewer may not play the film, or nil
+func (e *Evaluator) CanWatch(v Viewer) error {
+	switch {
+	case !e.CanSee(v):
+		return ErrPrivate
+	case !e.AvailableIn(v.Country):
+		return ErrRegion
+	case v.Embedded && !e.policy.Embeds:
+		return ErrEmbed
+	case v.Embedded && !e.EmbeddableOn(v.EmbedHost):
+		return ErrEmbedSite
+	case !e.AgeGated() || v.Privileged:
+		return nil
+	case v.Age == nil:
+		return ErrAgeUnknown
+	case *v.Age < e.policy.MinAge:
+		return ErrUnderage
+	}
+	return nil
+}
+
+// EmbeddableOn reports whether the film may be embedded on a site: embeds
+// are on and the site, or a domain it is a subdomain of, is allowed. An
+// unknown site is only allowed when every site is.
+func (e *Evaluator) EmbeddableOn(host string) bool {
+	if !e.policy.Embeds {
+		return false
+	}
+	if len(e.policy.EmbedDomains) == 0 {
+		return true
+	}
+	host = HostOf(host)
+	for _, domain := range e.policy.EmbedDomains {
+		if host != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AgeGated reports whether the film has a minimum viewer age
+func (e *Evaluator) AgeGated() bool {
+	return e.policy.MinAge > 0
+}
+
+// Comments returns whether and how the film takes comments
+func (e *Evaluator) Comments() models.CommentMode {
+	return e.policy.Comments
+}
+
+// SpamFilter returns how the film's comments are checked for spam
+func (e *Evaluator) SpamFilter() models.SpamFilter {
+	return e.policy.Spam
+}
+
+// AllowsDownloads reports whether the film may be sold as a download
+func (e *Evaluator) AllowsDownloads() bool {
+	return e.policy.Downloads
+}
+
+// AllowsEmbeds reports whether the film may play in embedded players
+func (e *Evaluator) AllowsEmbeds() bool {
+	return e.policy.Embeds
+}
+
+// Age returns the age in whole years at now of someone born on birth
+func Age(birth, now time.Time) int {
+	years := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		years--
+	}
+	return years
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
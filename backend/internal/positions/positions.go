@@ -0,0 +1,115 @@
+// Package positions keeps viewers' playback positions so they can resume
+// where they left off on any device. Player heartbeats land in Redis and
+// are flushed to Postgres in batches.
+package positions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// positionTTL is how long Redis holds a position; Postgres has it
+	// after the next flush
+	positionTTL = 7 * 24 * time.Hour
+
+	// flushBatchSize bounds how many positions one flush writes
+	flushBatchSize = 500
+)
+
+// Store reads and writes playback positions
+type Store struct {
+	queries *db.Queries
+	redis   *redis.Client
+}
+
+// New creates a playback position store
+func New(queries *db.Queries, redisClient *redis.Client) *Store {
+	return &Store{
+		queries: queries,
+		redis:   redisClient,
+	}
+}
+
+// Save records a heartbeat. When Redis is unavailable the position goes
+// straight to Postgres.
+func (s *Store) Save(ctx context.Context, position *models.PlaybackPosition) error {
+	if err := s.redis.SetPlaybackPosition(ctx, position, positionTTL); err != nil {
+		log.Printf("[Positions] Failed to cache position, writing through: %v", err)
+		return s.queries.SavePlaybackPosition(ctx, position)
+	}
+	return nil
+}
+
+// Get returns a user's latest position in a film, or nil when they have
+// not played it
+func (s *Store) Get(ctx context.Context, userID, filmID uuid.UUID) (*models.PlaybackPosition, error) {
+	position, err := s.redis.GetPlaybackPosition(ctx, userID, filmID)
+	if err == nil {
+		return position, nil
+	}
+	if !errors.Is(err, goredis.Nil) {
+		log.Printf("[Positions] Failed to read cached position: %v", err)
+	}
+
+	position, err = s.queries.GetPlaybackPosition(ctx, userID, filmID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return position, err
+}
+
+// Flush writes positions heartbeated since the last flush to Postgres and
+// returns how many it wrote. Instances may flush concurrently; each
+// position is taken by one of them.
+func (s *Store) Flush(ctx context.Context) (int, error) {
+	flushed := 0
+	for {
+		batch, err := s.redis.PopDirtyPlaybackPositions(ctx, flushBatchSize)
+		if err != nil {
+			return flushed, err
+		}
+		if len(batch) == 0 {
+			return flushed, nil
+		}
+
+		for i, position := range batch {
+			if err := s.queries.SavePlaybackPosition(ctx, position); err != nil {
+				// Put back what was not written so the next flush retries it
+				if markErr := s.redis.MarkPlaybackPositionsDirty(ctx, batch[i:]); markErr != nil {
+					log.Printf("[Positions] Failed to requeue %d positions: %v", len(batch)-i, markErr)
+				}
+				return flushed, err
+			}
+			flushed++
+		}
+	}
+}
+
+// RunFlushLoop flushes positions on every interval. It blocks until ctx is
+// cancelled; flush once more on shutdown to keep the last heartbeats.
+func (s *Store) RunFlushLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.Flush(ctx)
+			if err != nil {
+				log.Printf("[Positions] Flush failed after %d positions: %v", n, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package preflight holds the rules a film's source must meet to be
+// transcoded. The API checks a creator's description of a file against
+// them before it is uploaded and the worker checks the probed source.
+package preflight
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MinDimension is the smallest width or height transcoded; the lowest
+	// rendition is 640x360
+	MinDimension = 144
+	// MaxWidth and MaxHeight bound sources at 8K, in either orientation
+	MaxWidth  = 7680
+	MaxHeight = 4320
+)
+
+// Containers are the file formats a source may be uploaded in, matching
+// the content types accepted for upload URLs
+var Containers = map[string]bool{
+	"mp4":  true,
+	"m4v":  true,
+	"mov":  true,
+	"mkv":  true,
+	"webm": true,
+	"avi":  true,
+	"mpeg": true,
+	"mpg":  true,
+	"ts":   true,
+}
+
+// VideoCodecs are the video codecs FFmpeg decodes for transcoding, by
+// FFmpeg's codec name
+var VideoCodecs = map[string]bool{
+	"h264":       true,
+	"hevc":       true,
+	"vp8":        true,
+	"vp9":        true,
+	"av1":        true,
+	"mpeg4":      true,
+	"mpeg2video": true,
+	"mpeg1video": true,
+	"prores":     true,
+	"dnxhd":      true,
+	"mjpeg":      true,
+}
+
+// AudioCodecs are the audio codecs FFmpeg decodes for transcoding
+var AudioCodecs = map[string]bool{
+	"aac":       true,
+	"mp3":       true,
+	"opus":      true,
+	"vorbis":    true,
+	"ac3":       true,
+	"eac3":      true,
+	"flac":      true,
+	"alac":      true,
+	"mp2":       true,
+	"pcm_s16le": true,
+	"pcm_s24le": true,
+	"pcm_s32le": true,
+	"pcm_f32le": true,
+}
+
+// codecAliases maps common names for codecs to FFmpeg's
+var codecAliases = map[string]string{
+	"avc":   "h264",
+	"h.264": "h264",
+	"x264":  "h264",
+	"h265":  "hevc",
+	"h.265": "hevc",
+	"x265":  "hevc",
+	"mpeg2": "mpeg2video",
+	"mpeg1": "mpeg1video",
+	"xvid":  "mpeg4",
+	"divx":  "mpeg4",
+}
+
+// Source describes a video file. Fields left empty or zero aren't checked,
+// so the worker can check what it probed and the API what it was told.
+type Source struct {
+	Container  string
+	VideoCodec string
+	AudioCodec string // empty for silent films
+	Width      int
+	Height     int
+	Size       int64
+}
+
+// Check returns why a source would fail transcoding, or nil when it would
+// be accepted. maxBytes bounds Size when positive.
+func Check(src Source, maxBytes int64) []string {
+	var reasons []string
+
+	if container := normalize(src.Container); container != "" && !Containers[container] {
+		reasons = append(reasons, fmt.Sprintf("container %q is not supported", src.Container))
+	}
+	if codec := Codec(src.VideoCodec); codec != "" && !VideoCodecs[codec] {
+		reasons = append(reasons, fmt.Sprintf("video codec %q is not supported", src.VideoCodec))
+	}
+	if codec := Codec(src.AudioCodec); codec != "" && !AudioCodecs[codec] {
+		reasons = append(reasons, fmt.Sprintf("audio codec %q is not supported", src.AudioCodec))
+	}
+
+	if src.Width > 0 && src.Height > 0 {
+		long, short := max(src.Width, src.Height), min(src.Width, src.Height)
+		if short < MinDimension {
+			reasons = append(reasons, fmt.Sprintf("resolution %dx%d is below the %dp minimum", src.Width, src.Height, MinDimension))
+		}
+		if long > MaxWidth || short > MaxHeight {
+			reasons = append(reasons, fmt.Sprintf("resolution %dx%d is above the %dx%d maximum", src.Width, src.Height, MaxWidth, MaxHeight))
+		}
+	}
+
+	if maxBytes > 0 && src.Size > maxBytes {
+		reasons = append(reasons, fmt.Sprintf("size %d bytes is above the %d byte maximum", src.Size, maxBytes))
+	}
+	return reasons
+}
+
+// Codec returns FFmpeg's name for a codec name like "H.264" or "x265"
+func Codec(name string) string {
+	name = normalize(name)
+	if alias, ok := codecAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+func normalize(name string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
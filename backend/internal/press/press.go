@@ -0,0 +1,139 @@
+package press
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	expiryLock = "press-expiry"
+
+	// expiryBatchSize bounds how many expired entries one pass cleans up
+	expiryBatchSize = 100
+)
+
+// Service manages per-film press lists and their watermarked screeners
+type Service struct {
+	queries  *db.Queries
+	r2Client *r2.Client
+	redis    *redis.Client
+	token    string
+}
+
+// New creates a press access service
+func New(queries *db.Queries, r2Client *r2.Client, redisClient *redis.Client) *Service {
+	return &Service{
+		queries:  queries,
+		r2Client: r2Client,
+		redis:    redisClient,
+		token:    uuid.New().String(),
+	}
+}
+
+// Grant adds a press user to a film's list and queues their watermarked
+// screener rendition set
+func (s *Service) Grant(ctx context.Context, film *models.Film, user *models.User, grantedBy uuid.UUID, expiresAt *time.Time) (*models.WorkerTask, error) {
+	access := &models.FilmPressAccess{
+		FilmID:      film.ID,
+		UserID:      user.ID,
+		GrantedByID: &grantedBy,
+		ExpiresAt:   expiresAt,
+	}
+	if err := s.queries.UpsertPressAccess(ctx, access); err != nil {
+		return nil, err
+	}
+
+	task := &models.WorkerTask{
+		ID:     uuid.New(),
+		Type:   models.TaskPressScreener,
+		FilmID: film.ID,
+		Params: map[string]string{
+			"variant":   models.ScreenerVariant(user.ID),
+			"watermark": fmt.Sprintf("PRESS SCREENER - %s - %s", user.Email, user.ID.String()[:8]),
+		},
+		RequestedBy: grantedBy,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.redis.EnqueueTask(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Revoke removes a press list entry and deletes its screener renditions,
+// returning sql.ErrNoRows if there was no entry
+func (s *Service) Revoke(ctx context.Context, filmID, userID uuid.UUID) error {
+	if err := s.r2Client.DeletePrefix(ctx, r2.GetHLSVariantPrefix(filmID, models.ScreenerVariant(userID))); err != nil {
+		return fmt.Errorf("failed to delete screener: %w", err)
+	}
+	return s.queries.DeletePressAccess(ctx, filmID, userID)
+}
+
+// ExpireOnce revokes every entry whose embargo or own expiry has passed
+func (s *Service) ExpireOnce(ctx context.Context) (int, error) {
+	expired, err := s.queries.ListExpiredPressAccess(ctx, expiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, entry := range expired {
+		err := s.Revoke(ctx, entry.FilmID, entry.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// RunExpiryLoop revokes lapsed press access on every interval. A Redis
+// lock keeps a single instance cleaning up at a time. It blocks until ctx
+// is cancelled.
+func (s *Service) RunExpiryLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expire(ctx, interval)
+		}
+	}
+}
+
+func (s *Service) expire(ctx context.Context, interval time.Duration) {
+	ok, err := s.redis.AcquireLock(ctx, expiryLock, s.token, interval)
+	if err != nil {
+		log.Printf("[Press] Failed to acquire expiry lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := s.redis.ReleaseLock(context.Background(), expiryLock, s.token); err != nil {
+			log.Printf("[Press] Failed to release expiry lock: %v", err)
+		}
+	}()
+
+	n, err := s.ExpireOnce(ctx)
+	if err != nil {
+		log.Printf("[Press] Failed to expire press access: %v", err)
+	}
+	if n > 0 {
+		log.Printf("[Press] Revoked %d lapsed press screeners", n)
+	}
+}
@@ -0,0 +1,152 @@
+// Package pruning frees storage by deleting the renditions old, rarely
+// watched films can do without, as set by the rendition pruning policy.
+package pruning
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/google/uuid"
+)
+
+const (
+	pruneLock = "rendition-pruning"
+
+	// batchSize bounds how many films one run queues for pruning
+	batchSize = 50
+)
+
+// Pruner picks films to prune and queues a worker task for each; the
+// worker deletes the renditions and records what it freed
+type Pruner struct {
+	queries  *db.Queries
+	redis    *redis.Client
+	settings *settings.Service
+	token    string
+}
+
+// New creates a rendition pruner
+func New(queries *db.Queries, redisClient *redis.Client, settingsService *settings.Service) *Pruner {
+	return &Pruner{
+		queries:  queries,
+		redis:    redisClient,
+		settings: settingsService,
+		token:    uuid.New().String(),
+	}
+}
+
+// Policy returns the configured pruning policy
+func (p *Pruner) Policy(ctx context.Context) models.PruningPolicy {
+	var policy models.PruningPolicy
+	if err := p.settings.Decode(ctx, settings.KeyRenditionPruning, &policy); err != nil {
+		log.Printf("[Pruning] Failed to load pruning policy: %v", err)
+	}
+	return policy
+}
+
+// RunOnce queues pruning tasks for the next batch of films the policy
+// applies to, whether or not the policy is enabled
+func (p *Pruner) RunOnce(ctx context.Context, requestedBy uuid.UUID) ([]*models.WorkerTask, error) {
+	policy := p.Policy(ctx)
+	tasks := []*models.WorkerTask{}
+	if len(policy.Keep) == 0 {
+		return tasks, nil
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -policy.MinAgeDays)
+	filmIDs, err := p.queries.ListPruneCandidates(ctx, policy.MaxViews, cutoff, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, filmID := range filmIDs {
+		task := &models.WorkerTask{
+			ID:          uuid.New(),
+			Type:        models.TaskPruneRenditions,
+			FilmID:      filmID,
+			Params:      map[string]string{"keep": strings.Join(policy.Keep, ",")},
+			RequestedBy: requestedBy,
+			CreatedAt:   time.Now(),
+		}
+		prune := &models.RenditionPrune{FilmID: filmID, TaskID: task.ID, Kept: policy.Keep}
+		if err := p.queries.CreateRenditionPrune(ctx, prune); err != nil {
+			return tasks, err
+		}
+		if err := p.redis.EnqueueTask(ctx, task); err != nil {
+			p.queries.DeleteRenditionPrune(ctx, filmID)
+			return tasks, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// Restore queues re-transcoding a film's pruned renditions. It returns
+// sql.ErrNoRows if the film has none to restore.
+func (p *Pruner) Restore(ctx context.Context, filmID, requestedBy uuid.UUID) (*models.WorkerTask, error) {
+	task := &models.WorkerTask{
+		ID:          uuid.New(),
+		Type:        models.TaskRestoreRenditions,
+		FilmID:      filmID,
+		RequestedBy: requestedBy,
+		CreatedAt:   time.Now(),
+	}
+	if err := p.queries.StartRenditionRestore(ctx, filmID, task.ID); err != nil {
+		return nil, err
+	}
+	if err := p.redis.EnqueueTask(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// RunLoop prunes on every interval while the policy is enabled. A Redis
+// lock keeps a single instance queueing at a time. It blocks until ctx is
+// cancelled.
+func (p *Pruner) RunLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.prune(ctx, interval)
+		}
+	}
+}
+
+func (p *Pruner) prune(ctx context.Context, interval time.Duration) {
+	if !p.Policy(ctx).Enabled {
+		return
+	}
+
+	ok, err := p.redis.AcquireLock(ctx, pruneLock, p.token, interval)
+	if err != nil {
+		log.Printf("[Pruning] Failed to acquire pruning lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := p.redis.ReleaseLock(context.Background(), pruneLock, p.token); err != nil {
+			log.Printf("[Pruning] Failed to release pruning lock: %v", err)
+		}
+	}()
+
+	tasks, err := p.RunOnce(ctx, uuid.Nil)
+	if err != nil {
+		log.Printf("[Pruning] Failed to queue rendition pruning: %v", err)
+	}
+	if len(tasks) > 0 {
+		log.Printf("[Pruning] Queued rendition pruning for %d films", len(tasks))
+	}
+}
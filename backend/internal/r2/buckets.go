@@ -0,0 +1,34 @@
+package r2
+
+import "strings"
+
+// privatePaths are the key prefixes never delivered publicly: uploaded
+// sources and audio, quarantined uploads, purchased downloads, comment
+// archives and admin exports. They are only reached with pre-signed URLs.
+var privatePaths = []string{OriginalPath, QuarantinePath, DownloadPath, CommentPath, ExportPath}
+
+// SetPrivateBucket keeps the private paths in bucket, which must not be
+// publicly reachable, leaving the client's bucket to what the CDN
+// delivers. Call it before the client is shared.
+func (c *Client) SetPrivateBucket(bucket string) {
+	c.privateBucket = bucket
+}
+
+// IsPrivateKey reports whether key is under one of the private paths
+func IsPrivateKey(key string) bool {
+	for _, path := range privatePaths {
+		if strings.HasPrefix(key, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketFor returns the bucket an object, or the objects under a prefix,
+// are stored in
+func (c *Client) bucketFor(key string) string {
+	if c.privateBucket != "" && IsPrivateKey(key) {
+		return c.privateBucket
+	}
+	return c.bucket
+}
@@ -0,0 +1,62 @@
+package r2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// SHA256Checksum converts a hex SHA-256 digest to the base64 form storage
+// uses for x-amz-checksum-sha256
+func SHA256Checksum(hexDigest string) (string, error) {
+	sum, err := hex.DecodeString(hexDigest)
+	if err != nil || len(sum) != sha256.Size {
+		return "", fmt.Errorf("invalid SHA-256 digest %q", hexDigest)
+	}
+	return base64.StdEncoding.EncodeToString(sum), nil
+}
+
+// ObjectSHA256 returns the hex SHA-256 of an object. A full-object checksum
+// stored with the object is used when there is one, e.g. for uploads to a
+// URL with a signed checksum; otherwise the object is read and hashed.
+func (c *Client) ObjectSHA256(ctx context.Context, key string) (string, error) {
+	head, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(c.bucketFor(key)),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to head object: %w", err)
+	}
+	// Multipart uploads store a checksum of part checksums, suffixed with
+	// the part count, which can't be compared with the file's
+	if stored := aws.ToString(head.ChecksumSHA256); stored != "" && !strings.Contains(stored, "-") &&
+		head.ChecksumType != types.ChecksumTypeComposite {
+		if sum, err := base64.StdEncoding.DecodeString(stored); err == nil && len(sum) == sha256.Size {
+			return hex.EncodeToString(sum), nil
+		}
+	}
+
+	obj, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucketFor(key)),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get object: %w", err)
+	}
+	defer obj.Body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, obj.Body); err != nil {
+		return "", fmt.Errorf("failed to read object: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
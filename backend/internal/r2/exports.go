@@ -0,0 +1,16 @@
+package r2
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ExportPath holds admin listing exports
+const ExportPath = "exports"
+
+// GetAdminExportKey returns the storage key of an admin listing export
+// written by an export task; format is its file extension
+func GetAdminExportKey(taskID uuid.UUID, format string) string {
+	return fmt.Sprintf("%s/admin/%s.%s", ExportPath, taskID, format)
+}
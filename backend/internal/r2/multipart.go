@@ -0,0 +1,174 @@
+package r2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Multipart upload limits of S3 and R2
+const (
+	MinPartSize = 5 << 20 // every part but the last
+	MaxPartSize = 5 << 30
+	MaxParts    = 10000
+)
+
+// UploadedPart is a part of a multipart upload that reached storage
+type UploadedPart struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size,omitempty"`
+}
+
+// CreateMultipartUpload starts a multipart upload to key and returns its
+// upload ID
+func (c *Client) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(c.bucketFor(key)),
+		Key:    aws.String(key),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	out, err := c.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+	return aws.ToString(out.UploadId), nil
+}
+
+// GeneratePresignedUploadPartURL creates a pre-signed URL for uploading one
+// part of a multipart upload. The part's length is signed, so storage
+// rejects a part of any other size.
+func (c *Client) GeneratePresignedUploadPartURL(ctx context.Context, key, uploadID string, partNumber int, size int64, expiration time.Duration) (string, error) {
+	presignClient := s3.NewPresignClient(c.client)
+
+	presignedResult, err := presignClient.PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(c.bucketFor(key)),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(int32(partNumber)),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expiration))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign upload part: %w", err)
+	}
+
+	return presignedResult.URL, nil
+}
+
+// ListUploadedParts returns the parts of a multipart upload stored so far,
+// by part number
+func (c *Client) ListUploadedParts(ctx context.Context, key, uploadID string) ([]UploadedPart, error) {
+	parts := []UploadedPart{}
+	paginator := s3.NewListPartsPaginator(c.client, &s3.ListPartsInput{
+		Bucket:   aws.String(c.bucketFor(key)),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list upload parts: %w", err)
+		}
+		for _, part := range page.Parts {
+			parts = append(parts, UploadedPart{
+				PartNumber: int(aws.ToInt32(part.PartNumber)),
+				ETag:       aws.ToString(part.ETag),
+				Size:       aws.ToInt64(part.Size),
+			})
+		}
+	}
+	return parts, nil
+}
+
+// CompleteMultipartUpload assembles the parts into the object
+func (c *Client) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []UploadedPart) error {
+	sorted := make([]UploadedPart, len(parts))
+	copy(sorted, parts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PartNumber < sorted[j].PartNumber })
+
+	completed := make([]types.CompletedPart, len(sorted))
+	for i, part := range sorted {
+		completed[i] = types.CompletedPart{
+			PartNumber: aws.Int32(int32(part.PartNumber)),
+			ETag:       aws.String(part.ETag),
+		}
+	}
+
+	_, err := c.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(c.bucketFor(key)),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+// AbortMultipartUpload discards a multipart upload and its stored parts
+func (c *Client) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	_, err := c.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(c.bucketFor(key)),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return nil
+}
+
+// PendingUpload is a multipart upload that was started but neither
+// completed nor aborted
+type PendingUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+// ListPendingUploads returns the unfinished multipart uploads to keys under
+// prefix
+func (c *Client) ListPendingUploads(ctx context.Context, prefix string) ([]PendingUpload, error) {
+	uploads := []PendingUpload{}
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(c.bucketFor(prefix)),
+		Prefix: aws.String(prefix),
+	}
+	for {
+		out, err := c.client.ListMultipartUploads(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list multipart uploads: %w", err)
+		}
+		for _, upload := range out.Uploads {
+			uploads = append(uploads, PendingUpload{
+				Key:       aws.ToString(upload.Key),
+				UploadID:  aws.ToString(upload.UploadId),
+				Initiated: aws.ToTime(upload.Initiated),
+			})
+		}
+		if !aws.ToBool(out.IsTruncated) {
+			return uploads, nil
+		}
+		input.KeyMarker = out.NextKeyMarker
+		input.UploadIdMarker = out.NextUploadIdMarker
+	}
+}
+
+// PartSize picks the part size for a file: at least 16 MiB, and large
+// enough to fit the file in MaxParts parts, in whole MiB
+func PartSize(fileSize int64) int64 {
+	const mib = 1 << 20
+	size := int64(16 * mib)
+	if least := (fileSize + MaxParts - 1) / MaxParts; least > size {
+		size = (least + mib - 1) / mib * mib
+	}
+	return size
+}
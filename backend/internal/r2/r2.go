@@ -2,14 +2,19 @@ package r2
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/google/uuid"
 )
 
@@ -18,6 +23,12 @@ const (
 	OriginalPath = "original"
 	ThumbnailPath = "thumb"
 	HLSPath      = "hls"
+	SubtitlePath = "subtitles"
+	LUTPath      = "luts"
+	FeaturedPath = "featured"
+	DownloadPath = "downloads"
+	CommentPath  = "comments"
+	QuarantinePath = "quarantine"
 )
 
 type Client struct {
@@ -26,6 +37,17 @@ type Client struct {
 	downloader *manager.Downloader
 	bucket     string
 	publicURL  string
+
+	// Bucket holding the private paths, when they are kept out of the
+	// public delivery bucket
+	privateBucket string
+
+	// Part size and concurrency of multipart uploads, when tuned
+	uploadLimits UploadLimits
+
+	// Regional buckets holding uploaded sources, by region label
+	sourceRegion  string
+	sourceBuckets map[string]string
 }
 
 // New creates a new Cloudflare R2 client (S3-compatible)
@@ -57,28 +79,57 @@ func New(endpoint, accessKey, secretKey, bucket, region, publicURL string) (*Cli
 	// Create S3 client
 	client := s3.NewFromConfig(cfg)
 
-	return &Client{
+	c := &Client{
 		client:     client,
-		uploader:   manager.NewUploader(client),
 		downloader: manager.NewDownloader(client),
 		bucket:     bucket,
 		publicURL:  publicURL,
-	}, nil
+	}
+	c.uploader = c.newUploader()
+	return c, nil
+}
+
+// InjectFaults runs fault before every R2 operation (including presigning)
+// and fails the operation with its error; used for resilience testing.
+// Call it before the client is shared.
+func (c *Client) InjectFaults(fault func(ctx context.Context, operation string) error) {
+	c.client = s3.New(c.client.Options(), func(o *s3.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("FaultInjection",
+				func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+					if err := fault(ctx, awsmiddleware.GetOperationName(ctx)); err != nil {
+						return middleware.InitializeOutput{}, middleware.Metadata{}, err
+					}
+					return next.HandleInitialize(ctx, in)
+				}), middleware.Before)
+		})
+	})
+	c.uploader = c.newUploader()
+	c.downloader = manager.NewDownloader(c.client)
 }
 
 // ========== UPLOAD URL GENERATION ==========
 
 // GeneratePresignedUploadURL creates a pre-signed URL for direct upload to R2
 // The file will be uploaded to: original/{filmId}/source.mp4
-func (c *Client) GeneratePresignedUploadURL(ctx context.Context, filmID uuid.UUID, expiration time.Duration) (string, error) {
+// The content type and length are signed, so storage rejects an upload
+// that doesn't send exactly those headers. A base64 SHA-256 checksum, when
+// given, is signed too: storage verifies the body against it and keeps it.
+func (c *Client) GeneratePresignedUploadURL(ctx context.Context, filmID uuid.UUID, contentType string, size int64, checksum string, expiration time.Duration) (string, error) {
 	key := fmt.Sprintf("%s/%s/source.mp4", OriginalPath, filmID)
 
 	presignClient := s3.NewPresignClient(c.client)
 
-	presignedResult, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(c.bucket),
-		Key:    aws.String(key),
-	}, s3.WithPresignExpires(expiration))
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(c.bucketFor(key)),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}
+	if checksum != "" {
+		input.ChecksumSHA256 = aws.String(checksum)
+	}
+	presignedResult, err := presignClient.PresignPutObject(ctx, input, s3.WithPresignExpires(expiration))
 	if err != nil {
 		return "", fmt.Errorf("failed to presign put object: %w", err)
 	}
@@ -93,7 +144,7 @@ func (c *Client) GeneratePresignedUploadURLForThumbnail(ctx context.Context, fil
 	presignClient := s3.NewPresignClient(c.client)
 
 	presignedResult, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(c.bucket),
+		Bucket: aws.String(c.bucketFor(key)),
 		Key:    aws.String(key),
 	}, s3.WithPresignExpires(expiration))
 	if err != nil {
@@ -103,12 +154,65 @@ func (c *Client) GeneratePresignedUploadURLForThumbnail(ctx context.Context, fil
 	return presignedResult.URL, nil
 }
 
+// GeneratePresignedUploadURLForAudio creates a pre-signed URL for a
+// replacement audio upload
+// The file will be uploaded to: original/{filmId}/replacement-audio
+func (c *Client) GeneratePresignedUploadURLForAudio(ctx context.Context, filmID uuid.UUID, expiration time.Duration) (string, error) {
+	key := GetReplacementAudioKey(filmID)
+
+	presignClient := s3.NewPresignClient(c.client)
+
+	presignedResult, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(c.bucketFor(key)),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expiration))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign put object: %w", err)
+	}
+
+	return presignedResult.URL, nil
+}
+
+// GeneratePresignedUploadURLForKey creates a pre-signed URL for uploading
+// an object of the given content type to an arbitrary key
+func (c *Client) GeneratePresignedUploadURLForKey(ctx context.Context, key, contentType string, expiration time.Duration) (string, error) {
+	presignClient := s3.NewPresignClient(c.client)
+
+	presignedResult, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(c.bucketFor(key)),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	}, s3.WithPresignExpires(expiration))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign put object: %w", err)
+	}
+
+	return presignedResult.URL, nil
+}
+
+// GeneratePresignedDownloadURL creates a pre-signed URL that downloads an
+// object as an attachment with the given file name
+func (c *Client) GeneratePresignedDownloadURL(ctx context.Context, key, filename string, expiration time.Duration) (string, error) {
+	presignClient := s3.NewPresignClient(c.client)
+
+	presignedResult, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:                     aws.String(c.bucketFor(key)),
+		Key:                        aws.String(key),
+		ResponseContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", filename)),
+	}, s3.WithPresignExpires(expiration))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign get object: %w", err)
+	}
+
+	return presignedResult.URL, nil
+}
+
 // ========== FILE OPERATIONS ==========
 
 // UploadFile uploads a file to R2
 func (c *Client) UploadFile(ctx context.Context, key string, reader io.Reader, contentType string) error {
 	_, err := c.uploader.Upload(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(c.bucket),
+		Bucket:      aws.String(c.bucketFor(key)),
 		Key:          aws.String(key),
 		Body:         reader,
 		ContentType:  aws.String(contentType),
@@ -119,11 +223,24 @@ func (c *Client) UploadFile(ctx context.Context, key string, reader io.Reader, c
 // UploadHLSFile uploads an HLS file to R2
 func (c *Client) UploadHLSFile(ctx context.Context, filmID uuid.UUID, quality, filename string, reader io.Reader) error {
 	key := fmt.Sprintf("%s/%s/%s/%s", HLSPath, filmID, quality, filename)
-	contentType := "application/x-mpegURL"
+	return c.UploadFile(ctx, key, reader, hlsContentType(filename))
+}
+
+// hlsContentType returns the content type of an HLS playlist or segment
+func hlsContentType(filename string) string {
 	if len(filename) > 4 && filename[len(filename)-3:] == ".ts" {
-		contentType = "video/mp2t"
+		return "video/mp2t"
+	}
+	return "application/x-mpegURL"
+}
+
+// UploadSubtitle uploads a WebVTT subtitle track and returns its public URL
+func (c *Client) UploadSubtitle(ctx context.Context, filmID uuid.UUID, language string, reader io.Reader) (string, error) {
+	key := GetSubtitleKey(filmID, language)
+	if err := c.UploadFile(ctx, key, reader, "text/vtt"); err != nil {
+		return "", err
 	}
-	return c.UploadFile(ctx, key, reader, contentType)
+	return c.GetPublicURL(key), nil
 }
 
 // DownloadFile downloads a file from R2
@@ -131,7 +248,7 @@ func (c *Client) DownloadFile(ctx context.Context, key string) ([]byte, error) {
 	buffer := manager.NewWriteAtBuffer([]byte{})
 
 	_, err := c.downloader.Download(ctx, buffer, &s3.GetObjectInput{
-		Bucket: aws.String(c.bucket),
+		Bucket: aws.String(c.bucketFor(key)),
 		Key:    aws.String(key),
 	})
 	if err != nil {
@@ -141,6 +258,28 @@ func (c *Client) DownloadFile(ctx context.Context, key string) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// DownloadToFile streams a file from R2 to a local path and returns its
+// size. Parts are written as they arrive, so the file is never held in
+// memory.
+func (c *Client) DownloadToFile(ctx context.Context, key, path string) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return c.downloader.Download(ctx, f, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucketFor(key)),
+		Key:    aws.String(key),
+	})
+}
+
+// UploadOriginalVideo replaces the stored original video for a film
+func (c *Client) UploadOriginalVideo(ctx context.Context, filmID uuid.UUID, reader io.Reader) error {
+	key := fmt.Sprintf("%s/%s/source.mp4", OriginalPath, filmID)
+	return c.UploadFile(ctx, key, reader, "video/mp4")
+}
+
 // DownloadOriginalVideo downloads the original video for transcoding
 func (c *Client) DownloadOriginalVideo(ctx context.Context, filmID uuid.UUID) ([]byte, error) {
 	key := fmt.Sprintf("%s/%s/source.mp4", OriginalPath, filmID)
@@ -156,11 +295,14 @@ func (c *Client) DeleteFilm(ctx context.Context, filmID uuid.UUID) error {
 		fmt.Sprintf("%s/%s/", OriginalPath, filmID),
 		fmt.Sprintf("%s/%s/", ThumbnailPath, filmID),
 		fmt.Sprintf("%s/%s/", HLSPath, filmID),
+		fmt.Sprintf("%s/%s/", SubtitlePath, filmID),
+		fmt.Sprintf("%s/film/%s/", LUTPath, filmID),
+		fmt.Sprintf("%s/%s/", DownloadPath, filmID),
 	}
 
 	for _, prefix := range paths {
 		listOutput, err := c.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-			Bucket: aws.String(c.bucket),
+			Bucket: aws.String(c.bucketFor(prefix)),
 			Prefix: aws.String(prefix),
 		})
 		if err != nil {
@@ -169,7 +311,7 @@ func (c *Client) DeleteFilm(ctx context.Context, filmID uuid.UUID) error {
 
 		for _, obj := range listOutput.Contents {
 			_, err := c.client.DeleteObject(ctx, &s3.DeleteObjectInput{
-				Bucket: aws.String(c.bucket),
+				Bucket: aws.String(c.bucketFor(prefix)),
 				Key:    obj.Key,
 			})
 			if err != nil {
@@ -181,6 +323,125 @@ func (c *Client) DeleteFilm(ctx context.Context, filmID uuid.UUID) error {
 	return nil
 }
 
+// DeleteFile removes a single object
+func (c *Client) DeleteFile(ctx context.Context, key string) error {
+	_, err := c.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucketFor(key)),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// DeletePrefix removes every object whose key starts with prefix
+func (c *Client) DeletePrefix(ctx context.Context, prefix string) error {
+	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.bucketFor(prefix)),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, obj := range page.Contents {
+			_, err := c.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(c.bucketFor(prefix)),
+				Key:    obj.Key,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ListKeys returns the keys of every object whose key starts with prefix
+func (c *Client) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	keys := []string{}
+	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.bucketFor(prefix)),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+	}
+	return keys, nil
+}
+
+// PrefixSize returns the total size in bytes of the objects under prefix
+func (c *Client) PrefixSize(ctx context.Context, prefix string) (int64, error) {
+	var size int64
+	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.bucketFor(prefix)),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, obj := range page.Contents {
+			size += aws.ToInt64(obj.Size)
+		}
+	}
+	return size, nil
+}
+
+// FileExists reports whether an object exists, using a HEAD request
+func (c *Client) FileExists(ctx context.Context, key string) (bool, error) {
+	_, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucketFor(key)),
+		Key:    aws.String(key),
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ObjectInfo is what a HEAD request reports about an object
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// HeadObject returns an object's size and content type; nil when the
+// object does not exist
+func (c *Client) HeadObject(ctx context.Context, key string) (*ObjectInfo, error) {
+	out, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucketFor(key)),
+		Key:    aws.String(key),
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to head object: %w", err)
+	}
+	return &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(out.ContentLength),
+		ContentType:  aws.ToString(out.ContentType),
+		LastModified: aws.ToTime(out.LastModified),
+	}, nil
+}
+
+// GetHLSVariantPrefix returns the key prefix of a rendition variant's files
+func GetHLSVariantPrefix(filmID uuid.UUID, variant string) string {
+	return fmt.Sprintf("%s/%s/%s/", HLSPath, filmID, variant)
+}
+
 // ========== PUBLIC URL GENERATION ==========
 
 // GetPublicURL returns the public URL for a file in R2
@@ -194,14 +455,97 @@ func (c *Client) GetHLSMasterURL(filmID uuid.UUID) string {
 	return c.GetPublicURL(key)
 }
 
+// GetHLSVariantMasterURL returns the master playlist URL for a rendition variant
+func (c *Client) GetHLSVariantMasterURL(filmID uuid.UUID, variant string) string {
+	return c.GetPublicURL(GetHLSVariantMasterKey(filmID, variant))
+}
+
+// GetHLSVariantMasterKey returns the storage key of a rendition variant's
+// master playlist
+func GetHLSVariantMasterKey(filmID uuid.UUID, variant string) string {
+	if variant == "" || variant == "default" {
+		return fmt.Sprintf("%s/%s/master.m3u8", HLSPath, filmID)
+	}
+	return fmt.Sprintf("%s/%s/%s/master.m3u8", HLSPath, filmID, variant)
+}
+
+// GetSubtitleKey returns the storage key of a film's WebVTT subtitle track
+func GetSubtitleKey(filmID uuid.UUID, language string) string {
+	return fmt.Sprintf("%s/%s/%s.vtt", SubtitlePath, filmID, language)
+}
+
+// GetOriginalKey returns the key of a film's uploaded source
+func GetOriginalKey(filmID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s/source.mp4", OriginalPath, filmID)
+}
+
+// GetQuarantineKey returns the key an infected upload is moved to
+func GetQuarantineKey(filmID, scanID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s/%s", QuarantinePath, filmID, scanID)
+}
+
+// GetSpriteKey returns the storage key of a film's thumbnail sprite sheet
+func GetSpriteKey(filmID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s/sprite.jpg", ThumbnailPath, filmID)
+}
+
+// GetReplacementAudioKey returns the storage key of a film's uploaded
+// replacement audio track
+func GetReplacementAudioKey(filmID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s/replacement-audio", OriginalPath, filmID)
+}
+
+// GetLUTKey returns the storage key of a LUT slot: luts/platform.cube,
+// luts/creator/{id}/lut.cube or luts/film/{id}/lut.cube
+func GetLUTKey(scope string, ownerID uuid.UUID) string {
+	if scope == "platform" {
+		return fmt.Sprintf("%s/platform.cube", LUTPath)
+	}
+	return fmt.Sprintf("%s/%s/%s/lut.cube", LUTPath, scope, ownerID)
+}
+
+// GetLUTPreviewKey returns the storage key of a LUT preview frame
+func GetLUTPreviewKey(filmID uuid.UUID, index int, graded bool) string {
+	kind := "original"
+	if graded {
+		kind = "graded"
+	}
+	return fmt.Sprintf("%s/%s/lut-preview-%d-%s.jpg", ThumbnailPath, filmID, index, kind)
+}
+
+// GetFeaturedArtworkPrefix returns the key prefix under which a featured
+// slot's hero artwork is stored
+func GetFeaturedArtworkPrefix(featuredID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s/", FeaturedPath, featuredID)
+}
+
+// GetFeaturedArtworkKey returns a fresh storage key for a featured slot's
+// hero artwork; each upload gets its own key so CDN caches never go stale
+func GetFeaturedArtworkKey(featuredID uuid.UUID, ext string) string {
+	return fmt.Sprintf("%s%s%s", GetFeaturedArtworkPrefix(featuredID), uuid.New(), ext)
+}
+
+// GetPurchaseDownloadKey returns the storage key of a purchase's
+// watermarked MP4
+func GetPurchaseDownloadKey(filmID, purchaseID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s/%s.mp4", DownloadPath, filmID, purchaseID)
+}
+
+// GetCommentImportKey returns the storage key of a comment archive
+// uploaded for an import task
+func GetCommentImportKey(filmID, taskID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s/import-%s.json", CommentPath, filmID, taskID)
+}
+
+// GetCommentExportKey returns the storage key of a comment archive written
+// by an export task
+func GetCommentExportKey(filmID, taskID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s/export-%s.json", CommentPath, filmID, taskID)
+}
+
 // GetThumbnailURL returns the public thumbnail URL for a film
 func (c *Client) GetThumbnailURL(filmID uuid.UUID) string {
 	key := fmt.Sprintf("%s/%s/poster.jpg", ThumbnailPath, filmID)
 	return c.GetPublicURL(key)
 }
 
-// GetOriginalVideoURL returns the public URL for original video (if accessible)
-func (c *Client) GetOriginalVideoURL(filmID uuid.UUID) string {
-	key := fmt.Sprintf("%s/%s/source.mp4", OriginalPath, filmID)
-	return c.GetPublicURL(key)
-}
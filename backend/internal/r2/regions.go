@@ -0,0 +1,68 @@
+package r2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseSourceBuckets parses a comma-separated list of region=bucket pairs
+func ParseSourceBuckets(value string) (map[string]string, error) {
+	buckets := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		region, bucket, ok := strings.Cut(pair, "=")
+		region, bucket = strings.TrimSpace(region), strings.TrimSpace(bucket)
+		if !ok || region == "" || bucket == "" {
+			return nil, fmt.Errorf("invalid source bucket %q, want region=bucket", pair)
+		}
+		buckets[region] = bucket
+	}
+	return buckets, nil
+}
+
+// SetSourceRegions configures the regional buckets uploaded sources are
+// stored in, and the region whose bucket this client's uploads go to ("" for
+// the primary bucket). Call it before the client is shared.
+func (c *Client) SetSourceRegions(uploadRegion string, buckets map[string]string) error {
+	if _, ok := buckets[uploadRegion]; uploadRegion != "" && !ok {
+		return fmt.Errorf("no source bucket configured for region %q", uploadRegion)
+	}
+	c.sourceRegion = uploadRegion
+	c.sourceBuckets = buckets
+	return nil
+}
+
+// SourceRegion returns the region new sources are uploaded to, or "" for
+// the primary bucket
+func (c *Client) SourceRegion() string {
+	return c.sourceRegion
+}
+
+// SourceRegions returns every region with a source bucket, starting with
+// "" for the primary bucket
+func (c *Client) SourceRegions() []string {
+	regions := []string{""}
+	for region := range c.sourceBuckets {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions[1:])
+	return regions
+}
+
+// ForRegion returns a client for the source bucket of a region, sharing
+// this client's connection. Sources without a region, or in a region with
+// no configured bucket, are in the primary bucket.
+func (c *Client) ForRegion(region string) *Client {
+	bucket, ok := c.sourceBuckets[region]
+	if region == "" || !ok || bucket == c.bucket {
+		return c
+	}
+	regional := *c
+	regional.bucket = bucket
+	regional.privateBucket = bucket
+	return &regional
+}
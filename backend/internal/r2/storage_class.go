@@ -0,0 +1,82 @@
+package r2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// StorageClassInfrequentAccess is R2's cheaper storage class for objects
+// that are rarely read
+const StorageClassInfrequentAccess = types.StorageClassStandardIa
+
+// maxCopySize is the largest object a single CopyObject request copies
+const maxCopySize = 5 << 30
+
+// SetStorageClass moves an object to another storage class by copying it
+// onto itself, in parts when it is too large for a single copy
+func (c *Client) SetStorageClass(ctx context.Context, key string, class types.StorageClass) error {
+	bucket := c.bucketFor(key)
+	source := bucket + "/" + key
+
+	info, err := c.HeadObject(ctx, key)
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return fmt.Errorf("object %s does not exist", key)
+	}
+
+	if info.Size <= maxCopySize {
+		_, err := c.client.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:            aws.String(bucket),
+			Key:               aws.String(key),
+			CopySource:        aws.String(source),
+			StorageClass:      class,
+			MetadataDirective: types.MetadataDirectiveCopy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to copy object: %w", err)
+		}
+		return nil
+	}
+
+	created, err := c.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ContentType:  aws.String(info.ContentType),
+		StorageClass: class,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create multipart copy: %w", err)
+	}
+	uploadID := aws.ToString(created.UploadId)
+
+	partSize := PartSize(info.Size)
+	parts := []UploadedPart{}
+	for start, number := int64(0), 1; start < info.Size; start, number = start+partSize, number+1 {
+		end := min(start+partSize, info.Size) - 1
+		out, err := c.client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(bucket),
+			Key:             aws.String(key),
+			UploadId:        aws.String(uploadID),
+			PartNumber:      aws.Int32(int32(number)),
+			CopySource:      aws.String(source),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		})
+		if err != nil {
+			c.AbortMultipartUpload(ctx, key, uploadID)
+			return fmt.Errorf("failed to copy part %d: %w", number, err)
+		}
+		parts = append(parts, UploadedPart{PartNumber: number, ETag: aws.ToString(out.CopyPartResult.ETag)})
+	}
+
+	if err := c.CompleteMultipartUpload(ctx, key, uploadID, parts); err != nil {
+		c.AbortMultipartUpload(ctx, key, uploadID)
+		return err
+	}
+	return nil
+}
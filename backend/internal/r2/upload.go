@@ -0,0 +1,69 @@
+package r2
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/google/uuid"
+)
+
+// UploadLimits tune multipart uploads. Zero fields keep the uploader's
+// defaults of 5 MiB parts, 5 at a time.
+type UploadLimits struct {
+	// PartSize is the size of each part; files no larger go up in one
+	// request. Raised as needed to keep a file within 10,000 parts.
+	PartSize int64
+	// Concurrency is how many parts of one file are uploaded at once
+	Concurrency int
+}
+
+// SetUploadLimits tunes the part size and concurrency of multipart
+// uploads. Call it before the client is shared.
+func (c *Client) SetUploadLimits(limits UploadLimits) error {
+	if limits.PartSize != 0 && limits.PartSize < manager.MinUploadPartSize {
+		return fmt.Errorf("upload part size must be at least %d bytes", manager.MinUploadPartSize)
+	}
+	if limits.Concurrency < 0 {
+		return fmt.Errorf("upload concurrency must not be negative")
+	}
+	c.uploadLimits = limits
+	c.uploader = c.newUploader()
+	return nil
+}
+
+// UploadLimits returns the client's multipart upload limits
+func (c *Client) UploadLimits() UploadLimits {
+	return c.uploadLimits
+}
+
+// newUploader creates an uploader with the client's upload limits
+func (c *Client) newUploader() *manager.Uploader {
+	return manager.NewUploader(c.client, func(u *manager.Uploader) {
+		if c.uploadLimits.PartSize > 0 {
+			u.PartSize = c.uploadLimits.PartSize
+		}
+		if c.uploadLimits.Concurrency > 0 {
+			u.Concurrency = c.uploadLimits.Concurrency
+		}
+	})
+}
+
+// UploadLocalFile streams a file on disk to R2. Parts are read from the
+// file as they are sent rather than buffered, so only the parts in flight
+// are in memory.
+func (c *Client) UploadLocalFile(ctx context.Context, key, path, contentType string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.UploadFile(ctx, key, f, contentType)
+}
+
+// UploadHLSLocalFile streams an HLS playlist or segment on disk to R2
+func (c *Client) UploadHLSLocalFile(ctx context.Context, filmID uuid.UUID, quality, filename, path string) error {
+	key := fmt.Sprintf("%s/%s/%s/%s", HLSPath, filmID, quality, filename)
+	return c.UploadLocalFile(ctx, key, path, hlsContentType(filename))
+}
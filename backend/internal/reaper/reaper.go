@@ -0,0 +1,206 @@
+// Package reaper cleans up abandoned uploads: films left in DRAFT or
+// UPLOADED without a confirmed upload are expired and their partial
+// sources deleted, and multipart uploads nobody will finish are aborted.
+package reaper
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/google/uuid"
+)
+
+const (
+	reapLock = "upload-reaper"
+
+	// batchSize bounds how many films one run expires
+	batchSize = 200
+)
+
+// Result counts what a run cleaned up
+type Result struct {
+	Expired        int `json:"expired"`
+	AbortedUploads int `json:"aborted_uploads"`
+	DeletedSources int `json:"deleted_sources"`
+}
+
+// Reaper expires abandoned films and deletes what their uploads left in
+// storage
+type Reaper struct {
+	queries  *db.Queries
+	redis    *redis.Client
+	r2Client *r2.Client
+	settings *settings.Service
+	token    string
+}
+
+// New creates an upload reaper
+func New(queries *db.Queries, redisClient *redis.Client, r2Client *r2.Client, settingsService *settings.Service) *Reaper {
+	return &Reaper{
+		queries:  queries,
+		redis:    redisClient,
+		r2Client: r2Client,
+		settings: settingsService,
+		token:    uuid.New().String(),
+	}
+}
+
+// RunOnce expires the films untouched for the upload.abandoned_after_days
+// setting that never had an upload confirmed, then aborts the multipart
+// uploads of sources started before then that no film is waiting on
+func (r *Reaper) RunOnce(ctx context.Context) (*Result, error) {
+	days := r.settings.Int(ctx, settings.KeyAbandonedUploadDays)
+	cutoff := time.Now().AddDate(0, 0, -int(days))
+	result := &Result{}
+
+	films, err := r.queries.ListAbandonedFilms(ctx, cutoff, batchSize)
+	if err != nil {
+		return result, err
+	}
+	for _, film := range films {
+		if err := r.expire(ctx, film, cutoff, result); err != nil {
+			return result, err
+		}
+	}
+
+	for _, region := range r.r2Client.SourceRegions() {
+		if err := r.abortStaleUploads(ctx, region, cutoff, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// expire marks a film EXPIRED, then discards its multipart upload and any
+// source it stored
+func (r *Reaper) expire(ctx context.Context, film models.AbandonedFilm, cutoff time.Time, result *Result) error {
+	upload, err := r.redis.GetMultipartUpload(ctx, film.ID)
+	if err != nil {
+		log.Printf("[Reaper] Failed to get multipart upload of film %s: %v", film.ID, err)
+	}
+
+	ok, err := r.queries.ExpireFilm(ctx, film.ID, cutoff)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	result.Expired++
+	if err := r.redis.SetFilmStatus(ctx, film.ID, models.StatusExpired); err != nil {
+		log.Printf("[Reaper] Failed to cache status of film %s: %v", film.ID, err)
+	}
+
+	if upload != nil {
+		if err := r.r2Client.ForRegion(upload.Region).AbortMultipartUpload(ctx, upload.Key, upload.UploadID); err != nil {
+			log.Printf("[Reaper] Failed to abort multipart upload of film %s: %v", film.ID, err)
+		} else {
+			result.AbortedUploads++
+		}
+		if err := r.redis.ClearMultipartUpload(ctx, film.ID); err != nil {
+			log.Printf("[Reaper] Failed to clear multipart upload of film %s: %v", film.ID, err)
+		}
+	}
+
+	// A single upload may have stored a source that was never confirmed
+	store := r.r2Client.ForRegion(film.SourceRegion)
+	key := r2.GetOriginalKey(film.ID)
+	exists, err := store.FileExists(ctx, key)
+	if err != nil {
+		log.Printf("[Reaper] Failed to check source of film %s: %v", film.ID, err)
+		return nil
+	}
+	if exists {
+		if err := store.DeleteFile(ctx, key); err != nil {
+			log.Printf("[Reaper] Failed to delete source of film %s: %v", film.ID, err)
+			return nil
+		}
+		result.DeletedSources++
+	}
+	return nil
+}
+
+// abortStaleUploads aborts a region's multipart uploads of sources started
+// before cutoff, except one a film still has on record. These are left
+// behind when the upload's record expires before anyone aborts it.
+func (r *Reaper) abortStaleUploads(ctx context.Context, region string, cutoff time.Time, result *Result) error {
+	store := r.r2Client.ForRegion(region)
+	uploads, err := store.ListPendingUploads(ctx, r2.OriginalPath+"/")
+	if err != nil {
+		return err
+	}
+	for _, pending := range uploads {
+		if pending.Initiated.After(cutoff) {
+			continue
+		}
+		// Keys are original/<film id>/source.mp4
+		parts := strings.Split(pending.Key, "/")
+		if len(parts) < 2 {
+			continue
+		}
+		if filmID, err := uuid.Parse(parts[1]); err == nil {
+			upload, err := r.redis.GetMultipartUpload(ctx, filmID)
+			if err != nil {
+				log.Printf("[Reaper] Failed to get multipart upload of film %s: %v", filmID, err)
+				continue
+			}
+			if upload != nil && upload.UploadID == pending.UploadID {
+				continue
+			}
+		}
+		if err := store.AbortMultipartUpload(ctx, pending.Key, pending.UploadID); err != nil {
+			log.Printf("[Reaper] Failed to abort multipart upload %s: %v", pending.Key, err)
+			continue
+		}
+		result.AbortedUploads++
+	}
+	return nil
+}
+
+// RunLoop reaps abandoned uploads on every interval. A Redis lock keeps a
+// single instance reaping at a time. It blocks until ctx is cancelled.
+func (r *Reaper) RunLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reap(ctx, interval)
+		}
+	}
+}
+
+func (r *Reaper) reap(ctx context.Context, interval time.Duration) {
+	ok, err := r.redis.AcquireLock(ctx, reapLock, r.token, interval)
+	if err != nil {
+		log.Printf("[Reaper] Failed to acquire reaper lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := r.redis.ReleaseLock(context.Background(), reapLock, r.token); err != nil {
+			log.Printf("[Reaper] Failed to release reaper lock: %v", err)
+		}
+	}()
+
+	result, err := r.RunOnce(ctx)
+	if err != nil {
+		log.Printf("[Reaper] Failed to reap abandoned uploads: %v", err)
+	}
+	if result.Expired > 0 || result.AbortedUploads > 0 {
+		log.Printf("[Reaper] Expired %d films, aborted %d multipart uploads, deleted %d sources",
+			result.Expired, result.AbortedUploads, result.DeletedSources)
+	}
+}
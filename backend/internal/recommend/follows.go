@@ -0,0 +1,47 @@
+package recommend
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// NewFromFollowsWindow is how far back the "new from creators you
+	// follow" rail looks
+	NewFromFollowsWindow = 7 * 24 * time.Hour
+
+	// newFromFollowsMax caps the rail; it is a shelf, not the full feed
+	newFromFollowsMax = 50
+
+	// newFromFollowsTTL bounds how long a new release takes to appear
+	newFromFollowsTTL = 10 * time.Minute
+)
+
+// NewFromFollows returns films published in the last week by creators
+// the user follows, newest first. Lists are cached per user.
+func (e *Engine) NewFromFollows(ctx context.Context, userID uuid.UUID) ([]models.Film, error) {
+	if cached, err := e.redis.GetNewFromFollows(ctx, userID); err == nil {
+		return cached, nil
+	}
+
+	films, err := e.queries.GetNewFromFollows(ctx, userID, time.Now().Add(-NewFromFollowsWindow), newFromFollowsMax)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.redis.SetNewFromFollows(ctx, userID, films, newFromFollowsTTL); err != nil {
+		log.Printf("[Recommend] Failed to cache new-from-follows for %s: %v", userID, err)
+	}
+	return films, nil
+}
+
+// FollowsChanged drops the user's cached rail so follow changes show up
+// immediately
+func (e *Engine) FollowsChanged(ctx context.Context, userID uuid.UUID) {
+	if err := e.redis.InvalidateNewFromFollows(ctx, userID); err != nil {
+		log.Printf("[Recommend] Failed to invalidate new-from-follows for %s: %v", userID, err)
+	}
+}
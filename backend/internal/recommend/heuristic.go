@@ -0,0 +1,147 @@
+package recommend
+
+import (
+	"context"
+	"sort"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// perSignal is how many candidates each signal contributes before merging
+const perSignal = 100
+
+// Feature weights of the heuristic ranking
+var heuristicWeights = map[string]float64{
+	FeatureCoView: 0.5,
+	FeatureGenre:  0.3,
+	FeatureFollow: 0.2,
+}
+
+// featureReasons maps features to the reason shown when they dominate
+var featureReasons = map[string]models.RecommendationReason{
+	FeatureCoView: models.ReasonCoView,
+	FeatureGenre:  models.ReasonGenre,
+	FeatureFollow: models.ReasonFollow,
+}
+
+// Heuristic is the built-in recommender: candidates come from co-views,
+// genre affinity and creator follows, ranked by a weighted blend of them
+type Heuristic struct {
+	queries *db.Queries
+}
+
+// NewHeuristic creates the built-in recommender
+func NewHeuristic(queries *db.Queries) *Heuristic {
+	return &Heuristic{queries: queries}
+}
+
+// Name identifies the heuristic in feature logs
+func (h *Heuristic) Name() string {
+	return RecommenderHeuristic
+}
+
+// Generate merges the co-view, genre and follow signals into candidates
+// with one feature per signal
+func (h *Heuristic) Generate(ctx context.Context, userID uuid.UUID) ([]Candidate, error) {
+	coView, err := h.queries.CoViewCandidates(ctx, userID, perSignal)
+	if err != nil {
+		return nil, err
+	}
+	genre, err := h.queries.GenreAffinityCandidates(ctx, userID, perSignal)
+	if err != nil {
+		return nil, err
+	}
+	follow, err := h.queries.FollowedCreatorCandidates(ctx, userID, perSignal)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[uuid.UUID]*Candidate{}
+	var order []uuid.UUID
+	add := func(signal []db.ScoredFilm, feature string) {
+		max := 0.0
+		for _, s := range signal {
+			if s.Score > max {
+				max = s.Score
+			}
+		}
+		if max == 0 {
+			return
+		}
+		for _, s := range signal {
+			c, ok := merged[s.FilmID]
+			if !ok {
+				c = &Candidate{FilmID: s.FilmID, Features: models.RecommendationFeatures{}}
+				merged[s.FilmID] = c
+				order = append(order, s.FilmID)
+			}
+			c.Features[feature] = s.Score / max
+			if s.SeedFilmID != nil {
+				if c.Seeds == nil {
+					c.Seeds = map[string]uuid.UUID{}
+				}
+				c.Seeds[feature] = *s.SeedFilmID
+			}
+		}
+	}
+	add(coView, FeatureCoView)
+	add(genre, FeatureGenre)
+	add(follow, FeatureFollow)
+
+	candidates := make([]Candidate, 0, len(order))
+	for _, id := range order {
+		candidates = append(candidates, *merged[id])
+	}
+	return candidates, nil
+}
+
+// Rank scores each candidate by its weighted features
+func (h *Heuristic) Rank(ctx context.Context, userID uuid.UUID, candidates []Candidate) ([]models.RecommendationCandidate, error) {
+	ranked := make([]models.RecommendationCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		score := 0.0
+		for feature, value := range c.Features {
+			score += heuristicWeights[feature] * value
+		}
+		ranked = append(ranked, explain(c, score))
+	}
+	return sortCandidates(ranked), nil
+}
+
+// explain scores a candidate with the reason of the feature contributing
+// most to it under the heuristic weights. A film's "because you watched"
+// seed comes with that feature.
+func explain(c Candidate, score float64) models.RecommendationCandidate {
+	out := models.RecommendationCandidate{FilmID: c.FilmID, Score: score}
+	best := 0.0
+	// Fixed order so ties resolve the same way every time
+	for _, feature := range []string{FeatureCoView, FeatureGenre, FeatureFollow} {
+		contribution := heuristicWeights[feature] * c.Features[feature]
+		if contribution <= best {
+			continue
+		}
+		best = contribution
+		out.Reason = featureReasons[feature]
+		out.BecauseOf = nil
+		if seed, ok := c.Seeds[feature]; ok {
+			out.BecauseOf = &seed
+		}
+	}
+	return out
+}
+
+// sortCandidates orders candidates best first and keeps MaxCandidates
+func sortCandidates(candidates []models.RecommendationCandidate) []models.RecommendationCandidate {
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].FilmID.String() < candidates[j].FilmID.String()
+	})
+	if len(candidates) > MaxCandidates {
+		candidates = candidates[:MaxCandidates]
+	}
+	return candidates
+}
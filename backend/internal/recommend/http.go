@@ -0,0 +1,133 @@
+package recommend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// HTTP ranks candidates with an external inference service. Candidates
+// are generated locally by the heuristic, whose features are the model's
+// input; the service is sent them and returns a score per film:
+//
+//	POST {url}/rank
+//	{"user_id": "...", "limit": 50, "candidates": [{"film_id": "...", "features": {"co_view": 0.8}}]}
+//	-> {"scores": [{"film_id": "...", "score": 0.93, "reason": "genre"}]}
+//
+// reason is optional; without it, or when it is not one the app knows, the
+// reason is taken from the features.
+// Films the service did not score, or that were not sent, are dropped.
+type HTTP struct {
+	baseURL string
+	http    *http.Client
+	source  *Heuristic
+}
+
+// NewHTTP creates a recommender backed by an external inference service
+func NewHTTP(baseURL string, timeout time.Duration, source *Heuristic) *HTTP {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &HTTP{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: timeout},
+		source:  source,
+	}
+}
+
+// Name identifies the inference service in feature logs
+func (h *HTTP) Name() string {
+	return RecommenderHTTP
+}
+
+// Generate returns the heuristic's candidates and features
+func (h *HTTP) Generate(ctx context.Context, userID uuid.UUID) ([]Candidate, error) {
+	return h.source.Generate(ctx, userID)
+}
+
+// Rank asks the inference service to score the candidates
+func (h *HTTP) Rank(ctx context.Context, userID uuid.UUID, candidates []Candidate) ([]models.RecommendationCandidate, error) {
+	if len(candidates) == 0 {
+		return []models.RecommendationCandidate{}, nil
+	}
+
+	body := map[string]interface{}{
+		"user_id":    userID,
+		"limit":      MaxCandidates,
+		"candidates": candidates,
+	}
+	var resp struct {
+		Scores []struct {
+			FilmID uuid.UUID                   `json:"film_id"`
+			Score  float64                     `json:"score"`
+			Reason models.RecommendationReason `json:"reason"`
+		} `json:"scores"`
+	}
+	if err := h.do(ctx, "/rank", body, &resp); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]Candidate, len(candidates))
+	for _, c := range candidates {
+		byID[c.FilmID] = c
+	}
+	ranked := make([]models.RecommendationCandidate, 0, len(resp.Scores))
+	for _, s := range resp.Scores {
+		c, ok := byID[s.FilmID]
+		if !ok {
+			continue
+		}
+		delete(byID, s.FilmID) // a film scored twice counts once
+		r := explain(c, s.Score)
+		if knownReason(s.Reason) && s.Reason != r.Reason {
+			r.Reason = s.Reason
+			r.BecauseOf = nil
+		}
+		ranked = append(ranked, r)
+	}
+	return sortCandidates(ranked), nil
+}
+
+// knownReason reports whether the app can show reason to users
+func knownReason(reason models.RecommendationReason) bool {
+	for _, r := range featureReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// do sends a JSON request to the inference service and decodes its reply
+func (h *HTTP) do(ctx context.Context, path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("recommender request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("recommender POST %s: %d %s", path, resp.StatusCode, msg)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,179 @@
+// Package recommend builds per-user film recommendations. A Recommender
+// generates and ranks candidates: the built-in heuristic blends co-view
+// counts, genre affinity and creator follows, and an external inference
+// service can take over ranking.
+package recommend
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxCandidates is how many candidates are kept per user
+	MaxCandidates = 50
+
+	// CacheTTL bounds how stale precomputed lists may get if the batch job stops
+	CacheTTL = 24 * time.Hour
+
+	// activeWindow is how far back the batch job looks for users to refresh
+	activeWindow = 30 * 24 * time.Hour
+)
+
+// Engine computes and caches recommendation candidates
+type Engine struct {
+	queries   *db.Queries
+	redis     *redis.Client
+	settings  *settings.Service
+	model     Recommender
+	heuristic *Heuristic
+}
+
+// New creates a recommendations engine ranking with model. When model
+// fails, the heuristic ranks its candidates instead.
+func New(queries *db.Queries, redisClient *redis.Client, settingsService *settings.Service, model Recommender) *Engine {
+	return &Engine{
+		queries:   queries,
+		redis:     redisClient,
+		settings:  settingsService,
+		model:     model,
+		heuristic: NewHeuristic(queries),
+	}
+}
+
+// Candidates returns the user's cached candidates, computing and caching
+// them on a miss (e.g. a user who became active since the last batch run)
+func (e *Engine) Candidates(ctx context.Context, userID uuid.UUID) ([]models.RecommendationCandidate, error) {
+	if cached, err := e.redis.GetRecommendations(ctx, userID); err == nil {
+		return cached, nil
+	}
+	return e.Refresh(ctx, userID)
+}
+
+// Refresh recomputes a user's candidates and stores them in Redis
+func (e *Engine) Refresh(ctx context.Context, userID uuid.UUID) ([]models.RecommendationCandidate, error) {
+	candidates, err := e.Compute(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.redis.SetRecommendations(ctx, userID, candidates, CacheTTL); err != nil {
+		log.Printf("[Recommend] Failed to cache recommendations for %s: %v", userID, err)
+	}
+	return candidates, nil
+}
+
+// Compute generates and ranks a user's candidates with the configured
+// model, logging them as training data when feature logging is on
+func (e *Engine) Compute(ctx context.Context, userID uuid.UUID) ([]models.RecommendationCandidate, error) {
+	candidates, err := e.model.Generate(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	model := e.model.Name()
+	ranked, err := e.model.Rank(ctx, userID, candidates)
+	if err != nil {
+		if model == RecommenderHeuristic {
+			return nil, err
+		}
+		log.Printf("[Recommend] %s recommender failed for %s, using the heuristic: %v", model, userID, err)
+		model = RecommenderHeuristic
+		if ranked, err = e.heuristic.Rank(ctx, userID, candidates); err != nil {
+			return nil, err
+		}
+	}
+
+	if e.settings.Bool(ctx, settings.KeyFeatureLogging) {
+		e.logFeatures(ctx, userID, model, candidates, ranked)
+	}
+	return ranked, nil
+}
+
+// logFeatures records every candidate of a computed list with its
+// features, rank and score. Failures are logged; recommendations are
+// served regardless.
+func (e *Engine) logFeatures(ctx context.Context, userID uuid.UUID, model string, candidates []Candidate, ranked []models.RecommendationCandidate) {
+	type placement struct {
+		rank  int
+		score float64
+	}
+	placed := make(map[uuid.UUID]placement, len(ranked))
+	for i, r := range ranked {
+		placed[r.FilmID] = placement{rank: i + 1, score: r.Score}
+	}
+
+	listID := uuid.New()
+	now := time.Now()
+	logs := make([]models.RecommendationFeatureLog, 0, len(candidates))
+	for _, c := range candidates {
+		entry := models.RecommendationFeatureLog{
+			ListID:    listID,
+			UserID:    userID,
+			FilmID:    c.FilmID,
+			Model:     model,
+			Features:  c.Features,
+			CreatedAt: now,
+		}
+		if p, ok := placed[c.FilmID]; ok {
+			entry.Rank = &p.rank
+			entry.Score = &p.score
+		}
+		logs = append(logs, entry)
+	}
+
+	if err := e.queries.InsertRecommendationFeatureLogs(ctx, logs); err != nil {
+		log.Printf("[Recommend] Failed to log features for %s: %v", userID, err)
+	}
+}
+
+// RunBatch periodically precomputes candidate lists for recently active
+// users. It blocks until ctx is cancelled.
+func (e *Engine) RunBatch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.refreshActive(ctx)
+		}
+	}
+}
+
+func (e *Engine) refreshActive(ctx context.Context) {
+	userIDs, err := e.queries.ListActiveViewers(ctx, time.Now().Add(-activeWindow))
+	if err != nil {
+		log.Printf("[Recommend] Failed to list active viewers: %v", err)
+		return
+	}
+
+	refreshed := 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := e.Refresh(ctx, userID); err != nil {
+			log.Printf("[Recommend] Failed to refresh recommendations for %s: %v", userID, err)
+			continue
+		}
+		refreshed++
+	}
+	log.Printf("[Recommend] Refreshed recommendations for %d users", refreshed)
+
+	days := e.settings.Int(ctx, settings.KeyFeatureLogDays)
+	cutoff := time.Now().AddDate(0, 0, -int(days))
+	if deleted, err := e.queries.DeleteRecommendationFeatureLogsBefore(ctx, cutoff); err != nil {
+		log.Printf("[Recommend] Failed to delete old feature logs: %v", err)
+	} else if deleted > 0 {
+		log.Printf("[Recommend] Deleted %d feature logs older than %d days", deleted, days)
+	}
+}
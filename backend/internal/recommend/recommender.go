@@ -0,0 +1,67 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// Recommender names
+const (
+	RecommenderHeuristic = "heuristic"
+	RecommenderHTTP      = "http"
+)
+
+// Feature names. Each is normalised to 0..1 across a user's candidates.
+const (
+	FeatureCoView = "co_view"
+	FeatureGenre  = "genre"
+	FeatureFollow = "follow"
+)
+
+// Candidate is a film that may be recommended to a user, with the
+// features it is ranked on
+type Candidate struct {
+	FilmID   uuid.UUID                     `json:"film_id"`
+	Features models.RecommendationFeatures `json:"features"`
+	// Seeds are the films behind a feature, such as the watched film a
+	// co-view pick came from
+	Seeds map[string]uuid.UUID `json:"-"`
+}
+
+// Recommender is implemented by every recommendation model
+type Recommender interface {
+	// Name identifies the model in feature logs
+	Name() string
+	// Generate returns the films worth ranking for a user
+	Generate(ctx context.Context, userID uuid.UUID) ([]Candidate, error)
+	// Rank scores candidates and returns at most MaxCandidates of them,
+	// best first, each with the reason shown to the user
+	Rank(ctx context.Context, userID uuid.UUID, candidates []Candidate) ([]models.RecommendationCandidate, error)
+}
+
+// Config selects and configures a recommender
+type Config struct {
+	Recommender string
+	URL         string
+	Timeout     time.Duration
+}
+
+// NewRecommender creates the configured recommender
+func NewRecommender(cfg Config, queries *db.Queries) (Recommender, error) {
+	switch cfg.Recommender {
+	case "", RecommenderHeuristic:
+		return NewHeuristic(queries), nil
+	case RecommenderHTTP:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("the http recommender needs a URL")
+		}
+		return NewHTTP(cfg.URL, cfg.Timeout, NewHeuristic(queries)), nil
+	default:
+		return nil, fmt.Errorf("unknown recommender %q", cfg.Recommender)
+	}
+}
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// DeferredTranscodeQueue holds the films whose upload was confirmed while
+// transcoding was saturated, oldest first
+const DeferredTranscodeQueue = "filmtube:transcode:deferred"
+
+// ========== TRANSCODE ADMISSION ==========
+
+// TranscodeQueueDepth counts the jobs waiting in the shared transcode
+// queue and the queue of every region
+func (c *Client) TranscodeQueueDepth(ctx context.Context) (int64, error) {
+	regions, err := c.SMembers(ctx, WorkerRegionsKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	depth, err := c.LLen(ctx, TranscodeQueue).Result()
+	if err != nil {
+		return 0, err
+	}
+	for _, region := range regions {
+		n, err := c.LLen(ctx, fmt.Sprintf(RegionTranscodeQueue, region)).Result()
+		if err != nil {
+			return 0, err
+		}
+		depth += n
+	}
+	return depth, nil
+}
+
+// DeferTranscodeJob adds a film to the deferred queue and returns its
+// position, counting from 1
+func (c *Client) DeferTranscodeJob(ctx context.Context, filmID uuid.UUID) (int64, error) {
+	return c.RPush(ctx, DeferredTranscodeQueue, filmID.String()).Result()
+}
+
+// NextDeferredTranscodeJob removes and returns the oldest deferred film,
+// or uuid.Nil when none is waiting
+func (c *Client) NextDeferredTranscodeJob(ctx context.Context) (uuid.UUID, error) {
+	value, err := c.LPop(ctx, DeferredTranscodeQueue).Result()
+	if err == redis.Nil {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	filmID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid film ID in deferred queue: %w", err)
+	}
+	return filmID, nil
+}
+
+// DeferredTranscodeJobs counts the deferred films
+func (c *Client) DeferredTranscodeJobs(ctx context.Context) (int64, error) {
+	return c.LLen(ctx, DeferredTranscodeQueue).Result()
+}
@@ -0,0 +1,144 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	APIKeyRateKey       = "filmtube:apikey:rate:%s:%d"  // per key and unix minute
+	APIKeyQuotaKey      = "filmtube:apikey:quota:%s:%s" // per key and UTC date
+	APIKeyUsageKey      = "filmtube:apikey:usage:%s:%d" // per key and unix hour
+	DirtyAPIKeyUsageKey = "filmtube:apikey:usage:dirty"
+)
+
+// APIKeyUsageDelta is usage counted in Redis for one key and hour, not yet
+// flushed to Postgres
+type APIKeyUsageDelta struct {
+	KeyID uuid.UUID
+	Usage models.APIKeyUsage
+}
+
+// ========== API KEY OPERATIONS ==========
+
+// CountAPIKeyRequest counts a request against an API key's rate limit and
+// daily quota and returns the counts of the current minute and UTC day
+func (c *Client) CountAPIKeyRequest(ctx context.Context, keyID uuid.UUID, now time.Time) (minute, day int64, err error) {
+	minuteKey, dayKey := apiKeyCounterKeys(keyID, now)
+
+	pipe := c.TxPipeline()
+	minuteCount := pipe.Incr(ctx, minuteKey)
+	pipe.Expire(ctx, minuteKey, 2*time.Minute)
+	dayCount := pipe.Incr(ctx, dayKey)
+	pipe.Expire(ctx, dayKey, 48*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+	return minuteCount.Val(), dayCount.Val(), nil
+}
+
+// GetAPIKeyCounts returns an API key's request counts of the current
+// minute and UTC day
+func (c *Client) GetAPIKeyCounts(ctx context.Context, keyID uuid.UUID, now time.Time) (minute, day int64, err error) {
+	minuteKey, dayKey := apiKeyCounterKeys(keyID, now)
+
+	values, err := c.MGet(ctx, minuteKey, dayKey).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	counts := make([]int64, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			counts[i], _ = strconv.ParseInt(s, 10, 64)
+		}
+	}
+	return counts[0], counts[1], nil
+}
+
+func apiKeyCounterKeys(keyID uuid.UUID, now time.Time) (minute, day string) {
+	now = now.UTC()
+	return fmt.Sprintf(APIKeyRateKey, keyID, now.Unix()/60),
+		fmt.Sprintf(APIKeyQuotaKey, keyID, now.Format("2006-01-02"))
+}
+
+// AddAPIKeyUsage adds to an API key's usage of the hour of usage.Period
+// and marks it for flushing to Postgres
+func (c *Client) AddAPIKeyUsage(ctx context.Context, keyID uuid.UUID, usage models.APIKeyUsage) error {
+	hour := usage.Period.Unix() / 3600
+	key := fmt.Sprintf(APIKeyUsageKey, keyID, hour)
+
+	pipe := c.TxPipeline()
+	for field, n := range map[string]int64{
+		"requests":      usage.Requests,
+		"client_errors": usage.ClientErrors,
+		"server_errors": usage.ServerErrors,
+		"rate_limited":  usage.RateLimited,
+		"latency_ms":    usage.LatencyMs,
+	} {
+		if n != 0 {
+			pipe.HIncrBy(ctx, key, field, n)
+		}
+	}
+	pipe.Expire(ctx, key, 7*24*time.Hour)
+	pipe.SAdd(ctx, DirtyAPIKeyUsageKey, keyID.String()+":"+strconv.FormatInt(hour, 10))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// PopAPIKeyUsage takes up to count keys' hourly usage not yet flushed to
+// Postgres, removing it from Redis. Usage that fails to flush must be
+// added back with AddAPIKeyUsage.
+func (c *Client) PopAPIKeyUsage(ctx context.Context, count int) ([]APIKeyUsageDelta, error) {
+	members, err := c.SPopN(ctx, DirtyAPIKeyUsageKey, int64(count)).Result()
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+
+	deltas := make([]APIKeyUsageDelta, 0, len(members))
+	for _, member := range members {
+		id, hourValue, ok := strings.Cut(member, ":")
+		if !ok {
+			continue
+		}
+		keyID, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		hour, err := strconv.ParseInt(hourValue, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		key := fmt.Sprintf(APIKeyUsageKey, keyID, hour)
+		pipe := c.TxPipeline()
+		fields := pipe.HGetAll(ctx, key)
+		pipe.Del(ctx, key)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return deltas, err
+		}
+
+		values := fields.Val()
+		n := func(field string) int64 {
+			v, _ := strconv.ParseInt(values[field], 10, 64)
+			return v
+		}
+		deltas = append(deltas, APIKeyUsageDelta{
+			KeyID: keyID,
+			Usage: models.APIKeyUsage{
+				Period:       time.Unix(hour*3600, 0).UTC(),
+				Requests:     n("requests"),
+				ClientErrors: n("client_errors"),
+				ServerErrors: n("server_errors"),
+				RateLimited:  n("rate_limited"),
+				LatencyMs:    n("latency_ms"),
+			},
+		})
+	}
+	return deltas, nil
+}
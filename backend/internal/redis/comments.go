@@ -0,0 +1,33 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CommentRateMinuteKey = "filmtube:comments:rate:%s:m:%d" // per user and unix minute
+	CommentRateHourKey   = "filmtube:comments:rate:%s:h:%d" // per user and unix hour
+)
+
+// ========== COMMENT RATE OPERATIONS ==========
+
+// CountComment counts a comment against a user's per-minute and hourly
+// comment limits and returns the counts of the current minute and hour
+func (c *Client) CountComment(ctx context.Context, userID uuid.UUID, now time.Time) (minute, hour int64, err error) {
+	minuteKey := fmt.Sprintf(CommentRateMinuteKey, userID, now.Unix()/60)
+	hourKey := fmt.Sprintf(CommentRateHourKey, userID, now.Unix()/3600)
+
+	pipe := c.TxPipeline()
+	minuteCount := pipe.Incr(ctx, minuteKey)
+	pipe.Expire(ctx, minuteKey, 2*time.Minute)
+	hourCount := pipe.Incr(ctx, hourKey)
+	pipe.Expire(ctx, hourKey, 2*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+	return minuteCount.Val(), hourCount.Val(), nil
+}
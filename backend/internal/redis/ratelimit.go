@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const APIRateKey = "filmtube:ratelimit:api:%s:%d" // per client and unix minute
+
+// ========== API RATE OPERATIONS ==========
+
+// CountAPIRequest counts a request against a client's per-minute API rate
+// limit and returns the count of the current minute. client identifies the
+// caller, e.g. "user:{id}" or "ip:{address}".
+func (c *Client) CountAPIRequest(ctx context.Context, client string, now time.Time) (int64, error) {
+	key := fmt.Sprintf(APIRateKey, client, now.Unix()/60)
+
+	pipe := c.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, 2*time.Minute)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
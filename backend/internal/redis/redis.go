@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/arjunaayasa/filmtube/internal/models"
@@ -14,10 +16,35 @@ import (
 const (
 	// Queue names
 	TranscodeQueue = "filmtube:transcode:queue"
+	TaskQueue      = "filmtube:tasks:queue"
 
 	// Key patterns
 	TranscodeJobKey = "filmtube:transcode:job:%s"
 	FilmStatusKey   = "filmtube:film:status:%s"
+	UploadProgressKey = "filmtube:upload:progress:%s"
+	UploadPartsKey    = "filmtube:upload:parts:%s"
+	FilmCountKey      = "filmtube:films:count:%s"
+	TaskKey           = "filmtube:task:%s"
+	RecommendationsKey = "filmtube:recs:%s"
+	LockKey            = "filmtube:lock:%s"
+	NewFromFollowsKey  = "filmtube:follows:new:%s"
+	FilmFacetsKey      = "filmtube:films:facets:%s"
+	FilmMetaKey        = "filmtube:film:meta:%s"
+	RealtimeViewersKey = "filmtube:rt:viewers:%s"    // per creator
+	RealtimePlaysKey   = "filmtube:rt:plays:%s:%d"   // per creator and unix minute
+	ChaosFaultsKey     = "filmtube:chaos:faults"
+	FeedKey            = "filmtube:feed:%s"
+	PlaylistFilmsKey   = "filmtube:playlist:films:%s"
+	PositionKey        = "filmtube:position:%s:%s" // per user and film
+	DirtyPositionsKey  = "filmtube:positions:dirty"
+
+	// Streams
+	FilmEventsStream = "filmtube:events:films"
+
+	// Pub/sub channels
+	UploadProgressChannel = "filmtube:upload:progress:events:%s"
+	FilmStatusChannel     = "filmtube:film:status:events:%s"
+	SettingsChannel       = "filmtube:settings:changed"
 )
 
 type Client struct {
@@ -45,14 +72,21 @@ func New(addr, password string, db int) (*Client, error) {
 
 // ========== TRANSCODE QUEUE OPERATIONS ==========
 
-// EnqueueTranscodeJob adds a film ID to the transcode queue
+// EnqueueTranscodeJob adds a film ID to the transcode queue of its source
+// region, or the shared queue
 func (c *Client) EnqueueTranscodeJob(ctx context.Context, filmID uuid.UUID) error {
-	return c.LPush(ctx, TranscodeQueue, filmID.String()).Err()
+	region, err := c.GetFilmRegion(ctx, filmID)
+	if err != nil {
+		return err
+	}
+	queue := c.routeQueue(ctx, region, RegionTranscodeQueue, TranscodeQueue)
+	return c.LPush(ctx, queue, filmID.String()).Err()
 }
 
-// DequeueTranscodeJob removes and returns a film ID from the queue (blocking)
-func (c *Client) DequeueTranscodeJob(ctx context.Context, timeout time.Duration) (uuid.UUID, error) {
-	result, err := c.BRPop(ctx, timeout, TranscodeQueue).Result()
+// DequeueTranscodeJob removes and returns a film ID from the queue of the
+// worker's region, else the shared queue (blocking)
+func (c *Client) DequeueTranscodeJob(ctx context.Context, region string, timeout time.Duration) (uuid.UUID, error) {
+	result, err := c.BRPop(ctx, timeout, dequeueKeys(region, RegionTranscodeQueue, TranscodeQueue)...).Result()
 	if err != nil {
 		return uuid.Nil, err
 	}
@@ -65,6 +99,66 @@ func (c *Client) DequeueTranscodeJob(ctx context.Context, timeout time.Duration)
 	return filmID, nil
 }
 
+// EnqueueTask stores a worker task, labelled with its film's source
+// region, and adds it to the task queue of that region or the shared queue
+func (c *Client) EnqueueTask(ctx context.Context, task *models.WorkerTask) error {
+	if task.Region == "" && task.FilmID != uuid.Nil {
+		region, err := c.GetFilmRegion(ctx, task.FilmID)
+		if err != nil {
+			return err
+		}
+		task.Region = region
+	}
+	task.Status = models.TaskQueued
+	if err := c.SetTask(ctx, task); err != nil {
+		return err
+	}
+	queue := c.routeQueue(ctx, task.Region, RegionTaskQueue, TaskQueue)
+	return c.LPush(ctx, queue, task.ID.String()).Err()
+}
+
+// DequeueTask removes and returns the next worker task from the queue of
+// the worker's region, else the shared queue (blocking)
+func (c *Client) DequeueTask(ctx context.Context, region string, timeout time.Duration) (*models.WorkerTask, error) {
+	result, err := c.BRPop(ctx, timeout, dequeueKeys(region, RegionTaskQueue, TaskQueue)...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	taskID, err := uuid.Parse(result[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid task ID in queue: %w", err)
+	}
+
+	return c.GetTask(ctx, taskID)
+}
+
+// SetTask stores worker task state for status lookups
+func (c *Client) SetTask(ctx context.Context, task *models.WorkerTask) error {
+	task.UpdatedAt = time.Now()
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf(TaskKey, task.ID)
+	return c.Set(ctx, key, data, 7*24*time.Hour).Err()
+}
+
+// GetTask retrieves worker task state
+func (c *Client) GetTask(ctx context.Context, taskID uuid.UUID) (*models.WorkerTask, error) {
+	key := fmt.Sprintf(TaskKey, taskID)
+	data, err := c.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var task models.WorkerTask
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
 // SetTranscodeJobProgress stores job progress in Redis
 func (c *Client) SetTranscodeJobProgress(ctx context.Context, filmID uuid.UUID, job *models.TranscodeJob) error {
 	key := fmt.Sprintf(TranscodeJobKey, filmID)
@@ -92,10 +186,38 @@ func (c *Client) GetTranscodeJobProgress(ctx context.Context, filmID uuid.UUID)
 	return &job, nil
 }
 
-// SetFilmStatus caches film status in Redis
+// SetFilmStatus caches film status in Redis and notifies status stream
+// subscribers
 func (c *Client) SetFilmStatus(ctx context.Context, filmID uuid.UUID, status models.FilmStatus) error {
 	key := fmt.Sprintf(FilmStatusKey, filmID)
-	return c.Set(ctx, key, string(status), 5*time.Minute).Err()
+	if err := c.Set(ctx, key, string(status), 5*time.Minute).Err(); err != nil {
+		return err
+	}
+	return c.publishFilmStatus(ctx, &models.FilmStatusUpdate{FilmID: filmID, Status: status})
+}
+
+// PublishTranscodeProgress notifies status stream subscribers of a film's
+// transcode progress
+func (c *Client) PublishTranscodeProgress(ctx context.Context, filmID uuid.UUID, progress int) error {
+	return c.publishFilmStatus(ctx, &models.FilmStatusUpdate{
+		FilmID:   filmID,
+		Status:   models.StatusTranscoding,
+		Progress: &progress,
+	})
+}
+
+func (c *Client) publishFilmStatus(ctx context.Context, update *models.FilmStatusUpdate) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	return c.Publish(ctx, fmt.Sprintf(FilmStatusChannel, update.FilmID), data).Err()
+}
+
+// SubscribeFilmStatus subscribes to status and transcode progress updates
+// for a film
+func (c *Client) SubscribeFilmStatus(ctx context.Context, filmID uuid.UUID) *redis.PubSub {
+	return c.Subscribe(ctx, fmt.Sprintf(FilmStatusChannel, filmID))
 }
 
 // GetFilmStatus retrieves cached film status from Redis
@@ -107,3 +229,520 @@ func (c *Client) GetFilmStatus(ctx context.Context, filmID uuid.UUID) (models.Fi
 	}
 	return models.FilmStatus(result), nil
 }
+
+// SetFilmCount caches the total film count for the unfiltered listing as
+// seen from a viewer country ("" for unknown)
+func (c *Client) SetFilmCount(ctx context.Context, country string, total int) error {
+	return c.Set(ctx, filmCountKey(country), total, time.Minute).Err()
+}
+
+// GetFilmCount retrieves the cached film count for the unfiltered listing
+// as seen from a viewer country
+func (c *Client) GetFilmCount(ctx context.Context, country string) (int, error) {
+	return c.Get(ctx, filmCountKey(country)).Int()
+}
+
+func filmCountKey(country string) string {
+	if country == "" {
+		country = "unknown"
+	}
+	return fmt.Sprintf(FilmCountKey, country)
+}
+
+// SetFilmFacets caches facet counts for a listing filter, identified by a
+// caller-computed digest of the filter
+func (c *Client) SetFilmFacets(ctx context.Context, filterDigest string, facets *models.FilmFacets, ttl time.Duration) error {
+	data, err := json.Marshal(facets)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, fmt.Sprintf(FilmFacetsKey, filterDigest), data, ttl).Err()
+}
+
+// GetFilmFacets retrieves cached facet counts for a listing filter
+func (c *Client) GetFilmFacets(ctx context.Context, filterDigest string) (*models.FilmFacets, error) {
+	data, err := c.Get(ctx, fmt.Sprintf(FilmFacetsKey, filterDigest)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var facets models.FilmFacets
+	if err := json.Unmarshal(data, &facets); err != nil {
+		return nil, err
+	}
+	return &facets, nil
+}
+
+// ========== RECOMMENDATION OPERATIONS ==========
+
+// SetRecommendations stores a user's precomputed recommendation candidates
+func (c *Client) SetRecommendations(ctx context.Context, userID uuid.UUID, candidates []models.RecommendationCandidate, ttl time.Duration) error {
+	data, err := json.Marshal(candidates)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf(RecommendationsKey, userID)
+	return c.Set(ctx, key, data, ttl).Err()
+}
+
+// GetRecommendations retrieves a user's precomputed recommendation candidates
+func (c *Client) GetRecommendations(ctx context.Context, userID uuid.UUID) ([]models.RecommendationCandidate, error) {
+	key := fmt.Sprintf(RecommendationsKey, userID)
+	data, err := c.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []models.RecommendationCandidate
+	if err := json.Unmarshal(data, &candidates); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// SetNewFromFollows caches a user's "new from creators you follow" rail
+func (c *Client) SetNewFromFollows(ctx context.Context, userID uuid.UUID, films []models.Film, ttl time.Duration) error {
+	data, err := json.Marshal(films)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf(NewFromFollowsKey, userID)
+	return c.Set(ctx, key, data, ttl).Err()
+}
+
+// GetNewFromFollows retrieves a user's cached "new from creators you follow" rail
+func (c *Client) GetNewFromFollows(ctx context.Context, userID uuid.UUID) ([]models.Film, error) {
+	key := fmt.Sprintf(NewFromFollowsKey, userID)
+	data, err := c.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var films []models.Film
+	if err := json.Unmarshal(data, &films); err != nil {
+		return nil, err
+	}
+	return films, nil
+}
+
+// InvalidateNewFromFollows drops a user's cached rail after their follows change
+func (c *Client) InvalidateNewFromFollows(ctx context.Context, userID uuid.UUID) error {
+	return c.Del(ctx, fmt.Sprintf(NewFromFollowsKey, userID)).Err()
+}
+
+// ========== UPLOAD PROGRESS OPERATIONS ==========
+
+// InitUploadProgress resets upload progress tracking for a film
+func (c *Client) InitUploadProgress(ctx context.Context, filmID uuid.UUID, totalBytes int64, totalParts int) error {
+	progressKey := fmt.Sprintf(UploadProgressKey, filmID)
+	partsKey := fmt.Sprintf(UploadPartsKey, filmID)
+
+	pipe := c.TxPipeline()
+	pipe.Del(ctx, progressKey, partsKey)
+	pipe.HSet(ctx, progressKey, "total_bytes", totalBytes, "total_parts", totalParts)
+	pipe.Expire(ctx, progressKey, 24*time.Hour)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RecordUploadPart marks a part as completed and returns the updated progress.
+// Confirming the same part twice only counts it once.
+func (c *Client) RecordUploadPart(ctx context.Context, filmID uuid.UUID, partNumber int, sizeBytes int64) (*models.UploadProgress, error) {
+	partsKey := fmt.Sprintf(UploadPartsKey, filmID)
+
+	pipe := c.TxPipeline()
+	pipe.HSet(ctx, partsKey, strconv.Itoa(partNumber), sizeBytes)
+	pipe.Expire(ctx, partsKey, 24*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	progress, err := c.GetUploadProgress(ctx, filmID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.PublishUploadProgress(ctx, progress); err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// GetUploadProgress computes upload progress from the completed parts
+func (c *Client) GetUploadProgress(ctx context.Context, filmID uuid.UUID) (*models.UploadProgress, error) {
+	progressKey := fmt.Sprintf(UploadProgressKey, filmID)
+	partsKey := fmt.Sprintf(UploadPartsKey, filmID)
+
+	totals, err := c.HGetAll(ctx, progressKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	parts, err := c.HGetAll(ctx, partsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &models.UploadProgress{FilmID: filmID}
+	progress.TotalBytes, _ = strconv.ParseInt(totals["total_bytes"], 10, 64)
+	progress.TotalParts, _ = strconv.Atoi(totals["total_parts"])
+
+	for _, size := range parts {
+		n, _ := strconv.ParseInt(size, 10, 64)
+		progress.UploadedBytes += n
+		progress.CompletedParts++
+	}
+
+	if progress.TotalBytes > 0 {
+		progress.Percent = float64(progress.UploadedBytes) / float64(progress.TotalBytes) * 100
+		if progress.Percent > 100 {
+			progress.Percent = 100
+		}
+	}
+
+	return progress, nil
+}
+
+// PublishUploadProgress notifies stream subscribers of an upload progress change
+func (c *Client) PublishUploadProgress(ctx context.Context, progress *models.UploadProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	channel := fmt.Sprintf(UploadProgressChannel, progress.FilmID)
+	return c.Publish(ctx, channel, data).Err()
+}
+
+// SubscribeUploadProgress subscribes to upload progress updates for a film
+func (c *Client) SubscribeUploadProgress(ctx context.Context, filmID uuid.UUID) *redis.PubSub {
+	return c.Subscribe(ctx, fmt.Sprintf(UploadProgressChannel, filmID))
+}
+
+// ========== SETTINGS NOTIFICATIONS ==========
+
+// PublishSettingChanged notifies all API instances that a setting changed
+func (c *Client) PublishSettingChanged(ctx context.Context, key string) error {
+	return c.Publish(ctx, SettingsChannel, key).Err()
+}
+
+// SubscribeSettingsChanged subscribes to setting change notifications
+func (c *Client) SubscribeSettingsChanged(ctx context.Context) *redis.PubSub {
+	return c.Subscribe(ctx, SettingsChannel)
+}
+
+// ========== LOCK OPERATIONS ==========
+
+// AcquireLock takes a named lock for ttl, returning false if another
+// holder has it. The token identifies the holder for ReleaseLock.
+func (c *Client) AcquireLock(ctx context.Context, name, token string, ttl time.Duration) (bool, error) {
+	return c.SetNX(ctx, fmt.Sprintf(LockKey, name), token, ttl).Result()
+}
+
+// ReleaseLock drops a named lock if it is still held by token
+func (c *Client) ReleaseLock(ctx context.Context, name, token string) error {
+	script := redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("DEL", KEYS[1])
+		end
+		return 0
+	`)
+	return script.Run(ctx, c.Client, []string{fmt.Sprintf(LockKey, name)}, token).Err()
+}
+
+// ========== REALTIME ANALYTICS OPERATIONS ==========
+
+// realtimeRetention is how long per-minute play counters are kept
+const realtimeRetention = 2 * time.Hour
+
+// SetFilmMeta caches the owner and title of a film for realtime counters
+func (c *Client) SetFilmMeta(ctx context.Context, filmID, creatorID uuid.UUID, title string, ttl time.Duration) error {
+	key := fmt.Sprintf(FilmMetaKey, filmID)
+	pipe := c.TxPipeline()
+	pipe.HSet(ctx, key, "creator_id", creatorID.String(), "title", title)
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetFilmMeta retrieves the cached owner and title of a film
+func (c *Client) GetFilmMeta(ctx context.Context, filmID uuid.UUID) (creatorID uuid.UUID, title string, err error) {
+	values, err := c.HMGet(ctx, fmt.Sprintf(FilmMetaKey, filmID), "creator_id", "title").Result()
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	id, _ := values[0].(string)
+	if id == "" {
+		return uuid.Nil, "", redis.Nil
+	}
+	creatorID, err = uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	title, _ = values[1].(string)
+	return creatorID, title, nil
+}
+
+// RecordRealtimeActivity marks a viewer as active on one of a creator's
+// films and, for play starts, counts the play in the current minute
+func (c *Client) RecordRealtimeActivity(ctx context.Context, creatorID, filmID uuid.UUID, viewer string, play bool, at time.Time) error {
+	pipe := c.Pipeline()
+	if viewer != "" {
+		key := fmt.Sprintf(RealtimeViewersKey, creatorID)
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(at.Unix()), Member: filmID.String() + "|" + viewer})
+		pipe.Expire(ctx, key, realtimeRetention)
+	}
+	if play {
+		key := fmt.Sprintf(RealtimePlaysKey, creatorID, at.Unix()/60)
+		pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, realtimeRetention)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetRealtimeViewers returns the viewers of a creator's films active since
+// the given time, as film ID -> viewer count plus the number of distinct
+// viewers, and drops older entries
+func (c *Client) GetRealtimeViewers(ctx context.Context, creatorID uuid.UUID, since time.Time) (map[uuid.UUID]int, int, error) {
+	key := fmt.Sprintf(RealtimeViewersKey, creatorID)
+	cutoff := strconv.FormatInt(since.Unix(), 10)
+
+	pipe := c.Pipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+cutoff)
+	members := pipe.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: cutoff, Max: "+inf"})
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, 0, err
+	}
+
+	viewers := make(map[uuid.UUID]int)
+	distinct := make(map[string]struct{})
+	for _, member := range members.Val() {
+		filmPart, viewer, ok := strings.Cut(member, "|")
+		if !ok {
+			continue
+		}
+		filmID, err := uuid.Parse(filmPart)
+		if err != nil {
+			continue
+		}
+		viewers[filmID]++
+		distinct[viewer] = struct{}{}
+	}
+	return viewers, len(distinct), nil
+}
+
+// GetRealtimePlays returns play counts for a creator for the given number
+// of whole minutes ending at until, oldest first
+func (c *Client) GetRealtimePlays(ctx context.Context, creatorID uuid.UUID, until time.Time, minutes int) ([]models.MinuteCount, error) {
+	last := until.Unix() / 60
+	keys := make([]string, minutes)
+	for i := range keys {
+		keys[i] = fmt.Sprintf(RealtimePlaysKey, creatorID, last-int64(minutes-1-i))
+	}
+
+	values, err := c.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]models.MinuteCount, minutes)
+	for i, v := range values {
+		counts[i].Minute = time.Unix((last-int64(minutes-1-i))*60, 0).UTC()
+		if s, ok := v.(string); ok {
+			counts[i].Plays, _ = strconv.Atoi(s)
+		}
+	}
+	return counts, nil
+}
+
+// ========== CHAOS TESTING ==========
+
+// SetChaosFaults stores the fault injection rates shared by every API and
+// worker instance; the key expires with the faults
+func (c *Client) SetChaosFaults(ctx context.Context, faults *models.ChaosFaults) error {
+	data, err := json.Marshal(faults)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, ChaosFaultsKey, data, time.Until(faults.ExpiresAt)).Err()
+}
+
+// GetChaosFaults returns the current fault injection rates, or nil when
+// none are set
+func (c *Client) GetChaosFaults(ctx context.Context) (*models.ChaosFaults, error) {
+	data, err := c.Get(ctx, ChaosFaultsKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var faults models.ChaosFaults
+	if err := json.Unmarshal(data, &faults); err != nil {
+		return nil, err
+	}
+	return &faults, nil
+}
+
+// ClearChaosFaults stops all fault injection
+func (c *Client) ClearChaosFaults(ctx context.Context) error {
+	return c.Del(ctx, ChaosFaultsKey).Err()
+}
+
+// ========== FEED OPERATIONS ==========
+
+// SetFeed caches a rendered sitemap or RSS document under name
+func (c *Client) SetFeed(ctx context.Context, name string, data []byte, ttl time.Duration) error {
+	return c.Set(ctx, fmt.Sprintf(FeedKey, name), data, ttl).Err()
+}
+
+// GetFeed retrieves a cached sitemap or RSS document
+func (c *Client) GetFeed(ctx context.Context, name string) ([]byte, error) {
+	return c.Get(ctx, fmt.Sprintf(FeedKey, name)).Bytes()
+}
+
+// ========== PLAYLIST OPERATIONS ==========
+
+// SetPlaylistFilms caches the evaluated film IDs of a smart playlist
+func (c *Client) SetPlaylistFilms(ctx context.Context, playlistID uuid.UUID, filmIDs []uuid.UUID, ttl time.Duration) error {
+	data, err := json.Marshal(filmIDs)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, fmt.Sprintf(PlaylistFilmsKey, playlistID), data, ttl).Err()
+}
+
+// GetPlaylistFilms retrieves the cached film IDs of a smart playlist
+func (c *Client) GetPlaylistFilms(ctx context.Context, playlistID uuid.UUID) ([]uuid.UUID, error) {
+	data, err := c.Get(ctx, fmt.Sprintf(PlaylistFilmsKey, playlistID)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var filmIDs []uuid.UUID
+	if err := json.Unmarshal(data, &filmIDs); err != nil {
+		return nil, err
+	}
+	return filmIDs, nil
+}
+
+// InvalidatePlaylistFilms drops a smart playlist's cached films after its
+// rules change
+func (c *Client) InvalidatePlaylistFilms(ctx context.Context, playlistID uuid.UUID) error {
+	return c.Del(ctx, fmt.Sprintf(PlaylistFilmsKey, playlistID)).Err()
+}
+
+// ========== PLAYBACK POSITION OPERATIONS ==========
+
+// SetPlaybackPosition stores a user's latest position in a film and marks
+// it for flushing to Postgres
+func (c *Client) SetPlaybackPosition(ctx context.Context, position *models.PlaybackPosition, ttl time.Duration) error {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf(PositionKey, position.UserID, position.FilmID), data, ttl)
+	pipe.SAdd(ctx, DirtyPositionsKey, positionMember(position))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetPlaybackPosition retrieves a user's latest position in a film;
+// redis.Nil when none is held
+func (c *Client) GetPlaybackPosition(ctx context.Context, userID, filmID uuid.UUID) (*models.PlaybackPosition, error) {
+	data, err := c.Get(ctx, fmt.Sprintf(PositionKey, userID, filmID)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var position models.PlaybackPosition
+	if err := json.Unmarshal(data, &position); err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+// PopDirtyPlaybackPositions takes up to count positions not yet flushed to
+// Postgres. Each is handed to one caller only; positions that fail to
+// flush must be returned with MarkPlaybackPositionsDirty.
+func (c *Client) PopDirtyPlaybackPositions(ctx context.Context, count int) ([]*models.PlaybackPosition, error) {
+	members, err := c.SPopN(ctx, DirtyPositionsKey, int64(count)).Result()
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(members))
+	for _, member := range members {
+		userID, filmID, ok := strings.Cut(member, ":")
+		if !ok {
+			continue
+		}
+		keys = append(keys, fmt.Sprintf(PositionKey, userID, filmID))
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := c.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]*models.PlaybackPosition, 0, len(values))
+	for _, v := range values {
+		// Positions that expired before being flushed are gone
+		data, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var position models.PlaybackPosition
+		if err := json.Unmarshal([]byte(data), &position); err != nil {
+			continue
+		}
+		positions = append(positions, &position)
+	}
+	return positions, nil
+}
+
+// MarkPlaybackPositionsDirty queues positions for flushing again
+func (c *Client) MarkPlaybackPositionsDirty(ctx context.Context, positions []*models.PlaybackPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(positions))
+	for i, p := range positions {
+		members[i] = positionMember(p)
+	}
+	return c.SAdd(ctx, DirtyPositionsKey, members...).Err()
+}
+
+// positionMember names a position in the dirty set as "userID:filmID"
+func positionMember(p *models.PlaybackPosition) string {
+	return p.UserID.String() + ":" + p.FilmID.String()
+}
+
+// ========== FILM EVENT OPERATIONS ==========
+
+// filmEventsMaxLen bounds the film event stream; consumers that fall
+// further behind than this need a backfill
+const filmEventsMaxLen = 100000
+
+// PublishFilmEvent appends a lifecycle event to the film event stream
+func (c *Client) PublishFilmEvent(ctx context.Context, event *models.FilmEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return c.XAdd(ctx, &redis.XAddArgs{
+		Stream: FilmEventsStream,
+		MaxLen: filmEventsMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"type":    string(event.Type),
+			"film_id": event.FilmID.String(),
+			"event":   data,
+		},
+	}).Err()
+}
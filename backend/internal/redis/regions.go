@@ -0,0 +1,172 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Per-region queues, drained before the shared queues by workers in
+	// their region
+	RegionTranscodeQueue = "filmtube:transcode:queue:%s"
+	RegionTaskQueue      = "filmtube:tasks:queue:%s"
+
+	FilmRegionKey    = "filmtube:film:region:%s"
+	WorkersKey       = "filmtube:workers:%s" // live worker IDs scored by last heartbeat, per region
+	WorkerRegionsKey = "filmtube:workers:regions"
+
+	// WorkerTTL is how long a worker counts as live after its last
+	// heartbeat
+	WorkerTTL = 30 * time.Second
+)
+
+// ========== REGION ROUTING ==========
+
+// SetFilmRegion records the source region of a film for routing its jobs.
+// An empty region routes them to the shared queues.
+func (c *Client) SetFilmRegion(ctx context.Context, filmID uuid.UUID, region string) error {
+	key := fmt.Sprintf(FilmRegionKey, filmID)
+	if region == "" {
+		return c.Del(ctx, key).Err()
+	}
+	return c.Set(ctx, key, region, 0).Err()
+}
+
+// GetFilmRegion returns the source region of a film, or "" when it has
+// none
+func (c *Client) GetFilmRegion(ctx context.Context, filmID uuid.UUID) (string, error) {
+	region, err := c.Get(ctx, fmt.Sprintf(FilmRegionKey, filmID)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return region, err
+}
+
+// routeQueue picks the queue for work on a source in region: the region's
+// own queue while it has a live worker for each job already waiting
+// there, otherwise the shared queue, which workers in every region drain
+func (c *Client) routeQueue(ctx context.Context, region, regionQueue, sharedQueue string) string {
+	if region == "" {
+		return sharedQueue
+	}
+	live, err := c.LiveWorkers(ctx, region)
+	if err != nil || live == 0 {
+		return sharedQueue
+	}
+	waiting, err := c.LLen(ctx, fmt.Sprintf(regionQueue, region)).Result()
+	if err != nil || waiting >= live {
+		return sharedQueue
+	}
+	return fmt.Sprintf(regionQueue, region)
+}
+
+// dequeueKeys lists the queues a worker in region drains, its region's
+// first
+func dequeueKeys(region, regionQueue, sharedQueue string) []string {
+	if region == "" {
+		return []string{sharedQueue}
+	}
+	return []string{fmt.Sprintf(regionQueue, region), sharedQueue}
+}
+
+// ========== WORKER REGISTRY ==========
+
+// WorkerHeartbeat marks a worker in region as live for WorkerTTL
+func (c *Client) WorkerHeartbeat(ctx context.Context, region, workerID string) error {
+	now := time.Now()
+	key := fmt.Sprintf(WorkersKey, region)
+	pipe := c.TxPipeline()
+	pipe.SAdd(ctx, WorkerRegionsKey, region)
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.Unix()), Member: workerID})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(now.Add(-WorkerTTL).Unix(), 10))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RemoveWorker drops a worker from its region, on shutdown
+func (c *Client) RemoveWorker(ctx context.Context, region, workerID string) error {
+	return c.ZRem(ctx, fmt.Sprintf(WorkersKey, region), workerID).Err()
+}
+
+// LiveWorkers counts the workers in region with a recent heartbeat
+func (c *Client) LiveWorkers(ctx context.Context, region string) (int64, error) {
+	cutoff := strconv.FormatInt(time.Now().Add(-WorkerTTL).Unix(), 10)
+	return c.ZCount(ctx, fmt.Sprintf(WorkersKey, region), cutoff, "+inf").Result()
+}
+
+// RequeueOrphanedWork moves jobs and tasks waiting in the queue of a
+// region whose workers have all gone to the shared queues, so they are not
+// stranded. It returns how many were moved.
+func (c *Client) RequeueOrphanedWork(ctx context.Context) (int, error) {
+	regions, err := c.SMembers(ctx, WorkerRegionsKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, region := range regions {
+		live, err := c.LiveWorkers(ctx, region)
+		if err != nil {
+			return moved, err
+		}
+		if live > 0 {
+			continue
+		}
+		for _, queues := range [][2]string{
+			{fmt.Sprintf(RegionTranscodeQueue, region), TranscodeQueue},
+			{fmt.Sprintf(RegionTaskQueue, region), TaskQueue},
+		} {
+			for {
+				err := c.RPopLPush(ctx, queues[0], queues[1]).Err()
+				if err == redis.Nil {
+					break
+				}
+				if err != nil {
+					return moved, err
+				}
+				moved++
+			}
+		}
+	}
+	return moved, nil
+}
+
+// WorkerRegions reports the live workers and queued work of each region a
+// worker has registered in, by name
+func (c *Client) WorkerRegions(ctx context.Context) ([]models.WorkerRegion, error) {
+	regions, err := c.SMembers(ctx, WorkerRegionsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(regions)
+
+	report := make([]models.WorkerRegion, 0, len(regions))
+	for _, region := range regions {
+		live, err := c.LiveWorkers(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+		jobs, err := c.LLen(ctx, fmt.Sprintf(RegionTranscodeQueue, region)).Result()
+		if err != nil {
+			return nil, err
+		}
+		tasks, err := c.LLen(ctx, fmt.Sprintf(RegionTaskQueue, region)).Result()
+		if err != nil {
+			return nil, err
+		}
+		report = append(report, models.WorkerRegion{
+			Region:      region,
+			LiveWorkers: live,
+			QueuedJobs:  jobs,
+			QueuedTasks: tasks,
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const ReportRateKey = "filmtube:reports:rate:%s:%d" // per user and unix hour
+
+// ========== REPORT OPERATIONS ==========
+
+// CountReport counts a report against a user's hourly report limit and
+// returns the count of the current hour
+func (c *Client) CountReport(ctx context.Context, userID uuid.UUID, now time.Time) (int64, error) {
+	key := fmt.Sprintf(ReportRateKey, userID, now.Unix()/3600)
+
+	pipe := c.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, 2*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+const FilmTimelineKey = "filmtube:film:timeline:%s"
+
+// ========== TIMELINE OPERATIONS ==========
+
+// SetFilmTimeline caches a film's generated timeline document
+func (c *Client) SetFilmTimeline(ctx context.Context, timeline *models.Timeline, ttl time.Duration) error {
+	data, err := json.Marshal(timeline)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, fmt.Sprintf(FilmTimelineKey, timeline.FilmID), data, ttl).Err()
+}
+
+// GetFilmTimeline retrieves a film's cached timeline document
+func (c *Client) GetFilmTimeline(ctx context.Context, filmID uuid.UUID) (*models.Timeline, error) {
+	data, err := c.Get(ctx, fmt.Sprintf(FilmTimelineKey, filmID)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var timeline models.Timeline
+	if err := json.Unmarshal(data, &timeline); err != nil {
+		return nil, err
+	}
+	return &timeline, nil
+}
+
+// InvalidateFilmTimeline drops a film's cached timeline after its markers,
+// subtitles or renditions change
+func (c *Client) InvalidateFilmTimeline(ctx context.Context, filmID uuid.UUID) error {
+	return c.Del(ctx, fmt.Sprintf(FilmTimelineKey, filmID)).Err()
+}
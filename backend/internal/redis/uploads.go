@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	MultipartUploadKey = "filmtube:upload:multipart:%s" // per film
+
+	// MultipartUploadTTL is how long an unfinished multipart upload can be
+	// resumed
+	MultipartUploadTTL = 7 * 24 * time.Hour
+)
+
+// ========== MULTIPART UPLOAD OPERATIONS ==========
+
+// SetMultipartUpload records a film's unfinished multipart upload
+func (c *Client) SetMultipartUpload(ctx context.Context, upload *models.MultipartUpload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, fmt.Sprintf(MultipartUploadKey, upload.FilmID), data, MultipartUploadTTL).Err()
+}
+
+// GetMultipartUpload returns a film's unfinished multipart upload, or nil
+// when it has none
+func (c *Client) GetMultipartUpload(ctx context.Context, filmID uuid.UUID) (*models.MultipartUpload, error) {
+	data, err := c.Get(ctx, fmt.Sprintf(MultipartUploadKey, filmID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var upload models.MultipartUpload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// ClearMultipartUpload forgets a film's multipart upload once it is
+// completed or aborted
+func (c *Client) ClearMultipartUpload(ctx context.Context, filmID uuid.UUID) error {
+	return c.Del(ctx, fmt.Sprintf(MultipartUploadKey, filmID)).Err()
+}
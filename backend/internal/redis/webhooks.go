@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// WebhookCursorKey holds the ID of the last film event stream entry
+// fanned out to webhook endpoints
+const WebhookCursorKey = "filmtube:webhooks:cursor"
+
+// StreamFilmEvent is a film event read back from the stream. Raw is the
+// event's JSON exactly as published.
+type StreamFilmEvent struct {
+	StreamID string
+	Event    models.FilmEvent
+	Raw      string
+}
+
+// ========== WEBHOOK OPERATIONS ==========
+
+// GetWebhookCursor returns the stream ID webhooks have fanned out up to.
+// The first time it starts at the newest entry, so history isn't sent to
+// endpoints; backfills reach them through the stream as usual.
+func (c *Client) GetWebhookCursor(ctx context.Context) (string, error) {
+	cursor, err := c.Get(ctx, WebhookCursorKey).Result()
+	if err == nil {
+		return cursor, nil
+	}
+	if err != redis.Nil {
+		return "", err
+	}
+
+	cursor, err = c.LatestFilmEventID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return cursor, c.SetWebhookCursor(ctx, cursor)
+}
+
+// LatestFilmEventID returns the ID of the newest film event stream entry,
+// or "0-0" when the stream is empty, for consumers starting at the present
+func (c *Client) LatestFilmEventID(ctx context.Context) (string, error) {
+	latest, err := c.XRevRangeN(ctx, FilmEventsStream, "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(latest) == 0 {
+		return "0-0", nil
+	}
+	return latest[0].ID, nil
+}
+
+// SetWebhookCursor records the stream ID webhooks have fanned out up to
+func (c *Client) SetWebhookCursor(ctx context.Context, streamID string) error {
+	return c.Set(ctx, WebhookCursorKey, streamID, 0).Err()
+}
+
+// ReadFilmEventsAfter returns up to count film events published after the
+// stream ID, oldest first. Entries that can't be decoded come back with an
+// empty Raw, for the caller to skip while moving the cursor past them.
+func (c *Client) ReadFilmEventsAfter(ctx context.Context, streamID string, count int64) ([]StreamFilmEvent, error) {
+	entries, err := c.XRangeN(ctx, FilmEventsStream, "("+streamID, "+", count).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]StreamFilmEvent, 0, len(entries))
+	for _, entry := range entries {
+		e := StreamFilmEvent{StreamID: entry.ID}
+		if raw, ok := entry.Values["event"].(string); ok {
+			e.Raw = raw
+			if err := json.Unmarshal([]byte(raw), &e.Event); err != nil {
+				e.Raw = ""
+			}
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
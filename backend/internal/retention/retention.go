@@ -0,0 +1,153 @@
+// Package retention applies the original-source retention policy: once a
+// film is ready, its uploaded source is kept, deleted or archived to
+// infrequent-access storage, as set by the policy or the film's override.
+package retention
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/google/uuid"
+)
+
+const (
+	retentionLock = "source-retention"
+
+	// batchSize bounds how many sources one run acts on
+	batchSize = 100
+)
+
+// Result counts what a run did
+type Result struct {
+	Deleted  int `json:"deleted"`
+	Archived int `json:"archived"`
+	Failed   int `json:"failed"`
+}
+
+// Enforcer deletes or archives the sources of ready films
+type Enforcer struct {
+	queries  *db.Queries
+	redis    *redis.Client
+	r2Client *r2.Client
+	settings *settings.Service
+	token    string
+}
+
+// New creates a source retention enforcer
+func New(queries *db.Queries, redisClient *redis.Client, r2Client *r2.Client, settingsService *settings.Service) *Enforcer {
+	return &Enforcer{
+		queries:  queries,
+		redis:    redisClient,
+		r2Client: r2Client,
+		settings: settingsService,
+		token:    uuid.New().String(),
+	}
+}
+
+// Policy returns the configured source retention policy
+func (e *Enforcer) Policy(ctx context.Context) models.SourceRetentionPolicy {
+	policy := models.SourceRetentionPolicy{Action: models.RetentionKeep}
+	if err := e.settings.Decode(ctx, settings.KeySourceRetention, &policy); err != nil {
+		log.Printf("[Retention] Failed to load source retention policy: %v", err)
+	}
+	return policy
+}
+
+// RunOnce acts on the next batch of sources that are due. A source that
+// fails is logged and tried again on the next run.
+func (e *Enforcer) RunOnce(ctx context.Context) (*Result, error) {
+	policy := e.Policy(ctx)
+	cutoff := time.Now().AddDate(0, 0, -policy.AfterDays)
+	result := &Result{}
+
+	candidates, err := e.queries.ListSourceRetentionCandidates(ctx, policy.Action, cutoff, batchSize)
+	if err != nil {
+		return result, err
+	}
+	for _, candidate := range candidates {
+		if err := e.apply(ctx, candidate); err != nil {
+			log.Printf("[Retention] Failed to %s source of film %s: %v", candidate.Action, candidate.FilmID, err)
+			result.Failed++
+			continue
+		}
+		if candidate.Action == models.RetentionDelete {
+			result.Deleted++
+		} else {
+			result.Archived++
+		}
+	}
+	return result, nil
+}
+
+// apply deletes or archives one film's source and records it
+func (e *Enforcer) apply(ctx context.Context, candidate models.SourceRetentionCandidate) error {
+	store := e.r2Client.ForRegion(candidate.SourceRegion)
+	key := r2.GetOriginalKey(candidate.FilmID)
+
+	disposition := models.SourceArchived
+	if candidate.Action == models.RetentionDelete {
+		disposition = models.SourceDeleted
+		if err := store.DeleteFile(ctx, key); err != nil {
+			return err
+		}
+	} else {
+		exists, err := store.FileExists(ctx, key)
+		if err != nil {
+			return err
+		}
+		// A source that is already gone has nothing left to archive
+		if !exists {
+			disposition = models.SourceDeleted
+		} else if err := store.SetStorageClass(ctx, key, r2.StorageClassInfrequentAccess); err != nil {
+			return err
+		}
+	}
+	return e.queries.SetSourceDisposition(ctx, candidate.FilmID, disposition)
+}
+
+// RunLoop applies the policy on every interval. A Redis lock keeps a single
+// instance at work at a time. It blocks until ctx is cancelled.
+func (e *Enforcer) RunLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.enforce(ctx, interval)
+		}
+	}
+}
+
+func (e *Enforcer) enforce(ctx context.Context, interval time.Duration) {
+	ok, err := e.redis.AcquireLock(ctx, retentionLock, e.token, interval)
+	if err != nil {
+		log.Printf("[Retention] Failed to acquire retention lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := e.redis.ReleaseLock(context.Background(), retentionLock, e.token); err != nil {
+			log.Printf("[Retention] Failed to release retention lock: %v", err)
+		}
+	}()
+
+	result, err := e.RunOnce(ctx)
+	if err != nil {
+		log.Printf("[Retention] Failed to list sources due for retention: %v", err)
+		return
+	}
+	if result.Deleted > 0 || result.Archived > 0 || result.Failed > 0 {
+		log.Printf("[Retention] Deleted %d sources, archived %d, %d failed", result.Deleted, result.Archived, result.Failed)
+	}
+}
@@ -0,0 +1,46 @@
+package search
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/google/uuid"
+)
+
+// Indexer keeps the search backend in sync with film changes
+type Indexer struct {
+	backend Search
+	queries *db.Queries
+}
+
+// NewIndexer creates an indexer for the given backend
+func NewIndexer(backend Search, queries *db.Queries) *Indexer {
+	return &Indexer{
+		backend: backend,
+		queries: queries,
+	}
+}
+
+// SyncFilm reloads a film from the database and (re)indexes it
+func (i *Indexer) SyncFilm(ctx context.Context, filmID uuid.UUID) error {
+	film, err := i.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		return err
+	}
+	return i.backend.Index(ctx, film)
+}
+
+// SyncFilmAsync indexes a film in the background so request latency is not
+// tied to the search backend. Failures are logged.
+func (i *Indexer) SyncFilmAsync(filmID uuid.UUID) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := i.SyncFilm(ctx, filmID); err != nil {
+			log.Printf("[Search] Failed to index film %s: %v", filmID, err)
+		}
+	}()
+}
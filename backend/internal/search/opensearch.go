@@ -0,0 +1,344 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// OpenSearch searches an OpenSearch/Elasticsearch index over the REST API.
+// Only IDs are read back from the index; films are loaded from Postgres so
+// responses match the other backends exactly.
+type OpenSearch struct {
+	baseURL  string
+	index    string
+	username string
+	password string
+	http     *http.Client
+	queries  *db.Queries
+}
+
+// NewOpenSearch creates an OpenSearch/Elasticsearch search backend
+func NewOpenSearch(baseURL, index, username, password string, queries *db.Queries) *OpenSearch {
+	if index == "" {
+		index = "filmtube-films"
+	}
+	return &OpenSearch{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		index:    index,
+		username: username,
+		password: password,
+		http:     &http.Client{Timeout: 10 * time.Second},
+		queries:  queries,
+	}
+}
+
+// filmDocument is the indexed representation of a film
+type filmDocument struct {
+	ID          uuid.UUID         `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Tags        []string          `json:"tags"`
+	Type        models.FilmType   `json:"type"`
+	Genre       string            `json:"genre"`
+	Status      models.FilmStatus `json:"status"`
+	Visibility  models.Visibility `json:"visibility"`
+	CreatedByID uuid.UUID         `json:"created_by_id"`
+	ViewCount   int               `json:"view_count"`
+	PublishedAt *time.Time        `json:"published_at,omitempty"`
+	// CreatorVerified is copied from the creator; films are reindexed when
+	// it changes
+	CreatorVerified bool `json:"creator_verified"`
+	// UpdatedAt is the film version the document was built from and Shard
+	// its consistency check shard
+	UpdatedAt time.Time `json:"updated_at"`
+	Shard     int       `json:"shard"`
+}
+
+// boostScript multiplies the text score by the same recency, view count
+// and verified creator lifts as the Postgres backend
+const boostScript = `
+	double score = _score;
+	if (doc['published_at'].size() > 0) {
+		double ageDays = (params.now - doc['published_at'].value.toInstant().toEpochMilli()) / 86400000.0;
+		score *= 1 + params.recency * Math.pow(0.5, ageDays / params.half_life_days);
+	}
+	score *= 1 + params.views * Math.log10(1 + doc['view_count'].value);
+	if (doc['creator_verified'].size() > 0 && doc['creator_verified'].value) {
+		score *= 1 + params.verified;
+	}
+	return score;
+`
+
+// Search runs a multi-field match with automatic fuzziness for typos,
+// boosted by recency, views and creator verification
+func (o *OpenSearch) Search(ctx context.Context, query Query) (*Result, error) {
+	match := map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must": map[string]interface{}{
+				"multi_match": map[string]interface{}{
+					"query":     query.Text,
+					"fields":    []string{"title^3", "tags^2", "description"},
+					"fuzziness": "AUTO",
+				},
+			},
+			"filter": map[string]interface{}{
+				"term": map[string]interface{}{"status": models.StatusReady},
+			},
+			// Documents indexed before visibility existed are public
+			"must_not": map[string]interface{}{
+				"terms": map[string]interface{}{"visibility": []models.Visibility{models.VisibilityUnlisted, models.VisibilityPrivate}},
+			},
+		},
+	}
+	body := map[string]interface{}{
+		"from":    query.Offset,
+		"size":    query.Limit,
+		"_source": false,
+		"query": map[string]interface{}{
+			"script_score": map[string]interface{}{
+				"query": match,
+				"script": map[string]interface{}{
+					"source": boostScript,
+					"params": map[string]interface{}{
+						"now":            time.Now().UnixMilli(),
+						"recency":        query.Boosts.Recency,
+						"half_life_days": query.Boosts.RecencyHalfLifeDays,
+						"views":          query.Boosts.Views,
+						"verified":       query.Boosts.Verified,
+					},
+				},
+			},
+		},
+		"highlight": map[string]interface{}{
+			"pre_tags":  []string{models.HighlightStart},
+			"post_tags": []string{models.HighlightStop},
+			"fields": map[string]interface{}{
+				"title":       map[string]interface{}{"number_of_fragments": 0},
+				"description": map[string]interface{}{"fragment_size": 150, "number_of_fragments": 2},
+			},
+		},
+	}
+
+	var resp struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID        string              `json:"_id"`
+				Score     float64             `json:"_score"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := o.do(ctx, http.MethodPost, "/"+o.index+"/_search", body, &resp); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(resp.Hits.Hits))
+	scores := map[uuid.UUID]float64{}
+	highlights := map[uuid.UUID]map[string][]string{}
+	for _, hit := range resp.Hits.Hits {
+		id, err := uuid.Parse(hit.ID)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+		scores[id] = hit.Score
+		highlights[id] = hit.Highlight
+	}
+
+	// The index can lag the database, so hits are loaded only while they
+	// are still listed and available in the viewer's region
+	films, err := o.queries.GetListedFilmsByIDs(ctx, ids, query.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]models.SearchHit, len(films))
+	for i, film := range films {
+		hits[i] = models.SearchHit{Film: film, Score: scores[film.ID]}
+
+		// Fields without a match come back unhighlighted
+		title := film.Title
+		if fragments := highlights[film.ID]["title"]; len(fragments) > 0 {
+			title = strings.Join(fragments, " ")
+		}
+		hits[i].TitleHTML = markHighlight(title)
+		hits[i].DescriptionHTML = markHighlight(strings.Join(highlights[film.ID]["description"], " … "))
+	}
+
+	return &Result{Hits: hits, Total: resp.Hits.Total.Value}, nil
+}
+
+// Index adds or replaces a film document
+func (o *OpenSearch) Index(ctx context.Context, film *models.Film) error {
+	creator, err := o.queries.GetUserByID(ctx, film.CreatedByID)
+	if err != nil {
+		return fmt.Errorf("failed to load creator: %w", err)
+	}
+
+	doc := filmDocument{
+		ID:          film.ID,
+		Title:       film.Title,
+		Description: film.Description,
+		Tags:        film.Tags,
+		Type:        film.Type,
+		Genre:       film.Genre,
+		Status:      film.Status,
+		Visibility:  film.Visibility,
+		CreatedByID: film.CreatedByID,
+		ViewCount:   film.ViewCount,
+		PublishedAt: film.PublishedAt,
+
+		CreatorVerified: creator.Verified,
+		UpdatedAt:       film.UpdatedAt,
+		Shard:           ShardOf(film.ID),
+	}
+	return o.do(ctx, http.MethodPut, fmt.Sprintf("/%s/_doc/%s", o.index, film.ID), doc, nil)
+}
+
+// Delete removes a film document; deleting a missing document is not an error
+func (o *OpenSearch) Delete(ctx context.Context, filmID uuid.UUID) error {
+	err := o.do(ctx, http.MethodDelete, fmt.Sprintf("/%s/_doc/%s", o.index, filmID), nil, nil)
+	if err != nil && strings.Contains(err.Error(), "404") {
+		return nil
+	}
+	return err
+}
+
+// Name identifies the OpenSearch index
+func (o *OpenSearch) Name() string {
+	return BackendOpenSearch + ":" + o.index
+}
+
+// Versions returns the film version of every document in a shard
+func (o *OpenSearch) Versions(ctx context.Context, shard int) (map[uuid.UUID]time.Time, error) {
+	query := map[string]interface{}{
+		"term": map[string]interface{}{"shard": shard},
+	}
+	versions := map[uuid.UUID]time.Time{}
+	err := o.scroll(ctx, query, []string{"updated_at"}, func(id uuid.UUID, source json.RawMessage) error {
+		var doc struct {
+			UpdatedAt time.Time `json:"updated_at"`
+		}
+		if err := json.Unmarshal(source, &doc); err != nil {
+			return fmt.Errorf("document %s: %w", id, err)
+		}
+		versions[id] = doc.UpdatedAt
+		return nil
+	})
+	return versions, err
+}
+
+// Unsharded returns the films whose documents have no shard
+func (o *OpenSearch) Unsharded(ctx context.Context) ([]uuid.UUID, error) {
+	query := map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must_not": map[string]interface{}{
+				"exists": map[string]interface{}{"field": "shard"},
+			},
+		},
+	}
+	var ids []uuid.UUID
+	err := o.scroll(ctx, query, false, func(id uuid.UUID, _ json.RawMessage) error {
+		ids = append(ids, id)
+		return nil
+	})
+	return ids, err
+}
+
+// scroll calls fn for every document matching query, in pages. Documents
+// whose ID isn't a film ID are skipped.
+func (o *OpenSearch) scroll(ctx context.Context, query, source interface{}, fn func(id uuid.UUID, source json.RawMessage) error) error {
+	type page struct {
+		ScrollID string `json:"_scroll_id"`
+		Hits     struct {
+			Hits []struct {
+				ID     string          `json:"_id"`
+				Source json.RawMessage `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	body := map[string]interface{}{
+		"size":    1000,
+		"query":   query,
+		"_source": source,
+		"sort":    []string{"_doc"},
+	}
+	var resp page
+	if err := o.do(ctx, http.MethodPost, "/"+o.index+"/_search?scroll=1m", body, &resp); err != nil {
+		return err
+	}
+	defer func() {
+		if resp.ScrollID != "" {
+			_ = o.do(context.Background(), http.MethodDelete, "/_search/scroll", map[string]interface{}{"scroll_id": resp.ScrollID}, nil)
+		}
+	}()
+
+	for len(resp.Hits.Hits) > 0 {
+		for _, hit := range resp.Hits.Hits {
+			id, err := uuid.Parse(hit.ID)
+			if err != nil {
+				continue
+			}
+			if err := fn(id, hit.Source); err != nil {
+				return err
+			}
+		}
+
+		next := map[string]interface{}{"scroll": "1m", "scroll_id": resp.ScrollID}
+		resp = page{ScrollID: resp.ScrollID}
+		if err := o.do(ctx, http.MethodPost, "/_search/scroll", next, &resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *OpenSearch) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, o.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.username != "" {
+		req.SetBasicAuth(o.username, o.password)
+	}
+
+	resp, err := o.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("opensearch request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("opensearch %s %s: %d %s", method, path, resp.StatusCode, msg)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"context"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// Postgres searches the films table's tsvector column directly.
+// The index is maintained by a database trigger, so Index and Delete are no-ops.
+type Postgres struct {
+	queries *db.Queries
+}
+
+// NewPostgres creates a Postgres full-text search backend
+func NewPostgres(queries *db.Queries) *Postgres {
+	return &Postgres{queries: queries}
+}
+
+// Search runs a ranked full-text search, falling back to trigram matching
+func (p *Postgres) Search(ctx context.Context, query Query) (*Result, error) {
+	hits, total, err := p.queries.SearchFilms(ctx, query.Text, query.Region, query.Boosts, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	if total > 0 {
+		return &Result{Hits: markHits(hits), Total: total}, nil
+	}
+
+	// Fall back to trigram matching when nothing matches exactly
+	hits, total, err = p.queries.SearchFilmsFuzzy(ctx, query.Text, query.Region, query.Boosts, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Hits: markHits(hits), Total: total, Fuzzy: true}, nil
+}
+
+// markHits converts the database's delimited headlines into HTML
+func markHits(hits []models.SearchHit) []models.SearchHit {
+	for i := range hits {
+		hits[i].TitleHTML = markHighlight(hits[i].TitleHTML)
+		hits[i].DescriptionHTML = markHighlight(hits[i].DescriptionHTML)
+	}
+	return hits
+}
+
+// Index is a no-op; the search vector is kept up to date by a trigger
+func (p *Postgres) Index(ctx context.Context, film *models.Film) error {
+	return nil
+}
+
+// Delete is a no-op; rows leave the index when they are deleted
+func (p *Postgres) Delete(ctx context.Context, filmID uuid.UUID) error {
+	return nil
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// Backend names
+const (
+	BackendPostgres   = "postgres"
+	BackendOpenSearch = "opensearch"
+)
+
+// Query describes a film search
+type Query struct {
+	Text   string
+	Boosts models.SearchBoosts
+	// Region restricts hits to films available in a viewer country, as
+	// db.FilmFilter.Region does
+	Region *string
+	Limit  int
+	Offset int
+}
+
+// Result is a page of search results
+type Result struct {
+	Hits  []models.SearchHit
+	Total int
+	// Fuzzy is set when results came from typo-tolerant matching
+	Fuzzy bool
+}
+
+// Search is implemented by every search backend
+type Search interface {
+	// Search returns ready films matching the query, best match first, with
+	// highlighted snippets
+	Search(ctx context.Context, query Query) (*Result, error)
+	// Index adds or replaces a film in the search index
+	Index(ctx context.Context, film *models.Film) error
+	// Delete removes a film from the search index
+	Delete(ctx context.Context, filmID uuid.UUID) error
+}
+
+// Shards is how many slices consistency checks split films into. A film's
+// shard is the first hex digit of its ID.
+const Shards = 16
+
+// ShardOf returns the consistency check shard of a film
+func ShardOf(filmID uuid.UUID) int {
+	return int(filmID[0] >> 4)
+}
+
+// Replica is implemented by backends that keep their own copy of films,
+// which has to be synced with Postgres and can drift from it
+type Replica interface {
+	Search
+	// Name identifies the index the replica syncs to
+	Name() string
+	// Versions returns, for each film indexed in a shard, the update time
+	// of the film version it was indexed from
+	Versions(ctx context.Context, shard int) (map[uuid.UUID]time.Time, error)
+	// Unsharded returns the films indexed before shards were recorded,
+	// which checks can't see until they are reindexed
+	Unsharded(ctx context.Context) ([]uuid.UUID, error)
+}
+
+// Config selects and configures a search backend
+type Config struct {
+	Backend            string
+	OpenSearchURL      string
+	OpenSearchIndex    string
+	OpenSearchUsername string
+	OpenSearchPassword string
+}
+
+// New creates the configured search backend
+func New(cfg Config, queries *db.Queries) (Search, error) {
+	switch cfg.Backend {
+	case "", BackendPostgres:
+		return NewPostgres(queries), nil
+	case BackendOpenSearch:
+		return NewOpenSearch(cfg.OpenSearchURL, cfg.OpenSearchIndex, cfg.OpenSearchUsername, cfg.OpenSearchPassword, queries), nil
+	default:
+		return nil, fmt.Errorf("unknown search backend %q", cfg.Backend)
+	}
+}
+
+// highlighter turns highlight delimiters into <mark> tags
+var highlighter = strings.NewReplacer(models.HighlightStart, "<mark>", models.HighlightStop, "</mark>")
+
+// markHighlight HTML-escapes a delimited snippet and marks up its matches
+func markHighlight(snippet string) string {
+	return highlighter.Replace(html.EscapeString(snippet))
+}
@@ -0,0 +1,377 @@
+package search
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	syncLock  = "search-sync"
+	syncLease = 10 * time.Minute
+	// syncBatch is how many events and how many updated films each pass
+	// reindexes at most
+	syncBatch = 500
+	// watermarkLag keeps the watermark behind films whose updates were
+	// stamped before they committed
+	watermarkLag  = 30 * time.Second
+	checkInterval = time.Hour
+	// maxRepairErrors caps the errors a repair reports
+	maxRepairErrors = 20
+)
+
+// ErrNotReplica is returned when the search backend reads Postgres
+// directly, so there is no index to sync or check
+var ErrNotReplica = errors.New("search backend has no index to sync")
+
+// Syncer keeps a replica search index in sync with Postgres. Each pass
+// reindexes films named by new film events, then films updated since the
+// watermark; an hourly check diffs the index against Postgres per shard.
+type Syncer struct {
+	replica   Replica
+	queries   *db.Queries
+	redis     *redis.Client
+	token     string
+	lastCheck time.Time
+}
+
+// NewSyncer creates a syncer for the backend; it does nothing unless the
+// backend is a Replica
+func NewSyncer(backend Search, queries *db.Queries, redisClient *redis.Client) *Syncer {
+	replica, _ := backend.(Replica)
+	return &Syncer{
+		replica: replica,
+		queries: queries,
+		redis:   redisClient,
+		token:   uuid.New().String(),
+	}
+}
+
+// RunLoop syncs on every interval and checks consistency hourly until the
+// context is cancelled. Only one instance works at a time.
+func (s *Syncer) RunLoop(ctx context.Context, interval time.Duration) {
+	if s.replica == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.pass(ctx)
+		}
+	}
+}
+
+func (s *Syncer) pass(ctx context.Context) {
+	ok, err := s.redis.AcquireLock(ctx, syncLock, s.token, syncLease)
+	if err != nil {
+		log.Printf("[Search] Failed to acquire sync lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := s.redis.ReleaseLock(context.Background(), syncLock, s.token); err != nil {
+			log.Printf("[Search] Failed to release sync lock: %v", err)
+		}
+	}()
+
+	if n, err := s.Sync(ctx); err != nil {
+		log.Printf("[Search] Failed to sync index: %v", err)
+	} else if n > 0 {
+		log.Printf("[Search] Reindexed %d films", n)
+	}
+
+	if time.Since(s.lastCheck) < checkInterval {
+		return
+	}
+	report, err := s.Check(ctx)
+	if err != nil {
+		log.Printf("[Search] Failed to check index: %v", err)
+		return
+	}
+	s.lastCheck = time.Now()
+	if !report.Consistent {
+		log.Printf("[Search] Index %s diverges from Postgres: %d films, %d unsharded documents", report.Index, report.Divergent, report.Unsharded)
+	}
+}
+
+// State returns the sync state of the index
+func (s *Syncer) State(ctx context.Context) (*models.SearchSyncState, error) {
+	if s.replica == nil {
+		return nil, ErrNotReplica
+	}
+	return s.queries.GetSearchSyncState(ctx, s.replica.Name())
+}
+
+// Sync reindexes films named by film events since the event cursor, then
+// films updated since the watermark, and returns how many it reindexed.
+// Films that fail are logged and skipped so one can't hold up the rest;
+// the consistency check finds them.
+func (s *Syncer) Sync(ctx context.Context) (int, error) {
+	state, err := s.State(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := s.syncEvents(ctx, state)
+	if err != nil {
+		return n, err
+	}
+	m, err := s.syncUpdated(ctx, state)
+	return n + m, err
+}
+
+// syncEvents reindexes the films of the events after the cursor. A new
+// index starts at the newest event; the watermark sweep covers history.
+func (s *Syncer) syncEvents(ctx context.Context, state *models.SearchSyncState) (int, error) {
+	cursor := state.EventCursor
+	if cursor == "" {
+		latest, err := s.redis.LatestFilmEventID(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return 0, s.queries.SetSearchEventCursor(ctx, state.IndexName, latest)
+	}
+
+	events, err := s.redis.ReadFilmEventsAfter(ctx, cursor, syncBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	// Events often repeat a film; each is reindexed once per pass
+	seen := map[uuid.UUID]bool{}
+	var n int
+	for _, e := range events {
+		cursor = e.StreamID
+		if e.Raw == "" || seen[e.Event.FilmID] {
+			continue
+		}
+		seen[e.Event.FilmID] = true
+		if err := s.reindex(ctx, e.Event.FilmID); err != nil {
+			log.Printf("[Search] Failed to reindex film %s: %v", e.Event.FilmID, err)
+			continue
+		}
+		n++
+	}
+	if cursor == state.EventCursor {
+		return n, nil
+	}
+	return n, s.queries.SetSearchEventCursor(ctx, state.IndexName, cursor)
+}
+
+// syncUpdated reindexes films updated after the watermark and moves it past
+// them. A new index starts from the first film, which backfills it.
+func (s *Syncer) syncUpdated(ctx context.Context, state *models.SearchSyncState) (int, error) {
+	var at time.Time
+	var id uuid.UUID
+	if state.WatermarkAt != nil && state.WatermarkID != nil {
+		at, id = *state.WatermarkAt, *state.WatermarkID
+	}
+
+	films, err := s.queries.ListFilmsUpdatedAfter(ctx, at, id, time.Now().Add(-watermarkLag), syncBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(films) == 0 {
+		return 0, nil
+	}
+
+	var n int
+	for _, film := range films {
+		if err := s.reindex(ctx, film.ID); err != nil {
+			log.Printf("[Search] Failed to reindex film %s: %v", film.ID, err)
+			continue
+		}
+		n++
+	}
+	last := films[len(films)-1]
+	return n, s.queries.SetSearchWatermark(ctx, state.IndexName, last.UpdatedAt, last.ID)
+}
+
+// Check diffs each shard of the index against Postgres by count and
+// checksum, lists the films of shards that differ and saves the report.
+// Films updated after the watermark are still to be synced and left out.
+func (s *Syncer) Check(ctx context.Context) (*models.SearchConsistencyReport, error) {
+	state, err := s.State(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SearchConsistencyReport{
+		Index:     state.IndexName,
+		CheckedAt: time.Now(),
+		Watermark: state.WatermarkAt,
+		Shards:    make([]models.SearchShardCheck, 0, Shards),
+	}
+	for shard := 0; shard < Shards; shard++ {
+		check, err := s.checkShard(ctx, shard, state.WatermarkAt)
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", shard, err)
+		}
+		report.Divergent += len(check.Missing) + len(check.Stale) + len(check.Orphaned)
+		report.Shards = append(report.Shards, check)
+	}
+
+	unsharded, err := s.replica.Unsharded(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Unsharded = len(unsharded)
+	report.Consistent = report.Divergent == 0 && report.Unsharded == 0
+
+	if err := s.queries.SaveSearchCheck(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Syncer) checkShard(ctx context.Context, shard int, watermark *time.Time) (models.SearchShardCheck, error) {
+	check := models.SearchShardCheck{Shard: shard}
+
+	films, err := s.queries.ListFilmVersions(ctx, shard)
+	if err != nil {
+		return check, err
+	}
+	indexed, err := s.replica.Versions(ctx, shard)
+	if err != nil {
+		return check, err
+	}
+
+	stored := map[uuid.UUID]int64{}
+	pending := map[uuid.UUID]bool{}
+	for _, film := range films {
+		if watermark != nil && film.UpdatedAt.After(*watermark) {
+			pending[film.ID] = true
+			continue
+		}
+		stored[film.ID] = film.UpdatedAt.UnixMicro()
+	}
+	documents := map[uuid.UUID]int64{}
+	for id, updatedAt := range indexed {
+		if !pending[id] {
+			documents[id] = updatedAt.UnixMicro()
+		}
+	}
+
+	check.DBCount, check.DBChecksum = len(stored), checksum(stored)
+	check.IndexCount, check.IndexChecksum = len(documents), checksum(documents)
+	if check.DBCount == check.IndexCount && check.DBChecksum == check.IndexChecksum {
+		return check, nil
+	}
+
+	for id, version := range stored {
+		indexedVersion, ok := documents[id]
+		switch {
+		case !ok:
+			check.Missing = append(check.Missing, id)
+		case indexedVersion != version:
+			check.Stale = append(check.Stale, id)
+		}
+	}
+	for id := range documents {
+		if _, ok := stored[id]; !ok {
+			check.Orphaned = append(check.Orphaned, id)
+		}
+	}
+	sortIDs(check.Missing)
+	sortIDs(check.Stale)
+	sortIDs(check.Orphaned)
+	return check, nil
+}
+
+// Repair runs a fresh check and reindexes or deletes only the films it
+// finds divergent, along with documents indexed before shards existed
+func (s *Syncer) Repair(ctx context.Context) (*models.SearchConsistencyReport, *models.SearchRepairResult, error) {
+	report, err := s.Check(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &models.SearchRepairResult{}
+	fail := func(filmID uuid.UUID, err error) {
+		result.Failed++
+		if len(result.Errors) < maxRepairErrors {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", filmID, err))
+		}
+	}
+
+	var reindex []uuid.UUID
+	for _, shard := range report.Shards {
+		reindex = append(reindex, shard.Missing...)
+		reindex = append(reindex, shard.Stale...)
+		for _, filmID := range shard.Orphaned {
+			if err := s.replica.Delete(ctx, filmID); err != nil {
+				fail(filmID, err)
+				continue
+			}
+			result.Deleted++
+		}
+	}
+	if report.Unsharded > 0 {
+		unsharded, err := s.replica.Unsharded(ctx)
+		if err != nil {
+			return report, result, err
+		}
+		reindex = append(reindex, unsharded...)
+	}
+
+	for _, filmID := range reindex {
+		if err := s.reindex(ctx, filmID); err != nil {
+			fail(filmID, err)
+			continue
+		}
+		result.Reindexed++
+	}
+	return report, result, nil
+}
+
+// reindex indexes a film as it is now, or deletes its document when the
+// film no longer exists
+func (s *Syncer) reindex(ctx context.Context, filmID uuid.UUID) error {
+	film, err := s.queries.GetFilmByID(ctx, filmID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.replica.Delete(ctx, filmID)
+	}
+	if err != nil {
+		return err
+	}
+	return s.replica.Index(ctx, film)
+}
+
+// checksum hashes a set of film versions independently of order
+func checksum(versions map[uuid.UUID]int64) string {
+	ids := make([]uuid.UUID, 0, len(versions))
+	for id := range versions {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	h := sha256.New()
+	for _, id := range ids {
+		fmt.Fprintf(h, "%s:%d\n", id, versions[id])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sortIDs(ids []uuid.UUID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+}
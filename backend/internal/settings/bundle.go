@@ -0,0 +1,405 @@
+package settings
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/google/uuid"
+)
+
+// BundleVersion is the current configuration bundle format version
+const BundleVersion = 1
+
+// featureFlagPrefix marks settings exported in the feature_flags section
+const featureFlagPrefix = "features."
+
+// Bundle is a portable snapshot of platform configuration
+type Bundle struct {
+	Version           int                    `json:"version" yaml:"version"`
+	ExportedAt        time.Time              `json:"exported_at" yaml:"exported_at"`
+	Settings          map[string]interface{} `json:"settings" yaml:"settings"`
+	FeatureFlags      map[string]interface{} `json:"feature_flags" yaml:"feature_flags"`
+	TranscodeProfiles []BundleProfile        `json:"transcode_profiles" yaml:"transcode_profiles"`
+	FeaturedFilms     []BundleFeatured       `json:"featured_films" yaml:"featured_films"` // nil leaves hero slots alone
+}
+
+// BundleProfile is the exported form of a transcode profile
+type BundleProfile struct {
+	Name         string `json:"name" yaml:"name"`
+	Width        int    `json:"width" yaml:"width"`
+	Height       int    `json:"height" yaml:"height"`
+	VideoBitrate string `json:"video_bitrate" yaml:"video_bitrate"`
+	AudioBitrate string `json:"audio_bitrate" yaml:"audio_bitrate"`
+	Enabled      bool   `json:"enabled" yaml:"enabled"`
+}
+
+// BundleFeatured is the exported form of a hero slot. Slots are matched by
+// film and start time, so film IDs must be the same in both environments.
+// Hero artwork is stored per environment and is not carried over.
+type BundleFeatured struct {
+	FilmID   uuid.UUID  `json:"film_id" yaml:"film_id"`
+	Headline *string    `json:"headline,omitempty" yaml:"headline,omitempty"`
+	Tagline  *string    `json:"tagline,omitempty" yaml:"tagline,omitempty"`
+	StartsAt time.Time  `json:"starts_at" yaml:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at,omitempty" yaml:"ends_at,omitempty"`
+	Priority int        `json:"priority" yaml:"priority"`
+}
+
+// key identifies the slot across environments
+func (f BundleFeatured) key() string {
+	return f.FilmID.String() + "@" + f.StartsAt.UTC().Format(time.RFC3339Nano)
+}
+
+// equal reports whether two slots with the same key have the same copy,
+// end and priority
+func (f BundleFeatured) equal(o BundleFeatured) bool {
+	endsEqual := f.EndsAt == nil && o.EndsAt == nil ||
+		f.EndsAt != nil && o.EndsAt != nil && f.EndsAt.Equal(*o.EndsAt)
+	return endsEqual && f.Priority == o.Priority &&
+		reflect.DeepEqual(f.Headline, o.Headline) && reflect.DeepEqual(f.Tagline, o.Tagline)
+}
+
+// Change describes one difference an import would apply
+type Change struct {
+	Section string      `json:"section"`
+	Key     string      `json:"key"`
+	Action  string      `json:"action"` // create, update, reset, delete
+	Old     interface{} `json:"old,omitempty"`
+	New     interface{} `json:"new,omitempty"`
+}
+
+// Export captures the stored settings, transcode profiles and hero slots
+// that have not ended as a bundle. Settings still at their default are
+// omitted so defaults can evolve.
+func (s *Service) Export(ctx context.Context) (*Bundle, error) {
+	bundle := &Bundle{
+		Version:       BundleVersion,
+		ExportedAt:    time.Now().UTC(),
+		Settings:      map[string]interface{}{},
+		FeatureFlags:  map[string]interface{}{},
+		FeaturedFilms: []BundleFeatured{},
+	}
+
+	stored, err := s.queries.ListSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, setting := range stored {
+		var value interface{}
+		if err := json.Unmarshal(setting.Value, &value); err != nil {
+			return nil, fmt.Errorf("setting %s has invalid value: %w", setting.Key, err)
+		}
+		if strings.HasPrefix(setting.Key, featureFlagPrefix) {
+			bundle.FeatureFlags[setting.Key] = value
+		} else {
+			bundle.Settings[setting.Key] = value
+		}
+	}
+
+	profiles, err := s.queries.ListTranscodeProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		bundle.TranscodeProfiles = append(bundle.TranscodeProfiles, BundleProfile{
+			Name:         p.Name,
+			Width:        p.Width,
+			Height:       p.Height,
+			VideoBitrate: p.VideoBitrate,
+			AudioBitrate: p.AudioBitrate,
+			Enabled:      p.Enabled,
+		})
+	}
+
+	slots, err := s.featuredSlots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		bundle.FeaturedFilms = append(bundle.FeaturedFilms, bundleFeatured(slot))
+	}
+	sort.Slice(bundle.FeaturedFilms, func(i, j int) bool {
+		return bundle.FeaturedFilms[i].key() < bundle.FeaturedFilms[j].key()
+	})
+
+	return bundle, nil
+}
+
+// Import computes the changes needed to make this environment match the
+// bundle and, unless dryRun is set, applies them. The whole bundle is
+// validated before anything is written, and every change is applied in one
+// transaction.
+func (s *Service) Import(ctx context.Context, bundle *Bundle, dryRun bool, updatedByID uuid.UUID) ([]Change, error) {
+	if bundle.Version != BundleVersion {
+		return nil, &ValidationError{Err: fmt.Errorf("unsupported bundle version %d", bundle.Version)}
+	}
+
+	// Merge both sections and validate against the registry
+	incoming := map[string]json.RawMessage{}
+	for _, section := range []map[string]interface{}{bundle.Settings, bundle.FeatureFlags} {
+		for key, value := range section {
+			def, ok := Definitions[key]
+			if !ok {
+				return nil, &ValidationError{Err: fmt.Errorf("unknown setting %s", key)}
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, &ValidationError{Err: fmt.Errorf("setting %s: %w", key, err)}
+			}
+			if err := def.validate(data); err != nil {
+				return nil, &ValidationError{Err: err}
+			}
+			incoming[key] = data
+		}
+	}
+
+	for _, p := range bundle.TranscodeProfiles {
+		if p.Name == "" || p.Width <= 0 || p.Height <= 0 || p.VideoBitrate == "" || p.AudioBitrate == "" {
+			return nil, &ValidationError{Err: fmt.Errorf("transcode profile %q is incomplete", p.Name)}
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, f := range bundle.FeaturedFilms {
+		if f.FilmID == uuid.Nil || f.StartsAt.IsZero() {
+			return nil, &ValidationError{Err: fmt.Errorf("featured film slots need a film_id and starts_at")}
+		}
+		if f.EndsAt != nil && !f.EndsAt.After(f.StartsAt) {
+			return nil, &ValidationError{Err: fmt.Errorf("featured film %s: ends_at must be after starts_at", f.key())}
+		}
+		if seen[f.key()] {
+			return nil, &ValidationError{Err: fmt.Errorf("featured film %s is listed twice", f.key())}
+		}
+		seen[f.key()] = true
+
+		_, err := s.queries.GetFilmByID(ctx, f.FilmID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &ValidationError{Err: fmt.Errorf("featured film %s does not exist", f.FilmID)}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := s.diff(ctx, incoming, bundle.TranscodeProfiles, bundle.FeaturedFilms)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return changes, nil
+	}
+
+	slots, err := s.featuredSlots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	writes := db.ConfigBundleWrites{}
+	var keys []string
+	for _, change := range changes {
+		switch change.Section {
+		case "settings":
+			keys = append(keys, change.Key)
+			if change.Action == "reset" {
+				writes.ResetSettings = append(writes.ResetSettings, change.Key)
+				continue
+			}
+			writes.Settings = append(writes.Settings, models.Setting{
+				Key:         change.Key,
+				Type:        Definitions[change.Key].Type,
+				Value:       incoming[change.Key],
+				UpdatedByID: &updatedByID,
+			})
+		case "transcode_profiles":
+			p := change.New.(BundleProfile)
+			writes.Profiles = append(writes.Profiles, models.TranscodeProfile{
+				ID:           uuid.New(),
+				Name:         p.Name,
+				Width:        p.Width,
+				Height:       p.Height,
+				VideoBitrate: p.VideoBitrate,
+				AudioBitrate: p.AudioBitrate,
+				Enabled:      p.Enabled,
+			})
+		case "featured_films":
+			addFeaturedWrite(&writes, change, slots[change.Key], updatedByID)
+		}
+	}
+
+	// Nothing is written unless every change is, and caches only drop
+	// values once they are committed
+	if err := s.queries.ApplyConfigBundle(ctx, writes); err != nil {
+		return nil, fmt.Errorf("failed to apply bundle: %w", err)
+	}
+	for _, key := range keys {
+		s.invalidate(key)
+		if err := s.redis.PublishSettingChanged(ctx, key); err != nil {
+			log.Printf("[Settings] Failed to publish change for %s: %v", key, err)
+		}
+	}
+
+	return changes, nil
+}
+
+func (s *Service) diff(ctx context.Context, incoming map[string]json.RawMessage, profiles []BundleProfile, featured []BundleFeatured) ([]Change, error) {
+	var changes []Change
+
+	stored, err := s.queries.ListSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]json.RawMessage{}
+	for _, setting := range stored {
+		current[setting.Key] = setting.Value
+	}
+
+	for key, value := range incoming {
+		old, exists := current[key]
+		if exists && jsonEqual(old, value) {
+			continue
+		}
+		change := Change{Section: "settings", Key: key, Action: "create", New: value}
+		if exists {
+			change.Action = "update"
+			change.Old = old
+		}
+		changes = append(changes, change)
+	}
+	for key, old := range current {
+		if _, ok := incoming[key]; !ok {
+			changes = append(changes, Change{Section: "settings", Key: key, Action: "reset", Old: old})
+		}
+	}
+
+	existing, err := s.queries.ListTranscodeProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]BundleProfile{}
+	for _, p := range existing {
+		byName[p.Name] = BundleProfile{
+			Name:         p.Name,
+			Width:        p.Width,
+			Height:       p.Height,
+			VideoBitrate: p.VideoBitrate,
+			AudioBitrate: p.AudioBitrate,
+			Enabled:      p.Enabled,
+		}
+	}
+	for _, p := range profiles {
+		old, exists := byName[p.Name]
+		if exists && old == p {
+			continue
+		}
+		change := Change{Section: "transcode_profiles", Key: p.Name, Action: "create", New: p}
+		if exists {
+			change.Action = "update"
+			change.Old = old
+		}
+		changes = append(changes, change)
+	}
+
+	// A bundle without the section leaves the curation alone
+	if featured != nil {
+		slots, err := s.featuredSlots(ctx)
+		if err != nil {
+			return nil, err
+		}
+		wanted := map[string]bool{}
+		for _, f := range featured {
+			wanted[f.key()] = true
+			slot, exists := slots[f.key()]
+			if exists && bundleFeatured(slot).equal(f) {
+				continue
+			}
+			change := Change{Section: "featured_films", Key: f.key(), Action: "create", New: f}
+			if exists {
+				change.Action = "update"
+				change.Old = bundleFeatured(slot)
+			}
+			changes = append(changes, change)
+		}
+		for key, slot := range slots {
+			if !wanted[key] {
+				changes = append(changes, Change{Section: "featured_films", Key: key, Action: "delete", Old: bundleFeatured(slot)})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Section != changes[j].Section {
+			return changes[i].Section < changes[j].Section
+		}
+		return changes[i].Key < changes[j].Key
+	})
+	return changes, nil
+}
+
+// featuredSlots returns the hero slots that have not ended, by bundle key.
+// Ended slots are history and are neither exported nor replaced.
+func (s *Service) featuredSlots(ctx context.Context) (map[string]models.FeaturedFilm, error) {
+	all, err := s.queries.ListFeaturedFilms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	slots := map[string]models.FeaturedFilm{}
+	for _, slot := range all {
+		if slot.EndsAt == nil || slot.EndsAt.After(now) {
+			slots[bundleFeatured(slot).key()] = slot
+		}
+	}
+	return slots, nil
+}
+
+// addFeaturedWrite adds the creation, update or deletion of the hero slot
+// a change names. Updated slots keep their artwork.
+func addFeaturedWrite(writes *db.ConfigBundleWrites, change Change, slot models.FeaturedFilm, updatedByID uuid.UUID) {
+	if change.Action == "delete" {
+		writes.DeleteFeatured = append(writes.DeleteFeatured, slot.ID)
+		return
+	}
+
+	f := change.New.(BundleFeatured)
+	slot.Headline = f.Headline
+	slot.Tagline = f.Tagline
+	slot.EndsAt = f.EndsAt
+	slot.Priority = f.Priority
+	if change.Action == "update" {
+		writes.UpdateFeatured = append(writes.UpdateFeatured, &slot)
+		return
+	}
+	slot.FilmID = f.FilmID
+	slot.StartsAt = f.StartsAt
+	slot.CreatedByID = &updatedByID
+	writes.CreateFeatured = append(writes.CreateFeatured, &slot)
+}
+
+// bundleFeatured converts a hero slot to its exported form
+func bundleFeatured(slot models.FeaturedFilm) BundleFeatured {
+	return BundleFeatured{
+		FilmID:   slot.FilmID,
+		Headline: slot.Headline,
+		Tagline:  slot.Tagline,
+		StartsAt: slot.StartsAt,
+		EndsAt:   slot.EndsAt,
+		Priority: slot.Priority,
+	}
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,530 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/internal/models"
+)
+
+// Setting keys
+const (
+	KeyQualityLadder       = "transcode.quality_ladder"
+	KeyMaxUploadBytes      = "upload.max_file_size_bytes"
+	KeyCreatorQuotaBytes   = "upload.creator_quota_bytes"
+	KeyAPIRateLimit        = "ratelimit.api_requests_per_minute"
+	KeyRegistrationEnabled = "features.registration_enabled"
+	KeyRelatedWeights      = "related.weights"
+	KeyAnalyticsRetention  = "analytics.retention"
+	KeyRollupCompactDays   = "analytics.rollup_compact_after_days"
+	KeySearchBoosts        = "search.boosts"
+	KeyPurchaseDownloads   = "purchases.download_limit"
+	KeyPremiereSlot        = "calendar.premiere_slot_minutes"
+	KeyBeaconMode          = "analytics.beacon_mode"
+	KeyReviewAutoHide      = "reviews.auto_hide_flags"
+	KeyCommentEditWindow   = "comments.edit_window_minutes"
+	KeyRenditionPruning    = "storage.rendition_pruning"
+	KeyPlaybackLogHours    = "support.playback_log_retention_hours"
+	KeyFeatureLogging      = "recommend.feature_logging"
+	KeyFeatureLogDays      = "recommend.feature_log_retention_days"
+	KeyQualityCheck        = "transcode.quality_check"
+	KeyAPIKeyRateLimit     = "ratelimit.api_key_requests_per_minute"
+	KeyAPIKeyDailyQuota    = "ratelimit.api_key_requests_per_day"
+	KeyWebhookDisableAfter = "webhooks.disable_after_failures"
+	KeyWebhookDisableHours = "webhooks.disable_after_hours"
+	KeyReportRateLimit     = "ratelimit.reports_per_hour"
+	KeyModerationMinAge    = "moderation.age_restrict_min_age"
+	KeyCommentRateMinute   = "ratelimit.comments_per_minute"
+	KeyCommentRateHour     = "ratelimit.comments_per_hour"
+	KeySpamKeywords        = "comments.spam_keywords"
+	KeyPlaybackPlans       = "playback.plans"
+	KeyImportURLSchemes    = "upload.import_url_schemes"
+	KeyAbandonedUploadDays = "upload.abandoned_after_days"
+	KeyTranscodeAdmission  = "transcode.admission"
+	KeySourceRetention     = "storage.source_retention"
+	KeyContentClassify     = "moderation.content_classification"
+)
+
+// Definition describes a known setting: its type, default and validation
+type Definition struct {
+	Key         string
+	Type        models.SettingType
+	Default     interface{}
+	Description string
+	// Validate performs additional checks after the type check (optional)
+	Validate func(value json.RawMessage) error
+}
+
+// Definitions is the registry of all settings that may be stored.
+// Unknown keys are rejected so typos don't silently create dead settings.
+var Definitions = map[string]Definition{
+	KeyQualityLadder: {
+		Key:         KeyQualityLadder,
+		Type:        models.SettingTypeJSON,
+		Default:     []string{"360p", "720p"},
+		Description: "Transcode profile names produced for new uploads",
+		Validate: func(value json.RawMessage) error {
+			var names []string
+			if err := json.Unmarshal(value, &names); err != nil || len(names) == 0 {
+				return fmt.Errorf("must be a non-empty array of profile names")
+			}
+			return nil
+		},
+	},
+	KeyMaxUploadBytes: {
+		Key:         KeyMaxUploadBytes,
+		Type:        models.SettingTypeInt,
+		Default:     int64(2147483648), // 2GB
+		Description: "Maximum size of a single source upload in bytes",
+		Validate:    minInt(1),
+	},
+	KeyCreatorQuotaBytes: {
+		Key:         KeyCreatorQuotaBytes,
+		Type:        models.SettingTypeInt,
+		Default:     int64(0),
+		Description: "Total source storage allowed per creator in bytes (0 = unlimited)",
+		Validate:    minInt(0),
+	},
+	KeyAPIRateLimit: {
+		Key:         KeyAPIRateLimit,
+		Type:        models.SettingTypeInt,
+		Default:     int64(600),
+		Description: "Requests per minute allowed per API client",
+		Validate:    minInt(1),
+	},
+	KeyRegistrationEnabled: {
+		Key:         KeyRegistrationEnabled,
+		Type:        models.SettingTypeBool,
+		Default:     true,
+		Description: "Whether new users may register",
+	},
+	KeyRelatedWeights: {
+		Key:         KeyRelatedWeights,
+		Type:        models.SettingTypeJSON,
+		Default:     models.RelatedWeights{Creator: 1.0, Genre: 0.6, Tags: 0.8},
+		Description: "Weights for same-creator, same-genre and tag-overlap signals in related films",
+		Validate: func(value json.RawMessage) error {
+			var w models.RelatedWeights
+			if err := json.Unmarshal(value, &w); err != nil {
+				return fmt.Errorf("must be an object with creator, genre and tags weights")
+			}
+			if w.Creator < 0 || w.Genre < 0 || w.Tags < 0 {
+				return fmt.Errorf("weights must not be negative")
+			}
+			if w.Creator+w.Genre+w.Tags == 0 {
+				return fmt.Errorf("at least one weight must be positive")
+			}
+			return nil
+		},
+	},
+	KeyAnalyticsRetention: {
+		Key:  KeyAnalyticsRetention,
+		Type: models.SettingTypeJSON,
+		Default: map[string]models.RetentionPolicy{
+			models.DefaultRetentionKey:     {AnonymizeAfterDays: 30, DeleteAfterDays: 395},
+			string(models.EventHeartbeat):  {AnonymizeAfterDays: 7, DeleteAfterDays: 30},
+			string(models.EventImpression): {AnonymizeAfterDays: 7, DeleteAfterDays: 90},
+		},
+		Description: `Raw analytics retention per event type ("*" = default); 0 disables a step`,
+		Validate: func(value json.RawMessage) error {
+			var policies map[string]models.RetentionPolicy
+			if err := json.Unmarshal(value, &policies); err != nil {
+				return fmt.Errorf("must map event types to {anonymize_after_days, delete_after_days}")
+			}
+			if _, ok := policies[models.DefaultRetentionKey]; !ok {
+				return fmt.Errorf("must include a %q default policy", models.DefaultRetentionKey)
+			}
+			for eventType, p := range policies {
+				if p.AnonymizeAfterDays < 0 || p.DeleteAfterDays < 0 {
+					return fmt.Errorf("%s: days must not be negative", eventType)
+				}
+				if p.DeleteAfterDays > 0 && p.AnonymizeAfterDays > p.DeleteAfterDays {
+					return fmt.Errorf("%s: anonymize_after_days must not exceed delete_after_days", eventType)
+				}
+			}
+			return nil
+		},
+	},
+	KeyRollupCompactDays: {
+		Key:         KeyRollupCompactDays,
+		Type:        models.SettingTypeInt,
+		Default:     int64(180),
+		Description: "Age in days after which daily analytics rollups are compacted into monthly rollups",
+		Validate:    minInt(1),
+	},
+	KeyBeaconMode: {
+		Key:         KeyBeaconMode,
+		Type:        models.SettingTypeString,
+		Default:     models.BeaconModeFlag,
+		Description: `What happens to play, view, heartbeat and completion events without a valid beacon token: "flag" stores them as unverified, "drop" discards them`,
+		Validate: func(value json.RawMessage) error {
+			var mode string
+			if err := json.Unmarshal(value, &mode); err != nil {
+				return err
+			}
+			if mode != models.BeaconModeFlag && mode != models.BeaconModeDrop {
+				return fmt.Errorf("must be %q or %q", models.BeaconModeFlag, models.BeaconModeDrop)
+			}
+			return nil
+		},
+	},
+	KeyPurchaseDownloads: {
+		Key:         KeyPurchaseDownloads,
+		Type:        models.SettingTypeInt,
+		Default:     int64(5),
+		Description: "Times a download-to-own purchase may be downloaded; applies to new purchases",
+		Validate:    minInt(1),
+	},
+	KeyPremiereSlot: {
+		Key:         KeyPremiereSlot,
+		Type:        models.SettingTypeInt,
+		Default:     int64(120),
+		Description: "Minutes a premiere without an end time occupies on the content calendar when checking for collisions",
+		Validate:    minInt(1),
+	},
+	KeyReviewAutoHide: {
+		Key:         KeyReviewAutoHide,
+		Type:        models.SettingTypeInt,
+		Default:     int64(3),
+		Description: "Flags from different users after which a review is hidden until a moderator reviews it (0 = never)",
+		Validate:    minInt(0),
+	},
+	KeyCommentEditWindow: {
+		Key:         KeyCommentEditWindow,
+		Type:        models.SettingTypeInt,
+		Default:     int64(15),
+		Description: "Minutes after posting during which a comment's author can edit it (0 = comments cannot be edited)",
+		Validate:    minInt(0),
+	},
+	KeyRenditionPruning: {
+		Key:         KeyRenditionPruning,
+		Type:        models.SettingTypeJSON,
+		Default:     models.PruningPolicy{Enabled: false, Keep: []string{"360p"}, MaxViews: 100, MinAgeDays: 365},
+		Description: "Which old, rarely watched films lose their other renditions to save storage, and the renditions they keep",
+		Validate: func(value json.RawMessage) error {
+			var p models.PruningPolicy
+			if err := json.Unmarshal(value, &p); err != nil {
+				return fmt.Errorf("must be an object with enabled, keep, max_views and min_age_days")
+			}
+			if len(p.Keep) == 0 {
+				return fmt.Errorf("keep must name at least one rendition")
+			}
+			if p.MaxViews < 0 {
+				return fmt.Errorf("max_views must not be negative")
+			}
+			if p.MinAgeDays < 1 {
+				return fmt.Errorf("min_age_days must be at least 1")
+			}
+			return nil
+		},
+	},
+	KeyTranscodeAdmission: {
+		Key:         KeyTranscodeAdmission,
+		Type:        models.SettingTypeJSON,
+		Default:     models.AdmissionPolicy{MaxQueued: 0, MaxInFlight: 0, Mode: models.AdmissionReject, RetryAfterSeconds: 60},
+		Description: "Limits on queued and in-flight transcodes above which confirming an upload is rejected with 429 or deferred until there is room",
+		Validate: func(value json.RawMessage) error {
+			var p models.AdmissionPolicy
+			if err := json.Unmarshal(value, &p); err != nil {
+				return fmt.Errorf("must be an object with max_queued, max_in_flight, mode and retry_after_seconds")
+			}
+			if p.MaxQueued < 0 || p.MaxInFlight < 0 {
+				return fmt.Errorf("limits must not be negative")
+			}
+			if p.Mode != models.AdmissionReject && p.Mode != models.AdmissionDefer {
+				return fmt.Errorf("mode must be reject or defer")
+			}
+			if p.RetryAfterSeconds < 1 {
+				return fmt.Errorf("retry_after_seconds must be at least 1")
+			}
+			return nil
+		},
+	},
+	KeySourceRetention: {
+		Key:         KeySourceRetention,
+		Type:        models.SettingTypeJSON,
+		Default:     models.SourceRetentionPolicy{Action: models.RetentionKeep, AfterDays: 30},
+		Description: "What happens to uploaded sources once their film is ready: keep, delete or archive (infrequent-access storage) after_days later",
+		Validate: func(value json.RawMessage) error {
+			var p models.SourceRetentionPolicy
+			if err := json.Unmarshal(value, &p); err != nil {
+				return fmt.Errorf("must be an object with action and after_days")
+			}
+			if !p.Action.Valid() {
+				return fmt.Errorf("action must be keep, delete or archive")
+			}
+			if p.AfterDays < 0 {
+				return fmt.Errorf("after_days must not be negative")
+			}
+			return nil
+		},
+	},
+	KeyQualityCheck: {
+		Key:         KeyQualityCheck,
+		Type:        models.SettingTypeJSON,
+		Default:     models.QualityCheckPolicy{Enabled: false, Samples: 5, SampleSeconds: 10, MinVMAF: 80, MaxDrop: 5},
+		Description: "Post-encode VMAF check of new films' renditions on sampled windows of the source, and when it alerts",
+		Validate: func(value json.RawMessage) error {
+			var p models.QualityCheckPolicy
+			if err := json.Unmarshal(value, &p); err != nil {
+				return fmt.Errorf("must be an object with enabled, samples, sample_seconds, min_vmaf and max_drop")
+			}
+			if p.Samples < 1 || p.Samples > 20 {
+				return fmt.Errorf("samples must be between 1 and 20")
+			}
+			if p.SampleSeconds < 1 || p.SampleSeconds > 60 {
+				return fmt.Errorf("sample_seconds must be between 1 and 60")
+			}
+			if p.MinVMAF < 0 || p.MinVMAF > 100 {
+				return fmt.Errorf("min_vmaf must be between 0 and 100")
+			}
+			if p.MaxDrop <= 0 {
+				return fmt.Errorf("max_drop must be positive")
+			}
+			return nil
+		},
+	},
+	KeyContentClassify: {
+		Key:  KeyContentClassify,
+		Type: models.SettingTypeJSON,
+		Default: models.ClassificationPolicy{
+			Enabled: false, Frames: 8, Labels: []string{"nsfw"}, Threshold: 0.9, Action: models.ClassificationFlag,
+		},
+		Description: "Post-transcode classification of frames sampled from new films, and what a flagged film gets: flag or age_restrict",
+		Validate: func(value json.RawMessage) error {
+			var p models.ClassificationPolicy
+			if err := json.Unmarshal(value, &p); err != nil {
+				return fmt.Errorf("must be an object with enabled, frames, labels, threshold and action")
+			}
+			if p.Frames < 1 || p.Frames > 50 {
+				return fmt.Errorf("frames must be between 1 and 50")
+			}
+			if len(p.Labels) == 0 {
+				return fmt.Errorf("labels must not be empty")
+			}
+			if p.Threshold <= 0 || p.Threshold > 1 {
+				return fmt.Errorf("threshold must be above 0 and at most 1")
+			}
+			if !p.Action.Valid() {
+				return fmt.Errorf("action must be flag or age_restrict")
+			}
+			return nil
+		},
+	},
+	KeyAPIKeyRateLimit: {
+		Key:         KeyAPIKeyRateLimit,
+		Type:        models.SettingTypeInt,
+		Default:     int64(120),
+		Description: "Requests per minute allowed per API key, unless the key has its own limit",
+		Validate:    minInt(1),
+	},
+	KeyAPIKeyDailyQuota: {
+		Key:         KeyAPIKeyDailyQuota,
+		Type:        models.SettingTypeInt,
+		Default:     int64(10000),
+		Description: "Requests per day (UTC) allowed per API key, unless the key has its own quota (0 = unlimited)",
+		Validate:    minInt(0),
+	},
+	KeyWebhookDisableAfter: {
+		Key:         KeyWebhookDisableAfter,
+		Type:        models.SettingTypeInt,
+		Default:     int64(20),
+		Description: "Consecutive failed webhook attempts after which an endpoint is disabled, once it has also been failing for webhooks.disable_after_hours",
+		Validate:    minInt(1),
+	},
+	KeyWebhookDisableHours: {
+		Key:         KeyWebhookDisableHours,
+		Type:        models.SettingTypeInt,
+		Default:     int64(24),
+		Description: "Hours a webhook endpoint must have been failing before it is disabled",
+		Validate:    minInt(0),
+	},
+	KeyReportRateLimit: {
+		Key:         KeyReportRateLimit,
+		Type:        models.SettingTypeInt,
+		Default:     int64(20),
+		Description: "Film and comment reports each user can send per hour (0 = unlimited)",
+		Validate:    minInt(0),
+	},
+	KeyCommentRateMinute: {
+		Key:         KeyCommentRateMinute,
+		Type:        models.SettingTypeInt,
+		Default:     int64(5),
+		Description: "Comments and replies each user can post per minute (0 = unlimited)",
+		Validate:    minInt(0),
+	},
+	KeyCommentRateHour: {
+		Key:         KeyCommentRateHour,
+		Type:        models.SettingTypeInt,
+		Default:     int64(60),
+		Description: "Comments and replies each user can post per hour (0 = unlimited)",
+		Validate:    minInt(0),
+	},
+	KeyImportURLSchemes: {
+		Key:         KeyImportURLSchemes,
+		Type:        models.SettingTypeJSON,
+		Default:     []string{"https"},
+		Description: "URL schemes creators may import sources from; http and https are supported",
+		Validate: func(value json.RawMessage) error {
+			var schemes []string
+			if err := json.Unmarshal(value, &schemes); err != nil {
+				return fmt.Errorf("must be an array of strings")
+			}
+			for _, scheme := range schemes {
+				if scheme != "http" && scheme != "https" {
+					return fmt.Errorf("unsupported scheme %q", scheme)
+				}
+			}
+			return nil
+		},
+	},
+	KeyAbandonedUploadDays: {
+		Key:         KeyAbandonedUploadDays,
+		Type:        models.SettingTypeInt,
+		Default:     int64(7),
+		Description: "Days after which a draft or uploaded film that was never confirmed is expired and its partial upload deleted",
+		Validate:    minInt(1),
+	},
+	KeySpamKeywords: {
+		Key:         KeySpamKeywords,
+		Type:        models.SettingTypeJSON,
+		Default:     []string{},
+		Description: "Words and phrases that hold a comment as suspected spam on every film with the spam filter on, besides the film's own keywords",
+		Validate: func(value json.RawMessage) error {
+			var keywords []string
+			if err := json.Unmarshal(value, &keywords); err != nil {
+				return fmt.Errorf("must be an array of strings")
+			}
+			for _, keyword := range keywords {
+				if strings.TrimSpace(keyword) == "" {
+					return fmt.Errorf("keywords must not be empty")
+				}
+			}
+			return nil
+		},
+	},
+	KeyPlaybackPlans: {
+		Key:  KeyPlaybackPlans,
+		Type: models.SettingTypeJSON,
+		Default: models.PlanCatalog{
+			Default: "free",
+			Plans: []models.PlaybackPlan{
+				{Key: "free", Name: "Free", MaxHeight: 720},
+				{Key: "standard", Name: "Standard", MaxHeight: 1080},
+				{Key: "premium", Name: "Premium", MaxHeight: 0},
+			},
+		},
+		Description: "Platform playback plans and the tallest rendition each serves (0 for no cap), and the plan of viewers without an entitlement; organizations may define their own",
+		Validate: func(value json.RawMessage) error {
+			var catalog models.PlanCatalog
+			if err := json.Unmarshal(value, &catalog); err != nil {
+				return fmt.Errorf("must be an object with default and plans")
+			}
+			return catalog.Validate()
+		},
+	},
+	KeyModerationMinAge: {
+		Key:         KeyModerationMinAge,
+		Type:        models.SettingTypeInt,
+		Default:     int64(18),
+		Description: "Minimum viewer age moderators' age restriction sets on a film (1-21)",
+		Validate: func(value json.RawMessage) error {
+			var v int64
+			if err := json.Unmarshal(value, &v); err != nil {
+				return err
+			}
+			if v < 1 || v > 21 {
+				return fmt.Errorf("must be between 1 and 21")
+			}
+			return nil
+		},
+	},
+	KeyPlaybackLogHours: {
+		Key:         KeyPlaybackLogHours,
+		Type:        models.SettingTypeInt,
+		Default:     int64(72),
+		Description: "Hours playback session logs are kept for support investigations",
+		Validate:    minInt(1),
+	},
+	KeyFeatureLogging: {
+		Key:         KeyFeatureLogging,
+		Type:        models.SettingTypeBool,
+		Default:     false,
+		Description: "Log every recommendation candidate with its features and rank as model training data",
+	},
+	KeyFeatureLogDays: {
+		Key:         KeyFeatureLogDays,
+		Type:        models.SettingTypeInt,
+		Default:     int64(30),
+		Description: "Days recommendation feature logs are kept",
+		Validate:    minInt(1),
+	},
+	KeySearchBoosts: {
+		Key:         KeySearchBoosts,
+		Type:        models.SettingTypeJSON,
+		Default:     models.SearchBoosts{Recency: 0.5, Views: 0.2, Verified: 0.3, RecencyHalfLifeDays: 30},
+		Description: "Search ranking lift for recent, popular and verified-creator films on top of text relevance",
+		Validate: func(value json.RawMessage) error {
+			var b models.SearchBoosts
+			if err := json.Unmarshal(value, &b); err != nil {
+				return fmt.Errorf("must be an object with recency, views, verified and recency_half_life_days")
+			}
+			if b.Recency < 0 || b.Views < 0 || b.Verified < 0 {
+				return fmt.Errorf("boosts must not be negative")
+			}
+			if b.RecencyHalfLifeDays < 1 {
+				return fmt.Errorf("recency_half_life_days must be at least 1")
+			}
+			return nil
+		},
+	},
+}
+
+// validate checks that value matches the definition's type and constraints
+func (d Definition) validate(value json.RawMessage) error {
+	var err error
+	switch d.Type {
+	case models.SettingTypeInt:
+		var v int64
+		err = json.Unmarshal(value, &v)
+	case models.SettingTypeBool:
+		var v bool
+		err = json.Unmarshal(value, &v)
+	case models.SettingTypeString:
+		var v string
+		err = json.Unmarshal(value, &v)
+	case models.SettingTypeJSON:
+		if !json.Valid(value) {
+			err = fmt.Errorf("invalid JSON")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s must be of type %s", d.Key, d.Type)
+	}
+
+	if d.Validate != nil {
+		if err := d.Validate(value); err != nil {
+			return fmt.Errorf("%s %v", d.Key, err)
+		}
+	}
+	return nil
+}
+
+func (d Definition) defaultValue() json.RawMessage {
+	data, _ := json.Marshal(d.Default)
+	return data
+}
+
+func minInt(min int64) func(json.RawMessage) error {
+	return func(value json.RawMessage) error {
+		var v int64
+		if err := json.Unmarshal(value, &v); err != nil {
+			return err
+		}
+		if v < min {
+			return fmt.Errorf("must be at least %d", min)
+		}
+		return nil
+	}
+}
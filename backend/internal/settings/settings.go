@@ -0,0 +1,220 @@
+package settings
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownSetting = errors.New("unknown setting")
+)
+
+// Service provides cached access to database-backed settings. Changes are
+// broadcast over Redis so every API instance drops its cached copy.
+type Service struct {
+	queries *db.Queries
+	redis   *redis.Client
+
+	mu    sync.RWMutex
+	cache map[string]json.RawMessage
+}
+
+// New creates a new settings service
+func New(queries *db.Queries, redisClient *redis.Client) *Service {
+	return &Service{
+		queries: queries,
+		redis:   redisClient,
+		cache:   make(map[string]json.RawMessage),
+	}
+}
+
+// Listen invalidates cached settings when another instance changes them.
+// It blocks until ctx is cancelled.
+func (s *Service) Listen(ctx context.Context) {
+	pubsub := s.redis.SubscribeSettingsChanged(ctx)
+	defer pubsub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-pubsub.Channel():
+			if !ok {
+				return
+			}
+			s.invalidate(msg.Payload)
+		}
+	}
+}
+
+func (s *Service) invalidate(key string) {
+	s.mu.Lock()
+	delete(s.cache, key)
+	s.mu.Unlock()
+}
+
+// Get returns the raw JSON value of a setting, falling back to its default
+func (s *Service) Get(ctx context.Context, key string) (json.RawMessage, error) {
+	def, ok := Definitions[key]
+	if !ok {
+		return nil, ErrUnknownSetting
+	}
+
+	s.mu.RLock()
+	value, cached := s.cache[key]
+	s.mu.RUnlock()
+	if cached {
+		return value, nil
+	}
+
+	setting, err := s.queries.GetSetting(ctx, key)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		value = def.defaultValue()
+	case err != nil:
+		return nil, err
+	default:
+		value = setting.Value
+	}
+
+	s.mu.Lock()
+	s.cache[key] = value
+	s.mu.Unlock()
+
+	return value, nil
+}
+
+// Int returns an INT setting, or its default if it cannot be loaded
+func (s *Service) Int(ctx context.Context, key string) int64 {
+	var v int64
+	s.decode(ctx, key, &v)
+	return v
+}
+
+// Bool returns a BOOL setting, or its default if it cannot be loaded
+func (s *Service) Bool(ctx context.Context, key string) bool {
+	var v bool
+	s.decode(ctx, key, &v)
+	return v
+}
+
+// String returns a STRING setting, or its default if it cannot be loaded
+func (s *Service) String(ctx context.Context, key string) string {
+	var v string
+	s.decode(ctx, key, &v)
+	return v
+}
+
+// Decode unmarshals a setting value into out
+func (s *Service) Decode(ctx context.Context, key string, out interface{}) error {
+	value, err := s.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(value, out)
+}
+
+func (s *Service) decode(ctx context.Context, key string, out interface{}) {
+	if err := s.Decode(ctx, key, out); err != nil {
+		log.Printf("[Settings] Using default for %s: %v", key, err)
+		if def, ok := Definitions[key]; ok {
+			json.Unmarshal(def.defaultValue(), out)
+		}
+	}
+}
+
+// List returns every known setting, with defaults for unset keys
+func (s *Service) List(ctx context.Context) ([]models.Setting, error) {
+	stored, err := s.queries.ListSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]models.Setting, len(stored))
+	for _, setting := range stored {
+		byKey[setting.Key] = setting
+	}
+
+	result := make([]models.Setting, 0, len(Definitions))
+	for key, def := range Definitions {
+		setting, ok := byKey[key]
+		if !ok {
+			setting = models.Setting{
+				Key:       key,
+				Type:      def.Type,
+				Value:     def.defaultValue(),
+				IsDefault: true,
+			}
+		}
+		setting.Description = def.Description
+		result = append(result, setting)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result, nil
+}
+
+// Set validates and stores a setting, then notifies other instances
+func (s *Service) Set(ctx context.Context, key string, value json.RawMessage, updatedByID uuid.UUID) (*models.Setting, error) {
+	def, ok := Definitions[key]
+	if !ok {
+		return nil, ErrUnknownSetting
+	}
+	if err := def.validate(value); err != nil {
+		return nil, &ValidationError{Err: err}
+	}
+
+	setting, err := s.queries.UpsertSetting(ctx, key, def.Type, value, &updatedByID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store setting: %w", err)
+	}
+	setting.Description = def.Description
+
+	s.invalidate(key)
+	if err := s.redis.PublishSettingChanged(ctx, key); err != nil {
+		log.Printf("[Settings] Failed to publish change for %s: %v", key, err)
+	}
+
+	return setting, nil
+}
+
+// Reset removes a stored setting so it reverts to its default
+func (s *Service) Reset(ctx context.Context, key string) error {
+	if _, ok := Definitions[key]; !ok {
+		return ErrUnknownSetting
+	}
+
+	if err := s.queries.DeleteSetting(ctx, key); err != nil {
+		return fmt.Errorf("failed to reset setting: %w", err)
+	}
+
+	s.invalidate(key)
+	if err := s.redis.PublishSettingChanged(ctx, key); err != nil {
+		log.Printf("[Settings] Failed to publish change for %s: %v", key, err)
+	}
+	return nil
+}
+
+// ValidationError is returned when a setting value is rejected
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
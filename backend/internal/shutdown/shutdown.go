@@ -0,0 +1,86 @@
+// Package shutdown coordinates a graceful API shutdown: the instance first
+// reports itself draining so load balancers stop sending it traffic, stops
+// taking new uploads, ends long-lived streams with a reconnect hint, and
+// runs flush hooks before the process exits.
+package shutdown
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReconnectAfter is how long clients of closed streams are told to wait
+// before reconnecting, by which time another instance should serve them
+const ReconnectAfter = 2 * time.Second
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Coordinator tracks whether the instance is draining and holds the hooks
+// to run before exit
+type Coordinator struct {
+	draining atomic.Bool
+	done     chan struct{}
+	once     sync.Once
+
+	mu    sync.Mutex
+	hooks []hook
+}
+
+// New creates a coordinator for an instance that is serving
+func New() *Coordinator {
+	return &Coordinator{done: make(chan struct{})}
+}
+
+// Draining reports whether shutdown has begun
+func (c *Coordinator) Draining() bool {
+	return c.draining.Load()
+}
+
+// Done is closed when shutdown begins; long-lived streams select on it
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.done
+}
+
+// OnDrain registers a hook run by RunHooks, in registration order, such as
+// flushing buffered writes
+func (c *Coordinator) OnDrain(name string, fn func(ctx context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, hook{name: name, fn: fn})
+}
+
+// Drain begins shutdown. It is safe to call more than once.
+func (c *Coordinator) Drain() {
+	c.once.Do(func() {
+		c.draining.Store(true)
+		close(c.done)
+		log.Println("[Shutdown] Draining: health checks fail and new uploads are refused")
+	})
+}
+
+// RunHooks runs every hook, logging failures rather than stopping, until
+// ctx expires
+func (c *Coordinator) RunHooks(ctx context.Context) {
+	c.mu.Lock()
+	hooks := append([]hook(nil), c.hooks...)
+	c.mu.Unlock()
+
+	for _, h := range hooks {
+		if ctx.Err() != nil {
+			log.Printf("[Shutdown] Out of time; skipped %s", h.name)
+			continue
+		}
+		start := time.Now()
+		if err := h.fn(ctx); err != nil {
+			log.Printf("[Shutdown] %s failed: %v", h.name, err)
+			continue
+		}
+		log.Printf("[Shutdown] %s done in %s", h.name, time.Since(start).Round(time.Millisecond))
+	}
+}
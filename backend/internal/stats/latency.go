@@ -0,0 +1,30 @@
+package stats
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+)
+
+// RunUploadLatencyRollup rebuilds the upload latency rollups of yesterday
+// and today at startup and then on every interval, so days completed
+// overnight are final. It blocks until ctx is cancelled.
+func RunUploadLatencyRollup(ctx context.Context, queries *db.Queries, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		since := time.Now().AddDate(0, 0, -1)
+		if err := queries.RollupUploadLatency(ctx, since); err != nil {
+			log.Printf("[Stats] Failed to roll up upload latency: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	platformStatsLock = "platform-stats"
+
+	// platformBackfillDays is how far back the first rollup starts, the
+	// longest platform analytics window
+	platformBackfillDays = 90
+)
+
+// PlatformRollup rolls up the platform-wide daily stats behind admin
+// analytics once each day is complete
+type PlatformRollup struct {
+	queries *db.Queries
+	redis   *redis.Client
+	token   string
+}
+
+// NewPlatformRollup creates a platform stats rollup
+func NewPlatformRollup(queries *db.Queries, redisClient *redis.Client) *PlatformRollup {
+	return &PlatformRollup{
+		queries: queries,
+		redis:   redisClient,
+		token:   uuid.New().String(),
+	}
+}
+
+// Rollup rolls up every complete day since the last rolled up one,
+// snapshotting storage for yesterday
+func (r *PlatformRollup) Rollup(ctx context.Context) error {
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+
+	day := yesterday.AddDate(0, 0, -(platformBackfillDays - 1))
+	last, err := r.queries.GetLastPlatformStatsDay(ctx)
+	if err != nil {
+		return err
+	}
+	if last != nil && last.After(day.AddDate(0, 0, -1)) {
+		day = last.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	}
+
+	for ; !day.After(yesterday); day = day.AddDate(0, 0, 1) {
+		if err := r.queries.RollupPlatformDay(ctx, day, day.Equal(yesterday)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunLoop rolls up at startup and then on every interval, so each day is
+// rolled up shortly after midnight (UTC). A Redis lock keeps a single
+// instance rolling up at a time. It blocks until ctx is cancelled.
+func (r *PlatformRollup) RunLoop(ctx context.Context, interval time.Duration) {
+	r.runOnce(ctx, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.runOnce(ctx, interval)
+		}
+	}
+}
+
+func (r *PlatformRollup) runOnce(ctx context.Context, interval time.Duration) {
+	ok, err := r.redis.AcquireLock(ctx, platformStatsLock, r.token, interval)
+	if err != nil {
+		log.Printf("[Stats] Failed to acquire platform stats lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := r.redis.ReleaseLock(context.Background(), platformStatsLock, r.token); err != nil {
+			log.Printf("[Stats] Failed to release platform stats lock: %v", err)
+		}
+	}()
+
+	if err := r.Rollup(ctx); err != nil {
+		log.Printf("[Stats] Failed to roll up platform stats: %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package stats
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+)
+
+// RunCountReconciler periodically rebuilds the maintained film counters.
+// It blocks until ctx is cancelled.
+func RunCountReconciler(ctx context.Context, queries *db.Queries, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := queries.ReconcileFilmCounts(ctx); err != nil {
+				log.Printf("[Stats] Failed to reconcile film counts: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/google/uuid"
+)
+
+const topFilmsLock = "top-films"
+
+// TopFilmsAggregator periodically rebuilds the "most watched" counters
+type TopFilmsAggregator struct {
+	queries *db.Queries
+	redis   *redis.Client
+	token   string
+}
+
+// NewTopFilmsAggregator creates a top films aggregator
+func NewTopFilmsAggregator(queries *db.Queries, redisClient *redis.Client) *TopFilmsAggregator {
+	return &TopFilmsAggregator{
+		queries: queries,
+		redis:   redisClient,
+		token:   uuid.New().String(),
+	}
+}
+
+// Refresh rebuilds every window's counters
+func (a *TopFilmsAggregator) Refresh(ctx context.Context) error {
+	for window, days := range models.TopWindowDays {
+		if _, err := a.queries.RefreshTopFilms(ctx, window, days); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunLoop refreshes the counters at startup and then on every interval.
+// A Redis lock keeps a single instance refreshing at a time. It blocks
+// until ctx is cancelled.
+func (a *TopFilmsAggregator) RunLoop(ctx context.Context, interval time.Duration) {
+	a.runOnce(ctx, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.runOnce(ctx, interval)
+		}
+	}
+}
+
+func (a *TopFilmsAggregator) runOnce(ctx context.Context, interval time.Duration) {
+	ok, err := a.redis.AcquireLock(ctx, topFilmsLock, a.token, interval)
+	if err != nil {
+		log.Printf("[Stats] Failed to acquire top films lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := a.redis.ReleaseLock(context.Background(), topFilmsLock, a.token); err != nil {
+			log.Printf("[Stats] Failed to release top films lock: %v", err)
+		}
+	}()
+
+	if err := a.Refresh(ctx); err != nil {
+		log.Printf("[Stats] Failed to refresh top films: %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package subtitles
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported input formats
+const (
+	FormatSRT = "srt"
+	FormatASS = "ass"
+	FormatVTT = "vtt"
+)
+
+// Cue is a single timed subtitle
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Result is a converted subtitle file
+type Result struct {
+	VTT      string
+	Cues     int
+	Encoding string
+	Format   string
+}
+
+// FormatFromFilename returns the subtitle format for a file extension
+func FormatFromFilename(name string) (string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".srt":
+		return FormatSRT, nil
+	case ".ass", ".ssa":
+		return FormatASS, nil
+	case ".vtt":
+		return FormatVTT, nil
+	default:
+		return "", fmt.Errorf("unsupported subtitle format %q (use .srt, .ass, .ssa or .vtt)", filepath.Ext(name))
+	}
+}
+
+// ConvertToVTT decodes, parses and validates a subtitle file and renders it as WebVTT
+func ConvertToVTT(data []byte, format string) (*Result, error) {
+	text, encoding := DecodeText(data)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var cues []Cue
+	var err error
+	switch format {
+	case FormatSRT:
+		cues, err = ParseSRT(text)
+	case FormatASS:
+		cues, err = ParseASS(text)
+	case FormatVTT:
+		cues, err = ParseVTT(text)
+	default:
+		return nil, fmt.Errorf("unsupported subtitle format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateTiming(cues); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		VTT:      RenderVTT(cues),
+		Cues:     len(cues),
+		Encoding: encoding,
+		Format:   format,
+	}, nil
+}
+
+// ValidateTiming checks that cues exist and each has a positive duration
+func ValidateTiming(cues []Cue) error {
+	if len(cues) == 0 {
+		return fmt.Errorf("no subtitle cues found")
+	}
+	for i, cue := range cues {
+		if cue.Start < 0 {
+			return fmt.Errorf("cue %d: negative start time", i+1)
+		}
+		if cue.End <= cue.Start {
+			return fmt.Errorf("cue %d: end time %s is not after start time %s", i+1, formatTimestamp(cue.End), formatTimestamp(cue.Start))
+		}
+	}
+	return nil
+}
+
+// RenderVTT renders cues as a WebVTT document
+func RenderVTT(cues []Cue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for i, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, formatTimestamp(cue.Start), formatTimestamp(cue.End), cue.Text)
+	}
+	return b.String()
+}
+
+func formatTimestamp(d time.Duration) string {
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+	d -= s * time.Second
+	ms := d / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, s, ms)
+}
+
+// ========== SRT / VTT ==========
+
+var cueTimingRegex = regexp.MustCompile(`^\s*(\d+:)?(\d{1,2}):(\d{2})[,.](\d{1,3})\s*-->\s*(\d+:)?(\d{1,2}):(\d{2})[,.](\d{1,3})`)
+
+// ParseSRT parses SubRip subtitles
+func ParseSRT(text string) ([]Cue, error) {
+	return parseTimedBlocks(text)
+}
+
+// ParseVTT parses WebVTT subtitles (re-rendered to normalize them)
+func ParseVTT(text string) ([]Cue, error) {
+	if !strings.HasPrefix(strings.TrimSpace(text), "WEBVTT") {
+		return nil, fmt.Errorf("missing WEBVTT header")
+	}
+	return parseTimedBlocks(text)
+}
+
+// parseTimedBlocks handles the blank-line separated block layout shared by SRT and VTT
+func parseTimedBlocks(text string) ([]Cue, error) {
+	var cues []Cue
+	for n, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+
+		timingLine := -1
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				timingLine = i
+				break
+			}
+		}
+		if timingLine < 0 {
+			continue // header, note or stray counter
+		}
+
+		m := cueTimingRegex.FindStringSubmatch(lines[timingLine])
+		if m == nil {
+			return nil, fmt.Errorf("block %d: malformed timing line %q", n+1, lines[timingLine])
+		}
+
+		body := strings.TrimSpace(strings.Join(lines[timingLine+1:], "\n"))
+		if body == "" {
+			continue
+		}
+
+		cues = append(cues, Cue{
+			Start: parseClock(m[1], m[2], m[3], m[4]),
+			End:   parseClock(m[5], m[6], m[7], m[8]),
+			Text:  body,
+		})
+	}
+	return cues, nil
+}
+
+func parseClock(hours, minutes, seconds, fraction string) time.Duration {
+	h, _ := strconv.Atoi(strings.TrimSuffix(hours, ":"))
+	m, _ := strconv.Atoi(minutes)
+	s, _ := strconv.Atoi(seconds)
+	// Normalize the fraction to milliseconds ("5" -> 500, "05" -> 50)
+	for len(fraction) < 3 {
+		fraction += "0"
+	}
+	ms, _ := strconv.Atoi(fraction)
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(s)*time.Second + time.Duration(ms)*time.Millisecond
+}
+
+// ========== ASS / SSA ==========
+
+var assOverrideRegex = regexp.MustCompile(`\{[^}]*\}`)
+
+// ParseASS parses Advanced SubStation Alpha dialogue events. Styling and
+// override tags are dropped; line breaks are preserved.
+func ParseASS(text string) ([]Cue, error) {
+	var cues []Cue
+	var format []string
+	inEvents := false
+
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inEvents = strings.EqualFold(line, "[Events]")
+			continue
+		}
+		if !inEvents {
+			continue
+		}
+
+		if strings.HasPrefix(line, "Format:") {
+			format = splitFields(strings.TrimPrefix(line, "Format:"), -1)
+			continue
+		}
+		if !strings.HasPrefix(line, "Dialogue:") {
+			continue
+		}
+		if format == nil {
+			return nil, fmt.Errorf("line %d: dialogue before Format line", n+1)
+		}
+
+		// Text is always last and may itself contain commas
+		fields := splitFields(strings.TrimPrefix(line, "Dialogue:"), len(format))
+		if len(fields) != len(format) {
+			return nil, fmt.Errorf("line %d: expected %d fields, got %d", n+1, len(format), len(fields))
+		}
+
+		var start, end time.Duration
+		var body string
+		var err error
+		for i, name := range format {
+			switch strings.ToLower(name) {
+			case "start":
+				start, err = parseASSTime(fields[i])
+			case "end":
+				end, err = parseASSTime(fields[i])
+			case "text":
+				body = fields[i]
+			}
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+		}
+
+		body = assOverrideRegex.ReplaceAllString(body, "")
+		body = strings.NewReplacer(`\N`, "\n", `\n`, "\n", `\h`, " ").Replace(body)
+		body = strings.TrimSpace(body)
+		if body == "" {
+			continue
+		}
+
+		cues = append(cues, Cue{Start: start, End: end, Text: body})
+	}
+
+	if format == nil {
+		return nil, fmt.Errorf("missing [Events] section")
+	}
+	return cues, nil
+}
+
+func splitFields(s string, n int) []string {
+	fields := strings.SplitN(s, ",", n)
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+var assTimeRegex = regexp.MustCompile(`^(\d+):(\d{2}):(\d{2})\.(\d{1,3})$`)
+
+// parseASSTime parses H:MM:SS.cc timestamps
+func parseASSTime(s string) (time.Duration, error) {
+	m := assTimeRegex.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("malformed timestamp %q", s)
+	}
+	return parseClock(m[1], m[2], m[3], m[4]), nil
+}
@@ -0,0 +1,224 @@
+package timeline
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/r2"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/subtitles"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxMarkers bounds how many markers a film's timeline holds
+	MaxMarkers = 200
+
+	// CacheTTL is how long a generated timeline is cached. Marker and
+	// subtitle changes drop the cache, so this only bounds stale sprites.
+	CacheTTL = 7 * 24 * time.Hour
+
+	// densityBuckets is how many buckets subtitle cue density is counted in
+	densityBuckets = 100
+
+	maxTitleLength = 200
+)
+
+// ErrInvalidMarkers is wrapped by ValidateMarkers errors
+var ErrInvalidMarkers = errors.New("invalid timeline markers")
+
+// ValidateMarkers checks markers against a film of duration seconds and
+// trims their titles. Intros and credits need an end, and a film has at
+// most one of each.
+func ValidateMarkers(markers []models.FilmMarker, duration int) error {
+	if len(markers) > MaxMarkers {
+		return fmt.Errorf("%w: at most %d markers", ErrInvalidMarkers, MaxMarkers)
+	}
+
+	seen := map[models.MarkerKind]bool{}
+	for i := range markers {
+		m := &markers[i]
+		m.Title = strings.TrimSpace(m.Title)
+
+		switch m.Kind {
+		case models.MarkerChapter, models.MarkerAd:
+		case models.MarkerIntro, models.MarkerCredits:
+			if seen[m.Kind] {
+				return fmt.Errorf("%w: only one %s marker", ErrInvalidMarkers, m.Kind)
+			}
+			seen[m.Kind] = true
+			if m.EndSeconds == nil {
+				return fmt.Errorf("%w: marker %d: %s needs an end", ErrInvalidMarkers, i+1, m.Kind)
+			}
+		default:
+			return fmt.Errorf("%w: marker %d: kind must be CHAPTER, AD, INTRO or CREDITS", ErrInvalidMarkers, i+1)
+		}
+
+		switch {
+		case len(m.Title) > maxTitleLength:
+			return fmt.Errorf("%w: marker %d: title exceeds %d characters", ErrInvalidMarkers, i+1, maxTitleLength)
+		case m.StartSeconds < 0:
+			return fmt.Errorf("%w: marker %d: start must not be negative", ErrInvalidMarkers, i+1)
+		case m.EndSeconds != nil && *m.EndSeconds <= m.StartSeconds:
+			return fmt.Errorf("%w: marker %d: end must be after start", ErrInvalidMarkers, i+1)
+		case duration > 0 && m.StartSeconds >= float64(duration):
+			return fmt.Errorf("%w: marker %d: start is past the end of the film", ErrInvalidMarkers, i+1)
+		case duration > 0 && m.EndSeconds != nil && *m.EndSeconds > float64(duration):
+			return fmt.Errorf("%w: marker %d: end is past the end of the film", ErrInvalidMarkers, i+1)
+		}
+	}
+	return nil
+}
+
+// Builder generates film timeline documents and caches them in Redis
+type Builder struct {
+	queries  *db.Queries
+	r2Client *r2.Client
+	redis    *redis.Client
+}
+
+// New creates a timeline builder
+func New(queries *db.Queries, r2Client *r2.Client, redisClient *redis.Client) *Builder {
+	return &Builder{queries: queries, r2Client: r2Client, redis: redisClient}
+}
+
+// Get returns a film's cached timeline, generating it on a miss
+func (b *Builder) Get(ctx context.Context, film *models.Film) (*models.Timeline, error) {
+	if timeline, err := b.redis.GetFilmTimeline(ctx, film.ID); err == nil {
+		return timeline, nil
+	}
+	return b.Build(ctx, film)
+}
+
+// Build generates a film's timeline from its markers, subtitle tracks and
+// sprite sheet, and caches it
+func (b *Builder) Build(ctx context.Context, film *models.Film) (*models.Timeline, error) {
+	markers, err := b.queries.ListFilmMarkers(ctx, film.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load markers: %w", err)
+	}
+
+	timeline := &models.Timeline{
+		FilmID:      film.ID,
+		Duration:    film.Duration,
+		Chapters:    chapters(markers, film.Duration),
+		AdMarkers:   []models.TimelineAdMarker{},
+		CueDensity:  []models.CueDensity{},
+		GeneratedAt: time.Now().UTC(),
+	}
+	for _, m := range markers {
+		switch m.Kind {
+		case models.MarkerAd:
+			ad := models.TimelineAdMarker{At: m.StartSeconds}
+			if m.EndSeconds != nil {
+				ad.Duration = *m.EndSeconds - m.StartSeconds
+			}
+			timeline.AdMarkers = append(timeline.AdMarkers, ad)
+		case models.MarkerIntro:
+			timeline.Intro = &models.TimelineRange{Start: m.StartSeconds, End: *m.EndSeconds}
+		case models.MarkerCredits:
+			timeline.Credits = &models.TimelineRange{Start: m.StartSeconds, End: *m.EndSeconds}
+		}
+	}
+
+	if film.Duration > 0 {
+		timeline.BucketSeconds = float64(film.Duration) / densityBuckets
+		if timeline.BucketSeconds < 1 {
+			timeline.BucketSeconds = 1
+		}
+		timeline.CueDensity, err = b.cueDensity(ctx, film.ID, timeline.BucketSeconds, film.Duration)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sprite, err := b.queries.GetFilmSprite(ctx, film.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load sprite sheet: %w", err)
+	}
+	timeline.Sprite = sprite
+
+	if err := b.redis.SetFilmTimeline(ctx, timeline, CacheTTL); err != nil {
+		log.Printf("Failed to cache timeline of film %s: %v", film.ID, err)
+	}
+	return timeline, nil
+}
+
+// Invalidate drops a film's cached timeline so the next read regenerates it
+func (b *Builder) Invalidate(ctx context.Context, filmID uuid.UUID) {
+	if err := b.redis.InvalidateFilmTimeline(ctx, filmID); err != nil {
+		log.Printf("Failed to invalidate timeline of film %s: %v", filmID, err)
+	}
+}
+
+// chapters turns chapter markers into spans, each running to its own end
+// or else the next chapter's start or the end of the film
+func chapters(markers []models.FilmMarker, duration int) []models.TimelineChapter {
+	var starts []models.FilmMarker
+	for _, m := range markers {
+		if m.Kind == models.MarkerChapter {
+			starts = append(starts, m)
+		}
+	}
+	sort.SliceStable(starts, func(i, j int) bool { return starts[i].StartSeconds < starts[j].StartSeconds })
+
+	result := make([]models.TimelineChapter, 0, len(starts))
+	for i, m := range starts {
+		end := float64(duration)
+		switch {
+		case m.EndSeconds != nil:
+			end = *m.EndSeconds
+		case i+1 < len(starts):
+			end = starts[i+1].StartSeconds
+		}
+		result = append(result, models.TimelineChapter{Title: m.Title, Start: m.StartSeconds, End: end})
+	}
+	return result
+}
+
+// cueDensity counts the cues starting in each bucket of every subtitle
+// track. A track that cannot be read is left out.
+func (b *Builder) cueDensity(ctx context.Context, filmID uuid.UUID, bucketSeconds float64, duration int) ([]models.CueDensity, error) {
+	subs, err := b.queries.GetSubtitlesByFilmID(ctx, filmID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load subtitles: %w", err)
+	}
+
+	buckets := int(float64(duration)/bucketSeconds + 0.5)
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	result := []models.CueDensity{}
+	for _, sub := range subs {
+		data, err := b.r2Client.DownloadFile(ctx, r2.GetSubtitleKey(filmID, sub.Language))
+		if err != nil {
+			log.Printf("Failed to read %s subtitles of film %s: %v", sub.Language, filmID, err)
+			continue
+		}
+		cues, err := subtitles.ParseVTT(string(data))
+		if err != nil {
+			log.Printf("Failed to parse %s subtitles of film %s: %v", sub.Language, filmID, err)
+			continue
+		}
+
+		counts := make([]int, buckets)
+		for _, cue := range cues {
+			i := int(cue.Start.Seconds() / bucketSeconds)
+			if i >= buckets {
+				i = buckets - 1
+			}
+			counts[i]++
+		}
+		result = append(result, models.CueDensity{Language: sub.Language, Counts: counts})
+	}
+	return result, nil
+}
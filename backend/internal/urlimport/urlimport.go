@@ -0,0 +1,129 @@
+// Package urlimport fetches creators' sources from URLs they host
+// elsewhere, refusing URLs that would reach the platform's own network
+package urlimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxRedirects bounds the redirects followed to reach the file, e.g. from
+// a sharing link to its CDN
+const maxRedirects = 5
+
+var (
+	ErrInvalidURL = errors.New("URL must be absolute, use an allowed scheme and point at a public host")
+	ErrTooLarge   = errors.New("remote file is larger than the upload limit")
+)
+
+// ValidateURL checks that a URL is absolute, uses one of the allowed
+// schemes and names a host that is not loopback, private or link-local.
+// Hosts are checked again as they resolve when fetched.
+func ValidateURL(raw string, schemes []string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || !slices.Contains(schemes, u.Scheme) || u.Hostname() == "" {
+		return nil, ErrInvalidURL
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".internal") {
+		return nil, ErrInvalidURL
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return nil, ErrInvalidURL
+	}
+	return u, nil
+}
+
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast())
+}
+
+// NewClient returns an HTTP client for fetching remote sources. It refuses
+// to connect to non-public addresses, whatever a host resolves to, and
+// follows only redirects to URLs ValidateURL accepts.
+func NewClient(schemes []string, timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return ErrInvalidURL
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if _, err := ValidateURL(req.URL.String(), schemes); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+}
+
+// Fetch starts downloading a remote file, refusing one that declares a
+// size above maxBytes or is served as a web page. The caller closes the
+// body, which is limited to maxBytes.
+func Fetch(ctx context.Context, client *http.Client, u *url.URL, maxBytes int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("remote server returned %s", resp.Status)
+	}
+	if resp.ContentLength > maxBytes {
+		resp.Body.Close()
+		return nil, ErrTooLarge
+	}
+	if contentType := resp.Header.Get("Content-Type"); strings.HasPrefix(contentType, "text/") {
+		resp.Body.Close()
+		return nil, fmt.Errorf("URL serves %s, not a video file", contentType)
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: maxBytes}
+	return resp, nil
+}
+
+// limitedBody fails with ErrTooLarge once more than its limit is read,
+// for servers that send no or a wrong Content-Length
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
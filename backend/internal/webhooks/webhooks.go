@@ -0,0 +1,393 @@
+// Package webhooks sends film lifecycle events from the film event stream
+// to users' HTTP endpoints. Every delivery and attempt is recorded, so
+// consumers can inspect what was sent and replay what they missed.
+// Endpoints that keep failing are disabled until their owner re-enables
+// them.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/internal/db"
+	"github.com/arjunaayasa/filmtube/internal/models"
+	"github.com/arjunaayasa/filmtube/internal/redis"
+	"github.com/arjunaayasa/filmtube/internal/settings"
+	"github.com/arjunaayasa/filmtube/internal/urlimport"
+	"github.com/google/uuid"
+)
+
+const (
+	dispatchLock = "webhook-dispatch"
+
+	// SignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256 of
+	// "<t>.<body>" keyed with the endpoint secret>"
+	SignatureHeader = "X-FilmTube-Signature"
+
+	// secretPrefix marks endpoint secrets
+	secretPrefix = "whsec_"
+
+	// fanOutBatch bounds how many stream events one pass fans out
+	fanOutBatch = 500
+
+	// deliveryBatch bounds how many due deliveries one pass sends, and
+	// senders how many at once
+	deliveryBatch = 100
+	senders       = 10
+
+	// deliveryLease is how long a claimed delivery is held before another
+	// pass may retry it
+	deliveryLease = 5 * time.Minute
+
+	requestTimeout = 10 * time.Second
+
+	// maxResponseBody bounds how much of a response is kept for debugging
+	maxResponseBody = 1024
+
+	// Retention of finished deliveries and how often it is enforced
+	deliveryRetention = 30 * 24 * time.Hour
+	cleanupInterval   = time.Hour
+)
+
+// retryDelays are the waits after each failed attempt; a delivery that
+// fails once more after the last one is given up on
+var retryDelays = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	6 * time.Hour,
+	12 * time.Hour,
+}
+
+// ErrInvalidURL is returned for endpoint URLs that can't receive webhooks
+var ErrInvalidURL = errors.New("endpoint URL must be an absolute http(s) URL to a public host")
+
+// Dispatcher fans film events out to endpoints and sends deliveries
+type Dispatcher struct {
+	queries     *db.Queries
+	redis       *redis.Client
+	settings    *settings.Service
+	http        *http.Client
+	token       string
+	lastCleanup time.Time
+}
+
+// webhookSchemes are the URL schemes endpoints may use
+var webhookSchemes = []string{"http", "https"}
+
+// New creates a webhook dispatcher. Deliveries only connect to public
+// addresses, whatever an endpoint's host resolves to at the time.
+func New(queries *db.Queries, redisClient *redis.Client, settingsService *settings.Service) *Dispatcher {
+	client := urlimport.NewClient(webhookSchemes, requestTimeout)
+	// A redirect is a failed delivery; its target is never requested
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &Dispatcher{
+		queries:  queries,
+		redis:    redisClient,
+		settings: settingsService,
+		http:     client,
+		token:    uuid.New().String(),
+	}
+}
+
+// NewSecret generates an endpoint signing secret
+func NewSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// Sign computes the signature of a payload sent at a unix time
+func Sign(secret string, signedAt int64, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", signedAt, payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureValue formats the SignatureHeader value
+func SignatureValue(signedAt int64, signature string) string {
+	return fmt.Sprintf("t=%d,v1=%s", signedAt, signature)
+}
+
+// Verify checks a SignatureHeader value received with a payload
+func Verify(secret, header, payload string) bool {
+	var signedAt int64
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			signedAt, _ = strconv.ParseInt(v, 10, 64)
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+	if signedAt == 0 {
+		return false
+	}
+	expected := Sign(secret, signedAt, payload)
+	for _, s := range signatures {
+		if hmac.Equal([]byte(s), []byte(expected)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateURL checks that an endpoint URL is an absolute http(s) URL whose
+// host is not a loopback, private or link-local address. Hosts are checked
+// again as they resolve when deliveries connect.
+func ValidateURL(raw string) error {
+	if _, err := urlimport.ValidateURL(raw, webhookSchemes); err != nil {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// RunLoop fans out new events and sends due deliveries on every interval.
+// A Redis lock keeps a single instance dispatching at a time. It blocks
+// until ctx is cancelled.
+func (d *Dispatcher) RunLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.dispatch(ctx)
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	ok, err := d.redis.AcquireLock(ctx, dispatchLock, d.token, deliveryLease)
+	if err != nil {
+		log.Printf("[Webhooks] Failed to acquire dispatch lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := d.redis.ReleaseLock(context.Background(), dispatchLock, d.token); err != nil {
+			log.Printf("[Webhooks] Failed to release dispatch lock: %v", err)
+		}
+	}()
+
+	if n, err := d.FanOut(ctx); err != nil {
+		log.Printf("[Webhooks] Failed to fan out events: %v", err)
+	} else if n > 0 {
+		log.Printf("[Webhooks] Queued %d deliveries", n)
+	}
+
+	if n, err := d.SendDue(ctx); err != nil {
+		log.Printf("[Webhooks] Failed to send deliveries: %v", err)
+	} else if n > 0 {
+		log.Printf("[Webhooks] Sent %d deliveries", n)
+	}
+
+	if time.Since(d.lastCleanup) >= cleanupInterval {
+		n, err := d.queries.DeleteWebhookDeliveries(ctx, time.Now().Add(-deliveryRetention))
+		if err != nil {
+			log.Printf("[Webhooks] Failed to delete old deliveries: %v", err)
+			return
+		}
+		d.lastCleanup = time.Now()
+		if n > 0 {
+			log.Printf("[Webhooks] Deleted %d old deliveries", n)
+		}
+	}
+}
+
+// FanOut queues deliveries of the film events published since the last
+// pass and returns how many were queued
+func (d *Dispatcher) FanOut(ctx context.Context) (int64, error) {
+	cursor, err := d.redis.GetWebhookCursor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	events, err := d.redis.ReadFilmEventsAfter(ctx, cursor, fanOutBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var queued int64
+	for _, e := range events {
+		if e.Raw != "" {
+			n, err := d.queries.EnqueueWebhookEvent(ctx, &e.Event, e.Raw)
+			if err != nil {
+				return queued, err
+			}
+			queued += n
+		}
+		if err := d.redis.SetWebhookCursor(ctx, e.StreamID); err != nil {
+			return queued, err
+		}
+	}
+	return queued, nil
+}
+
+// SendDue sends deliveries that are due and returns how many were
+// attempted
+func (d *Dispatcher) SendDue(ctx context.Context) (int, error) {
+	due, err := d.queries.ClaimDueWebhookDeliveries(ctx, deliveryBatch, deliveryLease)
+	if err != nil {
+		return 0, err
+	}
+
+	endpoints := map[uuid.UUID]*models.WebhookEndpoint{}
+	for _, delivery := range due {
+		if _, ok := endpoints[delivery.EndpointID]; ok {
+			continue
+		}
+		endpoint, err := d.queries.GetWebhookEndpoint(ctx, delivery.EndpointID)
+		if err != nil {
+			return 0, err
+		}
+		endpoints[delivery.EndpointID] = endpoint
+	}
+
+	work := make(chan *models.WebhookDelivery)
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range work {
+				if _, err := d.Send(ctx, endpoints[delivery.EndpointID], delivery); err != nil {
+					log.Printf("[Webhooks] Failed to record delivery %s: %v", delivery.ID, err)
+				}
+			}
+		}()
+	}
+	for i := range due {
+		work <- &due[i]
+	}
+	close(work)
+	wg.Wait()
+
+	return len(due), nil
+}
+
+// Send makes one attempt at a delivery and records it, scheduling a retry
+// when it fails and retries are left. Pings are not retried. The returned
+// error is about recording the attempt; a failed request is reported in
+// the attempt.
+func (d *Dispatcher) Send(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) (*models.WebhookAttempt, error) {
+	attempt := d.post(ctx, endpoint, delivery)
+
+	status := models.WebhookDeliverySucceeded
+	next := time.Now()
+	if !attempt.Succeeded() {
+		status = models.WebhookDeliveryFailed
+		if n := delivery.Attempts; delivery.EventType != models.WebhookPing && n < len(retryDelays) {
+			status = models.WebhookDeliveryPending
+			next = next.Add(retryDelays[n])
+		}
+	}
+
+	disableAfter := d.settings.Int(ctx, settings.KeyWebhookDisableAfter)
+	disableHours := d.settings.Int(ctx, settings.KeyWebhookDisableHours)
+	disabled, err := d.queries.RecordWebhookAttempt(ctx, attempt, status, next, int(disableAfter), int(disableHours))
+	if err != nil {
+		return attempt, err
+	}
+	if disabled {
+		log.Printf("[Webhooks] Disabled endpoint %s after sustained failures", endpoint.ID)
+	}
+	return attempt, nil
+}
+
+// Ping sends a test delivery to an endpoint, whatever its status
+func (d *Dispatcher) Ping(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookAttempt, error) {
+	eventID := uuid.New()
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":          eventID,
+		"type":        models.WebhookPing,
+		"endpoint_id": endpoint.ID,
+		"occurred_at": time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &models.WebhookDelivery{
+		ID:         uuid.New(),
+		EndpointID: endpoint.ID,
+		EventID:    eventID,
+		EventType:  models.WebhookPing,
+		Payload:    string(payload),
+	}
+	if err := d.queries.CreateWebhookDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return d.Send(ctx, endpoint, delivery)
+}
+
+// post makes the HTTP request for a delivery
+func (d *Dispatcher) post(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) *models.WebhookAttempt {
+	signedAt := time.Now().Unix()
+	attempt := &models.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		EndpointID: endpoint.ID,
+		SignedAt:   signedAt,
+		Signature:  Sign(endpoint.Secret, signedAt, delivery.Payload),
+	}
+	fail := func(err error) *models.WebhookAttempt {
+		msg := err.Error()
+		attempt.Error = &msg
+		return attempt
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return fail(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FilmTube-Webhooks/1.0")
+	req.Header.Set("X-FilmTube-Event", delivery.EventType)
+	req.Header.Set("X-FilmTube-Event-ID", delivery.EventID.String())
+	req.Header.Set("X-FilmTube-Delivery", delivery.ID.String())
+	req.Header.Set(SignatureHeader, SignatureValue(signedAt, attempt.Signature))
+
+	start := time.Now()
+	resp, err := d.http.Do(req)
+	attempt.DurationMs = int(time.Since(start).Milliseconds())
+	if err != nil {
+		return fail(err)
+	}
+	defer resp.Body.Close()
+
+	attempt.StatusCode = &resp.StatusCode
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if len(body) > 0 {
+		// Postgres text can't hold NUL bytes or invalid UTF-8
+		s := strings.ReplaceAll(strings.ToValidUTF8(string(body), ""), "\x00", "")
+		attempt.ResponseBody = &s
+	}
+	return attempt
+}
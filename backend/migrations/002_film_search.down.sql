@@ -0,0 +1,11 @@
+-- Migration: Rollback full-text search over films
+-- Down
+
+DROP INDEX IF EXISTS idx_films_title_trgm;
+DROP INDEX IF EXISTS idx_films_search_vector;
+
+DROP TRIGGER IF EXISTS update_films_search_vector ON films;
+DROP FUNCTION IF EXISTS update_films_search_vector;
+
+ALTER TABLE films DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE films DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,30 @@
+-- Migration: Full-text search over films
+-- Up
+
+-- Trigram matching for typo-tolerant fallback search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE films ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE films ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Function to keep the search vector in sync with title/description/tags
+CREATE OR REPLACE FUNCTION update_films_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(array_to_string(NEW.tags, ' '), '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER update_films_search_vector BEFORE INSERT OR UPDATE OF title, description, tags ON films
+    FOR EACH ROW EXECUTE FUNCTION update_films_search_vector();
+
+-- Backfill existing films
+UPDATE films SET title = title;
+
+-- Indexes for search
+CREATE INDEX idx_films_search_vector ON films USING GIN (search_vector);
+CREATE INDEX idx_films_title_trgm ON films USING GIN (title gin_trgm_ops);
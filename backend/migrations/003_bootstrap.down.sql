@@ -0,0 +1,9 @@
+-- Migration: Rollback tenants, transcode profiles and bootstrap state
+-- Down
+
+DROP TRIGGER IF EXISTS update_transcode_profiles_updated_at ON transcode_profiles;
+DROP TRIGGER IF EXISTS update_tenants_updated_at ON tenants;
+
+DROP TABLE IF EXISTS platform_bootstrap;
+DROP TABLE IF EXISTS transcode_profiles;
+DROP TABLE IF EXISTS tenants;
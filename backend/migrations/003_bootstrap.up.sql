@@ -0,0 +1,38 @@
+-- Migration: Tenants, transcode profiles and bootstrap state
+-- Up
+
+-- Tenants table
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) UNIQUE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Transcode profiles table (quality ladder rungs)
+CREATE TABLE IF NOT EXISTS transcode_profiles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(20) UNIQUE NOT NULL, -- 360p, 720p, 1080p
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    video_bitrate VARCHAR(20) NOT NULL,
+    audio_bitrate VARCHAR(20) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Single-row table recording that first-run setup has completed
+CREATE TABLE IF NOT EXISTS platform_bootstrap (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    admin_id UUID NOT NULL REFERENCES users(id),
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    bootstrapped_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_tenants_updated_at BEFORE UPDATE ON tenants
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_transcode_profiles_updated_at BEFORE UPDATE ON transcode_profiles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
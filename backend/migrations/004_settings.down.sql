@@ -0,0 +1,5 @@
+-- Migration: Rollback database-backed platform settings
+-- Down
+
+DROP TRIGGER IF EXISTS update_settings_updated_at ON settings;
+DROP TABLE IF EXISTS settings;
@@ -0,0 +1,15 @@
+-- Migration: Database-backed platform settings
+-- Up
+
+CREATE TABLE IF NOT EXISTS settings (
+    key VARCHAR(100) PRIMARY KEY,
+    type VARCHAR(20) NOT NULL,
+    value JSONB NOT NULL,
+    updated_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT settings_type_check CHECK (type IN ('INT', 'BOOL', 'STRING', 'JSON'))
+);
+
+CREATE TRIGGER update_settings_updated_at BEFORE UPDATE ON settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,10 @@
+-- Migration: Rollback film genres and maintained film counters
+-- Down
+
+DROP TRIGGER IF EXISTS update_film_counts ON films;
+DROP FUNCTION IF EXISTS update_film_counts;
+DROP FUNCTION IF EXISTS adjust_film_count;
+DROP TABLE IF EXISTS film_counts;
+
+DROP INDEX IF EXISTS idx_films_genre;
+ALTER TABLE films DROP COLUMN IF EXISTS genre;
@@ -0,0 +1,53 @@
+-- Migration: Film genres and maintained film counters
+-- Up
+
+ALTER TABLE films ADD COLUMN IF NOT EXISTS genre VARCHAR(50) NOT NULL DEFAULT '';
+CREATE INDEX idx_films_genre ON films(genre);
+
+-- Counters per dimension (creator, status, genre), maintained by trigger
+-- and periodically reconciled by the API
+CREATE TABLE IF NOT EXISTS film_counts (
+    dimension VARCHAR(20) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (dimension, value),
+    CONSTRAINT film_counts_dimension_check CHECK (dimension IN ('creator', 'status', 'genre'))
+);
+
+CREATE OR REPLACE FUNCTION adjust_film_count(dim VARCHAR, val VARCHAR, delta INTEGER)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO film_counts (dimension, value, count)
+    VALUES (dim, val, delta)
+    ON CONFLICT (dimension, value) DO UPDATE
+    SET count = film_counts.count + delta;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION update_film_counts()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM adjust_film_count('creator', OLD.created_by_id::text, -1);
+        PERFORM adjust_film_count('status', OLD.status, -1);
+        PERFORM adjust_film_count('genre', OLD.genre, -1);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM adjust_film_count('creator', NEW.created_by_id::text, 1);
+        PERFORM adjust_film_count('status', NEW.status, 1);
+        PERFORM adjust_film_count('genre', NEW.genre, 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER update_film_counts AFTER INSERT OR DELETE OR UPDATE OF created_by_id, status, genre ON films
+    FOR EACH ROW EXECUTE FUNCTION update_film_counts();
+
+-- Seed counters from existing films
+INSERT INTO film_counts (dimension, value, count)
+SELECT 'creator', created_by_id::text, COUNT(*) FROM films GROUP BY created_by_id
+UNION ALL
+SELECT 'status', status, COUNT(*) FROM films GROUP BY status
+UNION ALL
+SELECT 'genre', genre, COUNT(*) FROM films GROUP BY genre;
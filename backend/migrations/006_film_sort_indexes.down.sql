@@ -0,0 +1,8 @@
+-- Migration: Rollback indexes backing ListFilms sort options
+-- Down
+
+DROP INDEX IF EXISTS idx_films_sort_title;
+DROP INDEX IF EXISTS idx_films_sort_duration;
+DROP INDEX IF EXISTS idx_films_sort_oldest;
+DROP INDEX IF EXISTS idx_films_sort_newest;
+DROP INDEX IF EXISTS idx_films_sort_views;
@@ -0,0 +1,9 @@
+-- Migration: Indexes backing ListFilms sort options
+-- Up
+
+-- Each index ends with id, which ListFilms uses as the stable tiebreaker
+CREATE INDEX idx_films_sort_views ON films(view_count DESC, id DESC);
+CREATE INDEX idx_films_sort_newest ON films(published_at DESC NULLS LAST, created_at DESC, id DESC);
+CREATE INDEX idx_films_sort_oldest ON films(published_at ASC NULLS LAST, created_at ASC, id ASC);
+CREATE INDEX idx_films_sort_duration ON films(duration DESC, id DESC);
+CREATE INDEX idx_films_sort_title ON films(LOWER(title) ASC, id ASC);
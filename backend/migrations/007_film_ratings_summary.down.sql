@@ -0,0 +1,8 @@
+-- Migration: Rollback rating summary columns on films
+-- Down
+
+DROP INDEX IF EXISTS idx_films_duration;
+DROP INDEX IF EXISTS idx_films_average_rating;
+
+ALTER TABLE films DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE films DROP COLUMN IF EXISTS average_rating;
@@ -0,0 +1,8 @@
+-- Migration: Rating summary columns on films for catalog filtering
+-- Up
+
+ALTER TABLE films ADD COLUMN IF NOT EXISTS average_rating NUMERIC(3, 2) NOT NULL DEFAULT 0;
+ALTER TABLE films ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_films_average_rating ON films(average_rating DESC);
+CREATE INDEX idx_films_duration ON films(duration);
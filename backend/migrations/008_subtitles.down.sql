@@ -0,0 +1,5 @@
+-- Migration: Rollback subtitle tracks
+-- Down
+
+DROP TRIGGER IF EXISTS update_subtitles_updated_at ON subtitles;
+DROP TABLE IF EXISTS subtitles;
@@ -0,0 +1,20 @@
+-- Migration: Subtitle tracks
+-- Up
+
+CREATE TABLE IF NOT EXISTS subtitles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    language VARCHAR(16) NOT NULL, -- BCP 47 tag, e.g. en, pt-BR
+    label VARCHAR(100),
+    vtt_url TEXT NOT NULL,
+    source_format VARCHAR(10) NOT NULL,
+    cue_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(film_id, language)
+);
+
+CREATE INDEX idx_subtitles_film_id ON subtitles(film_id);
+
+CREATE TRIGGER update_subtitles_updated_at BEFORE UPDATE ON subtitles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,9 @@
+-- Migration: Rollback rendition variants on video assets
+-- Down
+
+DELETE FROM video_assets WHERE variant <> 'default';
+
+ALTER TABLE video_assets DROP CONSTRAINT IF EXISTS video_assets_film_id_quality_variant_key;
+ALTER TABLE video_assets ADD CONSTRAINT video_assets_film_id_quality_key UNIQUE (film_id, quality);
+
+ALTER TABLE video_assets DROP COLUMN IF EXISTS variant;
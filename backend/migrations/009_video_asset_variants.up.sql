@@ -0,0 +1,7 @@
+-- Migration: Rendition variants (e.g. burned-in subtitles) on video assets
+-- Up
+
+ALTER TABLE video_assets ADD COLUMN IF NOT EXISTS variant VARCHAR(32) NOT NULL DEFAULT 'default';
+
+ALTER TABLE video_assets DROP CONSTRAINT IF EXISTS video_assets_film_id_quality_key;
+ALTER TABLE video_assets ADD CONSTRAINT video_assets_film_id_quality_variant_key UNIQUE (film_id, quality, variant);
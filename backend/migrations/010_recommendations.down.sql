@@ -0,0 +1,5 @@
+-- Migration: Rollback watch history and creator follows
+-- Down
+
+DROP TABLE IF EXISTS creator_follows;
+DROP TABLE IF EXISTS watch_history;
@@ -0,0 +1,25 @@
+-- Migration: Watch history and creator follows (recommendation signals)
+-- Up
+
+CREATE TABLE IF NOT EXISTS watch_history (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    watch_count INTEGER NOT NULL DEFAULT 1,
+    first_watched_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_watched_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, film_id)
+);
+
+-- Co-view lookups go film -> viewers
+CREATE INDEX idx_watch_history_film_id ON watch_history(film_id);
+CREATE INDEX idx_watch_history_last_watched_at ON watch_history(last_watched_at DESC);
+
+CREATE TABLE IF NOT EXISTS creator_follows (
+    follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    creator_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (follower_id, creator_id),
+    CHECK (follower_id <> creator_id)
+);
+
+CREATE INDEX idx_creator_follows_creator_id ON creator_follows(creator_id);
@@ -0,0 +1,5 @@
+-- Migration: Rollback 3D LUTs
+-- Down
+
+DROP TRIGGER IF EXISTS update_luts_updated_at ON luts;
+DROP TABLE IF EXISTS luts;
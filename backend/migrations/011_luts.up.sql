@@ -0,0 +1,28 @@
+-- Migration: 3D LUTs applied during encode
+-- Up
+
+-- A LUT applies platform-wide, to all of a creator's films, or to one film.
+-- The most specific one wins at encode time.
+CREATE TABLE IF NOT EXISTS luts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('platform', 'creator', 'film')),
+    creator_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    film_id UUID REFERENCES films(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    storage_key TEXT NOT NULL,
+    size INTEGER NOT NULL, -- LUT_3D_SIZE
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (
+        (scope = 'platform' AND creator_id IS NULL AND film_id IS NULL) OR
+        (scope = 'creator' AND creator_id IS NOT NULL AND film_id IS NULL) OR
+        (scope = 'film' AND film_id IS NOT NULL)
+    )
+);
+
+CREATE UNIQUE INDEX idx_luts_platform ON luts(scope) WHERE scope = 'platform';
+CREATE UNIQUE INDEX idx_luts_creator ON luts(creator_id) WHERE scope = 'creator';
+CREATE UNIQUE INDEX idx_luts_film ON luts(film_id) WHERE scope = 'film';
+
+CREATE TRIGGER update_luts_updated_at BEFORE UPDATE ON luts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,6 @@
+-- Migration: Rollback analytics events, rollups and data lifecycle runs
+-- Down
+
+DROP TABLE IF EXISTS data_lifecycle_runs;
+DROP TABLE IF EXISTS analytics_rollups;
+DROP TABLE IF EXISTS analytics_events;
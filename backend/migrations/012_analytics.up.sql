@@ -0,0 +1,48 @@
+-- Migration: Analytics events, rollups and data lifecycle runs
+-- Up
+
+-- Raw client events (views, heartbeats, ...). Personal fields are stripped
+-- and rows deleted according to the analytics.retention setting.
+CREATE TABLE IF NOT EXISTS analytics_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(32) NOT NULL,
+    film_id UUID REFERENCES films(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    session_id VARCHAR(64),
+    ip_address INET,
+    user_agent TEXT,
+    country CHAR(2),
+    properties JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    anonymized_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_analytics_events_type_occurred ON analytics_events(event_type, occurred_at);
+CREATE INDEX idx_analytics_events_film_occurred ON analytics_events(film_id, occurred_at);
+
+-- Per-film counters. Daily rows are compacted into monthly rows once they
+-- are older than analytics.rollup_compact_after_days.
+CREATE TABLE IF NOT EXISTS analytics_rollups (
+    period VARCHAR(10) NOT NULL CHECK (period IN ('day', 'month')),
+    period_start DATE NOT NULL,
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    event_type VARCHAR(32) NOT NULL,
+    event_count BIGINT NOT NULL DEFAULT 0,
+    unique_viewers BIGINT NOT NULL DEFAULT 0, -- summed from days for monthly rows
+    PRIMARY KEY (period, period_start, film_id, event_type)
+);
+
+CREATE INDEX idx_analytics_rollups_film ON analytics_rollups(film_id, period, period_start);
+
+CREATE TABLE IF NOT EXISTS data_lifecycle_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    rolled_up BIGINT NOT NULL DEFAULT 0,
+    anonymized BIGINT NOT NULL DEFAULT 0,
+    deleted BIGINT NOT NULL DEFAULT 0,
+    compacted BIGINT NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+CREATE INDEX idx_data_lifecycle_runs_started_at ON data_lifecycle_runs(started_at DESC);
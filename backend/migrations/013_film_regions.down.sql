@@ -0,0 +1,5 @@
+-- Migration: Rollback per-film region availability
+-- Down
+
+ALTER TABLE films DROP COLUMN IF EXISTS blocked_regions;
+ALTER TABLE films DROP COLUMN IF EXISTS allowed_regions;
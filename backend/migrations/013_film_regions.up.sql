@@ -0,0 +1,7 @@
+-- Migration: Per-film region availability
+-- Up
+
+-- ISO 3166-1 alpha-2 codes. An empty allow list means available everywhere
+-- not explicitly blocked.
+ALTER TABLE films ADD COLUMN IF NOT EXISTS allowed_regions TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE films ADD COLUMN IF NOT EXISTS blocked_regions TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,5 @@
+-- Migration: Rollback analytics sink export watermark
+-- Down
+
+DROP TRIGGER IF EXISTS update_analytics_sink_state_updated_at ON analytics_sink_state;
+DROP TABLE IF EXISTS analytics_sink_state;
@@ -0,0 +1,15 @@
+-- Migration: Analytics sink export watermark
+-- Up
+
+-- One row per external sink; events with id <= last_event_id have been exported
+CREATE TABLE IF NOT EXISTS analytics_sink_state (
+    sink VARCHAR(32) PRIMARY KEY,
+    last_event_id BIGINT NOT NULL DEFAULT 0,
+    exported_count BIGINT NOT NULL DEFAULT 0,
+    last_exported_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_analytics_sink_state_updated_at BEFORE UPDATE ON analytics_sink_state
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,10 @@
+-- Migration: Rollback playback positions
+-- Down
+
+DROP INDEX IF EXISTS idx_watch_history_in_progress;
+
+ALTER TABLE watch_history
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS position_updated_at,
+    DROP COLUMN IF EXISTS duration_seconds,
+    DROP COLUMN IF EXISTS position_seconds;
@@ -0,0 +1,13 @@
+-- Migration: Playback positions for Continue Watching
+-- Up
+
+ALTER TABLE watch_history
+    ADD COLUMN IF NOT EXISTS position_seconds INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS duration_seconds INTEGER,
+    ADD COLUMN IF NOT EXISTS position_updated_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+
+-- Continue Watching reads a user's unfinished films, most recent first
+CREATE INDEX IF NOT EXISTS idx_watch_history_in_progress
+    ON watch_history(user_id, position_updated_at DESC)
+    WHERE completed_at IS NULL AND position_seconds > 0;
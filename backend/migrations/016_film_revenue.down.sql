@@ -0,0 +1,4 @@
+-- Migration: Rollback per-film revenue ledger
+-- Down
+
+DROP TABLE IF EXISTS film_transactions;
@@ -0,0 +1,21 @@
+-- Migration: Per-film revenue ledger
+-- Up
+
+-- Every paid rental, purchase or attributed subscription share is one row;
+-- refunds are separate negative rows pointing at the original transaction
+CREATE TABLE IF NOT EXISTS film_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('rental', 'purchase', 'subscription', 'refund')),
+    amount_cents BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    refund_of UUID REFERENCES film_transactions(id) ON DELETE SET NULL,
+    external_ref VARCHAR(255) UNIQUE,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK ((kind = 'refund') = (amount_cents < 0))
+);
+
+CREATE INDEX idx_film_transactions_film_occurred ON film_transactions(film_id, occurred_at);
+CREATE INDEX idx_film_transactions_refund_of ON film_transactions(refund_of) WHERE refund_of IS NOT NULL;
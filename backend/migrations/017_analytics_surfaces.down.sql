@@ -0,0 +1,5 @@
+-- Migration: Rollback analytics surface attribution
+-- Down
+
+DROP TABLE IF EXISTS analytics_surface_rollups;
+ALTER TABLE analytics_events DROP COLUMN IF EXISTS surface;
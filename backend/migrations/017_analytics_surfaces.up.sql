@@ -0,0 +1,18 @@
+-- Migration: Surface attribution for analytics funnels
+-- Up
+
+-- Where in the UI an event happened (e.g. "home:trending", "search",
+-- "related"); empty when the client did not say
+ALTER TABLE analytics_events ADD COLUMN IF NOT EXISTS surface VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Daily per-film, per-surface counters, rolled up alongside analytics_rollups
+CREATE TABLE IF NOT EXISTS analytics_surface_rollups (
+    day DATE NOT NULL,
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    surface VARCHAR(64) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    event_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, film_id, surface, event_type)
+);
+
+CREATE INDEX idx_analytics_surface_rollups_film ON analytics_surface_rollups(film_id, day);
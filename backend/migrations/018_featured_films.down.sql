@@ -0,0 +1,5 @@
+-- Migration: Rollback featured films
+-- Down
+
+DROP TRIGGER IF EXISTS update_featured_films_updated_at ON featured_films;
+DROP TABLE IF EXISTS featured_films;
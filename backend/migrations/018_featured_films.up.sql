@@ -0,0 +1,24 @@
+-- Migration: Featured (hero) films
+-- Up
+
+-- Scheduled hero slots. The live entry is the highest-priority one whose
+-- window contains now; ends_at NULL keeps it pinned until replaced.
+CREATE TABLE IF NOT EXISTS featured_films (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    headline VARCHAR(200),
+    tagline TEXT,
+    hero_image_key TEXT,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMP WITH TIME ZONE,
+    priority INTEGER NOT NULL DEFAULT 0,
+    created_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX idx_featured_films_window ON featured_films(starts_at, ends_at);
+
+CREATE TRIGGER update_featured_films_updated_at BEFORE UPDATE ON featured_films
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,16 @@
+-- Migration: Rollback organization roles and publishing approval chains
+-- Down
+
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS film_approval_events;
+
+DROP INDEX IF EXISTS idx_films_tenant_approval;
+ALTER TABLE films DROP COLUMN IF EXISTS approval_state;
+ALTER TABLE films DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE tenants DROP COLUMN IF EXISTS required_approvals;
+ALTER TABLE tenants DROP COLUMN IF EXISTS approver_roles;
+ALTER TABLE tenants DROP COLUMN IF EXISTS approval_required;
+
+DROP TRIGGER IF EXISTS update_tenant_members_updated_at ON tenant_members;
+DROP TABLE IF EXISTS tenant_members;
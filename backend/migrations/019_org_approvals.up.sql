@@ -0,0 +1,59 @@
+-- Migration: Organization roles and publishing approval chains
+-- Up
+
+-- Per-organization roles: editors submit films, approvers (by default
+-- producers and owners) sign off, owners manage members and policy
+CREATE TABLE IF NOT EXISTS tenant_members (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('OWNER', 'PRODUCER', 'EDITOR')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, user_id)
+);
+
+CREATE INDEX idx_tenant_members_user ON tenant_members(user_id);
+
+CREATE TRIGGER update_tenant_members_updated_at BEFORE UPDATE ON tenant_members
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Approval policy per organization
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS approval_required BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS approver_roles TEXT[] NOT NULL DEFAULT '{OWNER,PRODUCER}';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS required_approvals INTEGER NOT NULL DEFAULT 1
+    CHECK (required_approvals >= 1);
+
+-- Films owned by an organization carry their approval state
+ALTER TABLE films ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id) ON DELETE SET NULL;
+ALTER TABLE films ADD COLUMN IF NOT EXISTS approval_state VARCHAR(20) NOT NULL DEFAULT 'NONE'
+    CHECK (approval_state IN ('NONE', 'SUBMITTED', 'IN_REVIEW', 'APPROVED', 'REJECTED'));
+
+CREATE INDEX idx_films_tenant_approval ON films(tenant_id, approval_state) WHERE tenant_id IS NOT NULL;
+
+-- Append-only history of approval actions
+CREATE TABLE IF NOT EXISTS film_approval_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('SUBMIT', 'REVIEW', 'APPROVE', 'REJECT')),
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_film_approval_events_film ON film_approval_events(film_id, created_at);
+
+-- In-app notification inbox
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    film_id UUID REFERENCES films(id) ON DELETE CASCADE,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
@@ -0,0 +1,4 @@
+-- Migration: Rollback materialized "most watched" counters
+-- Down
+
+DROP TABLE IF EXISTS film_top_counters;
@@ -0,0 +1,15 @@
+-- Migration: Materialized "most watched" counters
+-- Up
+
+-- Views per film over rolling calendar-day windows (today, last 7 days,
+-- last 30 days), rebuilt periodically from the daily rollups plus raw view
+-- events not yet rolled up
+CREATE TABLE IF NOT EXISTS film_top_counters (
+    time_window VARCHAR(10) NOT NULL CHECK (time_window IN ('day', 'week', 'month')),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    views BIGINT NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (time_window, film_id)
+);
+
+CREATE INDEX idx_film_top_counters_views ON film_top_counters(time_window, views DESC);
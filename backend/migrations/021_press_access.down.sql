@@ -0,0 +1,10 @@
+-- Migration: Rollback embargoed press access
+-- Down
+
+DROP TABLE IF EXISTS film_press_access;
+
+ALTER TABLE films DROP COLUMN IF EXISTS press_embargo_until;
+
+UPDATE users SET role = 'USER' WHERE role = 'PRESS';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('USER', 'CREATOR', 'ADMIN'));
@@ -0,0 +1,22 @@
+-- Migration: Embargoed press access
+-- Up
+
+-- Press accounts see films they have been granted before release
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('USER', 'CREATOR', 'PRESS', 'ADMIN'));
+
+-- When the press embargo lifts; press access ends at this time
+ALTER TABLE films ADD COLUMN IF NOT EXISTS press_embargo_until TIMESTAMP WITH TIME ZONE;
+
+-- Per-film press list. Each entry gets its own watermarked screener
+-- rendition set; access ends at the embargo or the earlier expires_at.
+CREATE TABLE IF NOT EXISTS film_press_access (
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    granted_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (film_id, user_id)
+);
+
+CREATE INDEX idx_film_press_access_user ON film_press_access(user_id);
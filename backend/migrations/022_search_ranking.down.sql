@@ -0,0 +1,4 @@
+-- Migration: Rollback search ranking boosts
+-- Down
+
+ALTER TABLE users DROP COLUMN IF EXISTS verified;
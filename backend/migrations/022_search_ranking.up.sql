@@ -0,0 +1,5 @@
+-- Migration: Search ranking boosts
+-- Up
+
+-- Verified creators can be boosted in search results
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,7 @@
+-- Migration: Rollback download-to-own purchases
+-- Down
+
+DROP TABLE IF EXISTS film_purchases;
+
+ALTER TABLE films DROP COLUMN IF EXISTS download_currency;
+ALTER TABLE films DROP COLUMN IF EXISTS download_price_cents;
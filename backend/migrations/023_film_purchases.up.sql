@@ -0,0 +1,25 @@
+-- Migration: Download-to-own purchases
+-- Up
+
+-- Films are for sale as downloads when a price is set
+ALTER TABLE films ADD COLUMN IF NOT EXISTS download_price_cents BIGINT CHECK (download_price_cents > 0);
+ALTER TABLE films ADD COLUMN IF NOT EXISTS download_currency CHAR(3);
+
+-- One license per paid purchase; each gets its own watermarked MP4
+CREATE TABLE IF NOT EXISTS film_purchases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL UNIQUE REFERENCES film_transactions(id) ON DELETE CASCADE,
+    license_key VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'READY', 'FAILED', 'REVOKED')),
+    download_key TEXT,
+    file_size BIGINT,
+    download_count INTEGER NOT NULL DEFAULT 0,
+    download_limit INTEGER NOT NULL CHECK (download_limit > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    ready_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_film_purchases_user ON film_purchases(user_id, created_at DESC);
@@ -0,0 +1,5 @@
+-- Migration: Rollback release calendar
+-- Down
+
+DROP TRIGGER IF EXISTS update_release_events_updated_at ON release_events;
+DROP TABLE IF EXISTS release_events;
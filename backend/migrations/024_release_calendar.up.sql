@@ -0,0 +1,30 @@
+-- Migration: Release calendar
+-- Up
+
+-- Scheduled publishes, premieres and festival windows coordinated by
+-- admins. A PUBLISH event publishes its film when starts_at passes;
+-- published_at or last_error records the outcome.
+CREATE TABLE IF NOT EXISTS release_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('PUBLISH', 'PREMIERE', 'FESTIVAL')),
+    name VARCHAR(200),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    published_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (ends_at IS NULL OR ends_at > starts_at),
+    CHECK (kind <> 'FESTIVAL' OR (name IS NOT NULL AND ends_at IS NOT NULL))
+);
+
+CREATE INDEX idx_release_events_window ON release_events(starts_at, ends_at);
+
+-- At most one pending scheduled publish per film
+CREATE UNIQUE INDEX idx_release_events_pending_publish ON release_events(film_id)
+    WHERE kind = 'PUBLISH' AND published_at IS NULL;
+
+CREATE TRIGGER update_release_events_updated_at BEFORE UPDATE ON release_events
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
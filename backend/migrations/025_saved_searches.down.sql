@@ -0,0 +1,5 @@
+-- Migration: Rollback saved searches
+-- Down
+
+DROP TRIGGER IF EXISTS update_saved_searches_updated_at ON saved_searches;
+DROP TABLE IF EXISTS saved_searches;
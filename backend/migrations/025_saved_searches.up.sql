@@ -0,0 +1,26 @@
+-- Migration: Saved searches with new-result alerts
+-- Up
+
+-- A user's saved search text and listing filters (NULL means any). The
+-- matcher alerts on films published after checked_until, then advances it.
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    type VARCHAR(20),
+    genre VARCHAR(50),
+    creator_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    min_duration INTEGER,
+    max_duration INTEGER,
+    min_rating NUMERIC(3, 2),
+    email_alerts BOOLEAN NOT NULL DEFAULT FALSE,
+    checked_until TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_saved_searches_user ON saved_searches(user_id, created_at);
+
+CREATE TRIGGER update_saved_searches_updated_at BEFORE UPDATE ON saved_searches
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+-- Migration: Rollback film likes and dislikes
+-- Down
+
+ALTER TABLE films DROP COLUMN IF EXISTS dislike_count;
+ALTER TABLE films DROP COLUMN IF EXISTS like_count;
+
+DROP TABLE IF EXISTS film_reactions;
@@ -0,0 +1,17 @@
+-- Migration: Film likes and dislikes
+-- Up
+
+-- One reaction per user and film; toggling the same reaction removes it
+CREATE TABLE IF NOT EXISTS film_reactions (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    reaction VARCHAR(10) NOT NULL CHECK (reaction IN ('LIKE', 'DISLIKE')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, film_id)
+);
+
+CREATE INDEX idx_film_reactions_film ON film_reactions(film_id);
+
+-- Counts on films, kept in step with film_reactions by the API
+ALTER TABLE films ADD COLUMN IF NOT EXISTS like_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE films ADD COLUMN IF NOT EXISTS dislike_count INTEGER NOT NULL DEFAULT 0;
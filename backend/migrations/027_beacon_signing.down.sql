@@ -0,0 +1,6 @@
+-- Migration: Rollback beacon token flags
+-- Down
+
+DROP INDEX IF EXISTS idx_analytics_events_unverified;
+
+ALTER TABLE analytics_events DROP COLUMN IF EXISTS unverified;
@@ -0,0 +1,6 @@
+-- Migration: Flag playback events sent without a valid beacon token
+-- Up
+
+ALTER TABLE analytics_events ADD COLUMN IF NOT EXISTS unverified BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX idx_analytics_events_unverified ON analytics_events(occurred_at) WHERE unverified;
@@ -0,0 +1,9 @@
+-- Migration: Rollback comments and ghost users
+-- Down
+
+DROP TRIGGER IF EXISTS update_comments_updated_at ON comments;
+DROP TABLE IF EXISTS comments;
+
+DELETE FROM users WHERE ghost_owner_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_users_ghost_name;
+ALTER TABLE users DROP COLUMN IF EXISTS ghost_owner_id;
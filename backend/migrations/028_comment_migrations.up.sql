@@ -0,0 +1,27 @@
+-- Migration: Comments and ghost users for imported comment history
+-- Up
+
+-- Ghost users stand in for comment authors imported from other platforms.
+-- They belong to the creator who imported them and cannot sign in.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS ghost_owner_id UUID REFERENCES users(id) ON DELETE CASCADE;
+CREATE UNIQUE INDEX idx_users_ghost_name ON users(ghost_owner_id, name) WHERE ghost_owner_id IS NOT NULL;
+
+-- Comments on films; replies are one level deep. external_id keeps the id
+-- an imported comment had on its original platform so re-imports skip it.
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES comments(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    external_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_comments_film ON comments(film_id, created_at DESC);
+CREATE INDEX idx_comments_parent ON comments(parent_id) WHERE parent_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_comments_external ON comments(film_id, external_id) WHERE external_id IS NOT NULL;
+
+CREATE TRIGGER update_comments_updated_at BEFORE UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+-- Migration: Rollback film ratings and reviews
+-- Down
+
+DROP TABLE IF EXISTS review_flags;
+DROP TABLE IF EXISTS film_reviews;
+
+UPDATE films SET average_rating = 0, rating_count = 0;
@@ -0,0 +1,36 @@
+-- Migration: Film ratings, written reviews and review flags
+-- Up
+
+-- One rating per user per film, optionally with review text. Hidden
+-- reviews (auto-hidden by flags or by a moderator) are left out of listings
+-- and of the film's rating summary.
+CREATE TABLE IF NOT EXISTS film_reviews (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    flag_count INTEGER NOT NULL DEFAULT 0,
+    hidden BOOLEAN NOT NULL DEFAULT false,
+    moderated_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    moderated_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT film_reviews_rating_check CHECK (rating BETWEEN 1 AND 5),
+    CONSTRAINT film_reviews_unique UNIQUE (film_id, user_id)
+);
+
+CREATE INDEX idx_film_reviews_film ON film_reviews(film_id, created_at DESC) WHERE NOT hidden;
+CREATE INDEX idx_film_reviews_flagged ON film_reviews(flag_count DESC) WHERE flag_count > 0;
+
+-- One flag per user per review
+CREATE TABLE IF NOT EXISTS review_flags (
+    review_id UUID NOT NULL REFERENCES film_reviews(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (review_id, user_id)
+);
+
+CREATE TRIGGER update_film_reviews_updated_at BEFORE UPDATE ON film_reviews
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
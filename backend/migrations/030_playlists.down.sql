@@ -0,0 +1,5 @@
+-- Migration: Rollback playlists
+-- Down
+
+DROP TABLE IF EXISTS playlist_items;
+DROP TABLE IF EXISTS playlists;
@@ -0,0 +1,33 @@
+-- Migration: Manual and smart playlists
+-- Up
+
+-- Manual playlists list films in a chosen order. Smart playlists hold
+-- rules instead and are evaluated against the catalog when read.
+CREATE TABLE IF NOT EXISTS playlists (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind VARCHAR(10) NOT NULL,
+    rules JSONB,
+    public BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT playlists_kind_check CHECK (kind IN ('MANUAL', 'SMART')),
+    CONSTRAINT playlists_rules_check CHECK ((kind = 'SMART') = (rules IS NOT NULL))
+);
+
+CREATE INDEX idx_playlists_owner ON playlists(owner_id, created_at);
+
+CREATE TABLE IF NOT EXISTS playlist_items (
+    playlist_id UUID NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (playlist_id, film_id)
+);
+
+CREATE INDEX idx_playlist_items_position ON playlist_items(playlist_id, position);
+
+CREATE TRIGGER update_playlists_updated_at BEFORE UPDATE ON playlists
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,11 @@
+-- Migration: Rollback threaded comment counts
+-- Down
+
+DROP INDEX IF EXISTS idx_comments_parent;
+CREATE INDEX idx_comments_parent ON comments(parent_id) WHERE parent_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_comments_film_top;
+DROP INDEX IF EXISTS idx_comments_film_threads;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS reply_count;
+ALTER TABLE films DROP COLUMN IF EXISTS comment_count;
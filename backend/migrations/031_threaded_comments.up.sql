@@ -0,0 +1,21 @@
+-- Migration: Threaded comments with edit tracking and counts
+-- Up
+
+ALTER TABLE films ADD COLUMN IF NOT EXISTS comment_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS reply_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP WITH TIME ZONE;
+
+-- Count comments imported before counts were kept
+UPDATE comments c SET reply_count = r.replies
+FROM (SELECT parent_id, COUNT(*) AS replies FROM comments WHERE parent_id IS NOT NULL GROUP BY parent_id) r
+WHERE c.id = r.parent_id;
+
+UPDATE films f SET comment_count = c.comments
+FROM (SELECT film_id, COUNT(*) AS comments FROM comments GROUP BY film_id) c
+WHERE f.id = c.film_id;
+
+-- Top-level comments, newest first and by replies; replies oldest first
+CREATE INDEX idx_comments_film_threads ON comments(film_id, created_at DESC) WHERE parent_id IS NULL;
+CREATE INDEX idx_comments_film_top ON comments(film_id, reply_count DESC, created_at DESC) WHERE parent_id IS NULL;
+DROP INDEX IF EXISTS idx_comments_parent;
+CREATE INDEX idx_comments_parent ON comments(parent_id, created_at) WHERE parent_id IS NOT NULL;
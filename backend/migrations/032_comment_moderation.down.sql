@@ -0,0 +1,18 @@
+-- Migration: Rollback comment settings, pinning, hiding and reports
+-- Down
+
+DROP TABLE IF EXISTS comment_reports;
+
+DROP INDEX IF EXISTS idx_comments_reported;
+DROP INDEX IF EXISTS idx_comments_held;
+DROP INDEX IF EXISTS idx_comments_pinned;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS moderated_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS moderated_by_id;
+ALTER TABLE comments DROP COLUMN IF EXISTS report_count;
+ALTER TABLE comments DROP COLUMN IF EXISTS pinned_at;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_status_check;
+ALTER TABLE comments DROP COLUMN IF EXISTS status;
+
+ALTER TABLE films DROP CONSTRAINT IF EXISTS films_comment_mode_check;
+ALTER TABLE films DROP COLUMN IF EXISTS comment_mode;
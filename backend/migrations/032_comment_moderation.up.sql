@@ -0,0 +1,31 @@
+-- Migration: Comment settings, pinning, hiding and reports
+-- Up
+
+-- ENABLED comments appear right away, HELD comments wait for the creator's
+-- approval and DISABLED films take no comments and show none
+ALTER TABLE films ADD COLUMN IF NOT EXISTS comment_mode VARCHAR(20) NOT NULL DEFAULT 'ENABLED';
+ALTER TABLE films ADD CONSTRAINT films_comment_mode_check CHECK (comment_mode IN ('ENABLED', 'HELD', 'DISABLED'));
+
+-- Only VISIBLE comments are listed and counted in comment_count and
+-- reply_count. HELD comments await approval; HIDDEN ones were hidden by the
+-- film's creator or a moderator.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'VISIBLE';
+ALTER TABLE comments ADD CONSTRAINT comments_status_check CHECK (status IN ('VISIBLE', 'HELD', 'HIDDEN'));
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS pinned_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS report_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS moderated_by_id UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP WITH TIME ZONE;
+
+-- At most one pinned comment per film
+CREATE UNIQUE INDEX idx_comments_pinned ON comments(film_id) WHERE pinned_at IS NOT NULL;
+CREATE INDEX idx_comments_held ON comments(film_id, created_at) WHERE status = 'HELD';
+CREATE INDEX idx_comments_reported ON comments(report_count DESC) WHERE report_count > 0;
+
+-- One report per user per comment
+CREATE TABLE IF NOT EXISTS comment_reports (
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id)
+);
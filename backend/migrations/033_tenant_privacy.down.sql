@@ -0,0 +1,4 @@
+-- Migration: Rollback viewer data minimization for organizations
+-- Down
+
+ALTER TABLE tenants DROP COLUMN IF EXISTS privacy_mode;
@@ -0,0 +1,7 @@
+-- Migration: Viewer data minimization for organizations
+-- Up
+
+-- Organizations in privacy mode keep no per-user viewing data for their
+-- films: no watch history or playback positions, and analytics events are
+-- stored without user, IP address or user agent. Aggregate counts remain.
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS privacy_mode BOOLEAN NOT NULL DEFAULT FALSE;
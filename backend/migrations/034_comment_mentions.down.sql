@@ -0,0 +1,7 @@
+-- Migration: Rollback user handles and @-mentions in comments
+-- Down
+
+DROP TABLE IF EXISTS comment_mentions;
+
+DROP INDEX IF EXISTS idx_users_handle;
+ALTER TABLE users DROP COLUMN IF EXISTS handle;
@@ -0,0 +1,19 @@
+-- Migration: User handles and @-mentions in comments
+-- Up
+
+-- Handles are optional and unique regardless of case; only users with a
+-- handle can be mentioned
+ALTER TABLE users ADD COLUMN IF NOT EXISTS handle VARCHAR(30);
+CREATE UNIQUE INDEX idx_users_handle ON users(LOWER(handle)) WHERE handle IS NOT NULL;
+
+-- Users mentioned in a comment. notified_at is set once the mentioned user
+-- has been notified, which waits until the comment is visible.
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX idx_comment_mentions_user ON comment_mentions(user_id, created_at DESC);
@@ -0,0 +1,8 @@
+-- Migration: Rollback per-film policy document
+-- Down
+
+ALTER TABLE users DROP COLUMN IF EXISTS birth_date;
+
+DROP INDEX IF EXISTS idx_films_listed;
+ALTER TABLE films DROP COLUMN IF EXISTS visibility;
+ALTER TABLE films DROP COLUMN IF EXISTS policy;
@@ -0,0 +1,25 @@
+-- Migration: Per-film policy document
+-- Up
+
+-- The policy document is the source of truth for who may see, watch,
+-- comment on, download and embed a film. comment_mode, allowed_regions,
+-- blocked_regions and visibility are copies kept for SQL filtering.
+ALTER TABLE films ADD COLUMN IF NOT EXISTS policy JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE films ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'PUBLIC';
+
+UPDATE films SET policy = jsonb_build_object(
+    'visibility', 'PUBLIC',
+    'comments', comment_mode,
+    'downloads', true,
+    'embeds', true,
+    'min_age', 0,
+    'regions', jsonb_build_object(
+        'allowed', to_jsonb(allowed_regions),
+        'blocked', to_jsonb(blocked_regions)
+    )
+);
+
+CREATE INDEX idx_films_listed ON films(published_at DESC) WHERE visibility = 'PUBLIC';
+
+-- Age-gated films need the viewer's age
+ALTER TABLE users ADD COLUMN IF NOT EXISTS birth_date DATE;
@@ -0,0 +1,4 @@
+-- Migration: Rollback rendition pruning
+-- Down
+
+DROP TABLE IF EXISTS rendition_prunes;
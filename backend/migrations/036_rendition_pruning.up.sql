@@ -0,0 +1,21 @@
+-- Migration: Rendition pruning
+-- Up
+
+-- One row per film the pruning job has taken on. Pruned renditions are
+-- deleted from storage; the row records what went and the bytes freed
+-- until the film is restored by re-transcoding them. A film with a row,
+-- restored or not, is not pruned again.
+CREATE TABLE IF NOT EXISTS rendition_prunes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL UNIQUE REFERENCES films(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL,
+    kept TEXT[] NOT NULL,
+    pruned TEXT[] NOT NULL DEFAULT '{}',
+    bytes_freed BIGINT NOT NULL DEFAULT 0,
+    queued_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    pruned_at TIMESTAMP WITH TIME ZONE,
+    restore_task_id UUID,
+    restored_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_rendition_prunes_pruned ON rendition_prunes(pruned_at DESC) WHERE pruned_at IS NOT NULL;
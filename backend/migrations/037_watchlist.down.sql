@@ -0,0 +1,4 @@
+-- Migration: Rollback watchlist
+-- Down
+
+DROP TABLE IF EXISTS watchlist_items;
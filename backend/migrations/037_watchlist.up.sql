@@ -0,0 +1,12 @@
+-- Migration: Watchlist
+-- Up
+
+-- Films a user saved for later
+CREATE TABLE IF NOT EXISTS watchlist_items (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, film_id)
+);
+
+CREATE INDEX idx_watchlist_items_user_added ON watchlist_items(user_id, added_at DESC);
@@ -0,0 +1,4 @@
+-- Migration: Rollback playback session logs
+-- Down
+
+DROP TABLE IF EXISTS playback_logs;
@@ -0,0 +1,27 @@
+-- Migration: Playback session logs
+-- Up
+
+-- Structured steps of each playback request (entitlement, geo check,
+-- token issuance, errors) for support investigations. Kept briefly; IP
+-- addresses are stored truncated to their network prefix. film_id has no
+-- foreign key so requests for unknown films are logged too.
+CREATE TABLE IF NOT EXISTS playback_logs (
+    id BIGSERIAL PRIMARY KEY,
+    session_id UUID NOT NULL,
+    film_id UUID NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    endpoint VARCHAR(20) NOT NULL,
+    stage VARCHAR(20) NOT NULL,
+    outcome VARCHAR(10) NOT NULL CHECK (outcome IN ('OK', 'DENIED', 'ERROR')),
+    reason TEXT NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    ip_prefix VARCHAR(64),
+    user_agent VARCHAR(256),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_playback_logs_user ON playback_logs(user_id, created_at DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_playback_logs_film ON playback_logs(film_id, created_at DESC);
+CREATE INDEX idx_playback_logs_session ON playback_logs(session_id);
+CREATE INDEX idx_playback_logs_created ON playback_logs(created_at);
@@ -0,0 +1,4 @@
+-- Migration: Rollback time-coded share links
+-- Down
+
+DROP TABLE IF EXISTS share_links;
@@ -0,0 +1,12 @@
+-- Migration: Time-coded share links
+-- Up
+
+-- Short codes for sharing a film from a start offset. Links are shared by
+-- everyone sharing the same film and offset.
+CREATE TABLE IF NOT EXISTS share_links (
+    code VARCHAR(16) PRIMARY KEY,
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    start_seconds INTEGER NOT NULL DEFAULT 0 CHECK (start_seconds >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (film_id, start_seconds)
+);
@@ -0,0 +1,4 @@
+-- Migration: Rollback recommendation feature logs
+-- Down
+
+DROP TABLE IF EXISTS recommendation_feature_logs;
@@ -0,0 +1,22 @@
+-- Migration: Recommendation feature logs
+-- Up
+
+-- Every candidate a recommender considered for a user, with the features
+-- it was ranked on and where it landed, so ranking models can be trained
+-- against what the user went on to watch. Only written while the
+-- recommend.feature_logging setting is on.
+CREATE TABLE IF NOT EXISTS recommendation_feature_logs (
+    id BIGSERIAL PRIMARY KEY,
+    list_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    model VARCHAR(64) NOT NULL,
+    rank INTEGER, -- 1-based position in the kept list; NULL when cut
+    score DOUBLE PRECISION,
+    features JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recommendation_feature_logs_list ON recommendation_feature_logs(list_id);
+CREATE INDEX idx_recommendation_feature_logs_user ON recommendation_feature_logs(user_id, created_at DESC);
+CREATE INDEX idx_recommendation_feature_logs_created ON recommendation_feature_logs(created_at);
@@ -0,0 +1,9 @@
+-- Migration: Rollback rendition prefetch hints
+-- Down
+
+ALTER TABLE video_assets
+    DROP COLUMN IF EXISTS first_segment_url,
+    DROP COLUMN IF EXISTS segment_count,
+    DROP COLUMN IF EXISTS avg_segment_bytes,
+    DROP COLUMN IF EXISTS avg_segment_seconds,
+    DROP COLUMN IF EXISTS bandwidth;
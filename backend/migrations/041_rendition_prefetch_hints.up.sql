@@ -0,0 +1,12 @@
+-- Migration: Rendition prefetch hints
+-- Up
+
+-- Measured at transcode so players can prefetch a rendition's first
+-- segment and pick a starting rendition from real bitrates. Renditions
+-- transcoded before this keep zeros.
+ALTER TABLE video_assets
+    ADD COLUMN IF NOT EXISTS first_segment_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS segment_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS avg_segment_bytes BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS avg_segment_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS bandwidth INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- Migration: Rollback rendition QC scores
+-- Down
+
+ALTER TABLE video_assets
+    DROP COLUMN IF EXISTS vmaf,
+    DROP COLUMN IF EXISTS psnr,
+    DROP COLUMN IF EXISTS qc_at;
@@ -0,0 +1,11 @@
+-- Migration: Rendition QC scores
+-- Up
+
+-- VMAF and PSNR of a rendition against the original video, measured by
+-- the optional QC stage of a re-transcode so admins can compare versions
+-- before swapping them. NULL until measured; VMAF stays NULL when FFmpeg
+-- is built without libvmaf.
+ALTER TABLE video_assets
+    ADD COLUMN IF NOT EXISTS vmaf DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS psnr DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS qc_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,5 @@
+-- Migration: Rollback quality alerts
+-- Down
+
+DROP INDEX IF EXISTS idx_video_assets_quality_qc;
+DROP TABLE IF EXISTS quality_alerts;
@@ -0,0 +1,21 @@
+-- Migration: Quality alerts
+-- Up
+
+-- Renditions whose post-encode VMAF fell below the floor, or well below
+-- recent renditions of the same quality, for admins to look into
+CREATE TABLE IF NOT EXISTS quality_alerts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    variant VARCHAR(64) NOT NULL,
+    quality VARCHAR(16) NOT NULL,
+    reason VARCHAR(16) NOT NULL, -- below_floor or regression
+    vmaf DOUBLE PRECISION NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL, -- the floor, or the baseline it fell from
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_quality_alerts_created ON quality_alerts(created_at DESC);
+CREATE INDEX idx_quality_alerts_film ON quality_alerts(film_id);
+
+-- Recent scores per quality are the regression baseline
+CREATE INDEX idx_video_assets_quality_qc ON video_assets(quality, qc_at DESC) WHERE vmaf IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Migration: Rollback analytics watch time
+-- Down
+
+ALTER TABLE analytics_rollups
+    DROP COLUMN IF EXISTS watch_seconds;
@@ -0,0 +1,8 @@
+-- Migration: Analytics watch time
+-- Up
+
+-- Seconds watched, rolled up from heartbeat events so creator analytics
+-- keep watch time after raw heartbeats are deleted. Zero for other event
+-- types; summed from days for monthly rows.
+ALTER TABLE analytics_rollups
+    ADD COLUMN IF NOT EXISTS watch_seconds BIGINT NOT NULL DEFAULT 0;
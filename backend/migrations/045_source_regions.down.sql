@@ -0,0 +1,5 @@
+-- Migration: Rollback source regions
+-- Down
+
+ALTER TABLE films
+    DROP COLUMN IF EXISTS source_region;
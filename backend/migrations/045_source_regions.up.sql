@@ -0,0 +1,8 @@
+-- Migration: Source regions
+-- Up
+
+-- Region label of the bucket holding a film's uploaded source, so jobs
+-- that read it are routed to workers in the same region. Empty for
+-- sources in the primary bucket.
+ALTER TABLE films
+    ADD COLUMN IF NOT EXISTS source_region VARCHAR(32) NOT NULL DEFAULT '';
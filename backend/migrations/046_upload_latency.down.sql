@@ -0,0 +1,5 @@
+-- Migration: Rollback upload latency
+-- Down
+
+DROP TABLE IF EXISTS upload_latency_rollups;
+DROP TABLE IF EXISTS upload_timings;
@@ -0,0 +1,29 @@
+-- Migration: Upload latency
+-- Up
+
+-- When each lifecycle stage of a film's latest upload was reached, from
+-- requesting the upload URL to the film being ready. Later stages are
+-- cleared when an earlier one is reached again, e.g. on re-upload.
+CREATE TABLE IF NOT EXISTS upload_timings (
+    film_id UUID PRIMARY KEY REFERENCES films(id) ON DELETE CASCADE,
+    upload_started_at TIMESTAMP WITH TIME ZONE,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    queued_at TIMESTAMP WITH TIME ZONE,
+    encode_started_at TIMESTAMP WITH TIME ZONE,
+    ready_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_timings_ready_at ON upload_timings(ready_at);
+
+-- Daily latency percentiles of each stage, for films that became ready
+-- that day
+CREATE TABLE IF NOT EXISTS upload_latency_rollups (
+    day DATE NOT NULL,
+    stage VARCHAR(20) NOT NULL,
+    films INTEGER NOT NULL,
+    avg_seconds DOUBLE PRECISION NOT NULL,
+    p50_seconds DOUBLE PRECISION NOT NULL,
+    p90_seconds DOUBLE PRECISION NOT NULL,
+    p99_seconds DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (day, stage)
+);
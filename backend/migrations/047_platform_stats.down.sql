@@ -0,0 +1,9 @@
+-- Migration: Rollback platform stats
+-- Down
+
+DROP TABLE IF EXISTS platform_daily_stats;
+
+DROP INDEX IF EXISTS idx_upload_timings_confirmed_at;
+DROP INDEX IF EXISTS idx_upload_timings_failed_at;
+ALTER TABLE upload_timings
+    DROP COLUMN IF EXISTS failed_at;
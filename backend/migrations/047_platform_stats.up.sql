@@ -0,0 +1,23 @@
+-- Migration: Platform stats
+-- Up
+
+-- When a film's latest transcode failed; cleared when its upload reaches
+-- a lifecycle stage again
+ALTER TABLE upload_timings
+    ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_upload_timings_failed_at ON upload_timings(failed_at);
+CREATE INDEX IF NOT EXISTS idx_upload_timings_confirmed_at ON upload_timings(confirmed_at);
+
+-- Platform-wide totals per day, rolled up nightly for admin analytics.
+-- storage_bytes is a snapshot taken when the day is rolled up, NULL for
+-- days rolled up later than the next day.
+CREATE TABLE IF NOT EXISTS platform_daily_stats (
+    day DATE PRIMARY KEY,
+    signups INTEGER NOT NULL DEFAULT 0,
+    uploads INTEGER NOT NULL DEFAULT 0,
+    transcodes_completed INTEGER NOT NULL DEFAULT 0,
+    transcodes_failed INTEGER NOT NULL DEFAULT 0,
+    storage_bytes BIGINT,
+    rolled_up_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
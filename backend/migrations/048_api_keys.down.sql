@@ -0,0 +1,5 @@
+-- Migration: Rollback API keys
+-- Down
+
+DROP TABLE IF EXISTS api_key_usage;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,34 @@
+-- Migration: API keys
+-- Up
+
+-- Keys for scripts and integrations acting as their user. Only a SHA-256
+-- hash of each key is stored; prefix identifies it in listings. NULL
+-- limits fall back to the ratelimit.api_key_* settings.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    rate_limit_per_minute INTEGER CHECK (rate_limit_per_minute > 0),
+    daily_quota INTEGER CHECK (daily_quota >= 0),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys(user_id, created_at DESC);
+
+-- Hourly usage of each key, flushed from Redis
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    hour TIMESTAMP WITH TIME ZONE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    client_errors BIGINT NOT NULL DEFAULT 0,
+    server_errors BIGINT NOT NULL DEFAULT 0,
+    rate_limited BIGINT NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, hour)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_usage_hour ON api_key_usage(hour);
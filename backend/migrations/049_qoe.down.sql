@@ -0,0 +1,4 @@
+-- Migration: Rollback playback quality of experience
+-- Down
+
+DROP TABLE IF EXISTS qoe_rollups;
@@ -0,0 +1,18 @@
+-- Migration: Playback quality of experience
+-- Up
+
+-- Startup time, rebuffering, watch time and bitrate per rendition and day,
+-- rolled up from startup, rebuffer and heartbeat events with the other
+-- analytics rollups. rendition is '' when the player did not say.
+CREATE TABLE IF NOT EXISTS qoe_rollups (
+    day DATE NOT NULL,
+    rendition VARCHAR(16) NOT NULL,
+    startups BIGINT NOT NULL DEFAULT 0,
+    startup_ms BIGINT NOT NULL DEFAULT 0,
+    rebuffers BIGINT NOT NULL DEFAULT 0,
+    rebuffer_ms BIGINT NOT NULL DEFAULT 0,
+    watch_seconds BIGINT NOT NULL DEFAULT 0,
+    bits_played BIGINT NOT NULL DEFAULT 0,
+    bitrate_seconds BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, rendition)
+);
@@ -0,0 +1,8 @@
+-- Migration: Rollback analytics breakdowns
+-- Down
+
+DROP TABLE IF EXISTS analytics_breakdown_rollups;
+
+ALTER TABLE analytics_events
+    DROP COLUMN IF EXISTS os,
+    DROP COLUMN IF EXISTS device;
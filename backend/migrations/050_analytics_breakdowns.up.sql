@@ -0,0 +1,24 @@
+-- Migration: Analytics breakdowns
+-- Up
+
+-- Device class and operating system family, classified from the user
+-- agent on ingestion and kept when events are anonymized. '' for events
+-- recorded before.
+ALTER TABLE analytics_events
+    ADD COLUMN IF NOT EXISTS device VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS os VARCHAR(16) NOT NULL DEFAULT '';
+
+-- Daily event counts per film broken down by country, device or os,
+-- rolled up with the other analytics rollups. value is '' when unknown.
+CREATE TABLE IF NOT EXISTS analytics_breakdown_rollups (
+    day DATE NOT NULL,
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    dimension VARCHAR(16) NOT NULL,
+    value VARCHAR(16) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    event_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, film_id, dimension, value, event_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_breakdown_rollups_film ON analytics_breakdown_rollups(film_id, dimension, day);
+CREATE INDEX IF NOT EXISTS idx_analytics_breakdown_rollups_dimension ON analytics_breakdown_rollups(dimension, day);
@@ -0,0 +1,11 @@
+-- Migration: Rollback ghost account for deleted users' content
+-- Down
+
+DROP TABLE IF EXISTS account_deletions;
+
+ALTER TABLE films
+    DROP COLUMN IF EXISTS on_owner_deletion,
+    DROP COLUMN IF EXISTS attribution;
+
+-- Films still owned by the ghost account are deleted with it
+DELETE FROM users WHERE id = '00000000-0000-0000-0000-000000000001';
@@ -0,0 +1,30 @@
+-- Migration: Ghost account for deleted users' content
+-- Up
+
+-- The system account that takes over films kept when their owner's
+-- account is deleted. It cannot sign in.
+INSERT INTO users (id, email, password_hash, role, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'deleted-accounts@system.filmtube.invalid', '', 'CREATOR', 'Deleted account')
+ON CONFLICT (id) DO NOTHING;
+
+-- attribution keeps the original creator's name on films reassigned to
+-- the ghost account. on_owner_deletion overrides what happens to a film
+-- when its owner's account is deleted: 'auto' keeps it when published or
+-- owned by an organization, 'keep' and 'delete' always do that.
+ALTER TABLE films
+    ADD COLUMN IF NOT EXISTS attribution VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS on_owner_deletion VARCHAR(8) NOT NULL DEFAULT 'auto'
+        CHECK (on_owner_deletion IN ('auto', 'keep', 'delete'));
+
+-- Completed account deletions, for audit. The user row itself is gone.
+CREATE TABLE IF NOT EXISTS account_deletions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    deleted_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    films_kept INTEGER NOT NULL DEFAULT 0,
+    films_deleted INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_deletions_created ON account_deletions(created_at DESC);
@@ -0,0 +1,4 @@
+-- Migration: Rollback per-film egress
+-- Down
+
+DROP TABLE IF EXISTS film_egress_daily;
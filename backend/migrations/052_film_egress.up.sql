@@ -0,0 +1,16 @@
+-- Migration: Per-film egress
+-- Up
+
+-- Bytes delivered per film and day, for cost attribution.
+-- estimated_bytes is rolled up from heartbeat watch time at the bitrate of
+-- the rendition played, from its segment sizes; cdn_bytes is ingested from
+-- CDN logs and wins over the estimate when present.
+CREATE TABLE IF NOT EXISTS film_egress_daily (
+    day DATE NOT NULL,
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    estimated_bytes BIGINT NOT NULL DEFAULT 0,
+    cdn_bytes BIGINT,
+    PRIMARY KEY (day, film_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_film_egress_daily_film ON film_egress_daily(film_id, day);
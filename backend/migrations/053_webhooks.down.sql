@@ -0,0 +1,6 @@
+-- Migration: Rollback outgoing webhooks
+-- Down
+
+DROP TABLE IF EXISTS webhook_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
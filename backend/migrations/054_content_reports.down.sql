@@ -0,0 +1,5 @@
+-- Migration: Rollback categorized film and comment reports
+-- Down
+
+DROP TABLE IF EXISTS film_reports;
+ALTER TABLE comment_reports DROP COLUMN IF EXISTS category;
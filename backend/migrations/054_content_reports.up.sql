@@ -0,0 +1,24 @@
+-- Migration: Categorized film and comment reports
+-- Up
+
+-- Existing comment reports predate categories
+ALTER TABLE comment_reports ADD COLUMN IF NOT EXISTS category VARCHAR(20) NOT NULL DEFAULT 'other'
+    CHECK (category IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'copyright', 'misleading', 'other'));
+
+-- Reports of films. Open reports have no resolved_at; resolved ones are
+-- kept, and the same user can report the film again.
+CREATE TABLE IF NOT EXISTS film_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(20) NOT NULL
+        CHECK (category IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'copyright', 'misleading', 'other')),
+    reason VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolved_by_id UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- One open report per user per film
+CREATE UNIQUE INDEX IF NOT EXISTS idx_film_reports_open ON film_reports(film_id, user_id) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_film_reports_user ON film_reports(user_id, created_at DESC);
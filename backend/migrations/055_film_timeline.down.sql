@@ -0,0 +1,5 @@
+-- Migration: Rollback film timeline markers and thumbnail sprites
+-- Down
+
+DROP TABLE IF EXISTS film_sprites;
+DROP TABLE IF EXISTS film_markers;
@@ -0,0 +1,30 @@
+-- Migration: Film timeline markers and thumbnail sprites
+-- Up
+
+-- Creator-authored points and ranges on a film's timeline. Chapters and ad
+-- breaks may be points (no end); intros and credits are always ranges.
+CREATE TABLE IF NOT EXISTS film_markers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('CHAPTER', 'AD', 'INTRO', 'CREDITS')),
+    title VARCHAR(200) NOT NULL DEFAULT '',
+    start_seconds DOUBLE PRECISION NOT NULL CHECK (start_seconds >= 0),
+    end_seconds DOUBLE PRECISION CHECK (end_seconds > start_seconds),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_film_markers_film ON film_markers(film_id, start_seconds);
+
+-- The thumbnail sprite sheet cut at transcode: one frame every
+-- interval_seconds, laid out in a columns x rows grid of tiles
+CREATE TABLE IF NOT EXISTS film_sprites (
+    film_id UUID PRIMARY KEY REFERENCES films(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    interval_seconds DOUBLE PRECISION NOT NULL,
+    frame_count INTEGER NOT NULL,
+    columns INTEGER NOT NULL,
+    rows INTEGER NOT NULL,
+    tile_width INTEGER NOT NULL,
+    tile_height INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- Migration: Rollback moderation queue actions and audit log
+-- Down
+
+DROP TABLE IF EXISTS moderation_audit_log;
+ALTER TABLE users DROP COLUMN IF EXISTS banned_at;
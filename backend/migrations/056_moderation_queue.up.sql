@@ -0,0 +1,24 @@
+-- Migration: Moderation queue actions and audit log
+-- Up
+
+-- Banned users cannot sign in; their films are unpublished
+ALTER TABLE users ADD COLUMN IF NOT EXISTS banned_at TIMESTAMP WITH TIME ZONE;
+
+-- Every action taken from the moderation queue. Target and subject IDs
+-- are kept without foreign keys so entries outlive what they point at.
+CREATE TABLE IF NOT EXISTS moderation_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    admin_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('dismiss', 'age_restrict', 'unpublish', 'ban_creator')),
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('film', 'comment')),
+    target_id UUID NOT NULL,
+    film_id UUID NOT NULL,
+    -- The film's creator or the comment's author
+    subject_user_id UUID NOT NULL,
+    reports_resolved INTEGER NOT NULL DEFAULT 0,
+    reason VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_audit_log_created ON moderation_audit_log(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_moderation_audit_log_target ON moderation_audit_log(target_id, created_at DESC);
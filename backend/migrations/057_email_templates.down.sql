@@ -0,0 +1,4 @@
+-- Migration: Rollback per-organization and per-creator email templates
+-- Down
+
+DROP TABLE IF EXISTS email_templates;
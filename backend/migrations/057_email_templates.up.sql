@@ -0,0 +1,30 @@
+-- Migration: Per-organization and per-creator email templates
+-- Up
+
+-- Overrides of system email templates. An organization's template brands
+-- email about its films, a creator's about theirs; without one the system
+-- default is sent. body is the source as written (HTML or MJML) and html
+-- its compiled form; an empty subject or text_body keeps the default's.
+CREATE TABLE IF NOT EXISTS email_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_key VARCHAR(100) NOT NULL,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    creator_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('html', 'mjml')),
+    subject VARCHAR(300) NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    html TEXT NOT NULL,
+    text_body TEXT NOT NULL DEFAULT '',
+    updated_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK ((tenant_id IS NULL) <> (creator_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_templates_tenant ON email_templates(template_key, tenant_id)
+    WHERE tenant_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_templates_creator ON email_templates(template_key, creator_id)
+    WHERE creator_id IS NOT NULL;
+
+CREATE TRIGGER update_email_templates_updated_at BEFORE UPDATE ON email_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+-- Migration: Rollback malware scans of uploaded files and quarantine
+-- Down
+
+DROP TABLE IF EXISTS file_scans;
@@ -0,0 +1,26 @@
+-- Migration: Malware scans of uploaded files and quarantine
+-- Up
+
+-- One row per scan of an uploaded file. Infected files are moved to
+-- quarantine_key and the film fails; signature and error are for admins
+-- only.
+CREATE TABLE IF NOT EXISTS file_scans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('SOURCE')),
+    object_key VARCHAR(500) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'CLEAN', 'INFECTED', 'ERROR', 'SKIPPED')),
+    scanner VARCHAR(50) NOT NULL DEFAULT '',
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    sha256 VARCHAR(64) NOT NULL DEFAULT '',
+    signature VARCHAR(500) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    quarantine_key VARCHAR(500),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    scanned_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_scans_film ON file_scans(film_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_file_scans_infected ON file_scans(scanned_at DESC, id DESC)
+    WHERE status = 'INFECTED';
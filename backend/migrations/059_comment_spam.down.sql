@@ -0,0 +1,10 @@
+-- Migration: Rollback shadow-held suspected spam comments
+-- Down
+
+DROP INDEX IF EXISTS idx_comments_held;
+CREATE INDEX idx_comments_held ON comments(film_id, created_at) WHERE status = 'HELD';
+
+UPDATE comments SET status = 'HELD' WHERE status = 'SHADOW_HELD';
+ALTER TABLE comments DROP COLUMN IF EXISTS spam_reason;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_status_check;
+ALTER TABLE comments ADD CONSTRAINT comments_status_check CHECK (status IN ('VISIBLE', 'HELD', 'HIDDEN'));
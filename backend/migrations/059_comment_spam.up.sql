@@ -0,0 +1,12 @@
+-- Migration: Shadow-held suspected spam comments
+-- Up
+
+-- SHADOW_HELD comments tripped a film's spam filter and wait for the
+-- creator's approval like HELD ones, but their author sees them as posted.
+-- spam_reason says why they were held.
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_status_check;
+ALTER TABLE comments ADD CONSTRAINT comments_status_check CHECK (status IN ('VISIBLE', 'HELD', 'HIDDEN', 'SHADOW_HELD'));
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS spam_reason VARCHAR(200);
+
+DROP INDEX IF EXISTS idx_comments_held;
+CREATE INDEX idx_comments_held ON comments(film_id, created_at) WHERE status IN ('HELD', 'SHADOW_HELD');
@@ -0,0 +1,5 @@
+-- Migration: Rollback search index sync watermark and consistency checks
+-- Down
+
+DROP INDEX IF EXISTS idx_films_updated;
+DROP TABLE IF EXISTS search_sync_state;
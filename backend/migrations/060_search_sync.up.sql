@@ -0,0 +1,24 @@
+-- Migration: Search index sync watermark and consistency checks
+-- Up
+
+-- One row per search index kept alongside Postgres. event_cursor is the
+-- film event stream entry synced up to; films updated up to
+-- (watermark_at, watermark_id) are indexed. last_check is the latest
+-- consistency report.
+CREATE TABLE IF NOT EXISTS search_sync_state (
+    index_name VARCHAR(200) PRIMARY KEY,
+    event_cursor VARCHAR(50) NOT NULL DEFAULT '',
+    watermark_at TIMESTAMP WITH TIME ZONE,
+    watermark_id UUID,
+    last_synced_at TIMESTAMP WITH TIME ZONE,
+    last_check JSONB,
+    last_checked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_search_sync_state_updated_at BEFORE UPDATE ON search_sync_state
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Incremental sync pages through films by last update
+CREATE INDEX IF NOT EXISTS idx_films_updated ON films(updated_at, id);
@@ -0,0 +1,4 @@
+-- Migration: Rollback remote player configuration rules
+-- Down
+
+DROP TABLE IF EXISTS player_config_rules;
@@ -0,0 +1,25 @@
+-- Migration: Remote player configuration rules
+-- Up
+
+-- Rules layering changes onto the default player configuration for a
+-- platform and range of app versions. patch is a JSON merge patch; rules
+-- apply in priority order, each to rollout_percent of devices. NULL
+-- platform matches every platform, and an empty version bound is open.
+CREATE TABLE IF NOT EXISTS player_config_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    platform VARCHAR(20),
+    min_app_version VARCHAR(50) NOT NULL DEFAULT '',
+    max_app_version VARCHAR(50) NOT NULL DEFAULT '',
+    rollout_percent INTEGER NOT NULL DEFAULT 100 CHECK (rollout_percent BETWEEN 0 AND 100),
+    priority INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    patch JSONB NOT NULL DEFAULT '{}',
+    updated_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_player_config_rules_updated_at BEFORE UPDATE ON player_config_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,5 @@
+-- Migration: Rollback playback plans and viewer entitlements
+-- Down
+
+DROP TABLE IF EXISTS user_entitlements;
+DROP TABLE IF EXISTS tenant_playback_plans;
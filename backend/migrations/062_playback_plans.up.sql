@@ -0,0 +1,36 @@
+-- Migration: Playback plans and viewer entitlements
+-- Up
+
+-- An organization's own plan catalog, replacing the platform's for its
+-- films: the plans by key, each capping rendition height, and the plan
+-- of viewers without an entitlement
+CREATE TABLE IF NOT EXISTS tenant_playback_plans (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    catalog JSONB NOT NULL,
+    updated_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- A viewer's plan, platform-wide (NULL tenant) or for one organization's
+-- films; NULL expires_at never expires
+CREATE TABLE IF NOT EXISTS user_entitlements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    plan_key VARCHAR(50) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    granted_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_entitlements_scope
+    ON user_entitlements(user_id, COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'::uuid));
+CREATE INDEX IF NOT EXISTS idx_user_entitlements_tenant ON user_entitlements(tenant_id) WHERE tenant_id IS NOT NULL;
+
+CREATE TRIGGER update_tenant_playback_plans_updated_at BEFORE UPDATE ON tenant_playback_plans
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_user_entitlements_updated_at BEFORE UPDATE ON user_entitlements
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
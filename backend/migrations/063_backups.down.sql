@@ -0,0 +1,4 @@
+-- Migration: Rollback platform data backups
+-- Down
+
+DROP TABLE IF EXISTS backup_runs;
@@ -0,0 +1,20 @@
+-- Migration: Platform data backups
+-- Up
+
+-- One run of the nightly backup job; prefix is where its objects live in
+-- the backup bucket
+CREATE TABLE IF NOT EXISTS backup_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'RUNNING',
+    prefix VARCHAR(200) NOT NULL,
+    tables INTEGER NOT NULL DEFAULT 0,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    playlists INTEGER NOT NULL DEFAULT 0,
+    objects BIGINT NOT NULL DEFAULT 0,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+CREATE INDEX idx_backup_runs_started_at ON backup_runs(started_at DESC);
@@ -0,0 +1,5 @@
+-- Migration: Rollback bulk catalog imports
+-- Down
+
+DROP TABLE IF EXISTS catalog_import_items;
+DROP TABLE IF EXISTS catalog_imports;
@@ -0,0 +1,40 @@
+-- Migration: Bulk catalog imports
+-- Up
+
+-- A creator's bulk import of films hosted elsewhere; film_type and
+-- tenant_id apply to every film it creates
+CREATE TABLE IF NOT EXISTS catalog_imports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_by_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE SET NULL,
+    film_type VARCHAR(20) NOT NULL,
+    total INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- One source URL of an import, with the film created for it once its
+-- metadata was fetched
+CREATE TABLE IF NOT EXISTS catalog_import_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    import_id UUID NOT NULL REFERENCES catalog_imports(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    source_url TEXT NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    film_id UUID REFERENCES films(id) ON DELETE SET NULL,
+    title VARCHAR(500),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (import_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_catalog_imports_created_by ON catalog_imports(created_by_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_catalog_import_items_film ON catalog_import_items(film_id) WHERE film_id IS NOT NULL;
+
+CREATE TRIGGER update_catalog_imports_updated_at BEFORE UPDATE ON catalog_imports
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_catalog_import_items_updated_at BEFORE UPDATE ON catalog_import_items
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,9 @@
+-- Migration: Rollback expired films
+-- Down
+
+DROP INDEX IF EXISTS idx_films_status_updated_at;
+
+UPDATE films SET status = 'DRAFT' WHERE status = 'EXPIRED';
+ALTER TABLE films DROP CONSTRAINT films_status_check;
+ALTER TABLE films ADD CONSTRAINT films_status_check
+    CHECK (status IN ('DRAFT', 'UPLOADED', 'TRANSCODING', 'READY', 'FAILED'));
@@ -0,0 +1,11 @@
+-- Migration: Expired films
+-- Up
+
+-- Films whose upload was abandoned are expired by the upload reaper
+ALTER TABLE films DROP CONSTRAINT films_status_check;
+ALTER TABLE films ADD CONSTRAINT films_status_check
+    CHECK (status IN ('DRAFT', 'UPLOADED', 'TRANSCODING', 'READY', 'FAILED', 'EXPIRED'));
+
+-- The reaper looks for unconfirmed films by status and age
+CREATE INDEX idx_films_status_updated_at ON films(status, updated_at)
+    WHERE status IN ('DRAFT', 'UPLOADED');
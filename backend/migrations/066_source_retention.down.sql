@@ -0,0 +1,7 @@
+-- Migration: Rollback original-source retention
+-- Down
+
+ALTER TABLE films
+    DROP COLUMN IF EXISTS source_disposed_at,
+    DROP COLUMN IF EXISTS source_disposition,
+    DROP COLUMN IF EXISTS source_retention;
@@ -0,0 +1,11 @@
+-- Migration: Original-source retention
+-- Up
+
+-- source_retention overrides the platform retention policy for a film;
+-- source_disposition records what the policy did to its source, and when
+ALTER TABLE films
+    ADD COLUMN IF NOT EXISTS source_retention VARCHAR(10)
+        CHECK (source_retention IN ('keep', 'delete', 'archive')),
+    ADD COLUMN IF NOT EXISTS source_disposition VARCHAR(10)
+        CHECK (source_disposition IN ('deleted', 'archived')),
+    ADD COLUMN IF NOT EXISTS source_disposed_at TIMESTAMP WITH TIME ZONE;
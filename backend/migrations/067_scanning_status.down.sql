@@ -0,0 +1,7 @@
+-- Migration: Rollback scanning status
+-- Down
+
+UPDATE films SET status = 'UPLOADED' WHERE status = 'SCANNING';
+ALTER TABLE films DROP CONSTRAINT films_status_check;
+ALTER TABLE films ADD CONSTRAINT films_status_check
+    CHECK (status IN ('DRAFT', 'UPLOADED', 'TRANSCODING', 'READY', 'FAILED', 'EXPIRED'));
@@ -0,0 +1,8 @@
+-- Migration: Scanning status
+-- Up
+
+-- Confirmed uploads wait in SCANNING until their source passes the
+-- malware scan, and only then are queued for transcoding
+ALTER TABLE films DROP CONSTRAINT films_status_check;
+ALTER TABLE films ADD CONSTRAINT films_status_check
+    CHECK (status IN ('DRAFT', 'UPLOADED', 'SCANNING', 'TRANSCODING', 'READY', 'FAILED', 'EXPIRED'));
@@ -0,0 +1,7 @@
+-- Migration: Rollback content classification of transcoded films
+-- Down
+
+DROP TABLE IF EXISTS content_classifications;
+
+-- The classifier's reports are deleted with it
+DELETE FROM users WHERE id = '00000000-0000-0000-0000-000000000002';
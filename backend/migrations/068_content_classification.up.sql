@@ -0,0 +1,26 @@
+-- Migration: Content classification of transcoded films
+-- Up
+
+-- The system account that reports films the content classifier flags to
+-- the moderation queue. It cannot sign in.
+INSERT INTO users (id, email, password_hash, role, name)
+VALUES ('00000000-0000-0000-0000-000000000002', 'content-classifier@system.filmtube.invalid', '', 'CREATOR', 'Content classifier')
+ON CONFLICT (id) DO NOTHING;
+
+-- One row per classification of a film's sampled frames. scores maps each
+-- label to its highest confidence; label and action are set when a score
+-- reached the policy's threshold.
+CREATE TABLE IF NOT EXISTS content_classifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    film_id UUID NOT NULL REFERENCES films(id) ON DELETE CASCADE,
+    classifier VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('COMPLETED', 'SKIPPED', 'ERROR')),
+    frames INTEGER NOT NULL DEFAULT 0,
+    scores JSONB NOT NULL DEFAULT '{}',
+    label VARCHAR(50),
+    action VARCHAR(20) CHECK (action IN ('flag', 'age_restrict')),
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_classifications_film ON content_classifications(film_id, created_at DESC);
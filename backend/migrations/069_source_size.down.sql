@@ -0,0 +1,5 @@
+-- Migration: Rollback source size for creator storage quotas
+-- Down
+
+ALTER TABLE films
+    DROP COLUMN IF EXISTS source_size_bytes;
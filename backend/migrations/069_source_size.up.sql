@@ -0,0 +1,7 @@
+-- Migration: Source size for creator storage quotas
+-- Up
+
+-- The size of a film's source as declared when its upload URL was issued;
+-- NULL for sources uploaded before it was recorded
+ALTER TABLE films
+    ADD COLUMN IF NOT EXISTS source_size_bytes BIGINT;
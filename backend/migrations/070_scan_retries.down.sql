@@ -0,0 +1,6 @@
+-- Migration: Rollback malware scan retries
+-- Down
+
+DROP INDEX IF EXISTS idx_file_scans_retry;
+ALTER TABLE file_scans DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE file_scans DROP COLUMN IF EXISTS attempts;
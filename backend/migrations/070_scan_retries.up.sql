@@ -0,0 +1,11 @@
+-- Migration: Malware scan retries
+-- Up
+
+-- A source scan that reaches no verdict, or whose task is lost, is queued
+-- again at next_attempt_at; its film stays SCANNING meanwhile. attempts
+-- counts the times it was queued again.
+ALTER TABLE file_scans ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE file_scans ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_file_scans_retry ON file_scans(next_attempt_at)
+    WHERE status IN ('PENDING', 'ERROR');
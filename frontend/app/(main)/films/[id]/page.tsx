@@ -11,17 +11,25 @@ async function getFilm(id: string): Promise<Film> {
   }
 }
 
-async function getPlaybackURL(id: string) {
+async function getPlaybackURL(id: string, t?: string) {
   try {
-    return await api.getPlaybackURL(id);
+    return await api.getPlaybackURL(id, false, t);
   } catch (error) {
     return null;
   }
 }
 
-export default async function FilmPage({ params }: { params: { id: string } }) {
+export default async function FilmPage({
+  params,
+  searchParams,
+}: {
+  params: { id: string };
+  searchParams: { t?: string };
+}) {
   const film = await getFilm(params.id);
-  const playbackInfo = await getPlaybackURL(params.id);
+  // ?t= from a time-coded share link; an invalid offset plays from the start
+  const playbackInfo =
+    (await getPlaybackURL(params.id, searchParams.t)) ?? (searchParams.t ? await getPlaybackURL(params.id) : null);
 
   const isReady = film.status === 'READY';
 
@@ -39,16 +47,17 @@ export default async function FilmPage({ params }: { params: { id: string } }) {
                 src={playbackInfo.hls_master_url}
                 poster={playbackInfo.thumbnail_url}
                 title={film.title}
+                startAt={playbackInfo.start_offset || playbackInfo.resume_position}
               />
             ) : (
               <div className="w-full aspect-video bg-black rounded-xl overflow-hidden flex items-center justify-center">
                 <div className="text-center text-white">
                   <span className="material-icons text-6xl mb-4">video_library</span>
                   <p className="text-xl font-semibold mb-2">
-                    {film.status === 'TRANSCODING' ? 'Processing...' : 'Not Ready'}
+                    {film.status === 'SCANNING' || film.status === 'TRANSCODING' ? 'Processing...' : 'Not Ready'}
                   </p>
                   <p className="text-sm text-gray-400">
-                    {film.status === 'TRANSCODING'
+                    {film.status === 'SCANNING' || film.status === 'TRANSCODING'
                       ? 'This film is being processed. Please check back later.'
                       : 'This film is not yet available for playback.'}
                   </p>
@@ -151,7 +160,7 @@ export default async function FilmPage({ params }: { params: { id: string } }) {
                   <span className="text-slate-500 dark:text-slate-400">Status</span>
                   <span className={`font-medium ${
                     film.status === 'READY' ? 'text-green-500' :
-                    film.status === 'TRANSCODING' ? 'text-yellow-500' :
+                    film.status === 'SCANNING' || film.status === 'TRANSCODING' ? 'text-yellow-500' :
                     'text-gray-500'
                   }`}>
                     {film.status}
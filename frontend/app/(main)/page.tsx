@@ -1,19 +1,29 @@
 import { Navigation } from '@/components/Navigation';
 import { FilmCard } from '@/components/FilmCard';
+import Link from 'next/link';
 import { api, type Film } from '@/lib/api';
 
 async function getFilms() {
   try {
     const response = await api.getFilms(1, 20, 'READY');
-    return response.films;
+    return response.items;
   } catch (error) {
     console.error('Failed to fetch films:', error);
     return [];
   }
 }
 
+async function getFeatured() {
+  try {
+    return await api.getFeatured();
+  } catch (error) {
+    console.error('Failed to fetch featured film:', error);
+    return null;
+  }
+}
+
 export default async function HomePage() {
-  const films = await getFilms();
+  const [films, featured] = await Promise.all([getFilms(), getFeatured()]);
 
   return (
     <div className="flex min-h-screen">
@@ -49,8 +59,18 @@ export default async function HomePage() {
 
         {/* Featured Section */}
         <section className="max-w-[2000px] mx-auto p-4 md:p-6 pb-2">
-          <div className="relative w-full rounded-2xl overflow-hidden bg-surface-dark border border-white/5 shadow-2xl flex flex-col md:flex-row h-auto md:h-[300px] group cursor-pointer hover:border-white/20 transition-all">
+          <Link
+            href={featured ? `/films/${featured.film.id}` : '#'}
+            className="relative w-full rounded-2xl overflow-hidden bg-surface-dark border border-white/5 shadow-2xl flex flex-col md:flex-row h-auto md:h-[300px] group cursor-pointer hover:border-white/20 transition-all"
+          >
             <div className="relative w-full md:w-[55%] h-48 md:h-full overflow-hidden bg-gray-900">
+              {featured?.hero_image_url && (
+                <img
+                  src={featured.hero_image_url}
+                  alt={featured.headline || featured.film.title}
+                  className="absolute inset-0 w-full h-full object-cover"
+                />
+              )}
               <div className="absolute inset-0 bg-gradient-to-r from-primary/20 to-transparent" />
             </div>
             <div className="flex-1 p-6 md:p-8 flex flex-col justify-center bg-gradient-to-b from-surface-dark to-background-dark relative z-10">
@@ -61,19 +81,21 @@ export default async function HomePage() {
                 </span>
               </div>
               <h2 className="text-2xl md:text-3xl font-bold text-white mb-2 leading-tight">
-                Discover Amazing Films
+                {featured ? featured.headline || featured.film.title : 'Discover Amazing Films'}
               </h2>
               <p className="text-gray-300 text-sm mb-6 line-clamp-2 md:line-clamp-3">
-                Explore a curated collection of short films, feature films, and documentaries from emerging independent creators around the world.
+                {featured
+                  ? featured.tagline || featured.film.description
+                  : 'Explore a curated collection of short films, feature films, and documentaries from emerging independent creators around the world.'}
               </p>
               <div className="flex gap-3 mt-auto">
-                <button className="flex-1 bg-white text-black font-semibold py-2 px-4 rounded hover:bg-gray-200 transition-colors flex items-center justify-center gap-2">
+                <span className="flex-1 bg-white text-black font-semibold py-2 px-4 rounded hover:bg-gray-200 transition-colors flex items-center justify-center gap-2">
                   <span className="material-icons text-lg">play_arrow</span>
                   Start Watching
-                </button>
+                </span>
               </div>
             </div>
-          </div>
+          </Link>
         </section>
 
         {/* Films Grid */}
@@ -0,0 +1,14 @@
+import { api } from '@/lib/api';
+import { notFound, redirect } from 'next/navigation';
+
+// Short share link: open the film at the shared start offset
+export default async function ShareLinkPage({ params }: { params: { code: string } }) {
+  let link;
+  try {
+    link = await api.getShareLink(params.code);
+  } catch (error) {
+    return notFound();
+  }
+
+  redirect(link.t > 0 ? `/films/${link.film_id}?t=${link.t}` : `/films/${link.film_id}`);
+}
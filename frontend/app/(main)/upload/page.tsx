@@ -41,27 +41,29 @@ export default function UploadPage() {
     if (!filmId) return;
 
     try {
-      // Get upload URL
-      const uploadInfo = await api.getUploadURL(filmId);
-      setStep('processing');
-
       // Get file input
       const fileInput = document.getElementById('video-file') as HTMLInputElement;
       const file = fileInput?.files?.[0];
       if (!file) {
         setError('Please select a video file');
-        setStep('upload');
         return;
       }
 
+      // Get upload URL for this file's size and type
+      const uploadInfo = await api.getUploadURL(filmId, file);
+      setStep('processing');
+
       // Upload directly to R2
-      await fetch(uploadInfo.upload_url, {
+      const response = await fetch(uploadInfo.upload_url, {
         method: 'PUT',
         body: file,
         headers: {
-          'Content-Type': 'video/mp4',
+          'Content-Type': uploadInfo.content_type,
         },
       });
+      if (!response.ok) {
+        throw new Error('Upload was rejected by storage');
+      }
 
       setUploadProgress(100);
 
@@ -0,0 +1,38 @@
+import { VideoPlayer } from '@/components/VideoPlayer';
+import { api } from '@/lib/api';
+import { headers } from 'next/headers';
+
+// Player embedded on other sites (the oEmbed iframe). The embedding page
+// arrives as this request's Referer and is passed on so the film's embed
+// settings can refuse it.
+export default async function EmbedPage({
+  params,
+  searchParams,
+}: {
+  params: { id: string };
+  searchParams: { t?: string };
+}) {
+  const referer = headers().get('referer') ?? undefined;
+
+  let playbackInfo;
+  let error = '';
+  try {
+    playbackInfo = await api.getPlaybackURL(params.id, true, searchParams.t, referer);
+  } catch (e) {
+    error = e instanceof Error ? e.message : 'This film cannot be played here.';
+  }
+
+  return (
+    <div className="w-screen h-screen bg-black flex items-center justify-center">
+      {playbackInfo?.hls_master_url ? (
+        <VideoPlayer
+          src={playbackInfo.hls_master_url}
+          poster={playbackInfo.thumbnail_url}
+          startAt={playbackInfo.start_offset}
+        />
+      ) : (
+        <p className="text-white text-sm">{error}</p>
+      )}
+    </div>
+  );
+}
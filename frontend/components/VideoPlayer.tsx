@@ -7,9 +7,10 @@ interface VideoPlayerProps {
   src: string;
   poster?: string;
   title?: string;
+  startAt?: number; // seconds to start at, e.g. from a time-coded link
 }
 
-export function VideoPlayer({ src, poster, title }: VideoPlayerProps) {
+export function VideoPlayer({ src, poster, title, startAt }: VideoPlayerProps) {
   const videoRef = useRef<HTMLVideoElement>(null);
   const containerRef = useRef<HTMLDivElement>(null);
   const [isPlaying, setIsPlaying] = useState(false);
@@ -79,6 +80,10 @@ export function VideoPlayer({ src, poster, title }: VideoPlayerProps) {
   const handleLoadedMetadata = () => {
     if (videoRef.current) {
       setDuration(videoRef.current.duration);
+      if (startAt && startAt > 0) {
+        videoRef.current.currentTime = startAt;
+        setCurrentTime(startAt);
+      }
     }
   };
 
@@ -12,15 +12,97 @@ export interface User {
   id: string;
   email: string;
   name: string;
+  handle?: string;
   role: 'USER' | 'CREATOR' | 'ADMIN';
   avatar_url?: string;
   bio?: string;
+  birth_date?: string;
   created_at: string;
 }
 
 // Film types
 export type FilmType = 'SHORT_FILM' | 'FEATURE_FILM';
-export type FilmStatus = 'DRAFT' | 'UPLOADED' | 'TRANSCODING' | 'READY' | 'FAILED';
+export type FilmStatus = 'DRAFT' | 'UPLOADED' | 'SCANNING' | 'TRANSCODING' | 'READY' | 'FAILED' | 'EXPIRED';
+
+export type Reaction = 'LIKE' | 'DISLIKE';
+
+export interface FilmReactions {
+  like_count: number;
+  dislike_count: number;
+  viewer_reaction: Reaction | null;
+}
+
+export interface Review {
+  id: string;
+  film_id: string;
+  user_id: string;
+  rating: number;
+  body: string;
+  author_name: string;
+  hidden: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export type CommentSort = 'newest' | 'top';
+export type CommentMode = 'ENABLED' | 'HELD' | 'DISABLED';
+export type CommentStatus = 'VISIBLE' | 'HELD' | 'HIDDEN';
+
+export type FilmVisibility = 'PUBLIC' | 'UNLISTED' | 'PRIVATE';
+
+export interface FilmPolicy {
+  visibility: FilmVisibility;
+  comments: CommentMode;
+  downloads: boolean;
+  embeds: boolean;
+  embed_domains: string[]; // sites (and subdomains) allowed to embed; empty allows any
+  min_age: number;
+  regions: { allowed: string[]; blocked: string[] };
+}
+
+export interface Comment {
+  id: string;
+  film_id: string;
+  user_id: string;
+  parent_id?: string;
+  body: string;
+  reply_count: number;
+  edited_at?: string;
+  status: CommentStatus;
+  pinned_at?: string;
+  author_name: string;
+  author_avatar_url?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export type PlaylistKind = 'MANUAL' | 'SMART';
+
+export interface PlaylistRules {
+  own_films?: boolean;
+  creator_id?: string;
+  tags?: string[];
+  type?: FilmType;
+  genre?: string;
+  min_duration?: number;
+  max_duration?: number;
+  min_rating?: number;
+  published_after?: string;
+  sort?: 'newest' | 'oldest' | 'views' | 'duration' | 'title';
+  limit?: number;
+}
+
+export interface Playlist {
+  id: string;
+  owner_id: string;
+  title: string;
+  description: string;
+  kind: PlaylistKind;
+  rules?: PlaylistRules;
+  public: boolean;
+  created_at: string;
+  updated_at: string;
+}
 
 export interface Film {
   id: string;
@@ -34,15 +116,79 @@ export interface Film {
   created_by_id: string;
   created_by?: User;
   view_count: number;
+  average_rating: number;
+  rating_count: number;
+  like_count: number;
+  dislike_count: number;
+  comment_count: number;
+  comment_mode: CommentMode;
+  visibility: FilmVisibility;
+  policy: FilmPolicy;
+  viewer_reaction?: Reaction;
+  in_watchlist?: boolean;
   created_at: string;
   updated_at: string;
   published_at?: string;
 }
 
-export interface FilmListResponse {
-  films: Film[];
-  page: number;
+export interface WatchlistFilm extends Film {
+  added_at: string;
+}
+
+export interface WatchlistStatus {
+  film_id: string;
+  in_watchlist: boolean;
+  added_at?: string;
+}
+
+// Shared list envelope returned by all paginated endpoints
+export interface Page<T> {
+  items: T[];
   limit: number;
+  page?: number;
+  total_pages?: number;
+  total: number;
+  total_estimated: boolean;
+  has_more: boolean;
+  next_cursor?: string;
+}
+
+export type FilmListResponse = Page<Film>;
+
+// Search hit; highlight snippets are HTML-escaped with matches in <mark>
+export interface SearchHit extends Film {
+  score: number;
+  highlight: {
+    title: string;
+    description?: string;
+  };
+}
+
+export interface SearchResponse extends Page<SearchHit> {
+  query: string;
+  fuzzy: boolean;
+}
+
+// Home page hero slot scheduled by admins
+export interface Featured {
+  id: string;
+  film_id: string;
+  headline?: string;
+  tagline?: string;
+  hero_image_url?: string;
+  starts_at: string;
+  ends_at?: string;
+  priority: number;
+  film: Film;
+}
+
+// Short, time-coded link to a film
+export interface ShareLink {
+  code: string;
+  film_id: string;
+  t: number; // start offset in seconds
+  url: string; // short link, e.g. https://filmtube.app/s/Ab3dEf7h
+  film_url: string; // film page it opens, with ?t= when set
 }
 
 // Auth types
@@ -55,6 +201,7 @@ export interface RegisterRequest {
   email: string;
   password: string;
   name: string;
+  handle?: string;
   role?: string;
 }
 
@@ -68,6 +215,8 @@ export interface UploadURLResponse {
   upload_url: string;
   expiration: string;
   max_file_size: number;
+  content_type: string;
+  file_size: number;
 }
 
 // API client class
@@ -150,13 +299,28 @@ class APIClient {
     return this.request<User>('/api/auth/me');
   }
 
+  async setHandle(handle: string): Promise<User> {
+    return this.request<User>('/api/auth/me/handle', {
+      method: 'PUT',
+      body: JSON.stringify({ handle }),
+    });
+  }
+
+  async setBirthDate(birthDate: string): Promise<User> {
+    return this.request<User>('/api/auth/me/birth-date', {
+      method: 'PUT',
+      body: JSON.stringify({ birth_date: birthDate }),
+    });
+  }
+
   // Film endpoints
-  async getFilms(page = 1, limit = 20, status = ''): Promise<FilmListResponse> {
+  async getFilms(page = 1, limit = 20, status = '', excludeWatched = false): Promise<FilmListResponse> {
     const params = new URLSearchParams({
       page: page.toString(),
       limit: limit.toString(),
     });
     if (status) params.set('status', status);
+    if (excludeWatched) params.set('exclude_watched', 'true');
     return this.request<FilmListResponse>(`/api/films?${params}`);
   }
 
@@ -164,6 +328,145 @@ class APIClient {
     return this.request<Film>(`/api/films/${id}`);
   }
 
+  async likeFilm(id: string): Promise<FilmReactions> {
+    return this.request<FilmReactions>(`/api/films/${id}/like`, {
+      method: 'POST',
+    });
+  }
+
+  async dislikeFilm(id: string): Promise<FilmReactions> {
+    return this.request<FilmReactions>(`/api/films/${id}/dislike`, {
+      method: 'POST',
+    });
+  }
+
+  async addToWatchlist(id: string): Promise<WatchlistStatus> {
+    return this.request<WatchlistStatus>(`/api/films/${id}/watchlist`, {
+      method: 'POST',
+    });
+  }
+
+  async removeFromWatchlist(id: string): Promise<WatchlistStatus> {
+    return this.request<WatchlistStatus>(`/api/films/${id}/watchlist`, {
+      method: 'DELETE',
+    });
+  }
+
+  async getWatchlist(order: 'newest' | 'oldest' = 'newest', page = 1, limit = 20): Promise<Page<WatchlistFilm>> {
+    const params = new URLSearchParams({
+      order,
+      page: page.toString(),
+      limit: limit.toString(),
+    });
+    return this.request<Page<WatchlistFilm>>(`/api/my/watchlist?${params}`);
+  }
+
+  async getFilmReviews(id: string, page = 1, limit = 20): Promise<Page<Review>> {
+    const params = new URLSearchParams({
+      page: page.toString(),
+      limit: limit.toString(),
+    });
+    return this.request<Page<Review>>(`/api/films/${id}/reviews?${params}`);
+  }
+
+  async saveReview(id: string, rating: number, body = ''): Promise<{ review: Review; average_rating: number; rating_count: number }> {
+    return this.request<{ review: Review; average_rating: number; rating_count: number }>(`/api/films/${id}/review`, {
+      method: 'PUT',
+      body: JSON.stringify({ rating, body }),
+    });
+  }
+
+  async deleteReview(id: string): Promise<{ average_rating: number; rating_count: number }> {
+    return this.request<{ average_rating: number; rating_count: number }>(`/api/films/${id}/review`, {
+      method: 'DELETE',
+    });
+  }
+
+  async flagReview(reviewId: string, reason = ''): Promise<{ message: string }> {
+    return this.request<{ message: string }>(`/api/reviews/${reviewId}/flag`, {
+      method: 'POST',
+      body: JSON.stringify({ reason }),
+    });
+  }
+
+  async getFilmComments(id: string, sort: CommentSort = 'newest', page = 1, limit = 20): Promise<Page<Comment>> {
+    const params = new URLSearchParams({
+      sort,
+      page: page.toString(),
+      limit: limit.toString(),
+    });
+    return this.request<Page<Comment>>(`/api/films/${id}/comments?${params}`);
+  }
+
+  async getCommentReplies(id: string, commentId: string, page = 1, limit = 20): Promise<Page<Comment>> {
+    const params = new URLSearchParams({
+      page: page.toString(),
+      limit: limit.toString(),
+    });
+    return this.request<Page<Comment>>(`/api/films/${id}/comments/${commentId}/replies?${params}`);
+  }
+
+  async createComment(id: string, body: string, parentId?: string): Promise<Comment> {
+    return this.request<Comment>(`/api/films/${id}/comments`, {
+      method: 'POST',
+      body: JSON.stringify({ body, parent_id: parentId }),
+    });
+  }
+
+  async editComment(id: string, commentId: string, body: string): Promise<Comment> {
+    return this.request<Comment>(`/api/films/${id}/comments/${commentId}`, {
+      method: 'PATCH',
+      body: JSON.stringify({ body }),
+    });
+  }
+
+  async deleteComment(id: string, commentId: string): Promise<{ message: string }> {
+    return this.request<{ message: string }>(`/api/films/${id}/comments/${commentId}`, {
+      method: 'DELETE',
+    });
+  }
+
+  async reportComment(id: string, commentId: string, reason = ''): Promise<{ message: string }> {
+    return this.request<{ message: string }>(`/api/films/${id}/comments/${commentId}/report`, {
+      method: 'POST',
+      body: JSON.stringify({ reason }),
+    });
+  }
+
+  async getPlaylist(id: string): Promise<Playlist & { films: Film[] }> {
+    return this.request<Playlist & { films: Film[] }>(`/api/playlists/${id}`);
+  }
+
+  // Resolves to null at the end of the playlist
+  async getNextPlaylistFilm(id: string, afterFilmId?: string): Promise<{ film: Film; position: number } | null> {
+    const params = afterFilmId ? `?${new URLSearchParams({ after: afterFilmId })}` : '';
+    const response = await fetch(`${this.baseURL}/api/playlists/${id}/next${params}`, {
+      headers: this.getHeaders(),
+    });
+    if (response.status === 204) {
+      return null;
+    }
+    if (!response.ok) {
+      const error = await response.json().catch(() => ({ error: 'Request failed' }));
+      throw new Error(error.error || 'Request failed');
+    }
+    return response.json();
+  }
+
+  async searchFilms(q: string, page = 1, limit = 20): Promise<SearchResponse> {
+    const params = new URLSearchParams({
+      q,
+      page: page.toString(),
+      limit: limit.toString(),
+    });
+    return this.request<SearchResponse>(`/api/films/search?${params}`);
+  }
+
+  async getFeatured(): Promise<Featured | null> {
+    const response = await this.request<{ featured: Featured | null }>('/api/featured');
+    return response.featured;
+  }
+
   async createFilm(data: { title: string; description?: string; type: FilmType }): Promise<Film> {
     return this.request<Film>('/api/films', {
       method: 'POST',
@@ -171,9 +474,11 @@ class APIClient {
     });
   }
 
-  async getUploadURL(id: string): Promise<UploadURLResponse> {
+  // The upload URL only accepts a file of the declared size and type
+  async getUploadURL(id: string, file: { size: number; type: string }): Promise<UploadURLResponse> {
     return this.request<UploadURLResponse>(`/api/films/${id}/upload-url`, {
       method: 'POST',
+      body: JSON.stringify({ file_size: file.size, content_type: file.type || 'video/mp4' }),
     });
   }
 
@@ -189,12 +494,82 @@ class APIClient {
     });
   }
 
-  async getPlaybackURL(id: string): Promise<{
+  // Allow or refuse embedding, optionally only on the given sites
+  async updateEmbedSettings(id: string, embeds: boolean, domains: string[] = []): Promise<{ embeds: boolean; domains: string[] }> {
+    return this.request(`/api/films/${id}/embed`, {
+      method: 'PUT',
+      body: JSON.stringify({ embeds, domains }),
+    });
+  }
+
+  async updateFilmPolicy(id: string, policy: Partial<FilmPolicy>): Promise<{ film_id: string; policy: FilmPolicy }> {
+    return this.request<{ film_id: string; policy: FilmPolicy }>(`/api/films/${id}/policy`, {
+      method: 'PUT',
+      body: JSON.stringify(policy),
+    });
+  }
+
+  // Embedded players pass embed = true so the film's policy can refuse them;
+  // embedReferer is the embedding page, checked against the film's embed
+  // domains when this runs on the server.
+  // t is a start offset from a time-coded link, in seconds or like 1m30s
+  async getPlaybackURL(id: string, embed = false, t?: string, embedReferer?: string): Promise<{
     hls_master_url: string;
     thumbnail_url?: string;
-    assets: Array<{ quality: string; hls_index_url: string }>;
+    // Renditions with prefetch hints: fetch first_segment_url early and
+    // pick a starting quality from bandwidth (bits per second)
+    assets: Array<{
+      quality: string;
+      hls_index_url: string;
+      first_segment_url?: string;
+      segment_count: number;
+      avg_segment_bytes: number;
+      avg_segment_seconds: number;
+      bandwidth: number;
+    }>;
+    // Send beacon_token with this session's playback and player events
+    session_id: string;
+    beacon_token: string;
+    // Seconds to seek to for a signed-in viewer; 0 starts from the beginning
+    resume_position: number;
+    // Seconds to start at from t; when set, it wins over resume_position
+    start_offset: number;
+  }> {
+    const params = new URLSearchParams();
+    if (embed) params.set('embed', 'true');
+    if (t) params.set('t', t);
+    const query = params.toString();
+    return this.request(`/api/films/${id}/playback${query ? `?${query}` : ''}`, {
+      headers: embedReferer ? { Referer: embedReferer } : undefined,
+    });
+  }
+
+  // Short link opening the film at t (seconds or like 1m30s); the same film
+  // and offset always get the same link
+  async shareFilm(id: string, t?: string): Promise<ShareLink> {
+    return this.request(`/api/films/${id}/share`, {
+      method: 'POST',
+      body: JSON.stringify(t ? { t } : {}),
+    });
+  }
+
+  async getShareLink(code: string): Promise<ShareLink> {
+    return this.request(`/api/share/${code}`);
+  }
+
+  // Heartbeat while playing so the viewer can resume on any device
+  async updatePlaybackPosition(id: string, positionSeconds: number, durationSeconds?: number, completed = false): Promise<{
+    position_seconds: number;
+    completed: boolean;
   }> {
-    return this.request(`/api/films/${id}/playback`);
+    return this.request(`/api/films/${id}/position`, {
+      method: 'PUT',
+      body: JSON.stringify({
+        position_seconds: Math.floor(positionSeconds),
+        duration_seconds: durationSeconds ? Math.floor(durationSeconds) : undefined,
+        completed,
+      }),
+    });
   }
 
   async healthCheck(): Promise<{ status: string; service: string }> {
@@ -8,12 +8,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/arjunaayasa/filmtube/backend/internal/chaos"
 	"github.com/arjunaayasa/filmtube/backend/internal/db"
 	"github.com/arjunaayasa/filmtube/backend/internal/r2"
 	"github.com/arjunaayasa/filmtube/backend/internal/redis"
+	"github.com/arjunaayasa/filmtube/backend/internal/search"
+	"github.com/arjunaayasa/filmtube/backend/internal/settings"
+	"github.com/arjunaayasa/filmtube/worker/internal/classifier"
 	"github.com/arjunaayasa/filmtube/worker/internal/config"
 	"github.com/arjunaayasa/filmtube/worker/internal/ffmpeg"
 	"github.com/arjunaayasa/filmtube/worker/internal/jobs"
+	"github.com/arjunaayasa/filmtube/worker/internal/scanner"
+	"github.com/arjunaayasa/filmtube/worker/internal/ytdlp"
 	"github.com/google/uuid"
 )
 
@@ -52,19 +58,93 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to initialize R2 client: %v", err)
 	}
+	r2Client.SetPrivateBucket(cfg.R2PrivateBucket)
+	sourceBuckets, err := r2.ParseSourceBuckets(cfg.SourceBuckets)
+	if err != nil {
+		log.Fatalf("Invalid SOURCE_BUCKETS: %v", err)
+	}
+	if err := r2Client.SetSourceRegions("", sourceBuckets); err != nil {
+		log.Fatalf("Failed to configure source buckets: %v", err)
+	}
+	if err := r2Client.SetUploadLimits(r2.UploadLimits{
+		PartSize:    cfg.UploadPartSizeMB << 20,
+		Concurrency: cfg.UploadConcurrency,
+	}); err != nil {
+		log.Fatalf("Invalid upload limits: %v", err)
+	}
 
 	// Initialize FFmpeg handler
 	ffmpegHandler := ffmpeg.New(cfg.FFmpegPath, cfg.TempDir)
 
 	// Initialize processor
 	queries := db.NewQueries(database)
-	processor := jobs.NewProcessor(queries, r2Client, redisClient, ffmpegHandler)
+
+	// Initialize search indexer so films become searchable once READY
+	searchBackend, err := search.New(search.Config{
+		Backend:            cfg.SearchBackend,
+		OpenSearchURL:      cfg.OpenSearchURL,
+		OpenSearchIndex:    cfg.OpenSearchIndex,
+		OpenSearchUsername: cfg.OpenSearchUsername,
+		OpenSearchPassword: cfg.OpenSearchPassword,
+	}, queries)
+	if err != nil {
+		log.Fatalf("Failed to initialize search backend: %v", err)
+	}
+	indexer := search.NewIndexer(searchBackend, queries)
+
+	// Settings tune tasks such as the post-encode quality check
+	settingsService := settings.New(queries, redisClient)
+
+	// Malware scanner for uploaded files (SCANNER=none skips scanning)
+	fileScanner, err := scanner.New(scanner.Config{
+		Scanner:          cfg.Scanner,
+		ClamAVAddress:    cfg.ClamAVAddress,
+		VirusTotalAPIKey: cfg.VirusTotalAPIKey,
+		Timeout:          cfg.ScanTimeout,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize scanner: %v", err)
+	}
+
+	// Content classifier for transcoded films (CLASSIFIER=none skips it)
+	contentClassifier, err := classifier.New(classifier.Config{
+		Classifier: cfg.Classifier,
+		URL:        cfg.ClassifierURL,
+		APIKey:     cfg.ClassifierAPIKey,
+		Timeout:    cfg.ClassifyTimeout,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize classifier: %v", err)
+	}
+
+	processor := jobs.NewProcessor(queries, r2Client, redisClient, ffmpegHandler, indexer, settingsService, fileScanner, contentClassifier, ytdlp.New(cfg.YTDLPPath, cfg.TempDir))
 
 	// Start worker loop
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	go settingsService.Listen(ctx)
+
+	// Fault injection for resilience testing (CHAOS_ENABLED, staging only)
+	chaosInjector := chaos.New(redisClient, cfg.ChaosEnabled)
+	if cfg.ChaosEnabled {
+		redisClient.AddHook(chaosInjector.RedisHook())
+		r2Client.InjectFaults(chaosInjector.R2Fault)
+		ffmpegHandler.SetFaultHook(func() error {
+			return chaosInjector.Fail(chaos.FaultFFmpegFailure)
+		})
+		go chaosInjector.RunSync(ctx, 5*time.Second)
+	}
+
+	// Workers in a region take jobs for sources stored there first
+	workerID := uuid.New().String()
+	if cfg.WorkerRegion != "" {
+		log.Printf("Worker %s serving region %s", workerID, cfg.WorkerRegion)
+	}
+	go heartbeatLoop(ctx, redisClient, cfg.WorkerRegion, workerID)
 
-	go workerLoop(ctx, processor, redisClient)
+	go workerLoop(ctx, processor, redisClient, cfg.WorkerRegion)
+	go taskLoop(ctx, processor, redisClient, cfg.WorkerRegion)
+	go scanRetryLoop(ctx, processor)
 
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
@@ -73,12 +153,45 @@ func main() {
 
 	log.Println("Worker shutting down...")
 	cancel()
+	if cfg.WorkerRegion != "" {
+		if err := redisClient.RemoveWorker(context.Background(), cfg.WorkerRegion, workerID); err != nil {
+			log.Printf("Error leaving region %s: %v", cfg.WorkerRegion, err)
+		}
+	}
 	time.Sleep(2 * time.Second)
 	log.Println("Worker stopped")
 }
 
-// workerLoop continuously polls for and processes transcoding jobs
-func workerLoop(ctx context.Context, processor *jobs.Processor, redisClient *redis.Client) {
+// heartbeatLoop keeps the worker registered as live in its region, and
+// moves work stranded in the queue of a region with no live workers to the
+// shared queues
+func heartbeatLoop(ctx context.Context, redisClient *redis.Client, region, workerID string) {
+	ticker := time.NewTicker(redis.WorkerTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		if region != "" {
+			if err := redisClient.WorkerHeartbeat(ctx, region, workerID); err != nil {
+				log.Printf("Error sending heartbeat: %v", err)
+			}
+		}
+		if moved, err := redisClient.RequeueOrphanedWork(ctx); err != nil {
+			log.Printf("Error requeueing orphaned work: %v", err)
+		} else if moved > 0 {
+			log.Printf("Moved %d jobs from regions without workers to the shared queues", moved)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// workerLoop continuously polls for and processes transcoding jobs, from
+// the worker's region first
+func workerLoop(ctx context.Context, processor *jobs.Processor, redisClient *redis.Client, region string) {
 	log.Println("Worker loop started")
 
 	for {
@@ -89,7 +202,7 @@ func workerLoop(ctx context.Context, processor *jobs.Processor, redisClient *red
 
 		default:
 			// Try to dequeue a job (with 5 second timeout)
-			filmID, err := redisClient.DequeueTranscodeJob(ctx, 5*time.Second)
+			filmID, err := redisClient.DequeueTranscodeJob(ctx, region, 5*time.Second)
 			if err != nil {
 				if err.Error() != "redis: nil" {
 					log.Printf("Error dequeuing job: %v", err)
@@ -110,3 +223,50 @@ func workerLoop(ctx context.Context, processor *jobs.Processor, redisClient *red
 		}
 	}
 }
+
+// taskLoop continuously polls for and processes auxiliary worker tasks
+// (burn-in renditions, etc.) separately from the main transcode queue
+func taskLoop(ctx context.Context, processor *jobs.Processor, redisClient *redis.Client, region string) {
+	log.Println("Task loop started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Task loop stopped")
+			return
+
+		default:
+			task, err := redisClient.DequeueTask(ctx, region, 5*time.Second)
+			if err != nil {
+				if err.Error() != "redis: nil" {
+					log.Printf("Error dequeuing task: %v", err)
+				}
+				continue
+			}
+
+			log.Printf("Received %s task %s for film %s", task.Type, task.ID, task.FilmID)
+
+			if err := processor.ProcessTask(ctx, task); err != nil {
+				log.Printf("Error processing task %s: %v", task.ID, err)
+			}
+		}
+	}
+}
+
+// scanRetryLoop queues again the malware scans that are due a retry. Every
+// worker runs it; claiming a retry is atomic, so each is queued once.
+func scanRetryLoop(ctx context.Context, processor *jobs.Processor) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := processor.RetryDueScans(ctx); err != nil {
+				log.Printf("Error retrying scans: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Package classifier scores video frames for content such as nudity. A
+// worker runs one classifier, picked by configuration: an HTTP service or
+// none.
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+)
+
+// Scores maps a label such as "nsfw" to a confidence between 0 and 1
+type Scores map[string]float64
+
+// Classifier scores frames
+type Classifier interface {
+	// Name identifies the classifier in classification records
+	Name() string
+	// Classify scores one JPEG frame, or returns an error when it could not
+	Classify(ctx context.Context, frame []byte) (Scores, error)
+}
+
+// Config selects and configures a classifier
+type Config struct {
+	// Classifier is none or http
+	Classifier string
+	// URL is the endpoint the http classifier posts frames to
+	URL string
+	// APIKey is sent to it as a bearer token when set
+	APIKey string
+	// Timeout bounds one frame
+	Timeout time.Duration
+}
+
+// New creates the configured classifier
+func New(cfg Config) (Classifier, error) {
+	switch cfg.Classifier {
+	case "", models.ClassifierNone:
+		return Noop{}, nil
+	case models.ClassifierHTTP:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("CLASSIFIER_URL is required for the http classifier")
+		}
+		return NewHTTP(cfg.URL, cfg.APIKey, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown classifier %q (want none or http)", cfg.Classifier)
+	}
+}
+
+// Noop is the classifier of workers with none configured; it scores
+// nothing
+type Noop struct{}
+
+func (Noop) Name() string { return models.ClassifierNone }
+
+func (Noop) Classify(ctx context.Context, frame []byte) (Scores, error) {
+	return Scores{}, nil
+}
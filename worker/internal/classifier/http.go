@@ -0,0 +1,69 @@
+package classifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+)
+
+// HTTP classifies frames with an external service. Each frame is posted
+// as image/jpeg and the service answers {"scores": {"nsfw": 0.97}}.
+type HTTP struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTP creates an HTTP classifier
+func NewHTTP(url, apiKey string, timeout time.Duration) *HTTP {
+	return &HTTP{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (h *HTTP) Name() string { return models.ClassifierHTTP }
+
+// Classify posts a frame to the service and returns its scores, dropping
+// any outside 0 to 1
+func (h *HTTP) Classify(ctx context.Context, frame []byte) (Scores, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(frame))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "image/jpeg")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("classifier request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("classifier returned %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	var result struct {
+		Scores Scores `json:"scores"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode classifier response: %w", err)
+	}
+	scores := Scores{}
+	for label, score := range result.Scores {
+		if score >= 0 && score <= 1 {
+			scores[label] = score
+		}
+	}
+	return scores, nil
+}
@@ -3,9 +3,11 @@ package ffmpeg
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,6 +15,7 @@ import (
 type FFmpeg struct {
 	path   string
 	tempDir string
+	fault  func() error // optional fault injection, see SetFaultHook
 }
 
 // New creates a new FFmpeg handler
@@ -23,6 +26,20 @@ func New(path, tempDir string) *FFmpeg {
 	}
 }
 
+// SetFaultHook makes every FFmpeg run first call fault and fail with its
+// error; used for resilience testing
+func (f *FFmpeg) SetFaultHook(fault func() error) {
+	f.fault = fault
+}
+
+// injectFault returns the fault hook's error, if any
+func (f *FFmpeg) injectFault() error {
+	if f.fault == nil {
+		return nil
+	}
+	return f.fault()
+}
+
 // VideoInfo contains metadata about a video file
 type VideoInfo struct {
 	Duration   time.Duration `json:"duration"`
@@ -30,10 +47,16 @@ type VideoInfo struct {
 	Height     int           `json:"height"`
 	Bitrate    int           `json:"bitrate"`
 	Framerate  float64       `json:"framerate"`
+	VideoCodec string        `json:"video_codec"`
+	AudioCodec string        `json:"audio_codec"` // empty without an audio stream
 }
 
 // GetVideoInfo extracts metadata from a video file
 func (f *FFmpeg) GetVideoInfo(data []byte) (*VideoInfo, error) {
+	if err := f.injectFault(); err != nil {
+		return nil, err
+	}
+
 	cmd := exec.Command(f.path,
 		"-i", "pipe:0",
 		"-f", "null",
@@ -64,8 +87,8 @@ func (f *FFmpeg) GetVideoInfo(data []byte) (*VideoInfo, error) {
 		time.Duration(seconds*1000)*time.Millisecond
 
 	// Parse resolution
-	// Format: 1920x1080
-	resolutionRegex := regexp.MustCompile(`(\d+)x(\d+)`)
+	// Format: 1920x1080, skipping codec tags like 0x31637661
+	resolutionRegex := regexp.MustCompile(`(\d{2,})x(\d{2,})`)
 	resMatches := resolutionRegex.FindStringSubmatch(stderr.String())
 	if len(resMatches) < 3 {
 		return nil, fmt.Errorf("could not parse resolution")
@@ -73,10 +96,22 @@ func (f *FFmpeg) GetVideoInfo(data []byte) (*VideoInfo, error) {
 	width, _ := strconv.Atoi(resMatches[1])
 	height, _ := strconv.Atoi(resMatches[2])
 
+	// Parse codecs of the first streams
+	// Format: Stream #0:0(und): Video: h264 (High) ...
+	var videoCodec, audioCodec string
+	if m := regexp.MustCompile(`Video: (\w+)`).FindStringSubmatch(stderr.String()); m != nil {
+		videoCodec = m[1]
+	}
+	if m := regexp.MustCompile(`Audio: (\w+)`).FindStringSubmatch(stderr.String()); m != nil {
+		audioCodec = m[1]
+	}
+
 	return &VideoInfo{
-		Duration: duration,
-		Width:    width,
-		Height:   height,
+		Duration:   duration,
+		Width:      width,
+		Height:     height,
+		VideoCodec: videoCodec,
+		AudioCodec: audioCodec,
 	}, nil
 }
 
@@ -121,6 +156,115 @@ type TranscodeResult struct {
 func (f *FFmpeg) TranscodeToHLS(data []byte, filmID string, quality QualityLevel, progressChan chan<- int) (*TranscodeResult, error) {
 	// Create temp directory for output
 	outputDir := fmt.Sprintf("%s/hls_%s_%s", f.tempDir, filmID, quality.Name)
+	return f.transcodeToHLS(data, outputDir, quality, "", progressChan)
+}
+
+// TranscodeToHLSWithLUT transcodes video data to HLS, grading it with a 3D
+// LUT file first. An empty lutPath transcodes without grading.
+func (f *FFmpeg) TranscodeToHLSWithLUT(data []byte, filmID string, quality QualityLevel, lutPath string, progressChan chan<- int) (*TranscodeResult, error) {
+	outputDir := fmt.Sprintf("%s/hls_%s_%s", f.tempDir, filmID, quality.Name)
+	return f.transcodeToHLS(data, outputDir, quality, lutFilter(lutPath), progressChan)
+}
+
+// TranscodeToHLSVariant is TranscodeToHLSWithLUT for a rendition variant,
+// encoding into the variant's own workspace
+func (f *FFmpeg) TranscodeToHLSVariant(data []byte, filmID, variant string, quality QualityLevel, lutPath string, progressChan chan<- int) (*TranscodeResult, error) {
+	outputDir := fmt.Sprintf("%s/hls_%s_%s_%s", f.tempDir, filmID, variant, quality.Name)
+	return f.transcodeToHLS(data, outputDir, quality, lutFilter(lutPath), progressChan)
+}
+
+// TranscodeToHLSWithSubtitles transcodes video data to HLS with a subtitle
+// file burned into the picture, after optional LUT grading
+func (f *FFmpeg) TranscodeToHLSWithSubtitles(data []byte, filmID, variant string, quality QualityLevel, subtitlePath, lutPath string, progressChan chan<- int) (*TranscodeResult, error) {
+	outputDir := fmt.Sprintf("%s/hls_%s_%s_%s", f.tempDir, filmID, variant, quality.Name)
+	filter := fmt.Sprintf("subtitles=%s", escapeFilterPath(subtitlePath))
+	if lut := lutFilter(lutPath); lut != "" {
+		filter = lut + "," + filter
+	}
+	return f.transcodeToHLS(data, outputDir, quality, filter, progressChan)
+}
+
+// TranscodeToHLSWithWatermark transcodes video data to HLS with a visible
+// text watermark drawn over the picture, after optional LUT grading
+func (f *FFmpeg) TranscodeToHLSWithWatermark(data []byte, filmID, variant string, quality QualityLevel, text, lutPath string, progressChan chan<- int) (*TranscodeResult, error) {
+	outputDir := fmt.Sprintf("%s/hls_%s_%s_%s", f.tempDir, filmID, variant, quality.Name)
+	filter := watermarkFilter(text)
+	if lut := lutFilter(lutPath); lut != "" {
+		filter = lut + "," + filter
+	}
+	return f.transcodeToHLS(data, outputDir, quality, filter, progressChan)
+}
+
+// WatermarkMP4 re-encodes a source video at its original resolution with a
+// visible text watermark, after optional LUT grading, and returns the MP4
+func (f *FFmpeg) WatermarkMP4(data []byte, text, lutPath string) ([]byte, error) {
+	filter := watermarkFilter(text)
+	if lut := lutFilter(lutPath); lut != "" {
+		filter = lut + "," + filter
+	}
+
+	args := []string{
+		"-i", "pipe:0",
+		"-vf", filter,
+		"-c:v", "libx264",
+		"-preset", "medium",
+		"-crf", "20",
+		"-c:a", "aac",
+		"-b:a", "192k",
+		"-movflags", "frag_keyframe+empty_moov",
+		"-f", "mp4",
+		"pipe:1",
+	}
+
+	if err := f.injectFault(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(f.path, args...)
+	cmd.Stdin = bytes.NewReader(data)
+
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg watermark failed: %w, stderr: %s", err, stderr.String())
+	}
+
+	return out.Bytes(), nil
+}
+
+// watermarkFilter returns a drawtext filter placing text faintly at the
+// bottom centre of the picture
+func watermarkFilter(text string) string {
+	return fmt.Sprintf("drawtext=text=%s:expansion=none:fontcolor=white@0.35:fontsize=h/24:x=(w-text_w)/2:y=h-text_h-h/12",
+		escapeFilterPath(text))
+}
+
+// lutFilter returns the lut3d filter for a .cube file, or "" for no LUT
+func lutFilter(lutPath string) string {
+	if lutPath == "" {
+		return ""
+	}
+	return fmt.Sprintf("lut3d=file=%s", escapeFilterPath(lutPath))
+}
+
+// escapeFilterPath escapes a file path for use inside an FFmpeg filter argument
+func escapeFilterPath(path string) string {
+	return strings.NewReplacer(`\`, `\\`, `:`, `\:`, `'`, `\'`).Replace(path)
+}
+
+// transcodeToHLS runs the HLS encode into outputDir, appending extraFilter
+// (if any) to the video filter chain after scaling
+func (f *FFmpeg) transcodeToHLS(data []byte, outputDir string, quality QualityLevel, extraFilter string, progressChan chan<- int) (*TranscodeResult, error) {
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	videoFilter := fmt.Sprintf("scale=%d:%d", quality.Width, quality.Height)
+	if extraFilter != "" {
+		videoFilter += "," + extraFilter
+	}
 
 	// FFmpeg command for HLS transcoding
 	// -c:v libx264: H.264 video codec
@@ -138,7 +282,7 @@ func (f *FFmpeg) TranscodeToHLS(data []byte, filmID string, quality QualityLevel
 		"-c:v", "libx264",
 		"-preset", "fast",
 		"-b:v", quality.Bitrate,
-		"-vf", fmt.Sprintf("scale=%d:%d", quality.Width, quality.Height),
+		"-vf", videoFilter,
 		"-c:a", "aac",
 		"-b:a", quality.Audio,
 		"-f", "hls",
@@ -149,6 +293,10 @@ func (f *FFmpeg) TranscodeToHLS(data []byte, filmID string, quality QualityLevel
 		fmt.Sprintf("%s/index.m3u8", outputDir),
 	}
 
+	if err := f.injectFault(); err != nil {
+		return nil, err
+	}
+
 	cmd := exec.Command(f.path, args...)
 	cmd.Stdin = bytes.NewReader(data)
 
@@ -194,8 +342,138 @@ func (f *FFmpeg) TranscodeToHLS(data []byte, filmID string, quality QualityLevel
 	}, nil
 }
 
+// ReplaceAudioHLS remuxes an existing HLS rendition with a new audio track.
+// The video stream is copied as-is; only the audio is encoded.
+func (f *FFmpeg) ReplaceAudioHLS(indexURL, audioPath, outputDir string, quality QualityLevel) (*TranscodeResult, error) {
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	args := []string{
+		"-i", indexURL,
+		"-i", audioPath,
+		"-map", "0:v:0",
+		"-map", "1:a:0",
+		"-c:v", "copy",
+		"-c:a", "aac",
+		"-b:a", quality.Audio,
+		"-shortest",
+		"-f", "hls",
+		"-hls_time", "10",
+		"-hls_list_size", "0",
+		"-hls_segment_filename", fmt.Sprintf("%s/seg_%%05d.ts", outputDir),
+		fmt.Sprintf("%s/index.m3u8", outputDir),
+	}
+
+	if err := f.injectFault(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(f.path, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg audio remux failed: %w, stderr: %s", err, stderr.String())
+	}
+
+	indexData, err := f.readIndexFile(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
+	return &TranscodeResult{
+		Quality:   quality.Name,
+		IndexData: indexData,
+	}, nil
+}
+
+// ReplaceAudio remuxes a source video with a new audio track, copying the
+// video stream, and returns the resulting MP4
+func (f *FFmpeg) ReplaceAudio(data []byte, audioPath string) ([]byte, error) {
+	args := []string{
+		"-i", "pipe:0",
+		"-i", audioPath,
+		"-map", "0:v:0",
+		"-map", "1:a:0",
+		"-c:v", "copy",
+		"-c:a", "aac",
+		"-b:a", "192k",
+		"-shortest",
+		"-movflags", "frag_keyframe+empty_moov",
+		"-f", "mp4",
+		"pipe:1",
+	}
+
+	if err := f.injectFault(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(f.path, args...)
+	cmd.Stdin = bytes.NewReader(data)
+
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg audio remux failed: %w, stderr: %s", err, stderr.String())
+	}
+
+	return out.Bytes(), nil
+}
+
+// WorkspaceDir returns the directory an HLS encode of a rendition writes
+// its playlist and segments to. rendition is a quality name, or
+// "{variant}/{quality}" for a variant's rendition.
+func (f *FFmpeg) WorkspaceDir(filmID, rendition string) string {
+	return fmt.Sprintf("%s/hls_%s_%s", f.tempDir, filmID, strings.ReplaceAll(rendition, "/", "_"))
+}
+
+// EncodeSegment re-encodes duration seconds of a source video from start as
+// a single MPEG-TS segment, after optional LUT grading. Timestamps are
+// offset to start so the segment drops into an existing playlist.
+func (f *FFmpeg) EncodeSegment(data []byte, quality QualityLevel, lutPath string, start, duration float64) ([]byte, error) {
+	videoFilter := fmt.Sprintf("scale=%d:%d", quality.Width, quality.Height)
+	if lut := lutFilter(lutPath); lut != "" {
+		videoFilter += "," + lut
+	}
+
+	args := []string{
+		"-ss", fmt.Sprintf("%.3f", start),
+		"-i", "pipe:0",
+		"-t", fmt.Sprintf("%.3f", duration),
+		"-vf", videoFilter,
+		"-c:v", "libx264",
+		"-preset", "fast",
+		"-b:v", quality.Bitrate,
+		"-c:a", "aac",
+		"-b:a", quality.Audio,
+		"-output_ts_offset", fmt.Sprintf("%.3f", start),
+		"-f", "mpegts",
+		"pipe:1",
+	}
+
+	if err := f.injectFault(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(f.path, args...)
+	cmd.Stdin = bytes.NewReader(data)
+
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg segment encode failed: %w, stderr: %s", err, stderr.String())
+	}
+
+	return out.Bytes(), nil
+}
+
 // GenerateMasterPlaylist creates the master.m3u8 file
-func (f *FFmpeg) GenerateMasterPlaylist(filmID string, qualities []string) ([]byte, error) {
+func (f *FFmpeg) GenerateMasterPlaylist(filmID string, qualities []QualityLevel) ([]byte, error) {
 	// Master playlist format
 	// #EXTM3U
 	// #EXT-X-VERSION:3
@@ -207,34 +485,94 @@ func (f *FFmpeg) GenerateMasterPlaylist(filmID string, qualities []string) ([]by
 	master += "#EXTM3U\n"
 	master += "#EXT-X-VERSION:3\n"
 
-	bitrates := map[string]int{
-		"360p": 800000,
-		"720p": 2500000,
-	}
-
-	resolutions := map[string]string{
-		"360p": "640x360",
-		"720p": "1280x720",
-	}
-
 	for _, q := range qualities {
-		master += fmt.Sprintf("#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%s\n", bitrates[q], resolutions[q])
-		master += fmt.Sprintf("%s/%s/index.m3u8\n", q, q)
+		master += fmt.Sprintf("#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n", bitrateBits(q.Bitrate), q.Width, q.Height)
+		master += fmt.Sprintf("%s/%s/index.m3u8\n", q.Name, q.Name)
 	}
 
 	return []byte(master), nil
 }
 
+// bitrateBits converts an FFmpeg bitrate such as "800k" or "2.5M" to bits
+// per second, or 0 if it cannot be parsed
+func bitrateBits(bitrate string) int {
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(bitrate, "k"):
+		multiplier = 1e3
+	case strings.HasSuffix(bitrate, "M"):
+		multiplier = 1e6
+	}
+	value, err := strconv.ParseFloat(strings.TrimRight(bitrate, "kM"), 64)
+	if err != nil {
+		return 0
+	}
+	return int(value * multiplier)
+}
+
 // GenerateThumbnail generates a thumbnail from video
 func (f *FFmpeg) GenerateThumbnail(data []byte, timestamp time.Duration) ([]byte, error) {
+	return f.generateFrame(data, timestamp, "")
+}
+
+// GenerateThumbnailWithLUT generates a thumbnail graded with a 3D LUT file
+func (f *FFmpeg) GenerateThumbnailWithLUT(data []byte, timestamp time.Duration, lutPath string) ([]byte, error) {
+	return f.generateFrame(data, timestamp, lutFilter(lutPath))
+}
+
+// GenerateSpriteSheet tiles one frame every interval into a single
+// columns x rows JPEG of tileWidth x tileHeight tiles, letterboxing frames
+// that do not fit the tile's aspect ratio
+func (f *FFmpeg) GenerateSpriteSheet(data []byte, interval time.Duration, columns, rows, tileWidth, tileHeight int, lutPath string) ([]byte, error) {
+	filter := fmt.Sprintf("fps=1/%.3f,scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,tile=%dx%d",
+		interval.Seconds(), tileWidth, tileHeight, tileWidth, tileHeight, columns, rows)
+	if lut := lutFilter(lutPath); lut != "" {
+		filter = lut + "," + filter
+	}
+
+	if err := f.injectFault(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(f.path,
+		"-i", "pipe:0",
+		"-vf", filter,
+		"-frames:v", "1",
+		"-q:v", "4",
+		"-f", "image2pipe",
+		"pipe:1",
+	)
+	cmd.Stdin = bytes.NewReader(data)
+
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg sprite sheet failed: %w, stderr: %s", err, stderr.String())
+	}
+
+	return out.Bytes(), nil
+}
+
+func (f *FFmpeg) generateFrame(data []byte, timestamp time.Duration, filter string) ([]byte, error) {
 	// Extract a single frame at the specified timestamp
 	args := []string{
-		"-ss", timestamp.String(),
+		"-ss", fmt.Sprintf("%.3f", timestamp.Seconds()),
 		"-i", "pipe:0",
 		"-vframes", "1",
+	}
+	if filter != "" {
+		args = append(args, "-vf", filter)
+	}
+	args = append(args,
 		"-q:v", "2",
 		"-f", "image2pipe",
 		"pipe:1",
+	)
+
+	if err := f.injectFault(); err != nil {
+		return nil, err
 	}
 
 	cmd := exec.Command(f.path, args...)
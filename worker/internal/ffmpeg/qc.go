@@ -0,0 +1,160 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	psnrAverageRegex = regexp.MustCompile(`PSNR .*average:([0-9.]+|inf)`)
+	vmafScoreRegex   = regexp.MustCompile(`VMAF score[:=] ?([0-9.]+)`)
+)
+
+// QualityScores are a rendition's full-reference quality metrics against
+// its source. VMAF is nil when FFmpeg is built without libvmaf.
+type QualityScores struct {
+	VMAF *float64
+	PSNR *float64
+}
+
+// Window is a stretch of a film, in seconds, scored by a sampled quality
+// check
+type Window struct {
+	Start    float64
+	Duration float64
+}
+
+// MeasureQuality scores an HLS rendition against the source video file it
+// was encoded from. The source is scaled to the rendition's size and
+// graded with the same optional LUT, so only encoding loss is measured.
+func (f *FFmpeg) MeasureQuality(sourcePath, renditionURL string, quality QualityLevel, lutPath string) (*QualityScores, error) {
+	return f.measureQuality(sourcePath, renditionURL, quality, lutPath, nil)
+}
+
+// MeasureQualitySampled is MeasureQuality over windows of the film rather
+// than all of it, averaging their scores
+func (f *FFmpeg) MeasureQualitySampled(sourcePath, renditionURL string, quality QualityLevel, lutPath string, windows []Window) (*QualityScores, error) {
+	if len(windows) == 0 {
+		return f.MeasureQuality(sourcePath, renditionURL, quality, lutPath)
+	}
+
+	var vmafSum, psnrSum float64
+	vmafMeasured := true
+	for i := range windows {
+		scores, err := f.measureQuality(sourcePath, renditionURL, quality, lutPath, &windows[i])
+		if err != nil {
+			return nil, err
+		}
+		psnrSum += *scores.PSNR
+		if scores.VMAF == nil {
+			vmafMeasured = false
+		} else {
+			vmafSum += *scores.VMAF
+		}
+	}
+
+	n := float64(len(windows))
+	psnr := psnrSum / n
+	scores := &QualityScores{PSNR: &psnr}
+	if vmafMeasured {
+		vmaf := vmafSum / n
+		scores.VMAF = &vmaf
+	}
+	return scores, nil
+}
+
+// measureQuality scores a rendition against its source, over one window
+// or, when window is nil, the whole film
+func (f *FFmpeg) measureQuality(sourcePath, renditionURL string, quality QualityLevel, lutPath string, window *Window) (*QualityScores, error) {
+	reference := fmt.Sprintf("scale=%d:%d", quality.Width, quality.Height)
+	if lut := lutFilter(lutPath); lut != "" {
+		reference += "," + lut
+	}
+
+	scores := &QualityScores{}
+
+	stderr, err := f.compare(sourcePath, renditionURL, reference, "psnr", window)
+	if err != nil {
+		return nil, err
+	}
+	psnr, ok := parseScore(psnrAverageRegex, stderr)
+	if !ok {
+		return nil, fmt.Errorf("could not parse PSNR")
+	}
+	scores.PSNR = &psnr
+
+	stderr, err = f.compare(sourcePath, renditionURL, reference, "libvmaf", window)
+	if err != nil {
+		if strings.Contains(err.Error(), "No such filter") {
+			return scores, nil
+		}
+		return nil, err
+	}
+	if vmaf, ok := parseScore(vmafScoreRegex, stderr); ok {
+		scores.VMAF = &vmaf
+	}
+
+	return scores, nil
+}
+
+// compare runs a two-input metric filter over a rendition (the distorted
+// input) and its source (the reference), both cut to window when one is
+// given, and returns FFmpeg's log
+func (f *FFmpeg) compare(sourcePath, renditionURL, reference, metric string, window *Window) (string, error) {
+	filter := fmt.Sprintf(
+		"[0:v]settb=AVTB,setpts=PTS-STARTPTS[dist];[1:v]%s,settb=AVTB,setpts=PTS-STARTPTS[ref];[dist][ref]%s",
+		reference, metric,
+	)
+
+	var seek []string
+	if window != nil {
+		seek = []string{
+			"-ss", fmt.Sprintf("%.3f", window.Start),
+			"-t", fmt.Sprintf("%.3f", window.Duration),
+		}
+	}
+	args := append([]string{}, seek...)
+	args = append(args, "-i", renditionURL)
+	args = append(args, seek...)
+	args = append(args,
+		"-i", sourcePath,
+		"-lavfi", filter,
+		"-f", "null",
+		"-",
+	)
+
+	if err := f.injectFault(); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command(f.path, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("ffmpeg %s failed: %w, stderr: %s", metric, err, stderr.String())
+	}
+	return stderr.String(), nil
+}
+
+// parseScore reads the last score matching re from FFmpeg's log. An
+// infinite PSNR (identical pictures) is reported as 100 dB.
+func parseScore(re *regexp.Regexp, log string) (float64, bool) {
+	matches := re.FindAllStringSubmatch(log, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	value := matches[len(matches)-1][1]
+	if value == "inf" {
+		return 100, true
+	}
+	score, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return score, true
+}
@@ -0,0 +1,216 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/events"
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+	"github.com/arjunaayasa/filmtube/backend/internal/settings"
+	"github.com/arjunaayasa/filmtube/backend/internal/urlimport"
+	"github.com/arjunaayasa/filmtube/worker/internal/ytdlp"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// processCatalogImportItem imports one video of a catalog import: it
+// creates a DRAFT film from the video's metadata, stores the video as the
+// film's source and queues its transcode. A retried item reuses the film
+// it already created.
+func (p *Processor) processCatalogImportItem(ctx context.Context, task *models.WorkerTask) error {
+	itemID, err := uuid.Parse(task.Params["item_id"])
+	if err != nil {
+		return fmt.Errorf("invalid item_id parameter: %w", err)
+	}
+	item, err := p.queries.GetCatalogImportItem(ctx, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to get import item: %w", err)
+	}
+	if item.Status == models.ImportItemQueued {
+		return nil
+	}
+	imp, err := p.queries.GetCatalogImport(ctx, item.ImportID)
+	if err != nil {
+		return fmt.Errorf("failed to get import: %w", err)
+	}
+
+	if err := p.importCatalogItem(ctx, task, imp, item); err != nil {
+		message := err.Error()
+		item.Status = models.ImportItemFailed
+		item.Error = &message
+		p.saveImportItem(ctx, item)
+		return err
+	}
+
+	item.Status = models.ImportItemQueued
+	item.Error = nil
+	p.saveImportItem(ctx, item)
+	task.FilmID = *item.FilmID
+	task.Result = map[string]string{"film_id": item.FilmID.String()}
+	return nil
+}
+
+func (p *Processor) importCatalogItem(ctx context.Context, task *models.WorkerTask, imp *models.CatalogImport, item *models.CatalogImportItem) error {
+	if item.FilmID == nil {
+		item.Status = models.ImportItemFetching
+		p.saveImportItem(ctx, item)
+
+		film, err := p.createImportedFilm(ctx, imp, item)
+		if err != nil {
+			return err
+		}
+		item.FilmID = &film.ID
+		item.Title = &film.Title
+	}
+	filmID := *item.FilmID
+
+	item.Status = models.ImportItemDownloading
+	p.saveImportItem(ctx, item)
+
+	if err := p.queries.SetFilmSourceRegion(ctx, filmID, task.Region); err != nil {
+		return fmt.Errorf("failed to record source region: %w", err)
+	}
+	if err := p.redis.SetFilmRegion(ctx, filmID, task.Region); err != nil {
+		log.Printf("[Task] Warning: failed to cache source region of film %s: %v", filmID, err)
+	}
+	p.recordUploadMilestone(ctx, filmID, models.UploadStarted)
+
+	ctx, cancel := context.WithTimeout(ctx, importTimeout)
+	defer cancel()
+
+	maxBytes := p.settings.Int(ctx, settings.KeyMaxUploadBytes)
+	store := p.r2Client.ForRegion(task.Region)
+	if item.Provider == models.ImportProviderDirect {
+		if err := p.storeDirectImport(ctx, store, filmID, item.SourceURL, maxBytes); err != nil {
+			return err
+		}
+	} else {
+		file, cleanup, err := p.ytdlp.Download(ctx, item.SourceURL, maxBytes)
+		defer cleanup()
+		if errors.Is(err, ytdlp.ErrTooLarge) {
+			return fmt.Errorf("video is larger than the %d byte upload limit", maxBytes)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to download video: %w", err)
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := store.UploadOriginalVideo(ctx, filmID, f); err != nil {
+			return fmt.Errorf("failed to store imported source: %w", err)
+		}
+	}
+
+	return p.queueImportedTranscode(ctx, filmID)
+}
+
+// createImportedFilm creates the DRAFT film for an item, titled and
+// described from the video's metadata
+func (p *Processor) createImportedFilm(ctx context.Context, imp *models.CatalogImport, item *models.CatalogImportItem) (*models.Film, error) {
+	film := &models.Film{
+		ID:          uuid.New(),
+		Type:        imp.FilmType,
+		Status:      models.StatusDraft,
+		CreatedByID: imp.CreatedByID,
+		TenantID:    imp.TenantID,
+		Tags:        pq.StringArray{},
+	}
+
+	if item.Provider == models.ImportProviderDirect {
+		u, err := url.Parse(item.SourceURL)
+		if err != nil {
+			return nil, err
+		}
+		name := path.Base(u.Path)
+		film.Title = strings.TrimSuffix(name, path.Ext(name))
+	} else {
+		meta, err := p.ytdlp.Metadata(ctx, item.SourceURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+		}
+		film.Title = meta.Title
+		film.Description = meta.Description
+		film.Duration = int(meta.Duration)
+		for _, tag := range meta.Tags {
+			if len(film.Tags) == 20 {
+				break
+			}
+			if tag = strings.TrimSpace(tag); tag != "" && len(tag) <= 50 {
+				film.Tags = append(film.Tags, tag)
+			}
+		}
+	}
+	film.Title = strings.TrimSpace(film.Title)
+	if film.Title == "" || film.Title == "." || film.Title == "/" {
+		film.Title = fmt.Sprintf("Imported video %d", item.Position+1)
+	}
+	if runes := []rune(film.Title); len(runes) > 500 {
+		film.Title = string(runes[:500])
+	}
+
+	if err := p.queries.CreateFilm(ctx, film); err != nil {
+		return nil, fmt.Errorf("failed to create film: %w", err)
+	}
+	events.Emit(ctx, p.redis, models.FilmEventCreated, film.ID)
+	if err := p.indexer.SyncFilm(ctx, film.ID); err != nil {
+		log.Printf("[Task] Warning: failed to index film: %v", err)
+	}
+	return film, nil
+}
+
+// storeDirectImport streams a plain video file URL into a film's source
+func (p *Processor) storeDirectImport(ctx context.Context, store *r2.Client, filmID uuid.UUID, rawURL string, maxBytes int64) error {
+	var schemes []string
+	if err := p.settings.Decode(ctx, settings.KeyImportURLSchemes, &schemes); err != nil {
+		return fmt.Errorf("failed to load import settings: %w", err)
+	}
+	u, err := urlimport.ValidateURL(rawURL, schemes)
+	if err != nil {
+		return err
+	}
+	resp, err := urlimport.Fetch(ctx, urlimport.NewClient(schemes, importTimeout), u, maxBytes)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", u.Host, err)
+	}
+	defer resp.Body.Close()
+	if err := store.UploadOriginalVideo(ctx, filmID, resp.Body); err != nil {
+		return fmt.Errorf("failed to store imported source: %w", err)
+	}
+	return nil
+}
+
+// queueImportedTranscode queues the malware scan of an imported source,
+// which submits it for transcoding once it passes, as confirming an upload
+// does
+func (p *Processor) queueImportedTranscode(ctx context.Context, filmID uuid.UUID) error {
+	job := &models.TranscodeJob{
+		ID:     uuid.New(),
+		FilmID: filmID,
+		Status: models.StatusUploaded,
+	}
+	if err := p.queries.CreateTranscodeJob(ctx, job); err != nil {
+		return fmt.Errorf("failed to create transcode job: %w", err)
+	}
+	p.recordUploadMilestone(ctx, filmID, models.UploadConfirmed)
+
+	if err := p.queueSourceScan(ctx, filmID); err != nil {
+		return fmt.Errorf("failed to queue source scan: %w", err)
+	}
+	return nil
+}
+
+// saveImportItem records an item's progress; a failure is only logged
+func (p *Processor) saveImportItem(ctx context.Context, item *models.CatalogImportItem) {
+	if err := p.queries.UpdateCatalogImportItem(ctx, item); err != nil {
+		log.Printf("[Task] Warning: failed to record import item %s: %v", item.ID, err)
+	}
+}
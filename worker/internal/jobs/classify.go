@@ -0,0 +1,154 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/settings"
+	"github.com/arjunaayasa/filmtube/worker/internal/classifier"
+	"github.com/google/uuid"
+)
+
+// classificationPolicy loads the moderation.content_classification setting
+func (p *Processor) classificationPolicy(ctx context.Context) models.ClassificationPolicy {
+	var policy models.ClassificationPolicy
+	if err := p.settings.Decode(ctx, settings.KeyContentClassify, &policy); err != nil {
+		log.Printf("[Classify] Failed to load classification policy: %v", err)
+	}
+	return policy
+}
+
+// queueClassification queues the content classification of a newly
+// transcoded film while the policy is enabled. The film is already
+// playable; a flag is dealt with after the fact.
+func (p *Processor) queueClassification(ctx context.Context, filmID uuid.UUID) {
+	if !p.classificationPolicy(ctx).Enabled {
+		return
+	}
+
+	task := &models.WorkerTask{
+		ID:        uuid.New(),
+		Type:      models.TaskClassifyContent,
+		FilmID:    filmID,
+		CreatedAt: time.Now(),
+	}
+	if err := p.redis.EnqueueTask(ctx, task); err != nil {
+		log.Printf("[Job] Warning: failed to queue content classification: %v", err)
+	}
+}
+
+// processClassifyContent scores frames sampled evenly over a film with the
+// worker's classifier and records each label's highest score. When one of
+// the policy's labels reaches its threshold the film is reported to the
+// moderation queue or age restricted, as the policy says.
+func (p *Processor) processClassifyContent(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	policy := p.classificationPolicy(ctx)
+	record := &models.ContentClassification{
+		FilmID:     filmID,
+		Classifier: p.classifier.Name(),
+		Status:     models.ClassificationCompleted,
+		Scores:     json.RawMessage("{}"),
+	}
+
+	if record.Classifier == models.ClassifierNone {
+		record.Status = models.ClassificationSkipped
+		task.Result = map[string]string{"status": string(record.Status)}
+		return p.queries.RecordContentClassification(ctx, record)
+	}
+
+	log.Printf("[Task] Downloading video from R2 for classification...")
+	videoData, err := p.downloadSource(ctx, filmID)
+	if err != nil {
+		return p.failClassification(ctx, record, fmt.Errorf("failed to download video: %w", err))
+	}
+	info, err := p.ffmpeg.GetVideoInfo(videoData)
+	if err != nil {
+		return p.failClassification(ctx, record, fmt.Errorf("failed to get video info: %w", err))
+	}
+
+	scores := classifier.Scores{}
+	for i := 0; i < policy.Frames; i++ {
+		at := time.Duration(float64(info.Duration) * (float64(i) + 0.5) / float64(policy.Frames))
+		frame, err := p.ffmpeg.GenerateThumbnail(videoData, at)
+		if err != nil {
+			return p.failClassification(ctx, record, fmt.Errorf("failed to extract frame at %s: %w", at, err))
+		}
+		frameScores, err := p.classifier.Classify(ctx, frame)
+		if err != nil {
+			return p.failClassification(ctx, record, fmt.Errorf("failed to classify frame at %s: %w", at, err))
+		}
+		for label, score := range frameScores {
+			if score > scores[label] {
+				scores[label] = score
+			}
+		}
+		record.Frames++
+	}
+	if record.Scores, err = json.Marshal(scores); err != nil {
+		return p.failClassification(ctx, record, err)
+	}
+
+	task.Result = map[string]string{"status": string(record.Status)}
+	if label, score, ok := flaggedLabel(scores, policy); ok {
+		reason := fmt.Sprintf("Content classifier: %s %.2f", label, score)
+		if err := p.actOnClassification(ctx, filmID, label, reason, policy.Action); err != nil {
+			return p.failClassification(ctx, record, err)
+		}
+		record.Label = &label
+		record.Action = &policy.Action
+		task.Result["label"] = label
+		task.Result["score"] = strconv.FormatFloat(score, 'f', 2, 64)
+		task.Result["action"] = string(policy.Action)
+		log.Printf("[Task] Film %s flagged %s (%.2f): %s", filmID, label, score, policy.Action)
+	}
+
+	if err := p.queries.RecordContentClassification(ctx, record); err != nil {
+		return fmt.Errorf("failed to record classification: %w", err)
+	}
+	return nil
+}
+
+// actOnClassification reports a flagged film to the moderation queue as
+// the classifier account, or age restricts it
+func (p *Processor) actOnClassification(ctx context.Context, filmID uuid.UUID, label, reason string, action models.ClassificationAction) error {
+	if action == models.ClassificationAgeRestrict {
+		if _, err := p.moderation.AgeRestrictFilm(ctx, filmID, reason); err != nil {
+			return fmt.Errorf("failed to age restrict film: %w", err)
+		}
+		return nil
+	}
+	category := models.ClassificationCategory(label)
+	if _, err := p.queries.ReportFilm(ctx, filmID, models.ClassifierAccountID, category, reason); err != nil {
+		return fmt.Errorf("failed to report film: %w", err)
+	}
+	return nil
+}
+
+// failClassification records that a classification reached no result and
+// returns its error
+func (p *Processor) failClassification(ctx context.Context, record *models.ContentClassification, classifyErr error) error {
+	record.Status = models.ClassificationError
+	record.Error = classifyErr.Error()
+	if err := p.queries.RecordContentClassification(ctx, record); err != nil {
+		log.Printf("[Task] Warning: failed to record classification error: %v", err)
+	}
+	return classifyErr
+}
+
+// flaggedLabel returns the highest scoring of the policy's labels when it
+// reached the threshold
+func flaggedLabel(scores classifier.Scores, policy models.ClassificationPolicy) (string, float64, bool) {
+	flagged, best := "", 0.0
+	for _, label := range policy.Labels {
+		if score, ok := scores[label]; ok && score >= policy.Threshold && score > best {
+			flagged, best = label, score
+		}
+	}
+	return flagged, best, flagged != ""
+}
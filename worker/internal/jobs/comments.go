@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/comments"
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+)
+
+// processCommentImport validates an uploaded comment archive and, unless
+// it is a dry run, stores the valid comments under ghost users. The
+// validation report goes in the task result either way.
+func (p *Processor) processCommentImport(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	key := r2.GetCommentImportKey(filmID, task.ID)
+	dryRun := task.Params["dry_run"] == "true"
+
+	log.Printf("[Task] Downloading comment archive from R2...")
+	data, err := p.r2Client.DownloadFile(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to download comment archive: %w", err)
+	}
+	defer func() {
+		if err := p.r2Client.DeleteFile(ctx, key); err != nil {
+			log.Printf("[Task] Failed to delete comment archive %s: %v", key, err)
+		}
+	}()
+
+	var archive models.CommentArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return fmt.Errorf("invalid comment archive: %w", err)
+	}
+
+	existing, err := p.queries.ListCommentArchiveIDs(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to load existing comments: %w", err)
+	}
+
+	valid, report := comments.Validate(&archive, existing, time.Now())
+	log.Printf("[Task] Comment archive has %d valid, %d invalid, %d already imported", len(valid), report.Invalid, report.Skipped)
+
+	if !dryRun && len(valid) > 0 {
+		imported, skipped, err := p.queries.ImportComments(ctx, filmID, task.RequestedBy, valid)
+		if err != nil {
+			return fmt.Errorf("failed to import comments: %w", err)
+		}
+		report.Imported = imported
+		report.Skipped += skipped
+	}
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	task.Result = map[string]string{
+		"dry_run":  strconv.FormatBool(dryRun),
+		"total":    strconv.Itoa(report.Total),
+		"valid":    strconv.Itoa(len(valid)),
+		"imported": strconv.Itoa(report.Imported),
+		"skipped":  strconv.Itoa(report.Skipped),
+		"invalid":  strconv.Itoa(report.Invalid),
+		"report":   string(reportJSON),
+	}
+	return nil
+}
+
+// processCommentExport writes a film's comment threads to R2 as an archive
+// that can be imported again
+func (p *Processor) processCommentExport(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+
+	archived, err := p.queries.ListCommentsForExport(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to load comments: %w", err)
+	}
+
+	now := time.Now().UTC()
+	data, err := json.MarshalIndent(models.CommentArchive{
+		FilmID:     &filmID,
+		ExportedAt: &now,
+		Comments:   archived,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode comment archive: %w", err)
+	}
+
+	key := r2.GetCommentExportKey(filmID, task.ID)
+	if err := p.r2Client.UploadFile(ctx, key, bytes.NewReader(data), "application/json"); err != nil {
+		return fmt.Errorf("failed to upload comment archive: %w", err)
+	}
+
+	task.Result = map[string]string{
+		"key":      key,
+		"comments": strconv.Itoa(len(archived)),
+	}
+	return nil
+}
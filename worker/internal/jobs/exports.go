@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/adminlists"
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+)
+
+// processAdminExport writes the rows of an admin listing matching the
+// task's filters to R2 as CSV or JSON
+func (p *Processor) processAdminExport(ctx context.Context, task *models.WorkerTask) error {
+	listing, ok := adminlists.Lookup(task.Params["listing"])
+	if !ok {
+		return fmt.Errorf("unknown listing %q", task.Params["listing"])
+	}
+	format := task.Params["format"]
+	contentType := adminlists.ContentType(format)
+	if contentType == "" {
+		return fmt.Errorf("unknown export format %q", format)
+	}
+	filter, err := adminlists.ParseFilterParams(task.Params)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	rows, truncated, err := listing.Export(ctx, p.queries, filter, format, &buf)
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", listing.Name(), err)
+	}
+	if truncated {
+		log.Printf("[Task] Export of %s stopped at %d rows", listing.Name(), rows)
+	}
+
+	key := r2.GetAdminExportKey(task.ID, format)
+	if err := p.r2Client.UploadFile(ctx, key, bytes.NewReader(buf.Bytes()), contentType); err != nil {
+		return fmt.Errorf("failed to upload export: %w", err)
+	}
+
+	task.Result = map[string]string{
+		"key":       key,
+		"rows":      strconv.Itoa(rows),
+		"truncated": strconv.FormatBool(truncated),
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/settings"
+	"github.com/arjunaayasa/filmtube/backend/internal/urlimport"
+	"github.com/google/uuid"
+)
+
+const (
+	// importTimeout bounds a whole URL import, however large the file
+	importTimeout = 6 * time.Hour
+	// importProgressEvery is how many bytes pass between progress updates
+	importProgressEvery = 16 << 20
+)
+
+// processImportURL downloads a remote video file into the film's source,
+// reporting upload progress as it goes. The result carries the stored
+// size and SHA-256, which the creator can pass when confirming.
+func (p *Processor) processImportURL(ctx context.Context, task *models.WorkerTask) error {
+	var schemes []string
+	if err := p.settings.Decode(ctx, settings.KeyImportURLSchemes, &schemes); err != nil {
+		return fmt.Errorf("failed to load import settings: %w", err)
+	}
+	u, err := urlimport.ValidateURL(task.Params["url"], schemes)
+	if err != nil {
+		return err
+	}
+	maxBytes := p.settings.Int(ctx, settings.KeyMaxUploadBytes)
+
+	ctx, cancel := context.WithTimeout(ctx, importTimeout)
+	defer cancel()
+
+	log.Printf("[Task] Importing source of film %s from %s", task.FilmID, u.Host)
+	resp, err := urlimport.Fetch(ctx, urlimport.NewClient(schemes, importTimeout), u, maxBytes)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", u.Host, err)
+	}
+	defer resp.Body.Close()
+
+	total := max(resp.ContentLength, 0)
+	if err := p.redis.InitUploadProgress(ctx, task.FilmID, total, 1); err != nil {
+		log.Printf("[Task] Warning: failed to reset upload progress: %v", err)
+	}
+
+	store, err := p.sourceStore(ctx, task.FilmID)
+	if err != nil {
+		return err
+	}
+	body := &importReader{reader: resp.Body, hash: sha256.New(), ctx: ctx, processor: p, filmID: task.FilmID}
+	if err := store.UploadOriginalVideo(ctx, task.FilmID, body); err != nil {
+		if body.tooLarge {
+			return fmt.Errorf("remote file is larger than the %d byte upload limit", maxBytes)
+		}
+		return fmt.Errorf("failed to store imported source: %w", err)
+	}
+	if body.read == 0 {
+		return errors.New("remote file is empty")
+	}
+	body.report()
+
+	task.Result = map[string]string{
+		"bytes":        strconv.FormatInt(body.read, 10),
+		"sha256":       hex.EncodeToString(body.hash.Sum(nil)),
+		"content_type": resp.Header.Get("Content-Type"),
+	}
+	return nil
+}
+
+// importReader hashes and counts an imported file as it is stored,
+// publishing upload progress every importProgressEvery bytes
+type importReader struct {
+	reader    io.Reader
+	hash      hash.Hash
+	read      int64
+	reported  int64
+	tooLarge  bool
+	ctx       context.Context
+	processor *Processor
+	filmID    uuid.UUID
+}
+
+func (r *importReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if errors.Is(err, urlimport.ErrTooLarge) {
+		r.tooLarge = true
+	}
+	r.hash.Write(p[:n])
+	r.read += int64(n)
+	if r.read-r.reported >= importProgressEvery {
+		r.report()
+	}
+	return n, err
+}
+
+// report records the bytes imported so far as the upload's single part
+func (r *importReader) report() {
+	r.reported = r.read
+	if _, err := r.processor.redis.RecordUploadPart(r.ctx, r.filmID, 1, r.read); err != nil {
+		log.Printf("[Task] Warning: failed to record import progress: %v", err)
+	}
+}
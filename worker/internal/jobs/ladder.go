@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/settings"
+	"github.com/arjunaayasa/filmtube/worker/internal/ffmpeg"
+	"github.com/google/uuid"
+)
+
+// qualityLadder returns the quality levels new encodes produce: the
+// profiles the transcode.quality_ladder setting names, in its order, with
+// their settings from transcode_profiles. Names with no enabled profile
+// are skipped; when none are left, or the profiles cannot be loaded, the
+// built-in ffmpeg.Qualities are used.
+func (p *Processor) qualityLadder(ctx context.Context) []ffmpeg.QualityLevel {
+	var names []string
+	if err := p.settings.Decode(ctx, settings.KeyQualityLadder, &names); err != nil {
+		log.Printf("[Job] Warning: failed to load quality ladder: %v", err)
+		return ffmpeg.Qualities
+	}
+
+	profiles, err := p.queries.ListTranscodeProfiles(ctx)
+	if err != nil {
+		log.Printf("[Job] Warning: failed to load transcode profiles: %v", err)
+		return ffmpeg.Qualities
+	}
+	byName := map[string]ffmpeg.QualityLevel{}
+	for _, profile := range profiles {
+		if profile.Enabled {
+			byName[profile.Name] = qualityFromProfile(profile)
+		}
+	}
+
+	ladder := []ffmpeg.QualityLevel{}
+	for _, name := range names {
+		quality, ok := byName[name]
+		if !ok {
+			log.Printf("[Job] Warning: quality ladder names %q, which has no enabled transcode profile", name)
+			continue
+		}
+		ladder = append(ladder, quality)
+	}
+	if len(ladder) == 0 {
+		return ffmpeg.Qualities
+	}
+	return ladder
+}
+
+// knownQualities returns every quality level a rendition may have been
+// encoded at, lowest first: all transcode profiles, enabled or not, and
+// the built-in ffmpeg.Qualities, so renditions encoded under an earlier
+// ladder are still recognised.
+func (p *Processor) knownQualities(ctx context.Context) []ffmpeg.QualityLevel {
+	byName := map[string]ffmpeg.QualityLevel{}
+	for _, q := range ffmpeg.Qualities {
+		byName[q.Name] = q
+	}
+	profiles, err := p.queries.ListTranscodeProfiles(ctx)
+	if err != nil {
+		log.Printf("[Job] Warning: failed to load transcode profiles: %v", err)
+	}
+	for _, profile := range profiles {
+		byName[profile.Name] = qualityFromProfile(profile)
+	}
+
+	qualities := make([]ffmpeg.QualityLevel, 0, len(byName))
+	for _, q := range byName {
+		qualities = append(qualities, q)
+	}
+	sort.Slice(qualities, func(i, j int) bool {
+		if qualities[i].Height != qualities[j].Height {
+			return qualities[i].Height < qualities[j].Height
+		}
+		return qualities[i].Name < qualities[j].Name
+	})
+	return qualities
+}
+
+// qualityByName returns the quality level a default rendition is encoded at
+func (p *Processor) qualityByName(ctx context.Context, name string) (ffmpeg.QualityLevel, bool) {
+	for _, q := range p.knownQualities(ctx) {
+		if q.Name == name {
+			return q, true
+		}
+	}
+	return ffmpeg.QualityLevel{}, false
+}
+
+// masterPlaylist generates a master playlist listing the named renditions
+func (p *Processor) masterPlaylist(ctx context.Context, filmID uuid.UUID, names []string) ([]byte, error) {
+	known := map[string]ffmpeg.QualityLevel{}
+	for _, q := range p.knownQualities(ctx) {
+		known[q.Name] = q
+	}
+	qualities := make([]ffmpeg.QualityLevel, 0, len(names))
+	for _, name := range names {
+		quality, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown rendition %q", name)
+		}
+		qualities = append(qualities, quality)
+	}
+	return p.ffmpeg.GenerateMasterPlaylist(filmID.String(), qualities)
+}
+
+// qualityFromProfile converts a transcode profile to an encoder quality level
+func qualityFromProfile(profile models.TranscodeProfile) ffmpeg.QualityLevel {
+	return ffmpeg.QualityLevel{
+		Name:    profile.Name,
+		Width:   profile.Width,
+		Height:  profile.Height,
+		Bitrate: profile.VideoBitrate,
+		Audio:   profile.AudioBitrate,
+	}
+}
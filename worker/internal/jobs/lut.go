@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+	"github.com/google/uuid"
+)
+
+// fetchLUT downloads the LUT that applies to a film into a temp file. It
+// returns an empty path when no LUT applies; the cleanup func is always safe
+// to call.
+func (p *Processor) fetchLUT(ctx context.Context, filmID uuid.UUID) (string, *models.LUT, func(), error) {
+	noop := func() {}
+
+	lut, err := p.queries.GetEffectiveLUT(ctx, filmID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil, noop, nil
+	}
+	if err != nil {
+		return "", nil, noop, fmt.Errorf("failed to look up LUT: %w", err)
+	}
+
+	data, err := p.r2Client.DownloadFile(ctx, lut.StorageKey)
+	if err != nil {
+		return "", nil, noop, fmt.Errorf("failed to download LUT: %w", err)
+	}
+
+	f, err := os.CreateTemp("", fmt.Sprintf("lut_%s_*.cube", filmID))
+	if err != nil {
+		return "", nil, noop, fmt.Errorf("failed to create LUT temp file: %w", err)
+	}
+	cleanup := func() { os.Remove(f.Name()) }
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, noop, fmt.Errorf("failed to write LUT temp file: %w", err)
+	}
+	f.Close()
+
+	return f.Name(), lut, cleanup, nil
+}
+
+// processLUTPreview renders matching original and graded frames so a
+// creator can check a LUT before committing to the full transcode
+func (p *Processor) processLUTPreview(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+
+	lutPath, lut, cleanup, err := p.fetchLUT(ctx, filmID)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	if lutPath == "" {
+		return fmt.Errorf("no LUT applies to this film")
+	}
+
+	log.Printf("[Task] Downloading video from R2...")
+	videoData, err := p.downloadSource(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+
+	var timestamps []time.Duration
+	if raw := task.Params["timestamps"]; raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			seconds, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return fmt.Errorf("invalid timestamp %q", part)
+			}
+			timestamps = append(timestamps, time.Duration(seconds*float64(time.Second)))
+		}
+	} else {
+		// Default to frames spread across the film
+		info, err := p.ffmpeg.GetVideoInfo(videoData)
+		if err != nil {
+			return fmt.Errorf("failed to get video info: %w", err)
+		}
+		for _, fraction := range []float64{0.1, 0.5, 0.9} {
+			timestamps = append(timestamps, time.Duration(float64(info.Duration)*fraction))
+		}
+	}
+
+	task.Result = map[string]string{
+		"lut_id":    lut.ID.String(),
+		"lut_scope": string(lut.Scope),
+	}
+
+	for i, ts := range timestamps {
+		original, err := p.ffmpeg.GenerateThumbnail(videoData, ts)
+		if err != nil {
+			return fmt.Errorf("failed to render frame at %v: %w", ts, err)
+		}
+		graded, err := p.ffmpeg.GenerateThumbnailWithLUT(videoData, ts, lutPath)
+		if err != nil {
+			return fmt.Errorf("failed to render graded frame at %v: %w", ts, err)
+		}
+
+		for _, frame := range []struct {
+			data   []byte
+			graded bool
+		}{{original, false}, {graded, true}} {
+			key := r2.GetLUTPreviewKey(filmID, i, frame.graded)
+			if err := p.r2Client.UploadFile(ctx, key, bytes.NewReader(frame.data), "image/jpeg"); err != nil {
+				return fmt.Errorf("failed to upload preview frame: %w", err)
+			}
+			kind := "original"
+			if frame.graded {
+				kind = "graded"
+			}
+			task.Result[fmt.Sprintf("frame_%d_%s", i, kind)] = p.r2Client.GetPublicURL(key)
+		}
+		task.Result[fmt.Sprintf("frame_%d_seconds", i)] = strconv.FormatFloat(ts.Seconds(), 'f', 3, 64)
+	}
+
+	return nil
+}
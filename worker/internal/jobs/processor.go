@@ -5,68 +5,142 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"path"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/arjunaayasa/filmtube/backend/internal/admission"
 	"github.com/arjunaayasa/filmtube/backend/internal/db"
+	"github.com/arjunaayasa/filmtube/backend/internal/events"
 	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/moderation"
+	"github.com/arjunaayasa/filmtube/backend/internal/preflight"
 	"github.com/arjunaayasa/filmtube/backend/internal/r2"
 	"github.com/arjunaayasa/filmtube/backend/internal/redis"
+	"github.com/arjunaayasa/filmtube/backend/internal/search"
+	"github.com/arjunaayasa/filmtube/backend/internal/settings"
+	"github.com/arjunaayasa/filmtube/backend/internal/timeline"
+	"github.com/arjunaayasa/filmtube/worker/internal/classifier"
 	"github.com/arjunaayasa/filmtube/worker/internal/ffmpeg"
+	"github.com/arjunaayasa/filmtube/worker/internal/scanner"
+	"github.com/arjunaayasa/filmtube/worker/internal/ytdlp"
 	"github.com/google/uuid"
 )
 
 // Processor handles video transcoding jobs
 type Processor struct {
-	queries   *db.Queries
-	r2Client  *r2.Client
-	redis     *redis.Client
-	ffmpeg    *ffmpeg.FFmpeg
+	queries    *db.Queries
+	r2Client   *r2.Client
+	redis      *redis.Client
+	ffmpeg     *ffmpeg.FFmpeg
+	indexer    *search.Indexer
+	settings   *settings.Service
+	timeline   *timeline.Builder
+	scanner    scanner.Scanner
+	ytdlp      *ytdlp.YTDLP
+	admission  *admission.Controller
+	classifier classifier.Classifier
+	moderation *moderation.Queue
 }
 
-func NewProcessor(queries *db.Queries, r2Client *r2.Client, redisClient *redis.Client, ffmpeg *ffmpeg.FFmpeg) *Processor {
+func NewProcessor(queries *db.Queries, r2Client *r2.Client, redisClient *redis.Client, ffmpeg *ffmpeg.FFmpeg, indexer *search.Indexer, settingsService *settings.Service, fileScanner scanner.Scanner, contentClassifier classifier.Classifier, downloader *ytdlp.YTDLP) *Processor {
 	return &Processor{
-		queries:  queries,
-		r2Client: r2Client,
-		redis:    redisClient,
-		ffmpeg:   ffmpeg,
+		queries:    queries,
+		r2Client:   r2Client,
+		redis:      redisClient,
+		ffmpeg:     ffmpeg,
+		indexer:    indexer,
+		settings:   settingsService,
+		timeline:   timeline.New(queries, r2Client, redisClient),
+		scanner:    fileScanner,
+		ytdlp:      downloader,
+		admission:  admission.New(queries, redisClient, indexer, settingsService),
+		classifier: contentClassifier,
+		moderation: moderation.NewQueue(queries, indexer, settingsService),
 	}
 }
 
+// setTranscodeProgress records the job's progress and pushes it to the
+// film's status stream
+func (p *Processor) setTranscodeProgress(ctx context.Context, filmID uuid.UUID, progress int) error {
+	if err := p.queries.UpdateTranscodeJobStatus(ctx, filmID, models.StatusTranscoding, progress, ""); err != nil {
+		return err
+	}
+	if err := p.redis.PublishTranscodeProgress(ctx, filmID, progress); err != nil {
+		log.Printf("[Job] Warning: failed to publish transcode progress: %v", err)
+	}
+	return nil
+}
+
 // ProcessJob processes a single transcoding job for a film
 func (p *Processor) ProcessJob(ctx context.Context, filmID uuid.UUID) error {
 	log.Printf("[Job] Starting transcoding for film %s", filmID)
 
+	// A source found infected has already failed the film
+	if p.sourceRejected(ctx, filmID) {
+		log.Printf("[Job] Skipping film %s: source failed its malware scan", filmID)
+		return nil
+	}
+
 	// Update job status to TRANSCODING
-	if err := p.queries.UpdateTranscodeJobStatus(ctx, filmID, models.StatusTranscoding, 10, ""); err != nil {
+	if err := p.setTranscodeProgress(ctx, filmID, 10); err != nil {
 		return fmt.Errorf("failed to update job status: %w", err)
 	}
+	p.recordUploadMilestone(ctx, filmID, models.EncodeStarted)
 
 	// Download original video from R2
 	log.Printf("[Job] Downloading video from R2...")
-	videoData, err := p.r2Client.DownloadOriginalVideo(ctx, filmID)
+	videoData, err := p.downloadSource(ctx, filmID)
 	if err != nil {
+		if p.sourceRejected(ctx, filmID) {
+			return nil
+		}
 		p.markFailed(ctx, filmID, fmt.Sprintf("failed to download video: %v", err))
 		return fmt.Errorf("failed to download video: %w", err)
 	}
 
 	// Get video info
 	log.Printf("[Job] Getting video info...")
-	ffmpegHandler := ffmpeg.New("ffmpeg", "/tmp")
+	ffmpegHandler := p.ffmpeg
 	videoInfo, err := ffmpegHandler.GetVideoInfo(videoData)
 	if err != nil {
 		p.markFailed(ctx, filmID, fmt.Sprintf("failed to get video info: %v", err))
 		return fmt.Errorf("failed to get video info: %w", err)
 	}
 
-	log.Printf("[Job] Video info: duration=%v, resolution=%dx%d",
-		videoInfo.Duration, videoInfo.Width, videoInfo.Height)
+	log.Printf("[Job] Video info: duration=%v, resolution=%dx%d, codecs=%s/%s",
+		videoInfo.Duration, videoInfo.Width, videoInfo.Height, videoInfo.VideoCodec, videoInfo.AudioCodec)
+
+	// Reject sources the upload preflight would have rejected
+	if reasons := preflight.Check(preflight.Source{
+		VideoCodec: videoInfo.VideoCodec,
+		AudioCodec: videoInfo.AudioCodec,
+		Width:      videoInfo.Width,
+		Height:     videoInfo.Height,
+	}, 0); len(reasons) > 0 {
+		message := "unsupported source: " + strings.Join(reasons, "; ")
+		p.markFailed(ctx, filmID, message)
+		return fmt.Errorf("%s", message)
+	}
 
 	// Update progress
-	p.queries.UpdateTranscodeJobStatus(ctx, filmID, models.StatusTranscoding, 20, "")
+	p.setTranscodeProgress(ctx, filmID, 20)
+
+	// Grade with the film's, creator's or platform LUT if one is set
+	lutPath, lut, cleanupLUT, err := p.fetchLUT(ctx, filmID)
+	defer cleanupLUT()
+	if err != nil {
+		p.markFailed(ctx, filmID, err.Error())
+		return err
+	}
+	if lut != nil {
+		log.Printf("[Job] Applying %s LUT %q", lut.Scope, lut.Name)
+	}
 
 	// Generate thumbnail at 10% of video
 	thumbnailTime := time.Duration(float64(videoInfo.Duration) * 0.1)
-	thumbnailData, err := ffmpegHandler.GenerateThumbnail(videoData, thumbnailTime)
+	thumbnailData, err := ffmpegHandler.GenerateThumbnailWithLUT(videoData, thumbnailTime, lutPath)
 	if err != nil {
 		log.Printf("[Job] Warning: failed to generate thumbnail: %v", err)
 	} else {
@@ -77,11 +151,17 @@ func (p *Processor) ProcessJob(ctx context.Context, filmID uuid.UUID) error {
 		}
 	}
 
+	// Cut the thumbnail sprite sheet for the timeline
+	if err := p.generateSprite(ctx, filmID, videoData, videoInfo.Duration, lutPath); err != nil {
+		log.Printf("[Job] Warning: failed to generate sprite sheet: %v", err)
+	}
+
 	// Transcode to each quality
 	completedQualities := []string{}
 	progressChan := make(chan int, 100)
 
-	for i, quality := range ffmpeg.Qualities {
+	ladder := p.qualityLadder(ctx)
+	for i, quality := range ladder {
 		log.Printf("[Job] Transcoding to %s...", quality.Name)
 
 		// Start transcoding
@@ -89,7 +169,7 @@ func (p *Processor) ProcessJob(ctx context.Context, filmID uuid.UUID) error {
 		errChan := make(chan error, 1)
 
 		go func(q ffmpeg.QualityLevel) {
-			result, err := ffmpegHandler.TranscodeToHLS(videoData, filmID.String(), q, progressChan)
+			result, err := ffmpegHandler.TranscodeToHLSWithLUT(videoData, filmID.String(), q, lutPath, progressChan)
 			if err != nil {
 				errChan <- err
 				return
@@ -106,23 +186,28 @@ func (p *Processor) ProcessJob(ctx context.Context, filmID uuid.UUID) error {
 		case result := <-resultChan:
 			// Upload HLS files to R2
 			log.Printf("[Job] Uploading HLS files for %s...", quality.Name)
-			if err := p.uploadHLSFiles(ctx, filmID, quality.Name, result.IndexData); err != nil {
+			workspace := ffmpegHandler.WorkspaceDir(filmID.String(), quality.Name)
+			if err := p.uploadHLSFiles(ctx, filmID, quality.Name, workspace, result.IndexData); err != nil {
 				p.markFailed(ctx, filmID, fmt.Sprintf("failed to upload HLS files: %v", err))
 				return fmt.Errorf("failed to upload HLS files: %w", err)
 			}
+			if err := p.recordRendition(ctx, filmID, models.VariantDefault, quality.Name, result.IndexData, workspace); err != nil {
+				p.markFailed(ctx, filmID, err.Error())
+				return err
+			}
 			completedQualities = append(completedQualities, quality.Name)
 		}
 
 		// Update progress (20-80% for transcoding)
 		baseProgress := 20
-		progressPerQuality := 60 / len(ffmpeg.Qualities)
+		progressPerQuality := 60 / len(ladder)
 		currentProgress := baseProgress + (i+1)*progressPerQuality
-		p.queries.UpdateTranscodeJobStatus(ctx, filmID, models.StatusTranscoding, currentProgress, "")
+		p.setTranscodeProgress(ctx, filmID, currentProgress)
 	}
 
 	// Generate and upload master playlist
 	log.Printf("[Job] Generating master playlist...")
-	masterData, err := ffmpegHandler.GenerateMasterPlaylist(filmID.String(), completedQualities)
+	masterData, err := p.masterPlaylist(ctx, filmID, completedQualities)
 	if err != nil {
 		p.markFailed(ctx, filmID, fmt.Sprintf("failed to generate master playlist: %v", err))
 		return fmt.Errorf("failed to generate master playlist: %w", err)
@@ -135,6 +220,12 @@ func (p *Processor) ProcessJob(ctx context.Context, filmID uuid.UUID) error {
 		return fmt.Errorf("failed to upload master playlist: %w", err)
 	}
 
+	// The scan may have quarantined the source while it transcoded
+	if p.sourceRejected(ctx, filmID) {
+		log.Printf("[Job] Discarding renditions of film %s: source failed its malware scan", filmID)
+		return nil
+	}
+
 	// Update film status to READY
 	log.Printf("[Job] Updating film status to READY...")
 	tx, _ := p.queries.db.BeginTx(ctx, nil)
@@ -146,33 +237,82 @@ func (p *Processor) ProcessJob(ctx context.Context, filmID uuid.UUID) error {
 		return fmt.Errorf("failed to update film: %w", err)
 	}
 	tx.Commit()
+	p.recordUploadMilestone(ctx, filmID, models.UploadReady)
 
 	// Mark job as complete
 	p.queries.UpdateTranscodeJobStatus(ctx, filmID, models.StatusReady, 100, "")
 
 	// Update Redis cache
 	p.redis.SetFilmStatus(ctx, filmID, models.StatusReady)
+	events.Emit(ctx, p.redis, models.FilmEventReady, filmID)
+
+	// Make the film searchable
+	if err := p.indexer.SyncFilm(ctx, filmID); err != nil {
+		log.Printf("[Job] Warning: failed to index film: %v", err)
+	}
+
+	// Score the new renditions when the quality check is on
+	p.queueQualityCheck(ctx, filmID)
+
+	// Classify sampled frames for the moderation queue when it is on
+	p.queueClassification(ctx, filmID)
+
+	// Generate the timeline for editing and review UIs
+	p.buildTimeline(ctx, filmID)
 
 	log.Printf("[Job] Transcoding completed successfully for film %s", filmID)
 	return nil
 }
 
-func (p *Processor) uploadHLSFiles(ctx context.Context, filmID uuid.UUID, quality string, indexData []byte) error {
-	// Upload index.m3u8
-	if err := p.r2Client.UploadHLSFile(ctx, filmID, quality, "index.m3u8", bytes.NewReader(indexData)); err != nil {
-		return err
+// recordUploadMilestone timestamps an upload lifecycle stage for latency
+// stats; a failure only loses the sample
+func (p *Processor) recordUploadMilestone(ctx context.Context, filmID uuid.UUID, milestone models.UploadMilestone) {
+	if err := p.queries.RecordUploadMilestone(ctx, filmID, milestone); err != nil {
+		log.Printf("[Job] Warning: failed to record upload milestone: %v", err)
 	}
+}
 
-	// TODO: In a real implementation, you would upload all .ts segments here
-	// For this MVP, we're assuming segments are handled inline
+// uploadHLSFiles streams a rendition's segments from the directory it was
+// encoded to, a few files at a time, then uploads its playlist last so it
+// never lists a segment that is not in R2 yet
+func (p *Processor) uploadHLSFiles(ctx context.Context, filmID uuid.UUID, quality, dir string, indexData []byte) error {
+	segments := parseRenditionPlaylist(indexData)
+	errs := make([]error, len(segments))
 
-	return nil
+	concurrency := p.r2Client.UploadLimits().Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range segments {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			filename := segments[i].filename
+			errs[i] = p.r2Client.UploadHLSLocalFile(ctx, filmID, quality, filename, path.Join(dir, filename))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, seg := range segments {
+		if errs[i] != nil {
+			return fmt.Errorf("failed to upload %s: %w", seg.filename, errs[i])
+		}
+	}
+
+	return p.r2Client.UploadHLSFile(ctx, filmID, quality, "index.m3u8", bytes.NewReader(indexData))
 }
 
 func (p *Processor) markFailed(ctx context.Context, filmID uuid.UUID, errorMsg string) {
 	log.Printf("[Job] Marking job as failed: %s", errorMsg)
 	p.queries.UpdateTranscodeJobStatus(ctx, filmID, models.StatusFailed, 0, errorMsg)
 	p.redis.SetFilmStatus(ctx, filmID, models.StatusFailed)
+	if err := p.queries.RecordUploadFailure(ctx, filmID); err != nil {
+		log.Printf("[Job] Warning: failed to record transcode failure: %v", err)
+	}
 
 	// Also update film status to FAILED
 	tx, _ := p.queries.db.BeginTx(ctx, nil)
@@ -0,0 +1,196 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+	"github.com/arjunaayasa/filmtube/worker/internal/ffmpeg"
+	"github.com/google/uuid"
+)
+
+// processPruneRenditions deletes a film's default renditions that the
+// pruning policy does not keep, after pointing its master playlist at the
+// kept ones, and records the bytes freed. Variants are left alone. A film
+// that has none of the kept renditions is not pruned, so it stays
+// playable.
+func (p *Processor) processPruneRenditions(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	err := p.pruneRenditions(ctx, task)
+	if err != nil {
+		// Forget the prune so the next run tries the film again
+		if delErr := p.queries.DeleteRenditionPrune(ctx, filmID); delErr != nil {
+			log.Printf("[Task] Failed to reset rendition prune for film %s: %v", filmID, delErr)
+		}
+	}
+	return err
+}
+
+func (p *Processor) pruneRenditions(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	keep := map[string]bool{}
+	for _, name := range strings.Split(task.Params["keep"], ",") {
+		if name != "" {
+			keep[name] = true
+		}
+	}
+	if len(keep) == 0 {
+		return fmt.Errorf("missing keep parameter")
+	}
+
+	renditions, err := p.defaultRenditions(ctx, filmID)
+	if err != nil {
+		return err
+	}
+	kept, pruned := []string{}, []string{}
+	for _, name := range renditions {
+		if keep[name] {
+			kept = append(kept, name)
+		} else {
+			pruned = append(pruned, name)
+		}
+	}
+	if len(kept) == 0 {
+		log.Printf("[Task] Film %s has none of the renditions to keep; not pruning", filmID)
+		pruned = []string{}
+	}
+
+	var freed int64
+	if len(pruned) > 0 {
+		if err := p.uploadDefaultMaster(ctx, filmID, kept); err != nil {
+			return err
+		}
+		for _, name := range pruned {
+			prefix := fmt.Sprintf("%s/%s/%s/", r2.HLSPath, filmID, name)
+			size, err := p.r2Client.PrefixSize(ctx, prefix)
+			if err != nil {
+				return fmt.Errorf("failed to size %s rendition: %w", name, err)
+			}
+			if err := p.r2Client.DeletePrefix(ctx, prefix); err != nil {
+				return fmt.Errorf("failed to delete %s rendition: %w", name, err)
+			}
+			freed += size
+		}
+		log.Printf("[Task] Pruned %s from film %s, freeing %d bytes", strings.Join(pruned, ", "), filmID, freed)
+	}
+
+	if err := p.queries.FinishRenditionPrune(ctx, filmID, pruned, freed); err != nil {
+		return fmt.Errorf("failed to record rendition prune: %w", err)
+	}
+
+	task.Result = map[string]string{
+		"kept":        strings.Join(kept, ","),
+		"pruned":      strings.Join(pruned, ","),
+		"bytes_freed": strconv.FormatInt(freed, 10),
+	}
+	return nil
+}
+
+// processRestoreRenditions transcodes a film's pruned renditions again and
+// puts them back in its master playlist
+func (p *Processor) processRestoreRenditions(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+
+	prune, err := p.queries.GetRenditionPrune(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to load rendition prune: %w", err)
+	}
+	if prune.RestoredAt != nil {
+		return nil
+	}
+
+	qualities := []ffmpeg.QualityLevel{}
+	for _, name := range prune.Pruned {
+		quality, ok := p.qualityByName(ctx, name)
+		if !ok {
+			return fmt.Errorf("unknown rendition %q", name)
+		}
+		qualities = append(qualities, quality)
+	}
+
+	log.Printf("[Task] Downloading video from R2 to restore renditions...")
+	videoData, err := p.downloadSource(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+
+	lutPath, _, cleanupLUT, err := p.fetchLUT(ctx, filmID)
+	defer cleanupLUT()
+	if err != nil {
+		return err
+	}
+
+	for _, quality := range qualities {
+		log.Printf("[Task] Re-transcoding pruned %s rendition...", quality.Name)
+		result, err := p.ffmpeg.TranscodeToHLSWithLUT(videoData, filmID.String(), quality, lutPath, nil)
+		if err != nil {
+			return fmt.Errorf("transcoding failed for %s: %w", quality.Name, err)
+		}
+		workspace := p.ffmpeg.WorkspaceDir(filmID.String(), quality.Name)
+		if err := p.uploadHLSFiles(ctx, filmID, quality.Name, workspace, result.IndexData); err != nil {
+			return fmt.Errorf("failed to upload HLS files: %w", err)
+		}
+		if err := p.recordRendition(ctx, filmID, models.VariantDefault, quality.Name, result.IndexData, workspace); err != nil {
+			return err
+		}
+	}
+
+	renditions, err := p.defaultRenditions(ctx, filmID)
+	if err != nil {
+		return err
+	}
+	if err := p.uploadDefaultMaster(ctx, filmID, renditions); err != nil {
+		return err
+	}
+
+	if err := p.queries.MarkRenditionsRestored(ctx, filmID); err != nil {
+		return fmt.Errorf("failed to record restore: %w", err)
+	}
+
+	task.Result = map[string]string{
+		"restored": strings.Join(prune.Pruned, ","),
+	}
+	return nil
+}
+
+// defaultRenditions returns the standard qualities a film has a default
+// rendition playlist for, lowest first
+func (p *Processor) defaultRenditions(ctx context.Context, filmID uuid.UUID) ([]string, error) {
+	prefix := fmt.Sprintf("%s/%s/", r2.HLSPath, filmID)
+	keys, err := p.r2Client.ListKeys(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list HLS files: %w", err)
+	}
+
+	present := map[string]bool{}
+	for _, key := range keys {
+		present[key] = true
+	}
+	renditions := []string{}
+	for _, q := range p.knownQualities(ctx) {
+		if present[prefix+q.Name+"/index.m3u8"] {
+			renditions = append(renditions, q.Name)
+		}
+	}
+	return renditions, nil
+}
+
+// uploadDefaultMaster replaces a film's master playlist with one listing
+// the given default renditions
+func (p *Processor) uploadDefaultMaster(ctx context.Context, filmID uuid.UUID, qualities []string) error {
+	masterData, err := p.masterPlaylist(ctx, filmID, qualities)
+	if err != nil {
+		return fmt.Errorf("failed to generate master playlist: %w", err)
+	}
+
+	masterKey := fmt.Sprintf("%s/%s/master.m3u8", r2.HLSPath, filmID)
+	if err := p.r2Client.UploadFile(ctx, masterKey, bytes.NewReader(masterData), "application/x-mpegURL"); err != nil {
+		return fmt.Errorf("failed to upload master playlist: %w", err)
+	}
+	return nil
+}
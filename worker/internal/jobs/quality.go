@@ -0,0 +1,184 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/settings"
+	"github.com/arjunaayasa/filmtube/worker/internal/ffmpeg"
+	"github.com/google/uuid"
+)
+
+const (
+	// The regression baseline is the average VMAF of this many recently
+	// scored renditions of the same quality, once at least
+	// minBaselineRenditions have been scored
+	baselineRenditions    = 100
+	minBaselineRenditions = 10
+)
+
+// qualityCheckPolicy loads the transcode.quality_check setting
+func (p *Processor) qualityCheckPolicy(ctx context.Context) models.QualityCheckPolicy {
+	var policy models.QualityCheckPolicy
+	if err := p.settings.Decode(ctx, settings.KeyQualityCheck, &policy); err != nil {
+		log.Printf("[Quality] Failed to load quality check policy: %v", err)
+	}
+	return policy
+}
+
+// queueQualityCheck queues the quality check of a newly transcoded film
+// while the policy is enabled. The film is already playable; the check
+// only scores it.
+func (p *Processor) queueQualityCheck(ctx context.Context, filmID uuid.UUID) {
+	if !p.qualityCheckPolicy(ctx).Enabled {
+		return
+	}
+
+	task := &models.WorkerTask{
+		ID:        uuid.New(),
+		Type:      models.TaskQualityCheck,
+		FilmID:    filmID,
+		CreatedAt: time.Now(),
+	}
+	if err := p.redis.EnqueueTask(ctx, task); err != nil {
+		log.Printf("[Job] Warning: failed to queue quality check: %v", err)
+	}
+}
+
+// processQualityCheck scores a film's default renditions against its
+// source with VMAF and PSNR on evenly spaced windows of the film, stores
+// the scores on the renditions and raises an alert for each rendition
+// below the policy's floor or too far below the recent baseline for its
+// quality
+func (p *Processor) processQualityCheck(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	policy := p.qualityCheckPolicy(ctx)
+
+	assets, err := p.queries.GetVideoAssetsByVariant(ctx, filmID, models.VariantDefault)
+	if err != nil {
+		return fmt.Errorf("failed to list video assets: %w", err)
+	}
+	if len(assets) == 0 {
+		return fmt.Errorf("film has no renditions to check")
+	}
+
+	log.Printf("[Task] Downloading video from R2 for quality check...")
+	videoData, err := p.downloadSource(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+	info, err := p.ffmpeg.GetVideoInfo(videoData)
+	if err != nil {
+		return fmt.Errorf("failed to get video info: %w", err)
+	}
+
+	// FFmpeg reads the reference from a file as it is opened per window
+	sourceFile, err := os.CreateTemp("", fmt.Sprintf("qc_%s_*", filmID))
+	if err != nil {
+		return fmt.Errorf("failed to create source temp file: %w", err)
+	}
+	defer os.Remove(sourceFile.Name())
+	if _, err := sourceFile.Write(videoData); err != nil {
+		sourceFile.Close()
+		return fmt.Errorf("failed to write source temp file: %w", err)
+	}
+	sourceFile.Close()
+
+	lutPath, _, cleanupLUT, err := p.fetchLUT(ctx, filmID)
+	defer cleanupLUT()
+	if err != nil {
+		return err
+	}
+
+	windows := sampleWindows(info.Duration.Seconds(), policy.Samples, float64(policy.SampleSeconds))
+	task.Result = map[string]string{}
+	alerts := 0
+	for _, asset := range assets {
+		quality, ok := p.qualityByName(ctx, asset.Quality)
+		if !ok {
+			continue
+		}
+
+		log.Printf("[Task] Scoring %s on %d windows...", quality.Name, len(windows))
+		scores, err := p.ffmpeg.MeasureQualitySampled(sourceFile.Name(), asset.HLSIndexURL, quality, lutPath, windows)
+		if err != nil {
+			return fmt.Errorf("failed to measure %s: %w", quality.Name, err)
+		}
+		if err := p.queries.SetVideoAssetQC(ctx, filmID, models.VariantDefault, quality.Name, scores.VMAF, scores.PSNR); err != nil {
+			return fmt.Errorf("failed to record QC scores: %w", err)
+		}
+
+		task.Result["psnr_"+quality.Name] = strconv.FormatFloat(*scores.PSNR, 'f', 2, 64)
+		if scores.VMAF == nil {
+			// Without libvmaf there is nothing to alert on
+			continue
+		}
+		task.Result["vmaf_"+quality.Name] = strconv.FormatFloat(*scores.VMAF, 'f', 2, 64)
+
+		alerted, err := p.checkQuality(ctx, filmID, quality.Name, *scores.VMAF, policy)
+		if err != nil {
+			return err
+		}
+		if alerted {
+			alerts++
+		}
+	}
+
+	task.Result["alerts"] = strconv.Itoa(alerts)
+	return nil
+}
+
+// checkQuality raises an alert when a default rendition's VMAF is below
+// the floor or has regressed from the baseline for its quality
+func (p *Processor) checkQuality(ctx context.Context, filmID uuid.UUID, quality string, vmaf float64, policy models.QualityCheckPolicy) (bool, error) {
+	alert := &models.QualityAlert{
+		ID:      uuid.New(),
+		FilmID:  filmID,
+		Variant: models.VariantDefault,
+		Quality: quality,
+		VMAF:    vmaf,
+	}
+
+	if vmaf < policy.MinVMAF {
+		alert.Reason = models.QualityBelowFloor
+		alert.Threshold = policy.MinVMAF
+	} else {
+		baseline, err := p.queries.GetQualityBaseline(ctx, quality, filmID, baselineRenditions, minBaselineRenditions)
+		if err != nil {
+			return false, fmt.Errorf("failed to load quality baseline: %w", err)
+		}
+		if baseline == nil || *baseline-vmaf <= policy.MaxDrop {
+			return false, nil
+		}
+		alert.Reason = models.QualityRegression
+		alert.Threshold = *baseline
+	}
+
+	log.Printf("[Quality] Film %s %s scored VMAF %.2f (%s, threshold %.2f)", filmID, quality, vmaf, alert.Reason, alert.Threshold)
+	if err := p.queries.CreateQualityAlert(ctx, alert); err != nil {
+		return false, fmt.Errorf("failed to record quality alert: %w", err)
+	}
+	return true, nil
+}
+
+// sampleWindows spreads n windows of the given length evenly over a film,
+// each centred in its share of the running time. A film too short for
+// them is scored whole.
+func sampleWindows(duration float64, n int, length float64) []ffmpeg.Window {
+	if n < 1 || length <= 0 || duration <= float64(n)*length {
+		return nil
+	}
+
+	windows := make([]ffmpeg.Window, 0, n)
+	share := duration / float64(n)
+	for i := 0; i < n; i++ {
+		start := float64(i)*share + (share-length)/2
+		windows = append(windows, ffmpeg.Window{Start: start, Duration: length})
+	}
+	return windows
+}
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+	"github.com/google/uuid"
+)
+
+// recordRendition stores an uploaded rendition in video_assets with the
+// prefetch hints players use to start faster: its first segment, average
+// segment size and duration, and measured bitrate. Segment sizes are read
+// from dir, the encode's output directory.
+func (p *Processor) recordRendition(ctx context.Context, filmID uuid.UUID, variant, quality string, indexData []byte, dir string) error {
+	renditionPath := quality
+	if variant != models.VariantDefault {
+		renditionPath = fmt.Sprintf("%s/%s", variant, quality)
+	}
+	prefix := fmt.Sprintf("%s/%s/%s", r2.HLSPath, filmID, renditionPath)
+
+	asset := &models.VideoAsset{
+		ID:          uuid.New(),
+		FilmID:      filmID,
+		Quality:     quality,
+		Variant:     variant,
+		HLSIndexURL: p.r2Client.GetPublicURL(prefix + "/index.m3u8"),
+	}
+
+	segments := parseRenditionPlaylist(indexData)
+	if len(segments) > 0 {
+		var seconds float64
+		var size int64
+		for _, seg := range segments {
+			seconds += seg.duration
+			if info, err := os.Stat(path.Join(dir, seg.filename)); err == nil {
+				size += info.Size()
+			}
+		}
+
+		asset.SizeBytes = size
+		asset.FirstSegmentURL = p.r2Client.GetPublicURL(prefix + "/" + segments[0].filename)
+		asset.SegmentCount = len(segments)
+		asset.AvgSegmentBytes = size / int64(len(segments))
+		asset.AvgSegmentSeconds = seconds / float64(len(segments))
+		if seconds > 0 {
+			asset.Bandwidth = int(float64(size*8) / seconds)
+		}
+	}
+
+	if err := p.queries.CreateVideoAsset(ctx, asset); err != nil {
+		return fmt.Errorf("failed to record video asset: %w", err)
+	}
+	return nil
+}
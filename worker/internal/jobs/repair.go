@@ -0,0 +1,194 @@
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+)
+
+// hlsRepairConcurrency bounds the HEAD requests in flight for one film
+const hlsRepairConcurrency = 16
+
+// hlsSegment is a segment referenced by a rendition playlist
+type hlsSegment struct {
+	rendition string // quality name, or {variant}/{quality}
+	filename  string
+	key       string
+	start     float64 // seconds from the start of the film
+	duration  float64
+}
+
+// processRepairHLS checks that every segment each of a film's rendition
+// playlists references exists in R2, and puts back the missing ones. A
+// segment still in the encode workspace is uploaded again; otherwise a
+// default rendition's segment is re-encoded from the original video,
+// covering only that segment's time range. Variant segments cannot be
+// re-encoded on their own, since their picture carries burned-in subtitles
+// or a watermark, and are reported as unrepaired.
+func (p *Processor) processRepairHLS(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	prefix := fmt.Sprintf("%s/%s/", r2.HLSPath, filmID)
+
+	keys, err := p.r2Client.ListKeys(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list HLS files: %w", err)
+	}
+
+	playlists := 0
+	segments := []hlsSegment{}
+	for _, key := range keys {
+		if !strings.HasSuffix(key, "/index.m3u8") {
+			continue
+		}
+		rendition := strings.TrimSuffix(strings.TrimPrefix(key, prefix), "/index.m3u8")
+
+		data, err := p.r2Client.DownloadFile(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to download %s playlist: %w", rendition, err)
+		}
+		playlists++
+		for _, seg := range parseRenditionPlaylist(data) {
+			seg.rendition = rendition
+			seg.key = prefix + rendition + "/" + seg.filename
+			segments = append(segments, seg)
+		}
+	}
+
+	log.Printf("[Task] Checking %d segments in %d playlists for film %s...", len(segments), playlists, filmID)
+	missing, err := p.missingSegments(ctx, segments)
+	if err != nil {
+		return err
+	}
+
+	reuploaded, reencoded := 0, 0
+	unrepaired := []string{}
+	if len(missing) > 0 {
+		log.Printf("[Task] Film %s is missing %d segments; repairing...", filmID, len(missing))
+
+		var videoData []byte
+		lutPath := ""
+		cleanupLUT := func() {}
+		defer func() { cleanupLUT() }()
+
+		for _, seg := range missing {
+			workspaceFile := path.Join(p.ffmpeg.WorkspaceDir(filmID.String(), seg.rendition), seg.filename)
+			if _, err := os.Stat(workspaceFile); err == nil {
+				if err := p.r2Client.UploadHLSLocalFile(ctx, filmID, seg.rendition, seg.filename, workspaceFile); err != nil {
+					return fmt.Errorf("failed to upload %s: %w", seg.key, err)
+				}
+				reuploaded++
+				continue
+			}
+
+			quality, ok := p.qualityByName(ctx, seg.rendition)
+			if !ok {
+				unrepaired = append(unrepaired, seg.key)
+				continue
+			}
+
+			if videoData == nil {
+				log.Printf("[Task] Downloading video from R2 to re-encode segments...")
+				if videoData, err = p.downloadSource(ctx, filmID); err != nil {
+					return fmt.Errorf("failed to download video: %w", err)
+				}
+				if lutPath, _, cleanupLUT, err = p.fetchLUT(ctx, filmID); err != nil {
+					return err
+				}
+			}
+
+			data, err := p.ffmpeg.EncodeSegment(videoData, quality, lutPath, seg.start, seg.duration)
+			if err != nil {
+				return fmt.Errorf("failed to re-encode %s: %w", seg.key, err)
+			}
+			if err := p.r2Client.UploadHLSFile(ctx, filmID, seg.rendition, seg.filename, bytes.NewReader(data)); err != nil {
+				return fmt.Errorf("failed to upload %s: %w", seg.key, err)
+			}
+			reencoded++
+		}
+	}
+
+	task.Result = map[string]string{
+		"playlists":  strconv.Itoa(playlists),
+		"segments":   strconv.Itoa(len(segments)),
+		"missing":    strconv.Itoa(len(missing)),
+		"reuploaded": strconv.Itoa(reuploaded),
+		"reencoded":  strconv.Itoa(reencoded),
+		"unrepaired": strconv.Itoa(len(unrepaired)),
+		"repaired":   strconv.FormatBool(reuploaded+reencoded > 0),
+	}
+	if len(unrepaired) > 0 {
+		return fmt.Errorf("%d missing segments could not be repaired, e.g. %s", len(unrepaired), unrepaired[0])
+	}
+	return nil
+}
+
+// missingSegments HEAD checks segments concurrently and returns the ones
+// not in R2, in playlist order
+func (p *Processor) missingSegments(ctx context.Context, segments []hlsSegment) ([]hlsSegment, error) {
+	exists := make([]bool, len(segments))
+	errs := make([]error, len(segments))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, hlsRepairConcurrency)
+	for i := range segments {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			exists[i], errs[i] = p.r2Client.FileExists(ctx, segments[i].key)
+		}(i)
+	}
+	wg.Wait()
+
+	missing := []hlsSegment{}
+	for i, seg := range segments {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", seg.key, errs[i])
+		}
+		if !exists[i] {
+			missing = append(missing, seg)
+		}
+	}
+	return missing, nil
+}
+
+// parseRenditionPlaylist returns the segments a media playlist lists, with
+// their start times. Absolute URIs point outside the film's HLS prefix and
+// are left out.
+func parseRenditionPlaylist(data []byte) []hlsSegment {
+	segments := []hlsSegment{}
+	start, duration := 0.0, 0.0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXTINF:"):
+			value := strings.TrimPrefix(line, "#EXTINF:")
+			if i := strings.IndexByte(value, ','); i >= 0 {
+				value = value[:i]
+			}
+			duration, _ = strconv.ParseFloat(value, 64)
+		case strings.HasPrefix(line, "#"):
+		default:
+			if !strings.Contains(line, "://") && !strings.HasPrefix(line, "/") {
+				segments = append(segments, hlsSegment{filename: line, start: start, duration: duration})
+			}
+			start += duration
+			duration = 0
+		}
+	}
+	return segments
+}
@@ -0,0 +1,267 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+	"github.com/arjunaayasa/filmtube/worker/internal/ffmpeg"
+	"github.com/google/uuid"
+)
+
+// processRetranscode transcodes a film again into the retranscode variant,
+// a candidate set admins compare with the current renditions before
+// swapping them in. With qc=true both sets are scored against the
+// original for the comparison.
+func (p *Processor) processRetranscode(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	variant := models.RetranscodeVariant
+
+	// Start from a clean candidate so no rendition of an earlier one lingers
+	if err := p.discardRetranscode(ctx, filmID); err != nil {
+		return err
+	}
+
+	log.Printf("[Task] Downloading video from R2 to re-transcode...")
+	videoData, err := p.downloadSource(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+
+	lutPath, _, cleanupLUT, err := p.fetchLUT(ctx, filmID)
+	defer cleanupLUT()
+	if err != nil {
+		return err
+	}
+
+	ladder := p.qualityLadder(ctx)
+	completedQualities := []string{}
+	for _, quality := range ladder {
+		log.Printf("[Task] Re-transcoding %s...", quality.Name)
+
+		result, err := p.ffmpeg.TranscodeToHLSVariant(videoData, filmID.String(), variant, quality, lutPath, nil)
+		if err != nil {
+			return fmt.Errorf("transcoding failed for %s: %w", quality.Name, err)
+		}
+
+		variantQuality := fmt.Sprintf("%s/%s", variant, quality.Name)
+		workspace := p.ffmpeg.WorkspaceDir(filmID.String(), variantQuality)
+		if err := p.uploadHLSFiles(ctx, filmID, variantQuality, workspace, result.IndexData); err != nil {
+			return fmt.Errorf("failed to upload HLS files: %w", err)
+		}
+
+		if err := p.recordRendition(ctx, filmID, variant, quality.Name, result.IndexData, workspace); err != nil {
+			return err
+		}
+
+		completedQualities = append(completedQualities, quality.Name)
+	}
+
+	masterData, err := p.masterPlaylist(ctx, filmID, completedQualities)
+	if err != nil {
+		return fmt.Errorf("failed to generate master playlist: %w", err)
+	}
+
+	masterKey := fmt.Sprintf("%s/%s/%s/master.m3u8", r2.HLSPath, filmID, variant)
+	if err := p.r2Client.UploadFile(ctx, masterKey, bytes.NewReader(masterData), "application/x-mpegURL"); err != nil {
+		return fmt.Errorf("failed to upload master playlist: %w", err)
+	}
+
+	task.Result = map[string]string{
+		"qualities": strings.Join(completedQualities, ","),
+	}
+
+	if task.Params["qc"] != "true" {
+		return nil
+	}
+	// The candidate is ready for review without scores, so a failed QC
+	// stage is reported rather than failing the task
+	if err := p.measureRetranscode(ctx, filmID, ladder, videoData, lutPath); err != nil {
+		log.Printf("[Task] QC failed for film %s: %v", filmID, err)
+		task.Result["qc"] = "failed"
+		return nil
+	}
+	task.Result["qc"] = "done"
+	return nil
+}
+
+// measureRetranscode scores every candidate rendition, encoded at the given
+// ladder, and the current rendition of the same quality against the
+// original video
+func (p *Processor) measureRetranscode(ctx context.Context, filmID uuid.UUID, ladder []ffmpeg.QualityLevel, videoData []byte, lutPath string) error {
+	// FFmpeg reads the reference from a file as it is opened twice per metric
+	sourceFile, err := os.CreateTemp("", fmt.Sprintf("qc_%s_*", filmID))
+	if err != nil {
+		return fmt.Errorf("failed to create source temp file: %w", err)
+	}
+	defer os.Remove(sourceFile.Name())
+	if _, err := sourceFile.Write(videoData); err != nil {
+		sourceFile.Close()
+		return fmt.Errorf("failed to write source temp file: %w", err)
+	}
+	sourceFile.Close()
+
+	current, err := p.defaultRenditions(ctx, filmID)
+	if err != nil {
+		return err
+	}
+	hasCurrent := map[string]bool{}
+	for _, name := range current {
+		hasCurrent[name] = true
+	}
+
+	for _, quality := range ladder {
+		log.Printf("[Task] Measuring %s quality...", quality.Name)
+
+		// The candidate's encode is still in its workspace
+		workspace := p.ffmpeg.WorkspaceDir(filmID.String(), fmt.Sprintf("%s/%s", models.RetranscodeVariant, quality.Name))
+		if err := p.scoreRendition(ctx, filmID, models.RetranscodeVariant, quality, sourceFile.Name(), path.Join(workspace, "index.m3u8"), lutPath); err != nil {
+			return err
+		}
+
+		if !hasCurrent[quality.Name] {
+			continue
+		}
+		indexURL := p.r2Client.GetPublicURL(fmt.Sprintf("%s/%s/%s/index.m3u8", r2.HLSPath, filmID, quality.Name))
+		if err := p.scoreRendition(ctx, filmID, models.VariantDefault, quality, sourceFile.Name(), indexURL, lutPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scoreRendition measures one rendition and records its scores
+func (p *Processor) scoreRendition(ctx context.Context, filmID uuid.UUID, variant string, quality ffmpeg.QualityLevel, sourcePath, playlist, lutPath string) error {
+	scores, err := p.ffmpeg.MeasureQuality(sourcePath, playlist, quality, lutPath)
+	if err != nil {
+		return fmt.Errorf("failed to measure %s %s: %w", variant, quality.Name, err)
+	}
+	if err := p.queries.SetVideoAssetQC(ctx, filmID, variant, quality.Name, scores.VMAF, scores.PSNR); err != nil {
+		return fmt.Errorf("failed to record QC scores: %w", err)
+	}
+	return nil
+}
+
+// processSwapRetranscode makes a film's reviewed re-transcode its default
+// rendition set: the candidate's files are copied over the default ones,
+// the master playlist is pointed at them and the candidate is removed.
+// Default renditions the candidate lacks are deleted.
+func (p *Processor) processSwapRetranscode(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	candidatePrefix := r2.GetHLSVariantPrefix(filmID, models.RetranscodeVariant)
+
+	assets, err := p.queries.GetVideoAssetsByVariant(ctx, filmID, models.RetranscodeVariant)
+	if err != nil {
+		return fmt.Errorf("failed to list video assets: %w", err)
+	}
+	if len(assets) == 0 {
+		return fmt.Errorf("film has no re-transcode to swap in")
+	}
+	qualities := []string{}
+	for _, q := range p.knownQualities(ctx) {
+		for _, asset := range assets {
+			if asset.Quality == q.Name {
+				qualities = append(qualities, q.Name)
+			}
+		}
+	}
+
+	current, err := p.defaultRenditions(ctx, filmID)
+	if err != nil {
+		return err
+	}
+
+	for _, quality := range qualities {
+		log.Printf("[Task] Swapping in re-transcoded %s...", quality)
+		if err := p.copyRendition(ctx, filmID, candidatePrefix+quality+"/", quality); err != nil {
+			return err
+		}
+	}
+
+	if err := p.uploadDefaultMaster(ctx, filmID, qualities); err != nil {
+		return err
+	}
+
+	kept := map[string]bool{}
+	for _, quality := range qualities {
+		kept[quality] = true
+	}
+	for _, name := range current {
+		if kept[name] {
+			continue
+		}
+		if err := p.r2Client.DeletePrefix(ctx, fmt.Sprintf("%s/%s/%s/", r2.HLSPath, filmID, name)); err != nil {
+			return fmt.Errorf("failed to delete %s rendition: %w", name, err)
+		}
+	}
+
+	fromPrefix := p.r2Client.GetPublicURL(candidatePrefix)
+	toPrefix := p.r2Client.GetPublicURL(fmt.Sprintf("%s/%s/", r2.HLSPath, filmID))
+	if err := p.queries.PromoteVideoAssetVariant(ctx, filmID, models.RetranscodeVariant, fromPrefix, toPrefix); err != nil {
+		return fmt.Errorf("failed to promote video assets: %w", err)
+	}
+
+	if err := p.r2Client.DeletePrefix(ctx, candidatePrefix); err != nil {
+		return fmt.Errorf("failed to delete re-transcode: %w", err)
+	}
+
+	task.Result = map[string]string{
+		"qualities": strings.Join(qualities, ","),
+	}
+	return nil
+}
+
+// copyRendition copies a rendition's files from prefix over the default
+// rendition of the same quality, then deletes default files the copy did
+// not replace, such as the surplus segments of a longer encode
+func (p *Processor) copyRendition(ctx context.Context, filmID uuid.UUID, prefix, quality string) error {
+	keys, err := p.r2Client.ListKeys(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list %s files: %w", quality, err)
+	}
+
+	copied := map[string]bool{}
+	for _, key := range keys {
+		filename := strings.TrimPrefix(key, prefix)
+		data, err := p.r2Client.DownloadFile(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", key, err)
+		}
+		if err := p.r2Client.UploadHLSFile(ctx, filmID, quality, filename, bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to upload %s/%s: %w", quality, filename, err)
+		}
+		copied[filename] = true
+	}
+
+	defaultPrefix := fmt.Sprintf("%s/%s/%s/", r2.HLSPath, filmID, quality)
+	existing, err := p.r2Client.ListKeys(ctx, defaultPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list %s files: %w", quality, err)
+	}
+	for _, key := range existing {
+		if copied[strings.TrimPrefix(key, defaultPrefix)] {
+			continue
+		}
+		if err := p.r2Client.DeleteFile(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// discardRetranscode deletes a film's re-transcode candidate, if any
+func (p *Processor) discardRetranscode(ctx context.Context, filmID uuid.UUID) error {
+	if err := p.r2Client.DeletePrefix(ctx, r2.GetHLSVariantPrefix(filmID, models.RetranscodeVariant)); err != nil {
+		return fmt.Errorf("failed to delete previous re-transcode: %w", err)
+	}
+	if err := p.queries.DeleteVideoAssetVariant(ctx, filmID, models.RetranscodeVariant); err != nil {
+		return fmt.Errorf("failed to forget previous re-transcode: %w", err)
+	}
+	return nil
+}
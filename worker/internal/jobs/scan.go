@@ -0,0 +1,285 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+	"github.com/arjunaayasa/filmtube/worker/internal/scanner"
+	"github.com/google/uuid"
+)
+
+// scanRetryDelays is how long a source scan that reached no verdict waits
+// before each retry. The film stays in SCANNING meanwhile, and fails once
+// the retries run out.
+var scanRetryDelays = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	6 * time.Hour,
+}
+
+// scanRetryBatch caps how many due scans one sweep queues again
+const scanRetryBatch = 100
+
+// processScanFile scans an uploaded file for malware and records the
+// verdict. An infected source is moved to quarantine, its film failed with
+// a message that names no signature and its creator notified; a clean one
+// is submitted for transcoding. The file is streamed to a temp file rather
+// than held in memory.
+func (p *Processor) processScanFile(ctx context.Context, task *models.WorkerTask) error {
+	scanID, err := uuid.Parse(task.Params["scan_id"])
+	if err != nil {
+		return fmt.Errorf("invalid scan_id parameter: %w", err)
+	}
+	scan, err := p.queries.GetFileScan(ctx, scanID)
+	if err != nil {
+		return fmt.Errorf("failed to get scan: %w", err)
+	}
+
+	store, err := p.sourceStore(ctx, scan.FilmID)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp("", fmt.Sprintf("scan_%s_*", scan.ID))
+	if err != nil {
+		return p.failScan(ctx, scan, fmt.Errorf("failed to create scan temp file: %w", err))
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	size, err := store.DownloadToFile(ctx, scan.ObjectKey, tmp.Name())
+	if err != nil {
+		return p.failScan(ctx, scan, fmt.Errorf("failed to download %s: %w", scan.ObjectKey, err))
+	}
+	sum, err := fileSHA256(tmp.Name())
+	if err != nil {
+		return p.failScan(ctx, scan, err)
+	}
+	scan.SizeBytes = size
+	scan.SHA256 = sum
+	scan.Scanner = p.scanner.Name()
+
+	verdict, err := p.scanner.Scan(ctx, scanner.File{
+		Name:   path.Base(scan.ObjectKey),
+		Path:   tmp.Name(),
+		Size:   size,
+		SHA256: sum,
+	})
+	if err != nil {
+		return p.failScan(ctx, scan, err)
+	}
+	scan.Status = verdict.Status
+	scan.Signature = verdict.Signature
+
+	if scan.Status == models.ScanInfected {
+		log.Printf("[Task] Scan %s found %s in %s", scan.ID, scan.Signature, scan.ObjectKey)
+		key := r2.GetQuarantineKey(scan.FilmID, scan.ID)
+		if err := quarantineFile(ctx, store, key, tmp.Name()); err != nil {
+			return p.failScan(ctx, scan, fmt.Errorf("failed to quarantine %s: %w", scan.ObjectKey, err))
+		}
+		scan.QuarantineKey = &key
+		if err := store.DeleteFile(ctx, scan.ObjectKey); err != nil {
+			log.Printf("[Task] Warning: failed to delete infected %s: %v", scan.ObjectKey, err)
+		}
+	}
+
+	if err := p.queries.CompleteFileScan(ctx, scan); err != nil {
+		return fmt.Errorf("failed to record scan: %w", err)
+	}
+	task.Result = map[string]string{"status": string(scan.Status)}
+	if scan.Kind != models.ScanKindSource {
+		return nil
+	}
+	if scan.Status == models.ScanInfected {
+		p.rejectSource(ctx, scan.FilmID)
+		return nil
+	}
+	return p.submitSource(ctx, scan.FilmID)
+}
+
+// fileSHA256 returns the hex digest of a file, read as a stream
+func fileSHA256(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to open scan temp file: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash scan temp file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// quarantineFile uploads the local copy of an infected file to its
+// quarantine key
+func quarantineFile(ctx context.Context, store *r2.Client, key, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return store.UploadFile(ctx, key, f, "application/octet-stream")
+}
+
+// failScan records that a scan reached no verdict and returns its error. A
+// source already found infected is rejected all the same; any other is
+// held in SCANNING and its scan retried after a backoff, so no source is
+// transcoded unscanned.
+func (p *Processor) failScan(ctx context.Context, scan *models.FileScan, scanErr error) error {
+	infected := scan.Status == models.ScanInfected
+	scan.Status = models.ScanError
+	scan.Error = scanErr.Error()
+	if err := p.queries.CompleteFileScan(ctx, scan); err != nil {
+		log.Printf("[Task] Warning: failed to record scan error: %v", err)
+	}
+	if scan.Kind != models.ScanKindSource {
+		return scanErr
+	}
+	if infected {
+		p.rejectSource(ctx, scan.FilmID)
+		return scanErr
+	}
+	delay := scanRetryDelays[len(scanRetryDelays)-1]
+	if scan.Attempts < len(scanRetryDelays) {
+		delay = scanRetryDelays[scan.Attempts]
+	}
+	if err := p.queries.SetFileScanNextAttempt(ctx, scan.ID, time.Now().Add(delay)); err != nil {
+		log.Printf("[Task] Warning: failed to schedule retry of scan %s: %v", scan.ID, err)
+	}
+	return scanErr
+}
+
+// RetryDueScans queues again the source scans that reached no verdict, or
+// whose tasks were lost, once their retry is due. A film whose scan has
+// used every retry is failed rather than transcoded unscanned.
+func (p *Processor) RetryDueScans(ctx context.Context) error {
+	scans, err := p.queries.ListDueSourceScans(ctx, scanRetryBatch)
+	if err != nil {
+		return fmt.Errorf("failed to list due scans: %w", err)
+	}
+	for i := range scans {
+		scan := &scans[i]
+		if scan.Attempts >= len(scanRetryDelays) {
+			log.Printf("[Task] Scan of film %s reached no verdict after %d retries", scan.FilmID, scan.Attempts)
+			p.markFailed(ctx, scan.FilmID, models.ScanUnavailableMessage)
+			continue
+		}
+		claimed, err := p.queries.ClaimFileScanRetry(ctx, scan)
+		if err != nil {
+			log.Printf("[Task] Warning: failed to claim scan %s: %v", scan.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		task := &models.WorkerTask{
+			ID:        uuid.New(),
+			Type:      models.TaskScanFile,
+			FilmID:    scan.FilmID,
+			Params:    map[string]string{"scan_id": scan.ID.String()},
+			CreatedAt: time.Now(),
+		}
+		// An unqueued retry falls due again after models.ScanStallTimeout
+		if err := p.redis.EnqueueTask(ctx, task); err != nil {
+			log.Printf("[Task] Warning: failed to queue retry of scan %s: %v", scan.ID, err)
+			continue
+		}
+		log.Printf("[Task] Retrying scan %s of film %s (attempt %d)", scan.ID, scan.FilmID, scan.Attempts+1)
+	}
+	return nil
+}
+
+// rejectSource fails a film whose source was found infected and tells its
+// creator
+func (p *Processor) rejectSource(ctx context.Context, filmID uuid.UUID) {
+	p.markFailed(ctx, filmID, models.ScanRejectedMessage)
+
+	film, err := p.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		log.Printf("[Task] Warning: failed to get film %s: %v", filmID, err)
+		return
+	}
+	notification := models.Notification{
+		UserID:  film.CreatedByID,
+		Kind:    models.NotifyUploadQuarantined,
+		FilmID:  &film.ID,
+		Message: fmt.Sprintf("Your upload for %q did not pass our security scan and was removed. Please upload a different file.", film.Title),
+	}
+	if err := p.queries.CreateNotifications(ctx, []models.Notification{notification}); err != nil {
+		log.Printf("[Task] Warning: failed to notify creator of film %s: %v", filmID, err)
+	}
+}
+
+// submitSource hands a film whose source passed its scan to admission
+// control, which queues it for transcoding or defers it. Only a film
+// still waiting in SCANNING is submitted, so a repeated scan queues it
+// once.
+func (p *Processor) submitSource(ctx context.Context, filmID uuid.UUID) error {
+	film, err := p.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to get film: %w", err)
+	}
+	if film.Status != models.StatusScanning {
+		return nil
+	}
+	position, err := p.admission.Submit(ctx, filmID)
+	if err != nil {
+		p.markFailed(ctx, filmID, fmt.Sprintf("failed to queue transcode: %v", err))
+		return fmt.Errorf("failed to queue transcode of film %s: %w", filmID, err)
+	}
+	if position > 0 {
+		log.Printf("[Task] Deferred transcode of film %s at position %d", filmID, position)
+	}
+	return nil
+}
+
+// queueSourceScan marks a film SCANNING and queues the malware scan of its
+// source. A scan task that can't be queued is left for RetryDueScans.
+func (p *Processor) queueSourceScan(ctx context.Context, filmID uuid.UUID) error {
+	scan, err := p.queries.CreateFileScan(ctx, filmID, models.ScanKindSource, r2.GetOriginalKey(filmID))
+	if err != nil {
+		return err
+	}
+	// The status is set first so a scan finishing at once isn't undone
+	if err := p.queries.SetFilmStatus(ctx, filmID, models.StatusScanning); err != nil {
+		return err
+	}
+	p.redis.SetFilmStatus(ctx, filmID, models.StatusScanning)
+
+	task := &models.WorkerTask{
+		ID:        uuid.New(),
+		Type:      models.TaskScanFile,
+		FilmID:    filmID,
+		Params:    map[string]string{"scan_id": scan.ID.String()},
+		CreatedAt: time.Now(),
+	}
+	if err := p.redis.EnqueueTask(ctx, task); err != nil {
+		log.Printf("[Task] Warning: failed to queue scan of film %s, retrying later: %v", filmID, err)
+		if err := p.queries.SetFileScanNextAttempt(ctx, scan.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceRejected reports whether a film's source was found infected, so
+// a transcode racing the scan does not overwrite its failure
+func (p *Processor) sourceRejected(ctx context.Context, filmID uuid.UUID) bool {
+	rejected, err := p.queries.FilmSourceRejected(ctx, filmID)
+	if err != nil {
+		log.Printf("[Job] Warning: failed to check source scan: %v", err)
+		return false
+	}
+	return rejected
+}
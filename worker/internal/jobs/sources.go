@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+	"github.com/google/uuid"
+)
+
+// sourceStore returns the R2 client for the bucket holding a film's
+// uploaded source, which may be in another region than the worker
+func (p *Processor) sourceStore(ctx context.Context, filmID uuid.UUID) (*r2.Client, error) {
+	film, err := p.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get film: %w", err)
+	}
+	return p.r2Client.ForRegion(film.SourceRegion), nil
+}
+
+// downloadSource downloads a film's uploaded source from its regional
+// bucket
+func (p *Processor) downloadSource(ctx context.Context, filmID uuid.UUID) ([]byte, error) {
+	film, err := p.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get film: %w", err)
+	}
+	data, err := p.r2Client.ForRegion(film.SourceRegion).DownloadOriginalVideo(ctx, filmID)
+	if err != nil && film.SourceDisposition != nil && *film.SourceDisposition == models.SourceDeleted {
+		return nil, fmt.Errorf("source was deleted by the retention policy, upload it again: %w", err)
+	}
+	return data, err
+}
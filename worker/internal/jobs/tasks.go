@@ -0,0 +1,370 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+	"github.com/google/uuid"
+)
+
+// ProcessTask runs a worker task and records its outcome
+func (p *Processor) ProcessTask(ctx context.Context, task *models.WorkerTask) error {
+	log.Printf("[Task] Starting %s task %s for film %s", task.Type, task.ID, task.FilmID)
+
+	task.Status = models.TaskRunning
+	p.redis.SetTask(ctx, task)
+
+	var err error
+	switch task.Type {
+	case models.TaskBurnInSubtitles:
+		err = p.processBurnIn(ctx, task)
+	case models.TaskReplaceAudio:
+		err = p.processReplaceAudio(ctx, task)
+	case models.TaskLUTPreview:
+		err = p.processLUTPreview(ctx, task)
+	case models.TaskPressScreener:
+		err = p.processScreener(ctx, task)
+	case models.TaskPurchaseDownload:
+		err = p.processPurchaseDownload(ctx, task)
+	case models.TaskImportComments:
+		err = p.processCommentImport(ctx, task)
+	case models.TaskExportComments:
+		err = p.processCommentExport(ctx, task)
+	case models.TaskRepairHLS:
+		err = p.processRepairHLS(ctx, task)
+	case models.TaskPruneRenditions:
+		err = p.processPruneRenditions(ctx, task)
+	case models.TaskRestoreRenditions:
+		err = p.processRestoreRenditions(ctx, task)
+	case models.TaskRetranscode:
+		err = p.processRetranscode(ctx, task)
+	case models.TaskSwapRetranscode:
+		err = p.processSwapRetranscode(ctx, task)
+	case models.TaskQualityCheck:
+		err = p.processQualityCheck(ctx, task)
+	case models.TaskAdminExport:
+		err = p.processAdminExport(ctx, task)
+	case models.TaskScanFile:
+		err = p.processScanFile(ctx, task)
+	case models.TaskImportURL:
+		err = p.processImportURL(ctx, task)
+	case models.TaskImportCatalogItem:
+		err = p.processCatalogImportItem(ctx, task)
+	case models.TaskClassifyContent:
+		err = p.processClassifyContent(ctx, task)
+	default:
+		err = fmt.Errorf("unknown task type %q", task.Type)
+	}
+
+	if err != nil {
+		log.Printf("[Task] Task %s failed: %v", task.ID, err)
+		task.Status = models.TaskFailed
+		task.Error = err.Error()
+	} else {
+		log.Printf("[Task] Task %s completed", task.ID)
+		task.Status = models.TaskCompleted
+	}
+	p.redis.SetTask(ctx, task)
+
+	return err
+}
+
+// processBurnIn produces an additional rendition set with a subtitle track
+// burned in, stored under hls/{filmId}/{variant}/ and tracked as its own
+// variant in video_assets
+func (p *Processor) processBurnIn(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	language := task.Params["language"]
+	if language == "" {
+		return fmt.Errorf("missing language parameter")
+	}
+	variant := models.BurnInVariant(language)
+
+	log.Printf("[Task] Downloading video and %s subtitles from R2...", language)
+	videoData, err := p.downloadSource(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+
+	subtitleData, err := p.r2Client.DownloadFile(ctx, r2.GetSubtitleKey(filmID, language))
+	if err != nil {
+		return fmt.Errorf("failed to download subtitles: %w", err)
+	}
+
+	// FFmpeg's subtitles filter reads from a file
+	subtitleFile, err := os.CreateTemp("", fmt.Sprintf("subs_%s_*.vtt", filmID))
+	if err != nil {
+		return fmt.Errorf("failed to create subtitle temp file: %w", err)
+	}
+	defer os.Remove(subtitleFile.Name())
+	if _, err := subtitleFile.Write(subtitleData); err != nil {
+		subtitleFile.Close()
+		return fmt.Errorf("failed to write subtitle temp file: %w", err)
+	}
+	subtitleFile.Close()
+
+	lutPath, _, cleanupLUT, err := p.fetchLUT(ctx, filmID)
+	defer cleanupLUT()
+	if err != nil {
+		return err
+	}
+
+	completedQualities := []string{}
+	for _, quality := range p.qualityLadder(ctx) {
+		log.Printf("[Task] Transcoding %s with burned-in subtitles...", quality.Name)
+
+		result, err := p.ffmpeg.TranscodeToHLSWithSubtitles(videoData, filmID.String(), variant, quality, subtitleFile.Name(), lutPath, nil)
+		if err != nil {
+			return fmt.Errorf("transcoding failed for %s: %w", quality.Name, err)
+		}
+
+		variantQuality := fmt.Sprintf("%s/%s", variant, quality.Name)
+		workspace := p.ffmpeg.WorkspaceDir(filmID.String(), variantQuality)
+		if err := p.uploadHLSFiles(ctx, filmID, variantQuality, workspace, result.IndexData); err != nil {
+			return fmt.Errorf("failed to upload HLS files: %w", err)
+		}
+
+		if err := p.recordRendition(ctx, filmID, variant, quality.Name, result.IndexData, workspace); err != nil {
+			return err
+		}
+
+		completedQualities = append(completedQualities, quality.Name)
+	}
+
+	masterData, err := p.masterPlaylist(ctx, filmID, completedQualities)
+	if err != nil {
+		return fmt.Errorf("failed to generate master playlist: %w", err)
+	}
+
+	masterKey := fmt.Sprintf("%s/%s/%s/master.m3u8", r2.HLSPath, filmID, variant)
+	if err := p.r2Client.UploadFile(ctx, masterKey, bytes.NewReader(masterData), "application/x-mpegURL"); err != nil {
+		return fmt.Errorf("failed to upload master playlist: %w", err)
+	}
+
+	return nil
+}
+
+// processScreener produces a press screener: a rendition set with the
+// recipient's watermark drawn over the picture, stored under
+// hls/{filmId}/{variant}/ like other variants
+func (p *Processor) processScreener(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	variant := task.Params["variant"]
+	watermark := task.Params["watermark"]
+	if !strings.HasPrefix(variant, models.ScreenerVariantPrefix) || watermark == "" {
+		return fmt.Errorf("missing screener parameters")
+	}
+
+	log.Printf("[Task] Downloading video from R2 for screener %s...", variant)
+	videoData, err := p.downloadSource(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+
+	lutPath, _, cleanupLUT, err := p.fetchLUT(ctx, filmID)
+	defer cleanupLUT()
+	if err != nil {
+		return err
+	}
+
+	completedQualities := []string{}
+	for _, quality := range p.qualityLadder(ctx) {
+		log.Printf("[Task] Transcoding %s with screener watermark...", quality.Name)
+
+		result, err := p.ffmpeg.TranscodeToHLSWithWatermark(videoData, filmID.String(), variant, quality, watermark, lutPath, nil)
+		if err != nil {
+			return fmt.Errorf("transcoding failed for %s: %w", quality.Name, err)
+		}
+
+		variantQuality := fmt.Sprintf("%s/%s", variant, quality.Name)
+		workspace := p.ffmpeg.WorkspaceDir(filmID.String(), variantQuality)
+		if err := p.uploadHLSFiles(ctx, filmID, variantQuality, workspace, result.IndexData); err != nil {
+			return fmt.Errorf("failed to upload HLS files: %w", err)
+		}
+
+		if err := p.recordRendition(ctx, filmID, variant, quality.Name, result.IndexData, workspace); err != nil {
+			return err
+		}
+
+		completedQualities = append(completedQualities, quality.Name)
+	}
+
+	masterData, err := p.masterPlaylist(ctx, filmID, completedQualities)
+	if err != nil {
+		return fmt.Errorf("failed to generate master playlist: %w", err)
+	}
+
+	masterKey := fmt.Sprintf("%s/%s/%s/master.m3u8", r2.HLSPath, filmID, variant)
+	if err := p.r2Client.UploadFile(ctx, masterKey, bytes.NewReader(masterData), "application/x-mpegURL"); err != nil {
+		return fmt.Errorf("failed to upload master playlist: %w", err)
+	}
+
+	return nil
+}
+
+// processPurchaseDownload produces a download-to-own purchase's MP4 with
+// the buyer's license watermarked into the picture, stored under
+// downloads/{filmId}/{purchaseId}.mp4
+func (p *Processor) processPurchaseDownload(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+	purchaseID, err := uuid.Parse(task.Params["purchase_id"])
+	if err != nil {
+		return fmt.Errorf("invalid purchase_id parameter: %w", err)
+	}
+	watermark := task.Params["watermark"]
+	if watermark == "" {
+		return fmt.Errorf("missing watermark parameter")
+	}
+
+	err = p.generatePurchaseDownload(ctx, filmID, purchaseID, watermark)
+	if err != nil {
+		if markErr := p.queries.MarkPurchaseFailed(ctx, purchaseID); markErr != nil {
+			log.Printf("[Task] Failed to mark purchase %s failed: %v", purchaseID, markErr)
+		}
+	}
+	return err
+}
+
+func (p *Processor) generatePurchaseDownload(ctx context.Context, filmID, purchaseID uuid.UUID, watermark string) error {
+	log.Printf("[Task] Downloading video from R2 for purchase %s...", purchaseID)
+	videoData, err := p.downloadSource(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+
+	lutPath, _, cleanupLUT, err := p.fetchLUT(ctx, filmID)
+	defer cleanupLUT()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[Task] Encoding watermarked download...")
+	mp4Data, err := p.ffmpeg.WatermarkMP4(videoData, watermark, lutPath)
+	if err != nil {
+		return err
+	}
+
+	key := r2.GetPurchaseDownloadKey(filmID, purchaseID)
+	if err := p.r2Client.UploadFile(ctx, key, bytes.NewReader(mp4Data), "video/mp4"); err != nil {
+		return fmt.Errorf("failed to upload download: %w", err)
+	}
+
+	err = p.queries.MarkPurchaseReady(ctx, purchaseID, key, int64(len(mp4Data)))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Refunded while the file was being generated
+		log.Printf("[Task] Purchase %s was revoked; deleting its download", purchaseID)
+		return p.r2Client.DeleteFile(ctx, key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record download: %w", err)
+	}
+
+	return nil
+}
+
+// processReplaceAudio swaps the audio of every existing rendition set (the
+// default one plus any alternate variants) for the uploaded replacement
+// track. Video streams are copied, so no video re-encode happens. The
+// original source is remuxed too so later tasks pick up the new audio.
+func (p *Processor) processReplaceAudio(ctx context.Context, task *models.WorkerTask) error {
+	filmID := task.FilmID
+
+	log.Printf("[Task] Downloading replacement audio from R2...")
+	audioData, err := p.r2Client.DownloadFile(ctx, r2.GetReplacementAudioKey(filmID))
+	if err != nil {
+		return fmt.Errorf("failed to download replacement audio: %w", err)
+	}
+
+	audioFile, err := os.CreateTemp("", fmt.Sprintf("audio_%s_*", filmID))
+	if err != nil {
+		return fmt.Errorf("failed to create audio temp file: %w", err)
+	}
+	defer os.Remove(audioFile.Name())
+	if _, err := audioFile.Write(audioData); err != nil {
+		audioFile.Close()
+		return fmt.Errorf("failed to write audio temp file: %w", err)
+	}
+	audioFile.Close()
+
+	// Films transcoded before the default rendition set was tracked in
+	// video_assets lack it, so start with it and add any alternate variants
+	variants := []string{models.VariantDefault}
+	assets, err := p.queries.GetVideoAssetsByFilmID(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to list video assets: %w", err)
+	}
+	seen := map[string]bool{models.VariantDefault: true}
+	for _, asset := range assets {
+		if !seen[asset.Variant] {
+			seen[asset.Variant] = true
+			variants = append(variants, asset.Variant)
+		}
+	}
+
+	// Renditions follow the ladder they were encoded at, so replace the
+	// audio of those present rather than of the current ladder
+	qualities := p.knownQualities(ctx)
+	for _, variant := range variants {
+		for _, quality := range qualities {
+			renditionPath := quality.Name
+			if variant != models.VariantDefault {
+				renditionPath = fmt.Sprintf("%s/%s", variant, quality.Name)
+			}
+
+			indexKey := fmt.Sprintf("%s/%s/%s/index.m3u8", r2.HLSPath, filmID, renditionPath)
+			exists, err := p.r2Client.FileExists(ctx, indexKey)
+			if err != nil {
+				return fmt.Errorf("failed to check %s: %w", indexKey, err)
+			}
+			if !exists {
+				continue
+			}
+
+			log.Printf("[Task] Replacing audio for %s...", renditionPath)
+
+			indexURL := p.r2Client.GetPublicURL(indexKey)
+			outputDir := fmt.Sprintf("%s/audio_%s_%s_%s", os.TempDir(), filmID, variant, quality.Name)
+			result, err := p.ffmpeg.ReplaceAudioHLS(indexURL, audioFile.Name(), outputDir, quality)
+			if err != nil {
+				return fmt.Errorf("audio remux failed for %s: %w", renditionPath, err)
+			}
+
+			if err := p.uploadHLSFiles(ctx, filmID, renditionPath, outputDir, result.IndexData); err != nil {
+				return fmt.Errorf("failed to upload HLS files: %w", err)
+			}
+			// New audio changes segment sizes
+			if err := p.recordRendition(ctx, filmID, variant, quality.Name, result.IndexData, outputDir); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("[Task] Remuxing original video with replacement audio...")
+	sources, err := p.sourceStore(ctx, filmID)
+	if err != nil {
+		return err
+	}
+	videoData, err := sources.DownloadOriginalVideo(ctx, filmID)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+
+	remuxed, err := p.ffmpeg.ReplaceAudio(videoData, audioFile.Name())
+	if err != nil {
+		return fmt.Errorf("failed to remux original video: %w", err)
+	}
+
+	if err := sources.UploadOriginalVideo(ctx, filmID, bytes.NewReader(remuxed)); err != nil {
+		return fmt.Errorf("failed to upload original video: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+	"github.com/arjunaayasa/filmtube/backend/internal/r2"
+	"github.com/google/uuid"
+)
+
+// Sprite sheets hold at most spriteColumns x spriteRows frames, spread
+// evenly over the film but no closer than minSpriteInterval
+const (
+	spriteColumns     = 10
+	spriteRows        = 10
+	spriteTileWidth   = 160
+	spriteTileHeight  = 90
+	minSpriteInterval = time.Second
+)
+
+// generateSprite cuts the film's thumbnail sprite sheet and records it for
+// the timeline
+func (p *Processor) generateSprite(ctx context.Context, filmID uuid.UUID, videoData []byte, duration time.Duration, lutPath string) error {
+	if duration <= 0 {
+		return fmt.Errorf("unknown duration")
+	}
+
+	interval := duration / (spriteColumns * spriteRows)
+	if interval < minSpriteInterval {
+		interval = minSpriteInterval
+	}
+	frames := int(math.Ceil(duration.Seconds() / interval.Seconds()))
+	if frames > spriteColumns*spriteRows {
+		frames = spriteColumns * spriteRows
+	}
+	rows := (frames + spriteColumns - 1) / spriteColumns
+
+	data, err := p.ffmpeg.GenerateSpriteSheet(videoData, interval, spriteColumns, rows, spriteTileWidth, spriteTileHeight, lutPath)
+	if err != nil {
+		return err
+	}
+
+	key := r2.GetSpriteKey(filmID)
+	if err := p.r2Client.UploadFile(ctx, key, bytes.NewReader(data), "image/jpeg"); err != nil {
+		return fmt.Errorf("failed to upload sprite sheet: %w", err)
+	}
+
+	sprite := &models.FilmSprite{
+		FilmID:          filmID,
+		URL:             p.r2Client.GetPublicURL(key),
+		IntervalSeconds: interval.Seconds(),
+		FrameCount:      frames,
+		Columns:         spriteColumns,
+		Rows:            rows,
+		TileWidth:       spriteTileWidth,
+		TileHeight:      spriteTileHeight,
+	}
+	if err := p.queries.UpsertFilmSprite(ctx, sprite); err != nil {
+		return fmt.Errorf("failed to record sprite sheet: %w", err)
+	}
+	return nil
+}
+
+// buildTimeline generates and caches the film's timeline once it is ready;
+// a failure leaves it to be generated on first read
+func (p *Processor) buildTimeline(ctx context.Context, filmID uuid.UUID) {
+	film, err := p.queries.GetFilmByID(ctx, filmID)
+	if err != nil {
+		log.Printf("[Job] Warning: failed to load film for timeline: %v", err)
+		return
+	}
+	if _, err := p.timeline.Build(ctx, film); err != nil {
+		log.Printf("[Job] Warning: failed to build timeline: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Package scanner checks uploaded files for malware. A worker runs one
+// scanner, picked by configuration: ClamAV, VirusTotal or none.
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+)
+
+// Verdict is a scanner's finding on one file. Signature names what was
+// found in an infected file.
+type Verdict struct {
+	Status    models.ScanStatus
+	Signature string
+}
+
+// File is an uploaded file downloaded to disk for scanning
+type File struct {
+	// Name is the file's name, as shown to scanning services
+	Name string
+	// Path is the local copy, read from the start by each scan
+	Path string
+	Size int64
+	// SHA256 is the hex digest of the contents
+	SHA256 string
+}
+
+// Scanner scans a file's contents
+type Scanner interface {
+	// Name identifies the scanner in scan records
+	Name() string
+	// Scan returns a verdict, or an error when it could not reach one
+	Scan(ctx context.Context, file File) (*Verdict, error)
+}
+
+// Config selects and configures a scanner
+type Config struct {
+	// Scanner is none, clamav or virustotal
+	Scanner string
+	// ClamAVAddress is clamd's TCP address (host:port)
+	ClamAVAddress string
+	// VirusTotalAPIKey authenticates against the VirusTotal API
+	VirusTotalAPIKey string
+	// Timeout bounds one scan
+	Timeout time.Duration
+}
+
+// New creates the configured scanner
+func New(cfg Config) (Scanner, error) {
+	switch cfg.Scanner {
+	case "", models.ScannerNone:
+		return Noop{}, nil
+	case models.ScannerClamAV:
+		if cfg.ClamAVAddress == "" {
+			return nil, fmt.Errorf("CLAMAV_ADDRESS is required for the clamav scanner")
+		}
+		return NewClamAV(cfg.ClamAVAddress, cfg.Timeout), nil
+	case models.ScannerVirusTotal:
+		if cfg.VirusTotalAPIKey == "" {
+			return nil, fmt.Errorf("VIRUSTOTAL_API_KEY is required for the virustotal scanner")
+		}
+		return NewVirusTotal(cfg.VirusTotalAPIKey, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown scanner %q (want none, clamav or virustotal)", cfg.Scanner)
+	}
+}
+
+// Noop is the scanner of workers with none configured; it skips every
+// file
+type Noop struct{}
+
+func (Noop) Name() string { return models.ScannerNone }
+
+func (Noop) Scan(ctx context.Context, file File) (*Verdict, error) {
+	return &Verdict{Status: models.ScanSkipped}, nil
+}
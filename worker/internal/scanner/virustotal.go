@@ -0,0 +1,209 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arjunaayasa/filmtube/backend/internal/models"
+)
+
+const (
+	virusTotalAPI = "https://www.virustotal.com/api/v3"
+	// virusTotalDirectLimit is the largest file POST /files accepts; bigger
+	// ones go to a one-off upload URL
+	virusTotalDirectLimit  = 32 << 20
+	virusTotalPollInterval = 15 * time.Second
+)
+
+// VirusTotal scans files with the VirusTotal v3 API. Files it already
+// knows by hash are not uploaded again.
+type VirusTotal struct {
+	apiKey  string
+	timeout time.Duration
+	client  *http.Client
+}
+
+// NewVirusTotal creates a VirusTotal scanner
+func NewVirusTotal(apiKey string, timeout time.Duration) *VirusTotal {
+	return &VirusTotal{
+		apiKey:  apiKey,
+		timeout: timeout,
+		client:  &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+func (v *VirusTotal) Name() string { return models.ScannerVirusTotal }
+
+// vtStats are the engine counts of a file report or analysis
+type vtStats struct {
+	Malicious  int `json:"malicious"`
+	Suspicious int `json:"suspicious"`
+}
+
+// vtResults are the per-engine results of a file report or analysis
+type vtResults map[string]struct {
+	Category string `json:"category"`
+	Result   string `json:"result"`
+}
+
+// Scan looks the file up by hash and uploads it for analysis if VirusTotal
+// has not seen it, waiting for the analysis to complete
+func (v *VirusTotal) Scan(ctx context.Context, file File) (*Verdict, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	var report struct {
+		Data struct {
+			Attributes struct {
+				Stats   vtStats   `json:"last_analysis_stats"`
+				Results vtResults `json:"last_analysis_results"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	found, err := v.get(ctx, "/files/"+file.SHA256, &report)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		attrs := report.Data.Attributes
+		return verdict(attrs.Stats, attrs.Results), nil
+	}
+
+	analysisID, err := v.upload(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	return v.await(ctx, analysisID)
+}
+
+// upload submits a file and returns its analysis ID. The form is streamed
+// from disk as it is sent.
+func (v *VirusTotal) upload(ctx context.Context, file File) (string, error) {
+	url := virusTotalAPI + "/files"
+	if file.Size > virusTotalDirectLimit {
+		var upload struct {
+			Data string `json:"data"`
+		}
+		if _, err := v.get(ctx, "/files/upload_url", &upload); err != nil {
+			return "", err
+		}
+		url = upload.Data
+	}
+
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	body, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	go func() {
+		part, err := form.CreateFormFile("file", file.Name)
+		if err == nil {
+			_, err = io.Copy(part, f)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		body.Close()
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	var result struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if _, err := v.do(req, &result); err != nil {
+		return "", fmt.Errorf("failed to upload to VirusTotal: %w", err)
+	}
+	return result.Data.ID, nil
+}
+
+// await polls an analysis until it completes or the scan times out
+func (v *VirusTotal) await(ctx context.Context, analysisID string) (*Verdict, error) {
+	for {
+		var analysis struct {
+			Data struct {
+				Attributes struct {
+					Status  string    `json:"status"`
+					Stats   vtStats   `json:"stats"`
+					Results vtResults `json:"results"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if _, err := v.get(ctx, "/analyses/"+analysisID, &analysis); err != nil {
+			return nil, err
+		}
+		attrs := analysis.Data.Attributes
+		if attrs.Status == "completed" {
+			return verdict(attrs.Stats, attrs.Results), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("VirusTotal analysis %s did not complete: %w", analysisID, ctx.Err())
+		case <-time.After(virusTotalPollInterval):
+		}
+	}
+}
+
+// get fetches an API path into out, reporting false when it is not found
+func (v *VirusTotal) get(ctx context.Context, path string, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, virusTotalAPI+path, nil)
+	if err != nil {
+		return false, err
+	}
+	return v.do(req, out)
+}
+
+func (v *VirusTotal) do(req *http.Request, out interface{}) (bool, error) {
+	req.Header.Set("x-apikey", v.apiKey)
+	req.Header.Set("Accept", "application/json")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("VirusTotal request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, fmt.Errorf("VirusTotal returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode VirusTotal response: %w", err)
+	}
+	return true, nil
+}
+
+// verdict treats a file any engine finds malicious as infected, naming it
+// by one of the detections
+func verdict(stats vtStats, results vtResults) *Verdict {
+	if stats.Malicious == 0 {
+		return &Verdict{Status: models.ScanClean}
+	}
+	signature := fmt.Sprintf("%d engines flagged the file", stats.Malicious)
+	for _, r := range results {
+		if r.Category == "malicious" && r.Result != "" {
+			signature = r.Result
+			break
+		}
+	}
+	return &Verdict{Status: models.ScanInfected, Signature: signature}
+}
@@ -0,0 +1,113 @@
+package ytdlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrTooLarge is returned when the video is larger than allowed
+var ErrTooLarge = errors.New("video is larger than the upload limit")
+
+// YTDLP fetches videos and their metadata from sites like YouTube and
+// Vimeo with the yt-dlp tool
+type YTDLP struct {
+	path    string
+	tempDir string
+}
+
+// New creates a yt-dlp handler
+func New(path, tempDir string) *YTDLP {
+	return &YTDLP{path: path, tempDir: tempDir}
+}
+
+// Metadata describes a video page
+type Metadata struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Duration    float64  `json:"duration"` // in seconds
+	Tags        []string `json:"tags"`
+	Uploader    string   `json:"uploader"`
+	WebpageURL  string   `json:"webpage_url"`
+}
+
+// Metadata reads a single video's metadata without downloading it
+func (y *YTDLP) Metadata(ctx context.Context, url string) (*Metadata, error) {
+	cmd := exec.CommandContext(ctx, y.path,
+		"--dump-single-json",
+		"--no-playlist",
+		"--skip-download",
+		"--no-warnings",
+		url,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("yt-dlp failed: %w, stderr: %s", err, lastLine(stderr.String()))
+	}
+
+	var meta Metadata
+	if err := json.Unmarshal(stdout.Bytes(), &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse yt-dlp metadata: %w", err)
+	}
+	return &meta, nil
+}
+
+// Download fetches a video as MP4 into a new temporary directory and
+// returns the file's path and a cleanup function removing it
+func (y *YTDLP) Download(ctx context.Context, url string, maxBytes int64) (string, func(), error) {
+	dir, err := os.MkdirTemp(y.tempDir, "ytdlp_*")
+	if err != nil {
+		return "", func() {}, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	cmd := exec.CommandContext(ctx, y.path,
+		"--no-playlist",
+		"--no-warnings",
+		"--no-progress",
+		"-f", "bv*[ext=mp4]+ba[ext=m4a]/b[ext=mp4]/bv*+ba/b",
+		"--merge-output-format", "mp4",
+		"--max-filesize", strconv.FormatInt(maxBytes, 10),
+		"-o", filepath.Join(dir, "source.%(ext)s"),
+		url,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		return "", func() {}, fmt.Errorf("yt-dlp failed: %w, stderr: %s", err, lastLine(stderr.String()))
+	}
+
+	// yt-dlp skips a file above --max-filesize without failing
+	matches, _ := filepath.Glob(filepath.Join(dir, "source.*"))
+	if len(matches) == 0 {
+		cleanup()
+		return "", func() {}, ErrTooLarge
+	}
+	info, err := os.Stat(matches[0])
+	if err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	if info.Size() > maxBytes {
+		cleanup()
+		return "", func() {}, ErrTooLarge
+	}
+	return matches[0], cleanup, nil
+}
+
+// lastLine returns the last non-empty line of yt-dlp's output, which
+// holds its error
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return lines[len(lines)-1]
+}